@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jobrunner/ortus/internal/adapters/geopackage"
+	"github.com/jobrunner/ortus/internal/app"
+	"github.com/jobrunner/ortus/internal/config"
+)
+
+// configCmd groups the configuration subcommands.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the ortus configuration",
+}
+
+// configValidateCmd runs the checks a server start would trip over — config
+// validation, storage reachability, SpatiaLite — without starting anything,
+// and prints the effective configuration so a deployment can be reviewed.
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration, check storage and SpatiaLite, print the effective config",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigValidate,
+	// A failed check is not a usage mistake.
+	SilenceUsage: true,
+}
+
+func init() {
+	configValidateCmd.Flags().Duration("timeout", 30*time.Second, "timeout for the storage check")
+	configValidateCmd.Flags().Bool("no-print", false, "only run the checks, do not print the effective configuration")
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, _ []string) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	noPrint, _ := cmd.Flags().GetBool("no-print")
+	w := cmd.OutOrStdout()
+
+	// Load runs Validate; nothing else is worth checking against an invalid config.
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		printCheck(w, "config", err)
+		return err
+	}
+	source := config.FileUsed()
+	if source == "" {
+		source = "no file, defaults and environment only"
+	}
+	printCheck(w, fmt.Sprintf("config (%s)", source), nil)
+
+	failed := 0
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+	storageCheck := fmt.Sprintf("storage (%s)", cfg.Storage.Type)
+	if n, err := app.CheckStorage(ctx, cfg); err != nil {
+		failed++
+		printCheck(w, storageCheck, err)
+	} else {
+		printCheck(w, fmt.Sprintf("%s: %d objects", storageCheck, n), nil)
+	}
+
+	if version, err := geopackage.SpatiaLiteVersion(cmd.Context()); err != nil {
+		failed++
+		printCheck(w, "spatialite", err)
+	} else {
+		printCheck(w, "spatialite "+version, nil)
+	}
+
+	if !noPrint {
+		out, err := yaml.Marshal(config.Settings())
+		if err != nil {
+			return fmt.Errorf("rendering config: %w", err)
+		}
+		if _, err := fmt.Fprintf(w, "\n# Effective configuration (secrets redacted)\n%s", out); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("config validate: checks failed: %d", failed)
+	}
+	return nil
+}
+
+// printCheck writes one check result line. Write errors are ignored: the
+// exit status carries the outcome.
+func printCheck(w io.Writer, what string, err error) {
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "FAIL %s: %v\n", what, err)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "ok   %s\n", what)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jobrunner/ortus/internal/adapters/geopackage"
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// indexCmd builds the R-tree indexes ortus would otherwise build on first
+// load, so an image or volume can ship already-indexed packages and the
+// server becomes ready without the index pass. Layers that already have an
+// index are left alone, so re-running it is cheap.
+var indexCmd = &cobra.Command{
+	Use:   "index <dir|file.gpkg>...",
+	Short: "Build missing spatial indexes of GeoPackages ahead of deployment",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runIndex,
+	// A file that fails to index is not a usage mistake.
+	SilenceUsage: true,
+}
+
+func init() {
+	indexCmd.Flags().Int("parallel", runtime.NumCPU(), "number of packages indexed concurrently")
+	rootCmd.AddCommand(indexCmd)
+}
+
+// indexResult is the outcome of indexing one package.
+type indexResult struct {
+	path    string
+	layers  int
+	created int
+	err     error
+}
+
+func runIndex(cmd *cobra.Command, args []string) error {
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	if parallel < 1 {
+		return errors.New("--parallel must be at least 1")
+	}
+
+	paths, err := collectGeoPackages(args)
+	if err != nil {
+		return fmt.Errorf("index: %w", err)
+	}
+	if len(paths) == 0 {
+		return errors.New("index: no GeoPackages found")
+	}
+
+	results := indexGeoPackages(cmd.Context(), paths, parallel)
+	return reportIndexResults(cmd.OutOrStdout(), results)
+}
+
+// collectGeoPackages expands the arguments into a sorted, de-duplicated list
+// of GeoPackage paths. Directories are walked recursively; a file argument
+// must itself be a GeoPackage.
+func collectGeoPackages(args []string) ([]string, error) {
+	seen := map[string]bool{}
+	var paths []string
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if !domain.IsGeoPackageFile(arg) {
+				return nil, fmt.Errorf("%s is not a GeoPackage (.gpkg)", arg)
+			}
+			add(filepath.Clean(arg))
+			continue
+		}
+		err = filepath.WalkDir(arg, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && domain.IsGeoPackageFile(p) {
+				add(filepath.Clean(p))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// indexGeoPackages indexes paths on a pool of parallel workers and returns
+// the results in the order of paths. Each package is opened under its path as
+// the source id, so two files with the same base name cannot collide in the
+// shared repository.
+func indexGeoPackages(ctx context.Context, paths []string, parallel int) []indexResult {
+	repo := geopackage.NewRepository(geopackage.Options{})
+	results := make([]indexResult, len(paths))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(parallel, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = indexGeoPackage(ctx, repo, paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// indexGeoPackage builds the missing R-tree index of every feature layer of
+// one package.
+func indexGeoPackage(ctx context.Context, repo *geopackage.Repository, path string) indexResult {
+	res := indexResult{path: path}
+	src, err := repo.Open(ctx, path, path)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	defer func() { _ = repo.Close(ctx, path) }()
+
+	res.layers = len(src.Layers)
+	for _, l := range src.Layers {
+		hasIndex, err := repo.HasSpatialIndex(ctx, path, l.Name)
+		if err != nil {
+			res.err = fmt.Errorf("layer %s: %w", l.Name, err)
+			return res
+		}
+		if hasIndex {
+			continue
+		}
+		if err := repo.CreateSpatialIndex(ctx, path, l.Name); err != nil {
+			res.err = fmt.Errorf("layer %s: %w", l.Name, err)
+			return res
+		}
+		res.created++
+	}
+	return res
+}
+
+// reportIndexResults prints one line per package and returns an error naming
+// the number of packages that failed, if any.
+func reportIndexResults(w io.Writer, results []indexResult) error {
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			if _, err := fmt.Fprintf(w, "FAIL %s: %v\n", r.path, r.err); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "ok   %s: %d layers, %d indexes built\n", r.path, r.layers, r.created); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("index: %d of %d packages failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jobrunner/ortus/internal/adapters/geopackage"
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// inspectCmd opens a single GeoPackage with the same adapter the server uses
+// and reports what ortus would see in it, so an operator can check a file
+// before publishing it to storage. It never builds indexes or otherwise writes.
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <file.gpkg>",
+	Short: "Print layers, SRIDs, feature counts, extents, metadata and index status of a GeoPackage",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runInspect,
+	// A file that fails to open is not a usage mistake.
+	SilenceUsage: true,
+}
+
+func init() {
+	inspectCmd.Flags().Bool("json", false, "print the report as JSON")
+	rootCmd.AddCommand(inspectCmd)
+}
+
+// inspectReport is the result of inspecting one GeoPackage. The JSON field
+// names follow the /api/v1/sources response where the two overlap.
+type inspectReport struct {
+	Path        string          `json:"path"`
+	Size        int64           `json:"size_bytes"`
+	SourceID    string          `json:"source_id"`
+	Identifier  string          `json:"identifier,omitempty"`
+	Description string          `json:"description,omitempty"`
+	License     *inspectLicense `json:"license,omitempty"`
+	Layers      []inspectLayer  `json:"layers"`
+	Warnings    []string        `json:"warnings"`
+}
+
+type inspectLicense struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Attribution string `json:"attribution"`
+}
+
+type inspectLayer struct {
+	Name           string         `json:"name"`
+	GeometryType   string         `json:"geometry_type"`
+	GeometryColumn string         `json:"geometry_column"`
+	SRID           int            `json:"srid"`
+	FeatureCount   int64          `json:"feature_count"`
+	Extent         *inspectExtent `json:"extent,omitempty"`
+	HasIndex       bool           `json:"has_index"`
+}
+
+type inspectExtent struct {
+	MinX float64 `json:"min_x"`
+	MinY float64 `json:"min_y"`
+	MaxX float64 `json:"max_x"`
+	MaxY float64 `json:"max_y"`
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	if !strings.EqualFold(filepath.Ext(path), ".gpkg") {
+		return fmt.Errorf("inspect: %s is not a GeoPackage (.gpkg)", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("inspect: %w", err)
+	}
+
+	report, err := inspectGeoPackage(cmd.Context(), path, info.Size())
+	if err != nil {
+		return fmt.Errorf("inspect %s: %w", path, err)
+	}
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return printInspectReport(cmd.OutOrStdout(), report)
+}
+
+// inspectGeoPackage opens path with the geopackage adapter and collects the
+// report. Layers without an R-tree are reported, not indexed: ortus builds the
+// index on first load, which needs the file to be writable there.
+func inspectGeoPackage(ctx context.Context, path string, size int64) (*inspectReport, error) {
+	repo := geopackage.NewRepository(geopackage.Options{})
+	id := domain.DeriveSourceID(path)
+
+	src, err := repo.Open(ctx, id, path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = repo.Close(ctx, id) }()
+
+	identifier, err := repo.Identify(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &inspectReport{
+		Path:        path,
+		Size:        size,
+		SourceID:    id,
+		Identifier:  identifier,
+		Description: src.Metadata.Description,
+		Layers:      make([]inspectLayer, 0, len(src.Layers)),
+		Warnings:    []string{},
+	}
+	if !src.License.IsEmpty() {
+		report.License = &inspectLicense{Name: src.License.Name, URL: src.License.URL, Attribution: src.License.Attribution}
+	}
+	for _, l := range src.Layers {
+		hasIndex, err := repo.HasSpatialIndex(ctx, id, l.Name)
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", l.Name, err)
+		}
+		layer := inspectLayer{
+			Name:           l.Name,
+			GeometryType:   l.GeometryType,
+			GeometryColumn: l.GeometryColumn,
+			SRID:           l.SRID,
+			FeatureCount:   l.FeatureCount,
+			HasIndex:       hasIndex,
+		}
+		if l.Extent != nil {
+			layer.Extent = &inspectExtent{MinX: l.Extent.MinX, MinY: l.Extent.MinY, MaxX: l.Extent.MaxX, MaxY: l.Extent.MaxY}
+		}
+		report.Layers = append(report.Layers, layer)
+		if !hasIndex {
+			report.Warnings = append(report.Warnings,
+				fmt.Sprintf("layer %s has no spatial index; ortus builds it on load, so the file must be writable there", l.Name))
+		}
+		if l.Extent == nil {
+			report.Warnings = append(report.Warnings,
+				fmt.Sprintf("layer %s has no extent in gpkg_contents", l.Name))
+		}
+	}
+	if len(src.Layers) == 0 {
+		report.Warnings = append(report.Warnings, "no feature layers; the package would load but never match a query")
+	}
+	if report.License == nil {
+		report.Warnings = append(report.Warnings, "no license/attribution in gpkg_metadata")
+	}
+	return report, nil
+}
+
+// printInspectReport writes the human-readable report: a header block, one
+// table row per layer and the warnings.
+func printInspectReport(w io.Writer, r *inspectReport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "File:        %s (%d bytes)\n", r.Path, r.Size)
+	fmt.Fprintf(&b, "Source ID:   %s\n", r.SourceID)
+	if r.Identifier != "" {
+		fmt.Fprintf(&b, "Identifier:  %s\n", r.Identifier)
+	}
+	if r.Description != "" {
+		fmt.Fprintf(&b, "Description: %s\n", r.Description)
+	}
+	if r.License != nil {
+		fmt.Fprintf(&b, "License:     %s", r.License.Name)
+		if r.License.URL != "" {
+			fmt.Fprintf(&b, " <%s>", r.License.URL)
+		}
+		b.WriteString("\n")
+		if r.License.Attribution != "" {
+			fmt.Fprintf(&b, "Attribution: %s\n", r.License.Attribution)
+		}
+	}
+	b.WriteString("\n")
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LAYER\tTYPE\tSRID\tFEATURES\tINDEX\tEXTENT")
+	for _, l := range r.Layers {
+		index := "no"
+		if l.HasIndex {
+			index = "yes"
+		}
+		extent := "-"
+		if l.Extent != nil {
+			extent = fmt.Sprintf("%g,%g,%g,%g", l.Extent.MinX, l.Extent.MinY, l.Extent.MaxX, l.Extent.MaxY)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%s\n", l.Name, l.GeometryType, l.SRID, l.FeatureCount, index, extent)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Warnings) > 0 {
+		var wb strings.Builder
+		wb.WriteString("\nWarnings:\n")
+		for _, warn := range r.Warnings {
+			fmt.Fprintf(&wb, "  - %s\n", warn)
+		}
+		if _, err := io.WriteString(w, wb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -15,11 +15,13 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/jobrunner/ortus/internal/adapters/logfile"
 	mcpAdapter "github.com/jobrunner/ortus/internal/adapters/mcp"
 	"github.com/jobrunner/ortus/internal/adapters/telemetry"
 	"github.com/jobrunner/ortus/internal/app"
 
 	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/domain"
 )
 
 var (
@@ -91,9 +93,12 @@ func init() {
 	rootCmd.Flags().Bool("tls", false, "enable TLS")
 	rootCmd.Flags().StringSlice("tls-domains", nil, "TLS domains")
 	rootCmd.Flags().String("tls-email", "", "TLS email for Let's Encrypt")
+	rootCmd.Flags().String("tls-challenge", "dns-01", "ACME challenge type (dns-01, http-01, tls-alpn-01)")
+	rootCmd.Flags().String("tls-cert-file", "", "TLS certificate PEM file (instead of Let's Encrypt)")
+	rootCmd.Flags().String("tls-key-file", "", "TLS private key PEM file (with --tls-cert-file)")
 
 	// Storage flags
-	rootCmd.Flags().String("storage-type", "local", "storage type (local, s3, azure, http)")
+	rootCmd.Flags().String("storage-type", "local", "storage type (local, s3, azure, http, catalog)")
 	rootCmd.Flags().String("storage-path", "./data", "local storage path")
 
 	// CORS flags
@@ -119,6 +124,9 @@ func init() {
 	_ = viper.BindPFlag("tls.enabled", rootCmd.Flags().Lookup("tls"))
 	_ = viper.BindPFlag("tls.domains", rootCmd.Flags().Lookup("tls-domains"))
 	_ = viper.BindPFlag("tls.email", rootCmd.Flags().Lookup("tls-email"))
+	_ = viper.BindPFlag("tls.challenge", rootCmd.Flags().Lookup("tls-challenge"))
+	_ = viper.BindPFlag("tls.cert_file", rootCmd.Flags().Lookup("tls-cert-file"))
+	_ = viper.BindPFlag("tls.key_file", rootCmd.Flags().Lookup("tls-key-file"))
 	_ = viper.BindPFlag("storage.type", rootCmd.Flags().Lookup("storage-type"))
 	_ = viper.BindPFlag("storage.local_path", rootCmd.Flags().Lookup("storage-path"))
 	_ = viper.BindPFlag("server.cors.allowed_origins", rootCmd.Flags().Lookup("cors"))
@@ -143,10 +151,13 @@ func initConfig() {
 	}
 }
 
-func runServer(cmd *cobra.Command, _ []string) error {
+// loadServerConfig loads the serve-mode config: the config file, env and
+// flags, plus the build info and the inverted --disable-frontend flag. Used at
+// startup and again for every config reload, so both see the same values.
+func loadServerConfig(cmd *cobra.Command) (*config.Config, error) {
 	cfg, err := config.Load(cfgFile)
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return nil, fmt.Errorf("loading config: %w", err)
 	}
 	cfg.Build = config.BuildInfo{Version: version, Commit: commit, BuildDate: buildDate}
 
@@ -154,9 +165,24 @@ func runServer(cmd *cobra.Command, _ []string) error {
 	if disableFrontend, _ := cmd.Flags().GetBool("disable-frontend"); disableFrontend {
 		cfg.Server.FrontendEnabled = false
 	}
+	return cfg, nil
+}
 
-	// Setup logger
-	logger := setupLogger(cfg.Logging)
+func runServer(cmd *cobra.Command, _ []string) error {
+	cfg, err := loadServerConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Setup logger. The level is a LevelVar so a config reload can change it.
+	level := new(slog.LevelVar)
+	level.Set(cfg.Logging.SlogLevel())
+	logOut, closeLog, err := logOutput(cfg.Logging)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closeLog() }()
+	logger := slog.New(telemetry.NewSpanContextHandler(buildHandler(cfg.Logging, level, logOut)))
 	slog.SetDefault(logger)
 
 	logger.Info("starting Ortus",
@@ -179,11 +205,19 @@ func runServer(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return fmt.Errorf("initializing application: %w", err)
 	}
+	application.LoadConfig = func() (*config.Config, error) { return loadServerConfig(cmd) }
+	application.LogLevel = level
+
+	// SIGHUP reloads the runtime settings (see App.ReloadConfig).
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	defer signal.Stop(hupChan)
+	go reloadOnHangup(ctx, hupChan, application, logger)
 
 	// Start server in background
 	serverErr := make(chan error, 1)
 	go func() {
-		logger.Info("server listening", "address", cfg.Server.Address())
+		logger.Info("server listening", "address", cfg.Server.Endpoint())
 		if err := application.Start(ctx); err != nil && err != http.ErrServerClosed {
 			serverErr <- err
 		}
@@ -213,12 +247,20 @@ func runServer(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
-// setupLogger constructs the default stdout-writing logger used by
-// `serve` mode. The handler is wrapped with a span-context injector so
-// any slog.*Context call carrying a traced ctx auto-includes
-// trace_id/span_id (cheap no-op when ctx has no span).
-func setupLogger(cfg config.LoggingConfig) *slog.Logger {
-	return slog.New(telemetry.NewSpanContextHandler(buildHandler(cfg, os.Stdout)))
+// reloadOnHangup reloads the config on every SIGHUP until ctx is done. A
+// rejected config is logged and the running settings stay in place.
+func reloadOnHangup(ctx context.Context, hup <-chan os.Signal, application *app.App, logger *slog.Logger) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logger.Info("received SIGHUP, reloading config")
+			if _, err := application.ReloadConfig(domain.WithActor(ctx, "signal")); err != nil {
+				logger.Error("config reload rejected", "error", err)
+			}
+		}
+	}
 }
 
 // runMCPStdio boots the same application stack as `serve` (storage,
@@ -291,29 +333,34 @@ func runMCPStdio(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
-// setupStderrLogger mirrors setupLogger exactly — same level, same
+// setupStderrLogger mirrors the serve-mode logger — same level, same
 // UTC RFC3339 timestamp formatting via ReplaceAttr — but writes to
 // stderr. Used by stdio-mode (`./ortus mcp`) where stdout belongs to
 // the JSON-RPC protocol.
 func setupStderrLogger(cfg config.LoggingConfig) *slog.Logger {
-	return slog.New(telemetry.NewSpanContextHandler(buildHandler(cfg, os.Stderr)))
+	return slog.New(telemetry.NewSpanContextHandler(buildHandler(cfg, cfg.SlogLevel(), os.Stderr)))
 }
 
-// buildHandler centralizes the slog.Handler construction shared by
-// setupLogger (stdout) and setupStderrLogger (stderr) so they never
-// drift on level parsing or timestamp formatting.
-func buildHandler(cfg config.LoggingConfig, w io.Writer) slog.Handler {
-	var level slog.Level
-	switch cfg.Level {
-	case "debug":
-		level = slog.LevelDebug
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
+// logOutput returns where serve mode logs: stdout, or the rotating file of
+// logging.output file for hosts where nothing captures stdout. The close
+// function flushes pending rotations on exit.
+func logOutput(cfg config.LoggingConfig) (io.Writer, func() error, error) {
+	if cfg.Output != config.LogOutputFile {
+		return os.Stdout, func() error { return nil }, nil
 	}
+	w, err := logfile.New(cfg.File.Path, cfg.File.MaxSizeMB, cfg.File.MaxBackups, cfg.File.Compress)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening logging.file.path: %w", err)
+	}
+	return w, w.Close, nil
+}
+
+// buildHandler centralizes the slog.Handler construction shared by
+// serve mode (stdout) and setupStderrLogger (stderr) so they never
+// drift on timestamp formatting. The handler is wrapped by the callers
+// with a span-context injector so any slog.*Context call carrying a
+// traced ctx auto-includes trace_id/span_id.
+func buildHandler(cfg config.LoggingConfig, level slog.Leveler, w io.Writer) slog.Handler {
 	opts := &slog.HandlerOptions{
 		Level: level,
 		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jobrunner/ortus/internal/adapters/geopackage"
+	"github.com/jobrunner/ortus/internal/ports/input"
+)
+
+// optimizeCmd runs the maintenance of POST /admin/sources/{id}/optimize on
+// GeoPackages that are not being served: it drops and rebuilds every R-tree
+// index, runs ANALYZE and VACUUM, printing each step as it goes.
+var optimizeCmd = &cobra.Command{
+	Use:   "optimize <dir|file.gpkg>...",
+	Short: "Rebuild spatial indexes, ANALYZE and VACUUM GeoPackages",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runOptimize,
+	// A file that fails to optimize is not a usage mistake.
+	SilenceUsage: true,
+}
+
+func init() {
+	optimizeCmd.Flags().StringSlice("steps", []string{"reindex", "analyze", "vacuum"}, "steps to run, in order (reindex, analyze, vacuum)")
+	rootCmd.AddCommand(optimizeCmd)
+}
+
+func runOptimize(cmd *cobra.Command, args []string) error {
+	names, _ := cmd.Flags().GetStringSlice("steps")
+	steps := make([]input.OptimizeStep, 0, len(names))
+	for _, n := range names {
+		step := input.OptimizeStep(strings.ToLower(strings.TrimSpace(n)))
+		if !slices.Contains(input.OptimizeSteps, step) {
+			return fmt.Errorf("--steps: unknown step %q (want reindex, analyze or vacuum)", n)
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		return errors.New("--steps: no steps given")
+	}
+
+	paths, err := collectGeoPackages(args)
+	if err != nil {
+		return fmt.Errorf("optimize: %w", err)
+	}
+	if len(paths) == 0 {
+		return errors.New("optimize: no GeoPackages found")
+	}
+
+	repo := geopackage.NewRepository(geopackage.Options{})
+	out := cmd.OutOrStdout()
+	failed := 0
+	for _, path := range paths {
+		if err := optimizeGeoPackage(cmd.Context(), out, repo, path, steps); err != nil {
+			failed++
+			if _, werr := fmt.Fprintf(out, "FAIL %s: %v\n", path, err); werr != nil {
+				return werr
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("optimize: %d of %d packages failed", failed, len(paths))
+	}
+	return nil
+}
+
+// optimizeGeoPackage runs steps on one package, printing a line per finished
+// step (and per reindexed layer).
+func optimizeGeoPackage(ctx context.Context, w io.Writer, repo *geopackage.Repository, path string, steps []input.OptimizeStep) error {
+	src, err := repo.Open(ctx, path, path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = repo.Close(ctx, path) }()
+
+	for _, step := range steps {
+		start := time.Now()
+		switch step {
+		case input.OptimizeReindex:
+			for i, l := range src.Layers {
+				if err := repo.RebuildSpatialIndex(ctx, path, l.Name); err != nil {
+					return fmt.Errorf("reindex %s: %w", l.Name, err)
+				}
+				if _, err := fmt.Fprintf(w, "     %s: reindex %d/%d %s\n", path, i+1, len(src.Layers), l.Name); err != nil {
+					return err
+				}
+			}
+		case input.OptimizeAnalyze:
+			err = repo.Analyze(ctx, path)
+		case input.OptimizeVacuum:
+			err = repo.Vacuum(ctx, path)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", step, err)
+		}
+		if _, err := fmt.Fprintf(w, "ok   %s: %s (%s)\n", path, step, time.Since(start).Round(time.Millisecond)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	httpAdapter "github.com/jobrunner/ortus/internal/adapters/http"
+	"github.com/jobrunner/ortus/internal/app"
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// errNoFeatures is returned by `ortus query --fail-empty` when the point hit
+// nothing, so a CI step fails without parsing the output.
+var errNoFeatures = errors.New("query returned no features")
+
+// queryCmd runs one point query against local files through the same
+// QueryService the server uses, without starting it. Intended for CI checks
+// of a dataset before it is published.
+var queryCmd = &cobra.Command{
+	Use:   "query --file <file.gpkg> --lon <x> --lat <y>",
+	Short: "Run a one-shot point query against local GeoPackages without starting the server",
+	Args:  cobra.NoArgs,
+	RunE:  runQuery,
+	// A query that fails at runtime is not a usage mistake.
+	SilenceUsage: true,
+}
+
+func init() {
+	f := queryCmd.Flags()
+	f.StringSlice("file", nil, "GeoPackage to query (repeatable)")
+	f.Float64("lon", 0, "longitude, or x when --srid is not 4326")
+	f.Float64("lat", 0, "latitude, or y when --srid is not 4326")
+	f.Int("srid", domain.SRIDWGS84, "SRID of --lon/--lat")
+	f.StringSlice("properties", nil, "properties to return (default: all)")
+	f.Bool("with-geometry", false, "include feature geometry (WKT) in the output (default: query.with_geometry)")
+	f.Bool("fail-empty", false, "exit non-zero when the point matches no feature")
+	_ = queryCmd.MarkFlagRequired("file")
+	_ = queryCmd.MarkFlagRequired("lon")
+	_ = queryCmd.MarkFlagRequired("lat")
+	rootCmd.AddCommand(queryCmd)
+}
+
+func runQuery(cmd *cobra.Command, _ []string) error {
+	f := cmd.Flags()
+	files, _ := f.GetStringSlice("file")
+	lon, _ := f.GetFloat64("lon")
+	lat, _ := f.GetFloat64("lat")
+	srid, _ := f.GetInt("srid")
+	props, _ := f.GetStringSlice("properties")
+	withGeometry, _ := f.GetBool("with-geometry")
+	failEmpty, _ := f.GetBool("fail-empty")
+
+	for _, p := range files {
+		if _, err := os.Stat(p); err != nil {
+			return fmt.Errorf("query: %w", err)
+		}
+	}
+
+	// The config file is optional here; it only contributes the query and
+	// SQLite settings. Logs go to stderr so stdout stays valid JSON.
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	logger := setupStderrLogger(cfg.Logging)
+	slog.SetDefault(logger)
+	if !f.Changed("with-geometry") {
+		withGeometry = cfg.Query.WithGeometry
+	}
+
+	ctx := cmd.Context()
+	offline, err := app.NewOffline(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer offline.Close(ctx)
+
+	if err := offline.Load(ctx, files...); err != nil {
+		return err
+	}
+
+	resp, err := offline.QueryService.QueryPoint(ctx, domain.QueryRequest{
+		Coordinate: domain.NewCoordinate(lon, lat, srid),
+		SourceSRID: srid,
+		Properties: props,
+	})
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	if err := writeQueryJSON(cmd.OutOrStdout(), resp, withGeometry); err != nil {
+		return err
+	}
+	if failEmpty && resp.TotalFeatures == 0 {
+		return errNoFeatures
+	}
+	return nil
+}
+
+// writeQueryJSON prints the response in the /api/v1/query JSON shape.
+func writeQueryJSON(w io.Writer, resp *domain.QueryResponse, withGeometry bool) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(httpAdapter.FormatQueryResponse(resp, withGeometry))
+}
@@ -0,0 +1,13 @@
+package featureflags
+
+import (
+	"testing"
+
+	"go.uber.org/goleak"
+)
+
+// TestMain fails the package's tests if the reload loop outlives them — a
+// Provider that was Started but never Stopped is the leak this catches.
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
@@ -0,0 +1,163 @@
+// Package featureflags provides the config-backed output.FeatureFlags adapter:
+// per-deployment flag values from the main config, optionally overridden by a
+// small YAML flags file that is re-read while the process runs, so a subsystem
+// can be switched off without a redeploy (e.g. by editing a mounted ConfigMap).
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+var _ output.FeatureFlags = (*Provider)(nil)
+
+// Provider evaluates flags from the file overrides first, then the config
+// values, then the caller's fallback.
+type Provider struct {
+	defaults  map[domain.FeatureFlag]bool
+	overrides atomic.Pointer[map[domain.FeatureFlag]bool]
+	path      string
+	interval  time.Duration
+	logger    *slog.Logger
+
+	modTime time.Time // of the last file read; touched only by reload
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// New builds a Provider from the configured flag values. When path is set the
+// flags file is read once here — an unreadable or invalid file is a startup
+// error — and then re-read every interval after Start.
+func New(defaults map[string]bool, path string, interval time.Duration, logger *slog.Logger) (*Provider, error) {
+	d, err := parseFlags(defaults)
+	if err != nil {
+		return nil, err
+	}
+	p := &Provider{
+		defaults: d,
+		path:     path,
+		interval: interval,
+		logger:   logger,
+		stopCh:   make(chan struct{}),
+	}
+	if path != "" {
+		if err := p.reload(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Enabled implements output.FeatureFlags.
+func (p *Provider) Enabled(_ context.Context, flag domain.FeatureFlag, fallback bool) bool {
+	if o := p.overrides.Load(); o != nil {
+		if v, ok := (*o)[flag]; ok {
+			return v
+		}
+	}
+	if v, ok := p.defaults[flag]; ok {
+		return v
+	}
+	return fallback
+}
+
+// Start re-reads the flags file every interval until ctx is done or Stop is
+// called. It is a no-op without a flags file.
+func (p *Provider) Start(ctx context.Context) {
+	if p.path == "" || p.interval <= 0 {
+		return
+	}
+	p.wg.Add(1)
+	go p.run(ctx)
+}
+
+// Stop ends the reload loop started by Start and waits for it to exit.
+func (p *Provider) Stop() {
+	select {
+	case <-p.stopCh:
+	default:
+		close(p.stopCh)
+	}
+	p.wg.Wait()
+}
+
+func (p *Provider) run(ctx context.Context) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			// A bad edit keeps the last good overrides rather than flipping
+			// every flag back to its config value mid-flight.
+			if err := p.reload(); err != nil {
+				p.logger.Warn("feature flags file not reloaded", "path", p.path, "error", err)
+			}
+		}
+	}
+}
+
+// reload reads the flags file if it changed since the last read and swaps in
+// its values. A missing file clears the overrides, so deleting it returns
+// every flag to its config value.
+func (p *Provider) reload() error {
+	info, err := os.Stat(p.path)
+	if os.IsNotExist(err) {
+		if p.overrides.Swap(nil) != nil {
+			p.logger.Info("feature flags file removed; using configured values", "path", p.path)
+		}
+		p.modTime = time.Time{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("feature flags file: %w", err)
+	}
+	if info.ModTime().Equal(p.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("feature flags file: %w", err)
+	}
+	var raw map[string]bool
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("feature flags file %s: %w", p.path, err)
+	}
+	flags, err := parseFlags(raw)
+	if err != nil {
+		return fmt.Errorf("feature flags file %s: %w", p.path, err)
+	}
+	p.overrides.Store(&flags)
+	p.modTime = info.ModTime()
+	p.logger.Info("feature flags loaded", "path", p.path, "flags", raw)
+	return nil
+}
+
+// parseFlags converts flag names to domain flags, rejecting unknown names so a
+// typo cannot silently leave a kill-switch unset.
+func parseFlags(raw map[string]bool) (map[domain.FeatureFlag]bool, error) {
+	out := make(map[domain.FeatureFlag]bool, len(raw))
+	for name, v := range raw {
+		f, err := domain.ParseFeatureFlag(name)
+		if err != nil {
+			return nil, err
+		}
+		out[f] = v
+	}
+	return out, nil
+}
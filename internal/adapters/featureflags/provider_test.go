@@ -0,0 +1,126 @@
+package featureflags
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+// writeFlags writes a flags file and stamps it with mtime, so a reload sees a
+// change even when two writes land within the filesystem's time resolution.
+func writeFlags(t *testing.T, path, body string, mtime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProviderDefaults(t *testing.T) {
+	p, err := New(map[string]bool{"geometry_encoding": false, "parallel_enrichment": true}, "", 0, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if p.Enabled(ctx, domain.FlagGeometryEncoding, true) {
+		t.Error("geometry_encoding = true, want configured false")
+	}
+	if !p.Enabled(ctx, domain.FlagParallelEnrichment, false) {
+		t.Error("parallel_enrichment = false, want configured true")
+	}
+	// Not configured → the caller's fallback.
+	if p.Enabled(ctx, domain.FlagWGS84Reprojection, false) {
+		t.Error("unconfigured flag ignored the fallback")
+	}
+}
+
+func TestProviderRejectsUnknownFlag(t *testing.T) {
+	if _, err := New(map[string]bool{"geometry_encodign": false}, "", 0, testLogger()); err == nil {
+		t.Error("New accepted an unknown flag name")
+	}
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	writeFlags(t, path, "no_such_flag: true\n", time.Now())
+	if _, err := New(nil, path, 0, testLogger()); err == nil {
+		t.Error("New accepted a flags file with an unknown flag name")
+	}
+}
+
+// TestProviderFileReload: the file overrides the configured values, a changed
+// file is picked up, a broken edit keeps the last good values, and removing
+// the file falls back to the configuration.
+func TestProviderFileReload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	base := time.Now().Add(-time.Hour)
+	writeFlags(t, path, "geometry_encoding: false\n", base)
+
+	p, err := New(map[string]bool{"geometry_encoding": true}, path, time.Minute, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Enabled(ctx, domain.FlagGeometryEncoding, true) {
+		t.Fatal("file override not applied at startup")
+	}
+
+	writeFlags(t, path, "geometry_encoding: true\n", base.Add(time.Second))
+	if err := p.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Enabled(ctx, domain.FlagGeometryEncoding, false) {
+		t.Error("changed flags file not picked up")
+	}
+
+	writeFlags(t, path, "geometry_encoding: [\n", base.Add(2*time.Second))
+	if err := p.reload(); err == nil {
+		t.Error("reload accepted an invalid flags file")
+	}
+	if !p.Enabled(ctx, domain.FlagGeometryEncoding, false) {
+		t.Error("invalid flags file dropped the last good overrides")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.reload(); err != nil {
+		t.Fatal(err)
+	}
+	p.defaults[domain.FlagGeometryEncoding] = false
+	if p.Enabled(ctx, domain.FlagGeometryEncoding, true) {
+		t.Error("removed flags file still overrides the configured value")
+	}
+}
+
+// TestProviderStartStop: the reload loop applies a file change on its own and
+// exits on Stop (goleak in TestMain checks the latter).
+func TestProviderStartStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	base := time.Now().Add(-time.Hour)
+	writeFlags(t, path, "wgs84_reprojection: true\n", base)
+
+	p, err := New(nil, path, 10*time.Millisecond, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Start(context.Background())
+	defer p.Stop()
+
+	writeFlags(t, path, "wgs84_reprojection: false\n", base.Add(time.Second))
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Enabled(context.Background(), domain.FlagWGS84Reprojection, true) {
+		if time.Now().After(deadline) {
+			t.Fatal("reload loop never applied the changed flags file")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
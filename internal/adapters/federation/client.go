@@ -0,0 +1,213 @@
+// Package federation implements the federation peer port over the public
+// HTTP API of another ortus instance.
+package federation
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// maxResponseBytes bounds what is read from a peer, so a misbehaving peer
+// cannot exhaust memory.
+const maxResponseBytes = 32 << 20
+
+// Client is a federation peer reached over HTTP. The deadlines come from the
+// caller's context; the client itself sets none.
+type Client struct {
+	name    string
+	baseURL string
+	client  *http.Client
+}
+
+var _ output.Peer = (*Client)(nil)
+
+// NewClient creates a peer client for the ortus instance at baseURL (scheme
+// and host, optionally a path prefix; no trailing /api/v1). The HTTP client
+// is wrapped with otelhttp so forwarded queries join the caller's trace.
+func NewClient(name, baseURL string) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("peer %q: url must be an absolute http(s) URL, got %q", name, baseURL)
+	}
+	return &Client{
+		name:    name,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+	}, nil
+}
+
+// Name returns the configured peer name.
+func (c *Client) Name() string { return c.name }
+
+// SourceIDs lists the peer's sources via GET /api/v1/sources.
+func (c *Client) SourceIDs(ctx context.Context) ([]string, error) {
+	var doc struct {
+		Sources []struct {
+			ID string `json:"id"`
+		} `json:"sources"`
+	}
+	if err := c.get(ctx, "/api/v1/sources", &doc); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(doc.Sources))
+	for _, s := range doc.Sources {
+		if s.ID != "" {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids, nil
+}
+
+// QueryPoint runs req on the peer via GET /api/v1/query (or
+// /api/v1/query/{sourceId}), marked as forwarded so the peer does not forward
+// it again.
+func (c *Client) QueryPoint(ctx context.Context, req domain.QueryRequest) (*domain.QueryResponse, error) {
+	q := url.Values{}
+	q.Set("x", strconv.FormatFloat(req.Coordinate.X, 'f', -1, 64))
+	q.Set("y", strconv.FormatFloat(req.Coordinate.Y, 'f', -1, 64))
+	q.Set("srid", strconv.Itoa(req.Coordinate.SRID))
+	if len(req.Properties) > 0 {
+		q.Set("properties", strings.Join(req.Properties, ","))
+	}
+	if req.Geometry.Format != "" {
+		q.Set("geometry_format", string(req.Geometry.Format))
+	}
+	if req.Geometry.Simplify > 0 {
+		q.Set("simplify", strconv.FormatFloat(req.Geometry.Simplify, 'f', -1, 64))
+	}
+	path := "/api/v1/query"
+	if req.SourceID != "" {
+		path += "/" + url.PathEscape(req.SourceID)
+	}
+
+	var doc queryResponse
+	if err := c.get(ctx, path+"?"+q.Encode(), &doc); err != nil {
+		return nil, err
+	}
+	return doc.toDomain(req.Coordinate)
+}
+
+// get fetches path from the peer and decodes the JSON body into v.
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("peer %q: %w", c.name, err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set(output.ForwardedHeader, "1")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("peer %q: %w", c.name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		return fmt.Errorf("peer %q: GET %s: unexpected status %d", c.name, strings.SplitN(path, "?", 2)[0], resp.StatusCode)
+	}
+	// UseNumber keeps integer properties exact instead of float64.
+	dec := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("peer %q: decoding response: %w", c.name, err)
+	}
+	return nil
+}
+
+// queryResponse is the /api/v1/query JSON shape, as far as federation needs
+// it.
+type queryResponse struct {
+	Results []struct {
+		SourceID    string `json:"source_id"`
+		SourceName  string `json:"source_name"`
+		QueryTimeMS int64  `json:"query_time_ms"`
+		Incomplete  bool   `json:"incomplete"`
+		License     *struct {
+			Name        string `json:"name"`
+			URL         string `json:"url"`
+			Attribution string `json:"attribution"`
+		} `json:"license"`
+		Notes []struct {
+			Layer string `json:"layer"`
+			Text  string `json:"text"`
+		} `json:"notes"`
+		Features []struct {
+			ID         int64                  `json:"id"`
+			Layer      string                 `json:"layer"`
+			Properties map[string]interface{} `json:"properties"`
+			Match      *struct {
+				Kind      string  `json:"kind"`
+				DistanceM float64 `json:"distance_m"`
+				SRID      int     `json:"srid"`
+			} `json:"match"`
+			Geometry *struct {
+				Type    string          `json:"type"`
+				WKT     string          `json:"wkt"`
+				WKB     string          `json:"wkb"`
+				GeoJSON json.RawMessage `json:"geojson"`
+				GML     string          `json:"gml"`
+			} `json:"geometry"`
+		} `json:"features"`
+	} `json:"results"`
+	Incomplete bool `json:"incomplete"`
+}
+
+// toDomain converts the peer's answer. coord is the queried coordinate; the
+// peer echoes it, so it is not read back.
+func (r *queryResponse) toDomain(coord domain.Coordinate) (*domain.QueryResponse, error) {
+	out := &domain.QueryResponse{Coordinate: coord, Incomplete: r.Incomplete}
+	for _, res := range r.Results {
+		qr := domain.QueryResult{
+			SourceID:   res.SourceID,
+			SourceName: res.SourceName,
+			QueryTime:  time.Duration(res.QueryTimeMS) * time.Millisecond,
+			Incomplete: res.Incomplete,
+		}
+		if res.License != nil {
+			qr.License = domain.License{
+				Name:        res.License.Name,
+				URL:         res.License.URL,
+				Attribution: res.License.Attribution,
+			}
+		}
+		for _, n := range res.Notes {
+			qr.Notes = append(qr.Notes, domain.LayerNote{Layer: n.Layer, Text: n.Text})
+		}
+		for _, f := range res.Features {
+			feature := domain.Feature{ID: f.ID, LayerName: f.Layer, Properties: f.Properties}
+			if m := f.Match; m != nil {
+				feature.Match = domain.Match{Kind: domain.MatchKind(m.Kind), DistanceM: m.DistanceM, SRID: m.SRID}
+			}
+			if g := f.Geometry; g != nil {
+				feature.Geometry = domain.Geometry{Type: g.Type, WKT: g.WKT, GML: g.GML}
+				if len(g.GeoJSON) > 0 {
+					feature.Geometry.GeoJSON = string(g.GeoJSON)
+				}
+				if g.WKB != "" {
+					wkb, err := hex.DecodeString(g.WKB)
+					if err != nil {
+						return nil, fmt.Errorf("decoding wkb of feature %d in %q: %w", f.ID, res.SourceID, err)
+					}
+					feature.Geometry.WKB = wkb
+				}
+			}
+			qr.Features = append(qr.Features, feature)
+		}
+		out.AddResult(qr)
+	}
+	return out, nil
+}
@@ -0,0 +1,101 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// newPeerServer serves a canned /api/v1 surface and records the last query.
+func newPeerServer(t *testing.T, last *http.Request) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/sources", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"sources":[{"id":"alkis_by"},{"id":"dgm_by"}],"count":2}`))
+	})
+	mux.HandleFunc("/api/v1/query/", func(w http.ResponseWriter, r *http.Request) {
+		*last = *r
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"results": []map[string]any{{
+				"source_id": "alkis_by", "source_name": "ALKIS Bayern", "query_time_ms": 12,
+				"license": map[string]any{"name": "CC-BY-4.0"},
+				"notes":   []map[string]any{{"layer": "parcels", "text": "quarterly"}},
+				"features": []map[string]any{{
+					"id": 7, "layer": "parcels",
+					"properties": map[string]any{"number": 9007199254740993},
+					"match":      map[string]any{"kind": "contains", "distance_m": 0, "srid": 25832},
+					"geometry":   map[string]any{"type": "POINT", "wkt": "POINT(1 2)", "wkb": "0101"},
+				}},
+			}},
+			"total_features": 1,
+			"incomplete":     true,
+		})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient(t *testing.T) {
+	var last http.Request
+	srv := newPeerServer(t, &last)
+	c, err := NewClient("by", srv.URL+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := c.SourceIDs(context.Background())
+	if err != nil || len(ids) != 2 || ids[0] != "alkis_by" {
+		t.Fatalf("SourceIDs = %v, %v", ids, err)
+	}
+
+	resp, err := c.QueryPoint(context.Background(), domain.QueryRequest{
+		Coordinate: domain.Coordinate{X: 11.5, Y: 48.1, SRID: 4326},
+		SourceID:   "alkis_by",
+		Properties: []string{"number"},
+	})
+	if err != nil {
+		t.Fatalf("QueryPoint: %v", err)
+	}
+	if last.URL.Path != "/api/v1/query/alkis_by" || last.URL.Query().Get("x") != "11.5" ||
+		last.URL.Query().Get("properties") != "number" || last.Header.Get(output.ForwardedHeader) == "" {
+		t.Errorf("forwarded request = %s %v", last.URL, last.Header)
+	}
+	if !resp.Incomplete || resp.TotalFeatures != 1 {
+		t.Fatalf("response = %+v", resp)
+	}
+	r := resp.Results[0]
+	if r.SourceName != "ALKIS Bayern" || r.License.Name != "CC-BY-4.0" || len(r.Notes) != 1 {
+		t.Errorf("result = %+v", r)
+	}
+	f := r.Features[0]
+	if f.ID != 7 || f.Geometry.WKT != "POINT(1 2)" || len(f.Geometry.WKB) != 2 ||
+		f.Match != (domain.Match{Kind: domain.MatchContains, SRID: 25832}) {
+		t.Errorf("feature = %+v", f)
+	}
+	// Integers beyond float64 precision survive.
+	if n, _ := f.Properties["number"].(json.Number); n.String() != "9007199254740993" {
+		t.Errorf("number = %#v", f.Properties["number"])
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	if _, err := NewClient("x", "ftp://peer"); err == nil {
+		t.Error("NewClient accepted a non-http URL")
+	}
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+	c, err := NewClient("gone", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SourceIDs(context.Background()); err == nil {
+		t.Error("SourceIDs on a 404 returned no error")
+	}
+}
@@ -0,0 +1,139 @@
+// Package geocoder implements the geocoder output port over external address
+// search services: a Nominatim instance, or any service answering the custom
+// JSON contract (see Custom).
+package geocoder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/time/rate"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// maxResponseBytes bounds what is read from a geocoder, so a misbehaving
+// service cannot exhaust memory.
+const maxResponseBytes = 4 << 20
+
+// Options configures a geocoder client.
+type Options struct {
+	// URL is the service endpoint: the base URL of a Nominatim instance, or
+	// the full search URL of a custom service.
+	URL string
+	// UserAgent identifies this application to the service. Nominatim's usage
+	// policy refuses requests without a specific one.
+	UserAgent string
+	// Headers are sent with every request, e.g. an API key.
+	Headers map[string]string
+	// Timeout bounds one search; 0 leaves it to the caller's context.
+	Timeout time.Duration
+	// MinInterval spaces the requests to the service; a search waits for its
+	// turn. 0 sends them as they come.
+	MinInterval time.Duration
+	// License overrides the license and attribution of the results.
+	License domain.License
+}
+
+// client does the HTTP side shared by the providers.
+type client struct {
+	provider  string
+	baseURL   *url.URL
+	userAgent string
+	headers   map[string]string
+	timeout   time.Duration
+	limiter   *rate.Limiter // nil ⇒ no spacing
+	http      *http.Client
+}
+
+func newClient(provider string, opts Options) (*client, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("geocoder %s: url must be an absolute http(s) URL, got %q", provider, opts.URL)
+	}
+	c := &client{
+		provider:  provider,
+		baseURL:   u,
+		userAgent: opts.UserAgent,
+		headers:   opts.Headers,
+		timeout:   opts.Timeout,
+		http:      &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+	}
+	if opts.MinInterval > 0 {
+		c.limiter = rate.NewLimiter(rate.Every(opts.MinInterval), 1)
+	}
+	return c, nil
+}
+
+// get fetches u and decodes the JSON body into v. A failure of the service
+// wraps domain.ErrUnavailable; the caller's own cancellation or deadline is
+// returned as is.
+func (c *client) get(ctx context.Context, u *url.URL, v any) error {
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// The wait would outlast the deadline.
+			return fmt.Errorf("geocoder %s: busy: %w", c.provider, domain.ErrUnavailable)
+		}
+	}
+	reqCtx := ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return fmt.Errorf("geocoder %s: %w", c.provider, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	for k, val := range c.headers {
+		req.Header.Set(k, val)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("geocoder %s: %w: %w", c.provider, domain.ErrUnavailable, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		return fmt.Errorf("geocoder %s: unexpected status %d: %w", c.provider, resp.StatusCode, domain.ErrUnavailable)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(v); err != nil {
+		return fmt.Errorf("geocoder %s: decoding response: %w: %w", c.provider, domain.ErrUnavailable, err)
+	}
+	return nil
+}
+
+// searchURL returns the endpoint with params added to its query.
+func (c *client) searchURL(path string, params url.Values) *url.URL {
+	u := *c.baseURL
+	if path != "" {
+		u.Path = strings.TrimSuffix(u.Path, "/") + path
+	}
+	q := u.Query()
+	for k, vs := range params {
+		for _, v := range vs {
+			q.Add(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return &u
+}
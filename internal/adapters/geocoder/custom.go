@@ -0,0 +1,72 @@
+package geocoder
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// Custom searches addresses with a service of one's own, which is how
+// geocoders without a built-in client (a commercial API, a what3words
+// bridge, an in-house address register) are plugged in. It sends
+//
+//	GET <url>?q=<address>&limit=<n>
+//
+// with the configured headers and expects
+//
+//	{"results": [{"label": "...", "lon": 9.93, "lat": 49.79,
+//	              "bbox": [min_lon, min_lat, max_lon, max_lat]}]}
+//
+// in WGS84, best match first; bbox is optional.
+type Custom struct {
+	client  *client
+	license domain.License
+}
+
+var _ output.Geocoder = (*Custom)(nil)
+
+// NewCustom creates a client for the search endpoint at opts.URL.
+func NewCustom(opts Options) (*Custom, error) {
+	c, err := newClient("custom", opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Custom{client: c, license: opts.License}, nil
+}
+
+// Geocode runs GET <url>?q=&limit=.
+func (c *Custom) Geocode(ctx context.Context, query string, limit int) ([]domain.GeocodeMatch, error) {
+	params := url.Values{"q": {query}, "limit": {strconv.Itoa(limit)}}
+	var doc struct {
+		Results []struct {
+			Label string    `json:"label"`
+			Lon   *float64  `json:"lon"`
+			Lat   *float64  `json:"lat"`
+			BBox  []float64 `json:"bbox"`
+		} `json:"results"`
+	}
+	if err := c.client.get(ctx, c.client.searchURL("", params), &doc); err != nil {
+		return nil, err
+	}
+	matches := make([]domain.GeocodeMatch, 0, len(doc.Results))
+	for _, r := range doc.Results {
+		if r.Lon == nil || r.Lat == nil {
+			continue
+		}
+		m := domain.GeocodeMatch{Label: r.Label, Coordinate: domain.NewWGS84Coordinate(*r.Lon, *r.Lat)}
+		if len(r.BBox) == 4 {
+			m.Extent = domain.Extent{MinX: r.BBox[0], MinY: r.BBox[1], MaxX: r.BBox[2], MaxY: r.BBox[3], SRID: domain.SRIDWGS84}
+		}
+		matches = append(matches, m)
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// License returns the configured license, if any.
+func (c *Custom) License() domain.License { return c.license }
@@ -0,0 +1,157 @@
+package geocoder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func TestNominatim(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		_, _ = w.Write([]byte(`[
+			{"lat":"49.7929","lon":"9.9385","display_name":"Residenz, Würzburg, Bayern, Deutschland",
+			 "boundingbox":["49.7919","49.7940","9.9360","9.9410"]},
+			{"lat":"not a number","lon":"9.9","display_name":"broken"}
+		]`))
+	}))
+	defer srv.Close()
+
+	n, err := NewNominatim(Options{URL: srv.URL + "/", UserAgent: "ortus-test"}, "ops@example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err := n.Geocode(context.Background(), "Residenz Würzburg", 3)
+	if err != nil {
+		t.Fatalf("Geocode() error = %v", err)
+	}
+
+	if got.URL.Path != "/search" {
+		t.Errorf("path = %q, want /search", got.URL.Path)
+	}
+	q := got.URL.Query()
+	if q.Get("q") != "Residenz Würzburg" || q.Get("format") != "jsonv2" || q.Get("limit") != "3" || q.Get("email") != "ops@example.org" {
+		t.Errorf("query = %v", q)
+	}
+	if ua := got.Header.Get("User-Agent"); ua != "ortus-test" {
+		t.Errorf("User-Agent = %q, want ortus-test", ua)
+	}
+
+	if len(matches) != 1 {
+		t.Fatalf("matches = %+v, want the one parseable place", matches)
+	}
+	m := matches[0]
+	if m.Label != "Residenz, Würzburg, Bayern, Deutschland" || m.Coordinate != domain.NewWGS84Coordinate(9.9385, 49.7929) {
+		t.Errorf("match = %+v", m)
+	}
+	if want := (domain.Extent{MinX: 9.9360, MinY: 49.7919, MaxX: 9.9410, MaxY: 49.7940, SRID: domain.SRIDWGS84}); m.Extent != want {
+		t.Errorf("extent = %+v, want %+v", m.Extent, want)
+	}
+	if n.License().Name != "ODbL 1.0" {
+		t.Errorf("License() = %+v, want the OpenStreetMap license", n.License())
+	}
+}
+
+func TestCustom(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		_, _ = w.Write([]byte(`{"results":[
+			{"label":"///index.home.raft","lon":-0.2033,"lat":51.5213,"bbox":[-0.20335,51.52128,-0.20331,51.52131]},
+			{"label":"no point"},
+			{"label":"second","lon":1,"lat":2},
+			{"label":"third","lon":3,"lat":4}
+		]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewCustom(Options{
+		URL:     srv.URL + "/w3w/convert?lang=en",
+		Headers: map[string]string{"X-Api-Key": "secret"},
+		License: domain.License{Attribution: "what3words"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err := c.Geocode(context.Background(), "index.home.raft", 2)
+	if err != nil {
+		t.Fatalf("Geocode() error = %v", err)
+	}
+	if got.URL.Path != "/w3w/convert" || got.URL.Query().Get("lang") != "en" || got.URL.Query().Get("q") != "index.home.raft" || got.URL.Query().Get("limit") != "2" {
+		t.Errorf("request = %s, want the configured URL plus q and limit", got.URL)
+	}
+	if got.Header.Get("X-Api-Key") != "secret" {
+		t.Errorf("X-Api-Key = %q, want the configured header", got.Header.Get("X-Api-Key"))
+	}
+	if len(matches) != 2 || matches[0].Label != "///index.home.raft" || matches[1].Label != "second" {
+		t.Fatalf("matches = %+v, want the first two with a point", matches)
+	}
+	if matches[0].Extent.MinX != -0.20335 || matches[1].Extent != (domain.Extent{}) {
+		t.Errorf("extents = %+v, %+v", matches[0].Extent, matches[1].Extent)
+	}
+	if c.License().Attribution != "what3words" {
+		t.Errorf("License() = %+v, want the configured one", c.License())
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	status := http.StatusOK
+	body := `{"results":[]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	c, err := NewCustom(Options{URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m, err := c.Geocode(context.Background(), "nowhere", 5); err != nil || len(m) != 0 {
+		t.Errorf("no match: %v, %v; want an empty result", m, err)
+	}
+	status = http.StatusTooManyRequests
+	if _, err := c.Geocode(context.Background(), "x", 5); !errors.Is(err, domain.ErrUnavailable) {
+		t.Errorf("status 429: error = %v, want ErrUnavailable", err)
+	}
+	status, body = http.StatusOK, `<html>`
+	if _, err := c.Geocode(context.Background(), "x", 5); !errors.Is(err, domain.ErrUnavailable) {
+		t.Errorf("malformed body: error = %v, want ErrUnavailable", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Geocode(ctx, "x", 5); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: error = %v, want context.Canceled", err)
+	}
+
+	if _, err := NewNominatim(Options{URL: "nominatim.example.org"}, ""); err == nil {
+		t.Error("NewNominatim with a relative URL: error = nil")
+	}
+}
+
+func TestClientMinInterval(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[]}`))
+	}))
+	defer srv.Close()
+	c, err := NewCustom(Options{URL: srv.URL, MinInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Geocode(context.Background(), "x", 1); err != nil {
+		t.Fatalf("first search: %v", err)
+	}
+	// The second search would have to wait an hour; its deadline is sooner.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := c.Geocode(ctx, "x", 1); !errors.Is(err, domain.ErrUnavailable) {
+		t.Errorf("second search: error = %v, want ErrUnavailable without waiting", err)
+	}
+}
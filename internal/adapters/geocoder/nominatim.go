@@ -0,0 +1,97 @@
+package geocoder
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// NominatimPublicURL is the OpenStreetMap Foundation's public Nominatim
+// instance. Its usage policy allows at most one request per second and asks
+// for caching and an identifying User-Agent.
+const NominatimPublicURL = "https://nominatim.openstreetmap.org"
+
+// nominatimLicense is the license of OpenStreetMap data, which Nominatim
+// returns.
+var nominatimLicense = domain.License{
+	Name:        "ODbL 1.0",
+	URL:         "https://opendatacommons.org/licenses/odbl/",
+	Attribution: "Data © OpenStreetMap contributors",
+}
+
+// Nominatim searches addresses with the /search API of a Nominatim instance.
+type Nominatim struct {
+	client  *client
+	email   string
+	license domain.License
+}
+
+var _ output.Geocoder = (*Nominatim)(nil)
+
+// NewNominatim creates a client for the Nominatim instance at opts.URL (no
+// trailing /search). email, when set, is sent along as the contact the usage
+// policy asks heavy users for.
+func NewNominatim(opts Options, email string) (*Nominatim, error) {
+	c, err := newClient("nominatim", opts)
+	if err != nil {
+		return nil, err
+	}
+	license := opts.License
+	if license.IsEmpty() {
+		license = nominatimLicense
+	}
+	return &Nominatim{client: c, email: email, license: license}, nil
+}
+
+// Geocode runs GET /search?format=jsonv2.
+func (n *Nominatim) Geocode(ctx context.Context, query string, limit int) ([]domain.GeocodeMatch, error) {
+	params := url.Values{
+		"q":      {query},
+		"format": {"jsonv2"},
+		"limit":  {strconv.Itoa(limit)},
+	}
+	if n.email != "" {
+		params.Set("email", n.email)
+	}
+	var places []struct {
+		Lat         string   `json:"lat"`
+		Lon         string   `json:"lon"`
+		DisplayName string   `json:"display_name"`
+		BoundingBox []string `json:"boundingbox"` // min lat, max lat, min lon, max lon
+	}
+	if err := n.client.get(ctx, n.client.searchURL("/search", params), &places); err != nil {
+		return nil, err
+	}
+	matches := make([]domain.GeocodeMatch, 0, len(places))
+	for _, p := range places {
+		lat, errLat := strconv.ParseFloat(p.Lat, 64)
+		lon, errLon := strconv.ParseFloat(p.Lon, 64)
+		if errLat != nil || errLon != nil {
+			continue
+		}
+		m := domain.GeocodeMatch{Label: p.DisplayName, Coordinate: domain.NewWGS84Coordinate(lon, lat)}
+		if bb, ok := parseFloats(p.BoundingBox); ok && len(bb) == 4 {
+			m.Extent = domain.Extent{MinX: bb[2], MinY: bb[0], MaxX: bb[3], MaxY: bb[1], SRID: domain.SRIDWGS84}
+		}
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
+
+// License returns the license of OpenStreetMap data, unless overridden.
+func (n *Nominatim) License() domain.License { return n.license }
+
+func parseFloats(ss []string) ([]float64, bool) {
+	out := make([]float64, len(ss))
+	for i, s := range ss {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, false
+		}
+		out[i] = f
+	}
+	return out, true
+}
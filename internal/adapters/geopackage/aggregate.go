@@ -0,0 +1,221 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// Repository implements output.Aggregator.
+var _ output.Aggregator = (*Repository)(nil)
+
+// Aggregate counts and summarizes the features of a layer in SQL: one query
+// for the total and the numeric statistics, one for the counts per GroupBy
+// value. With an area the rows are selected like in QueryArea. On polygon
+// layers the fragments of an ST_Subdivide-tiled feature count once, as
+// QueryPoint returns them once: the rows are made DISTINCT over their
+// properties. Properties are named as PropertyMappings expose them; one the
+// layer lacks is left out of the result.
+func (r *Repository) Aggregate(ctx context.Context, sourceID, layerName string, q domain.AggregateQuery) (domain.LayerAggregate, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository.Aggregate",
+		output.WithSpanKind(output.SpanKindClient),
+		output.WithAttributes(
+			output.String("db.system", "sqlite"),
+			output.String("ortus.source.id", sourceID),
+			output.String("ortus.layer.name", layerName),
+		),
+	)
+	defer span.End()
+
+	rel := q.Relation
+	if rel == "" {
+		rel = domain.RelationIntersects
+	}
+	if _, ok := areaPredicates[rel]; !ok {
+		err := fmt.Errorf("area relation %q: %w", rel, domain.ErrInvalidInput)
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "unknown relation")
+		return domain.LayerAggregate{}, err
+	}
+
+	r.mu.RLock()
+	db, ok := r.connections[sourceID]
+	src := r.sources[sourceID]
+	r.mu.RUnlock()
+	if !ok {
+		span.RecordError(domain.ErrSourceNotFound)
+		span.SetStatus(output.StatusError, "source not found")
+		return domain.LayerAggregate{}, domain.ErrSourceNotFound
+	}
+	layer, found := src.GetLayer(layerName)
+	if !found {
+		span.RecordError(domain.ErrLayerNotFound)
+		span.SetStatus(output.StatusError, "layer not found")
+		return domain.LayerAggregate{}, domain.ErrLayerNotFound
+	}
+
+	columns, err := tableColumns(ctx, db, layer.Name)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "columns failed")
+		return domain.LayerAggregate{}, err
+	}
+	props := propertyColumns(columns, layer.GeometryColumn)
+
+	var args []interface{}
+	from := fmt.Sprintf(`"%s" t`, layer.Name)
+	if q.Area != nil {
+		indexTable := rtreeName(layer.Name, layer.GeometryColumn)
+		indexed := tableExists(ctx, db, indexTable)
+		span.SetAttributes(output.Bool("ortus.rtree.used", indexed))
+		from += " WHERE " + areaFilter(layer, indexTable, indexed, rel, r.repairGeometries(layer) && layer.IsPolygonLayer())
+		args = areaArgs(layer, *q.Area, indexed)
+	}
+	matched := matchedRowsCTE(props, from, layer.IsPolygonLayer())
+
+	agg := domain.LayerAggregate{Layer: layer.Name}
+	var stats []string // resolved columns of agg.Stats, in order
+	for _, name := range q.Stats {
+		if col, ok := propertyColumn(props, layer.PropertyMappings, name); ok {
+			stats = append(stats, col)
+			agg.Stats = append(agg.Stats, domain.PropertyStats{Property: name})
+		}
+	}
+	query := buildAggregateQuery(matched, stats)
+	span.SetAttributes(output.String("db.statement", query))
+	if err := scanAggregate(db.QueryRowContext(ctx, query, args...), &agg); err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "query failed")
+		return domain.LayerAggregate{}, &domain.QueryError{Layer: layer.Name, Err: err}
+	}
+
+	if col, ok := propertyColumn(props, layer.PropertyMappings, q.GroupBy); ok && q.GroupBy != "" {
+		if err := r.aggregateGroups(ctx, db, layer, matched, col, args, q.MaxGroups, &agg); err != nil {
+			span.RecordError(err)
+			span.SetStatus(output.StatusError, "group query failed")
+			return domain.LayerAggregate{}, &domain.QueryError{Layer: layer.Name, Err: err}
+		}
+	}
+	span.SetAttributes(output.Int64("ortus.aggregate.count", agg.Count))
+	return agg, nil
+}
+
+// aggregateGroups fills agg.Groups with the counts per value of col, largest
+// first, at most maxGroups (0: all) of them.
+func (r *Repository) aggregateGroups(ctx context.Context, db *sql.DB, layer *domain.Layer, matched, col string, args []interface{}, maxGroups int, agg *domain.LayerAggregate) error {
+	limit := -1
+	if maxGroups > 0 {
+		limit = maxGroups + 1 // one more tells whether groups were cut off
+	}
+	query := matched + fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*) AS n FROM m GROUP BY %[1]s ORDER BY n DESC, %[1]s LIMIT ?
+	`, quoteIdent(col)) //#nosec G201 -- column from pragma_table_info, quoted
+	rows, err := db.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var mapping *domain.PropertyMapping
+	if m := layer.PropertyMappings.ForColumns([]string{col}); m != nil {
+		mapping = m[0]
+	}
+	for rows.Next() {
+		var g domain.GroupCount
+		if err := rows.Scan(&g.Value, &g.Count); err != nil {
+			return err
+		}
+		if b, ok := g.Value.([]byte); ok {
+			g.Value = string(b)
+		}
+		if mapping != nil && g.Value != nil {
+			if _, v, ok := mapping.Map(col, g.Value); ok {
+				g.Value = v
+			}
+		}
+		if maxGroups > 0 && len(agg.Groups) == maxGroups {
+			agg.GroupsTruncated = true
+			break
+		}
+		agg.Groups = append(agg.Groups, g)
+	}
+	return rows.Err()
+}
+
+// propertyColumns returns the columns of a feature table that are feature
+// properties: all but the fid and the geometry, as buildFeature reads them.
+func propertyColumns(columns []string, geomColumn string) []string {
+	out := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if c != "fid" && c != geomColumn {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// propertyColumn resolves a property name, as the layer's mappings expose
+// it, to its column; ok is false when the layer has no such property.
+func propertyColumn(props []string, mappings domain.PropertyMappings, name string) (string, bool) {
+	for _, c := range props {
+		if strings.EqualFold(mappings.Rename(c), name) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// matchedRowsCTE is the WITH clause naming the aggregated rows m: the
+// property columns of the rows from selects, DISTINCT when dedup is set so
+// that fragments of a tiled polygon count once.
+func matchedRowsCTE(props []string, from string, dedup bool) string {
+	if len(props) == 0 {
+		return fmt.Sprintf(`WITH m AS (SELECT t.rowid FROM %s)`, from)
+	}
+	cols := make([]string, len(props))
+	for i, c := range props {
+		cols[i] = "t." + quoteIdent(c)
+	}
+	distinct := ""
+	if dedup {
+		distinct = "DISTINCT "
+	}
+	return fmt.Sprintf(`WITH m AS (SELECT %s%s FROM %s)`, distinct, strings.Join(cols, ", "), from)
+}
+
+// buildAggregateQuery builds the query for the total count and, per column
+// of stats, the count, min, max and avg of its numeric values.
+func buildAggregateQuery(matched string, stats []string) string {
+	var b strings.Builder
+	b.WriteString(matched)
+	b.WriteString("\n\t\tSELECT COUNT(*)")
+	for _, c := range stats {
+		num := fmt.Sprintf(`CASE WHEN typeof(%[1]s) IN ('integer', 'real') THEN %[1]s END`, quoteIdent(c))
+		fmt.Fprintf(&b, ", COUNT(%[1]s), MIN(%[1]s), MAX(%[1]s), AVG(%[1]s)", num)
+	}
+	b.WriteString(" FROM m")
+	return b.String()
+}
+
+// scanAggregate scans the row of buildAggregateQuery into agg, whose Stats
+// are already laid out in the query's order.
+func scanAggregate(row *sql.Row, agg *domain.LayerAggregate) error {
+	dest := []interface{}{&agg.Count}
+	nums := make([]sql.NullFloat64, 3*len(agg.Stats))
+	for i := range agg.Stats {
+		dest = append(dest, &agg.Stats[i].Count, &nums[3*i], &nums[3*i+1], &nums[3*i+2])
+	}
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	for i := range agg.Stats {
+		agg.Stats[i].Min = nums[3*i].Float64
+		agg.Stats[i].Max = nums[3*i+1].Float64
+		agg.Stats[i].Avg = nums[3*i+2].Float64
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package geopackage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// newAggregateRepository opens a package with a polygon layer of districts,
+// two rows of which are fragments of one tiled feature.
+func newAggregateRepository(t *testing.T, mappings domain.PropertyMappings) *Repository {
+	t.Helper()
+	db := openPlainSQLite(t,
+		"CREATE TABLE districts (fid INTEGER PRIMARY KEY, geom BLOB, name TEXT, kind TEXT, pop INTEGER)",
+		`INSERT INTO districts (geom, name, kind, pop) VALUES
+			(NULL, 'Mitte', 'BZ', 100),
+			(NULL, 'Pankow', 'BZ', 300),
+			(NULL, 'Pankow', 'BZ', 300),
+			(NULL, 'Buch', 'OT', 'n/a'),
+			(NULL, 'Karow', NULL, 20)`,
+	)
+	r := NewRepository(Options{})
+	r.connections["berlin"] = db
+	r.sources["berlin"] = &domain.Source{ID: "berlin", Layers: []domain.Layer{{
+		Name: "districts", GeometryColumn: "geom", GeometryType: "MULTIPOLYGON", SRID: 4326,
+		PropertyMappings: mappings,
+	}}}
+	return r
+}
+
+func TestRepositoryAggregate(t *testing.T) {
+	r := newAggregateRepository(t, nil)
+
+	agg, err := r.Aggregate(context.Background(), "berlin", "districts", domain.AggregateQuery{
+		GroupBy: "kind",
+		Stats:   []string{"pop", "missing"},
+	})
+	if err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	// The two Pankow fragments count once.
+	if agg.Count != 4 {
+		t.Errorf("Count = %d, want 4", agg.Count)
+	}
+	if len(agg.Groups) != 3 || agg.Groups[0].Value != "BZ" || agg.Groups[0].Count != 2 || agg.GroupsTruncated {
+		t.Errorf("Groups = %+v, want BZ:2 first of 3", agg.Groups)
+	}
+	// "n/a" is not numeric; "missing" is no property of the layer.
+	if len(agg.Stats) != 1 {
+		t.Fatalf("Stats = %+v, want pop only", agg.Stats)
+	}
+	if s := agg.Stats[0]; s.Property != "pop" || s.Count != 3 || s.Min != 20 || s.Max != 300 || s.Avg != 140 {
+		t.Errorf("Stats[0] = %+v, want pop 3 values 20..300 avg 140", s)
+	}
+}
+
+func TestRepositoryAggregateMappedAndTruncated(t *testing.T) {
+	r := newAggregateRepository(t, domain.PropertyMappings{{Column: "kind", Name: "type"}})
+
+	agg, err := r.Aggregate(context.Background(), "berlin", "districts", domain.AggregateQuery{
+		GroupBy:   "type",
+		MaxGroups: 1,
+	})
+	if err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	if len(agg.Groups) != 1 || agg.Groups[0].Value != "BZ" || !agg.GroupsTruncated {
+		t.Errorf("Groups = %+v truncated=%v, want only BZ, truncated", agg.Groups, agg.GroupsTruncated)
+	}
+
+	// The column name is not how the mapping exposes the property.
+	agg, err = r.Aggregate(context.Background(), "berlin", "districts", domain.AggregateQuery{GroupBy: "kind"})
+	if err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	if agg.Groups != nil {
+		t.Errorf("Groups = %+v, want none for an unexposed name", agg.Groups)
+	}
+}
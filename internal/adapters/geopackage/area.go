@@ -0,0 +1,116 @@
+package geopackage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// Repository implements output.AreaQuerier.
+var _ output.AreaQuerier = (*Repository)(nil)
+
+// areaPredicates maps a relation to the SpatiaLite predicate testing it,
+// the feature geometry first.
+var areaPredicates = map[domain.SpatialRelation]string{
+	domain.RelationIntersects: "ST_Intersects",
+	domain.RelationWithin:     "ST_Within",
+	domain.RelationContains:   "ST_Contains",
+}
+
+// QueryArea returns the features of a layer in relation rel to the area, in
+// one query: the R-tree pre-filters candidates by the area's bbox, the
+// relation's predicate confirms them against the polygon. Without an R-tree
+// it scans the table. Fragments of ST_Subdivide-tiled polygons are
+// deduplicated like in QueryPoint; under "contains" a tiled feature only
+// matches when one of its fragments contains the whole area. The area must
+// already be in the layer's SRID.
+func (r *Repository) QueryArea(ctx context.Context, sourceID, layerName string, area domain.Area, rel domain.SpatialRelation) ([]domain.Feature, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository.QueryArea",
+		output.WithSpanKind(output.SpanKindClient),
+		output.WithAttributes(
+			output.String("db.system", "sqlite"),
+			output.String("ortus.source.id", sourceID),
+			output.String("ortus.layer.name", layerName),
+			output.Int("ortus.area.points", area.Points()),
+			output.String("ortus.area.relation", string(rel)),
+		),
+	)
+	defer span.End()
+
+	if _, ok := areaPredicates[rel]; !ok {
+		err := fmt.Errorf("area relation %q: %w", rel, domain.ErrInvalidInput)
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "unknown relation")
+		return nil, err
+	}
+
+	r.mu.RLock()
+	db, ok := r.connections[sourceID]
+	src := r.sources[sourceID]
+	r.mu.RUnlock()
+	if !ok {
+		span.RecordError(domain.ErrSourceNotFound)
+		span.SetStatus(output.StatusError, "source not found")
+		return nil, domain.ErrSourceNotFound
+	}
+	layer, found := src.GetLayer(layerName)
+	if !found {
+		span.RecordError(domain.ErrLayerNotFound)
+		span.SetStatus(output.StatusError, "layer not found")
+		return nil, domain.ErrLayerNotFound
+	}
+
+	indexTable := rtreeName(layer.Name, layer.GeometryColumn)
+	indexed := tableExists(ctx, db, indexTable)
+	span.SetAttributes(output.Bool("ortus.rtree.used", indexed))
+	query := buildAreaQuery(layer, indexTable, indexed, rel, r.repairGeometries(layer) && layer.IsPolygonLayer(), r.decimals(layer))
+	span.SetAttributes(output.String("db.statement", query))
+
+	args := areaArgs(layer, area, indexed)
+	return r.queryShapeFeatures(ctx, span, db, layer, query, args, rel.MatchKind())
+}
+
+// buildAreaQuery builds the area query of layer for rel; its parameters are
+// areaArgs.
+func buildAreaQuery(layer *domain.Layer, indexTable string, indexed bool, rel domain.SpatialRelation, repair bool, decimals int) string {
+	return fmt.Sprintf(`
+		SELECT t.*, %[3]s
+		FROM "%[1]s" t
+		WHERE %[2]s
+	`, layer.Name, areaFilter(layer, indexTable, indexed, rel, repair),
+		computedSelect(layer)+geometrySelect(layer.GeometryColumn, domain.GeometryOptions{}, decimals),
+	) //#nosec G201 -- identifiers from gpkg catalog, double-quoted; SQLite can't parameterize identifiers
+}
+
+// areaFilter is the condition selecting the rows of layer (aliased t) in
+// relation rel to an area. Indexed, its first parameters are the area's bbox
+// as minx, maxx, miny, maxy; the area WKT and the layer's stored SRID follow
+// (see areaArgs).
+func areaFilter(layer *domain.Layer, indexTable string, indexed bool, rel domain.SpatialRelation, repair bool) string {
+	predicate := fmt.Sprintf(`%s(%s, GeomFromText(?, ?))`,
+		areaPredicates[rel], coveringGeometry("t", layer.GeometryColumn, repair))
+	if !indexed {
+		return predicate
+	}
+	// A feature containing the area has a bbox covering the area's; for the
+	// other relations the bboxes overlap. (The R-tree rounds its boxes
+	// outward, which would cut off features sharing an edge with the area's
+	// bbox if "within" asked for the bbox to be inside.)
+	bbox := `r.minx <= ? AND r.maxx >= ? AND r.miny <= ? AND r.maxy >= ?`
+	if rel != domain.RelationContains {
+		bbox = `r.maxx >= ? AND r.minx <= ? AND r.maxy >= ? AND r.miny <= ?`
+	}
+	return fmt.Sprintf(`t.rowid IN (SELECT r.id FROM "%s" r WHERE %s) AND %s`, indexTable, bbox, predicate)
+}
+
+// areaArgs are the parameters of areaFilter.
+func areaArgs(layer *domain.Layer, area domain.Area, indexed bool) []interface{} {
+	var args []interface{}
+	if indexed {
+		e := area.Extent()
+		args = append(args, e.MinX, e.MaxX, e.MinY, e.MaxY)
+	}
+	return append(args, area.WKT(), layer.StoredSRID())
+}
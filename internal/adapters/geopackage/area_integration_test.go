@@ -0,0 +1,56 @@
+package geopackage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// TestQueryArea: each relation selects the regions of the fixture it should,
+// with and without the R-tree prefilter.
+func TestQueryArea(t *testing.T) {
+	tests := []struct {
+		name string
+		wkt  string
+		rel  domain.SpatialRelation
+		want []string
+	}{
+		// Overlaps "west" and reaches into "east".
+		{"intersects", "POLYGON((3 1, 7 1, 7 3, 3 3, 3 1))", domain.RelationIntersects, []string{"west", "east"}},
+		// Covers "west" whole and "east" in part.
+		{"within", "POLYGON((-1 -1, 8 -1, 8 5, -1 5, -1 -1))", domain.RelationWithin, []string{"west"}},
+		// Lies inside "east".
+		{"contains", "POLYGON((7 1, 9 1, 9 3, 7 3, 7 1))", domain.RelationContains, []string{"east"}},
+		// Lies in the gap between "west" and "east".
+		{"miss", "POLYGON((4.5 1, 5.5 1, 5.5 3, 4.5 3, 4.5 1))", domain.RelationIntersects, nil},
+	}
+	for _, indexed := range []bool{false, true} {
+		repo, _ := newFixtureRepo(t)
+		ctx := context.Background()
+		if indexed {
+			if err := repo.CreateSpatialIndex(ctx, "regions", "regions"); err != nil {
+				t.Fatalf("CreateSpatialIndex: %v", err)
+			}
+		}
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s/indexed=%v", tt.name, indexed), func(t *testing.T) {
+				area, err := domain.ParseAreaWKT(tt.wkt, 4326)
+				if err != nil {
+					t.Fatalf("ParseAreaWKT: %v", err)
+				}
+				features, err := repo.QueryArea(ctx, "regions", "regions", area, tt.rel)
+				if err != nil {
+					t.Fatalf("QueryArea (indexed=%v): %v", indexed, err)
+				}
+				assertFeatureNames(t, features, tt.want)
+				for _, f := range features {
+					if f.Match.Kind != tt.rel.MatchKind() {
+						t.Errorf("match kind = %q, want %q", f.Match.Kind, tt.rel.MatchKind())
+					}
+				}
+			})
+		}
+	}
+}
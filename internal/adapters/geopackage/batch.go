@@ -67,14 +67,14 @@ func (r *Repository) QueryPoints(ctx context.Context, sourceID, layerName string
 		span.SetStatus(output.StatusError, "marshal points failed")
 		return nil, err
 	}
-	query := buildBatchPointQuery(layer, indexTable)
+	query := buildBatchPointQuery(layer, indexTable, r.repairGeometries(layer))
 	span.SetAttributes(output.String("db.statement", query))
 
-	// Polygon layers bind the layer SRID for the ST_Covers MakePoint; non-polygon
+	// Polygon layers bind the stored SRID for the ST_Covers MakePoint; non-polygon
 	// (bbox-only) layers don't. Build the args once so there's no extra branch here.
 	args := []interface{}{jsonPts}
 	if layer.IsPolygonLayer() {
-		args = append(args, layer.SRID)
+		args = append(args, layer.StoredSRID())
 	}
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -84,7 +84,7 @@ func (r *Repository) QueryPoints(ctx context.Context, sourceID, layerName string
 	}
 	defer func() { _ = rows.Close() }()
 
-	if err := scanBatchRows(rows, layer, out); err != nil {
+	if err := r.scanBatchRows(rows, layer, out); err != nil {
 		span.RecordError(err)
 		span.SetStatus(output.StatusError, "scan failed")
 		return nil, err
@@ -114,7 +114,7 @@ func (r *Repository) queryPointsUnindexed(ctx context.Context, db *sql.DB, layer
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
-		feats, err := r.executePointQuery(ctx, db, layer, c)
+		feats, err := r.executePointQuery(ctx, db, layer, c, domain.GeometryOptions{}, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -140,31 +140,36 @@ func marshalPointsJSON(coords []domain.Coordinate) (string, error) {
 // buildBatchPointQuery builds the set-based query: json_each unrolls the points,
 // the R-tree bbox-prefilters candidates per point, then ST_Covers (polygon layers)
 // confirms. The leading je.idx column maps each row back to its input coordinate.
-func buildBatchPointQuery(layer *domain.Layer, indexTable string) string {
+// repair repairs invalid geometries before the predicate (see coveringGeometry).
+func buildBatchPointQuery(layer *domain.Layer, indexTable string, repair bool) string {
 	// %[1]$s = geom column, %[2]$s = rtree table, %[3]$s = layer table, %[4]$s = the
-	// polygon-only ST_Covers predicate (empty for non-polygon = bbox match only).
+	// computed properties (an argument, as an expression may contain %), %[5]$s = the
+	// geometry the predicate tests. covers is the polygon-only ST_Covers predicate
+	// (empty for non-polygon = bbox match only).
 	covers := ""
 	if layer.IsPolygonLayer() {
-		covers = `WHERE ST_Covers(CastAutomagic(t."%[1]s"), MakePoint(je.x, je.y, ?))`
+		covers = `WHERE ST_Covers(%[5]s, MakePoint(je.x, je.y, ?))`
 	}
 	return fmt.Sprintf(`
-		SELECT je.idx, t.*, AsText(CastAutomagic(t."%[1]s"))
+		SELECT je.idx, t.*, %[4]sAsText(CastAutomagic(t."%[1]s"))
 		FROM (SELECT key AS idx, CAST(value->>'x' AS REAL) AS x, CAST(value->>'y' AS REAL) AS y FROM json_each(?)) je
 		INNER JOIN "%[2]s" r ON r.minx <= je.x AND r.maxx >= je.x AND r.miny <= je.y AND r.maxy >= je.y
 		INNER JOIN "%[3]s" t ON t.rowid = r.id
 		`+covers+`
 		ORDER BY je.idx
-	`, layer.GeometryColumn, indexTable, layer.Name) //#nosec G201 -- identifiers from gpkg catalog, double-quoted; SQLite can't parameterize identifiers
+	`, layer.GeometryColumn, indexTable, layer.Name, computedSelect(layer),
+		coveringGeometry("t", layer.GeometryColumn, repair)) //#nosec G201 -- identifiers from gpkg catalog, double-quoted; SQLite can't parameterize identifiers
 }
 
 // scanBatchRows scans the (idx, feature…) rows and buckets each feature into
 // out[idx], reusing buildFeature for the per-row mapping.
-func scanBatchRows(rows *sql.Rows, layer *domain.Layer, out [][]domain.Feature) error {
+func (r *Repository) scanBatchRows(rows *sql.Rows, layer *domain.Layer, out [][]domain.Feature) error {
 	columns, err := rows.Columns()
 	if err != nil {
 		return err
 	}
 	featCols := columns[1:] // drop the leading idx column; rest is what buildFeature expects
+	reader := r.featureReader(layer, featCols)
 	// Allocate the scan buffers once and reuse them per row: buildFeature copies each
 	// value into the feature's property map before the next Scan overwrites the
 	// buffer, so sharing is safe and drops a per-row allocation from the hot batch
@@ -185,7 +190,7 @@ func scanBatchRows(rows *sql.Rows, layer *domain.Layer, out [][]domain.Feature)
 		if idx < 0 || int(idx) >= len(out) {
 			continue // defensive: json_each key out of range shouldn't happen
 		}
-		out[idx] = append(out[idx], buildFeature(featCols, vals[1:], layer.Name, layer.GeometryColumn))
+		out[idx] = append(out[idx], buildFeature(featCols, vals[1:], layer.Name, layer.GeometryColumn, reader))
 	}
 	return rows.Err()
 }
@@ -138,7 +138,7 @@ func BenchmarkBatchSpatialSerial(b *testing.B) {
 	pts := genPoints()
 	repo := NewRepository(Options{})
 	ctx := context.Background()
-	src, err := repo.Open(ctx, path)
+	src, err := repo.Open(ctx, domain.DeriveSourceID(path), path)
 	if err != nil {
 		b.Skipf("open: %v", err)
 	}
@@ -166,7 +166,7 @@ func BenchmarkBatchSpatialConcurrent(b *testing.B) {
 	pts := genPoints()
 	repo := NewRepository(Options{})
 	ctx := context.Background()
-	src, err := repo.Open(ctx, path)
+	src, err := repo.Open(ctx, domain.DeriveSourceID(path), path)
 	if err != nil {
 		b.Skipf("open: %v", err)
 	}
@@ -0,0 +1,53 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// explainPointQuery fills in what the point query on layer did beyond its
+// results: the SQL, SQLite's plan for it and the rows the spatial predicate
+// was evaluated on. It runs after the query, so the extra statements do not
+// count towards the measured SQL time. Failures leave the fields empty; an
+// explanation is best effort and never fails the query.
+func explainPointQuery(ctx context.Context, db *sql.DB, explain *domain.LayerExplain, layer *domain.Layer, rtree bool, indexTable, query string, args []interface{}, coord domain.Coordinate) {
+	explain.SQL = strings.Join(strings.Fields(query), " ")
+	explain.RTree = rtree
+
+	if rows, err := db.QueryContext(ctx, explainSQL(query), args...); err == nil {
+		for rows.Next() {
+			var id, parent, notUsed int
+			var detail string
+			if rows.Scan(&id, &parent, &notUsed, &detail) == nil {
+				explain.Plan = append(explain.Plan, detail)
+			}
+		}
+		_ = rows.Close()
+	}
+
+	if rtree {
+		_ = db.QueryRowContext(ctx, rtreeCountSelect(indexTable), coord.X, coord.X, coord.Y, coord.Y).Scan(&explain.RowsExamined)
+	} else {
+		_ = db.QueryRowContext(ctx, tableSelect("COUNT(*)", layer.Name, nil, false)).Scan(&explain.RowsExamined)
+	}
+}
+
+// explainSQL prefixes query with EXPLAIN QUERY PLAN.
+func explainSQL(query string) string {
+	var b strings.Builder
+	b.WriteString("EXPLAIN QUERY PLAN ")
+	b.WriteString(query)
+	return b.String()
+}
+
+// rtreeCountSelect counts the entries of an R-tree whose box contains the
+// point bound as x, x, y, y — the candidates the point query pre-filters to.
+func rtreeCountSelect(indexTable string) string {
+	var b strings.Builder
+	b.WriteString(tableSelect("COUNT(*)", indexTable, nil, false))
+	b.WriteString(" WHERE minx <= ? AND maxx >= ? AND miny <= ? AND maxy >= ?")
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package geopackage
+
+import (
+	"bytes"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// Handling of BLOB properties (Options.Blobs).
+const (
+	BlobBase64   = "base64"   // returned as they are, base64-encoded in JSON
+	BlobTruncate = "truncate" // cut to Options.BlobMaxBytes
+	BlobExclude  = "exclude"  // left out
+)
+
+// featureReader turns the column values of a row into feature properties
+// and rounds the geometry and distance. The zero value stores every non-NULL
+// value as it is and rounds nothing.
+type featureReader struct {
+	// mappings holds each column's mapping, nil where a column has none (see
+	// domain.PropertyMappings.ForColumns); nil maps no column.
+	mappings []*domain.PropertyMapping
+	blobs    string
+	blobMax  int
+	// round enables rounding the WKT to decimals and distances, in metres,
+	// to measureDecimals.
+	round           bool
+	decimals        int
+	measureDecimals int
+}
+
+// featureReader returns the reader of the columns of a query on layer.
+func (r *Repository) featureReader(layer *domain.Layer, columns []string) featureReader {
+	return featureReader{
+		mappings:        layer.PropertyMappings.ForColumns(columns),
+		blobs:           r.opts.Blobs,
+		blobMax:         r.opts.BlobMaxBytes,
+		round:           r.opts.Precision,
+		decimals:        r.decimals(layer),
+		measureDecimals: r.opts.ProjectedDecimals,
+	}
+}
+
+// decimals returns the number of decimals coordinates of layer are returned
+// with, or -1 to return them as stored.
+func (r *Repository) decimals(layer *domain.Layer) int {
+	switch {
+	case !r.opts.Precision:
+		return -1
+	case layer.Geographic:
+		return r.opts.GeographicDecimals
+	default:
+		return r.opts.ProjectedDecimals
+	}
+}
+
+func (p featureReader) roundWKT(wkt string) string {
+	if !p.round {
+		return wkt
+	}
+	return domain.RoundWKT(wkt, p.decimals)
+}
+
+func (p featureReader) roundMeasure(f float64) float64 {
+	if !p.round {
+		return f
+	}
+	return domain.RoundTo(f, p.measureDecimals)
+}
+
+// set stores the non-NULL value v of col, the i-th column, into props. A
+// mapped column is renamed and converted, a value the mapping cannot convert
+// left out; an unmapped BLOB ([]byte, the driver returns TEXT as string) is
+// handled as configured.
+func (p featureReader) set(props map[string]interface{}, i int, col string, v interface{}) {
+	if v == nil {
+		return
+	}
+	if p.mappings != nil && p.mappings[i] != nil {
+		if name, value, ok := p.mappings[i].Map(col, v); ok {
+			props[name] = value
+		}
+		return
+	}
+	if b, ok := v.([]byte); ok {
+		switch {
+		case p.blobs == BlobExclude:
+			return
+		case p.blobs == BlobTruncate && len(b) > p.blobMax:
+			v = bytes.Clone(b[:p.blobMax]) // not pinning the whole BLOB
+		}
+	}
+	props[col] = v
+}
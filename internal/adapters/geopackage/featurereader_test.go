@@ -0,0 +1,57 @@
+package geopackage
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func TestPropertyReaderBlobs(t *testing.T) {
+	blob := bytes.Repeat([]byte{0xff}, 10)
+	tests := []struct {
+		name   string
+		reader featureReader
+		want   map[string]interface{}
+	}{
+		{name: "default", reader: featureReader{}, want: map[string]interface{}{"name": "a", "photo": blob}},
+		{name: "base64", reader: featureReader{blobs: BlobBase64}, want: map[string]interface{}{"name": "a", "photo": blob}},
+		{name: "truncate", reader: featureReader{blobs: BlobTruncate, blobMax: 4}, want: map[string]interface{}{"name": "a", "photo": blob[:4]}},
+		{name: "truncate short", reader: featureReader{blobs: BlobTruncate, blobMax: 64}, want: map[string]interface{}{"name": "a", "photo": blob}},
+		{name: "exclude", reader: featureReader{blobs: BlobExclude}, want: map[string]interface{}{"name": "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := make(map[string]interface{})
+			tt.reader.set(props, 0, "name", "a")
+			tt.reader.set(props, 1, "photo", blob)
+			tt.reader.set(props, 2, "empty", nil)
+			if !reflect.DeepEqual(props, tt.want) {
+				t.Errorf("properties = %v, want %v", props, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeatureReaderRounds(t *testing.T) {
+	repo := NewRepository(Options{Precision: true, GeographicDecimals: 6, ProjectedDecimals: 2})
+	geographic := repo.featureReader(&domain.Layer{Geographic: true}, nil)
+	projected := repo.featureReader(&domain.Layer{}, nil)
+
+	if got := geographic.roundWKT("POINT(10.123456789 50.5)"); got != "POINT(10.123457 50.5)" {
+		t.Errorf("geographic WKT = %q", got)
+	}
+	if got := projected.roundWKT("POINT(512345.6789 5432109.1)"); got != "POINT(512345.68 5432109.1)" {
+		t.Errorf("projected WKT = %q", got)
+	}
+	// Distances are metres whatever the layer CRS.
+	if got := geographic.roundMeasure(12.3456); got != 12.35 {
+		t.Errorf("distance = %v, want 12.35", got)
+	}
+
+	off := NewRepository(Options{}).featureReader(&domain.Layer{Geographic: true}, nil)
+	if got := off.roundWKT("POINT(10.123456789 50.5)"); got != "POINT(10.123456789 50.5)" {
+		t.Errorf("WKT without precision = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,228 @@
+package geopackage
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// isoMetadata is the part of an ISO 19139 (gmd:MD_Metadata) record ortus
+// reads. Elements are matched by local name, so the gmd/gco prefixes — and
+// the same-named elements of ISO 19115-3 (mdb/mri/cit) — do not matter.
+type isoMetadata struct {
+	XMLName        xml.Name
+	Identification []isoIdentification `xml:"identificationInfo>MD_DataIdentification"`
+}
+
+type isoIdentification struct {
+	Citation struct {
+		Title   isoText    `xml:"title"`
+		Edition isoText    `xml:"edition"`
+		Dates   []isoDate  `xml:"date>CI_Date"`
+		Parties []isoParty `xml:"citedResponsibleParty>CI_ResponsibleParty"`
+	} `xml:"citation>CI_Citation"`
+	Abstract    isoText            `xml:"abstract"`
+	Contacts    []isoParty         `xml:"pointOfContact>CI_ResponsibleParty"`
+	Keywords    []isoText          `xml:"descriptiveKeywords>MD_Keywords>keyword"`
+	Constraints []isoConstraintSet `xml:"resourceConstraints"`
+}
+
+// isoText is a gco:CharacterString, or a gmx:Anchor in its place.
+type isoText struct {
+	CharacterString string `xml:"CharacterString"`
+	Anchor          string `xml:"Anchor"`
+}
+
+func (t isoText) String() string {
+	if s := strings.TrimSpace(t.CharacterString); s != "" {
+		return s
+	}
+	return strings.TrimSpace(t.Anchor)
+}
+
+// isoCode is a code list value such as gmd:CI_RoleCode.
+type isoCode struct {
+	Value string `xml:"codeListValue,attr"`
+}
+
+type isoDate struct {
+	Date struct {
+		Date     string `xml:"Date"`
+		DateTime string `xml:"DateTime"`
+	} `xml:"date"`
+	Type isoCode `xml:"dateType>CI_DateTypeCode"`
+}
+
+type isoParty struct {
+	Individual   isoText `xml:"individualName"`
+	Organisation isoText `xml:"organisationName"`
+	Role         isoCode `xml:"role>CI_RoleCode"`
+}
+
+// isoConstraintSet holds one gmd:resourceConstraints, whatever its kind
+// (MD_Constraints, MD_LegalConstraints, MD_SecurityConstraints).
+type isoConstraintSet struct {
+	Items []struct {
+		UseLimitation     []isoText `xml:"useLimitation"`
+		AccessConstraints []isoCode `xml:"accessConstraints>MD_RestrictionCode"`
+		UseConstraints    []isoCode `xml:"useConstraints>MD_RestrictionCode"`
+		OtherConstraints  []isoText `xml:"otherConstraints"`
+	} `xml:",any"`
+}
+
+// creatorRoles rank the CI_RoleCodes that name a dataset's creator.
+var creatorRoles = []string{"originator", "author", "owner", "publisher", "pointOfContact"}
+
+// isoDateLayouts are the gco:Date / gco:DateTime forms seen in practice; a
+// DateTime without a zone is taken as UTC.
+var isoDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02", "2006-01", "2006"}
+
+// isXMLMetadata reports whether a gpkg_metadata row holds XML.
+func isXMLMetadata(mime, metadata string) bool {
+	switch strings.ToLower(strings.TrimSpace(strings.SplitN(mime, ";", 2)[0])) {
+	case "text/xml", "application/xml":
+		return true
+	}
+	return strings.HasPrefix(strings.TrimSpace(metadata), "<")
+}
+
+// parseISOMetadata decodes an ISO 19139 record into domain metadata. ok is
+// false for XML that is not an MD_Metadata record or does not parse.
+func parseISOMetadata(raw string) (md domain.Metadata, ok bool) {
+	var doc isoMetadata
+	if err := xml.Unmarshal([]byte(raw), &doc); err != nil || doc.XMLName.Local != "MD_Metadata" {
+		return domain.Metadata{}, false
+	}
+	for i := range doc.Identification {
+		id := &doc.Identification[i]
+		setIfEmpty(&md.Title, id.Citation.Title.String())
+		setIfEmpty(&md.Description, id.Abstract.String())
+		setIfEmpty(&md.Version, id.Citation.Edition.String())
+		if md.Creator == "" {
+			md.Creator = isoCreator(append(id.Citation.Parties, id.Contacts...))
+		}
+		applyISODates(&md, id.Citation.Dates)
+		for _, k := range id.Keywords {
+			if s := k.String(); s != "" && !md.HasKeyword(s) {
+				md.Keywords = append(md.Keywords, s)
+			}
+		}
+		md.Constraints = append(md.Constraints, isoConstraints(id.Constraints)...)
+	}
+	return md, true
+}
+
+// isoCreator picks the party of the highest-ranked creator role and returns
+// its organisation (or, failing that, individual) name.
+func isoCreator(parties []isoParty) string {
+	for _, role := range creatorRoles {
+		for _, p := range parties {
+			if p.Role.Value != role {
+				continue
+			}
+			if name := p.Organisation.String(); name != "" {
+				return name
+			}
+			if name := p.Individual.String(); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// isoConstraints flattens the constraint sets into display strings. The
+// restriction code "otherRestrictions" only points at otherConstraints and is
+// dropped.
+func isoConstraints(sets []isoConstraintSet) []string {
+	var out []string
+	for _, set := range sets {
+		for _, c := range set.Items {
+			out = appendTexts(out, c.UseLimitation)
+			for _, code := range append(c.AccessConstraints, c.UseConstraints...) {
+				if code.Value != "" && code.Value != "otherRestrictions" {
+					out = append(out, code.Value)
+				}
+			}
+			out = appendTexts(out, c.OtherConstraints)
+		}
+	}
+	return out
+}
+
+// appendTexts appends the non-empty texts to out.
+func appendTexts(out []string, texts []isoText) []string {
+	for _, t := range texts {
+		if s := t.String(); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// applyISODates sets CreatedAt from the creation date — the publication date
+// if there is none — and UpdatedAt from the latest revision.
+func applyISODates(md *domain.Metadata, dates []isoDate) {
+	var published time.Time
+	for _, d := range dates {
+		t, ok := parseISODate(d)
+		if !ok {
+			continue
+		}
+		switch d.Type.Value {
+		case "creation":
+			md.CreatedAt = t
+		case "publication":
+			published = t
+		case "revision":
+			if t.After(md.UpdatedAt) {
+				md.UpdatedAt = t
+			}
+		}
+	}
+	if md.CreatedAt.IsZero() {
+		md.CreatedAt = published
+	}
+}
+
+func parseISODate(d isoDate) (time.Time, bool) {
+	v := strings.TrimSpace(d.Date.DateTime)
+	if v == "" {
+		v = strings.TrimSpace(d.Date.Date)
+	}
+	for _, layout := range isoDateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func setIfEmpty(dst *string, v string) {
+	if *dst == "" {
+		*dst = v
+	}
+}
+
+// mergeMetadata fills the fields of dst that are still empty from md, and
+// adds md's keywords and constraints. Earlier records therefore win.
+func mergeMetadata(dst *domain.Metadata, md domain.Metadata) {
+	setIfEmpty(&dst.Title, md.Title)
+	setIfEmpty(&dst.Description, md.Description)
+	setIfEmpty(&dst.Creator, md.Creator)
+	setIfEmpty(&dst.Version, md.Version)
+	if dst.CreatedAt.IsZero() {
+		dst.CreatedAt = md.CreatedAt
+	}
+	if dst.UpdatedAt.IsZero() {
+		dst.UpdatedAt = md.UpdatedAt
+	}
+	for _, k := range md.Keywords {
+		if !dst.HasKeyword(k) {
+			dst.Keywords = append(dst.Keywords, k)
+		}
+	}
+	dst.Constraints = append(dst.Constraints, md.Constraints...)
+}
@@ -0,0 +1,128 @@
+package geopackage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+const isoSample = `<?xml version="1.0" encoding="UTF-8"?>
+<gmd:MD_Metadata xmlns:gmd="http://www.isotc211.org/2005/gmd" xmlns:gco="http://www.isotc211.org/2005/gco" xmlns:gmx="http://www.isotc211.org/2005/gmx">
+  <gmd:identificationInfo>
+    <gmd:MD_DataIdentification>
+      <gmd:citation>
+        <gmd:CI_Citation>
+          <gmd:title><gco:CharacterString>ALKIS Flurstücke Bayern</gco:CharacterString></gmd:title>
+          <gmd:date>
+            <gmd:CI_Date>
+              <gmd:date><gco:Date>2024-03-01</gco:Date></gmd:date>
+              <gmd:dateType><gmd:CI_DateTypeCode codeListValue="publication"/></gmd:dateType>
+            </gmd:CI_Date>
+          </gmd:date>
+          <gmd:date>
+            <gmd:CI_Date>
+              <gmd:date><gco:DateTime>2025-06-30T08:15:00Z</gco:DateTime></gmd:date>
+              <gmd:dateType><gmd:CI_DateTypeCode codeListValue="revision"/></gmd:dateType>
+            </gmd:CI_Date>
+          </gmd:date>
+          <gmd:edition><gco:CharacterString>2025.2</gco:CharacterString></gmd:edition>
+        </gmd:CI_Citation>
+      </gmd:citation>
+      <gmd:abstract><gco:CharacterString>Flurstücke des Liegenschaftskatasters.</gco:CharacterString></gmd:abstract>
+      <gmd:pointOfContact>
+        <gmd:CI_ResponsibleParty>
+          <gmd:individualName><gco:CharacterString>Servicestelle</gco:CharacterString></gmd:individualName>
+          <gmd:role><gmd:CI_RoleCode codeListValue="pointOfContact"/></gmd:role>
+        </gmd:CI_ResponsibleParty>
+      </gmd:pointOfContact>
+      <gmd:pointOfContact>
+        <gmd:CI_ResponsibleParty>
+          <gmd:organisationName><gco:CharacterString>LDBV Bayern</gco:CharacterString></gmd:organisationName>
+          <gmd:role><gmd:CI_RoleCode codeListValue="owner"/></gmd:role>
+        </gmd:CI_ResponsibleParty>
+      </gmd:pointOfContact>
+      <gmd:descriptiveKeywords>
+        <gmd:MD_Keywords>
+          <gmd:keyword><gco:CharacterString>Kataster</gco:CharacterString></gmd:keyword>
+          <gmd:keyword><gmx:Anchor xlink:href="https://inspire.ec.europa.eu/theme/cp">Flurstücke/Grundstücke</gmx:Anchor></gmd:keyword>
+        </gmd:MD_Keywords>
+      </gmd:descriptiveKeywords>
+      <gmd:resourceConstraints>
+        <gmd:MD_LegalConstraints>
+          <gmd:accessConstraints><gmd:MD_RestrictionCode codeListValue="otherRestrictions"/></gmd:accessConstraints>
+          <gmd:otherConstraints><gco:CharacterString>Datenlizenz Deutschland – Namensnennung – Version 2.0</gco:CharacterString></gmd:otherConstraints>
+        </gmd:MD_LegalConstraints>
+      </gmd:resourceConstraints>
+      <gmd:resourceConstraints>
+        <gmd:MD_Constraints>
+          <gmd:useLimitation><gco:CharacterString>Nicht für Vermessungszwecke</gco:CharacterString></gmd:useLimitation>
+        </gmd:MD_Constraints>
+      </gmd:resourceConstraints>
+    </gmd:MD_DataIdentification>
+  </gmd:identificationInfo>
+</gmd:MD_Metadata>`
+
+func TestParseISOMetadata(t *testing.T) {
+	md, ok := parseISOMetadata(isoSample)
+	if !ok {
+		t.Fatal("parseISOMetadata: not recognised")
+	}
+	if md.Title != "ALKIS Flurstücke Bayern" || md.Description != "Flurstücke des Liegenschaftskatasters." {
+		t.Errorf("title/abstract = %q / %q", md.Title, md.Description)
+	}
+	if md.Creator != "LDBV Bayern" {
+		t.Errorf("Creator = %q, want the owner over the point of contact", md.Creator)
+	}
+	if md.Version != "2025.2" {
+		t.Errorf("Version = %q", md.Version)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); !md.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want the publication date %v", md.CreatedAt, want)
+	}
+	if want := time.Date(2025, 6, 30, 8, 15, 0, 0, time.UTC); !md.UpdatedAt.Equal(want) {
+		t.Errorf("UpdatedAt = %v, want %v", md.UpdatedAt, want)
+	}
+	if len(md.Keywords) != 2 || !md.HasKeyword("Flurstücke/Grundstücke") {
+		t.Errorf("Keywords = %v", md.Keywords)
+	}
+	want := []string{"Datenlizenz Deutschland – Namensnennung – Version 2.0", "Nicht für Vermessungszwecke"}
+	if len(md.Constraints) != len(want) || md.Constraints[0] != want[0] || md.Constraints[1] != want[1] {
+		t.Errorf("Constraints = %q, want %q", md.Constraints, want)
+	}
+}
+
+func TestParseISOMetadataRejectsOtherXML(t *testing.T) {
+	for _, raw := range []string{`<gmd:MD_Metadata>`, `<dc:record><dc:title>x</dc:title></dc:record>`, `not xml`} {
+		if _, ok := parseISOMetadata(raw); ok {
+			t.Errorf("parseISOMetadata(%q) ok, want rejected", raw)
+		}
+	}
+}
+
+func TestApplyXMLMetadata(t *testing.T) {
+	src := &domain.Source{
+		Metadata: domain.Metadata{Description: "json description"},
+		Layers:   []domain.Layer{{Name: "parcels"}, {Name: "buildings", Description: "from gpkg_contents"}},
+	}
+
+	// Dataset-level: fills what is empty, keeps what the JSON row set.
+	applyXMLMetadata(src, isoSample, metadataScope{})
+	if src.Metadata.Description != "json description" || src.Metadata.Title != "ALKIS Flurstücke Bayern" {
+		t.Errorf("metadata = %+v", src.Metadata)
+	}
+
+	// Table-level: describes the layer, unless gpkg_contents already does.
+	applyXMLMetadata(src, isoSample, metadataScope{scope: "table", table: "parcels"})
+	applyXMLMetadata(src, isoSample, metadataScope{scope: "table", table: "buildings"})
+	if src.Layers[0].Description != "Flurstücke des Liegenschaftskatasters." || src.Layers[1].Description != "from gpkg_contents" {
+		t.Errorf("layers = %+v", src.Layers)
+	}
+
+	// Non-ISO XML is not dumped into the description.
+	bare := &domain.Source{}
+	applyXMLMetadata(bare, `<record>free text</record>`, metadataScope{})
+	if bare.Metadata.Description != "" {
+		t.Errorf("Description = %q, want empty", bare.Metadata.Description)
+	}
+}
@@ -0,0 +1,95 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// memorySeq numbers the in-memory copies, so their names never depend on a
+// source id or path.
+var memorySeq atomic.Uint64
+
+// openWithLoadMode opens the database at path as mode says. For
+// LoadModeMemory it also returns the connection that keeps the in-memory
+// copy alive; close it after the *sql.DB.
+func (r *Repository) openWithLoadMode(ctx context.Context, path string, mode domain.LoadMode) (*sql.DB, driver.Conn, error) {
+	switch mode {
+	case domain.LoadModeFile:
+		db, err := r.openDB(ctx, path)
+		return db, nil, err
+	case domain.LoadModeMmap:
+		db, err := openSpatiaLite(ctx, path, mmapOptions(r.opts, path))
+		return db, nil, err
+	case domain.LoadModeMemory:
+		return r.openInMemory(ctx, path)
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown load mode %q", domain.ErrInvalidInput, mode)
+	}
+}
+
+// mmapOptions returns opts with mmap_size raised to cover the whole file at
+// path (SQLite caps it at its compile-time SQLITE_MAX_MMAP_SIZE).
+func mmapOptions(opts Options, path string) Options {
+	if fi, err := os.Stat(path); err == nil {
+		if mb := int((fi.Size() + 1<<20 - 1) >> 20); mb > opts.MmapSizeMB {
+			opts.MmapSizeMB = mb
+		}
+	}
+	return opts
+}
+
+// openInMemory copies the database at path into an in-memory database with
+// SQLite's backup API and opens a pool onto the copy. The copy lives in the
+// memdb VFS under a name starting with "/", so every pooled connection shares
+// it; the returned connection keeps it alive while the pool's idle
+// connections come and go. Spatial indexes are built in the copy only, and
+// in read-only mode not at all.
+func (r *Repository) openInMemory(ctx context.Context, path string) (*sql.DB, driver.Conn, error) {
+	src, err := spatiaLiteDriver.Open(fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = src.Close() }()
+
+	opts := r.opts
+	opts.JournalMode, opts.MmapSizeMB = "", 0
+	dsn := fmt.Sprintf("file:/ortus-memory-%d?vfs=memdb&cache=private", memorySeq.Add(1))
+	if opts.BusyTimeoutMS > 0 {
+		dsn += fmt.Sprintf("&_busy_timeout=%d", opts.BusyTimeoutMS)
+	}
+	keep, err := spatiaLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := copyDatabase(keep.(*sqlite3.SQLiteConn), src.(*sqlite3.SQLiteConn)); err != nil {
+		_ = keep.Close()
+		return nil, nil, fmt.Errorf("copying into memory: %w", err)
+	}
+	db, err := openSpatiaLiteDSN(ctx, dsn, opts)
+	if err != nil {
+		_ = keep.Close()
+		return nil, nil, err
+	}
+	return db, keep, nil
+}
+
+// copyDatabase copies the main database of src into dst in one backup step.
+func copyDatabase(dst, src *sqlite3.SQLiteConn) error {
+	backup, err := dst.Backup("main", src, "main")
+	if err != nil {
+		return err
+	}
+	if _, err := backup.Step(-1); err != nil {
+		_ = backup.Finish()
+		return err
+	}
+	return backup.Finish()
+}
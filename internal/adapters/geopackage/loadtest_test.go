@@ -98,7 +98,7 @@ func setupLoadTest(b *testing.B) loadTestFixture {
 	repo := NewRepository(opts)
 	ctx := context.Background()
 
-	src, err := repo.Open(ctx, path)
+	src, err := repo.Open(ctx, domain.DeriveSourceID(path), path)
 	if err != nil {
 		// A missing SpatiaLite extension surfaces here; treat as a skip rather
 		// than a failure so the gate behaves like the integration tests.
@@ -0,0 +1,130 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+var _ output.SourceOptimizer = (*Repository)(nil)
+
+// ErrReadOnly is returned by the maintenance operations in read-only mode,
+// which writes nothing.
+var ErrReadOnly = errors.New("read-only mode writes nothing")
+
+// RebuildSpatialIndex drops and rebuilds the R-tree index of a layer in one
+// transaction, so concurrent queries see either the old or the new index.
+func (r *Repository) RebuildSpatialIndex(ctx context.Context, sourceID, layerName string) error {
+	ctx, span := r.tracer.Start(ctx, "Repository.RebuildSpatialIndex",
+		output.WithSpanKind(output.SpanKindClient),
+		output.WithAttributes(
+			output.String("db.system", "sqlite"),
+			output.String("ortus.source.id", sourceID),
+			output.String("ortus.layer.name", layerName),
+		),
+	)
+	defer span.End()
+
+	db, src, err := r.writableSource(sourceID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "source not writable")
+		return err
+	}
+	layer, found := src.GetLayer(layerName)
+	if !found {
+		span.RecordError(domain.ErrLayerNotFound)
+		span.SetStatus(output.StatusError, "layer not found")
+		return domain.ErrLayerNotFound
+	}
+
+	indexTable := rtreeName(layerName, layer.GeometryColumn)
+	err = withTx(ctx, db, func(tx *sql.Tx) error {
+		//nolint:gocritic // sprintfQuotedString: SQL identifiers need double quotes, not Go's %q
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS "%s"`, indexTable)); err != nil { //#nosec G201 G701 -- table name derived from validated layer metadata, double-quoted
+			return fmt.Errorf("dropping R-tree table: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, createRTreeSQL(indexTable)); err != nil {
+			return fmt.Errorf("creating R-tree table: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, populateRTreeSQL(indexTable, layer)); err != nil {
+			return fmt.Errorf("populating R-tree index: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		idxErr := &domain.IndexError{SourceID: sourceID, Layer: layerName, Err: err}
+		span.RecordError(idxErr)
+		span.SetStatus(output.StatusError, "rebuild failed")
+		return idxErr
+	}
+	return r.setLayerIndexStatus(sourceID, layerName, true)
+}
+
+// Analyze runs ANALYZE, so the query planner picks indexes by up-to-date
+// statistics.
+func (r *Repository) Analyze(ctx context.Context, sourceID string) error {
+	return r.execMaintenance(ctx, "Repository.Analyze", sourceID, "ANALYZE")
+}
+
+// Vacuum runs VACUUM, which rewrites the file without the free pages that
+// dropped and rebuilt indexes leave behind. It needs free disk space of
+// about the file's size and waits for running queries to finish.
+func (r *Repository) Vacuum(ctx context.Context, sourceID string) error {
+	return r.execMaintenance(ctx, "Repository.Vacuum", sourceID, "VACUUM")
+}
+
+func (r *Repository) execMaintenance(ctx context.Context, spanName, sourceID, statement string) error {
+	ctx, span := r.tracer.Start(ctx, spanName,
+		output.WithSpanKind(output.SpanKindClient),
+		output.WithAttributes(
+			output.String("db.system", "sqlite"),
+			output.String("db.statement", statement),
+			output.String("ortus.source.id", sourceID),
+		),
+	)
+	defer span.End()
+
+	db, _, err := r.writableSource(sourceID)
+	if err == nil {
+		_, err = db.ExecContext(ctx, statement)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "maintenance failed")
+		return err
+	}
+	return nil
+}
+
+// writableSource returns the connection and metadata of an open source the
+// maintenance operations may write to.
+func (r *Repository) writableSource(sourceID string) (*sql.DB, *domain.Source, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	db, ok := r.connections[sourceID]
+	if !ok {
+		return nil, nil, domain.ErrSourceNotFound
+	}
+	if r.opts.ReadOnly {
+		return nil, nil, ErrReadOnly
+	}
+	return db, r.sources[sourceID], nil
+}
+
+// withTx runs fn in a transaction on db, committing when it succeeds.
+func withTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,122 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// Hot-path benchmarks of the point query and the coordinate transformation
+// against the GeoPackage bundled for the gazetteer tests (European admin
+// boundaries and places), so they run anywhere SpatiaLite is installed —
+// unlike the env-gated load test, which needs a large file of your own.
+// They skip without SpatiaLite.
+//
+//	go test -run=^$ -bench='ExecutePointQuery|TransformCoordinate' -benchmem \
+//	    ./internal/adapters/geopackage/
+const (
+	sampleGPKG  = "../../app/testdata/gazetteer-fixture.gpkg"
+	sampleLayer = "admin_levels"
+)
+
+// sampleCoord lies in Nicosia, covered by several nested admin levels.
+var sampleCoord = domain.NewWGS84Coordinate(33.3823, 35.1856)
+
+var sinkFeatures []domain.Feature
+
+// openSample opens a copy of the sample GeoPackage (Prepare writes the R-tree
+// into it) and returns the connection and layer executePointQuery runs on.
+func openSample(b *testing.B) (*sql.DB, *domain.Layer) {
+	b.Helper()
+	data, err := os.ReadFile(sampleGPKG)
+	if err != nil {
+		b.Fatalf("read sample: %v", err)
+	}
+	path := filepath.Join(b.TempDir(), "sample.gpkg")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		b.Fatalf("copy sample: %v", err)
+	}
+
+	repo := NewRepository(Options{})
+	ctx := context.Background()
+	src, err := repo.Open(ctx, domain.DeriveSourceID(path), path)
+	if err != nil {
+		b.Skipf("open sample (SpatiaLite unavailable?): %v", err)
+	}
+	b.Cleanup(func() { _ = repo.Close(ctx, src.ID) })
+	if err := repo.Prepare(ctx, src.ID, sampleLayer); err != nil {
+		b.Fatalf("prepare index: %v", err)
+	}
+	layer, ok := src.GetLayer(sampleLayer)
+	if !ok {
+		b.Fatalf("sample has no layer %q", sampleLayer)
+	}
+	repo.mu.RLock()
+	db := repo.connections[src.ID]
+	repo.mu.RUnlock()
+	return db, layer
+}
+
+func benchmarkExecutePointQuery(b *testing.B, opts domain.GeometryOptions) {
+	db, layer := openSample(b)
+	repo := NewRepository(Options{})
+	ctx := context.Background()
+	features, err := repo.executePointQuery(ctx, db, layer, sampleCoord, opts, nil)
+	if err != nil {
+		b.Fatalf("query: %v", err)
+	}
+	if len(features) == 0 {
+		b.Fatalf("sample point matches no feature of %q", sampleLayer)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		features, err = repo.executePointQuery(ctx, db, layer, sampleCoord, opts, nil)
+		if err != nil {
+			b.Fatalf("query: %v", err)
+		}
+	}
+	sinkFeatures = features
+}
+
+// BenchmarkExecutePointQuery measures one R-tree pre-filtered ST_Covers query
+// with the default WKT geometry.
+func BenchmarkExecutePointQuery(b *testing.B) {
+	benchmarkExecutePointQuery(b, domain.GeometryOptions{})
+}
+
+// BenchmarkExecutePointQueryGeoJSON adds the AsGeoJSON encoding of every
+// matched geometry, the cost of geometry_format=geojson and format=geojson.
+func BenchmarkExecutePointQueryGeoJSON(b *testing.B) {
+	benchmarkExecutePointQuery(b, domain.GeometryOptions{Format: domain.GeometryFormatGeoJSON})
+}
+
+var sinkCoord domain.Coordinate
+
+// BenchmarkTransformCoordinate measures reprojecting a query point from WGS84
+// to UTM 32N, as done for every layer not stored in the query's SRID.
+func BenchmarkTransformCoordinate(b *testing.B) {
+	t, err := NewRepositoryTransformer(nil)
+	if err != nil {
+		b.Skipf("transformer (SpatiaLite unavailable?): %v", err)
+	}
+	b.Cleanup(func() { _ = t.Close() })
+	ctx := context.Background()
+	from := domain.NewWGS84Coordinate(9.9295, 49.7913)
+
+	var c domain.Coordinate
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c, err = t.Transform(ctx, from, 25832)
+		if err != nil {
+			b.Fatalf("transform: %v", err)
+		}
+	}
+	sinkCoord = c
+}
@@ -4,13 +4,17 @@ package geopackage
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/mattn/go-sqlite3"
 
@@ -18,11 +22,15 @@ import (
 	"github.com/jobrunner/ortus/internal/ports/output"
 )
 
+// spatiaLiteDriver is the sqlite3 driver with SpatiaLite loaded into every
+// connection. openSpatiaLite wraps it in a pragmaConnector.
+var spatiaLiteDriver = &sqlite3.SQLiteDriver{
+	Extensions: getSpatiaLiteLibraryPaths(),
+}
+
 // Ensure sqlite3 driver is registered with extension support.
 func init() {
-	sql.Register("sqlite3_with_extensions", &sqlite3.SQLiteDriver{
-		Extensions: getSpatiaLiteLibraryPaths(),
-	})
+	sql.Register("sqlite3_with_extensions", spatiaLiteDriver)
 }
 
 // getSpatiaLiteLibraryPaths returns a list of paths to try for loading SpatiaLite.
@@ -68,7 +76,8 @@ func getSpatiaLiteLibraryPaths() []string {
 }
 
 // Options tunes how SQLite databases are opened. The zero value is valid and
-// yields safe defaults (private cache, no busy timeout, unlimited connections).
+// yields safe defaults (private cache, no busy timeout, unlimited connections,
+// no verification on open).
 // The composition root maps config.SQLiteConfig onto this, so the adapter does
 // not import the config package.
 type Options struct {
@@ -77,6 +86,30 @@ type Options struct {
 	JournalMode   string // "" = leave file's mode; e.g. "WAL"
 	MaxOpenConns  int    // 0 = unlimited
 	MaxIdleConns  int    // <=0 = database/sql default
+	Verify        string // "" | "off" = none; "quick" | "full" (see verifyGeoPackage)
+	FallbackSRID  int    // assumed for layers declaring SRID 0 or -1; 0 = none
+	Blobs         string // BLOB properties: "" | BlobBase64 (as they are), BlobTruncate, BlobExclude
+	BlobMaxBytes  int    // bytes kept by BlobTruncate
+	// Precision rounds returned geometries (except WKB) and distances to
+	// GeographicDecimals for layers in degrees, ProjectedDecimals otherwise.
+	Precision          bool
+	GeographicDecimals int
+	ProjectedDecimals  int
+	// ReadOnly opens every file read-only and immutable, for packages on a
+	// volume shared with other instances (ReadOnlyMany); no spatial index is
+	// built, so layers need one already.
+	ReadOnly bool
+	// Per-connection pragmas (see pragmasFor); the zero values keep SQLite's
+	// defaults.
+	Synchronous string // "" | "OFF" | "NORMAL" | "FULL" | "EXTRA"
+	MmapSizeMB  int    // PRAGMA mmap_size in MiB; 0 = SQLite default (off)
+	CacheSizeMB int    // PRAGMA cache_size in MiB per connection; 0 = SQLite default
+	TempStore   string // "" | "DEFAULT" | "FILE" | "MEMORY"
+	// CheckValidity counts each layer's invalid geometries on open (see
+	// checkLayerValidity); MakeValid makes point queries repair them (see
+	// coveringGeometry).
+	CheckValidity bool
+	MakeValid     bool
 }
 
 // Repository implements the output.SpatialSource port using SpatiaLite.
@@ -87,6 +120,9 @@ type Repository struct {
 	sources     map[string]*domain.Source
 	tracer      output.Tracer
 	opts        Options
+	// inMemory holds a connection to each in-memory copy (LoadModeMemory),
+	// which SQLite frees with its last connection.
+	inMemory map[string]driver.Conn
 }
 
 // Supports reports whether this adapter can open the given path. The
@@ -108,6 +144,7 @@ func NewRepository(opts Options) *Repository {
 	return &Repository{
 		connections: make(map[string]*sql.DB),
 		sources:     make(map[string]*domain.Source),
+		inMemory:    make(map[string]driver.Conn),
 		tracer:      output.NoOpTracer{},
 		opts:        opts,
 	}
@@ -122,20 +159,27 @@ func (r *Repository) SetTracer(t output.Tracer) {
 	r.tracer = t
 }
 
-// Open opens a GeoPackage file and returns its metadata.
-func (r *Repository) Open(ctx context.Context, path string) (*domain.Source, error) {
+// Open opens a GeoPackage file under sourceID and returns its metadata.
+func (r *Repository) Open(ctx context.Context, sourceID, path string) (*domain.Source, error) {
+	return r.OpenWithLoadMode(ctx, sourceID, path, domain.LoadModeFile)
+}
+
+// OpenWithLoadMode opens a GeoPackage file under sourceID like Open, reading
+// it as mode says: LoadModeMmap memory-maps the whole file, LoadModeMemory
+// copies it into an in-memory database.
+func (r *Repository) OpenWithLoadMode(ctx context.Context, sourceID, path string, mode domain.LoadMode) (*domain.Source, error) {
 	ctx, span := r.tracer.Start(ctx, "Repository.Open",
-		output.WithAttributes(output.String("ortus.source.path", path)),
+		output.WithAttributes(
+			output.String("ortus.source.path", path),
+			output.String("ortus.source.id", sourceID),
+			output.String("ortus.source.load_mode", string(mode)),
+		),
 	)
 	defer span.End()
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	// Derive source ID from filename
-	sourceID := domain.DeriveSourceID(path)
-	span.SetAttributes(output.String("ortus.source.id", sourceID))
-
 	// Check if already open
 	if src, ok := r.sources[sourceID]; ok {
 		span.AddEvent("already_open")
@@ -143,7 +187,7 @@ func (r *Repository) Open(ctx context.Context, path string) (*domain.Source, err
 	}
 
 	// Open database with SpatiaLite extension
-	db, err := r.openDB(ctx, path)
+	db, keep, err := r.openWithLoadMode(ctx, path, mode)
 	if err != nil {
 		return nil, &domain.StorageError{
 			Operation: "open",
@@ -151,23 +195,38 @@ func (r *Repository) Open(ctx context.Context, path string) (*domain.Source, err
 			Err:       err,
 		}
 	}
+	closeAll := func() {
+		_ = db.Close()
+		if keep != nil {
+			_ = keep.Close()
+		}
+	}
 
 	// Load SpatiaLite extension
 	if err := r.loadSpatiaLite(ctx, db); err != nil {
-		_ = db.Close()
+		closeAll()
 		return nil, fmt.Errorf("loading SpatiaLite: %w", err)
 	}
 
+	// Reject corrupt or non-conformant files before reading from them
+	if err := verifyGeoPackage(ctx, db, r.opts.Verify); err != nil {
+		closeAll()
+		return nil, fmt.Errorf("verifying %s: %w", filepath.Base(path), err)
+	}
+
 	// Read GeoPackage metadata
 	src, err := r.readSourceMetadata(ctx, db, sourceID, path)
 	if err != nil {
-		_ = db.Close()
+		closeAll()
 		return nil, err
 	}
 
 	// Store connection and source
 	r.connections[sourceID] = db
 	r.sources[sourceID] = src
+	if keep != nil {
+		r.inMemory[sourceID] = keep
+	}
 
 	return src, nil
 }
@@ -197,12 +256,28 @@ func (r *Repository) Close(ctx context.Context, sourceID string) error {
 		span.SetStatus(output.StatusError, "close failed")
 		return err
 	}
+	if keep, ok := r.inMemory[sourceID]; ok {
+		_ = keep.Close()
+		delete(r.inMemory, sourceID)
+	}
 
 	delete(r.connections, sourceID)
 	delete(r.sources, sourceID)
 	return nil
 }
 
+// SetMaxIdleConns resizes the idle connection pool of an open source; idle
+// connections above n are closed. Ids that are not open here (raster sources,
+// unloaded packages) are ignored.
+func (r *Repository) SetMaxIdleConns(sourceID string, n int) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if db, ok := r.connections[sourceID]; ok {
+		db.SetMaxIdleConns(n)
+	}
+	return nil
+}
+
 // GetLayers returns all layers in a GeoPackage.
 func (r *Repository) GetLayers(ctx context.Context, sourceID string) ([]domain.Layer, error) {
 	_, span := r.tracer.Start(ctx, "Repository.GetLayers",
@@ -226,6 +301,19 @@ func (r *Repository) GetLayers(ctx context.Context, sourceID string) ([]domain.L
 
 // QueryPoint performs a point query on a specific layer.
 func (r *Repository) QueryPoint(ctx context.Context, sourceID, layerName string, coord domain.Coordinate) ([]domain.Feature, error) {
+	return r.queryPoint(ctx, sourceID, layerName, coord, domain.GeometryOptions{})
+}
+
+var _ output.GeometryEncoder = (*Repository)(nil)
+
+// QueryPointEncoded is QueryPoint that simplifies each geometry
+// (ST_SimplifyPreserveTopology) and additionally encodes it in opts.Format
+// (SpatiaLite AsBinary / AsGeoJSON / AsGML) in the same SQL.
+func (r *Repository) QueryPointEncoded(ctx context.Context, sourceID, layerName string, coord domain.Coordinate, opts domain.GeometryOptions) ([]domain.Feature, error) {
+	return r.queryPoint(ctx, sourceID, layerName, coord, opts)
+}
+
+func (r *Repository) queryPoint(ctx context.Context, sourceID, layerName string, coord domain.Coordinate, opts domain.GeometryOptions) ([]domain.Feature, error) {
 	ctx, span := r.tracer.Start(ctx, "Repository.QueryPoint",
 		output.WithSpanKind(output.SpanKindClient),
 		output.WithAttributes(
@@ -235,6 +323,8 @@ func (r *Repository) QueryPoint(ctx context.Context, sourceID, layerName string,
 			output.Float64("ortus.coordinate.x", coord.X),
 			output.Float64("ortus.coordinate.y", coord.Y),
 			output.Int("ortus.coordinate.srid", coord.SRID),
+			output.String("ortus.geometry.format", string(opts.Format)),
+			output.Float64("ortus.geometry.simplify", opts.Simplify),
 		),
 	)
 	defer span.End()
@@ -264,7 +354,7 @@ func (r *Repository) QueryPoint(ctx context.Context, sourceID, layerName string,
 		output.Bool("ortus.layer.has_index", layer.HasIndex),
 	)
 
-	features, err := r.executePointQuery(ctx, db, layer, coord)
+	features, err := r.executePointQuery(ctx, db, layer, coord, opts, domain.ExplainFrom(ctx).Layer(sourceID, layerName))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(output.StatusError, "query failed")
@@ -275,6 +365,10 @@ func (r *Repository) QueryPoint(ctx context.Context, sourceID, layerName string,
 	return features, nil
 }
 
+// ErrNoSpatialIndex is returned by CreateSpatialIndex in read-only mode for
+// a layer whose package ships no R-tree index.
+var ErrNoSpatialIndex = errors.New("no pre-built spatial index (read-only mode builds none)")
+
 // CreateSpatialIndex creates a spatial index for a layer.
 // This creates an R-tree virtual table directly, bypassing SpatiaLite's CreateSpatialIndex()
 // which requires a geometry_columns table that GeoPackage files don't have.
@@ -324,16 +418,17 @@ func (r *Repository) CreateSpatialIndex(ctx context.Context, sourceID, layerName
 		}
 		return nil
 	}
+	if r.opts.ReadOnly {
+		idxErr := &domain.IndexError{SourceID: sourceID, Layer: layerName, Err: ErrNoSpatialIndex}
+		span.RecordError(idxErr)
+		span.SetStatus(output.StatusError, "no pre-built index")
+		return idxErr
+	}
 
-	indexTable := fmt.Sprintf("rtree_%s_%s", layerName, layer.GeometryColumn)
+	indexTable := rtreeName(layerName, layer.GeometryColumn)
 
 	// Create R-tree virtual table
-	//nolint:gocritic // sprintfQuotedString: SQL identifiers need double quotes, not Go's %q
-	createQuery := fmt.Sprintf(
-		`CREATE VIRTUAL TABLE "%s" USING rtree(id, minx, maxx, miny, maxy)`, //#nosec G201 -- table name derived from trusted database
-		indexTable,
-	)
-	if _, err := db.ExecContext(ctx, createQuery); err != nil { //#nosec G701 -- identifier from layer validated via GetLayer, double-quoted; SQLite DDL identifiers cannot be parameterized
+	if _, err := db.ExecContext(ctx, createRTreeSQL(indexTable)); err != nil { //#nosec G701 -- identifier from layer validated via GetLayer, double-quoted; SQLite DDL identifiers cannot be parameterized
 		idxErr := &domain.IndexError{
 			SourceID: sourceID,
 			Layer:    layerName,
@@ -345,23 +440,7 @@ func (r *Repository) CreateSpatialIndex(ctx context.Context, sourceID, layerName
 	}
 
 	// Populate R-tree with bounding boxes from all geometries
-	// Using CastAutomagic to convert GeoPackage binary geometry to SpatiaLite format
-	populateQuery := fmt.Sprintf(`
-		INSERT INTO "%s" (id, minx, maxx, miny, maxy)
-		SELECT rowid,
-			MbrMinX(CastAutomagic("%s")),
-			MbrMaxX(CastAutomagic("%s")),
-			MbrMinY(CastAutomagic("%s")),
-			MbrMaxY(CastAutomagic("%s"))
-		FROM "%s"
-		WHERE "%s" IS NOT NULL
-	`, indexTable,
-		layer.GeometryColumn, layer.GeometryColumn,
-		layer.GeometryColumn, layer.GeometryColumn,
-		layerName, layer.GeometryColumn,
-	) //#nosec G201 -- table/column names from trusted database source
-
-	if _, err := db.ExecContext(ctx, populateQuery); err != nil { //#nosec G701 -- identifiers from layer validated via GetLayer, double-quoted; SQLite DDL identifiers cannot be parameterized
+	if _, err := db.ExecContext(ctx, populateRTreeSQL(indexTable, layer)); err != nil { //#nosec G701 -- identifiers from layer validated via GetLayer, double-quoted; SQLite DDL identifiers cannot be parameterized
 		// Clean up the empty R-tree table on failure
 		//nolint:gocritic // sprintfQuotedString: SQL identifiers need double quotes, not Go's %q
 		_, _ = db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS "%s"`, indexTable)) //#nosec G701 -- table name derived from validated layer metadata, double-quoted
@@ -384,6 +463,35 @@ func (r *Repository) CreateSpatialIndex(ctx context.Context, sourceID, layerName
 	return nil
 }
 
+// createRTreeSQL creates the empty R-tree table indexTable.
+func createRTreeSQL(indexTable string) string {
+	//nolint:gocritic // sprintfQuotedString: SQL identifiers need double quotes, not Go's %q
+	return fmt.Sprintf(
+		`CREATE VIRTUAL TABLE "%s" USING rtree(id, minx, maxx, miny, maxy)`, //#nosec G201 -- table name derived from trusted database
+		indexTable,
+	)
+}
+
+// populateRTreeSQL fills indexTable with the bounding boxes of all geometries
+// of layer, using CastAutomagic to convert GeoPackage binary geometry to
+// SpatiaLite format.
+func populateRTreeSQL(indexTable string, layer *domain.Layer) string {
+	return fmt.Sprintf(`
+		INSERT INTO "%s" (id, minx, maxx, miny, maxy)
+		SELECT rowid,
+			MbrMinX(CastAutomagic("%s")),
+			MbrMaxX(CastAutomagic("%s")),
+			MbrMinY(CastAutomagic("%s")),
+			MbrMaxY(CastAutomagic("%s"))
+		FROM "%s"
+		WHERE "%s" IS NOT NULL
+	`, indexTable,
+		layer.GeometryColumn, layer.GeometryColumn,
+		layer.GeometryColumn, layer.GeometryColumn,
+		layer.Name, layer.GeometryColumn,
+	) //#nosec G201 -- table/column names from trusted database source
+}
+
 // setLayerIndexStatus safely updates the HasIndex status for a layer.
 // It handles concurrent access and checks if the source still exists.
 func (r *Repository) setLayerIndexStatus(sourceID, layerName string, hasIndex bool) error {
@@ -458,7 +566,8 @@ func (r *Repository) HasSpatialIndex(ctx context.Context, sourceID, layerName st
 // openDB opens the SQLite database with appropriate settings.
 func (r *Repository) openDB(ctx context.Context, path string) (*sql.DB, error) {
 	// Open read-write so the one-off spatial-index build can run; the GeoPackage
-	// data itself is never modified (only R-tree indexes are added).
+	// data itself is never modified (only R-tree indexes are added). In
+	// read-only mode nothing is written at all (see dsnFor).
 	return openSpatiaLite(ctx, path, r.opts)
 }
 
@@ -468,6 +577,13 @@ var validJournalModes = map[string]bool{
 	"MEMORY": true, "WAL": true, "OFF": true,
 }
 
+// validSynchronous and validTempStores are the values accepted for the
+// synchronous and temp_store pragmas.
+var (
+	validSynchronous = map[string]bool{"OFF": true, "NORMAL": true, "FULL": true, "EXTRA": true}
+	validTempStores  = map[string]bool{"DEFAULT": true, "FILE": true, "MEMORY": true}
+)
+
 // normalizeCacheMode constrains the cache mode to the two SQLite values. Any
 // other input (including "" or an attempt to smuggle extra DSN params via '&')
 // falls back to the safe read-concurrency default.
@@ -517,8 +633,14 @@ func (r *Repository) readSourceMetadata(ctx context.Context, db *sql.DB, sourceI
 	}
 	src.Layers = layers
 
+	// Tile pyramids are an extra next to the layers: a broken tile matrix
+	// must not keep the features from loading.
+	src.TileSets, _ = readTileSets(ctx, db)
+
 	// Try to read metadata from gpkg_metadata if available
 	_ = r.readMetadata(ctx, db, src)
+	// A sidecar file next to the package overrides what it embeds.
+	readSidecar(src, path)
 
 	return src, nil
 }
@@ -558,6 +680,7 @@ func (r *Repository) readLayers(ctx context.Context, db *sql.DB) ([]domain.Layer
 		if err != nil {
 			return nil, fmt.Errorf("scanning layer: %w", err)
 		}
+		checkLayerSRID(ctx, db, &l, r.opts.FallbackSRID)
 
 		if minX != 0 || minY != 0 || maxX != 0 || maxY != 0 {
 			l.Extent = &domain.Extent{
@@ -573,6 +696,9 @@ func (r *Repository) readLayers(ctx context.Context, db *sql.DB) ([]domain.Layer
 		if err := db.QueryRowContext(ctx, countQuery).Scan(&count); err == nil {
 			l.FeatureCount = count
 		}
+		if r.opts.CheckValidity {
+			checkLayerValidity(ctx, db, &l)
+		}
 
 		layers = append(layers, l)
 	}
@@ -598,64 +724,219 @@ type datasetMetadata struct {
 		Attribution string `json:"attribution"`
 	} `json:"license"`
 	Description string `json:"description"`
+	// Identifier is the package's self-declared source id, used under the
+	// "metadata" source id strategy.
+	Identifier string `json:"identifier"`
+	// Layers carries per-layer metadata keyed by table name: notes (caveats
+	// attached to every result the layer matches) and, for 3D layers, the
+	// attributes holding each feature's min/max height.
+	Layers map[string]struct {
+		Notes  []string `json:"notes"`
+		Height struct {
+			Min string `json:"min"`
+			Max string `json:"max"`
+		} `json:"height"`
+	} `json:"layers"`
+}
+
+var _ output.SourceIdentifier = (*Repository)(nil)
+
+// Identify returns the identifier declared in the ortus metadata row, or ""
+// when the package declares none. It implements output.SourceIdentifier.
+func (r *Repository) Identify(ctx context.Context, path string) (string, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository.Identify",
+		output.WithAttributes(output.String("ortus.source.path", path)),
+	)
+	defer span.End()
+
+	db, err := r.openDB(ctx, path)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "open failed")
+		return "", &domain.StorageError{Operation: "open", Key: path, Err: err}
+	}
+	defer func() { _ = db.Close() }()
+
+	if !hasTable(ctx, db, "gpkg_metadata") {
+		return "", nil
+	}
+
+	var metadata string
+	err = db.QueryRowContext(ctx,
+		`SELECT COALESCE(metadata,'') FROM gpkg_metadata WHERE md_standard_uri = ? AND mime_type = 'application/json' ORDER BY id LIMIT 1`,
+		ortusMetadataURI,
+	).Scan(&metadata)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading gpkg_metadata: %w", err)
+	}
+
+	var doc datasetMetadata
+	if err := json.Unmarshal([]byte(metadata), &doc); err != nil {
+		// Same leniency as readMetadata: a malformed row is treated as absent.
+		return "", nil
+	}
+	return strings.TrimSpace(doc.Identifier), nil
 }
 
 // readMetadata reads optional dataset metadata from gpkg_metadata. The
-// license/attribution and description come from the single ortus contract row
-// (md_standard_uri == ortusMetadataURI, mime_type application/json); any other
-// JSON metadata the file carries is ignored for the license so it cannot be
-// mistaken for it. A plain-text row provides a description fallback. All of it
-// is optional: a GeoPackage without a gpkg_metadata table, or without the ortus
-// row, still loads — the license simply stays empty.
+// license/attribution, description and per-layer notes come from the single
+// ortus contract row (md_standard_uri == ortusMetadataURI, mime_type
+// application/json); any other JSON metadata the file carries is ignored for
+// the license so it cannot be mistaken for it. ISO 19139 XML rows fill the
+// title, description, creator, dates, keywords and constraints — or, when
+// gpkg_metadata_reference scopes them to a table, that layer's description
+// (see applyXMLMetadata). A plain-text row provides a description fallback.
+// All of it is optional: a GeoPackage without a gpkg_metadata table, or
+// without the ortus row, still loads — the license simply stays empty.
 func (r *Repository) readMetadata(ctx context.Context, db *sql.DB, src *domain.Source) error {
 	// The gpkg_metadata table is optional; if it is absent (or the probe fails)
 	// there is simply no dataset metadata to read — not a fatal condition.
-	var exists int
-	err := db.QueryRowContext(ctx,
-		"SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='gpkg_metadata'",
-	).Scan(&exists)
-	if err != nil || exists == 0 {
+	if !hasTable(ctx, db, "gpkg_metadata") {
 		return nil
 	}
+	scopes := readMetadataScopes(ctx, db)
 
 	rows, err := db.QueryContext(ctx,
-		`SELECT COALESCE(md_standard_uri,''), COALESCE(mime_type,''), COALESCE(metadata,'') FROM gpkg_metadata ORDER BY id`)
+		`SELECT id, COALESCE(md_standard_uri,''), COALESCE(mime_type,''), COALESCE(metadata,'') FROM gpkg_metadata ORDER BY id`)
 	if err != nil {
 		return fmt.Errorf("reading gpkg_metadata: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
 	for rows.Next() {
+		var id int64
 		var uri, mime, metadata string
-		if err := rows.Scan(&uri, &mime, &metadata); err != nil {
+		if err := rows.Scan(&id, &uri, &mime, &metadata); err != nil {
 			return fmt.Errorf("scanning gpkg_metadata: %w", err)
 		}
-		// The ortus contract row: parse license + description from its JSON.
-		if uri == ortusMetadataURI && mime == "application/json" {
-			var doc datasetMetadata
-			if err := json.Unmarshal([]byte(metadata), &doc); err != nil {
-				// A malformed ortus entry is skipped, not fatal — the source
-				// still loads (without license).
-				continue
-			}
-			src.License = domain.License{
-				Name:        doc.License.Name,
-				URL:         doc.License.URL,
-				Attribution: doc.License.Attribution,
+		switch {
+		case uri == ortusMetadataURI && mime == "application/json":
+			applyDatasetMetadata(src, metadata)
+		case mime == "application/json":
+			// Unrelated JSON rows are ignored entirely — they feed neither the
+			// license nor the description.
+		case isXMLMetadata(mime, metadata):
+			applyXMLMetadata(src, metadata, scopes[id])
+		case src.Metadata.Description == "":
+			// Plain text (or any other non-XML row) is a description fallback.
+			src.Metadata.Description = metadata
+		}
+	}
+	return rows.Err()
+}
+
+// applyDatasetMetadata applies the ortus contract row: license, description
+// and layer notes. A malformed entry is skipped, not fatal — the source still
+// loads (without license).
+func applyDatasetMetadata(src *domain.Source, metadata string) {
+	var doc datasetMetadata
+	if err := json.Unmarshal([]byte(metadata), &doc); err != nil {
+		return
+	}
+	src.License = domain.License{
+		Name:        doc.License.Name,
+		URL:         doc.License.URL,
+		Attribution: doc.License.Attribution,
+	}
+	if doc.Description != "" {
+		src.Metadata.Description = doc.Description
+	}
+	for i := range src.Layers {
+		if lm, ok := doc.Layers[src.Layers[i].Name]; ok {
+			src.Layers[i].Notes = nonEmptyNotes(lm.Notes)
+			src.Layers[i].Height = domain.LayerHeight{
+				MinAttribute: strings.TrimSpace(lm.Height.Min),
+				MaxAttribute: strings.TrimSpace(lm.Height.Max),
 			}
-			if doc.Description != "" {
-				src.Metadata.Description = doc.Description
+		}
+	}
+}
+
+// metadataScope is where gpkg_metadata_reference attaches a metadata row.
+type metadataScope struct {
+	scope string // geopackage, table, column, row or row/col
+	table string
+}
+
+// readMetadataScopes maps gpkg_metadata ids to their first reference. Rows
+// without one describe the whole GeoPackage. The reference table is optional
+// like gpkg_metadata itself, and a failure to read it leaves every row
+// unscoped.
+func readMetadataScopes(ctx context.Context, db *sql.DB) map[int64]metadataScope {
+	scopes := make(map[int64]metadataScope)
+	if !hasTable(ctx, db, "gpkg_metadata_reference") {
+		return scopes
+	}
+	rows, err := db.QueryContext(ctx,
+		`SELECT md_file_id, COALESCE(reference_scope,''), COALESCE(table_name,'') FROM gpkg_metadata_reference ORDER BY md_file_id, timestamp`)
+	if err != nil {
+		return scopes
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var id int64
+		var s metadataScope
+		if err := rows.Scan(&id, &s.scope, &s.table); err != nil {
+			return scopes
+		}
+		if _, seen := scopes[id]; !seen {
+			scopes[id] = metadataScope{scope: strings.ToLower(s.scope), table: s.table}
+		}
+	}
+	return scopes
+}
+
+// applyXMLMetadata applies an ISO 19139 row. Dataset-level rows fill the
+// source metadata fields still empty, so the first record wins and the ortus
+// JSON description always does; a table-scoped row only describes that
+// layer. Column- and row-level metadata has no place in the source model and
+// is skipped, as is XML that is not an MD_Metadata record — it is never
+// dumped into the description.
+func applyXMLMetadata(src *domain.Source, metadata string, scope metadataScope) {
+	md, ok := parseISOMetadata(metadata)
+	if !ok {
+		return
+	}
+	switch scope.scope {
+	case "", "geopackage":
+		mergeMetadata(&src.Metadata, md)
+	case "table":
+		text := md.Description
+		if text == "" {
+			text = md.Title
+		}
+		for i := range src.Layers {
+			if src.Layers[i].Name == scope.table && src.Layers[i].Description == "" {
+				src.Layers[i].Description = text
 			}
-			continue
 		}
-		// Only a non-JSON (e.g. text/xml or text/plain) row provides a description
-		// fallback. Unrelated application/json rows are ignored entirely — they
-		// feed neither the license nor the description.
-		if mime != "application/json" && src.Metadata.Description == "" {
-			src.Metadata.Description = metadata
+	}
+}
+
+// hasTable reports whether db has the table name. A failed probe counts as
+// absent.
+func hasTable(ctx context.Context, db *sql.DB, name string) bool {
+	var exists int
+	err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?", name,
+	).Scan(&exists)
+	return err == nil && exists > 0
+}
+
+// nonEmptyNotes drops blank entries so a stray "" in the metadata does not
+// surface as an empty note on every result.
+func nonEmptyNotes(notes []string) []string {
+	var out []string
+	for _, n := range notes {
+		if n = strings.TrimSpace(n); n != "" {
+			out = append(out, n)
 		}
 	}
-	return rows.Err()
+	return out
 }
 
 // executePointQuery performs the actual point query.
@@ -668,7 +949,9 @@ func (r *Repository) readMetadata(ctx context.Context, db *sql.DB, src *domain.S
 // results for polygon layers (see dedupFeaturesByProperties).
 // The coordinate must already be transformed to the layer's SRID before calling this function.
 // Uses R-tree spatial index for fast bounding box filtering when available.
-func (r *Repository) executePointQuery(ctx context.Context, db *sql.DB, layer *domain.Layer, coord domain.Coordinate) ([]domain.Feature, error) {
+// A non-nil explain receives the SQL, its plan, the rows examined and the SQL
+// time (see explainPointQuery).
+func (r *Repository) executePointQuery(ctx context.Context, db *sql.DB, layer *domain.Layer, coord domain.Coordinate, opts domain.GeometryOptions, explain *domain.LayerExplain) ([]domain.Feature, error) {
 	ctx, span := r.tracer.Start(ctx, "Repository.executePointQuery",
 		output.WithSpanKind(output.SpanKindClient),
 		output.WithAttributes(
@@ -680,6 +963,9 @@ func (r *Repository) executePointQuery(ctx context.Context, db *sql.DB, layer *d
 	defer span.End()
 
 	pointWKT := coord.WKT()
+	// The point must carry the SRID of the stored geometries, which differs
+	// from coord.SRID when the layer SRID is an assumed fallback.
+	pointSRID := layer.StoredSRID()
 	indexTable := fmt.Sprintf("rtree_%s_%s", layer.Name, layer.GeometryColumn)
 
 	// Check if R-tree index exists
@@ -696,68 +982,73 @@ func (r *Repository) executePointQuery(ctx context.Context, db *sql.DB, layer *d
 		output.String("ortus.index.table", indexTable),
 	)
 
+	geomSelect := computedSelect(layer) + geometrySelect(layer.GeometryColumn, opts, r.decimals(layer))
+	if !layer.IsPolygonLayer() {
+		// A bbox match says little on its own; measure how far the geometry is.
+		geomSelect = distanceSelect(layer) + ", " + geomSelect
+	}
+
 	// Build query using ST_Covers for polygon layers (boundary-inclusive so
 	// tiled/subdivided packages match their originals), MbrContains for others.
 	// Note: GeoPackage uses GPKG binary format, so we use CastAutomagic() to convert
 	// the geometry to SpatiaLite format before spatial operations
+	repair := r.repairGeometries(layer)
 	var query string
 	if indexExists > 0 {
 		// Use R-tree index for fast bounding box pre-filtering
 		if layer.IsPolygonLayer() {
 			query = fmt.Sprintf(`
-				SELECT t.*, AsText(CastAutomagic(t."%s"))
+				SELECT t.*, %s
 				FROM "%s" t
 				INNER JOIN "%s" r ON t.rowid = r.id
 				WHERE r.minx <= ? AND r.maxx >= ? AND r.miny <= ? AND r.maxy >= ?
-				  AND ST_Covers(CastAutomagic(t."%s"), GeomFromText(?, ?))
-			`, layer.GeometryColumn, layer.Name, indexTable,
-				layer.GeometryColumn,
+				  AND ST_Covers(%s, GeomFromText(?, ?))
+			`, geomSelect, layer.Name, indexTable,
+				coveringGeometry("t", layer.GeometryColumn, repair),
 			) //#nosec G201 -- table/column names from trusted database
 		} else {
 			query = fmt.Sprintf(`
-				SELECT t.*, AsText(CastAutomagic(t."%s"))
+				SELECT t.*, %s
 				FROM "%s" t
 				INNER JOIN "%s" r ON t.rowid = r.id
 				WHERE r.minx <= ? AND r.maxx >= ? AND r.miny <= ? AND r.maxy >= ?
-			`, layer.GeometryColumn, layer.Name, indexTable,
+			`, geomSelect, layer.Name, indexTable,
 			) //#nosec G201 -- table/column names from trusted database
 		}
 	} else {
 		// Fallback: no R-tree index, full table scan
 		if layer.IsPolygonLayer() {
 			query = fmt.Sprintf(`
-				SELECT *, AsText(CastAutomagic("%s"))
+				SELECT *, %s
 				FROM "%s"
-				WHERE ST_Covers(CastAutomagic("%s"), GeomFromText(?, ?))
-			`, layer.GeometryColumn, layer.Name, layer.GeometryColumn) //#nosec G201 -- identifiers from layer metadata read from the gpkg catalog, double-quoted; SQLite can't parameterize identifiers
+				WHERE ST_Covers(%s, GeomFromText(?, ?))
+			`, geomSelect, layer.Name, coveringGeometry("", layer.GeometryColumn, repair)) //#nosec G201 -- identifiers from layer metadata read from the gpkg catalog, double-quoted; SQLite can't parameterize identifiers
 		} else {
 			query = fmt.Sprintf(`
-				SELECT *, AsText(CastAutomagic("%s"))
+				SELECT *, %s
 				FROM "%s"
 				WHERE MbrContains(CastAutomagic("%s"), GeomFromText(?, ?))
-			`, layer.GeometryColumn, layer.Name, layer.GeometryColumn) //#nosec G201 -- identifiers from layer metadata read from the gpkg catalog, double-quoted; SQLite can't parameterize identifiers
+			`, geomSelect, layer.Name, layer.GeometryColumn) //#nosec G201 -- identifiers from layer metadata read from the gpkg catalog, double-quoted; SQLite can't parameterize identifiers
 		}
 	}
 
 	span.SetAttributes(output.String("db.statement", query))
 
-	var rows *sql.Rows
+	var args []interface{}
+	if !layer.IsPolygonLayer() {
+		args = append(args, pointWKT, pointSRID) // distance column in the select list
+	}
 	if indexExists > 0 {
 		// R-tree query: pass point coordinates for bounding box filter, then WKT and SRID
+		args = append(args, coord.X, coord.X, coord.Y, coord.Y) // R-tree bounds (point = minx=maxx, miny=maxy)
 		if layer.IsPolygonLayer() {
-			rows, err = db.QueryContext(ctx, query,
-				coord.X, coord.X, coord.Y, coord.Y, // R-tree bounds (point = minx=maxx, miny=maxy)
-				pointWKT, coord.SRID, // ST_Covers parameters
-			)
-		} else {
-			rows, err = db.QueryContext(ctx, query,
-				coord.X, coord.X, coord.Y, coord.Y, // R-tree bounds only
-			)
+			args = append(args, pointWKT, pointSRID) // ST_Covers parameters
 		}
 	} else {
-		rows, err = db.QueryContext(ctx, query, pointWKT, coord.SRID)
+		args = append(args, pointWKT, pointSRID)
 	}
-
+	sqlStart := time.Now()
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(output.StatusError, "query failed")
@@ -776,14 +1067,19 @@ func (r *Repository) executePointQuery(ctx context.Context, db *sql.DB, layer *d
 		return nil, err
 	}
 
+	reader := r.featureReader(layer, columns)
 	var features []domain.Feature
 	for rows.Next() {
-		feature, err := scanFeature(rows, columns, layer.Name, layer.GeometryColumn)
+		feature, err := scanFeature(rows, columns, layer.Name, layer.GeometryColumn, reader)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(output.StatusError, "scan failed")
 			return nil, err
 		}
+		feature.Match.Kind = domain.MatchBBox
+		if layer.IsPolygonLayer() {
+			feature.Match.Kind = domain.MatchContains
+		}
 		features = append(features, feature)
 	}
 
@@ -792,6 +1088,11 @@ func (r *Repository) executePointQuery(ctx context.Context, db *sql.DB, layer *d
 		span.SetStatus(output.StatusError, "rows iteration failed")
 		return nil, err
 	}
+	if explain != nil {
+		explain.SQLTime = time.Since(sqlStart)
+		explain.RowsReturned = len(features)
+		explainPointQuery(ctx, db, explain, layer, indexExists > 0, indexTable, query, args, coord)
+	}
 
 	// Dedup fragments of the same feature — polygon layers only. ST_Covers is
 	// boundary-inclusive, so a point on an internal cut edge of an ST_Subdivide-tiled
@@ -810,6 +1111,78 @@ func (r *Repository) executePointQuery(ctx context.Context, db *sql.DB, layer *d
 	return features, nil
 }
 
+// Result-column aliases for the optional extra geometry encoding. They sit just
+// before the trailing AsText column; buildFeature routes them into
+// domain.Geometry instead of Properties.
+const (
+	colGeomWKB     = "__ortus_wkb"
+	colGeomGeoJSON = "__ortus_geojson"
+	colGeomGML     = "__ortus_gml"
+	// colDistance is the point-to-geometry distance of bbox matches, routed
+	// into Feature.Match.
+	colDistance = "__ortus_distance"
+)
+
+// distanceSelect returns the result column measuring the distance from the
+// query point (bound as WKT and SRID) to the layer geometry: ellipsoidal metres
+// for WGS 84 layers, CRS units (metres for the projected CRSs in use)
+// otherwise.
+func distanceSelect(layer *domain.Layer) string {
+	ellipsoid := ""
+	if layer.StoredSRID() == domain.SRIDWGS84 {
+		ellipsoid = ", 1"
+	}
+	return fmt.Sprintf(`ST_Distance(CastAutomagic("%s"), GeomFromText(?, ?)%s) AS "%s"`,
+		layer.GeometryColumn, ellipsoid, colDistance)
+}
+
+// computedSelect returns the layer's computed properties as result columns,
+// each followed by a comma, or "" when it has none. The expressions come from
+// the operator's configuration and are trusted like it.
+func computedSelect(layer *domain.Layer) string {
+	var b strings.Builder
+	for _, c := range layer.Computed {
+		b.WriteByte('(')
+		b.WriteString(strings.ReplaceAll(c.Expr, "{geom}", "CastAutomagic("+quoteIdent(layer.GeometryColumn)+")"))
+		b.WriteString(") AS ")
+		b.WriteString(quoteIdent(c.Name))
+		b.WriteString(", ")
+	}
+	return b.String()
+}
+
+// geometrySelect returns the geometry result columns for a point query: the
+// encoding requested by opts.Format (if any beyond WKT), then the AsText WKT
+// that buildFeature expects as the last column. A positive opts.Simplify wraps
+// the geometry in ST_SimplifyPreserveTopology for every encoding. GeoJSON and
+// GML are written with decimals decimals (SpatiaLite's 15 when negative); WKT
+// is rounded by buildFeature. col is the layer's geometry column, read from
+// the gpkg catalog.
+func geometrySelect(col string, opts domain.GeometryOptions, decimals int) string {
+	geom := fmt.Sprintf(`CastAutomagic("%s")`, col)
+	if opts.Simplify > 0 && opts.Validate() == nil {
+		// The tolerance is a validated finite float, formatted rather than bound
+		// so the four query variants keep their positional parameters.
+		geom = fmt.Sprintf("ST_SimplifyPreserveTopology(%s, %s)", geom,
+			strconv.FormatFloat(opts.Simplify, 'g', -1, 64))
+	}
+	wkt := "AsText(" + geom + ")"
+	precision := ""
+	if decimals >= 0 {
+		precision = ", " + strconv.Itoa(decimals)
+	}
+	switch opts.Format {
+	case domain.GeometryFormatWKB:
+		return fmt.Sprintf(`AsBinary(%s) AS "%s", %s`, geom, colGeomWKB, wkt)
+	case domain.GeometryFormatGeoJSON:
+		return fmt.Sprintf(`AsGeoJSON(%s%s) AS "%s", %s`, geom, precision, colGeomGeoJSON, wkt)
+	case domain.GeometryFormatGML:
+		return fmt.Sprintf(`AsGML(3, %s%s) AS "%s", %s`, geom, precision, colGeomGML, wkt)
+	default:
+		return wkt
+	}
+}
+
 // dedupFeaturesByProperties collapses features that are identical across all of
 // their non-geometry, non-fid properties. It is used to remove duplicate rows
 // produced by boundary-inclusive (ST_Covers) matching against ST_Subdivide
@@ -892,8 +1265,8 @@ func featurePropertyKey(f domain.Feature) string {
 	return b.String()
 }
 
-// scanFeature scans a row into a Feature.
-func scanFeature(rows *sql.Rows, columns []string, layerName, geomColumn string) (domain.Feature, error) {
+// scanFeature scans a row into a Feature, read by reader.
+func scanFeature(rows *sql.Rows, columns []string, layerName, geomColumn string, reader featureReader) (domain.Feature, error) {
 	// Create scan destinations
 	values := make([]interface{}, len(columns))
 	valuePtrs := make([]interface{}, len(columns))
@@ -905,13 +1278,13 @@ func scanFeature(rows *sql.Rows, columns []string, layerName, geomColumn string)
 		return domain.Feature{}, err
 	}
 
-	return buildFeature(columns, values, layerName, geomColumn), nil
+	return buildFeature(columns, values, layerName, geomColumn, reader), nil
 }
 
 // buildFeature maps a scanned row (columns + values, the LAST column being the
 // AsText geometry) into a domain.Feature. Split out from scanFeature so the batch
 // query can scan a leading per-point index column itself and reuse this mapping.
-func buildFeature(columns []string, values []interface{}, layerName, geomColumn string) domain.Feature {
+func buildFeature(columns []string, values []interface{}, layerName, geomColumn string, reader featureReader) domain.Feature {
 	feature := domain.Feature{
 		LayerName:  layerName,
 		Properties: make(map[string]interface{}),
@@ -925,6 +1298,18 @@ func buildFeature(columns []string, values []interface{}, layerName, geomColumn
 			}
 		case geomColumn:
 			// Skip raw geometry column
+		case colGeomWKB:
+			if b, ok := values[i].([]byte); ok {
+				feature.Geometry.WKB = b
+			}
+		case colGeomGeoJSON:
+			feature.Geometry.GeoJSON = textValue(values[i])
+		case colGeomGML:
+			feature.Geometry.GML = textValue(values[i])
+		case colDistance:
+			if d, ok := values[i].(float64); ok {
+				feature.Match.DistanceM = reader.roundMeasure(d)
+			}
 		default:
 			// Skip the AsText result column (last column) - it contains geometry WKT
 			// This is identified by checking if this is the last column and contains WKT-like string
@@ -932,16 +1317,14 @@ func buildFeature(columns []string, values []interface{}, layerName, geomColumn
 				// Last column is the AsText result, skip it from properties
 				continue
 			}
-			if values[i] != nil {
-				feature.Properties[col] = values[i]
-			}
+			reader.set(feature.Properties, i, col, values[i])
 		}
 	}
 
 	// Get WKT from the last column (AsText result)
 	if len(values) > 0 {
 		if wkt, ok := values[len(values)-1].(string); ok {
-			feature.Geometry.WKT = wkt
+			feature.Geometry.WKT = reader.roundWKT(wkt)
 			feature.Geometry.Type = extractGeometryType(wkt)
 		}
 	}
@@ -949,6 +1332,19 @@ func buildFeature(columns []string, values []interface{}, layerName, geomColumn
 	return feature
 }
 
+// textValue returns a TEXT result as a string; the sqlite driver may hand it
+// back as string or []byte.
+func textValue(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	default:
+		return ""
+	}
+}
+
 // extractGeometryType extracts the geometry type from WKT.
 func extractGeometryType(wkt string) string {
 	if idx := strings.Index(wkt, "("); idx > 0 {
@@ -3,6 +3,7 @@ package geopackage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"path/filepath"
 	"testing"
 
@@ -107,7 +108,7 @@ func newFixtureRepo(t *testing.T) (*Repository, *domain.Source) {
 	repo := NewRepository(Options{})
 	t.Cleanup(func() { _ = repo.Close(context.Background(), "regions") })
 
-	src, err := repo.Open(context.Background(), path)
+	src, err := repo.Open(context.Background(), domain.DeriveSourceID(path), path)
 	if err != nil {
 		t.Fatalf("Open: %v", err)
 	}
@@ -292,6 +293,87 @@ func TestIntegration_SpatialIndexCreateProbeAndQuery(t *testing.T) {
 	}
 }
 
+// TestIntegration_ReadOnlyMode: a read-only repository builds no index; it
+// reports the missing one and serves a package whose index was built ahead.
+func TestIntegration_ReadOnlyMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regions.gpkg")
+	buildFixtureGPKG(t, path)
+	ctx := context.Background()
+
+	ro := NewRepository(Options{ReadOnly: true})
+	if _, err := ro.Open(ctx, "regions", path); err != nil {
+		t.Fatalf("read-only Open: %v", err)
+	}
+	if err := ro.CreateSpatialIndex(ctx, "regions", "regions"); !errors.Is(err, ErrNoSpatialIndex) {
+		t.Fatalf("read-only CreateSpatialIndex err = %v, want ErrNoSpatialIndex", err)
+	}
+	_ = ro.Close(ctx, "regions")
+
+	// Build the index ahead, as the package's publisher would.
+	rw := NewRepository(Options{})
+	if _, err := rw.Open(ctx, "regions", path); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := rw.CreateSpatialIndex(ctx, "regions", "regions"); err != nil {
+		t.Fatalf("CreateSpatialIndex: %v", err)
+	}
+	_ = rw.Close(ctx, "regions")
+
+	if _, err := ro.Open(ctx, "regions", path); err != nil {
+		t.Fatalf("read-only Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ro.Close(ctx, "regions") })
+	if err := ro.CreateSpatialIndex(ctx, "regions", "regions"); err != nil {
+		t.Fatalf("read-only CreateSpatialIndex over a pre-built index: %v", err)
+	}
+	features, err := ro.QueryPoint(ctx, "regions", "regions", domain.NewWGS84Coordinate(8, 2))
+	if err != nil || len(features) != 1 {
+		t.Fatalf("read-only QueryPoint = %v, %v; want one feature", features, err)
+	}
+}
+
+// TestIntegration_LoadModes: a package opened memory-mapped or copied into
+// memory answers like one read from the file, and the in-memory copy does
+// not see later writes to the file.
+func TestIntegration_LoadModes(t *testing.T) {
+	for _, mode := range []domain.LoadMode{domain.LoadModeMmap, domain.LoadModeMemory} {
+		t.Run(string(mode), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "regions.gpkg")
+			buildFixtureGPKG(t, path)
+			ctx := context.Background()
+
+			repo := NewRepository(Options{MaxIdleConns: 1})
+			if _, err := repo.OpenWithLoadMode(ctx, "regions", path, mode); err != nil {
+				t.Fatalf("OpenWithLoadMode: %v", err)
+			}
+			t.Cleanup(func() { _ = repo.Close(ctx, "regions") })
+			if err := repo.CreateSpatialIndex(ctx, "regions", "regions"); err != nil {
+				t.Fatalf("CreateSpatialIndex: %v", err)
+			}
+			features, err := repo.QueryPoint(ctx, "regions", "regions", domain.NewWGS84Coordinate(8, 2))
+			if err != nil || len(features) != 1 {
+				t.Fatalf("QueryPoint = %v, %v; want one feature", features, err)
+			}
+
+			if mode != domain.LoadModeMemory {
+				return
+			}
+			db, err := sql.Open("sqlite3_with_extensions", "file:"+path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := db.Exec(`DELETE FROM regions`); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			_ = db.Close()
+			features, err = repo.QueryPoint(ctx, "regions", "regions", domain.NewWGS84Coordinate(8, 2))
+			if err != nil || len(features) != 1 {
+				t.Errorf("in-memory QueryPoint after the file changed = %v, %v; want the copied feature", features, err)
+			}
+		})
+	}
+}
+
 func TestIntegration_QueryErrors(t *testing.T) {
 	repo, _ := newFixtureRepo(t)
 	ctx := context.Background()
@@ -380,7 +462,7 @@ func TestIntegration_UnrelatedJSONIsNotLicense(t *testing.T) {
 
 	repo := NewRepository(Options{})
 	t.Cleanup(func() { _ = repo.Close(context.Background(), "regions") })
-	src, err := repo.Open(context.Background(), path)
+	src, err := repo.Open(context.Background(), domain.DeriveSourceID(path), path)
 	if err != nil {
 		t.Fatalf("Open: %v", err)
 	}
@@ -397,7 +479,7 @@ func TestIntegration_OpenReadsLicenseMetadata(t *testing.T) {
 
 	repo := NewRepository(Options{})
 	t.Cleanup(func() { _ = repo.Close(context.Background(), "regions") })
-	src, err := repo.Open(context.Background(), path)
+	src, err := repo.Open(context.Background(), domain.DeriveSourceID(path), path)
 	if err != nil {
 		t.Fatalf("Open: %v", err)
 	}
@@ -431,7 +513,7 @@ func TestIntegration_JSONMetadataWinsOverOtherRows(t *testing.T) {
 
 	repo := NewRepository(Options{})
 	t.Cleanup(func() { _ = repo.Close(context.Background(), "regions") })
-	src, err := repo.Open(context.Background(), path)
+	src, err := repo.Open(context.Background(), domain.DeriveSourceID(path), path)
 	if err != nil {
 		t.Fatalf("Open: %v", err)
 	}
@@ -453,7 +535,7 @@ func TestIntegration_PlainTextMetadataIsNotLicense(t *testing.T) {
 
 	repo := NewRepository(Options{})
 	t.Cleanup(func() { _ = repo.Close(context.Background(), "regions") })
-	src, err := repo.Open(context.Background(), path)
+	src, err := repo.Open(context.Background(), domain.DeriveSourceID(path), path)
 	if err != nil {
 		t.Fatalf("Open: %v", err)
 	}
@@ -465,3 +547,103 @@ func TestIntegration_PlainTextMetadataIsNotLicense(t *testing.T) {
 		t.Errorf("Description = %q, want the plain-text blob", src.Metadata.Description)
 	}
 }
+
+// TestIntegration_Optimize: rebuilding the R-tree, ANALYZE and VACUUM keep a
+// package answering queries, and read-only mode refuses all three.
+func TestIntegration_Optimize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regions.gpkg")
+	buildFixtureGPKG(t, path)
+	ctx := context.Background()
+
+	repo := NewRepository(Options{})
+	if _, err := repo.Open(ctx, "regions", path); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := repo.CreateSpatialIndex(ctx, "regions", "regions"); err != nil {
+		t.Fatalf("CreateSpatialIndex: %v", err)
+	}
+	if err := repo.RebuildSpatialIndex(ctx, "regions", "regions"); err != nil {
+		t.Fatalf("RebuildSpatialIndex: %v", err)
+	}
+	if err := repo.RebuildSpatialIndex(ctx, "regions", "missing"); !errors.Is(err, domain.ErrLayerNotFound) {
+		t.Errorf("RebuildSpatialIndex(missing) err = %v, want ErrLayerNotFound", err)
+	}
+	if err := repo.Analyze(ctx, "regions"); err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if err := repo.Vacuum(ctx, "regions"); err != nil {
+		t.Fatalf("Vacuum: %v", err)
+	}
+	features, err := repo.QueryPoint(ctx, "regions", "regions", domain.NewWGS84Coordinate(8, 2))
+	if err != nil || len(features) != 1 {
+		t.Fatalf("QueryPoint after optimize = %v, %v; want one feature", features, err)
+	}
+	_ = repo.Close(ctx, "regions")
+
+	ro := NewRepository(Options{ReadOnly: true})
+	if _, err := ro.Open(ctx, "regions", path); err != nil {
+		t.Fatalf("read-only Open: %v", err)
+	}
+	t.Cleanup(func() { _ = ro.Close(ctx, "regions") })
+	if err := ro.Vacuum(ctx, "regions"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("read-only Vacuum err = %v, want ErrReadOnly", err)
+	}
+}
+
+// TestIntegration_GeometryValidity: the validity check counts a
+// self-intersecting polygon, and only with MakeValid does a point inside it
+// match.
+func TestIntegration_GeometryValidity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regions.gpkg")
+	buildFixtureGPKG(t, path)
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite3_with_extensions", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A bow tie: its ring crosses itself at (22 2).
+	geomExpr := "AsGPB(GeomFromText(?, 4326))"
+	if _, err := db.ExecContext(ctx, "SELECT AsGPB(GeomFromText('POINT(0 0)', 4326))"); err != nil {
+		geomExpr = "GeomFromText(?, 4326)"
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO regions (name, pop, geom) VALUES ('bowtie', 600, `+geomExpr+`)`,
+		"POLYGON((20 0, 24 4, 24 0, 20 4, 20 0))"); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+
+	for _, tt := range []struct {
+		name      string
+		opts      Options
+		wantMatch bool
+	}{
+		{"check only", Options{CheckValidity: true}, false},
+		{"make valid", Options{CheckValidity: true, MakeValid: true}, true},
+		{"make valid unchecked", Options{MakeValid: true}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewRepository(tt.opts)
+			src, err := repo.Open(ctx, "regions", path)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			t.Cleanup(func() { _ = repo.Close(ctx, "regions") })
+			l := src.Layers[0]
+			if tt.opts.CheckValidity && (!l.ValidityChecked || l.InvalidGeometries != 1) {
+				t.Errorf("validity = %v, %d; want checked, 1 invalid", l.ValidityChecked, l.InvalidGeometries)
+			}
+			features, err := repo.QueryPoint(ctx, "regions", "regions", domain.NewWGS84Coordinate(21, 2))
+			if err != nil {
+				t.Fatalf("QueryPoint: %v", err)
+			}
+			if got := len(features) == 1; got != tt.wantMatch {
+				t.Errorf("QueryPoint inside the bow tie = %v, want a match: %v", features, tt.wantMatch)
+			}
+			// Valid polygons match either way.
+			if features, err := repo.QueryPoint(ctx, "regions", "regions", domain.NewWGS84Coordinate(8, 2)); err != nil || len(features) != 1 {
+				t.Errorf("QueryPoint in a valid polygon = %v, %v; want one feature", features, err)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package geopackage
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
 )
 
 func TestExtractGeometryType(t *testing.T) {
@@ -92,3 +95,182 @@ func TestNewRepository(t *testing.T) {
 		t.Error("sources map should be initialized")
 	}
 }
+
+func TestDSNForReadOnly(t *testing.T) {
+	opts := Options{BusyTimeoutMS: 5000, JournalMode: "WAL"}
+	if got, want := dsnFor("/data/a.gpkg", opts), "file:/data/a.gpkg?cache=private&_busy_timeout=5000&_journal_mode=WAL"; got != want {
+		t.Errorf("dsnFor = %q, want %q", got, want)
+	}
+	opts.ReadOnly = true
+	if got, want := dsnFor("/data/a.gpkg", opts), "file:/data/a.gpkg?cache=private&_busy_timeout=5000&mode=ro&immutable=1"; got != want {
+		t.Errorf("read-only dsnFor = %q, want %q (no journal mode)", got, want)
+	}
+}
+
+func TestPragmasFor(t *testing.T) {
+	if got := pragmasFor(Options{}); len(got) != 0 {
+		t.Errorf("pragmasFor(zero) = %q, want none", got)
+	}
+	got := pragmasFor(Options{Synchronous: "normal", MmapSizeMB: 256, CacheSizeMB: 64, TempStore: " memory "})
+	want := []string{
+		"PRAGMA synchronous = NORMAL",
+		"PRAGMA mmap_size = 268435456",
+		"PRAGMA cache_size = -65536",
+		"PRAGMA temp_store = MEMORY",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pragmasFor = %q, want %q", got, want)
+	}
+	if got := pragmasFor(Options{Synchronous: "NORMAL; DROP TABLE x", TempStore: "ram"}); len(got) != 0 {
+		t.Errorf("pragmasFor(invalid) = %q, want none", got)
+	}
+}
+
+func TestGeometrySelect(t *testing.T) {
+	tests := []struct {
+		name string
+		opts domain.GeometryOptions
+		want string
+	}{
+		{"wkt", domain.GeometryOptions{Format: domain.GeometryFormatWKT}, `AsText(CastAutomagic("geom"))`},
+		{"default", domain.GeometryOptions{}, `AsText(CastAutomagic("geom"))`},
+		{"wkb", domain.GeometryOptions{Format: domain.GeometryFormatWKB}, `AsBinary(CastAutomagic("geom")) AS "__ortus_wkb", AsText(CastAutomagic("geom"))`},
+		{"geojson", domain.GeometryOptions{Format: domain.GeometryFormatGeoJSON}, `AsGeoJSON(CastAutomagic("geom")) AS "__ortus_geojson", AsText(CastAutomagic("geom"))`},
+		{"gml", domain.GeometryOptions{Format: domain.GeometryFormatGML}, `AsGML(3, CastAutomagic("geom")) AS "__ortus_gml", AsText(CastAutomagic("geom"))`},
+		{"simplify", domain.GeometryOptions{Simplify: 0.5}, `AsText(ST_SimplifyPreserveTopology(CastAutomagic("geom"), 0.5))`},
+		{"simplify geojson", domain.GeometryOptions{Format: domain.GeometryFormatGeoJSON, Simplify: 10},
+			`AsGeoJSON(ST_SimplifyPreserveTopology(CastAutomagic("geom"), 10)) AS "__ortus_geojson", AsText(ST_SimplifyPreserveTopology(CastAutomagic("geom"), 10))`},
+		{"negative simplify ignored", domain.GeometryOptions{Simplify: -1}, `AsText(CastAutomagic("geom"))`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := geometrySelect("geom", tt.opts, -1); got != tt.want {
+				t.Errorf("geometrySelect = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// With a precision GeoJSON and GML are written with that many decimals.
+	if got, want := geometrySelect("geom", domain.GeometryOptions{Format: domain.GeometryFormatGeoJSON}, 6),
+		`AsGeoJSON(CastAutomagic("geom"), 6) AS "__ortus_geojson", AsText(CastAutomagic("geom"))`; got != want {
+		t.Errorf("geometrySelect = %q, want %q", got, want)
+	}
+	if got, want := geometrySelect("geom", domain.GeometryOptions{Format: domain.GeometryFormatGML}, 2),
+		`AsGML(3, CastAutomagic("geom"), 2) AS "__ortus_gml", AsText(CastAutomagic("geom"))`; got != want {
+		t.Errorf("geometrySelect = %q, want %q", got, want)
+	}
+}
+
+func TestBuildFeatureEncodedGeometry(t *testing.T) {
+	columns := []string{"fid", "geom", "name", colDistance, colGeomWKB, colGeomGeoJSON, colGeomGML, "AsText"}
+	values := []interface{}{
+		int64(7), []byte{0x01}, "Mitte", 12.5,
+		[]byte{0x01, 0x01}, []byte(`{"type":"Point","coordinates":[1,2]}`), "<gml:Point/>",
+		"POINT(1 2)",
+	}
+	f := buildFeature(columns, values, "districts", "geom", featureReader{})
+
+	if f.ID != 7 || f.Geometry.WKT != "POINT(1 2)" {
+		t.Errorf("feature = %+v", f)
+	}
+	if string(f.Geometry.WKB) != "\x01\x01" {
+		t.Errorf("WKB = %x", f.Geometry.WKB)
+	}
+	if f.Geometry.GeoJSON != `{"type":"Point","coordinates":[1,2]}` {
+		t.Errorf("GeoJSON = %q", f.Geometry.GeoJSON)
+	}
+	if f.Geometry.GML != "<gml:Point/>" {
+		t.Errorf("GML = %q", f.Geometry.GML)
+	}
+	if f.Match.DistanceM != 12.5 {
+		t.Errorf("Match.DistanceM = %v, want 12.5", f.Match.DistanceM)
+	}
+	if len(f.Properties) != 1 || f.Properties["name"] != "Mitte" {
+		t.Errorf("Properties = %v, want only name (encoded columns must not leak)", f.Properties)
+	}
+}
+
+func TestBuildFeaturePropertyMappings(t *testing.T) {
+	columns := []string{"fid", "geom", "GEN", "ags", "beginn", "note", "AsText"}
+	values := []interface{}{int64(1), []byte{0x01}, "Mitte", "011", "01.03.2024", "x", "POINT(1 2)"}
+	mappings := domain.PropertyMappings{
+		{Column: "gen", Name: "name"},
+		{Column: "AGS", Name: "ags", Type: domain.PropertyTypeInteger},
+		{Column: "BEGINN", Name: "valid_from", Type: domain.PropertyTypeDate},
+	}.ForColumns(columns)
+
+	f := buildFeature(columns, values, "districts", "geom", featureReader{mappings: mappings})
+
+	want := map[string]interface{}{"name": "Mitte", "ags": int64(11), "valid_from": "2024-03-01", "note": "x"}
+	if !reflect.DeepEqual(f.Properties, want) {
+		t.Errorf("Properties = %v, want %v", f.Properties, want)
+	}
+}
+
+func TestApplyDatasetMetadataLayerHeight(t *testing.T) {
+	src := &domain.Source{Layers: []domain.Layer{{Name: "buildings"}, {Name: "parcels"}}}
+	applyDatasetMetadata(src, `{"layers": {"buildings": {"height": {"min": "base_height", "max": " roof_height "}}}}`)
+
+	want := domain.LayerHeight{MinAttribute: "base_height", MaxAttribute: "roof_height"}
+	if src.Layers[0].Height != want {
+		t.Errorf("buildings height = %+v, want %+v", src.Layers[0].Height, want)
+	}
+	if !src.Layers[1].Height.IsZero() {
+		t.Errorf("parcels height = %+v, want none", src.Layers[1].Height)
+	}
+}
+
+func TestDistanceSelect(t *testing.T) {
+	wgs84 := distanceSelect(&domain.Layer{GeometryColumn: "geom", SRID: domain.SRIDWGS84})
+	if want := `ST_Distance(CastAutomagic("geom"), GeomFromText(?, ?), 1) AS "__ortus_distance"`; wgs84 != want {
+		t.Errorf("WGS 84 layer: %s, want %s", wgs84, want)
+	}
+	utm := distanceSelect(&domain.Layer{GeometryColumn: "geom", SRID: domain.SRIDETRS89UTM32N})
+	if want := `ST_Distance(CastAutomagic("geom"), GeomFromText(?, ?)) AS "__ortus_distance"`; utm != want {
+		t.Errorf("UTM layer: %s, want %s", utm, want)
+	}
+}
+
+func TestComputedSelect(t *testing.T) {
+	if got := computedSelect(&domain.Layer{GeometryColumn: "geom"}); got != "" {
+		t.Errorf("computedSelect without computed properties = %q, want empty", got)
+	}
+	layer := &domain.Layer{
+		GeometryColumn: "shape",
+		Computed: []domain.ComputedProperty{
+			{Name: "area_m2", Expr: "ST_Area({geom}, 1)"},
+			{Name: `odd"name`, Expr: "EW % 10"},
+		},
+	}
+	want := `(ST_Area(CastAutomagic("shape"), 1)) AS "area_m2", (EW % 10) AS "odd""name", `
+	if got := computedSelect(layer); got != want {
+		t.Errorf("computedSelect = %q, want %q", got, want)
+	}
+}
+
+func TestCoveringGeometry(t *testing.T) {
+	if got, want := coveringGeometry("t", "geom", false), `CastAutomagic(t."geom")`; got != want {
+		t.Errorf("without repair: %s, want %s", got, want)
+	}
+	want := `CASE WHEN ST_IsValid(CastAutomagic("geom")) = 0 THEN ST_CollectionExtract(ST_MakeValid(CastAutomagic("geom")), 3) ELSE CastAutomagic("geom") END`
+	if got := coveringGeometry("", "geom", true); got != want {
+		t.Errorf("with repair: %s, want %s", got, want)
+	}
+
+	repo := NewRepository(Options{MakeValid: true})
+	for _, tt := range []struct {
+		layer domain.Layer
+		want  bool
+	}{
+		{domain.Layer{}, true},
+		{domain.Layer{ValidityChecked: true, InvalidGeometries: 2}, true},
+		{domain.Layer{ValidityChecked: true}, false},
+	} {
+		if got := repo.repairGeometries(&tt.layer); got != tt.want {
+			t.Errorf("repairGeometries(%+v) = %v, want %v", tt.layer, got, tt.want)
+		}
+	}
+	if NewRepository(Options{}).repairGeometries(&domain.Layer{}) {
+		t.Error("repairGeometries without MakeValid = true")
+	}
+}
@@ -0,0 +1,151 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// Repository implements output.RouteQuerier.
+var _ output.RouteQuerier = (*Repository)(nil)
+
+// QueryRoute returns the features of a layer whose geometry intersects the
+// route, in one query: the R-tree pre-filters candidates by the bbox of each
+// route segment (json_each unrolls the segments), ST_Intersects confirms them
+// against the whole line. Without an R-tree it scans the table. Fragments of
+// ST_Subdivide-tiled polygons the route crosses are deduplicated like in
+// QueryPoint. The route must already be in the layer's SRID.
+func (r *Repository) QueryRoute(ctx context.Context, sourceID, layerName string, route domain.Route) ([]domain.Feature, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository.QueryRoute",
+		output.WithSpanKind(output.SpanKindClient),
+		output.WithAttributes(
+			output.String("db.system", "sqlite"),
+			output.String("ortus.source.id", sourceID),
+			output.String("ortus.layer.name", layerName),
+			output.Int("ortus.route.points", len(route.Points)),
+		),
+	)
+	defer span.End()
+
+	r.mu.RLock()
+	db, ok := r.connections[sourceID]
+	src := r.sources[sourceID]
+	r.mu.RUnlock()
+	if !ok {
+		span.RecordError(domain.ErrSourceNotFound)
+		span.SetStatus(output.StatusError, "source not found")
+		return nil, domain.ErrSourceNotFound
+	}
+	layer, found := src.GetLayer(layerName)
+	if !found {
+		span.RecordError(domain.ErrLayerNotFound)
+		span.SetStatus(output.StatusError, "layer not found")
+		return nil, domain.ErrLayerNotFound
+	}
+
+	indexTable := rtreeName(layer.Name, layer.GeometryColumn)
+	indexed := tableExists(ctx, db, indexTable)
+	span.SetAttributes(output.Bool("ortus.rtree.used", indexed))
+	query := buildRouteQuery(layer, indexTable, indexed, r.repairGeometries(layer) && layer.IsPolygonLayer(), r.decimals(layer))
+	span.SetAttributes(output.String("db.statement", query))
+
+	var args []interface{}
+	if indexed {
+		segments, err := marshalSegmentsJSON(route.Segments())
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(output.StatusError, "marshal segments failed")
+			return nil, err
+		}
+		args = append(args, segments)
+	}
+	args = append(args, route.WKT(), layer.StoredSRID())
+	return r.queryShapeFeatures(ctx, span, db, layer, query, args, domain.MatchIntersects)
+}
+
+// queryShapeFeatures runs a shape query (route, area) built for layer and
+// scans its rows, marking every feature with kind. Fragments of
+// ST_Subdivide-tiled polygons are deduplicated like in QueryPoint.
+func (r *Repository) queryShapeFeatures(ctx context.Context, span output.Span, db *sql.DB, layer *domain.Layer, query string, args []interface{}, kind domain.MatchKind) ([]domain.Feature, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "query failed")
+		return nil, &domain.QueryError{Layer: layer.Name, Err: err}
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "columns failed")
+		return nil, err
+	}
+	reader := r.featureReader(layer, columns)
+	var features []domain.Feature
+	for rows.Next() {
+		feature, err := scanFeature(rows, columns, layer.Name, layer.GeometryColumn, reader)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(output.StatusError, "scan failed")
+			return nil, err
+		}
+		feature.Match.Kind = kind
+		features = append(features, feature)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "rows iteration failed")
+		return nil, err
+	}
+	if layer.IsPolygonLayer() {
+		features = dedupFeaturesByProperties(features)
+	}
+	span.SetAttributes(output.Int("ortus.features.count", len(features)))
+	return features, nil
+}
+
+// buildRouteQuery builds the route query of layer. Indexed, its first
+// parameter is the segment bboxes as JSON (see marshalSegmentsJSON); the
+// route WKT and the layer's stored SRID follow.
+func buildRouteQuery(layer *domain.Layer, indexTable string, indexed, repair bool, decimals int) string {
+	// %[1]s = layer table, %[2]s = rtree table, %[3]s = result columns, %[4]s =
+	// the geometry ST_Intersects tests.
+	prefilter := ""
+	if indexed {
+		prefilter = `t.rowid IN (
+			SELECT r.id
+			FROM json_each(?) s
+			INNER JOIN "%[2]s" r
+			  ON r.minx <= CAST(s.value->>'maxx' AS REAL) AND r.maxx >= CAST(s.value->>'minx' AS REAL)
+			 AND r.miny <= CAST(s.value->>'maxy' AS REAL) AND r.maxy >= CAST(s.value->>'miny' AS REAL)
+		) AND `
+	}
+	return fmt.Sprintf(`
+		SELECT t.*, %[3]s
+		FROM "%[1]s" t
+		WHERE `+prefilter+`ST_Intersects(%[4]s, GeomFromText(?, ?))
+	`, layer.Name, indexTable,
+		computedSelect(layer)+geometrySelect(layer.GeometryColumn, domain.GeometryOptions{}, decimals),
+		coveringGeometry("t", layer.GeometryColumn, repair),
+	) //#nosec G201 -- identifiers from gpkg catalog, double-quoted; SQLite can't parameterize identifiers
+}
+
+// marshalSegmentsJSON encodes segment bboxes as [{"minx":..,...},...].
+func marshalSegmentsJSON(segments []domain.Extent) (string, error) {
+	boxes := make([]struct {
+		MinX float64 `json:"minx"`
+		MinY float64 `json:"miny"`
+		MaxX float64 `json:"maxx"`
+		MaxY float64 `json:"maxy"`
+	}, len(segments))
+	for i, s := range segments {
+		boxes[i].MinX, boxes[i].MinY, boxes[i].MaxX, boxes[i].MaxY = s.MinX, s.MinY, s.MaxX, s.MaxY
+	}
+	b, err := json.Marshal(boxes)
+	return string(b), err
+}
@@ -0,0 +1,61 @@
+package geopackage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// routeAcrossWestAndEast runs from inside "west" through the gap into "east".
+func routeAcrossWestAndEast() domain.Route {
+	return domain.NewRoute([]domain.Coordinate{{X: 2, Y: 2}, {X: 8, Y: 2}}, 4326)
+}
+
+// TestQueryRouteUnindexed: without an R-tree, ST_Intersects alone finds the
+// regions the line crosses and marks them as intersected.
+func TestQueryRouteUnindexed(t *testing.T) {
+	repo, _ := newFixtureRepo(t)
+	features, err := repo.QueryRoute(context.Background(), "regions", "regions", routeAcrossWestAndEast())
+	if err != nil {
+		t.Fatalf("QueryRoute: %v", err)
+	}
+	assertFeatureNames(t, features, []string{"west", "east"})
+	for _, f := range features {
+		if f.Match.Kind != domain.MatchIntersects {
+			t.Errorf("feature %q match = %+v, want kind intersects", f.GetStringProperty("name"), f.Match)
+		}
+	}
+}
+
+// TestQueryRouteIndexed: the per-segment R-tree prefilter keeps the regions
+// a bent route touches and drops the ones between its segments' boxes.
+func TestQueryRouteIndexed(t *testing.T) {
+	repo, _ := newFixtureRepo(t)
+	ctx := context.Background()
+	if err := repo.CreateSpatialIndex(ctx, "regions", "regions"); err != nil {
+		t.Fatalf("CreateSpatialIndex: %v", err)
+	}
+
+	features, err := repo.QueryRoute(ctx, "regions", "regions", routeAcrossWestAndEast())
+	if err != nil {
+		t.Fatalf("QueryRoute: %v", err)
+	}
+	assertFeatureNames(t, features, []string{"west", "east"})
+
+	// Crosses the cut edge of "tiled" (deduplicated to one) and ends in the gap.
+	tiled := domain.NewRoute([]domain.Coordinate{{X: 11, Y: 1}, {X: 15, Y: 3}}, 4326)
+	features, err = repo.QueryRoute(ctx, "regions", "regions", tiled)
+	if err != nil {
+		t.Fatalf("QueryRoute: %v", err)
+	}
+	assertFeatureNames(t, features, []string{"tiled"})
+
+	// Runs above every region.
+	miss := domain.NewRoute([]domain.Coordinate{{X: 0, Y: 10}, {X: 20, Y: 10}}, 4326)
+	features, err = repo.QueryRoute(ctx, "regions", "regions", miss)
+	if err != nil {
+		t.Fatalf("QueryRoute: %v", err)
+	}
+	assertFeatureNames(t, features, nil)
+}
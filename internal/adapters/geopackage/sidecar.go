@@ -0,0 +1,61 @@
+package geopackage
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// sidecarMetadata is the optional <name>.meta.yaml (or .meta.json) next to a
+// GeoPackage, for providers that cannot write gpkg_metadata rows. The license
+// block has the same shape as the embedded ortus document; YAML being a
+// superset of JSON, one decoder reads both files.
+type sidecarMetadata struct {
+	License struct {
+		Name        string `yaml:"name"`
+		URL         string `yaml:"url"`
+		Attribution string `yaml:"attribution"`
+	} `yaml:"license"`
+	Description string   `yaml:"description"`
+	Keywords    []string `yaml:"keywords"`
+}
+
+// readSidecar applies the first sidecar found next to path. A missing or
+// malformed sidecar is skipped, not fatal — the source still loads with
+// whatever the package itself carries.
+func readSidecar(src *domain.Source, path string) {
+	for _, name := range domain.SidecarNames(path) {
+		raw, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		var doc sidecarMetadata
+		if err := yaml.Unmarshal(raw, &doc); err != nil {
+			return
+		}
+		doc.apply(src)
+		return
+	}
+}
+
+// apply overlays the fields the sidecar sets onto src; fields it leaves empty
+// keep the values read from gpkg_metadata.
+func (doc *sidecarMetadata) apply(src *domain.Source) {
+	if doc.License.Name != "" {
+		src.License.Name = doc.License.Name
+	}
+	if doc.License.URL != "" {
+		src.License.URL = doc.License.URL
+	}
+	if doc.License.Attribution != "" {
+		src.License.Attribution = doc.License.Attribution
+	}
+	if doc.Description != "" {
+		src.Metadata.Description = doc.Description
+	}
+	if len(doc.Keywords) > 0 {
+		src.Metadata.Keywords = doc.Keywords
+	}
+}
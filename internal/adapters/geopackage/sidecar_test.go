@@ -0,0 +1,71 @@
+package geopackage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func TestReadSidecar(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "parcels.gpkg")
+
+	embedded := func() *domain.Source {
+		return &domain.Source{
+			License:  domain.License{Name: "CC-BY-4.0", Attribution: "© Embedded"},
+			Metadata: domain.Metadata{Description: "embedded"},
+		}
+	}
+
+	// No sidecar: the embedded metadata stays.
+	src := embedded()
+	readSidecar(src, path)
+	if src.License.Attribution != "© Embedded" || src.Metadata.Description != "embedded" {
+		t.Errorf("without sidecar: %+v", src)
+	}
+
+	// The JSON sidecar overrides only what it sets.
+	writeFile(t, filepath.Join(dir, "parcels.meta.json"),
+		`{"license": {"attribution": "© Provider"}, "keywords": ["cadastre", "parcels"]}`)
+	src = embedded()
+	readSidecar(src, path)
+	if src.License.Name != "CC-BY-4.0" || src.License.Attribution != "© Provider" {
+		t.Errorf("license = %+v", src.License)
+	}
+	if src.Metadata.Description != "embedded" || !src.Metadata.HasKeyword("cadastre") {
+		t.Errorf("metadata = %+v", src.Metadata)
+	}
+
+	// The YAML sidecar wins over the JSON one.
+	writeFile(t, filepath.Join(dir, "parcels.meta.yaml"), `
+license:
+  name: dl-de/by-2-0
+  url: https://www.govdata.de/dl-de/by-2-0
+description: Flurstücke
+`)
+	src = embedded()
+	readSidecar(src, path)
+	if src.License.Name != "dl-de/by-2-0" || src.License.URL == "" || src.Metadata.Description != "Flurstücke" {
+		t.Errorf("yaml sidecar: %+v", src)
+	}
+	if len(src.Metadata.Keywords) != 0 {
+		t.Errorf("keywords = %v, want none from the yaml sidecar", src.Metadata.Keywords)
+	}
+
+	// A malformed sidecar is skipped.
+	writeFile(t, filepath.Join(dir, "parcels.meta.yaml"), "license: [unterminated")
+	src = embedded()
+	readSidecar(src, path)
+	if src.License.Attribution != "© Embedded" {
+		t.Errorf("malformed sidecar applied: %+v", src.License)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,10 +3,13 @@ package geopackage
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"math"
 	"strings"
 
+	"github.com/mattn/go-sqlite3"
+
 	"github.com/jobrunner/ortus/internal/domain"
 	"github.com/jobrunner/ortus/internal/ports/output"
 )
@@ -15,27 +18,80 @@ import (
 // against fixed whitelists before being concatenated, so an invalid or hostile
 // config value cannot break DB open or smuggle extra DSN parameters via '&'.
 // Defaults to a private cache (each connection gets its own — allows true
-// concurrent reads, unlike the legacy shared cache).
+// concurrent reads, unlike the legacy shared cache). ReadOnly opens the file
+// with mode=ro and immutable=1: SQLite then takes no locks and never checks
+// the file for changes, which a read-only volume cannot hold anyway; a
+// journal mode would need a write and is left out.
 func dsnFor(path string, opts Options) string {
 	params := []string{"cache=" + normalizeCacheMode(opts.CacheMode)}
 	if opts.BusyTimeoutMS > 0 {
 		params = append(params, fmt.Sprintf("_busy_timeout=%d", opts.BusyTimeoutMS))
 	}
-	if jm := normalizeJournalMode(opts.JournalMode); jm != "" {
+	if opts.ReadOnly {
+		params = append(params, "mode=ro", "immutable=1")
+	} else if jm := normalizeJournalMode(opts.JournalMode); jm != "" {
 		params = append(params, "_journal_mode="+jm)
 	}
 	return fmt.Sprintf("file:%s?%s", path, strings.Join(params, "&"))
 }
 
+// pragmasFor returns the PRAGMA statements run on every new connection. Like
+// dsnFor it only emits whitelisted values, so nothing from config reaches the
+// SQL verbatim; unrecognized values leave SQLite's default in place.
+func pragmasFor(opts Options) []string {
+	var pragmas []string
+	if s := strings.ToUpper(strings.TrimSpace(opts.Synchronous)); validSynchronous[s] {
+		pragmas = append(pragmas, "PRAGMA synchronous = "+s)
+	}
+	if opts.MmapSizeMB > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA mmap_size = %d", int64(opts.MmapSizeMB)<<20))
+	}
+	if opts.CacheSizeMB > 0 {
+		// A negative cache_size is in KiB rather than pages.
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA cache_size = -%d", opts.CacheSizeMB<<10))
+	}
+	if t := strings.ToUpper(strings.TrimSpace(opts.TempStore)); validTempStores[t] {
+		pragmas = append(pragmas, "PRAGMA temp_store = "+t)
+	}
+	return pragmas
+}
+
+// pragmaConnector opens connections through the SpatiaLite driver and runs
+// the configured pragmas on each, since database/sql pools connections and a
+// pragma set once would only reach one of them.
+type pragmaConnector struct {
+	dsn     string
+	pragmas []string
+}
+
+func (c *pragmaConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := spatiaLiteDriver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	for _, pragma := range c.pragmas {
+		if _, err := conn.(*sqlite3.SQLiteConn).Exec(pragma, nil); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%s: %w", pragma, err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *pragmaConnector) Driver() driver.Driver { return spatiaLiteDriver }
+
 // openSpatiaLite opens a SpatiaLite-backed SQLite connection with the configured
 // pool limits and verifies it is reachable. It is the single open path shared by
 // the vector Repository and the GazetteerIndex, so both use the same registered
-// cgo driver, DSN whitelist, and pool policy.
+// cgo driver, DSN whitelist, pragmas, and pool policy.
 func openSpatiaLite(ctx context.Context, path string, opts Options) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3_with_extensions", dsnFor(path, opts))
-	if err != nil {
-		return nil, err
-	}
+	return openSpatiaLiteDSN(ctx, dsnFor(path, opts), opts)
+}
+
+// openSpatiaLiteDSN is openSpatiaLite for a DSN built elsewhere (an in-memory
+// copy, see openInMemory).
+func openSpatiaLiteDSN(ctx context.Context, dsn string, opts Options) (*sql.DB, error) {
+	db := sql.OpenDB(&pragmaConnector{dsn: dsn, pragmas: pragmasFor(opts)})
 	if opts.MaxOpenConns > 0 {
 		db.SetMaxOpenConns(opts.MaxOpenConns)
 	}
@@ -49,6 +105,23 @@ func openSpatiaLite(ctx context.Context, path string, opts Options) (*sql.DB, er
 	return db, nil
 }
 
+// SpatiaLiteVersion loads the SpatiaLite extension into an in-memory database
+// and returns its version. It fails exactly when no GeoPackage could be opened,
+// so it serves as a startup probe (`ortus config validate`).
+func SpatiaLiteVersion(ctx context.Context) (string, error) {
+	db, err := openSpatiaLite(ctx, ":memory:", Options{})
+	if err != nil {
+		return "", fmt.Errorf("SpatiaLite extension not available: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT spatialite_version()").Scan(&version); err != nil {
+		return "", fmt.Errorf("SpatiaLite extension not available: %w", err)
+	}
+	return version, nil
+}
+
 // GazetteerIndex implements the output.SpatialIndex port against the dedicated
 // gazetteer GeoPackage (places + admin_levels). It owns its own connection but
 // reuses this package's registered cgo/SpatiaLite driver, so cgo stays confined
@@ -277,7 +350,7 @@ func (g *GazetteerIndex) runFeatureQuery(ctx context.Context, layer, geom, query
 	}
 	var features []domain.Feature
 	for rows.Next() {
-		f, err := scanFeature(rows, columns, layer, geom)
+		f, err := scanFeature(rows, columns, layer, geom, featureReader{})
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,60 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// checkLayerSRID cross-checks the layer's declared SRID against
+// gpkg_spatial_ref_sys and records what is wrong with it in l.SRIDWarning,
+// and whether it is geographic in l.Geographic.
+// The GeoPackage spec reserves -1 (undefined Cartesian) and 0 (undefined
+// geographic): no coordinate can be transformed into them, so such a layer
+// silently matches nothing. With a fallback > 0 the layer is served as if it
+// declared the fallback instead.
+func checkLayerSRID(ctx context.Context, db *sql.DB, l *domain.Layer, fallback int) {
+	if l.SRID == 0 || l.SRID == -1 {
+		l.SRIDWarning = fmt.Sprintf("layer declares the undefined SRID %d", l.SRID)
+		if fallback > 0 {
+			l.DeclaredSRID = l.SRID
+			l.SRID = fallback
+			l.SRIDAssumed = true
+			l.SRIDWarning += fmt.Sprintf("; assuming EPSG:%d", fallback)
+			l.Geographic = fallback == domain.SRIDWGS84
+		}
+		return
+	}
+
+	var definition sql.NullString
+	err := db.QueryRowContext(ctx,
+		"SELECT definition FROM gpkg_spatial_ref_sys WHERE srs_id = ?", l.SRID,
+	).Scan(&definition)
+	switch {
+	case err != nil:
+		l.SRIDWarning = fmt.Sprintf("SRID %d is not defined in gpkg_spatial_ref_sys", l.SRID)
+	case !definition.Valid || strings.TrimSpace(definition.String) == "" ||
+		strings.EqualFold(strings.TrimSpace(definition.String), "undefined"):
+		l.SRIDWarning = fmt.Sprintf("SRID %d has no definition in gpkg_spatial_ref_sys", l.SRID)
+	default:
+		l.Geographic = isGeographicCRS(definition.String)
+	}
+	if l.SRIDWarning != "" {
+		l.Geographic = l.SRID == domain.SRIDWGS84
+	}
+}
+
+// isGeographicCRS reports whether a WKT CRS definition (WKT1 or WKT2) is
+// geographic.
+func isGeographicCRS(definition string) bool {
+	d := strings.ToUpper(strings.TrimSpace(definition))
+	for _, kw := range []string{"GEOGCS[", "GEOGCRS[", "GEOGRAPHICCRS["} {
+		if strings.HasPrefix(d, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package geopackage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func TestCheckLayerSRID(t *testing.T) {
+	db := openPlainSQLite(t,
+		"CREATE TABLE gpkg_spatial_ref_sys (srs_id INTEGER PRIMARY KEY, definition TEXT NOT NULL)",
+		"INSERT INTO gpkg_spatial_ref_sys VALUES (4326, 'GEOGCS[\"WGS 84\"]'), (25832, 'undefined'), (3035, 'PROJCS[\"ETRS89 / LAEA Europe\"]')",
+	)
+
+	tests := []struct {
+		name        string
+		srid        int
+		fallback    int
+		wantSRID    int
+		wantStored  int
+		wantWarning string
+		geographic  bool
+	}{
+		{name: "defined", srid: 4326, wantSRID: 4326, wantStored: 4326, geographic: true},
+		{name: "projected", srid: 3035, wantSRID: 3035, wantStored: 3035},
+		{name: "not in table", srid: 31467, wantSRID: 31467, wantStored: 31467, wantWarning: "not defined in gpkg_spatial_ref_sys"},
+		{name: "undefined definition", srid: 25832, wantSRID: 25832, wantStored: 25832, wantWarning: "has no definition"},
+		{name: "zero", srid: 0, wantSRID: 0, wantStored: 0, wantWarning: "undefined SRID 0"},
+		{name: "minus one with fallback", srid: -1, fallback: 25832, wantSRID: 25832, wantStored: -1, wantWarning: "assuming EPSG:25832"},
+		{name: "zero with WGS 84 fallback", srid: 0, fallback: 4326, wantSRID: 4326, wantStored: 0, wantWarning: "assuming EPSG:4326", geographic: true},
+		{name: "fallback leaves defined alone", srid: 4326, fallback: 25832, wantSRID: 4326, wantStored: 4326, geographic: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := domain.Layer{Name: "parcels", SRID: tt.srid}
+			checkLayerSRID(context.Background(), db, &l, tt.fallback)
+			if l.SRID != tt.wantSRID || l.StoredSRID() != tt.wantStored {
+				t.Errorf("SRID = %d, stored = %d, want %d, %d", l.SRID, l.StoredSRID(), tt.wantSRID, tt.wantStored)
+			}
+			if tt.wantWarning == "" && l.SRIDWarning != "" || !strings.Contains(l.SRIDWarning, tt.wantWarning) {
+				t.Errorf("SRIDWarning = %q, want %q", l.SRIDWarning, tt.wantWarning)
+			}
+			if l.Geographic != tt.geographic {
+				t.Errorf("Geographic = %v, want %v", l.Geographic, tt.geographic)
+			}
+		})
+	}
+}
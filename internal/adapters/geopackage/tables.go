@@ -0,0 +1,240 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+var _ output.AttributeTableReader = (*Repository)(nil)
+
+// Tables lists the attribute tables (gpkg_contents data_type 'attributes') of
+// an open GeoPackage.
+func (r *Repository) Tables(ctx context.Context, sourceID string) ([]domain.AttributeTable, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository.Tables",
+		output.WithSpanKind(output.SpanKindClient),
+		output.WithAttributes(
+			output.String("db.system", "sqlite"),
+			output.String("ortus.source.id", sourceID),
+		),
+	)
+	defer span.End()
+
+	db, err := r.connection(sourceID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT table_name, COALESCE(description, '')
+		FROM gpkg_contents
+		WHERE data_type = 'attributes'
+		ORDER BY table_name
+	`)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("reading attribute tables: %w", err)
+	}
+	var tables []domain.AttributeTable
+	for rows.Next() {
+		var t domain.AttributeTable
+		if err := rows.Scan(&t.Name, &t.Description); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scanning attribute table: %w", err)
+		}
+		tables = append(tables, t)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range tables {
+		if tables[i].Columns, err = tableColumns(ctx, db, tables[i].Name); err != nil {
+			return nil, err
+		}
+		if err := db.QueryRowContext(ctx, tableSelect("COUNT(*)", tables[i].Name, nil, false)).Scan(&tables[i].RowCount); err != nil {
+			return nil, fmt.Errorf("counting rows of %s: %w", tables[i].Name, err)
+		}
+	}
+	span.SetAttributes(output.Int("ortus.tables.count", len(tables)))
+	return tables, nil
+}
+
+// ReadTable returns the page of rows q selects from an attribute table. Only
+// tables registered as attributes in gpkg_contents are readable, so neither
+// feature tables nor the gpkg_* system tables are exposed.
+func (r *Repository) ReadTable(ctx context.Context, sourceID, table string, q domain.TableQuery) (domain.TableRows, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository.ReadTable",
+		output.WithSpanKind(output.SpanKindClient),
+		output.WithAttributes(
+			output.String("db.system", "sqlite"),
+			output.String("ortus.source.id", sourceID),
+			output.String("ortus.table.name", table),
+			output.Int("ortus.table.limit", q.Limit),
+			output.Int("ortus.table.offset", q.Offset),
+		),
+	)
+	defer span.End()
+
+	db, err := r.connection(sourceID)
+	if err != nil {
+		span.RecordError(err)
+		return domain.TableRows{}, err
+	}
+
+	err = db.QueryRowContext(ctx,
+		"SELECT table_name FROM gpkg_contents WHERE data_type = 'attributes' AND table_name = ? COLLATE NOCASE",
+		table,
+	).Scan(&table)
+	if errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(domain.ErrTableNotFound)
+		return domain.TableRows{}, domain.ErrTableNotFound
+	}
+	if err != nil {
+		return domain.TableRows{}, fmt.Errorf("looking up table: %w", err)
+	}
+
+	columns, err := tableColumns(ctx, db, table)
+	if err != nil {
+		return domain.TableRows{}, err
+	}
+	where, args, err := tableFilters(columns, q.Filters)
+	if err != nil {
+		return domain.TableRows{}, err
+	}
+
+	out := domain.TableRows{Columns: columns, Rows: []map[string]interface{}{}}
+	if err := db.QueryRowContext(ctx, tableSelect("COUNT(*)", table, where, false), args...).Scan(&out.Total); err != nil {
+		span.RecordError(err)
+		return domain.TableRows{}, fmt.Errorf("counting rows of %s: %w", table, err)
+	}
+
+	rows, err := db.QueryContext(ctx, tableSelect("*", table, where, true), append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		span.RecordError(err)
+		return domain.TableRows{}, fmt.Errorf("reading %s: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+	if err := scanTableRows(rows, &out); err != nil {
+		return domain.TableRows{}, fmt.Errorf("scanning %s: %w", table, err)
+	}
+	span.SetAttributes(output.Int("ortus.rows.count", len(out.Rows)))
+	return out, nil
+}
+
+// scanTableRows appends every row to out.Rows as a column -> value map.
+func scanTableRows(rows *sql.Rows, out *domain.TableRows) error {
+	names, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		values := make([]interface{}, len(names))
+		ptrs := make([]interface{}, len(names))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		row := make(map[string]interface{}, len(names))
+		for i, name := range names {
+			row[name] = values[i]
+		}
+		out.Rows = append(out.Rows, row)
+	}
+	return rows.Err()
+}
+
+// connection returns the database of an open source.
+func (r *Repository) connection(sourceID string) (*sql.DB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	db, ok := r.connections[sourceID]
+	if !ok {
+		return nil, domain.ErrSourceNotFound
+	}
+	return db, nil
+}
+
+// tableColumns returns the column names of table in table order.
+func tableColumns(ctx context.Context, db *sql.DB, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?) ORDER BY cid", table)
+	if err != nil {
+		return nil, fmt.Errorf("reading columns of %s: %w", table, err)
+	}
+	defer func() { _ = rows.Close() }()
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
+
+// tableFilters resolves the filter columns against the table's columns
+// (case-insensitively, as SQLite does) and returns them in a stable order
+// with their values.
+func tableFilters(columns []string, filters map[string]string) ([]string, []interface{}, error) {
+	known := make(map[string]string, len(columns))
+	for _, c := range columns {
+		known[strings.ToLower(c)] = c
+	}
+	keys := make([]string, 0, len(filters))
+	for k := range filters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	where := make([]string, 0, len(keys))
+	args := make([]interface{}, 0, len(keys)+2)
+	for _, k := range keys {
+		col, ok := known[strings.ToLower(k)]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: unknown column %q", domain.ErrInvalidInput, k)
+		}
+		where = append(where, col)
+		args = append(args, filters[k])
+	}
+	return where, args, nil
+}
+
+// tableSelect builds "SELECT what FROM table [WHERE col = ? AND ...]", with a
+// trailing "LIMIT ? OFFSET ?" when paged. Identifiers are quoted, and table
+// and columns come from the package's own catalog.
+func tableSelect(what, table string, where []string, paged bool) string {
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	b.WriteString(what)
+	b.WriteString(" FROM ")
+	b.WriteString(quoteIdent(table))
+	for i, col := range where {
+		if i == 0 {
+			b.WriteString(" WHERE ")
+		} else {
+			b.WriteString(" AND ")
+		}
+		b.WriteString(quoteIdent(col))
+		b.WriteString(" = ?")
+	}
+	if paged {
+		b.WriteString(" LIMIT ? OFFSET ?")
+	}
+	return b.String()
+}
+
+// quoteIdent quotes an SQL identifier, doubling embedded quotes.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
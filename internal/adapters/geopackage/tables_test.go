@@ -0,0 +1,75 @@
+package geopackage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// newTablesRepository opens a package with one attribute table (codes) next
+// to a feature table and a plain table not registered in gpkg_contents.
+func newTablesRepository(t *testing.T) *Repository {
+	t.Helper()
+	db := openPlainSQLite(t,
+		"CREATE TABLE gpkg_contents (table_name TEXT PRIMARY KEY, data_type TEXT NOT NULL, description TEXT)",
+		"INSERT INTO gpkg_contents VALUES ('codes', 'attributes', 'District types'), ('districts', 'features', '')",
+		"CREATE TABLE codes (fid INTEGER PRIMARY KEY, code TEXT, level INTEGER)",
+		"INSERT INTO codes (code, level) VALUES ('BZ', 1), ('OT', 2), ('ST', 2)",
+		"CREATE TABLE districts (fid INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE scratch (x INTEGER)",
+	)
+	r := NewRepository(Options{})
+	r.connections["berlin"] = db
+	return r
+}
+
+func TestRepositoryTables(t *testing.T) {
+	r := newTablesRepository(t)
+
+	tables, err := r.Tables(context.Background(), "berlin")
+	if err != nil {
+		t.Fatalf("Tables: %v", err)
+	}
+	if len(tables) != 1 || tables[0].Name != "codes" || tables[0].RowCount != 3 ||
+		tables[0].Description != "District types" || len(tables[0].Columns) != 3 {
+		t.Fatalf("Tables = %+v", tables)
+	}
+
+	if _, err := r.Tables(context.Background(), "unknown"); !errors.Is(err, domain.ErrSourceNotFound) {
+		t.Errorf("unknown source: err = %v", err)
+	}
+}
+
+func TestRepositoryReadTable(t *testing.T) {
+	r := newTablesRepository(t)
+	ctx := context.Background()
+
+	page, err := r.ReadTable(ctx, "berlin", "codes", domain.TableQuery{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("ReadTable: %v", err)
+	}
+	if page.Total != 3 || len(page.Rows) != 1 || page.Rows[0]["code"] != "OT" {
+		t.Errorf("page = %+v", page)
+	}
+
+	// Filters match case-insensitively on the column name; an integer column
+	// compares against the text value by SQLite's affinity rules.
+	page, err = r.ReadTable(ctx, "berlin", "CODES", domain.TableQuery{Limit: 10, Filters: map[string]string{"Level": "2"}})
+	if err != nil {
+		t.Fatalf("ReadTable filtered: %v", err)
+	}
+	if page.Total != 2 || len(page.Rows) != 2 {
+		t.Errorf("filtered page = %+v", page)
+	}
+
+	if _, err := r.ReadTable(ctx, "berlin", "codes", domain.TableQuery{Limit: 10, Filters: map[string]string{"nope": "1"}}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("unknown column: err = %v", err)
+	}
+	for _, table := range []string{"districts", "scratch", "gpkg_contents", "missing"} {
+		if _, err := r.ReadTable(ctx, "berlin", table, domain.TableQuery{Limit: 10}); !errors.Is(err, domain.ErrTableNotFound) {
+			t.Errorf("%s: err = %v, want ErrTableNotFound", table, err)
+		}
+	}
+}
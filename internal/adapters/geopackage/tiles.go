@@ -0,0 +1,118 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+var _ output.TileReader = (*Repository)(nil)
+
+// readTileSets reads the tile pyramids from gpkg_contents and their zoom
+// range from gpkg_tile_matrix. A package without tiles has neither table
+// populated and yields none.
+func readTileSets(ctx context.Context, db *sql.DB) ([]domain.TileSet, error) {
+	if !hasTable(ctx, db, "gpkg_tile_matrix") {
+		return nil, nil
+	}
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+			c.table_name,
+			COALESCE(c.description, ''),
+			COALESCE(c.srs_id, 0),
+			COALESCE(MIN(m.zoom_level), 0), COALESCE(MAX(m.zoom_level), 0),
+			COALESCE(c.min_x, 0), COALESCE(c.min_y, 0),
+			COALESCE(c.max_x, 0), COALESCE(c.max_y, 0)
+		FROM gpkg_contents c
+		LEFT JOIN gpkg_tile_matrix m ON m.table_name = c.table_name
+		WHERE c.data_type = 'tiles'
+		GROUP BY c.table_name
+		ORDER BY c.table_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("reading tile sets: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var sets []domain.TileSet
+	for rows.Next() {
+		var ts domain.TileSet
+		var minX, minY, maxX, maxY float64
+		if err := rows.Scan(&ts.Name, &ts.Description, &ts.SRID, &ts.MinZoom, &ts.MaxZoom,
+			&minX, &minY, &maxX, &maxY); err != nil {
+			return nil, fmt.Errorf("scanning tile set: %w", err)
+		}
+		if minX != 0 || minY != 0 || maxX != 0 || maxY != 0 {
+			ts.Extent = &domain.Extent{MinX: minX, MinY: minY, MaxX: maxX, MaxY: maxY, SRID: ts.SRID}
+		}
+		sets = append(sets, ts)
+	}
+	return sets, rows.Err()
+}
+
+// Tile returns the tile_data of one tile of a tile set.
+func (r *Repository) Tile(ctx context.Context, sourceID, tileSet string, z, x, y int) ([]byte, error) {
+	ctx, span := r.tracer.Start(ctx, "Repository.Tile",
+		output.WithSpanKind(output.SpanKindClient),
+		output.WithAttributes(
+			output.String("db.system", "sqlite"),
+			output.String("ortus.source.id", sourceID),
+			output.String("ortus.tileset.name", tileSet),
+			output.Int("ortus.tile.z", z),
+			output.Int("ortus.tile.x", x),
+			output.Int("ortus.tile.y", y),
+		),
+	)
+	defer span.End()
+
+	r.mu.RLock()
+	db, ok := r.connections[sourceID]
+	src := r.sources[sourceID]
+	r.mu.RUnlock()
+	if !ok {
+		span.RecordError(domain.ErrSourceNotFound)
+		return nil, domain.ErrSourceNotFound
+	}
+
+	// Only tile sets read from gpkg_contents are served, so the table name
+	// below never comes from the request.
+	table := ""
+	if src != nil {
+		for _, ts := range src.TileSets {
+			if strings.EqualFold(ts.Name, tileSet) {
+				table = ts.Name
+				break
+			}
+		}
+	}
+	if table == "" {
+		span.RecordError(domain.ErrTableNotFound)
+		return nil, domain.ErrTableNotFound
+	}
+
+	var data []byte
+	err := db.QueryRowContext(ctx, tileSelect(table), z, x, y).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrTileNotFound
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "tile query failed")
+		return nil, fmt.Errorf("reading tile %d/%d/%d of %s: %w", z, x, y, table, err)
+	}
+	return data, nil
+}
+
+// tileSelect builds the tile lookup on a GeoPackage tile pyramid table.
+func tileSelect(table string) string {
+	var b strings.Builder
+	b.WriteString("SELECT tile_data FROM ")
+	b.WriteString(quoteIdent(table))
+	b.WriteString(" WHERE zoom_level = ? AND tile_column = ? AND tile_row = ?")
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package geopackage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+var pngTile = []byte("\x89PNG\r\n\x1a\n tile")
+
+func TestTileSets(t *testing.T) {
+	db := openPlainSQLite(t,
+		`CREATE TABLE gpkg_contents (table_name TEXT PRIMARY KEY, data_type TEXT NOT NULL, description TEXT,
+			min_x DOUBLE, min_y DOUBLE, max_x DOUBLE, max_y DOUBLE, srs_id INTEGER)`,
+		"INSERT INTO gpkg_contents VALUES ('ortho', 'tiles', 'Orthophoto', 13.0, 52.3, 13.8, 52.7, 3857), ('districts', 'features', '', 0, 0, 0, 0, 4326)",
+		"CREATE TABLE gpkg_tile_matrix (table_name TEXT, zoom_level INTEGER, matrix_width INTEGER, matrix_height INTEGER)",
+		"INSERT INTO gpkg_tile_matrix VALUES ('ortho', 10, 1024, 1024), ('ortho', 14, 16384, 16384)",
+		"CREATE TABLE ortho (id INTEGER PRIMARY KEY, zoom_level INTEGER, tile_column INTEGER, tile_row INTEGER, tile_data BLOB)",
+	)
+	if _, err := db.Exec("INSERT INTO ortho (zoom_level, tile_column, tile_row, tile_data) VALUES (14, 8800, 5373, ?)", pngTile); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	sets, err := readTileSets(ctx, db)
+	if err != nil {
+		t.Fatalf("readTileSets: %v", err)
+	}
+	if len(sets) != 1 || sets[0].Name != "ortho" || sets[0].SRID != 3857 ||
+		sets[0].MinZoom != 10 || sets[0].MaxZoom != 14 || sets[0].Extent == nil {
+		t.Fatalf("tile sets = %+v", sets)
+	}
+
+	r := NewRepository(Options{})
+	r.connections["berlin"] = db
+	r.sources["berlin"] = &domain.Source{ID: "berlin", TileSets: sets}
+
+	data, err := r.Tile(ctx, "berlin", "ORTHO", 14, 8800, 5373)
+	if err != nil || !bytes.Equal(data, pngTile) {
+		t.Fatalf("Tile = %q, %v", data, err)
+	}
+	if _, err := r.Tile(ctx, "berlin", "ortho", 14, 0, 0); !errors.Is(err, domain.ErrTileNotFound) {
+		t.Errorf("missing tile: err = %v", err)
+	}
+	if _, err := r.Tile(ctx, "berlin", "districts", 14, 8800, 5373); !errors.Is(err, domain.ErrTableNotFound) {
+		t.Errorf("feature table: err = %v", err)
+	}
+	if _, err := r.Tile(ctx, "unknown", "ortho", 14, 8800, 5373); !errors.Is(err, domain.ErrSourceNotFound) {
+		t.Errorf("unknown source: err = %v", err)
+	}
+}
+
+func TestTileSetsWithoutTileMatrix(t *testing.T) {
+	db := openPlainSQLite(t, "CREATE TABLE gpkg_contents (table_name TEXT PRIMARY KEY, data_type TEXT NOT NULL)")
+	sets, err := readTileSets(context.Background(), db)
+	if err != nil || sets != nil {
+		t.Errorf("readTileSets = %v, %v; want none", sets, err)
+	}
+}
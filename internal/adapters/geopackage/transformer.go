@@ -28,9 +28,11 @@ func TransformCoordinate(ctx context.Context, db *sql.DB, coord domain.Coordinat
 		return domain.Coordinate{}, fmt.Errorf("transforming coordinate: %w", err)
 	}
 
+	// Only the horizontal CRS changes; the height passes through.
 	return domain.Coordinate{
 		X:    x,
 		Y:    y,
+		Z:    coord.Z,
 		SRID: targetSRID,
 	}, nil
 }
@@ -0,0 +1,45 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// checkLayerValidity counts the geometries of the layer ST_IsValid rejects
+// into l.InvalidGeometries. It reads every geometry, so it runs only with
+// Options.CheckValidity. A layer the count fails on (e.g. a SpatiaLite built
+// without GEOS) stays unchecked.
+func checkLayerValidity(ctx context.Context, db *sql.DB, l *domain.Layer) {
+	//nolint:gocritic // sprintfQuotedString: SQL identifiers need double quotes, not Go's %q
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM "%s" WHERE ST_IsValid(CastAutomagic("%s")) = 0`, l.Name, l.GeometryColumn) //#nosec G201 -- identifiers from layer metadata read from the gpkg catalog, double-quoted
+	if err := db.QueryRowContext(ctx, query).Scan(&l.InvalidGeometries); err != nil {
+		l.InvalidGeometries = 0
+		return
+	}
+	l.ValidityChecked = true
+}
+
+// coveringGeometry is the SQL expression the spatial predicate of a query
+// tests: the stored geometry of column (qualified by alias when set), or with
+// repair the ST_MakeValid repair of those ST_IsValid rejects, cut down to its
+// polygons so ST_Covers accepts it. Repair only suits polygon layers.
+func coveringGeometry(alias, column string, repair bool) string {
+	geom := fmt.Sprintf(`CastAutomagic("%s")`, column)
+	if alias != "" {
+		geom = fmt.Sprintf(`CastAutomagic(%s."%s")`, alias, column)
+	}
+	if !repair {
+		return geom
+	}
+	return fmt.Sprintf(`CASE WHEN ST_IsValid(%[1]s) = 0 THEN ST_CollectionExtract(ST_MakeValid(%[1]s), 3) ELSE %[1]s END`, geom)
+}
+
+// repairGeometries reports whether queries on layer repair invalid
+// geometries: with Options.MakeValid, unless the validity check found the
+// layer clean.
+func (r *Repository) repairGeometries(layer *domain.Layer) bool {
+	return r.opts.MakeValid && (!layer.ValidityChecked || layer.InvalidGeometries > 0)
+}
@@ -0,0 +1,91 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// Verification levels for Options.Verify.
+const (
+	VerifyOff   = "off"   // open the file as is (the zero value behaves the same)
+	VerifyQuick = "quick" // conformance checks + PRAGMA quick_check
+	VerifyFull  = "full"  // conformance checks + PRAGMA integrity_check (reads every page)
+)
+
+// geoPackageApplicationIDs are the application_id values a GeoPackage may
+// carry: "GPKG" (1.2+) and the older "GP10"/"GP11".
+var geoPackageApplicationIDs = map[int64]bool{
+	0x47504B47: true, // GPKG
+	0x47503130: true, // GP10
+	0x47503131: true, // GP11
+}
+
+// requiredTables are the tables every GeoPackage must contain.
+var requiredTables = []string{"gpkg_spatial_ref_sys", "gpkg_contents"}
+
+// verifyGeoPackage checks that db is an intact, conformant GeoPackage before
+// any metadata is read from it, so a truncated download or a stray SQLite
+// file fails at load with a reason instead of with an SQL error on the first
+// query. level is one of the Verify* constants; "" and VerifyOff skip it.
+func verifyGeoPackage(ctx context.Context, db *sql.DB, level string) error {
+	var pragma string
+	switch level {
+	case "", VerifyOff:
+		return nil
+	case VerifyFull:
+		pragma = "PRAGMA integrity_check"
+	default:
+		pragma = "PRAGMA quick_check"
+	}
+
+	var appID int64
+	if err := db.QueryRowContext(ctx, "PRAGMA application_id").Scan(&appID); err != nil {
+		return fmt.Errorf("%w: not a readable SQLite database: %v", domain.ErrInvalidSource, err)
+	}
+	if !geoPackageApplicationIDs[appID] {
+		return fmt.Errorf("%w: application_id %#x is not a GeoPackage (want \"GPKG\")", domain.ErrInvalidSource, appID)
+	}
+	for _, table := range requiredTables {
+		if !hasTable(ctx, db, table) {
+			return fmt.Errorf("%w: required table %s is missing", domain.ErrInvalidSource, table)
+		}
+	}
+
+	problems, err := integrityProblems(ctx, db, pragma)
+	if err != nil {
+		return fmt.Errorf("%w: %s failed: %v", domain.ErrInvalidSource, pragma, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: database is corrupt: %s", domain.ErrInvalidSource, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// maxIntegrityProblems caps how many integrity_check findings are reported.
+const maxIntegrityProblems = 5
+
+// integrityProblems runs an integrity pragma and returns its findings; none
+// when it reports "ok".
+func integrityProblems(ctx context.Context, db *sql.DB, pragma string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("%s(%d)", pragma, maxIntegrityProblems))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	return problems, rows.Err()
+}
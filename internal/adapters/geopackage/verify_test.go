@@ -0,0 +1,92 @@
+package geopackage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// openPlainSQLite creates a SQLite file from stmts and opens it without
+// SpatiaLite; verification needs nothing beyond core SQLite.
+func openPlainSQLite(t *testing.T, stmts ...string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "test.gpkg"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	return db
+}
+
+const (
+	gpkgAppID       = "PRAGMA application_id = 1196444487" // "GPKG"
+	gp11AppID       = "PRAGMA application_id = 1196437809" // "GP11"
+	createSRS       = "CREATE TABLE gpkg_spatial_ref_sys (srs_id INTEGER PRIMARY KEY)"
+	createContents  = "CREATE TABLE gpkg_contents (table_name TEXT PRIMARY KEY)"
+	plainSQLiteFile = "CREATE TABLE t (x INTEGER)"
+)
+
+func TestVerifyGeoPackage(t *testing.T) {
+	tests := []struct {
+		name    string
+		stmts   []string
+		level   string
+		wantErr string
+	}{
+		{name: "conformant", stmts: []string{gpkgAppID, createSRS, createContents}, level: VerifyQuick},
+		{name: "conformant full", stmts: []string{gpkgAppID, createSRS, createContents}, level: VerifyFull},
+		{name: "GP11 application_id", stmts: []string{gp11AppID, createSRS, createContents}, level: VerifyQuick},
+		{name: "plain SQLite", stmts: []string{plainSQLiteFile}, level: VerifyQuick, wantErr: "application_id 0"},
+		{name: "missing srs table", stmts: []string{gpkgAppID, createContents}, level: VerifyQuick, wantErr: "gpkg_spatial_ref_sys is missing"},
+		{name: "missing contents table", stmts: []string{gpkgAppID, createSRS}, level: VerifyQuick, wantErr: "gpkg_contents is missing"},
+		{name: "off", stmts: []string{plainSQLiteFile}, level: VerifyOff},
+		{name: "zero value", stmts: []string{plainSQLiteFile}, level: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openPlainSQLite(t, tt.stmts...)
+			err := verifyGeoPackage(context.Background(), db, tt.level)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("verifyGeoPackage: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("verifyGeoPackage = %v, want error containing %q", err, tt.wantErr)
+			}
+			if !errors.Is(err, domain.ErrInvalidSource) {
+				t.Errorf("error %v does not wrap ErrInvalidSource", err)
+			}
+		})
+	}
+}
+
+func TestVerifyGeoPackageRejectsGarbage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "truncated.gpkg")
+	if err := os.WriteFile(path, []byte("this is not a database, just a truncated download"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	err = verifyGeoPackage(context.Background(), db, VerifyQuick)
+	if !errors.Is(err, domain.ErrInvalidSource) {
+		t.Fatalf("verifyGeoPackage = %v, want ErrInvalidSource", err)
+	}
+}
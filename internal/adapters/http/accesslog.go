@@ -0,0 +1,176 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Access log formats (logging.access.format).
+const (
+	AccessLogCommon   = "common"
+	AccessLogCombined = "combined"
+	AccessLogJSON     = "json"
+)
+
+// clfTime is the timestamp layout of the Common Log Format.
+const clfTime = "02/Jan/2006:15:04:05 -0700"
+
+// accessLog writes one line per request to its own output, apart from the
+// application log, in the Common or Combined Log Format or as JSON, so
+// standard log analyzers can ingest the traffic.
+type accessLog struct {
+	mu     sync.Mutex
+	out    io.Writer
+	format string
+	now    func() time.Time // injectable for tests
+}
+
+func newAccessLog(out io.Writer, format string) *accessLog {
+	if format == "" {
+		format = AccessLogCombined
+	}
+	return &accessLog{out: out, format: format, now: time.Now}
+}
+
+// accessLogEntry is a JSON access log line.
+type accessLogEntry struct {
+	Time       string  `json:"time"`
+	ClientIP   string  `json:"client_ip"`
+	Method     string  `json:"method"`
+	URI        string  `json:"uri"`
+	Proto      string  `json:"proto"`
+	Status     int     `json:"status"`
+	Bytes      int64   `json:"bytes"`
+	DurationMS float64 `json:"duration_ms"`
+	Referer    string  `json:"referer,omitempty"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+}
+
+// accessLogMiddleware logs every request next serves. It wraps the whole
+// router, so unmatched routes (404/405) are logged as well.
+func (s *Server) accessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := s.accessLog.now()
+		counted := &countingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(counted, r)
+		s.accessLog.write(r, clientIP(r, s.settings().trustedProxies), counted, start)
+	})
+}
+
+// write formats and writes the line of one request. A failing output is
+// not the request's problem; the line is dropped.
+func (l *accessLog) write(r *http.Request, client string, w *countingWriter, start time.Time) {
+	var line []byte
+	if l.format == AccessLogJSON {
+		line, _ = json.Marshal(accessLogEntry{
+			Time:       start.Format(time.RFC3339Nano),
+			ClientIP:   client,
+			Method:     r.Method,
+			URI:        r.RequestURI,
+			Proto:      r.Proto,
+			Status:     w.statusCode,
+			Bytes:      w.bytes,
+			DurationMS: float64(l.now().Sub(start).Microseconds()) / 1000,
+			Referer:    r.Referer(),
+			UserAgent:  r.UserAgent(),
+		})
+	} else {
+		line = []byte(l.clfLine(r, client, w, start))
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.out.Write(line)
+}
+
+// clfLine renders a Common Log Format line, followed by the referer and user
+// agent in the Combined format:
+//
+//	client - - [10/Oct/2000:13:55:36 -0700] "GET /x HTTP/1.1" 200 2326 "referer" "agent"
+func (l *accessLog) clfLine(r *http.Request, client string, w *countingWriter, start time.Time) string {
+	size := "-"
+	if w.bytes > 0 {
+		size = strconv.FormatInt(w.bytes, 10)
+	}
+	line := fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %s",
+		clfField(client), start.Format(clfTime),
+		clfEscape(r.Method), clfEscape(r.RequestURI), clfEscape(r.Proto), w.statusCode, size)
+	if l.format == AccessLogCombined {
+		line += fmt.Sprintf(" \"%s\" \"%s\"", clfEscape(orDash(r.Referer())), clfEscape(orDash(r.UserAgent())))
+	}
+	return line
+}
+
+// clfField renders an unquoted field: "-" when empty, spaces escaped so the
+// line still splits into its fields.
+func clfField(s string) string {
+	return strings.ReplaceAll(clfEscape(orDash(s)), " ", `\x20`)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// clfEscape escapes quotes, backslashes and control characters the way
+// Apache httpd does, so a crafted request cannot forge log lines.
+func clfEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// countingWriter records the status code and the body bytes written. It
+// passes Flush through, so streamed responses keep streaming.
+type countingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	bytes      int64
+	wroteHead  bool
+}
+
+func (w *countingWriter) WriteHeader(code int) {
+	if !w.wroteHead {
+		w.statusCode = code
+		w.wroteHead = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	w.wroteHead = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *countingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *countingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
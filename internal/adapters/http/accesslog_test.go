@@ -0,0 +1,71 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// accessLogged serves one request through the access log in format and
+// returns the line written.
+func accessLogged(t *testing.T, format string, req *http.Request, h http.HandlerFunc) string {
+	t.Helper()
+	var buf bytes.Buffer
+	s := &Server{accessLog: newAccessLog(&buf, format)}
+	s.accessLog.now = func() time.Time { return time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600)) }
+	s.accessLogMiddleware(h).ServeHTTP(httptest.NewRecorder(), req)
+	return buf.String()
+}
+
+func TestAccessLogFormats(t *testing.T) {
+	body := func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("hello")) }
+	newReq := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/query?lat=52.5&lon=13.4", nil)
+		r.RemoteAddr = "203.0.113.7:51234"
+		r.Header.Set("Referer", "https://example.com/")
+		r.Header.Set("User-Agent", "curl/8.0")
+		return r
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{AccessLogCommon, `203.0.113.7 - - [10/Oct/2000:13:55:36 -0700] "GET /api/v1/query?lat=52.5&lon=13.4 HTTP/1.1" 200 5` + "\n"},
+		{AccessLogCombined, `203.0.113.7 - - [10/Oct/2000:13:55:36 -0700] "GET /api/v1/query?lat=52.5&lon=13.4 HTTP/1.1" 200 5 "https://example.com/" "curl/8.0"` + "\n"},
+	}
+	for _, tt := range tests {
+		if got := accessLogged(t, tt.format, newReq(), body); got != tt.want {
+			t.Errorf("%s:\n got %q\nwant %q", tt.format, got, tt.want)
+		}
+	}
+
+	var entry accessLogEntry
+	line := accessLogged(t, AccessLogJSON, newReq(), body)
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("json line %q: %v", line, err)
+	}
+	if entry.ClientIP != "203.0.113.7" || entry.Status != 200 || entry.Bytes != 5 ||
+		entry.UserAgent != "curl/8.0" || entry.URI != "/api/v1/query?lat=52.5&lon=13.4" {
+		t.Errorf("json entry = %+v", entry)
+	}
+}
+
+func TestAccessLogStatusAndEscaping(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	r.RemoteAddr = "203.0.113.7:51234"
+	r.Header.Set("User-Agent", "evil\" agent\n203.0.113.8 - - forged")
+	line := accessLogged(t, AccessLogCombined, r, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	if !strings.Contains(line, `" 404 - "-" "evil\" agent\x0a203.0.113.8 - - forged"`) {
+		t.Errorf("line = %q, want status 404, no size, and the agent escaped", line)
+	}
+	if strings.Count(line, "\n") != 1 {
+		t.Errorf("line = %q spans several lines", line)
+	}
+}
@@ -0,0 +1,314 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+)
+
+// maxAdminBody bounds the optional JSON bodies of the admin routes.
+const maxAdminBody = 4 << 10
+
+// adminAuthMiddleware requires `Authorization: Bearer <server.admin token>`.
+// The comparison is constant-time. Config validation guarantees a token when
+// the admin routes are enabled; an empty one still rejects every request.
+// Authorized requests are audited as "admin@<client ip>".
+func (s *Server) adminAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.adminAuthorized(r) {
+			s.writeAdminUnauthorized(w, r)
+			return
+		}
+		actor := "admin@" + clientIP(r, s.settings().trustedProxies)
+		next.ServeHTTP(w, r.WithContext(domain.WithActor(r.Context(), actor)))
+	})
+}
+
+// adminAuthorized reports whether r carries the admin token.
+func (s *Server) adminAuthorized(r *http.Request) bool {
+	want := "Bearer " + s.config.Admin.Token
+	got := r.Header.Get("Authorization")
+	return s.config.Admin.Token != "" && len(got) == len(want) &&
+		subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+func (s *Server) writeAdminUnauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="ortus-admin"`)
+	s.writeError(w, r, http.StatusUnauthorized, "Missing or invalid admin token")
+}
+
+// handleGetMaintenance reports the maintenance switch.
+func (s *Server) handleGetMaintenance(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, http.StatusOK, formatMaintenance(s.maintenance.MaintenanceStatus()))
+}
+
+// handleEnterMaintenance switches maintenance mode on. The body is optional:
+// {"reason": "..."} records why, for whoever looks at the status next.
+func (s *Server) handleEnterMaintenance(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxAdminBody)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		s.writeError(w, r, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, formatMaintenance(s.maintenance.EnterMaintenance(r.Context(), body.Reason)))
+}
+
+// handleLeaveMaintenance switches maintenance mode off.
+func (s *Server) handleLeaveMaintenance(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, formatMaintenance(s.maintenance.LeaveMaintenance(r.Context())))
+}
+
+func formatMaintenance(st input.MaintenanceStatus) map[string]interface{} {
+	out := map[string]interface{}{"maintenance": st.Active}
+	if st.Active {
+		out["since"] = st.Since.UTC().Format(time.RFC3339)
+		if st.Reason != "" {
+			out["reason"] = st.Reason
+		}
+	}
+	return out
+}
+
+// handleReloadConfig re-reads the configuration and applies its runtime
+// settings. A configuration that does not load or validate is reported as 422
+// and leaves the running settings untouched; maintenance mode blocks the
+// reload with 503.
+func (s *Server) handleReloadConfig(w http.ResponseWriter, r *http.Request) {
+	res, err := s.configReloader.ReloadConfig(r.Context())
+	if errors.Is(err, domain.ErrMaintenance) {
+		s.writeError(w, r, http.StatusServiceUnavailable, "Maintenance mode is on; config reload is blocked")
+		return
+	}
+	if err != nil {
+		s.logger.Warn("config reload rejected", "error", err)
+		s.writeError(w, r, http.StatusUnprocessableEntity, "Config not reloaded: "+err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"changed":          nonNil(res.Changed),
+		"reopened_sources": nonNil(res.ReopenedSources),
+	})
+}
+
+// nonNil keeps an empty list a JSON array rather than null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// defaultAuditLimit is the number of entries GET /admin/audit returns without
+// a limit parameter.
+const defaultAuditLimit = 100
+
+// auditParams are the query parameters of GET /admin/audit.
+type auditParams struct {
+	Limit int `query:"limit"`
+}
+
+// handleAudit returns the most recent audit entries, newest first.
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	params := auditParams{Limit: defaultAuditLimit}
+	if err := decodeQuery(r.URL.Query(), &params); err != nil || params.Limit < 1 {
+		s.writeError(w, r, http.StatusBadRequest, "limit must be a positive integer")
+		return
+	}
+
+	entries := s.audit.RecentAudit(params.Limit)
+	out := make([]map[string]interface{}, len(entries))
+	for i, e := range entries {
+		m := map[string]interface{}{
+			"time":   e.Time.UTC().Format(time.RFC3339),
+			"actor":  e.Actor,
+			"action": string(e.Action),
+		}
+		if e.Target != "" {
+			m["target"] = e.Target
+		}
+		if e.Detail != "" {
+			m["detail"] = e.Detail
+		}
+		if e.Error != "" {
+			m["error"] = e.Error
+		}
+		out[i] = m
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{"entries": out})
+}
+
+// handleStartOptimize starts index rebuilds, ANALYZE and VACUUM on a source's
+// working copy in the background and answers 202 with the initial progress.
+// The body is optional: {"steps": ["reindex", "analyze", "vacuum"]} picks
+// steps and their order; without it all three run.
+func (s *Server) handleStartOptimize(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Steps []input.OptimizeStep `json:"steps"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxAdminBody)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		s.writeError(w, r, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	progress, err := s.optimizer.StartOptimize(r.Context(), mux.Vars(r)["sourceId"], body.Steps)
+	switch {
+	case err == nil:
+		s.writeJSON(w, http.StatusAccepted, formatOptimizeProgress(progress))
+	case errors.Is(err, domain.ErrSourceNotFound):
+		s.writeError(w, r, http.StatusNotFound, "Source not found")
+	case errors.Is(err, domain.ErrOptimizeRunning):
+		s.writeError(w, r, http.StatusConflict, "An optimization of this source is still running")
+	case errors.Is(err, domain.ErrInvalidInput):
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrUnsupported):
+		s.writeError(w, r, http.StatusUnprocessableEntity, err.Error())
+	default:
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to start optimization")
+	}
+}
+
+// handleOptimizeProgress reports the running or last optimization of a
+// source.
+func (s *Server) handleOptimizeProgress(w http.ResponseWriter, r *http.Request) {
+	progress, err := s.optimizer.OptimizeProgress(mux.Vars(r)["sourceId"])
+	if err != nil {
+		s.writeError(w, r, http.StatusNotFound, "No optimization of this source")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, formatOptimizeProgress(progress))
+}
+
+func formatOptimizeProgress(p input.OptimizeProgress) map[string]interface{} {
+	done := make([]input.OptimizeStep, 0, len(p.Done))
+	out := map[string]interface{}{
+		"source_id":  p.SourceID,
+		"running":    p.Running(),
+		"steps":      p.Steps,
+		"done":       append(done, p.Done...),
+		"started_at": p.StartedAt.UTC().Format(time.RFC3339),
+	}
+	if p.Step != "" {
+		out["step"] = p.Step
+	}
+	if p.Layers > 0 {
+		out["layers"] = map[string]int{"done": p.LayersDone, "total": p.Layers}
+	}
+	if !p.Running() {
+		out["finished_at"] = p.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	if p.Error != "" {
+		out["error"] = p.Error
+	}
+	return out
+}
+
+// stageMiddleware selects the staged candidates for requests with
+// ?stage=candidate. Candidates are unreleased data: the request needs the
+// admin token.
+func (s *Server) stageMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch domain.Stage(r.URL.Query().Get("stage")) {
+		case "", domain.StageActive:
+			next.ServeHTTP(w, r)
+		case domain.StageCandidate:
+			if !s.adminAuthorized(r) {
+				s.writeAdminUnauthorized(w, r)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(domain.WithStage(r.Context(), domain.StageCandidate)))
+		default:
+			s.writeError(w, r, http.StatusBadRequest, "stage must be active or candidate")
+		}
+	})
+}
+
+// handleStageCandidate downloads a storage object and opens it as the
+// candidate of a loaded source. The body is optional:
+// {"key": "...", "version_id": "..."} picks the object; without it the
+// source's own key is staged again.
+func (s *Server) handleStageCandidate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Key       string `json:"key"`
+		VersionID string `json:"version_id"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxAdminBody)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		s.writeError(w, r, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	staged, err := s.stager.StageCandidate(r.Context(), mux.Vars(r)["sourceId"], input.StageRequest{Key: body.Key, VersionID: body.VersionID})
+	if err != nil {
+		s.writeStageError(w, r, err, "Failed to stage candidate")
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, formatStagedSource(staged))
+}
+
+// handleGetCandidate reports the candidate staged for a source.
+func (s *Server) handleGetCandidate(w http.ResponseWriter, r *http.Request) {
+	staged, err := s.stager.Candidate(mux.Vars(r)["sourceId"])
+	if err != nil {
+		s.writeStageError(w, r, err, "Failed to read candidate")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, formatStagedSource(staged))
+}
+
+// handleDiscardCandidate closes and deletes the candidate of a source.
+func (s *Server) handleDiscardCandidate(w http.ResponseWriter, r *http.Request) {
+	if err := s.stager.DiscardCandidate(r.Context(), mux.Vars(r)["sourceId"]); err != nil {
+		s.writeStageError(w, r, err, "Failed to discard candidate")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePromoteCandidate makes the candidate of a source the active source.
+func (s *Server) handlePromoteCandidate(w http.ResponseWriter, r *http.Request) {
+	staged, err := s.stager.PromoteCandidate(r.Context(), mux.Vars(r)["sourceId"])
+	if err != nil {
+		s.writeStageError(w, r, err, "Failed to promote candidate")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, formatStagedSource(staged))
+}
+
+func (s *Server) writeStageError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, domain.ErrMaintenance):
+		s.writeError(w, r, http.StatusServiceUnavailable, "Maintenance mode is on; staging is blocked")
+	case errors.Is(err, domain.ErrSourceNotFound):
+		s.writeError(w, r, http.StatusNotFound, "Source not found")
+	case errors.Is(err, domain.ErrNoCandidate):
+		s.writeError(w, r, http.StatusNotFound, "No candidate staged for this source")
+	case errors.Is(err, domain.ErrInvalidInput):
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrUnsupported):
+		s.writeError(w, r, http.StatusUnprocessableEntity, err.Error())
+	default:
+		s.logger.Error(strings.ToLower(msg), "error", err)
+		s.writeError(w, r, http.StatusInternalServerError, msg+": "+err.Error())
+	}
+}
+
+func formatStagedSource(st input.StagedSource) map[string]interface{} {
+	out := map[string]interface{}{
+		"source_id": st.SourceID,
+		"key":       st.Key,
+		"layers":    st.Layers,
+		"staged_at": st.StagedAt.UTC().Format(time.RFC3339),
+	}
+	if st.VersionID != "" {
+		out["version_id"] = st.VersionID
+	}
+	return out
+}
@@ -0,0 +1,403 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/jobrunner/ortus/internal/application"
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// newAdminTestServer builds a server with the admin routes enabled and a
+// maintenance switch wired into both the HTTP layer and the health service.
+func newAdminTestServer(t *testing.T, enabled bool) *Server {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	registry := application.NewSourceRegistry([]output.SpatialSource{&mockRepository{}}, &mockStorage{},
+		noop.NewMeterProvider().Meter("test"), output.NoOpTracer{}, logger, "/tmp")
+	_ = registry.LoadAll(context.Background())
+	maintenance := application.NewMaintenanceMode(logger)
+	health := application.NewHealthService(registry, true, output.NoOpTracer{})
+	health.SetMaintenance(maintenance)
+	query := application.NewQueryService(registry, nil, noop.NewMeterProvider().Meter("test"),
+		output.NoOpTracer{}, logger, application.QueryServiceConfig{})
+
+	return NewServer(
+		config.ServerConfig{Host: "localhost", Port: 8080, Admin: config.AdminConfig{Enabled: enabled, Token: "s3cret"}},
+		query, registry, health, nil, logger, false,
+		ServerOptions{Maintenance: maintenance},
+	)
+}
+
+func adminRequest(srv *Server, method, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/admin/maintenance", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAdminMaintenanceRequiresToken(t *testing.T) {
+	srv := newAdminTestServer(t, true)
+	for _, token := range []string{"", "wrong"} {
+		if rr := adminRequest(srv, http.MethodPut, token, ""); rr.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, rr.Code)
+		}
+	}
+}
+
+func TestAdminMaintenanceRoutesOffByDefault(t *testing.T) {
+	srv := newAdminTestServer(t, false)
+	if rr := adminRequest(srv, http.MethodGet, "s3cret", ""); rr.Code != http.StatusNotFound && rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want the route to be absent", rr.Code)
+	}
+}
+
+func TestAdminMaintenanceDrainsReadiness(t *testing.T) {
+	srv := newAdminTestServer(t, true)
+	ready := func() int {
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+		return rr.Code
+	}
+	if got := ready(); got != http.StatusOK {
+		t.Fatalf("ready before maintenance = %d", got)
+	}
+
+	rr := adminRequest(srv, http.MethodPut, "s3cret", `{"reason":"replace districts.gpkg"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var st map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &st); err != nil {
+		t.Fatal(err)
+	}
+	if st["maintenance"] != true || st["reason"] != "replace districts.gpkg" || st["since"] == nil {
+		t.Errorf("PUT body = %v", st)
+	}
+	if got := ready(); got != http.StatusServiceUnavailable {
+		t.Errorf("ready during maintenance = %d, want 503", got)
+	}
+
+	// Liveness and queries keep answering.
+	live := httptest.NewRecorder()
+	srv.router.ServeHTTP(live, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	if live.Code != http.StatusOK {
+		t.Errorf("live during maintenance = %d", live.Code)
+	}
+
+	if rr := adminRequest(srv, http.MethodDelete, "s3cret", ""); rr.Code != http.StatusOK {
+		t.Fatalf("DELETE status = %d", rr.Code)
+	}
+	if got := ready(); got != http.StatusOK {
+		t.Errorf("ready after maintenance = %d", got)
+	}
+}
+
+func TestAdminMaintenanceRejectsBadBody(t *testing.T) {
+	srv := newAdminTestServer(t, true)
+	if rr := adminRequest(srv, http.MethodPut, "s3cret", "{"); rr.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rr.Code)
+	}
+}
+
+// stubReloader returns a fixed reload result or error.
+type stubReloader struct {
+	res input.ConfigReload
+	err error
+}
+
+func (r stubReloader) ReloadConfig(context.Context) (input.ConfigReload, error) { return r.res, r.err }
+
+func TestAdminReloadConfig(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	newServer := func(r input.ConfigReloader) *Server {
+		return NewServer(
+			config.ServerConfig{Host: "localhost", Port: 8080, Admin: config.AdminConfig{Enabled: true, Token: "s3cret"}},
+			nil, nil, nil, nil, logger, false,
+			ServerOptions{ConfigReloader: r},
+		)
+	}
+	reload := func(srv *Server) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/reload-config", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := reload(newServer(stubReloader{res: input.ConfigReload{Changed: []string{"logging.level"}}}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var body struct {
+		Changed         []string `json:"changed"`
+		ReopenedSources []string `json:"reopened_sources"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Changed) != 1 || body.Changed[0] != "logging.level" || body.ReopenedSources == nil {
+		t.Errorf("body = %s", rr.Body.String())
+	}
+
+	if rr := reload(newServer(stubReloader{err: errors.New("validating config: bad")})); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid config: status = %d, want 422", rr.Code)
+	}
+	if rr := reload(newServer(stubReloader{err: domain.ErrMaintenance})); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("maintenance: status = %d, want 503", rr.Code)
+	}
+	if rr := reload(newServer(nil)); rr.Code != http.StatusNotFound && rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("without a reloader: status = %d, want the route to be absent", rr.Code)
+	}
+}
+
+func TestAdminAudit(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	audit := application.NewAuditLog(0, logger)
+	srv := NewServer(
+		config.ServerConfig{Host: "localhost", Port: 8080, Admin: config.AdminConfig{Enabled: true, Token: "s3cret"}},
+		nil, nil, nil, nil, logger, false,
+		ServerOptions{Audit: audit},
+	)
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit"+query, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	audit.Record(context.Background(), domain.AuditLoad, "parcels", "", nil)
+	audit.Record(domain.WithActor(context.Background(), "admin@10.0.0.1"), domain.AuditMaintenanceEnter, "", "", nil)
+
+	rr := get("?limit=1")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var body struct {
+		Entries []map[string]any `json:"entries"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Entries) != 1 || body.Entries[0]["action"] != "maintenance.enter" || body.Entries[0]["actor"] != "admin@10.0.0.1" {
+		t.Errorf("body = %s", rr.Body.String())
+	}
+	if _, ok := body.Entries[0]["target"]; ok {
+		t.Errorf("empty target should be omitted: %s", rr.Body.String())
+	}
+
+	if rr := get("?limit=x"); rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit: status = %d, want 400", rr.Code)
+	}
+}
+
+// stubOptimizer answers StartOptimize with err, or progress of the steps.
+type stubOptimizer struct {
+	err   error
+	steps []input.OptimizeStep
+}
+
+func (o *stubOptimizer) StartOptimize(_ context.Context, id string, steps []input.OptimizeStep) (input.OptimizeProgress, error) {
+	o.steps = steps
+	if o.err != nil {
+		return input.OptimizeProgress{}, o.err
+	}
+	return input.OptimizeProgress{SourceID: id, Steps: steps, Step: steps[0], Layers: 2, StartedAt: time.Now()}, nil
+}
+
+func (o *stubOptimizer) OptimizeProgress(id string) (input.OptimizeProgress, error) {
+	if id != "parcels" {
+		return input.OptimizeProgress{}, domain.ErrSourceNotFound
+	}
+	now := time.Now()
+	return input.OptimizeProgress{
+		SourceID: id, Steps: input.OptimizeSteps, Done: []input.OptimizeStep{input.OptimizeReindex},
+		StartedAt: now, FinishedAt: now, Error: "vacuum: disk full",
+	}, nil
+}
+
+func TestAdminOptimize(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	opt := &stubOptimizer{}
+	srv := NewServer(
+		config.ServerConfig{Host: "localhost", Port: 8080, Admin: config.AdminConfig{Enabled: true, Token: "s3cret"}},
+		nil, nil, nil, nil, logger, false,
+		ServerOptions{Optimizer: opt},
+	)
+	do := func(method, id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/sources/"+id+"/optimize", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(http.MethodPost, "parcels", `{"steps":["analyze","vacuum"]}`)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("start: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var started map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &started); err != nil {
+		t.Fatal(err)
+	}
+	if started["running"] != true || started["step"] != "analyze" || len(opt.steps) != 2 {
+		t.Errorf("start body = %s, steps = %v", rr.Body.String(), opt.steps)
+	}
+
+	rr = do(http.MethodGet, "parcels", "")
+	var finished map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &finished); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusOK || finished["running"] != false || finished["error"] != "vacuum: disk full" || finished["finished_at"] == nil {
+		t.Errorf("progress: status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodGet, "other", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("progress without a run: status = %d, want 404", rr.Code)
+	}
+
+	for err, want := range map[error]int{
+		domain.ErrSourceNotFound:                               http.StatusNotFound,
+		domain.ErrOptimizeRunning:                              http.StatusConflict,
+		fmt.Errorf("%w: unknown step", domain.ErrInvalidInput): http.StatusBadRequest,
+		domain.ErrUnsupportedSource:                            http.StatusUnprocessableEntity,
+	} {
+		opt.err = err
+		if rr := do(http.MethodPost, "parcels", ""); rr.Code != want {
+			t.Errorf("%v: status = %d, want %d", err, rr.Code, want)
+		}
+	}
+	if rr := do(http.MethodPost, "parcels", "{"); rr.Code != http.StatusBadRequest {
+		t.Errorf("bad body: status = %d, want 400", rr.Code)
+	}
+}
+
+type stubStager struct {
+	staged  *input.StagedSource
+	req     input.StageRequest
+	err     error
+	actorOK bool
+}
+
+func (s *stubStager) StageCandidate(ctx context.Context, id string, req input.StageRequest) (input.StagedSource, error) {
+	if s.err != nil {
+		return input.StagedSource{}, s.err
+	}
+	s.req = req
+	s.actorOK = strings.HasPrefix(domain.ActorFrom(ctx), "admin@")
+	s.staged = &input.StagedSource{SourceID: id, Key: req.Key, VersionID: req.VersionID, Layers: 3, StagedAt: time.Now()}
+	return *s.staged, nil
+}
+
+func (s *stubStager) PromoteCandidate(_ context.Context, id string) (input.StagedSource, error) {
+	st, err := s.Candidate(id)
+	s.staged = nil
+	return st, err
+}
+
+func (s *stubStager) DiscardCandidate(_ context.Context, id string) error {
+	_, err := s.Candidate(id)
+	s.staged = nil
+	return err
+}
+
+func (s *stubStager) Candidate(string) (input.StagedSource, error) {
+	if s.staged == nil {
+		return input.StagedSource{}, domain.ErrNoCandidate
+	}
+	return *s.staged, nil
+}
+
+func TestAdminStageCandidate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	stager := &stubStager{}
+	srv := NewServer(
+		config.ServerConfig{Host: "localhost", Port: 8080, Admin: config.AdminConfig{Enabled: true, Token: "s3cret"}},
+		nil, nil, nil, nil, logger, false,
+		ServerOptions{Stager: stager},
+	)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := do(http.MethodGet, "/admin/sources/parcels/stage", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("candidate before staging: status = %d, want 404", rr.Code)
+	}
+	rr := do(http.MethodPost, "/admin/sources/parcels/stage", `{"key":"parcels-2025.gpkg","version_id":"v7"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("stage: status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var staged map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &staged); err != nil {
+		t.Fatal(err)
+	}
+	if staged["key"] != "parcels-2025.gpkg" || staged["version_id"] != "v7" || staged["layers"] != float64(3) || !stager.actorOK {
+		t.Errorf("stage body = %s, actor ok = %v", rr.Body.String(), stager.actorOK)
+	}
+	if rr := do(http.MethodGet, "/admin/sources/parcels/stage", ""); rr.Code != http.StatusOK {
+		t.Errorf("candidate: status = %d, want 200", rr.Code)
+	}
+	if rr := do(http.MethodPost, "/admin/sources/parcels/promote", ""); rr.Code != http.StatusOK {
+		t.Errorf("promote: status = %d, want 200", rr.Code)
+	}
+	if rr := do(http.MethodPost, "/admin/sources/parcels/promote", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("promote without candidate: status = %d, want 404", rr.Code)
+	}
+	if rr := do(http.MethodDelete, "/admin/sources/parcels/stage", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("discard without candidate: status = %d, want 404", rr.Code)
+	}
+
+	for err, want := range map[error]int{
+		domain.ErrSourceNotFound: http.StatusNotFound,
+		fmt.Errorf("staging with leader election: %w", domain.ErrUnsupported): http.StatusUnprocessableEntity,
+		errors.New("storage down"): http.StatusInternalServerError,
+		domain.ErrMaintenance:      http.StatusServiceUnavailable,
+	} {
+		stager.err = err
+		if rr := do(http.MethodPost, "/admin/sources/parcels/stage", ""); rr.Code != want {
+			t.Errorf("%v: status = %d, want %d", err, rr.Code, want)
+		}
+	}
+}
+
+func TestStageQueryParameter(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := NewServer(
+		config.ServerConfig{Host: "localhost", Port: 8080, Admin: config.AdminConfig{Enabled: true, Token: "s3cret"}},
+		nil, nil, nil, nil, logger, false,
+		ServerOptions{Stager: &stubStager{}},
+	)
+	for query, want := range map[string]int{
+		"stage=candidate": http.StatusUnauthorized, // candidates need the admin token
+		"stage=next":      http.StatusBadRequest,
+	} {
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/query?lon=8&lat=50&"+query, nil))
+		if rr.Code != want {
+			t.Errorf("%s: status = %d, want %d", query, rr.Code, want)
+		}
+	}
+}
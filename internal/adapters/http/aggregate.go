@@ -0,0 +1,95 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// aggregateRequest is the POST /api/v1/query/aggregate body.
+type aggregateRequest struct {
+	// Area optionally restricts the aggregation to the features in Relation
+	// to a polygon, given as in POST /query/area; without it whole layers are
+	// aggregated.
+	Area     json.RawMessage `json:"area"`
+	SRID     int             `json:"srid"`     // SRID of the area's coordinates; default 4326
+	Relation string          `json:"relation"` // intersects (default) | within | contains
+	GroupBy  string          `json:"group_by"` // optional: count the features per value of this property
+	Stats    []string        `json:"stats"`    // optional: numeric properties to compute min/max/avg of
+	Sources  []string        `json:"sources"`  // optional: restrict to these source ids
+}
+
+// handleQueryAggregate returns per source and layer the number of matching
+// features, their counts per value of a property and the min, max and avg of
+// numeric properties — computed in SQL, without returning the features.
+func (s *Server) handleQueryAggregate(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, int64(s.areaMaxPoints)*64+64*1024)
+	var req aggregateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			s.writeError(w, r, http.StatusRequestEntityTooLarge, "request body too large — send a polygon with fewer points")
+			return
+		}
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid JSON body: %v", err))
+		return
+	}
+	rel, err := domain.ParseSpatialRelation(req.Relation)
+	if err != nil {
+		s.handleQueryError(w, r, err)
+		return
+	}
+	q := domain.AggregateQuery{Relation: rel, GroupBy: req.GroupBy, MaxGroups: s.aggMaxGroups, Stats: req.Stats}
+	if len(req.Area) > 0 && string(req.Area) != "null" {
+		area, err := (&areaRequest{Area: req.Area, SRID: req.SRID}).parseArea()
+		if err != nil {
+			s.writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if n := area.Points(); n > s.areaMaxPoints {
+			s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("area of %d points exceeds the limit of %d", n, s.areaMaxPoints))
+			return
+		}
+		q.Area = &area
+	}
+
+	resp, err := s.queryService.QueryAggregate(r.Context(), q, req.Sources)
+	if err != nil {
+		s.handleQueryError(w, r, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, formatAggregateResponse(resp))
+}
+
+func formatAggregateResponse(resp *domain.AggregateResponse) AggregateResponseDTO {
+	out := AggregateResponseDTO{
+		Results:          make([]AggregateResultDTO, len(resp.Results)),
+		TotalCount:       resp.TotalCount,
+		ProcessingTimeMS: resp.ProcessingTime.Milliseconds(),
+	}
+	for i, res := range resp.Results {
+		layers := make([]LayerAggregateDTO, len(res.Layers))
+		for j, l := range res.Layers {
+			dto := LayerAggregateDTO{Layer: l.Layer, Count: l.Count, GroupsTruncated: l.GroupsTruncated}
+			for _, g := range l.Groups {
+				dto.Groups = append(dto.Groups, GroupCountDTO{Value: g.Value, Count: g.Count})
+			}
+			if len(l.Stats) > 0 {
+				dto.Stats = make(map[string]PropertyStatsDTO, len(l.Stats))
+				for _, st := range l.Stats {
+					ps := PropertyStatsDTO{Count: st.Count}
+					if st.Count > 0 {
+						ps.Min, ps.Max, ps.Avg = &st.Min, &st.Max, &st.Avg
+					}
+					dto.Stats[st.Property] = ps
+				}
+			}
+			layers[j] = dto
+		}
+		out.Results[i] = AggregateResultDTO{SourceID: res.SourceID, SourceName: res.SourceName, Layers: layers}
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func doAggregate(t *testing.T, srv *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/query/aggregate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rec, req)
+	return rec
+}
+
+// TestQueryAggregate: with and without an area the endpoint answers 200; a
+// bad area or relation is 400.
+func TestQueryAggregate(t *testing.T) {
+	srv := newAreaServer(t, 100)
+	tests := map[string]struct {
+		body string
+		want int
+	}{
+		"whole layers":     {`{"group_by":"kind","stats":["pop"]}`, http.StatusOK},
+		"with area":        {`{"area":"POLYGON((9 49, 10 49, 10 50, 9 49))","relation":"within"}`, http.StatusOK},
+		"invalid json":     {`{"group_by":`, http.StatusBadRequest},
+		"bad area":         {`{"area":"POINT(1 2)"}`, http.StatusBadRequest},
+		"open ring":        {`{"area":"POLYGON((0 0, 1 0, 1 1, 0 1))"}`, http.StatusBadRequest},
+		"unknown relation": {`{"relation":"touches"}`, http.StatusBadRequest},
+		"blank stat":       {`{"stats":[""]}`, http.StatusBadRequest},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if rec := doAggregate(t, srv, tt.body); rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestFormatAggregateResponse: stats without numeric values leave out
+// min/max/avg; groups and the truncation flag carry over.
+func TestFormatAggregateResponse(t *testing.T) {
+	out := formatAggregateResponse(&domain.AggregateResponse{
+		Results: []domain.AggregateResult{{SourceID: "berlin", Layers: []domain.LayerAggregate{{
+			Layer:           "districts",
+			Count:           3,
+			Groups:          []domain.GroupCount{{Value: "BZ", Count: 2}, {Value: nil, Count: 1}},
+			GroupsTruncated: true,
+			Stats: []domain.PropertyStats{
+				{Property: "pop", Count: 2, Min: 1, Max: 3, Avg: 2},
+				{Property: "area", Count: 0},
+			},
+		}}}},
+		TotalCount:     3,
+		ProcessingTime: 5 * time.Millisecond,
+	})
+	if out.TotalCount != 3 || out.ProcessingTimeMS != 5 || len(out.Results) != 1 {
+		t.Fatalf("response = %+v", out)
+	}
+	l := out.Results[0].Layers[0]
+	if len(l.Groups) != 2 || l.Groups[1].Value != nil || !l.GroupsTruncated {
+		t.Errorf("groups = %+v truncated=%v", l.Groups, l.GroupsTruncated)
+	}
+	if pop := l.Stats["pop"]; pop.Avg == nil || *pop.Avg != 2 {
+		t.Errorf("pop stats = %+v, want avg 2", pop)
+	}
+	if area := l.Stats["area"]; area.Min != nil || area.Max != nil || area.Avg != nil {
+		t.Errorf("area stats = %+v, want no min/max/avg", area)
+	}
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// areaRequest is the POST /api/v1/query/area body.
+type areaRequest struct {
+	// Area is a GeoJSON Polygon (a geometry or a Feature holding one) or a
+	// WKT POLYGON string.
+	Area       json.RawMessage `json:"area"`
+	SRID       int             `json:"srid"`       // SRID of the area's coordinates; default 4326
+	Relation   string          `json:"relation"`   // intersects (default) | within | contains
+	Sources    []string        `json:"sources"`    // optional: restrict to these source ids
+	Properties []string        `json:"properties"` // optional: only these feature properties
+}
+
+// geoJSONPolygon is a GeoJSON Polygon, or a Feature whose geometry is one.
+type geoJSONPolygon struct {
+	Type        string          `json:"type"`
+	Coordinates [][][]float64   `json:"coordinates"`
+	Geometry    *geoJSONPolygon `json:"geometry"`
+}
+
+// parseArea resolves the area of the request body to a domain.Area.
+func (req *areaRequest) parseArea() (domain.Area, error) {
+	srid := req.SRID
+	if srid == 0 {
+		srid = domain.SRIDWGS84
+	}
+	if len(req.Area) == 0 || string(req.Area) == "null" {
+		return domain.Area{}, errors.New("area required: provide a GeoJSON Polygon or a WKT POLYGON")
+	}
+	if req.Area[0] == '"' {
+		var wkt string
+		if err := json.Unmarshal(req.Area, &wkt); err != nil {
+			return domain.Area{}, fmt.Errorf("invalid area: %w", err)
+		}
+		area, err := domain.ParseAreaWKT(wkt, srid)
+		if err != nil {
+			var ve *domain.ValidationError
+			if errors.As(err, &ve) {
+				return domain.Area{}, errors.New(ve.Message)
+			}
+			return domain.Area{}, err
+		}
+		return area, nil
+	}
+
+	var poly geoJSONPolygon
+	if err := json.Unmarshal(req.Area, &poly); err != nil {
+		return domain.Area{}, fmt.Errorf("invalid area: %w", err)
+	}
+	if poly.Type == "Feature" && poly.Geometry != nil {
+		poly = *poly.Geometry
+	}
+	if poly.Type != "Polygon" {
+		return domain.Area{}, fmt.Errorf("area must be a GeoJSON Polygon, got %q", poly.Type)
+	}
+	rings := make([][]domain.Coordinate, len(poly.Coordinates))
+	for ri, ring := range poly.Coordinates {
+		rings[ri] = make([]domain.Coordinate, len(ring))
+		for pi, c := range ring {
+			if len(c) < 2 {
+				return domain.Area{}, fmt.Errorf("area ring %d position %d needs at least x and y", ri, pi)
+			}
+			rings[ri][pi] = domain.Coordinate{X: c[0], Y: c[1]}
+		}
+	}
+	return domain.NewArea(rings, srid), nil
+}
+
+// handleQueryArea returns the features selected by a polygon — a shape drawn
+// on the map — per source: those intersecting it, within it or containing
+// it, as the relation asks.
+func (s *Server) handleQueryArea(w http.ResponseWriter, r *http.Request) {
+	// ~64 B per GeoJSON position, plus headroom for the rest of the body.
+	r.Body = http.MaxBytesReader(w, r.Body, int64(s.areaMaxPoints)*64+64*1024)
+	var req areaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			s.writeError(w, r, http.StatusRequestEntityTooLarge, "request body too large — send a polygon with fewer points")
+			return
+		}
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid JSON body: %v", err))
+		return
+	}
+	rel, err := domain.ParseSpatialRelation(req.Relation)
+	if err != nil {
+		s.handleQueryError(w, r, err)
+		return
+	}
+	area, err := req.parseArea()
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if n := area.Points(); n > s.areaMaxPoints {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("area of %d points exceeds the limit of %d", n, s.areaMaxPoints))
+		return
+	}
+
+	ticket, ok := s.admitQuery(w, r)
+	if !ok {
+		return
+	}
+	defer ticket.release()
+
+	resp, err := s.queryService.QueryArea(r.Context(), area, rel, req.Sources, req.Properties)
+	if err != nil {
+		s.handleQueryError(w, r, err)
+		return
+	}
+	ticket.charge(estimateResponseBytes(resp))
+	out := s.formatQueryResponse(resp)
+	s.writeJSON(w, http.StatusOK, AreaResponseDTO{
+		Area:             AreaDTO{Rings: len(area.Rings), Points: area.Points(), SRID: area.SRID, Relation: string(rel)},
+		Results:          out.Results,
+		TotalFeatures:    out.TotalFeatures,
+		ProcessingTimeMS: out.ProcessingTimeMS,
+	})
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func doArea(t *testing.T, srv *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/query/area", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rec, req)
+	return rec
+}
+
+// newAreaServer is newRouteServer with the area point limit set instead.
+func newAreaServer(t *testing.T, maxPoints int) *Server {
+	t.Helper()
+	srv := newRouteServer(t, 0)
+	srv.areaMaxPoints = maxPoints
+	return srv
+}
+
+// TestQueryAreaAcceptsGeoJSONAndWKT: a GeoJSON Polygon, a Feature holding one
+// and a WKT string all answer 200 with the area and relation echoed.
+func TestQueryAreaAcceptsGeoJSONAndWKT(t *testing.T) {
+	srv := newAreaServer(t, 100)
+	bodies := map[string]string{
+		"geojson": `{"area":{"type":"Polygon","coordinates":[[[9,49],[10,49],[10,50],[9,49]]]},"relation":"within"}`,
+		"feature": `{"area":{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[9,49],[10,49],[10,50],[9,49]]]}},"relation":"within"}`,
+		"wkt":     `{"area":"POLYGON((9 49, 10 49, 10 50, 9 49))","relation":"within"}`,
+	}
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			rec := doArea(t, srv, body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+			}
+			var resp AreaResponseDTO
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if resp.Area.Rings != 1 || resp.Area.Points != 4 || resp.Area.SRID != 4326 || resp.Area.Relation != "within" {
+				t.Errorf("area = %+v, want 1 ring of 4 points in 4326, within", resp.Area)
+			}
+		})
+	}
+}
+
+// TestQueryAreaBadRequest: a missing or malformed area, an unknown relation
+// and a polygon over the point limit are 400.
+func TestQueryAreaBadRequest(t *testing.T) {
+	srv := newAreaServer(t, 4)
+	tests := map[string]string{
+		"no area":          `{}`,
+		"linestring":       `{"area":{"type":"LineString","coordinates":[[0,0],[1,1]]}}`,
+		"bad wkt":          `{"area":"POLYGON(0 0, 1 0, 1 1, 0 0)"}`,
+		"open ring":        `{"area":"POLYGON((0 0, 1 0, 1 1, 0 1))"}`,
+		"unknown relation": `{"area":"POLYGON((0 0, 1 0, 1 1, 0 0))","relation":"touches"}`,
+		"too many":         `{"area":"POLYGON((0 0, 1 0, 1 1, 0 1, 0 0))"}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			if rec := doArea(t, srv, body); rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400 (body: %s)", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -84,40 +84,51 @@ func (s *Server) handleQueryBatch(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		var maxErr *http.MaxBytesError
 		if errors.As(err, &maxErr) {
-			s.writeError(w, http.StatusRequestEntityTooLarge, "request body too large — send fewer points or smaller per-point fields (e.g. shorter id values); NDJSON streaming only affects the response, not the request-body limit")
+			s.writeError(w, r, http.StatusRequestEntityTooLarge, "request body too large — send fewer points or smaller per-point fields (e.g. shorter id values); NDJSON streaming only affects the response, not the request-body limit")
 			return
 		}
-		s.writeError(w, http.StatusBadRequest, err.Error())
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 	if len(req.Points) == 0 {
-		s.writeError(w, http.StatusBadRequest, "points required: provide at least one point")
+		s.writeError(w, r, http.StatusBadRequest, "points required: provide at least one point")
 		return
 	}
 	if len(req.Points) > s.batchMaxPoints {
-		s.writeError(w, http.StatusBadRequest, fmt.Sprintf("batch of %d points exceeds the limit of %d", len(req.Points), s.batchMaxPoints))
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("batch of %d points exceeds the limit of %d", len(req.Points), s.batchMaxPoints))
 		return
 	}
 	stream := prefersNDJSON(r)
 	if !stream && len(req.Points) > s.batchMaxSync {
-		s.writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf(
+		s.writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf(
 			"batch of %d points exceeds the sync limit of %d — retry with 'Accept: application/x-ndjson' to stream",
 			len(req.Points), s.batchMaxSync))
 		return
 	}
 
+	ticket, ok := s.admitQuery(w, r)
+	if !ok {
+		return
+	}
+	defer ticket.release()
+
 	in := s.resolveBatchInputs(r, req)
 
 	start := time.Now()
 	sub, err := s.queryService.QueryBatch(r.Context(), in.valid, req.Sources, req.Properties)
 	if err != nil {
-		s.handleQueryError(w, err) // e.g. unknown source → 404
+		s.handleQueryError(w, r, err) // e.g. unknown source → 404
 		return
 	}
+	var size int64
+	for _, resp := range sub {
+		size += estimateResponseBytes(resp)
+	}
+	ticket.charge(size)
 	if len(sub) != len(in.valid) {
 		// Invariant: QueryBatch returns one response per input coordinate. Guard so a
 		// future divergence fails cleanly instead of panicking on the scatter below.
-		s.writeError(w, http.StatusInternalServerError, "batch query returned an unexpected result count")
+		s.writeError(w, r, http.StatusInternalServerError, "batch query returned an unexpected result count")
 		return
 	}
 	responses := make([]*domain.QueryResponse, len(req.Points))
@@ -125,15 +136,24 @@ func (s *Server) handleQueryBatch(w http.ResponseWriter, r *http.Request) {
 		responses[origIdx] = sub[k]
 	}
 
-	items := s.buildBatchItems(r, req, in.wgs, in.wgsOK, responses, in.itemErr)
+	gaz := s.batchGazetteer(r, req, in.wgs, in.wgsOK, in.itemErr)
+	item := func(i int) BatchItemDTO {
+		it := s.batchItem(req, &in, responses[i], gaz, i)
+		responses[i] = nil // formatted; the stream need not hold it any longer
+		return it
+	}
 	if stream {
-		s.streamBatchItems(w, r, items)
+		s.streamBatchItems(w, r, len(req.Points), item)
 		return
 	}
-	s.writeJSON(w, http.StatusOK, map[string]interface{}{
-		"results":            items,
-		"total":              len(items),
-		"processing_time_ms": time.Since(start).Milliseconds(),
+	items := make([]BatchItemDTO, len(req.Points))
+	for i := range items {
+		items[i] = item(i)
+	}
+	s.writeJSON(w, http.StatusOK, BatchResponseDTO{
+		Results:          items,
+		Total:            len(items),
+		ProcessingTimeMS: time.Since(start).Milliseconds(),
 	})
 }
 
@@ -186,31 +206,22 @@ func prefersNDJSON(r *http.Request) bool {
 	return strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
 }
 
-// buildBatchItems assembles one response item per input point (in order): the
-// per-source PiP result + echo id + the wgs84 block, plus the gazetteer block when
-// enrichment was requested. A per-point resolution error becomes an error object.
-func (s *Server) buildBatchItems(r *http.Request, req *batchRequest, wgs []domain.Coordinate, wgsOK []bool, responses []*domain.QueryResponse, itemErr []string) []map[string]interface{} {
-	gaz := s.batchGazetteer(r, req, wgs, wgsOK, itemErr)
-	items := make([]map[string]interface{}, len(req.Points))
-	for i := range req.Points {
-		id := req.Points[i].idOr(i)
-		if itemErr[i] != "" {
-			items[i] = map[string]interface{}{"id": id, "error": map[string]interface{}{"message": itemErr[i]}}
-			continue
-		}
-		item := s.formatQueryResponse(responses[i])
-		// The batch reports processing_time_ms once at the top level; drop the
-		// per-item copy (the single-point formatter adds it) so each item matches
-		// the BatchQueryResultItem schema.
-		delete(item, "processing_time_ms")
-		item["id"] = id
-		if wgsOK[i] {
-			item["wgs84"] = wgs84Block(wgs[i])
-		}
-		if len(gaz) > i && gaz[i] != nil {
-			item["gazetteer"] = gaz[i]
-		}
-		items[i] = item
+// batchItem assembles the response item of input point i: the per-source PiP
+// result + echo id + the wgs84 block, plus the gazetteer block when enrichment
+// was requested. A per-point resolution error becomes an error object.
+func (s *Server) batchItem(req *batchRequest, in *batchInputs, resp *domain.QueryResponse, gaz []map[string]interface{}, i int) BatchItemDTO {
+	item := BatchItemDTO{ID: req.Points[i].idOr(i)}
+	if in.itemErr[i] != "" {
+		item.Error = &BatchErrorDTO{Message: in.itemErr[i]}
+		return item
+	}
+	out := s.formatQueryResponse(resp)
+	if in.wgsOK[i] {
+		out.WGS84 = wgs84Block(in.wgs[i])
+	}
+	if len(gaz) > i && gaz[i] != nil {
+		out.Gazetteer = gaz[i]
 	}
-	return items
+	item.QueryResponseDTO = &out
+	return item
 }
@@ -22,7 +22,11 @@ func (s *Server) batchGazetteer(r *http.Request, req *batchRequest, wgs []domain
 	}
 	ctx := r.Context()
 	out := make([]map[string]interface{}, len(wgs))
-	sem := make(chan struct{}, s.batchConcurrency)
+	workers := s.batchConcurrency
+	if !s.flagEnabled(ctx, domain.FlagParallelEnrichment) {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
 	var wg sync.WaitGroup
 	for i := range wgs {
 		if itemErr[i] != "" || !wgsOK[i] {
@@ -65,22 +69,23 @@ func (s *Server) enrichGazetteerPoint(ctx context.Context, w domain.Coordinate)
 	return sec
 }
 
-// streamBatchItems writes each item as its own JSON line (application/x-ndjson),
-// flushing per line so the client can consume results incrementally and the
-// server holds no large response buffer. The result set itself is computed
-// set-based up front (one SQL per source), so this streams the already-resolved
-// items rather than producing them lazily — a v1 trade-off (see the plan). It
-// still lets a client abort mid-write via the request context.
-func (s *Server) streamBatchItems(w http.ResponseWriter, r *http.Request, items []map[string]interface{}) {
+// streamBatchItems writes the n items as one JSON line each
+// (application/x-ndjson), flushing per line so the client can consume results
+// incrementally. The point-in-polygon results are computed set-based up front
+// (one SQL per source), but each item is formatted by item only when it is
+// written, so the server never holds the rendered response: for batches of
+// tens of thousands of points the formatted DTOs are the larger part. It still
+// lets a client abort mid-write via the request context.
+func (s *Server) streamBatchItems(w http.ResponseWriter, r *http.Request, n int, item func(int) BatchItemDTO) {
 	w.Header().Set("Content-Type", "application/x-ndjson")
 	w.WriteHeader(http.StatusOK)
 	flusher, _ := w.(http.Flusher)
 	enc := json.NewEncoder(w)
-	for _, item := range items {
+	for i := 0; i < n; i++ {
 		if err := r.Context().Err(); err != nil {
 			return // client disconnected
 		}
-		if err := enc.Encode(item); err != nil { // Encode writes the trailing newline
+		if err := enc.Encode(item(i)); err != nil { // Encode writes the trailing newline
 			s.logger.Debug("batch stream write failed", "error", err)
 			return
 		}
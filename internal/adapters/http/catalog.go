@@ -0,0 +1,152 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// errInvalidBBox is the client error of a malformed ?bbox=.
+var errInvalidBBox = errors.New("bbox must be minx,miny,maxx,maxy with min <= max")
+
+// catalogParams are the query parameters of GET /api/v1/catalog.
+type catalogParams struct {
+	Keyword string `query:"keyword"`
+	BBox    string `query:"bbox"`
+	SRID    int    `query:"srid"`
+}
+
+// handleCatalog searches the loaded sources: ?keyword= matches a keyword or
+// tag exactly and the title or name in part, case-insensitively; ?bbox=
+// (minx,miny,maxx,maxy in ?srid=, default 4326) keeps the sources with a
+// layer whose extent intersects it. Without parameters every source is
+// listed.
+func (s *Server) handleCatalog(w http.ResponseWriter, r *http.Request) {
+	params := catalogParams{SRID: domain.SRIDWGS84}
+	if err := decodeQuery(r.URL.Query(), &params); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	var bbox *domain.Extent
+	if params.BBox != "" {
+		e, err := parseBBox(params.BBox, params.SRID)
+		if err != nil {
+			s.writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		bbox = &e
+	}
+
+	sources, err := s.registry.ListSources(r.Context())
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to list sources")
+		return
+	}
+	entries := make([]CatalogEntryDTO, 0, len(sources))
+	for i := range sources {
+		src := &sources[i]
+		if !matchesKeyword(src, params.Keyword) {
+			continue
+		}
+		var layers []string
+		if bbox != nil {
+			if layers = s.coveringLayers(r.Context(), src, *bbox); len(layers) == 0 {
+				continue
+			}
+		}
+		entries = append(entries, CatalogEntryDTO{SourceDTO: s.formatSource(src), Layers: layers})
+	}
+	s.writeJSON(w, http.StatusOK, CatalogDTO{Sources: entries, Count: len(entries)})
+}
+
+// parseBBox parses a "minx,miny,maxx,maxy" bounding box in srid.
+func parseBBox(raw string, srid int) (domain.Extent, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 4 {
+		return domain.Extent{}, errInvalidBBox
+	}
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return domain.Extent{}, errInvalidBBox
+		}
+		v[i] = f
+	}
+	e := domain.Extent{MinX: v[0], MinY: v[1], MaxX: v[2], MaxY: v[3], SRID: srid}
+	if !e.IsValid() {
+		return domain.Extent{}, errInvalidBBox
+	}
+	return e, nil
+}
+
+// matchesKeyword reports whether src matches keyword; "" matches every
+// source.
+func matchesKeyword(src *domain.Source, keyword string) bool {
+	if keyword == "" {
+		return true
+	}
+	for _, k := range src.Metadata.Keywords {
+		if strings.EqualFold(k, keyword) {
+			return true
+		}
+	}
+	for _, t := range src.Tags {
+		if strings.EqualFold(t, keyword) {
+			return true
+		}
+	}
+	keyword = strings.ToLower(keyword)
+	return strings.Contains(strings.ToLower(src.Metadata.Title), keyword) ||
+		strings.Contains(strings.ToLower(src.Name), keyword)
+}
+
+// coveringLayers returns the names of the layers of src whose extent
+// intersects bbox. A layer in another SRID is compared with bbox reprojected
+// to it; without a transformer for the pair it never matches, and neither
+// does a layer without an extent.
+func (s *Server) coveringLayers(ctx context.Context, src *domain.Source, bbox domain.Extent) []string {
+	var names []string
+	for _, l := range src.Layers {
+		if l.Extent == nil {
+			continue
+		}
+		srid := l.Extent.SRID
+		if srid == 0 {
+			srid = l.SRID
+		}
+		box, ok := s.reprojectBBox(ctx, bbox, srid)
+		if ok && l.Extent.Intersects(box) {
+			names = append(names, l.Name)
+		}
+	}
+	return names
+}
+
+// reprojectBBox returns the extent of bbox's corners in srid.
+func (s *Server) reprojectBBox(ctx context.Context, bbox domain.Extent, srid int) (domain.Extent, bool) {
+	if srid == bbox.SRID || srid == 0 {
+		return bbox, true
+	}
+	if s.transformer == nil || !s.transformer.IsSupported(bbox.SRID, srid) {
+		return domain.Extent{}, false
+	}
+	out := domain.Extent{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1), SRID: srid}
+	for _, x := range []float64{bbox.MinX, bbox.MaxX} {
+		for _, y := range []float64{bbox.MinY, bbox.MaxY} {
+			c, err := s.transformer.Transform(ctx, domain.Coordinate{X: x, Y: y, SRID: bbox.SRID}, srid)
+			if err != nil {
+				s.logger.Debug("catalog bbox not reprojected", "from", bbox.SRID, "to", srid, "error", err)
+				return domain.Extent{}, false
+			}
+			out.MinX, out.MaxX = math.Min(out.MinX, c.X), math.Max(out.MaxX, c.X)
+			out.MinY, out.MaxY = math.Min(out.MinY, c.Y), math.Max(out.MaxY, c.Y)
+		}
+	}
+	return out, true
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func TestCatalogSearch(t *testing.T) {
+	srv := newGazetteerServer(t, fakeGazetteer{})
+	srv.registry = &mockSourceRegistry{packages: []domain.Source{
+		{
+			ID: "parcels", Name: "parcels.gpkg",
+			Metadata: domain.Metadata{Title: "Flurstücke Hamburg", Keywords: []string{"Kataster"}},
+			Layers: []domain.Layer{
+				{Name: "parcels", SRID: 4326, Extent: &domain.Extent{MinX: 9.7, MinY: 53.4, MaxX: 10.3, MaxY: 53.7, SRID: 4326}},
+				{Name: "buildings", SRID: 4326},
+			},
+		},
+		{
+			ID: "districts", Name: "districts.gpkg", Tags: []string{"admin"},
+			Layers: []domain.Layer{
+				{Name: "districts", SRID: 25832, Extent: &domain.Extent{MinX: 500000, MinY: 5900000, MaxX: 600000, MaxY: 6000000}},
+			},
+		},
+	}}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"parcels", "districts"}},
+		{"keyword=kataster", []string{"parcels"}},
+		{"keyword=HAMBURG", []string{"parcels"}},
+		{"keyword=Admin", []string{"districts"}},
+		{"keyword=roads", nil},
+		{"bbox=9.9,53.5,10.1,53.6", []string{"parcels"}},
+		{"bbox=11,48,12,49", nil},
+		// Without a transformer a layer in another SRID never matches.
+		{"bbox=550000,5950000,560000,5960000&srid=25832", []string{"districts"}},
+	}
+	for _, tt := range tests {
+		rec, body := doGET(t, srv, "/api/v1/catalog?"+tt.query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: status = %d, want 200", tt.query, rec.Code)
+		}
+		sources, _ := body["sources"].([]any)
+		var got []string
+		for _, s := range sources {
+			got = append(got, s.(map[string]any)["id"].(string))
+		}
+		if len(got) != len(tt.want) || body["count"] != float64(len(tt.want)) {
+			t.Errorf("%q: sources = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q: sources = %v, want %v", tt.query, got, tt.want)
+			}
+		}
+	}
+
+	_, body := doGET(t, srv, "/api/v1/catalog?bbox=9.9,53.5,10.1,53.6")
+	entry := body["sources"].([]any)[0].(map[string]any)
+	if layers, _ := entry["layers"].([]any); len(layers) != 1 || layers[0] != "parcels" {
+		t.Errorf("covering layers = %v, want [parcels]", entry["layers"])
+	}
+
+	// A supported transformer lets a WGS84 box reach the 25832 layer.
+	srv.transformer = fakeTransformer{lon: 550000, lat: 5950000, supported: true}
+	_, body = doGET(t, srv, "/api/v1/catalog?bbox=9.9,53.5,10.1,53.6&keyword=admin")
+	if body["count"] != float64(1) {
+		t.Errorf("reprojected bbox: count = %v, want 1", body["count"])
+	}
+
+	for _, q := range []string{"bbox=1,2,3", "bbox=a,b,c,d", "bbox=10,53,9,54", "bbox=9,53,10,54&srid=x"} {
+		if rec, _ := doGET(t, srv, "/api/v1/catalog?"+q); rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
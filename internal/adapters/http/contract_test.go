@@ -1,7 +1,10 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
+	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -17,9 +20,12 @@ import (
 // compared separately by their own handlers; /sync is operator-only and not
 // part of the documented query contract.
 func TestRoutesMatchOpenAPISpec(t *testing.T) {
-	// Wire a (fake) gazetteer so the conditionally-registered /gazetteer route
-	// exists — it is part of the documented query contract (unlike operator-only
-	// /sync, which is intentionally undocumented).
+	// Wire a (fake) gazetteer and geocoder, a popularity tracker, a peer
+	// catalog and the source health reporter so the conditionally-registered
+	// /gazetteer, /geocode, /popularity, /peers and /sources/{sourceId}/health
+	// routes exist — all
+	// are part of the documented contract (unlike operator-only /sync, which
+	// is intentionally undocumented).
 	srv := newGazetteerServer(t, fakeGazetteer{})
 
 	// Compare "METHOD path" pairs (not just paths) so a GET→POST drift on the
@@ -44,8 +50,8 @@ func TestRoutesMatchOpenAPISpec(t *testing.T) {
 		t.Fatalf("walk router: %v", err)
 	}
 
-	// 2. Documented operations from the embedded spec (the bytes actually served
-	// at /openapi.json), minus the root health endpoints.
+	// 2. Documented operations from the embedded spec, minus the root health,
+	// admin and STAC endpoints.
 	specJSON, err := getOpenAPIJSON()
 	if err != nil {
 		t.Fatalf("getOpenAPIJSON: %v", err)
@@ -62,7 +68,7 @@ func TestRoutesMatchOpenAPISpec(t *testing.T) {
 	}
 	documented := map[string]bool{}
 	for p, ops := range spec.Paths {
-		if strings.HasPrefix(p, "/health") {
+		if strings.HasPrefix(p, "/health") || strings.HasPrefix(p, "/admin/") || strings.HasPrefix(p, "/stac") {
 			continue
 		}
 		for op := range ops {
@@ -84,3 +90,199 @@ func TestRoutesMatchOpenAPISpec(t *testing.T) {
 		}
 	}
 }
+
+// TestOpenAPIQueryParameters holds openapi.yaml to the query parameters the
+// handlers decode: the served document is generated from the parameter
+// structs anyway, but the committed spec (and its copy under api/openapi)
+// should not need the generator to be complete.
+func TestOpenAPIQueryParameters(t *testing.T) {
+	srv := newGazetteerServer(t, fakeGazetteer{})
+	spec, err := getOpenAPISpec()
+	if err != nil {
+		t.Fatalf("getOpenAPISpec: %v", err)
+	}
+	paths := spec["paths"].(map[string]interface{})
+
+	for op, structs := range srv.queryParamStructs() {
+		method, path, _ := strings.Cut(op, " ")
+		item, _ := paths[path].(map[string]interface{})
+		operation, ok := item[strings.ToLower(method)].(map[string]interface{})
+		if !ok {
+			t.Errorf("%s is not documented", op)
+			continue
+		}
+		documented := map[string]string{}
+		params, _ := operation["parameters"].([]interface{})
+		for _, p := range params {
+			resolved := resolveParameter(spec, p)
+			if resolved["in"] == "query" {
+				schema, _ := resolved["schema"].(map[string]interface{})
+				typ, _ := schema["type"].(string)
+				documented[resolved["name"].(string)] = typ
+			}
+		}
+		for _, gen := range queryParameters(structs...) {
+			name := gen["name"].(string)
+			want := gen["schema"].(map[string]interface{})["type"]
+			typ, ok := documented[name]
+			switch {
+			case !ok:
+				t.Errorf("%s: query parameter %q is decoded but not documented", op, name)
+			case typ != want:
+				t.Errorf("%s: query parameter %q documented as %q, decoded as %q", op, name, typ, want)
+			}
+			delete(documented, name)
+		}
+		for name := range documented {
+			t.Errorf("%s: documents query parameter %q the handler does not decode", op, name)
+		}
+	}
+}
+
+// TestResponseDTOsMatchOpenAPISpec holds the response DTOs to the schemas
+// openapi.yaml documents for them: every JSON field a DTO marshals is a
+// documented property and every documented property is a DTO field.
+func TestResponseDTOsMatchOpenAPISpec(t *testing.T) {
+	spec, err := getOpenAPISpec()
+	if err != nil {
+		t.Fatalf("getOpenAPISpec: %v", err)
+	}
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	source := schemaProperties(schemas, schemas["Source"])
+	tileSet := source["tile_sets"].(map[string]interface{})["items"]
+
+	tests := []struct {
+		dto    interface{}
+		schema interface{}
+		// undocumented are DTO fields the schema leaves out on purpose.
+		undocumented []string
+	}{
+		{dto: QueryResponseDTO{}, schema: schemas["QueryResponse"]},
+		{dto: CoordinateDTO{}, schema: schemas["Coordinate"]},
+		{dto: WGS84DTO{}, schema: schemas["Wgs84Coordinate"]},
+		{dto: QueryResultDTO{}, schema: schemas["QueryResult"]},
+		{dto: FeatureDTO{}, schema: schemas["Feature"]},
+		{dto: MatchDTO{}, schema: schemas["FeatureMatch"]},
+		{dto: GeometryDTO{}, schema: schemas["Geometry"]},
+		{dto: LicenseDTO{}, schema: schemas["License"]},
+		{dto: NoteDTO{}, schema: schemas["LayerNote"]},
+		{dto: PeerStatusDTO{}, schema: schemas["PeerOutcome"]},
+		{dto: ExplainDTO{}, schema: schemas["QueryExplain"]},
+		{dto: LayerExplainDTO{}, schema: schemas["LayerExplain"]},
+		{dto: TransformExplainDTO{}, schema: schemas["TransformExplain"]},
+		{dto: ExplainTimingsDTO{}, schema: schemas["ExplainTimings"]},
+		{dto: BatchResponseDTO{}, schema: schemas["BatchQueryResponse"]},
+		{dto: RouteResponseDTO{}, schema: schemas["RouteQueryResponse"]},
+		{dto: AreaResponseDTO{}, schema: schemas["AreaQueryResponse"]},
+		{dto: AggregateResponseDTO{}, schema: schemas["AggregateQueryResponse"]},
+		{dto: AggregateResultDTO{}, schema: schemas["AggregateResult"]},
+		{dto: LayerAggregateDTO{}, schema: schemas["LayerAggregate"]},
+		{dto: GroupCountDTO{}, schema: schemas["GroupCount"]},
+		{dto: PropertyStatsDTO{}, schema: schemas["PropertyStats"]},
+		// A batch never forwards to peers nor cuts a single point short,
+		// is never explained, and reports its processing time at the top level.
+		{dto: BatchItemDTO{}, schema: schemas["BatchQueryResultItem"], undocumented: []string{"incomplete", "peers", "explain", "processing_time_ms"}},
+		{dto: SourceListDTO{}, schema: schemas["SourceList"]},
+		{dto: SourceDTO{}, schema: schemas["Source"]},
+		{dto: CatalogDTO{}, schema: schemas["Catalog"]},
+		{dto: CatalogEntryDTO{}, schema: schemas["CatalogEntry"]},
+		{dto: StatsSummaryDTO{}, schema: schemas["StatsSummary"]},
+		{dto: GeocodeResponseDTO{}, schema: schemas["GeocodeResponse"]},
+		{dto: GeocodeResultDTO{}, schema: schemas["GeocodeResult"]},
+		{dto: STACCatalogDTO{}, schema: schemas["STACCatalog"]},
+		{dto: STACCollectionDTO{}, schema: schemas["STACCollection"]},
+		{dto: STACLinkDTO{}, schema: schemas["STACLink"]},
+		{dto: TileSetDTO{}, schema: tileSet},
+		{dto: LayerListDTO{}, schema: schemas["LayerList"]},
+		{dto: LayerDTO{}, schema: schemas["Layer"]},
+		{dto: ExtentDTO{}, schema: schemas["Extent"]},
+		{dto: ProblemDTO{}, schema: schemas["OutsideExtentError"]},
+		// The legacy envelope is documented without the strict-extent case.
+		{dto: ErrorDTO{}, schema: schemas["Error"], undocumented: []string{"extent"}},
+	}
+	for _, tt := range tests {
+		name := reflect.TypeOf(tt.dto).Name()
+		documented := schemaProperties(schemas, tt.schema)
+		if len(documented) == 0 {
+			t.Errorf("%s: schema has no properties", name)
+			continue
+		}
+		fields := jsonFields(reflect.TypeOf(tt.dto))
+		for _, f := range tt.undocumented {
+			delete(fields, f)
+		}
+		for f := range fields {
+			if _, ok := documented[f]; !ok {
+				t.Errorf("%s: field %q is not documented", name, f)
+			}
+		}
+		for p := range documented {
+			if !fields[p] {
+				t.Errorf("%s: documented property %q has no field", name, p)
+			}
+		}
+	}
+}
+
+// schemaProperties returns the properties of a schema, following $ref and
+// merging allOf.
+func schemaProperties(schemas map[string]interface{}, schema interface{}) map[string]interface{} {
+	m, _ := schema.(map[string]interface{})
+	if ref, ok := m["$ref"].(string); ok {
+		return schemaProperties(schemas, schemas[strings.TrimPrefix(ref, "#/components/schemas/")])
+	}
+	out := map[string]interface{}{}
+	if parts, ok := m["allOf"].([]interface{}); ok {
+		for _, part := range parts {
+			for k, v := range schemaProperties(schemas, part) {
+				out[k] = v
+			}
+		}
+	}
+	props, _ := m["properties"].(map[string]interface{})
+	for k, v := range props {
+		out[k] = v
+	}
+	return out
+}
+
+// TestOpenAPICopyInSync guards api/openapi/openapi.yaml, the copy published
+// for client generators, against drifting from the embedded spec. Run
+// `make openapi-sync` after editing the embedded one.
+func TestOpenAPICopyInSync(t *testing.T) {
+	embedded, err := openAPIYAML.ReadFile("openapi.yaml")
+	if err != nil {
+		t.Fatalf("read embedded spec: %v", err)
+	}
+	published, err := os.ReadFile("../../../api/openapi/openapi.yaml")
+	if err != nil {
+		t.Fatalf("read api/openapi/openapi.yaml: %v", err)
+	}
+	if !bytes.Equal(embedded, published) {
+		t.Error("api/openapi/openapi.yaml differs from the embedded spec; run `make openapi-sync`")
+	}
+}
+
+// jsonFields returns the JSON names encoding/json marshals for t, with the
+// fields of embedded structs flattened.
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			et := f.Type
+			if et.Kind() == reflect.Pointer {
+				et = et.Elem()
+			}
+			for name := range jsonFields(et) {
+				fields[name] = true
+			}
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
@@ -5,9 +5,14 @@ import (
 	"strings"
 )
 
-// corsMiddleware handles CORS headers based on configuration.
+// corsMiddleware handles CORS headers based on configuration. Without
+// allowed origins it passes every request through, preflights included.
 func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.settings().corsOrigins) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
 		origin := r.Header.Get("Origin")
 
 		// Check if origin is allowed
@@ -31,7 +36,7 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 
 // isOriginAllowed checks if the given origin matches any allowed pattern.
 func (s *Server) isOriginAllowed(origin string) bool {
-	for _, pattern := range s.config.CORS.AllowedOrigins {
+	for _, pattern := range s.settings().corsOrigins {
 		if matchOrigin(origin, pattern) {
 			return true
 		}
@@ -1,6 +1,8 @@
 package http
 
 import (
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -244,13 +246,8 @@ func TestServer_isOriginAllowed(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := &Server{
-				config: config.ServerConfig{
-					CORS: config.CORSConfig{
-						AllowedOrigins: tt.allowedOrigins,
-					},
-				},
-			}
+			s := &Server{}
+			s.live.Store(&liveSettings{corsOrigins: tt.allowedOrigins})
 
 			result := s.isOriginAllowed(tt.origin)
 			if result != tt.expected {
@@ -282,13 +279,8 @@ func runCORSTest(t *testing.T, tt *corsTestCase) {
 	})
 
 	// Create server with CORS config
-	s := &Server{
-		config: config.ServerConfig{
-			CORS: config.CORSConfig{
-				AllowedOrigins: tt.allowedOrigins,
-			},
-		},
-	}
+	s := &Server{}
+	s.live.Store(&liveSettings{corsOrigins: tt.allowedOrigins})
 
 	// Wrap with CORS middleware
 	handler := s.corsMiddleware(nextHandler)
@@ -422,13 +414,8 @@ func TestCORSMiddleware_PreflightDoesNotCallNext(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	s := &Server{
-		config: config.ServerConfig{
-			CORS: config.CORSConfig{
-				AllowedOrigins: []string{"https://example.com"},
-			},
-		},
-	}
+	s := &Server{}
+	s.live.Store(&liveSettings{corsOrigins: []string{"https://example.com"}})
 
 	handler := s.corsMiddleware(nextHandler)
 
@@ -488,3 +475,23 @@ func TestCORSConfig_Enabled(t *testing.T) {
 		})
 	}
 }
+
+func TestReconfigureSwitchesCORS(t *testing.T) {
+	s := NewServer(config.ServerConfig{Host: "localhost", Port: 8080}, nil, nil, nil, nil,
+		slog.New(slog.NewTextHandler(io.Discard, nil)), false, ServerOptions{})
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := get(); rr.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("CORS headers without configured origins")
+	}
+	s.Reconfigure(config.ServerConfig{CORS: config.CORSConfig{AllowedOrigins: []string{"*.example.com"}}})
+	if got := get().Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("after reload: allow-origin %q", got)
+	}
+}
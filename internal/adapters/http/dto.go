@@ -0,0 +1,419 @@
+package http
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The types in this file are the JSON bodies of the /api/v1 query and
+// catalog endpoints. Handlers build and marshal these instead of ad-hoc maps,
+// so a field name is spelled once, and TestResponseDTOsMatchOpenAPISpec holds
+// them to the schemas in openapi.yaml. Fields that are only present when set
+// carry omitempty (omitzero for times).
+
+// QueryResponseDTO is the body of GET /api/v1/query and
+// /api/v1/query/{sourceId}.
+type QueryResponseDTO struct {
+	Coordinate       CoordinateDTO          `json:"coordinate"`
+	Results          []QueryResultDTO       `json:"results"`
+	TotalFeatures    int                    `json:"total_features"`
+	ProcessingTimeMS int64                  `json:"processing_time_ms"`
+	Incomplete       bool                   `json:"incomplete,omitempty"`
+	Peers            []PeerStatusDTO        `json:"peers,omitempty"`
+	WGS84            *WGS84DTO              `json:"wgs84,omitempty"`
+	Gazetteer        map[string]interface{} `json:"gazetteer,omitempty"`
+	Explain          *ExplainDTO            `json:"explain,omitempty"`
+}
+
+// CoordinateDTO echoes the queried point in its request SRID.
+type CoordinateDTO struct {
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Z    float64 `json:"z,omitempty"`
+	SRID int     `json:"srid"`
+}
+
+// WGS84DTO is the queried point reprojected to WGS84.
+type WGS84DTO struct {
+	Lon float64 `json:"lon"`
+	Lat float64 `json:"lat"`
+}
+
+// QueryResultDTO is the answer of one source to a point query.
+type QueryResultDTO struct {
+	SourceID     string       `json:"source_id"`
+	SourceName   string       `json:"source_name"`
+	Features     []FeatureDTO `json:"features"`
+	FeatureCount int          `json:"feature_count"`
+	QueryTimeMS  int64        `json:"query_time_ms"`
+	License      *LicenseDTO  `json:"license,omitempty"`
+	Incomplete   bool         `json:"incomplete,omitempty"`
+	Notes        []NoteDTO    `json:"notes,omitempty"`
+	Peer         string       `json:"peer,omitempty"`
+}
+
+// FeatureDTO is one feature of a query result.
+type FeatureDTO struct {
+	ID         int64                  `json:"id"`
+	Layer      string                 `json:"layer"`
+	Properties map[string]interface{} `json:"properties"`
+	Match      *MatchDTO              `json:"match,omitempty"`
+	Geometry   *GeometryDTO           `json:"geometry,omitempty"`
+}
+
+// MatchDTO says why a point query returned a feature.
+type MatchDTO struct {
+	Kind      string  `json:"kind"`
+	DistanceM float64 `json:"distance_m"`
+	SRID      int     `json:"srid"`
+}
+
+// GeometryDTO is a feature geometry: the WKT always, plus the encoding the
+// query requested.
+type GeometryDTO struct {
+	Type    string          `json:"type"`
+	WKT     string          `json:"wkt"`
+	WKB     string          `json:"wkb,omitempty"`
+	GeoJSON json.RawMessage `json:"geojson,omitempty"`
+	GML     string          `json:"gml,omitempty"`
+}
+
+// LicenseDTO is the license of a source.
+type LicenseDTO struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Attribution string `json:"attribution"`
+}
+
+// NoteDTO is an advisory note on a layer of a result.
+type NoteDTO struct {
+	Layer string `json:"layer"`
+	Text  string `json:"text"`
+}
+
+// PeerStatusDTO reports how a federation peer answered a forwarded query.
+type PeerStatusDTO struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	DurationMS   int64  `json:"duration_ms"`
+	FeatureCount int    `json:"feature_count"`
+	Incomplete   bool   `json:"incomplete,omitempty"`
+}
+
+// ExplainDTO is how a query was executed, returned with ?explain=true.
+type ExplainDTO struct {
+	Layers []LayerExplainDTO `json:"layers"`
+}
+
+// LayerExplainDTO is the execution of a query on one layer.
+type LayerExplainDTO struct {
+	SourceID     string               `json:"source_id"`
+	Layer        string               `json:"layer"`
+	Skipped      string               `json:"skipped,omitempty"`
+	Transform    *TransformExplainDTO `json:"transform,omitempty"`
+	SQL          string               `json:"sql,omitempty"`
+	Plan         []string             `json:"plan,omitempty"`
+	RTreeUsed    bool                 `json:"rtree_used"`
+	RowsExamined int64                `json:"rows_examined"`
+	RowsReturned int                  `json:"rows_returned"`
+	Features     int                  `json:"features"`
+	TimingsMS    ExplainTimingsDTO    `json:"timings_ms"`
+}
+
+// TransformExplainDTO is the reprojection of the query point into a layer SRID.
+type TransformExplainDTO struct {
+	FromSRID int     `json:"from_srid"`
+	ToSRID   int     `json:"to_srid"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+}
+
+// ExplainTimingsDTO times the stages of a layer query in milliseconds.
+type ExplainTimingsDTO struct {
+	Transform float64 `json:"transform"`
+	SQL       float64 `json:"sql"`
+	Total     float64 `json:"total"`
+}
+
+// BatchResponseDTO is the body of POST /api/v1/query/batch.
+type BatchResponseDTO struct {
+	Results          []BatchItemDTO `json:"results"`
+	Total            int            `json:"total"`
+	ProcessingTimeMS int64          `json:"processing_time_ms"`
+}
+
+// BatchItemDTO is the result for one point of a batch: the single-point
+// response echoing the point's id, or the error that point failed with.
+type BatchItemDTO struct {
+	ID string `json:"id"`
+	*QueryResponseDTO
+	// ProcessingTimeMS shadows the embedded field and stays nil: a batch
+	// reports the processing time once, at the top level.
+	ProcessingTimeMS *int64         `json:"processing_time_ms,omitempty"`
+	Error            *BatchErrorDTO `json:"error,omitempty"`
+}
+
+// BatchErrorDTO is why a single batch point could not be answered.
+type BatchErrorDTO struct {
+	Message string `json:"message"`
+}
+
+// RouteResponseDTO is the body of POST /api/v1/query/route.
+type RouteResponseDTO struct {
+	Route            RouteDTO         `json:"route"`
+	Results          []QueryResultDTO `json:"results"`
+	TotalFeatures    int              `json:"total_features"`
+	ProcessingTimeMS int64            `json:"processing_time_ms"`
+}
+
+// RouteDTO describes the queried route.
+type RouteDTO struct {
+	Points int `json:"points"`
+	SRID   int `json:"srid"`
+}
+
+// AreaResponseDTO is the body of POST /api/v1/query/area.
+type AreaResponseDTO struct {
+	Area             AreaDTO          `json:"area"`
+	Results          []QueryResultDTO `json:"results"`
+	TotalFeatures    int              `json:"total_features"`
+	ProcessingTimeMS int64            `json:"processing_time_ms"`
+}
+
+// AreaDTO describes the queried area.
+type AreaDTO struct {
+	Rings    int    `json:"rings"`
+	Points   int    `json:"points"`
+	SRID     int    `json:"srid"`
+	Relation string `json:"relation"`
+}
+
+// AggregateResponseDTO is the body of POST /api/v1/query/aggregate.
+type AggregateResponseDTO struct {
+	Results          []AggregateResultDTO `json:"results"`
+	TotalCount       int64                `json:"total_count"`
+	ProcessingTimeMS int64                `json:"processing_time_ms"`
+}
+
+// AggregateResultDTO is the aggregation of one source.
+type AggregateResultDTO struct {
+	SourceID   string              `json:"source_id"`
+	SourceName string              `json:"source_name,omitempty"`
+	Layers     []LayerAggregateDTO `json:"layers"`
+}
+
+// LayerAggregateDTO is the aggregation of one layer.
+type LayerAggregateDTO struct {
+	Layer           string                      `json:"layer"`
+	Count           int64                       `json:"count"`
+	Groups          []GroupCountDTO             `json:"groups,omitempty"`
+	GroupsTruncated bool                        `json:"groups_truncated,omitempty"`
+	Stats           map[string]PropertyStatsDTO `json:"stats,omitempty"`
+}
+
+// GroupCountDTO is the feature count of one group-by value.
+type GroupCountDTO struct {
+	Value interface{} `json:"value"`
+	Count int64       `json:"count"`
+}
+
+// PropertyStatsDTO summarizes the numeric values of one property; min, max
+// and avg are left out when no feature has one.
+type PropertyStatsDTO struct {
+	Count int64    `json:"count"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+	Avg   *float64 `json:"avg,omitempty"`
+}
+
+// SourceListDTO is the body of GET /api/v1/sources.
+type SourceListDTO struct {
+	Sources []SourceDTO `json:"sources"`
+	Count   int         `json:"count"`
+}
+
+// CatalogDTO is the body of GET /api/v1/catalog.
+type CatalogDTO struct {
+	Sources []CatalogEntryDTO `json:"sources"`
+	Count   int               `json:"count"`
+}
+
+// CatalogEntryDTO is a source matching a catalog search. With a bbox, Layers
+// names the layers whose extent intersects it.
+type CatalogEntryDTO struct {
+	SourceDTO
+	Layers []string `json:"layers,omitempty"`
+}
+
+// StatsSummaryDTO is the body of GET /api/v1/stats/summary. It is flat, so a
+// JSON datasource can pick each figure by name. The latencies are left out
+// before the first query, the hit rate before the first cache lookup and
+// LastSync before the first sync.
+type StatsSummaryDTO struct {
+	QPS           float64    `json:"qps"`
+	LatencyP50MS  *float64   `json:"latency_p50_ms,omitempty"`
+	LatencyP99MS  *float64   `json:"latency_p99_ms,omitempty"`
+	SourcesLoaded int        `json:"sources_loaded"`
+	SourcesReady  int        `json:"sources_ready"`
+	CacheHitRate  *float64   `json:"cache_hit_rate,omitempty"`
+	LastSync      *time.Time `json:"last_sync,omitempty"`
+}
+
+// GeocodeResponseDTO is the body of GET /api/v1/geocode. Query is the point
+// query at the best match, present with ?query=true when there is a match.
+type GeocodeResponseDTO struct {
+	Q       string             `json:"q"`
+	Results []GeocodeResultDTO `json:"results"`
+	Count   int                `json:"count"`
+	License *LicenseDTO        `json:"license,omitempty"`
+	Query   *QueryResponseDTO  `json:"query,omitempty"`
+}
+
+// GeocodeResultDTO is one place an address resolved to, in WGS84.
+type GeocodeResultDTO struct {
+	Label string     `json:"label"`
+	Lon   float64    `json:"lon"`
+	Lat   float64    `json:"lat"`
+	BBox  *ExtentDTO `json:"bbox,omitempty"`
+}
+
+// STACCatalogDTO is the body of GET /stac, a STAC Catalog linking a
+// collection per source.
+type STACCatalogDTO struct {
+	Type        string        `json:"type"`
+	STACVersion string        `json:"stac_version"`
+	ID          string        `json:"id"`
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description"`
+	Links       []STACLinkDTO `json:"links"`
+}
+
+// STACCollectionDTO is the body of GET /stac/collections/{sourceId}, a STAC
+// Collection describing a source.
+type STACCollectionDTO struct {
+	Type        string            `json:"type"`
+	STACVersion string            `json:"stac_version"`
+	ID          string            `json:"id"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description"`
+	Keywords    []string          `json:"keywords,omitempty"`
+	License     string            `json:"license"`
+	Providers   []STACProviderDTO `json:"providers,omitempty"`
+	Extent      STACExtentDTO     `json:"extent"`
+	Links       []STACLinkDTO     `json:"links"`
+}
+
+// STACLinkDTO is a link of a STAC catalog or collection.
+type STACLinkDTO struct {
+	Rel   string `json:"rel"`
+	Href  string `json:"href"`
+	Type  string `json:"type,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+// STACProviderDTO is an organization that produced a collection's data.
+type STACProviderDTO struct {
+	Name  string   `json:"name"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// STACExtentDTO is the spatial (WGS84) and temporal extent of a collection.
+type STACExtentDTO struct {
+	Spatial struct {
+		BBox [][]float64 `json:"bbox"`
+	} `json:"spatial"`
+	Temporal struct {
+		Interval [][]*time.Time `json:"interval"`
+	} `json:"temporal"`
+}
+
+// SourceDTO is the body of GET /api/v1/sources/{sourceId}.
+type SourceDTO struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Path        string       `json:"path"`
+	Size        int64        `json:"size"`
+	LayerCount  int          `json:"layer_count"`
+	Indexed     bool         `json:"indexed"`
+	Ready       bool         `json:"ready"`
+	LoadedAt    time.Time    `json:"loaded_at"`
+	LastQueried time.Time    `json:"last_queried"`
+	License     *LicenseDTO  `json:"license,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Keywords    []string     `json:"keywords,omitempty"`
+	Title       string       `json:"title,omitempty"`
+	Creator     string       `json:"creator,omitempty"`
+	CreatedAt   time.Time    `json:"created_at,omitzero"`
+	UpdatedAt   time.Time    `json:"updated_at,omitzero"`
+	Constraints []string     `json:"constraints,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	TileSets    []TileSetDTO `json:"tile_sets,omitempty"`
+}
+
+// TileSetDTO is a tile pyramid of a source.
+type TileSetDTO struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	SRID        int        `json:"srid"`
+	MinZoom     int        `json:"min_zoom"`
+	MaxZoom     int        `json:"max_zoom"`
+	Tiles       string     `json:"tiles"`
+	Extent      *ExtentDTO `json:"extent,omitempty"`
+}
+
+// LayerListDTO is the body of GET /api/v1/sources/{sourceId}/layers.
+type LayerListDTO struct {
+	SourceID string     `json:"source_id"`
+	Layers   []LayerDTO `json:"layers"`
+	Count    int        `json:"count"`
+}
+
+// LayerDTO is a feature layer of a source.
+type LayerDTO struct {
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	GeometryType   string     `json:"geometry_type"`
+	GeometryColumn string     `json:"geometry_column"`
+	SRID           int        `json:"srid"`
+	HasIndex       bool       `json:"has_index"`
+	FeatureCount   int64      `json:"feature_count"`
+	Extent         *ExtentDTO `json:"extent,omitempty"`
+}
+
+// ExtentDTO is a bounding box in the SRID of its layer or tile set.
+type ExtentDTO struct {
+	MinX float64 `json:"min_x"`
+	MinY float64 `json:"min_y"`
+	MaxX float64 `json:"max_x"`
+	MaxY float64 `json:"max_y"`
+}
+
+// ProblemDTO is an error response as RFC 7807 problem details
+// (application/problem+json).
+type ProblemDTO struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Extent is where data is, for a point outside it (strict extent mode).
+	Extent *DataExtentDTO `json:"extent,omitempty"`
+	// RetryAfter is the seconds until a rate-limited call is accepted again,
+	// as in the Retry-After header.
+	RetryAfter int `json:"retry_after,omitempty"`
+}
+
+// ErrorDTO is an error response in the legacy envelope
+// (server.error_format: legacy).
+type ErrorDTO struct {
+	Error      string         `json:"error"`
+	Message    string         `json:"message"`
+	Extent     *DataExtentDTO `json:"extent,omitempty"`
+	RetryAfter int            `json:"retry_after,omitempty"`
+}
+
+// DataExtentDTO is the extent of all loaded data with its SRID.
+type DataExtentDTO struct {
+	ExtentDTO
+	SRID int `json:"srid"`
+}
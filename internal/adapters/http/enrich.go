@@ -27,14 +27,15 @@ func (s *Server) wgs84OrLog(r *http.Request, coord domain.Coordinate) (domain.Co
 	}
 }
 
-// attachGazetteer adds the best-effort gazetteer block to a /query response for the
-// (already WGS84) coordinate. Enrichment is ON by default when the feature is wired;
-// a client opts out with with-gazetteer=0 (false/no/off). A failure is logged and
-// the block omitted so it never breaks the core query result. A client-aborted
-// request (context.Canceled) is expected and logged at Debug, not Warn.
-func (s *Server) attachGazetteer(r *http.Request, wgs domain.Coordinate, out map[string]interface{}) {
+// gazetteerEnrichment returns the best-effort gazetteer block of a /query
+// response for the (already WGS84) coordinate. Enrichment is ON by default when
+// the feature is wired; a client opts out with with-gazetteer=0 (false/no/off).
+// A failure is logged and nil returned so it never breaks the core query
+// result. A client-aborted request (context.Canceled) is expected and logged at
+// Debug, not Warn.
+func (s *Server) gazetteerEnrichment(r *http.Request, wgs domain.Coordinate) map[string]interface{} {
 	if s.gazetteer == nil || !gazetteerEnrichmentRequested(r) {
-		return
+		return nil
 	}
 	g, err := s.gazetteerSections(r.Context(), wgs)
 	if err != nil {
@@ -43,7 +44,7 @@ func (s *Server) attachGazetteer(r *http.Request, wgs domain.Coordinate, out map
 		} else {
 			s.logger.Warn("gazetteer enrichment failed", "error", err)
 		}
-		return
+		return nil
 	}
-	out["gazetteer"] = g
+	return g
 }
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+)
+
+// Event Grid event types the webhook handles (Event Grid schema).
+const (
+	eventGridValidation  = "Microsoft.EventGrid.SubscriptionValidationEvent"
+	eventGridBlobCreated = "Microsoft.Storage.BlobCreated"
+	eventGridBlobDeleted = "Microsoft.Storage.BlobDeleted"
+)
+
+// maxEventGridBody caps a delivery; Event Grid batches stay below 1 MB.
+const maxEventGridBody = 1 << 20
+
+// eventGridEvent is the part of an Event Grid schema event the webhook reads.
+type eventGridEvent struct {
+	EventType string `json:"eventType"`
+	// Subject is "/blobServices/default/containers/<container>/blobs/<name>"
+	// for blob events.
+	Subject string `json:"subject"`
+	Data    struct {
+		ValidationCode string `json:"validationCode"`
+	} `json:"data"`
+}
+
+// handleSyncEvents is the Azure Event Grid webhook: it answers the
+// subscription validation handshake and turns BlobCreated/BlobDeleted events
+// into an incremental sync. Event Grid retries a delivery that is not
+// answered with 2xx, so a failed sync returns 500 and maintenance mode 503.
+func (s *Server) handleSyncEvents(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if s.syncEventsToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.syncEventsToken)) != 1 {
+		s.writeError(w, r, http.StatusUnauthorized, "Missing or invalid token")
+		return
+	}
+
+	var events []eventGridEvent
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEventGridBody)).Decode(&events); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, "Body must be an array of Event Grid events")
+		return
+	}
+
+	changes := make([]input.ObjectChange, 0, len(events))
+	for _, e := range events {
+		switch e.EventType {
+		case eventGridValidation:
+			s.writeJSON(w, http.StatusOK, map[string]string{"validationResponse": e.Data.ValidationCode})
+			return
+		case eventGridBlobCreated, eventGridBlobDeleted:
+			if c, ok := blobChange(e.Subject); ok {
+				c.Deleted = e.EventType == eventGridBlobDeleted
+				changes = append(changes, c)
+			}
+		}
+	}
+
+	ctx := domain.WithActor(r.Context(), "eventgrid")
+	result, err := s.changeSyncer.SyncChanges(ctx, changes)
+	if err != nil {
+		if errors.Is(err, domain.ErrMaintenance) {
+			s.writeError(w, r, http.StatusServiceUnavailable, "Maintenance mode is on; sync is blocked")
+			return
+		}
+		s.logger.Error("event sync failed", "error", err)
+		s.writeError(w, r, http.StatusInternalServerError, "Sync failed")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// blobChange parses a blob event subject into the change it reports.
+func blobChange(subject string) (input.ObjectChange, bool) {
+	rest, ok := strings.CutPrefix(subject, "/blobServices/default/containers/")
+	if !ok {
+		return input.ObjectChange{}, false
+	}
+	container, name, ok := strings.Cut(rest, "/blobs/")
+	if !ok || container == "" || name == "" {
+		return input.ObjectChange{}, false
+	}
+	return input.ObjectChange{Container: container, Name: name}, true
+}
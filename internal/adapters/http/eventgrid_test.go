@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+)
+
+// recordingChangeSyncer records the changes it is asked to apply.
+type recordingChangeSyncer struct {
+	changes []input.ObjectChange
+	err     error
+}
+
+func (r *recordingChangeSyncer) SyncChanges(_ context.Context, changes []input.ObjectChange) (input.SyncResult, error) {
+	r.changes = append(r.changes, changes...)
+	return input.SyncResult{SourcesAdded: len(changes)}, r.err
+}
+
+// stubSyncer records the selection it is triggered with and answers err.
+type stubSyncer struct {
+	sel input.SyncSelection
+	err error
+}
+
+func (s *stubSyncer) TriggerSync(_ context.Context, sel input.SyncSelection) (input.SyncResult, error) {
+	s.sel = sel
+	return input.SyncResult{}, s.err
+}
+
+func TestSyncSelection(t *testing.T) {
+	syncer := &stubSyncer{}
+	srv := NewServer(config.ServerConfig{Host: "localhost", Port: 8080}, nil, nil, nil, syncer,
+		slog.New(slog.NewTextHandler(io.Discard, nil)), false, ServerOptions{})
+	post := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/sync", strings.NewReader(body)))
+		return rr
+	}
+
+	if rr := post(""); rr.Code != http.StatusOK || !syncer.sel.IsZero() {
+		t.Errorf("no body: %d, selection %+v; want a full sync", rr.Code, syncer.sel)
+	}
+	if rr := post(`{"sources":["parcels"],"prefixes":["prod/2024/"]}`); rr.Code != http.StatusOK ||
+		len(syncer.sel.SourceIDs) != 1 || syncer.sel.SourceIDs[0] != "parcels" || syncer.sel.Prefixes[0] != "prod/2024/" {
+		t.Errorf("selection: %d, %+v", rr.Code, syncer.sel)
+	}
+	if rr := post(`{"prefixes":[""]}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("empty prefix: status = %d, want 400", rr.Code)
+	}
+	if rr := post(`{"sources":`); rr.Code != http.StatusBadRequest {
+		t.Errorf("bad JSON: status = %d, want 400", rr.Code)
+	}
+	syncer.err = fmt.Errorf("%w: nope", domain.ErrSourceNotFound)
+	if rr := post(`{"sources":["nope"]}`); rr.Code != http.StatusNotFound {
+		t.Errorf("unknown source: status = %d, want 404", rr.Code)
+	}
+	syncer.err = fmt.Errorf("leader election: %w", domain.ErrUnsupported)
+	if rr := post(`{"sources":["parcels"]}`); rr.Code != http.StatusConflict {
+		t.Errorf("leader election: status = %d, want 409", rr.Code)
+	}
+}
+
+func TestSyncRateLimited(t *testing.T) {
+	srv := NewServer(config.ServerConfig{Host: "localhost", Port: 8080}, nil, nil, nil,
+		&stubSyncer{err: &domain.RateLimitError{RetryAfter: 11200 * time.Millisecond}},
+		slog.New(slog.NewTextHandler(io.Discard, nil)), false, ServerOptions{})
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/sync", nil))
+
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "12" {
+		t.Errorf("Retry-After = %q, want 12 (rounded up)", got)
+	}
+	var problem ProblemDTO
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil || problem.RetryAfter != 12 {
+		t.Errorf("body = %s, want retry_after 12", rr.Body.String())
+	}
+}
+
+func TestSyncEventsWebhook(t *testing.T) {
+	syncer := &recordingChangeSyncer{}
+	srv := NewServer(config.ServerConfig{Host: "localhost", Port: 8080}, nil, nil, nil, nil,
+		slog.New(slog.NewTextHandler(io.Discard, nil)), false,
+		ServerOptions{ChangeSyncer: syncer, SyncEventsToken: "s3cret"})
+	post := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/events?token="+token, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := post("wrong", "[]"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", rr.Code)
+	}
+
+	rr := post("s3cret", `[{"eventType":"Microsoft.EventGrid.SubscriptionValidationEvent","data":{"validationCode":"abc-123"}}]`)
+	var validation map[string]string
+	if err := json.Unmarshal(rr.Body.Bytes(), &validation); err != nil || validation["validationResponse"] != "abc-123" {
+		t.Errorf("validation handshake: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = post("s3cret", `[
+		{"eventType":"Microsoft.Storage.BlobCreated","subject":"/blobServices/default/containers/geodata/blobs/prod/parcels.gpkg"},
+		{"eventType":"Microsoft.Storage.BlobDeleted","subject":"/blobServices/default/containers/geodata/blobs/prod/roads.gpkg"},
+		{"eventType":"Microsoft.Storage.BlobTierChanged","subject":"/blobServices/default/containers/geodata/blobs/prod/x.gpkg"}
+	]`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	want := []input.ObjectChange{
+		{Container: "geodata", Name: "prod/parcels.gpkg"},
+		{Container: "geodata", Name: "prod/roads.gpkg", Deleted: true},
+	}
+	if len(syncer.changes) != 2 || syncer.changes[0] != want[0] || syncer.changes[1] != want[1] {
+		t.Errorf("changes = %+v, want %+v", syncer.changes, want)
+	}
+
+	if rr := post("s3cret", `{"not":"an array"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("bad body: status = %d, want 400", rr.Code)
+	}
+	syncer.err = domain.ErrMaintenance
+	if rr := post("s3cret", "[]"); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("maintenance: status = %d, want 503", rr.Code)
+	}
+	syncer.err = errors.New("download failed")
+	if rr := post("s3cret", "[]"); rr.Code != http.StatusInternalServerError {
+		t.Errorf("failed sync: status = %d, want 500 so Event Grid retries", rr.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// startExplain returns the context to run a point query with. For
+// ?explain=true it records the execution into the returned QueryExplain; the
+// SQL and plans it reveals are for operators only, so the request must carry
+// the admin token. ok is false when the response was already written: explain
+// requested without the admin routes enabled (403) or without the token (401),
+// or with a format other than json (400).
+func (s *Server) startExplain(w http.ResponseWriter, r *http.Request, params *QueryParams) (context.Context, *domain.QueryExplain, bool) {
+	if !params.Explain {
+		return r.Context(), nil, true
+	}
+	if !s.config.Admin.Enabled {
+		s.writeError(w, r, http.StatusForbidden, "explain requires server.admin.enabled")
+		return nil, nil, false
+	}
+	if !s.adminAuthorized(r) {
+		s.writeAdminUnauthorized(w, r)
+		return nil, nil, false
+	}
+	if params.Format != formatJSON {
+		s.writeError(w, r, http.StatusBadRequest, "explain is only available with format=json")
+		return nil, nil, false
+	}
+	// The SQL differs per deployment and the timings per call.
+	w.Header().Set("Cache-Control", "no-store")
+	explain := &domain.QueryExplain{}
+	return domain.WithExplain(r.Context(), explain), explain, true
+}
+
+// formatExplain renders the recorded execution; nil stays nil.
+func formatExplain(e *domain.QueryExplain) *ExplainDTO {
+	if e == nil {
+		return nil
+	}
+	out := &ExplainDTO{Layers: []LayerExplainDTO{}}
+	for _, l := range e.Layers() {
+		dto := LayerExplainDTO{
+			SourceID:     l.SourceID,
+			Layer:        l.Layer,
+			Skipped:      l.Skipped,
+			SQL:          l.SQL,
+			Plan:         l.Plan,
+			RTreeUsed:    l.RTree,
+			RowsExamined: l.RowsExamined,
+			RowsReturned: l.RowsReturned,
+			Features:     l.Features,
+			TimingsMS: ExplainTimingsDTO{
+				Transform: durationMS(l.TransformTime),
+				SQL:       durationMS(l.SQLTime),
+				Total:     durationMS(l.Total),
+			},
+		}
+		if t := l.Transform; t != nil {
+			dto.Transform = &TransformExplainDTO{FromSRID: t.FromSRID, ToSRID: t.ToSRID, X: t.X, Y: t.Y}
+		}
+		out.Layers = append(out.Layers, dto)
+	}
+	return out
+}
+
+// durationMS is d in milliseconds, to the microsecond.
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
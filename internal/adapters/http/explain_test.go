@@ -0,0 +1,93 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func explainRequest(srv *Server, target, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestQueryExplainIsAdminGated(t *testing.T) {
+	const query = "/api/v1/query?lon=10&lat=50&explain=true"
+	tests := []struct {
+		name       string
+		admin      bool
+		target     string
+		token      string
+		wantStatus int
+	}{
+		{name: "admin routes off", admin: false, target: query, token: "s3cret", wantStatus: http.StatusForbidden},
+		{name: "no token", admin: true, target: query, wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", admin: true, target: query, token: "wrong", wantStatus: http.StatusUnauthorized},
+		{name: "csv", admin: true, target: query + "&format=csv", token: "s3cret", wantStatus: http.StatusBadRequest},
+		{name: "invalid value", admin: true, target: "/api/v1/query?lon=10&lat=50&explain=maybe", token: "s3cret", wantStatus: http.StatusBadRequest},
+		{name: "explained", admin: true, target: query, token: "s3cret", wantStatus: http.StatusOK},
+		{name: "not requested", admin: false, target: "/api/v1/query?lon=10&lat=50&explain=false", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := explainRequest(newAdminTestServer(t, tt.admin), tt.target, tt.token)
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if rr.Code != http.StatusOK {
+				return
+			}
+			var body struct {
+				Explain *ExplainDTO `json:"explain"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if explained := tt.token != ""; (body.Explain != nil) != explained {
+				t.Errorf("explain block present = %v, want %v", body.Explain != nil, explained)
+			}
+		})
+	}
+}
+
+func TestFormatExplain(t *testing.T) {
+	if formatExplain(nil) != nil {
+		t.Error("formatExplain(nil) != nil")
+	}
+	e := &domain.QueryExplain{}
+	l := e.Layer("pkg", "districts")
+	l.SQL = "SELECT 1"
+	l.Plan = []string{"SCAN r VIRTUAL TABLE INDEX 2:D0B1D2B3"}
+	l.RTree = true
+	l.RowsExamined, l.RowsReturned, l.Features = 3, 2, 1
+	l.Transform = &domain.TransformExplain{FromSRID: 4326, ToSRID: 25832, X: 500000, Y: 5500000}
+	l.TransformTime, l.SQLTime, l.Total = 250*time.Microsecond, 1500*time.Microsecond, 2*time.Millisecond
+	e.Layer("pkg", "rivers").Skipped = "coordinate not transformable to the layer SRID"
+
+	got := formatExplain(e)
+	if len(got.Layers) != 2 {
+		t.Fatalf("layers = %d, want 2", len(got.Layers))
+	}
+	d := got.Layers[0]
+	if d.SourceID != "pkg" || d.Layer != "districts" || !d.RTreeUsed || d.RowsExamined != 3 || d.RowsReturned != 2 || d.Features != 1 {
+		t.Errorf("layer = %+v", d)
+	}
+	if d.TimingsMS != (ExplainTimingsDTO{Transform: 0.25, SQL: 1.5, Total: 2}) {
+		t.Errorf("timings = %+v", d.TimingsMS)
+	}
+	if d.Transform == nil || d.Transform.ToSRID != 25832 {
+		t.Errorf("transform = %+v", d.Transform)
+	}
+	if got.Layers[1].Skipped == "" {
+		t.Error("skipped layer lost its reason")
+	}
+}
@@ -0,0 +1,248 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// Response formats of the point-query endpoints, selected with ?format=. JSON
+// is the nested default; csv and ndjson flatten the response to one row per
+// feature for spreadsheets and stream processors; geojson is a
+// FeatureCollection for GIS clients; xml serves legacy systems that cannot
+// consume JSON (schema: query-response.xsd).
+const (
+	formatJSON    = "json"
+	formatCSV     = "csv"
+	formatNDJSON  = "ndjson"
+	formatGeoJSON = "geojson"
+	formatXML     = "xml"
+)
+
+// geoJSONFlushEvery is the number of features after which a streamed
+// FeatureCollection is flushed to the client.
+const geoJSONFlushEvery = 256
+
+// headerIncomplete marks an export that the query deadline cut short; csv,
+// ndjson and geojson have no envelope to carry the JSON "incomplete" flag (xml also
+// carries it as an attribute).
+const headerIncomplete = "X-Ortus-Incomplete"
+
+// csvFixedColumns lead every CSV row; the feature properties follow as columns.
+var csvFixedColumns = []string{"source_id", "layer", "feature_id"}
+
+// parseResponseFormat validates a ?format= value; empty selects JSON.
+func parseResponseFormat(s string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(s)); f {
+	case "":
+		return formatJSON, nil
+	case formatJSON, formatCSV, formatNDJSON, formatGeoJSON, formatXML:
+		return f, nil
+	default:
+		return "", errors.New("invalid format parameter (json, csv, ndjson, geojson, xml)")
+	}
+}
+
+// writeQueryExport writes a query response in a non-JSON format: flat feature
+// rows (csv, ndjson), a GeoJSON FeatureCollection or the XML document. The wgs84 and gazetteer blocks of
+// the JSON response are not part of any export.
+func (s *Server) writeQueryExport(w http.ResponseWriter, r *http.Request, format string, resp *domain.QueryResponse) {
+	if resp.Incomplete {
+		w.Header().Set(headerIncomplete, "true")
+	}
+	switch format {
+	case formatCSV:
+		s.writeQueryCSV(w, resp)
+	case formatXML:
+		s.writeQueryXML(w, resp)
+	case formatGeoJSON:
+		s.writeQueryGeoJSON(w, r, resp)
+	default:
+		s.writeQueryNDJSON(w, r, resp)
+	}
+}
+
+// writeQueryCSV writes one row per feature. The property columns are the
+// sorted union of all returned property names, so rows from layers with
+// different schemas line up; a feature lacking a property leaves its cell
+// empty. A geometry_wkt column closes the row when geometries are enabled.
+func (s *Server) writeQueryCSV(w http.ResponseWriter, resp *domain.QueryResponse) {
+	propSet := map[string]struct{}{}
+	for i := range resp.Results {
+		for j := range resp.Results[i].Features {
+			for k := range resp.Results[i].Features[j].Properties {
+				propSet[k] = struct{}{}
+			}
+		}
+	}
+	props := make([]string, 0, len(propSet))
+	for k := range propSet {
+		props = append(props, k)
+	}
+	sort.Strings(props)
+
+	header := append(append([]string{}, csvFixedColumns...), props...)
+	if s.withGeometry {
+		header = append(header, "geometry_wkt")
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	cw := csv.NewWriter(w)
+	_ = cw.Write(header)
+	for i := range resp.Results {
+		res := &resp.Results[i]
+		for j := range res.Features {
+			f := &res.Features[j]
+			row := make([]string, 0, len(header))
+			row = append(row, res.SourceID, f.LayerName, strconv.FormatInt(f.ID, 10))
+			for _, k := range props {
+				row = append(row, csvValue(f.Properties[k]))
+			}
+			if s.withGeometry {
+				row = append(row, f.Geometry.WKT)
+			}
+			if err := cw.Write(row); err != nil {
+				s.logger.Debug("csv export write failed", "error", err)
+				return
+			}
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		s.logger.Debug("csv export write failed", "error", err)
+	}
+}
+
+// csvValue renders a property value as a CSV cell. Floats use the shortest
+// exact decimal form rather than %v's exponent notation, and blobs are hex.
+func csvValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []byte:
+		return hex.EncodeToString(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// writeQueryNDJSON writes one JSON object per feature and line, flushing per
+// line like the batch stream so a consumer can process features as they come.
+func (s *Server) writeQueryNDJSON(w http.ResponseWriter, r *http.Request, resp *domain.QueryResponse) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	for i := range resp.Results {
+		res := &resp.Results[i]
+		for j := range res.Features {
+			if r.Context().Err() != nil {
+				return // client disconnected
+			}
+			f := &res.Features[j]
+			line := map[string]interface{}{
+				"source_id":   res.SourceID,
+				"source_name": res.SourceName,
+				"layer":       f.LayerName,
+				"id":          f.ID,
+				"properties":  jsonSafeProperties(f.Properties),
+			}
+			if s.withGeometry && f.Geometry.WKT != "" {
+				line["geometry"] = formatGeometry(&f.Geometry)
+			}
+			if err := enc.Encode(line); err != nil {
+				s.logger.Debug("ndjson export write failed", "error", err)
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// geoJSONFeature is a feature of the geojson format. source_id and layer are
+// foreign members (RFC 7946, section 6.1), so the properties stay the
+// feature's own.
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         int64                  `json:"id"`
+	Geometry   json.RawMessage        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+	SourceID   string                 `json:"source_id"`
+	Layer      string                 `json:"layer"`
+}
+
+// writeQueryGeoJSON writes the features as a GeoJSON FeatureCollection. The
+// collection is encoded feature by feature and flushed every
+// geoJSONFlushEvery features rather than encoded as a whole, so resp is not
+// buffered a second time as JSON; resp itself is already complete, only the
+// encoding streams. Geometries are null when they are disabled; their
+// coordinates are in the layer SRID, as with geometry_format=geojson.
+func (s *Server) writeQueryGeoJSON(w http.ResponseWriter, r *http.Request, resp *domain.QueryResponse) {
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if _, err := io.WriteString(w, `{"type":"FeatureCollection","features":[`); err != nil {
+		s.logger.Debug("geojson export write failed", "error", err)
+		return
+	}
+	n := 0
+	for i := range resp.Results {
+		res := &resp.Results[i]
+		for j := range res.Features {
+			if r.Context().Err() != nil {
+				return // client disconnected
+			}
+			if err := s.writeGeoJSONFeature(w, res.SourceID, &res.Features[j], n > 0); err != nil {
+				s.logger.Debug("geojson export write failed", "error", err)
+				return
+			}
+			n++
+			if flusher != nil && n%geoJSONFlushEvery == 0 {
+				flusher.Flush()
+			}
+		}
+	}
+	_, _ = io.WriteString(w, "]}\n")
+}
+
+// writeGeoJSONFeature writes one feature of a FeatureCollection, preceded by
+// the separating comma unless it is the first.
+func (s *Server) writeGeoJSONFeature(w io.Writer, sourceID string, f *domain.Feature, comma bool) error {
+	out := geoJSONFeature{
+		Type:       "Feature",
+		ID:         f.ID,
+		Properties: jsonSafeProperties(f.Properties),
+		SourceID:   sourceID,
+		Layer:      f.LayerName,
+	}
+	if s.withGeometry && f.Geometry.GeoJSON != "" && json.Valid([]byte(f.Geometry.GeoJSON)) {
+		out.Geometry = json.RawMessage(f.Geometry.GeoJSON)
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	if comma {
+		b = append([]byte{','}, b...)
+	}
+	_, err = w.Write(b)
+	return err
+}
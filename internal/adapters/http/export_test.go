@@ -0,0 +1,187 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func TestParseResponseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", formatJSON, false},
+		{"json", formatJSON, false},
+		{"CSV", formatCSV, false},
+		{"ndjson", formatNDJSON, false},
+		{"GeoJSON", formatGeoJSON, false},
+		{"xml", formatXML, false},
+		{"xlsx", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseResponseFormat(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseResponseFormat(%q) = %q, %v; want %q, err %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCSVValue(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{nil, ""},
+		{"Mitte", "Mitte"},
+		{int64(384172), "384172"},
+		{1234567.5, "1234567.5"},
+		{float32(0.25), "0.25"},
+		{true, "true"},
+		{[]byte{0xca, 0xfe}, "cafe"},
+	}
+	for _, tt := range tests {
+		if got := csvValue(tt.in); got != tt.want {
+			t.Errorf("csvValue(%#v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestWriteQueryCSVMixedSchemas: layers with different property sets share one
+// header (sorted union), missing cells stay empty, and an incomplete response
+// is flagged in a header since CSV has no envelope.
+func TestWriteQueryCSVMixedSchemas(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	resp := &domain.QueryResponse{
+		Incomplete: true,
+		Results: []domain.QueryResult{
+			{SourceID: "admin", Features: []domain.Feature{
+				{ID: 1, LayerName: "districts", Properties: map[string]interface{}{"name": "Mitte, Berlin"}},
+			}},
+			{SourceID: "zones", Features: []domain.Feature{
+				{ID: 7, LayerName: "tz", Properties: map[string]interface{}{"tzid": "Europe/Berlin", "offset": int64(1)}},
+			}},
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	srv.writeQueryExport(rec, httptest.NewRequest(http.MethodGet, "/api/v1/query", nil), formatCSV, resp)
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", ct)
+	}
+	if rec.Header().Get(headerIncomplete) != "true" {
+		t.Errorf("%s header missing for an incomplete response", headerIncomplete)
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	want := [][]string{
+		{"source_id", "layer", "feature_id", "name", "offset", "tzid"},
+		{"admin", "districts", "1", "Mitte, Berlin", "", ""},
+		{"zones", "tz", "7", "", "1", "Europe/Berlin"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %v, want %v", rows, want)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %v, want %v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestWriteQueryNDJSONEmpty(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	rec := httptest.NewRecorder()
+	srv.writeQueryExport(rec, httptest.NewRequest(http.MethodGet, "/api/v1/query", nil), formatNDJSON, &domain.QueryResponse{})
+
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("empty export = %d %q, want 200 with no lines", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get(headerIncomplete) != "" {
+		t.Errorf("complete response carries %s", headerIncomplete)
+	}
+}
+
+// TestWriteQueryGeoJSON: the streamed collection is one valid
+// FeatureCollection across sources, with null geometries where a feature has
+// none and the source and layer as foreign members.
+func TestWriteQueryGeoJSON(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	srv.withGeometry = true
+	resp := &domain.QueryResponse{
+		Results: []domain.QueryResult{
+			{SourceID: "admin", Features: []domain.Feature{
+				{ID: 1, LayerName: "districts", Properties: map[string]interface{}{"name": "Mitte"},
+					Geometry: domain.Geometry{GeoJSON: `{"type":"Point","coordinates":[13.4,52.5]}`}},
+				{ID: 2, LayerName: "districts", Properties: map[string]interface{}{"name": "Pankow"}},
+			}},
+			{SourceID: "zones", Features: []domain.Feature{
+				{ID: 7, LayerName: "tz", Properties: map[string]interface{}{"tzid": "Europe/Berlin"}},
+			}},
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	srv.writeQueryExport(rec, httptest.NewRequest(http.MethodGet, "/api/v1/query", nil), formatGeoJSON, resp)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/geo+json" {
+		t.Errorf("Content-Type = %q, want application/geo+json", ct)
+	}
+	var fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Type       string                 `json:"type"`
+			ID         int64                  `json:"id"`
+			Geometry   json.RawMessage        `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+			SourceID   string                 `json:"source_id"`
+			Layer      string                 `json:"layer"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &fc); err != nil {
+		t.Fatalf("decode: %v; body %s", err, rec.Body.String())
+	}
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 3 {
+		t.Fatalf("collection = %s", rec.Body.String())
+	}
+	if f := fc.Features[0]; f.Type != "Feature" || f.ID != 1 || f.SourceID != "admin" || f.Layer != "districts" || f.Properties["name"] != "Mitte" {
+		t.Errorf("feature 0 = %+v", f)
+	}
+	if string(fc.Features[0].Geometry) != `{"type":"Point","coordinates":[13.4,52.5]}` {
+		t.Errorf("geometry = %s", fc.Features[0].Geometry)
+	}
+	if string(fc.Features[1].Geometry) != "null" {
+		t.Errorf("missing geometry = %s, want null", fc.Features[1].Geometry)
+	}
+	if fc.Features[2].SourceID != "zones" {
+		t.Errorf("feature 2 source = %q, want zones", fc.Features[2].SourceID)
+	}
+}
+
+func TestWriteQueryGeoJSONEmpty(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	rec := httptest.NewRecorder()
+	srv.writeQueryExport(rec, httptest.NewRequest(http.MethodGet, "/api/v1/query", nil), formatGeoJSON, &domain.QueryResponse{})
+
+	if got := strings.TrimSpace(rec.Body.String()); got != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("empty collection = %s", got)
+	}
+}
+
+func TestQueryInvalidFormat(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	rec := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/query?lon=10&lat=50&format=xlsx", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
@@ -4,16 +4,19 @@ import (
 	"html"
 	"net/http"
 	"strings"
+
+	"github.com/jobrunner/ortus/internal/config"
 )
 
 // frontendHTML is the embedded HTML for the coordinate query frontend.
 // Mobile-first, responsive design with pure CSS.
 const frontendHTML = `<!DOCTYPE html>
-<html lang="de">
+<html lang="__ORTUS_LANG__">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Ortus - Koordinatenabfrage</title>
+    <meta name="color-scheme" content="light dark">
+    <title>[[title]]</title>
     <style>
         :root {
             --primary: #2563eb;
@@ -26,10 +29,43 @@ const frontendHTML = `<!DOCTYPE html>
             --text: #1e293b;
             --text-muted: #64748b;
             --border: #e2e8f0;
+            --hover: #f1f5f9;
+            --badge-bg: #dbeafe;
+            --badge-success-bg: #dcfce7;
+            --error-bg: #fef2f2;
+            --error-border: #fecaca;
+            --focus-ring: rgba(37, 99, 235, 0.1);
             --radius: 8px;
             --shadow: 0 1px 3px rgba(0,0,0,0.1);
+            color-scheme: light dark;
+        }
+
+        /* Dark mode follows the OS/browser setting. */
+        @media (prefers-color-scheme: dark) {
+            :root {
+                --primary: #60a5fa;
+                --primary-dark: #3b82f6;
+                --success: #4ade80;
+                --error: #f87171;
+                --warning: #fbbf24;
+                --bg: #0f172a;
+                --card: #1e293b;
+                --text: #e2e8f0;
+                --text-muted: #94a3b8;
+                --border: #334155;
+                --hover: #273449;
+                --badge-bg: #1e3a5f;
+                --badge-success-bg: #14532d;
+                --error-bg: #450a0a;
+                --error-border: #7f1d1d;
+                --focus-ring: rgba(96, 165, 250, 0.25);
+                --shadow: 0 1px 3px rgba(0,0,0,0.5);
+            }
         }
 
+        /* Deployment branding (frontend.branding), rendered in by the server. */
+__ORTUS_BRANDING_CSS__
+
         * {
             box-sizing: border-box;
             margin: 0;
@@ -112,7 +148,7 @@ const frontendHTML = `<!DOCTYPE html>
         input:focus, select:focus {
             outline: none;
             border-color: var(--primary);
-            box-shadow: 0 0 0 3px rgba(37, 99, 235, 0.1);
+            box-shadow: 0 0 0 3px var(--focus-ring);
         }
 
         input::placeholder {
@@ -200,8 +236,8 @@ const frontendHTML = `<!DOCTYPE html>
         }
 
         .error {
-            background: #fef2f2;
-            border: 1px solid #fecaca;
+            background: var(--error-bg);
+            border: 1px solid var(--error-border);
             color: var(--error);
             padding: 0.75rem 1rem;
             border-radius: var(--radius);
@@ -246,6 +282,112 @@ const frontendHTML = `<!DOCTYPE html>
             color: var(--text-muted);
         }
 
+        .result-actions {
+            display: flex;
+            align-items: center;
+            gap: 0.5rem;
+        }
+
+        .btn-small {
+            width: auto;
+            padding: 0.375rem 0.75rem;
+            font-size: 0.8125rem;
+        }
+
+        .copy-status {
+            font-size: 0.8125rem;
+            color: var(--success);
+        }
+
+        .history-header {
+            display: flex;
+            justify-content: space-between;
+            align-items: center;
+            gap: 0.5rem;
+        }
+
+        .history-list {
+            list-style: none;
+        }
+
+        .history-list li + li {
+            border-top: 1px solid var(--border);
+        }
+
+        .history-list a {
+            display: block;
+            padding: 0.5rem 0;
+            font-family: 'SF Mono', Monaco, monospace;
+            font-size: 0.8125rem;
+            color: var(--primary);
+            text-decoration: none;
+        }
+
+        .history-list a:hover {
+            text-decoration: underline;
+        }
+
+        .picker {
+            margin-bottom: 1rem;
+            font-size: 0.875rem;
+        }
+
+        .picker summary {
+            cursor: pointer;
+            font-weight: 500;
+            min-height: 44px;
+            display: flex;
+            align-items: center;
+            gap: 0.5rem;
+        }
+
+        .picker-summary {
+            color: var(--text-muted);
+            font-weight: normal;
+        }
+
+        .picker-actions {
+            margin-bottom: 0.5rem;
+        }
+
+        .link-btn {
+            background: none;
+            border: none;
+            padding: 0;
+            font: inherit;
+            color: var(--primary);
+            cursor: pointer;
+        }
+
+        .picker-list,
+        .picker-layers ul {
+            list-style: none;
+        }
+
+        .picker-layers {
+            margin-left: 1.75rem;
+            color: var(--text-muted);
+        }
+
+        .picker-layers summary {
+            min-height: 0;
+        }
+
+        .picker label {
+            display: flex;
+            align-items: center;
+            gap: 0.5rem;
+            margin: 0;
+            padding: 0.25rem 0;
+            font-weight: normal;
+            cursor: pointer;
+        }
+
+        .picker input[type="checkbox"] {
+            width: auto;
+            padding: 0;
+        }
+
         .source-card {
             border: 1px solid var(--border);
             border-radius: var(--radius);
@@ -265,7 +407,7 @@ const frontendHTML = `<!DOCTYPE html>
         }
 
         .source-header:hover {
-            background: #f1f5f9;
+            background: var(--hover);
         }
 
         /* Title + meta take the row and may shrink/wrap; the chevron stays pinned
@@ -306,12 +448,12 @@ const frontendHTML = `<!DOCTYPE html>
             font-size: 0.75rem;
             font-weight: 500;
             border-radius: 9999px;
-            background: #dbeafe;
+            background: var(--badge-bg);
             color: var(--primary);
         }
 
         .badge-success {
-            background: #dcfce7;
+            background: var(--badge-success-bg);
             color: var(--success);
         }
 
@@ -396,7 +538,7 @@ const frontendHTML = `<!DOCTYPE html>
         .geometry-preview {
             margin-top: 0.5rem;
             padding: 0.5rem;
-            background: #f8fafc;
+            background: var(--bg);
             border: 1px solid var(--border);
             border-radius: 4px;
             font-family: monospace;
@@ -589,6 +731,21 @@ const frontendHTML = `<!DOCTYPE html>
             text-decoration: underline;
         }
 
+        .footer-lang {
+            margin-top: 0.4rem;
+        }
+
+        .brand-logo {
+            display: block;
+            max-width: 100%;
+            max-height: 48px;
+            margin: 0 auto 0.5rem;
+        }
+
+        .footer-text {
+            margin-bottom: 0.4rem;
+        }
+
         .footer-version {
             margin-top: 0.4rem;
             opacity: 0.65;
@@ -638,15 +795,16 @@ const frontendHTML = `<!DOCTYPE html>
 <body>
     <div class="container">
         <header>
+            __ORTUS_BRANDING_LOGO__
             <h1>Ortus</h1>
-            <p>Point-in-Polygon Abfrage über Datenquellen</p>
+            <p>[[subtitle]]</p>
         </header>
 
         <div class="card">
-            <h2 class="card-title">Koordinaten eingeben</h2>
+            <h2 class="card-title">[[enterCoordinates]]</h2>
             <form id="queryForm">
                 <div class="form-group">
-                    <label for="srid">Koordinatensystem</label>
+                    <label for="srid">[[crs]]</label>
                     <select id="srid" name="srid">
                         <option value="4326">WGS 84 (EPSG:4326) - GPS</option>
                         <option value="3857">Web Mercator (EPSG:3857)</option>
@@ -659,24 +817,33 @@ const frontendHTML = `<!DOCTYPE html>
 
                 <div class="coord-grid" id="coordGrid">
                     <div class="form-group" id="groupY">
-                        <label for="coordY" id="labelY">Breitengrad (Lat)</label>
-                        <input type="text" id="coordY" name="y" placeholder="z.B. 52.52" inputmode="decimal" required>
+                        <label for="coordY" id="labelY">[[labelLat]]</label>
+                        <input type="text" id="coordY" name="y" placeholder="[[example]] 52.52" inputmode="decimal" required>
                     </div>
                     <div class="form-group" id="groupX">
-                        <label for="coordX" id="labelX">Längengrad (Lon)</label>
-                        <input type="text" id="coordX" name="x" placeholder="z.B. 13.405" inputmode="decimal" required>
+                        <label for="coordX" id="labelX">[[labelLon]]</label>
+                        <input type="text" id="coordX" name="x" placeholder="[[example]] 13.405" inputmode="decimal" required>
                     </div>
                 </div>
 
+                <details class="picker" id="picker" hidden>
+                    <summary>[[dataSources]] <span class="picker-summary" id="pickerSummary"></span></summary>
+                    <div class="picker-actions">
+                        <button type="button" class="link-btn" id="pickerAll">[[selectAll]]</button> &middot;
+                        <button type="button" class="link-btn" id="pickerNone">[[selectNone]]</button>
+                    </div>
+                    <ul class="picker-list" id="pickerList"></ul>
+                </details>
+
                 <div class="btn-row">
-                    <button type="submit" class="btn" id="submitBtn">Abfragen</button>
-                    <button type="button" class="btn btn-secondary" id="locationBtn" title="Aktuellen Standort verwenden" aria-label="Aktuellen Standort verwenden">
+                    <button type="submit" class="btn" id="submitBtn">[[submit]]</button>
+                    <button type="button" class="btn btn-secondary" id="locationBtn" title="[[useLocation]]" aria-label="[[useLocation]]">
                         <svg width="20" height="20" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" aria-hidden="true" focusable="false">
                             <circle cx="12" cy="12" r="3"/>
                             <path d="M12 2v4m0 12v4M2 12h4m12 0h4"/>
                         </svg>
                     </button>
-                    <button type="button" class="btn btn-secondary" id="clearBtn">Leeren</button>
+                    <button type="button" class="btn btn-secondary" id="clearBtn">[[clear]]</button>
                 </div>
             </form>
         </div>
@@ -685,30 +852,59 @@ const frontendHTML = `<!DOCTYPE html>
 
         <div class="loading" id="loading" role="status" aria-live="polite">
             <div class="spinner"></div>
-            <p>Abfrage wird ausgeführt...</p>
+            <p>[[loading]]</p>
         </div>
 
         <div id="results">
             <div class="card">
-                <h2 class="card-title">Ergebnisse</h2>
+                <h2 class="card-title">[[results]]</h2>
                 <div class="result-header" role="status" aria-live="polite">
                     <span class="result-coord" id="resultCoord"></span>
                     <span class="result-stats" id="resultStats"></span>
+                    <div class="result-actions">
+                        <button type="button" class="btn btn-secondary btn-small" id="copyLinkBtn">[[copyLink]]</button>
+                        <span class="copy-status" id="copyStatus"></span>
+                    </div>
                 </div>
                 <div id="resultContent"></div>
             </div>
         </div>
 
+        <div class="card" id="history" hidden>
+            <div class="history-header">
+                <h2 class="card-title">[[recentQueries]]</h2>
+                <button type="button" class="btn btn-secondary btn-small" id="clearHistoryBtn">[[clearHistory]]</button>
+            </div>
+            <ul class="history-list" id="historyList"></ul>
+        </div>
+
         <footer>
-            <a href="/docs">API Dokumentation</a> &middot;
+            __ORTUS_BRANDING_FOOTER__
+            <a href="/docs">[[apiDocs]]</a> &middot;
             <a href="/openapi.json">OpenAPI Spec</a> &middot;
             <a href="/health">Health Status</a>
+            <div class="footer-lang">
+                <a href="?lang=de" lang="de" hreflang="de">Deutsch</a> &middot;
+                <a href="?lang=en" lang="en" hreflang="en">English</a>
+            </div>
             <div class="footer-version">ortus __ORTUS_VERSION__</div>
         </footer>
     </div>
 
     <script>
         (function() {
+            // Message catalog of the page language, rendered in by the server.
+            const MSG = __ORTUS_MESSAGES__;
+
+            // t returns message key with {0}, {1}, ... replaced by the further
+            // arguments. An unknown key is returned as is.
+            function t(key) {
+                const args = arguments;
+                return (MSG[key] || key).replace(/\{(\d+)\}/g, function(m, i) {
+                    return String(args[Number(i) + 1]);
+                });
+            }
+
             const form = document.getElementById('queryForm');
             const sridSelect = document.getElementById('srid');
             const coordX = document.getElementById('coordX');
@@ -727,32 +923,50 @@ const frontendHTML = `<!DOCTYPE html>
             const resultCoord = document.getElementById('resultCoord');
             const resultStats = document.getElementById('resultStats');
             const resultContent = document.getElementById('resultContent');
+            const copyLinkBtn = document.getElementById('copyLinkBtn');
+            const copyStatus = document.getElementById('copyStatus');
+            const historyCard = document.getElementById('history');
+            const historyList = document.getElementById('historyList');
+            const clearHistoryBtn = document.getElementById('clearHistoryBtn');
+            const picker = document.getElementById('picker');
+            const pickerSummary = document.getElementById('pickerSummary');
+            const pickerList = document.getElementById('pickerList');
+
+            // Query state lives in the URL hash, so a copied link reproduces the
+            // query: #srid=25832&x=389524&y=5820270&source=<id>, where x/y are the
+            // entered values (lon/lat for WGS84) and source is the expanded result
+            // package; sources lists the picked sources when not all are. Recent
+            // queries are kept in localStorage.
+            const HISTORY_KEY = 'ortus.recentQueries';
+            const HISTORY_MAX = 10;
+            let current = null;       // {srid, x, y, sources} of the displayed results
+            let selectedSource = '';  // source_id of the expanded package, '' = first
 
             // SRID-specific labels and placeholders
             const sridConfig = {
                 '4326': {
-                    xLabel: 'Längengrad (Lon)', yLabel: 'Breitengrad (Lat)',
-                    xPlaceholder: 'z.B. 13.405', yPlaceholder: 'z.B. 52.52'
+                    xLabel: t('labelLon'), yLabel: t('labelLat'),
+                    xPlaceholder: t('example') + ' 13.405', yPlaceholder: t('example') + ' 52.52'
                 },
                 '3857': {
-                    xLabel: 'X (Meter)', yLabel: 'Y (Meter)',
-                    xPlaceholder: 'z.B. 1492273', yPlaceholder: 'z.B. 6894026'
+                    xLabel: t('labelXMeters'), yLabel: t('labelYMeters'),
+                    xPlaceholder: t('example') + ' 1492273', yPlaceholder: t('example') + ' 6894026'
                 },
                 '25832': {
-                    xLabel: 'Rechtswert (E)', yLabel: 'Hochwert (N)',
-                    xPlaceholder: 'z.B. 389524', yPlaceholder: 'z.B. 5820270'
+                    xLabel: t('labelEastingUTM'), yLabel: t('labelNorthingUTM'),
+                    xPlaceholder: t('example') + ' 389524', yPlaceholder: t('example') + ' 5820270'
                 },
                 '25833': {
-                    xLabel: 'Rechtswert (E)', yLabel: 'Hochwert (N)',
-                    xPlaceholder: 'z.B. 389524', yPlaceholder: 'z.B. 5820270'
+                    xLabel: t('labelEastingUTM'), yLabel: t('labelNorthingUTM'),
+                    xPlaceholder: t('example') + ' 389524', yPlaceholder: t('example') + ' 5820270'
                 },
                 '31466': {
-                    xLabel: 'Rechtswert', yLabel: 'Hochwert',
-                    xPlaceholder: 'z.B. 2597000', yPlaceholder: 'z.B. 5735000'
+                    xLabel: t('labelEasting'), yLabel: t('labelNorthing'),
+                    xPlaceholder: t('example') + ' 2597000', yPlaceholder: t('example') + ' 5735000'
                 },
                 '31467': {
-                    xLabel: 'Rechtswert', yLabel: 'Hochwert',
-                    xPlaceholder: 'z.B. 3597000', yPlaceholder: 'z.B. 5735000'
+                    xLabel: t('labelEasting'), yLabel: t('labelNorthing'),
+                    xPlaceholder: t('example') + ' 3597000', yPlaceholder: t('example') + ' 5735000'
                 }
             };
 
@@ -838,7 +1052,7 @@ const frontendHTML = `<!DOCTYPE html>
             // Geolocation
             locationBtn.addEventListener('click', function() {
                 if (!navigator.geolocation) {
-                    showError('Geolokalisierung wird von Ihrem Browser nicht unterstützt.');
+                    showError(t('geoUnsupported'));
                     return;
                 }
 
@@ -852,23 +1066,26 @@ const frontendHTML = `<!DOCTYPE html>
                         locationBtn.disabled = false;
                     },
                     function(err) {
-                        showError('Standort konnte nicht ermittelt werden: ' + err.message);
+                        showError(t('geoFailed', err.message));
                         locationBtn.disabled = false;
                     },
                     { enableHighAccuracy: true, timeout: 10000 }
                 );
             });
 
-            // Clear form
+            // Clear form (and the query state in the URL)
             clearBtn.addEventListener('click', function() {
                 coordX.value = '';
                 coordY.value = '';
                 hideError();
                 results.classList.remove('active');
+                current = null;
+                selectedSource = '';
+                history.replaceState(null, '', location.pathname + location.search);
             });
 
             // Form submit
-            form.addEventListener('submit', async function(e) {
+            form.addEventListener('submit', function(e) {
                 e.preventDefault();
                 hideError();
 
@@ -877,16 +1094,28 @@ const frontendHTML = `<!DOCTYPE html>
                 const y = parseFloat(coordY.value.replace(',', '.'));
 
                 if (isNaN(x) || isNaN(y)) {
-                    showError('Bitte geben Sie gültige Koordinaten ein.');
+                    showError(t('invalidInput'));
                     return;
                 }
 
-                // Build query URL with proper URL encoding
-                let url = '/api/v1/query?srid=' + encodeURIComponent(srid);
+                selectedSource = '';
+                runQuery(srid, x, y, selectedSources());
+            });
+
+            // runQuery queries all sources, or only the given source ids through
+            // their per-source endpoints (sources null = all).
+            async function runQuery(srid, x, y, sources) {
+                if (sources && sources.length === 0) {
+                    showError(t('noSourceSelected'));
+                    return;
+                }
+
+                // Build the query string with proper URL encoding
+                let qs = 'srid=' + encodeURIComponent(srid);
                 if (srid === '4326') {
-                    url += '&lon=' + encodeURIComponent(x) + '&lat=' + encodeURIComponent(y);
+                    qs += '&lon=' + encodeURIComponent(x) + '&lat=' + encodeURIComponent(y);
                 } else {
-                    url += '&x=' + encodeURIComponent(x) + '&y=' + encodeURIComponent(y);
+                    qs += '&x=' + encodeURIComponent(x) + '&y=' + encodeURIComponent(y);
                 }
 
                 submitBtn.disabled = true;
@@ -894,33 +1123,277 @@ const frontendHTML = `<!DOCTYPE html>
                 results.classList.remove('active');
 
                 try {
-                    const response = await fetch(url);
-
-                    if (!response.ok) {
-                        let errorMessage = 'Abfrage fehlgeschlagen';
-                        try {
-                            const errorData = await response.json();
-                            errorMessage = errorData.error || errorData.message || errorMessage;
-                        } catch (parseErr) {
-                            // Response could not be parsed as JSON
-                        }
-                        throw new Error(errorMessage);
-                    }
-
                     let data;
-                    try {
-                        data = await response.json();
-                    } catch (parseErr) {
-                        throw new Error('Die Serverantwort konnte nicht verarbeitet werden.');
+                    if (sources) {
+                        data = mergeResponses(await Promise.all(sources.map(function(id) {
+                            return fetchQuery('/api/v1/query/' + encodeURIComponent(id) + '?' + qs);
+                        })));
+                    } else {
+                        data = await fetchQuery('/api/v1/query?' + qs);
                     }
+                    applyLayerFilter(data);
 
+                    current = { srid: srid, x: String(x), y: String(y), sources: sources };
                     displayResults(data, srid);
+                    writeHashState();
+                    addToHistory(current);
                 } catch (err) {
                     showError(err.message);
                 } finally {
                     submitBtn.disabled = false;
                     loading.classList.remove('active');
                 }
+            }
+
+            async function fetchQuery(url) {
+                const response = await fetch(url);
+
+                if (!response.ok) {
+                    let errorMessage = t('queryFailed');
+                    try {
+                        const errorData = await response.json();
+                        errorMessage = errorData.detail || errorData.title || errorData.error || errorData.message || errorMessage;
+                    } catch (parseErr) {
+                        // Response could not be parsed as JSON
+                    }
+                    throw new Error(errorMessage);
+                }
+
+                try {
+                    return await response.json();
+                } catch (parseErr) {
+                    throw new Error(t('badResponse'));
+                }
+            }
+
+            // mergeResponses combines per-source answers into one response: the
+            // point, wgs84 and gazetteer blocks are the same in each.
+            function mergeResponses(parts) {
+                const data = Object.assign({}, parts[0], { results: [], processing_time_ms: 0 });
+                parts.forEach(function(part) {
+                    data.results = data.results.concat(part.results || []);
+                    data.processing_time_ms = Math.max(data.processing_time_ms, part.processing_time_ms || 0);
+                    data.incomplete = data.incomplete || part.incomplete;
+                });
+                return data;
+            }
+
+            function queryHash(q, source) {
+                const p = new URLSearchParams({ srid: q.srid, x: q.x, y: q.y });
+                if (q.sources) p.set('sources', q.sources.join(','));
+                if (source) p.set('source', source);
+                return '#' + p.toString();
+            }
+
+            // replaceState neither adds a browser history entry per query nor
+            // fires hashchange.
+            function writeHashState() {
+                if (current) {
+                    history.replaceState(null, '', queryHash(current, selectedSource));
+                }
+            }
+
+            // Runs the query a link's hash describes (on load and when the hash
+            // changes, e.g. from a recent-queries entry). A hash without valid
+            // coordinates or with an SRID the form doesn't offer is ignored.
+            function applyHashState() {
+                const p = new URLSearchParams(location.hash.slice(1));
+                const srid = p.get('srid') || '4326';
+                const x = parseFloat(p.get('x'));
+                const y = parseFloat(p.get('y'));
+                if (isNaN(x) || isNaN(y) || !sridConfig[srid]) return;
+
+                sridSelect.value = srid;
+                sridSelect.dispatchEvent(new Event('change'));
+                coordX.value = String(x);
+                coordY.value = String(y);
+                selectedSource = p.get('source') || '';
+                const sources = p.get('sources') ? p.get('sources').split(',') : null;
+                setSourceSelection(sources);
+                hideError();
+                runQuery(srid, x, y, sources);
+            }
+            window.addEventListener('hashchange', applyHashState);
+
+            // Copy link: the clipboard API needs a secure context; elsewhere the
+            // link is offered in a prompt to copy by hand.
+            copyLinkBtn.addEventListener('click', function() {
+                const link = location.href;
+                if (navigator.clipboard && navigator.clipboard.writeText) {
+                    navigator.clipboard.writeText(link).then(function() {
+                        copyStatus.textContent = t('linkCopied');
+                    }, function() {
+                        window.prompt(t('copyLink'), link);
+                    });
+                } else {
+                    window.prompt(t('copyLink'), link);
+                }
+            });
+
+            // Recent queries. localStorage may be unavailable (private mode,
+            // disabled storage) or hold garbage; the history is then just empty.
+            function loadHistory() {
+                try {
+                    const list = JSON.parse(localStorage.getItem(HISTORY_KEY) || '[]');
+                    return Array.isArray(list) ? list : [];
+                } catch (e) {
+                    return [];
+                }
+            }
+
+            function saveHistory(list) {
+                try {
+                    localStorage.setItem(HISTORY_KEY, JSON.stringify(list));
+                } catch (e) {
+                    // Storage unavailable or full: keep the page working without it.
+                }
+            }
+
+            function addToHistory(q) {
+                const list = loadHistory().filter(function(h) {
+                    return queryHash(h) !== queryHash(q);
+                });
+                list.unshift(q);
+                saveHistory(list.slice(0, HISTORY_MAX));
+                renderHistory();
+            }
+
+            function renderHistory() {
+                const list = loadHistory();
+                let html = '';
+                list.forEach(function(q) {
+                    const label = q.srid === '4326'
+                        ? 'Lat: ' + q.y + ', Lon: ' + q.x
+                        : 'X: ' + q.x + ', Y: ' + q.y + ' (EPSG:' + q.srid + ')';
+                    html += '<li><a href="' + escapeHtml(queryHash(q)) + '">' + escapeHtml(label) + '</a></li>';
+                });
+                historyList.innerHTML = html;
+                historyCard.hidden = list.length === 0;
+            }
+
+            // Source/layer picker. Unticking sources sends the query to the
+            // per-source endpoint /api/v1/query/{sourceId} of each ticked one;
+            // unticked layers are dropped from the results, as the query API has
+            // no layer filter. With everything ticked the plain /api/v1/query runs.
+            let allSources = [];         // source ids listed by /api/v1/sources
+            let pendingSources = null;   // selection from a link, applied once the list loads
+            const excludedLayers = {};   // source id -> {layer name: true}
+
+            function loadSources() {
+                fetch('/api/v1/sources').then(function(r) {
+                    return r.ok ? r.json() : null;
+                }).then(function(data) {
+                    if (!data || !data.sources || data.sources.length === 0) return;
+                    allSources = data.sources.map(function(src) { return src.id; });
+                    let html = '';
+                    data.sources.forEach(function(src) {
+                        html += '<li>';
+                        html += '<label><input type="checkbox" class="picker-source" value="' + escapeHtml(src.id) + '" checked> ' + escapeHtml(src.name || src.id) + '</label>';
+                        html += '<details class="picker-layers" data-source="' + escapeHtml(src.id) + '">';
+                        html += '<summary>' + escapeHtml(t('layerCount', src.layer_count)) + '</summary><ul></ul></details>';
+                        html += '</li>';
+                    });
+                    pickerList.innerHTML = html;
+                    pickerList.querySelectorAll('.picker-layers').forEach(function(d) {
+                        d.addEventListener('toggle', function() {
+                            if (d.open && !d.dataset.loaded) loadLayers(d);
+                        });
+                    });
+                    picker.hidden = false;
+                    setSourceSelection(pendingSources);
+                }).catch(function() {
+                    // The picker is optional; without it every query covers all sources.
+                });
+            }
+
+            function loadLayers(d) {
+                d.dataset.loaded = 'true';
+                const id = d.dataset.source;
+                fetch('/api/v1/sources/' + encodeURIComponent(id) + '/layers').then(function(r) {
+                    return r.ok ? r.json() : null;
+                }).then(function(data) {
+                    if (!data || !data.layers) return;
+                    const excluded = excludedLayers[id] || {};
+                    let html = '';
+                    data.layers.forEach(function(l) {
+                        html += '<li><label><input type="checkbox" class="picker-layer" value="' + escapeHtml(l.name) + '"' +
+                            (excluded[l.name] ? '' : ' checked') + '> ' + escapeHtml(l.name) + '</label></li>';
+                    });
+                    d.querySelector('ul').innerHTML = html;
+                }).catch(function() {
+                    delete d.dataset.loaded; // retry on the next toggle
+                });
+            }
+
+            // selectedSources returns the ticked source ids, or null when all are
+            // ticked (or the list is not loaded), meaning "query everything".
+            function selectedSources() {
+                if (allSources.length === 0) return null;
+                const ids = [];
+                pickerList.querySelectorAll('.picker-source:checked').forEach(function(cb) {
+                    ids.push(cb.value);
+                });
+                return ids.length === allSources.length ? null : ids;
+            }
+
+            function setSourceSelection(ids) {
+                if (allSources.length === 0) {
+                    pendingSources = ids;
+                    return;
+                }
+                pickerList.querySelectorAll('.picker-source').forEach(function(cb) {
+                    cb.checked = !ids || ids.indexOf(cb.value) >= 0;
+                });
+                updatePickerSummary();
+            }
+
+            function updatePickerSummary() {
+                const ids = selectedSources();
+                pickerSummary.textContent = ids ? t('pickerSome', ids.length, allSources.length) : t('pickerAll');
+            }
+
+            pickerList.addEventListener('change', function(e) {
+                const cb = e.target;
+                if (cb.classList.contains('picker-layer')) {
+                    const id = cb.closest('.picker-layers').dataset.source;
+                    excludedLayers[id] = excludedLayers[id] || {};
+                    if (cb.checked) {
+                        delete excludedLayers[id][cb.value];
+                    } else {
+                        excludedLayers[id][cb.value] = true;
+                    }
+                }
+                updatePickerSummary();
+            });
+
+            document.getElementById('pickerAll').addEventListener('click', function() {
+                setSourceSelection(null);
+            });
+            document.getElementById('pickerNone').addEventListener('click', function() {
+                setSourceSelection([]);
+            });
+
+            // applyLayerFilter drops the features of unticked layers and recounts.
+            function applyLayerFilter(data) {
+                let total = 0;
+                (data.results || []).forEach(function(pkg) {
+                    const excluded = excludedLayers[pkg.source_id];
+                    if (excluded && pkg.features) {
+                        pkg.features = pkg.features.filter(function(f) { return !excluded[f.layer]; });
+                        pkg.feature_count = pkg.features.length;
+                    }
+                    total += pkg.feature_count || 0;
+                });
+                data.total_features = total;
+            }
+
+            clearHistoryBtn.addEventListener('click', function() {
+                try {
+                    localStorage.removeItem(HISTORY_KEY);
+                } catch (e) {
+                    // Storage unavailable: nothing was stored.
+                }
+                renderHistory();
             });
 
             function showError(message) {
@@ -948,6 +1421,7 @@ const frontendHTML = `<!DOCTYPE html>
                 }
 
                 resultStats.textContent = data.total_features + ' Feature(s) in ' + data.processing_time_ms + 'ms';
+                copyStatus.textContent = '';
 
                 let html = '';
 
@@ -963,10 +1437,14 @@ const frontendHTML = `<!DOCTYPE html>
 
                 // Point-in-polygon results
                 if (!data.results || data.results.length === 0) {
-                    html += '<div class="no-results">Keine Features an dieser Position gefunden.</div>';
+                    html += '<div class="no-results">' + escapeHtml(t('noFeatures')) + '</div>';
                 } else {
+                    // The package selected in the link is expanded, else the first.
+                    const selected = data.results.some(function(pkg) {
+                        return pkg.source_id === selectedSource;
+                    });
                     data.results.forEach(function(pkg, idx) {
-                        html += renderSource(pkg, idx === 0);
+                        html += renderSource(pkg, selected ? pkg.source_id === selectedSource : idx === 0);
                     });
                 }
                 resultContent.innerHTML = html;
@@ -974,8 +1452,15 @@ const frontendHTML = `<!DOCTYPE html>
                 // Expand/collapse — keyboard-accessible (the header is role="button").
                 document.querySelectorAll('.source-header').forEach(function(header) {
                     function toggle() {
-                        const isExpanded = header.parentElement.classList.toggle('expanded');
+                        const card = header.parentElement;
+                        const isExpanded = card.classList.toggle('expanded');
                         header.setAttribute('aria-expanded', isExpanded ? 'true' : 'false');
+                        if (isExpanded) {
+                            selectedSource = card.dataset.source;
+                        } else if (selectedSource === card.dataset.source) {
+                            selectedSource = '';
+                        }
+                        writeHashState();
                     }
                     header.addEventListener('click', toggle);
                     header.addEventListener('keydown', function(e) {
@@ -990,7 +1475,7 @@ const frontendHTML = `<!DOCTYPE html>
             }
 
             function renderSource(pkg, expanded) {
-                let html = '<div class="source-card' + (expanded ? ' expanded' : '') + '">';
+                let html = '<div class="source-card' + (expanded ? ' expanded' : '') + '" data-source="' + escapeHtml(pkg.source_id) + '">';
                 html += '<div class="source-header" role="button" tabindex="0" aria-expanded="' + (expanded ? 'true' : 'false') + '">';
                 html += '<div class="source-main">';
                 html += '<span class="source-name">' + escapeHtml(pkg.source_name || pkg.source_id) + '</span>';
@@ -1013,7 +1498,7 @@ const frontendHTML = `<!DOCTYPE html>
 
                 if (pkg.license) {
                     html += '<div class="license-info">';
-                    html += '<strong>Lizenz:</strong> ';
+                    html += '<strong>' + escapeHtml(t('license')) + ':</strong> ';
                     if (pkg.license.url) {
                         html += '<a href="' + escapeHtml(pkg.license.url) + '" target="_blank" rel="noopener noreferrer">' + escapeHtml(pkg.license.name || 'Link') + '</a>';
                     } else {
@@ -1072,11 +1557,11 @@ const frontendHTML = `<!DOCTYPE html>
             // a second request.
             function renderGazetteer(gaz) {
                 let html = '<div class="gazetteer-block">';
-                html += '<h3 class="gazetteer-title">Ort &amp; Umgebung</h3>';
+                html += '<h3 class="gazetteer-title">' + escapeHtml(t('gazTitle')) + '</h3>';
 
                 if (gaz.admin) {
                     html += '<div class="gaz-section">';
-                    html += '<div class="gaz-label">Verwaltungshierarchie';
+                    html += '<div class="gaz-label">' + escapeHtml(t('gazAdmin'));
                     if (gaz.admin.country_iso) {
                         html += ' <span class="badge">' + escapeHtml(gaz.admin.country_iso) + '</span>';
                     }
@@ -1114,7 +1599,7 @@ const frontendHTML = `<!DOCTYPE html>
 
                 if (gaz.islands && gaz.islands.length > 0) {
                     html += '<div class="gaz-section">';
-                    html += '<div class="gaz-label">Insel' + (gaz.islands.length > 1 ? 'n' : '') + '</div>';
+                    html += '<div class="gaz-label">' + escapeHtml(t(gaz.islands.length > 1 ? 'gazIslands' : 'gazIsland')) + '</div>';
                     html += '<ul class="admin-list">';
                     gaz.islands.forEach(function(is) {
                         html += '<li class="admin-item">';
@@ -1136,12 +1621,12 @@ const frontendHTML = `<!DOCTYPE html>
                 if (gaz.elevation) {
                     const e = gaz.elevation;
                     html += '<div class="gaz-section">';
-                    html += '<div class="gaz-label">Höhe</div>';
+                    html += '<div class="gaz-label">' + escapeHtml(t('gazElevation')) + '</div>';
                     let elevText;
                     if (e.sea_level) {
-                        elevText = 'Meeresspiegel (0 m)';
+                        elevText = t('gazSeaLevel');
                     } else {
-                        elevText = (typeof e.meters === 'number' ? e.meters.toFixed(0) : '-') + ' m ü. NN';
+                        elevText = t('gazElevationValue', typeof e.meters === 'number' ? e.meters.toFixed(0) : '-');
                     }
                     html += '<div class="gaz-elevation">' + escapeHtml(elevText) + '</div>';
                     const emeta = [];
@@ -1152,7 +1637,7 @@ const frontendHTML = `<!DOCTYPE html>
                         html += '<div class="gaz-elevation-meta">' + emeta.join(' &middot; ') + '</div>';
                     }
                     if (e.source && e.source.name) {
-                        html += '<div class="gaz-attribution">Quelle: ';
+                        html += '<div class="gaz-attribution">' + escapeHtml(t('gazSource')) + ': ';
                         const srcUrl = httpUrl(e.source.url);
                         if (srcUrl) {
                             html += '<a href="' + escapeHtml(srcUrl) + '" target="_blank" rel="noopener noreferrer">' + escapeHtml(e.source.name) + '</a>';
@@ -1166,7 +1651,7 @@ const frontendHTML = `<!DOCTYPE html>
 
                 if (gaz.bearing) {
                     html += '<div class="gaz-section">';
-                    html += '<div class="gaz-label">Peilung</div>';
+                    html += '<div class="gaz-label">' + escapeHtml(t('gazBearing')) + '</div>';
                     html += '<div class="gaz-bearing">' + escapeHtml(gaz.bearing.label || (gaz.bearing.reference || '')) + '</div>';
                     const meta = [];
                     if (gaz.bearing.class) meta.push(escapeHtml(gaz.bearing.class));
@@ -1182,20 +1667,21 @@ const frontendHTML = `<!DOCTYPE html>
                 if (gaz.exposure) {
                     const x = gaz.exposure;
                     html += '<div class="gaz-section">';
-                    html += '<div class="gaz-label">Exposition</div>';
+                    html += '<div class="gaz-label">' + escapeHtml(t('gazExposure')) + '</div>';
+                    const slope = typeof x.slope_deg === 'number' ? x.slope_deg.toFixed(1) : '?';
                     let expText;
                     if (x.flat) {
-                        expText = 'eben (Neigung ' + (typeof x.slope_deg === 'number' ? x.slope_deg.toFixed(1) : '?') + '°)';
-                    } else {
-                        const dir = x.aspect_compass ? escapeHtml(x.aspect_compass) : '';
+                        expText = t('gazFlat', slope);
+                    } else if (x.aspect_compass) {
                         const asp = typeof x.aspect_deg === 'number' ? ' (' + x.aspect_deg.toFixed(0) + '°)' : '';
-                        expText = (dir ? dir + asp + '-Exposition' : 'Exposition') +
-                            ', ' + (typeof x.slope_deg === 'number' ? x.slope_deg.toFixed(1) : '?') + '° Neigung';
+                        expText = t('gazAspect', x.aspect_compass + asp, slope);
+                    } else {
+                        expText = t('gazNoAspect', slope);
                     }
-                    html += '<div class="gaz-exposure">' + expText + '</div>';
+                    html += '<div class="gaz-exposure">' + escapeHtml(expText) + '</div>';
                     const xmeta = [];
                     if (typeof x.slope_percent === 'number') xmeta.push(x.slope_percent.toFixed(0) + ' %');
-                    if (typeof x.sample_spacing_m === 'number') xmeta.push('Raster ~' + x.sample_spacing_m.toFixed(0) + ' m');
+                    if (typeof x.sample_spacing_m === 'number') xmeta.push(escapeHtml(t('gazGrid', x.sample_spacing_m.toFixed(0))));
                     if (xmeta.length > 0) {
                         html += '<div class="gaz-exposure-meta">' + xmeta.join(' &middot; ') + '</div>';
                     }
@@ -1204,7 +1690,7 @@ const frontendHTML = `<!DOCTYPE html>
 
                 if (gaz.sources && gaz.sources.length > 0) {
                     html += '<div class="gaz-section">';
-                    html += '<div class="gaz-label">Namensquellen</div>';
+                    html += '<div class="gaz-label">' + escapeHtml(t('gazNameSources')) + '</div>';
                     html += '<ul class="source-explain-list">';
                     gaz.sources.forEach(function(s) {
                         html += '<li><code>' + escapeHtml(s.code) + '</code> ' + escapeHtml(s.long || s.short || '');
@@ -1218,9 +1704,9 @@ const frontendHTML = `<!DOCTYPE html>
 
                 if (gaz.license) {
                     html += '<div class="gaz-section gaz-license">';
-                    html += '<strong>Datenlizenz:</strong> ';
+                    html += '<strong>' + escapeHtml(t('gazDataLicense')) + ':</strong> ';
                     if (gaz.license.url) {
-                        html += '<a href="' + escapeHtml(gaz.license.url) + '" target="_blank" rel="noopener noreferrer">' + escapeHtml(gaz.license.name || 'Lizenz') + '</a>';
+                        html += '<a href="' + escapeHtml(gaz.license.url) + '" target="_blank" rel="noopener noreferrer">' + escapeHtml(gaz.license.name || t('license')) + '</a>';
                     } else {
                         html += escapeHtml(gaz.license.name || '-');
                     }
@@ -1265,22 +1751,65 @@ const frontendHTML = `<!DOCTYPE html>
             function httpUrl(u) {
                 return /^https?:\/\//i.test(String(u || '')) ? u : '';
             }
+
+            renderHistory();
+            loadSources();
+            applyHashState();
         })();
     </script>
 </body>
 </html>`
 
-// renderFrontend substitutes the build version into the footer placeholder once,
-// at server construction. The version is HTML-escaped (it comes from a trusted
-// -ldflags value, but escaping keeps the template injection-safe regardless).
-func renderFrontend(version string) []byte {
-	return []byte(strings.Replace(frontendHTML, "__ORTUS_VERSION__", html.EscapeString(version), 1))
+// renderFrontend renders the page in lang, once per language at server
+// construction: the message catalog of lang goes into the markup and the
+// script (frontendReplacer), then the build version into the footer and the
+// deployment branding into its placeholders. The version and the branding
+// are HTML-escaped (they come from trusted -ldflags and config values, but
+// escaping keeps the template injection-safe regardless).
+func renderFrontend(version, lang string, branding config.FrontendBrandingConfig) []byte {
+	page := frontendReplacer(lang).Replace(frontendHTML)
+	page = strings.NewReplacer(
+		"__ORTUS_VERSION__", html.EscapeString(version),
+		"__ORTUS_BRANDING_CSS__", brandingCSS(branding),
+		"__ORTUS_BRANDING_LOGO__", brandingLogo(branding),
+		"__ORTUS_BRANDING_FOOTER__", brandingFooter(branding),
+	).Replace(page)
+	return []byte(page)
+}
+
+// brandingCSS overrides the accent color in both color schemes. The color is
+// a hex color (config.Validate), so it is safe inside <style>.
+func brandingCSS(b config.FrontendBrandingConfig) string {
+	if b.PrimaryColor == "" {
+		return ""
+	}
+	return "        :root { --primary: " + b.PrimaryColor +
+		"; --primary-dark: color-mix(in srgb, " + b.PrimaryColor + " 85%, black); }"
+}
+
+func brandingLogo(b config.FrontendBrandingConfig) string {
+	if b.LogoURL == "" {
+		return ""
+	}
+	// The h1 names the page; the logo is decoration next to it.
+	return `<img class="brand-logo" src="` + html.EscapeString(b.LogoURL) + `" alt="">`
+}
+
+func brandingFooter(b config.FrontendBrandingConfig) string {
+	if b.FooterText == "" {
+		return ""
+	}
+	return `<div class="footer-text">` + html.EscapeString(b.FooterText) + `</div>`
 }
 
-// handleFrontend serves the pre-rendered coordinate query frontend. The page is
-// built once in NewServer (the version is constant for the server's lifetime),
-// so each request only writes the cached bytes.
-func (s *Server) handleFrontend(w http.ResponseWriter, _ *http.Request) {
+// handleFrontend serves the pre-rendered coordinate query frontend in the
+// language negotiated from ?lang= and Accept-Language. The pages are built
+// once in NewServer (the version is constant for the server's lifetime), so
+// each request only writes the cached bytes.
+func (s *Server) handleFrontend(w http.ResponseWriter, r *http.Request) {
+	lang := negotiateFrontendLanguage(r)
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	_, _ = w.Write(s.frontendPage)
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+	_, _ = w.Write(s.frontendPages[lang])
 }
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"github.com/jobrunner/ortus/internal/config"
+)
+
+// frontendMessages holds the message catalogs of the frontend, by language
+// and message key. Messages are plain text: the page template refers to one
+// as [[key]] in markup, substituted HTML-escaped when the page is rendered,
+// and the script looks them up with t('key') from the same catalog, which is
+// embedded as JSON. {0}, {1}, ... in a message are filled by t's arguments.
+// Every catalog carries every key (TestFrontendMessageCatalogs).
+var frontendMessages = map[string]map[string]string{
+	"de": {
+		"title":             "Ortus - Koordinatenabfrage",
+		"subtitle":          "Point-in-Polygon Abfrage über Datenquellen",
+		"enterCoordinates":  "Koordinaten eingeben",
+		"crs":               "Koordinatensystem",
+		"labelLat":          "Breitengrad (Lat)",
+		"labelLon":          "Längengrad (Lon)",
+		"labelXMeters":      "X (Meter)",
+		"labelYMeters":      "Y (Meter)",
+		"labelEastingUTM":   "Rechtswert (E)",
+		"labelNorthingUTM":  "Hochwert (N)",
+		"labelEasting":      "Rechtswert",
+		"labelNorthing":     "Hochwert",
+		"example":           "z.B.",
+		"submit":            "Abfragen",
+		"useLocation":       "Aktuellen Standort verwenden",
+		"clear":             "Leeren",
+		"loading":           "Abfrage wird ausgeführt...",
+		"results":           "Ergebnisse",
+		"apiDocs":           "API Dokumentation",
+		"copyLink":          "Link kopieren",
+		"linkCopied":        "Link kopiert",
+		"recentQueries":     "Letzte Abfragen",
+		"clearHistory":      "Verlauf löschen",
+		"dataSources":       "Datenquellen",
+		"selectAll":         "Alle",
+		"selectNone":        "Keine",
+		"pickerAll":         "alle",
+		"pickerSome":        "{0} von {1}",
+		"layerCount":        "{0} Layer",
+		"noSourceSelected":  "Bitte wählen Sie mindestens eine Datenquelle.",
+		"geoUnsupported":    "Geolokalisierung wird von Ihrem Browser nicht unterstützt.",
+		"geoFailed":         "Standort konnte nicht ermittelt werden: {0}",
+		"invalidInput":      "Bitte geben Sie gültige Koordinaten ein.",
+		"queryFailed":       "Abfrage fehlgeschlagen",
+		"badResponse":       "Die Serverantwort konnte nicht verarbeitet werden.",
+		"noFeatures":        "Keine Features an dieser Position gefunden.",
+		"license":           "Lizenz",
+		"gazTitle":          "Ort & Umgebung",
+		"gazAdmin":          "Verwaltungshierarchie",
+		"gazIsland":         "Insel",
+		"gazIslands":        "Inseln",
+		"gazElevation":      "Höhe",
+		"gazSeaLevel":       "Meeresspiegel (0 m)",
+		"gazElevationValue": "{0} m ü. NN",
+		"gazSource":         "Quelle",
+		"gazBearing":        "Peilung",
+		"gazExposure":       "Exposition",
+		"gazFlat":           "eben (Neigung {0}°)",
+		"gazAspect":         "{0}-Exposition, {1}° Neigung",
+		"gazNoAspect":       "Exposition, {0}° Neigung",
+		"gazGrid":           "Raster ~{0} m",
+		"gazNameSources":    "Namensquellen",
+		"gazDataLicense":    "Datenlizenz",
+	},
+	"en": {
+		"title":             "Ortus - Coordinate Query",
+		"subtitle":          "Point-in-polygon query across data sources",
+		"enterCoordinates":  "Enter coordinates",
+		"crs":               "Coordinate system",
+		"labelLat":          "Latitude (Lat)",
+		"labelLon":          "Longitude (Lon)",
+		"labelXMeters":      "X (meters)",
+		"labelYMeters":      "Y (meters)",
+		"labelEastingUTM":   "Easting (E)",
+		"labelNorthingUTM":  "Northing (N)",
+		"labelEasting":      "Easting",
+		"labelNorthing":     "Northing",
+		"example":           "e.g.",
+		"submit":            "Query",
+		"useLocation":       "Use current location",
+		"clear":             "Clear",
+		"loading":           "Running query...",
+		"results":           "Results",
+		"apiDocs":           "API documentation",
+		"copyLink":          "Copy link",
+		"linkCopied":        "Link copied",
+		"recentQueries":     "Recent queries",
+		"clearHistory":      "Clear history",
+		"dataSources":       "Data sources",
+		"selectAll":         "All",
+		"selectNone":        "None",
+		"pickerAll":         "all",
+		"pickerSome":        "{0} of {1}",
+		"layerCount":        "{0} layers",
+		"noSourceSelected":  "Please select at least one data source.",
+		"geoUnsupported":    "Geolocation is not supported by your browser.",
+		"geoFailed":         "Could not determine your location: {0}",
+		"invalidInput":      "Please enter valid coordinates.",
+		"queryFailed":       "Query failed",
+		"badResponse":       "The server response could not be processed.",
+		"noFeatures":        "No features found at this position.",
+		"license":           "License",
+		"gazTitle":          "Location & Surroundings",
+		"gazAdmin":          "Administrative hierarchy",
+		"gazIsland":         "Island",
+		"gazIslands":        "Islands",
+		"gazElevation":      "Elevation",
+		"gazSeaLevel":       "Sea level (0 m)",
+		"gazElevationValue": "{0} m above sea level",
+		"gazSource":         "Source",
+		"gazBearing":        "Bearing",
+		"gazExposure":       "Aspect",
+		"gazFlat":           "flat (slope {0}°)",
+		"gazAspect":         "{0}-facing, {1}° slope",
+		"gazNoAspect":       "Aspect, {0}° slope",
+		"gazGrid":           "Grid ~{0} m",
+		"gazNameSources":    "Name sources",
+		"gazDataLicense":    "Data license",
+	},
+}
+
+// frontendLanguages are the languages the frontend is served in. The first
+// is the fallback when neither ?lang= nor Accept-Language names one of them.
+var frontendLanguages = []language.Tag{language.English, language.German}
+
+var frontendLanguageMatcher = language.NewMatcher(frontendLanguages)
+
+// negotiateFrontendLanguage picks the page language for r: an explicit ?lang=
+// wins, then the Accept-Language preferences, then the fallback. The result
+// is always a key of frontendMessages.
+func negotiateFrontendLanguage(r *http.Request) string {
+	tag, _ := language.MatchStrings(frontendLanguageMatcher,
+		r.URL.Query().Get("lang"), r.Header.Get("Accept-Language"))
+	base, _ := tag.Base()
+	return base.String()
+}
+
+// renderFrontendPages renders the frontend once per supported language.
+func renderFrontendPages(version string, branding config.FrontendBrandingConfig) map[string][]byte {
+	pages := make(map[string][]byte, len(frontendLanguages))
+	for _, tag := range frontendLanguages {
+		lang := tag.String()
+		pages[lang] = renderFrontend(version, lang, branding)
+	}
+	return pages
+}
+
+// frontendReplacer substitutes the language, the message catalog and the
+// [[key]] placeholders of lang into the page template.
+func frontendReplacer(lang string) *strings.Replacer {
+	msgs := frontendMessages[lang]
+	// A map[string]string always marshals, and the encoder escapes <, > and &,
+	// so the catalog is safe inside <script>.
+	catalog, _ := json.Marshal(msgs)
+	pairs := []string{"__ORTUS_LANG__", lang, "__ORTUS_MESSAGES__", string(catalog)}
+	for key, msg := range msgs {
+		pairs = append(pairs, "[["+key+"]]", html.EscapeString(msg))
+	}
+	return strings.NewReplacer(pairs...)
+}
@@ -1,8 +1,13 @@
 package http
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/jobrunner/ortus/internal/config"
 )
 
 // TestFrontendCoordinateInputWiring guards the embedded mini-frontend against
@@ -33,12 +38,12 @@ func TestFrontendCoordinateInputWiring(t *testing.T) {
 		"function hasGazetteerContent",        // empty-block guard
 		"hasGazetteerContent(data.gazetteer)", // wired into displayResults
 		"equivalent_description",              // admin-level meaning rendered
-		"Namensquellen",                       // name_source explanations section
-		"Datenlizenz",                         // dataset attribution rendered
+		"t('gazNameSources')",                 // name_source explanations section
+		"t('gazDataLicense')",                 // dataset attribution rendered
 		"gaz-elevation",                       // elevation rendered in the gazetteer block
 		"gaz.islands",                         // islands rendered in the gazetteer block
 		"gaz.exposure",                        // exposure rendered in the gazetteer block
-		"Exposition",                          // exposure section label
+		"t('gazExposure')",                    // exposure section label
 		"function httpUrl",                    // scheme guard for source links
 		"'Lat: ' + coord.y",                   // WGS84 result shows Lat before Lon
 		"data.wgs84",                          // reprojected WGS84 shown for projected SRIDs
@@ -54,10 +59,10 @@ func TestFrontendCoordinateInputWiring(t *testing.T) {
 // bearing, with exposure next to the bearing).
 func TestFrontendGazetteerSectionOrder(t *testing.T) {
 	html := frontendHTML
-	iIslands := strings.Index(html, `Insel`)       // islands section label
-	iElev := strings.Index(html, `Höhe`)           // elevation section label
-	iBearing := strings.Index(html, `Peilung`)     // bearing section label
-	iExposure := strings.Index(html, `Exposition`) // exposure section label
+	iIslands := strings.Index(html, `'gazIsland`)        // islands section label
+	iElev := strings.Index(html, `t('gazElevation')`)    // elevation section label
+	iBearing := strings.Index(html, `t('gazBearing')`)   // bearing section label
+	iExposure := strings.Index(html, `t('gazExposure')`) // exposure section label
 	if iIslands < 0 || iElev < 0 || iBearing < 0 || iExposure < 0 {
 		t.Fatalf("gazetteer section labels missing: islands=%d elevation=%d bearing=%d exposure=%d", iIslands, iElev, iBearing, iExposure)
 	}
@@ -66,11 +71,53 @@ func TestFrontendGazetteerSectionOrder(t *testing.T) {
 	}
 }
 
+// TestFrontendPermalinkWiring guards the shareable query state: the query is
+// written to and read from the URL hash, the expanded package travels along,
+// and recent queries persist in localStorage.
+func TestFrontendPermalinkWiring(t *testing.T) {
+	html := frontendHTML
+	for _, marker := range []string{
+		"function applyHashState",                               // hash -> form + query
+		"window.addEventListener('hashchange', applyHashState)", // links within the page
+		"history.replaceState(null, '', queryHash(",             // query -> hash
+		"p.set('source', source)",                               // expanded package in the link
+		`data-source="`,                                         // package id on the card
+		`id="copyLinkBtn"`,                                      // copy link action
+		"navigator.clipboard.writeText(link)",                   // ...via the clipboard API
+		"localStorage.setItem(HISTORY_KEY",                      // recent queries persisted
+		`id="clearHistoryBtn"`,                                  // ...and clearable
+	} {
+		if !strings.Contains(html, marker) {
+			t.Errorf("frontend is missing expected marker %q", marker)
+		}
+	}
+}
+
+// TestFrontendPickerWiring guards the source/layer picker: it lists the
+// sources, loads a source's layers on demand, queries ticked sources through
+// their per-source endpoint and drops unticked layers from the results.
+func TestFrontendPickerWiring(t *testing.T) {
+	html := frontendHTML
+	for _, marker := range []string{
+		`id="picker"`,              // picker in the form
+		"fetch('/api/v1/sources')", // source list
+		"'/layers'",                // layers of a source
+		"'/api/v1/query/' + encodeURIComponent(id)", // per-source endpoint
+		"function mergeResponses",                   // per-source answers combined
+		"function applyLayerFilter",                 // unticked layers dropped
+		"p.set('sources'",                           // selection kept in the link
+	} {
+		if !strings.Contains(html, marker) {
+			t.Errorf("frontend is missing expected marker %q", marker)
+		}
+	}
+}
+
 // TestRenderFrontendInjectsVersion checks the footer version substitution: the
 // placeholder is gone, the version is present, and an HTML-metachar version is
 // escaped rather than injected verbatim.
 func TestRenderFrontendInjectsVersion(t *testing.T) {
-	page := string(renderFrontend("v1.2.3"))
+	page := string(renderFrontend("v1.2.3", "de", config.FrontendBrandingConfig{}))
 	if strings.Contains(page, "__ORTUS_VERSION__") {
 		t.Error("version placeholder was not substituted")
 	}
@@ -78,7 +125,7 @@ func TestRenderFrontendInjectsVersion(t *testing.T) {
 		t.Error("rendered page is missing the footer version")
 	}
 
-	escaped := string(renderFrontend("<script>x</script>"))
+	escaped := string(renderFrontend("<script>x</script>", "de", config.FrontendBrandingConfig{}))
 	if strings.Contains(escaped, "<script>x</script>") {
 		t.Error("version was not HTML-escaped")
 	}
@@ -92,7 +139,7 @@ func TestRenderFrontendInjectsVersion(t *testing.T) {
 // the icon-only location button, keyboard-operable collapsible source headers, and
 // reduced-motion support.
 func TestFrontendAccessibilityMarkers(t *testing.T) {
-	html := frontendHTML
+	html := string(renderFrontend("dev", "de", config.FrontendBrandingConfig{}))
 	for _, marker := range []string{
 		`role="alert"`,                             // error region announced
 		`role="status"`,                            // loading + results summary announced
@@ -108,3 +155,125 @@ func TestFrontendAccessibilityMarkers(t *testing.T) {
 		}
 	}
 }
+
+// TestRenderFrontendBranding checks that the branding lands in the page,
+// escaped, and that an unbranded page carries none of it.
+func TestRenderFrontendBranding(t *testing.T) {
+	page := string(renderFrontend("dev", "en", config.FrontendBrandingConfig{
+		LogoURL:      "https://example.org/logo.svg?a=1&b=2",
+		PrimaryColor: "#0a7f3f",
+		FooterText:   "Landesamt <Geodaten>",
+	}))
+	for _, want := range []string{
+		`<img class="brand-logo" src="https://example.org/logo.svg?a=1&amp;b=2" alt="">`,
+		"--primary: #0a7f3f;",
+		`<div class="footer-text">Landesamt &lt;Geodaten&gt;</div>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("branded page is missing %q", want)
+		}
+	}
+
+	plain := string(renderFrontend("dev", "en", config.FrontendBrandingConfig{}))
+	for _, unwanted := range []string{`class="brand-logo"`, `class="footer-text"`, ":root { --primary"} {
+		if strings.Contains(plain, unwanted) {
+			t.Errorf("unbranded page contains %q", unwanted)
+		}
+	}
+	if !strings.Contains(plain, "prefers-color-scheme: dark") {
+		t.Error("page has no dark color scheme")
+	}
+}
+
+// TestFrontendMessageCatalogs checks that every language carries the same
+// message keys and that every key the page template uses exists.
+func TestFrontendMessageCatalogs(t *testing.T) {
+	for _, tag := range frontendLanguages {
+		if _, ok := frontendMessages[tag.String()]; !ok {
+			t.Fatalf("no message catalog for %s", tag)
+		}
+	}
+	want := frontendMessages["en"]
+	for lang, msgs := range frontendMessages {
+		for key := range want {
+			if msgs[key] == "" {
+				t.Errorf("%s catalog is missing %q", lang, key)
+			}
+		}
+		for key := range msgs {
+			if _, ok := want[key]; !ok {
+				t.Errorf("%s catalog has %q, which en lacks", lang, key)
+			}
+		}
+	}
+
+	used := regexp.MustCompile(`\[\[(\w+)\]\]|\bt\('(\w+)'`).FindAllStringSubmatch(frontendHTML, -1)
+	if len(used) == 0 {
+		t.Fatal("page template uses no messages")
+	}
+	for _, m := range used {
+		key := m[1] + m[2]
+		if _, ok := want[key]; !ok {
+			t.Errorf("page template uses unknown message %q", key)
+		}
+	}
+}
+
+// TestRenderFrontendLanguages checks that each rendered page declares its
+// language and has no unsubstituted placeholder left.
+func TestRenderFrontendLanguages(t *testing.T) {
+	for lang, page := range renderFrontendPages("dev", config.FrontendBrandingConfig{}) {
+		html := string(page)
+		if !strings.Contains(html, `<html lang="`+lang+`">`) {
+			t.Errorf("%s page does not declare its language", lang)
+		}
+		for _, leftover := range []string{"[[", "__ORTUS_"} {
+			if strings.Contains(html, leftover) {
+				t.Errorf("%s page has unsubstituted placeholder %q", lang, leftover)
+			}
+		}
+		if !strings.Contains(html, "<title>"+frontendMessages[lang]["title"]+"</title>") {
+			t.Errorf("%s page title is not localized", lang)
+		}
+	}
+}
+
+func TestHandleFrontendNegotiatesLanguage(t *testing.T) {
+	s := &Server{frontendPages: renderFrontendPages("dev", config.FrontendBrandingConfig{})}
+	tests := []struct {
+		name           string
+		target         string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "no preference", target: "/", want: "en"},
+		{name: "accept german", target: "/", acceptLanguage: "de-DE,de;q=0.9,en;q=0.8", want: "de"},
+		{name: "accept swiss german", target: "/", acceptLanguage: "de-CH", want: "de"},
+		{name: "accept english first", target: "/", acceptLanguage: "en-GB,de;q=0.5", want: "en"},
+		{name: "unsupported falls back", target: "/", acceptLanguage: "fr-FR", want: "en"},
+		{name: "unsupported then german", target: "/", acceptLanguage: "fr-FR,de;q=0.7", want: "de"},
+		{name: "query overrides header", target: "/?lang=en", acceptLanguage: "de", want: "en"},
+		{name: "query alone", target: "/?lang=de", want: "de"},
+		{name: "invalid query ignored", target: "/?lang=%21%21", acceptLanguage: "de", want: "de"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			s.handleFrontend(rec, req)
+
+			if got := rec.Header().Get("Content-Language"); got != tt.want {
+				t.Errorf("Content-Language = %q, want %q", got, tt.want)
+			}
+			if !strings.Contains(rec.Body.String(), `<html lang="`+tt.want+`">`) {
+				t.Errorf("body is not the %s page", tt.want)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept-Language" {
+				t.Errorf("Vary = %q, want Accept-Language", got)
+			}
+		})
+	}
+}
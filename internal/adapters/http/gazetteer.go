@@ -14,12 +14,12 @@ import (
 // (GET /api/v1/gazetteer). It is registered only when the gazetteer feature is
 // wired; otherwise the route does not exist.
 func (s *Server) handleGazetteer(w http.ResponseWriter, r *http.Request) {
-	params, err := s.parseQueryParams(r)
+	params, err := parseCoordinateParams(r)
 	if err != nil {
-		s.writeError(w, http.StatusBadRequest, err.Error())
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
-	coord := s.paramsToCoordinate(params)
+	coord := params.Coordinate()
 
 	// Reproject to WGS84 (the gazetteer dataset's SRID). A non-4326 input is
 	// transformed rather than rejected; a non-transformable SRID is a client error
@@ -29,19 +29,19 @@ func (s *Server) handleGazetteer(w http.ResponseWriter, r *http.Request) {
 	case terr == nil:
 		// proceed
 	case errors.Is(terr, errNotTransformable):
-		s.writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf(
+		s.writeError(w, r, http.StatusUnprocessableEntity, fmt.Sprintf(
 			"coordinate SRID %d cannot be transformed to WGS84 (EPSG:4326); "+
 				"query with srid=4326 (lon/lat), or run ortus with a coordinate transformer",
 			coord.SRID))
 		return
 	default:
-		s.handleQueryError(w, terr)
+		s.handleQueryError(w, r, terr)
 		return
 	}
 
 	sections, err := s.gazetteerSections(r.Context(), wgs)
 	if err != nil {
-		s.handleQueryError(w, err)
+		s.handleQueryError(w, r, err)
 		return
 	}
 
@@ -264,13 +264,21 @@ func formatElevation(e *domain.Elevation) map[string]interface{} {
 	return out
 }
 
+// gazetteerParams is the query parameter GET /api/v1/query takes when the
+// gazetteer is wired.
+type gazetteerParams struct {
+	WithGazetteer string `query:"with-gazetteer"`
+}
+
 // gazetteerEnrichmentRequested reports whether /query should attach the gazetteer
 // block. Enrichment is ON by default when the feature is wired; a client opts out
 // only with an explicit falsy with-gazetteer value (0/false/no/off) to skip the
 // extra Locate+Bearing spatial work. Any other value — including an unrecognized
 // one — leaves enrichment on.
 func gazetteerEnrichmentRequested(r *http.Request) bool {
-	switch strings.ToLower(r.URL.Query().Get("with-gazetteer")) {
+	var params gazetteerParams
+	_ = decodeQuery(r.URL.Query(), &params) // a string field cannot fail to decode
+	switch strings.ToLower(params.WithGazetteer) {
 	case "0", "false", "no", "off":
 		return false
 	default:
@@ -300,7 +308,8 @@ func (s *Server) toWGS84(ctx context.Context, c domain.Coordinate) (domain.Coord
 		// explicit WGS84 SRID for downstream consistency (requireWGS84 accepts 0 too).
 		return domain.NewWGS84Coordinate(c.X, c.Y), nil
 	}
-	if s.transformer == nil || !s.transformer.IsSupported(c.SRID, domain.SRIDWGS84) {
+	if s.transformer == nil || !s.transformer.IsSupported(c.SRID, domain.SRIDWGS84) ||
+		!s.flagEnabled(ctx, domain.FlagWGS84Reprojection) {
 		return domain.Coordinate{}, errNotTransformable
 	}
 	w, err := s.transformer.Transform(ctx, c, domain.SRIDWGS84)
@@ -313,6 +322,6 @@ func (s *Server) toWGS84(ctx context.Context, c domain.Coordinate) (domain.Coord
 // wgs84Block renders the always-present WGS84 coordinate block. It is lon/lat
 // (not x/y/srid) because it is an explicitly-geographic coordinate other services
 // can compute with and store, regardless of the query's input SRID.
-func wgs84Block(c domain.Coordinate) map[string]interface{} {
-	return map[string]interface{}{"lon": c.X, "lat": c.Y}
+func wgs84Block(c domain.Coordinate) *WGS84DTO {
+	return &WGS84DTO{Lon: c.X, Lat: c.Y}
 }
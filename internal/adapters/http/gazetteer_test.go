@@ -71,7 +71,9 @@ func newGazetteerServerT(t *testing.T, gaz input.Gazetteer, tf output.Coordinate
 	return NewServer(
 		config.ServerConfig{Host: "localhost", Port: 8080, ReadTimeout: time.Second, WriteTimeout: time.Second},
 		query, reg, health, nil, logger, false,
-		ServerOptions{Gazetteer: gaz, GazetteerLicense: sampleGazetteerLicense(), Transformer: tf},
+		ServerOptions{Gazetteer: gaz, GazetteerLicense: sampleGazetteerLicense(), Transformer: tf,
+			Popularity: application.NewPopularity(), Peers: application.NewFederation(nil, time.Minute, logger),
+			Geocoder: &fakeGeocoder{}, SourceHealth: reg, Tables: reg, Tiles: reg},
 	)
 }
 
@@ -131,6 +133,36 @@ func TestQueryNonWGS84NoTransformer(t *testing.T) {
 	}
 }
 
+// flagsOff is an output.FeatureFlags that switches the listed flags off.
+type flagsOff []domain.FeatureFlag
+
+func (f flagsOff) Enabled(_ context.Context, flag domain.FeatureFlag, fallback bool) bool {
+	for _, off := range f {
+		if off == flag {
+			return false
+		}
+	}
+	return fallback
+}
+
+// TestQueryWGS84ReprojectionFlagOff: with the wgs84_reprojection kill-switch a
+// 3857 query behaves as if no transformer were configured, while a WGS84 query
+// keeps its wgs84 block.
+func TestQueryWGS84ReprojectionFlagOff(t *testing.T) {
+	tf := fakeTransformer{lon: -16.856, lat: 28.60, supported: true}
+	srv := newGazetteerServerT(t, fakeGazetteer{loc: sampleLocality(), fix: sampleFix()}, tf)
+	srv.flags = flagsOff{domain.FlagWGS84Reprojection}
+
+	_, body := doGET(t, srv, "/api/v1/query?x=-1876403.675&y=3291468.780&srid=3857")
+	if body["wgs84"] != nil || body["gazetteer"] != nil {
+		t.Errorf("wgs84 = %v, gazetteer = %v; want both absent with reprojection off", body["wgs84"], body["gazetteer"])
+	}
+	_, body = doGET(t, srv, "/api/v1/query?lon=9.93&lat=49.79")
+	if body["wgs84"] == nil {
+		t.Error("WGS84 query lost its wgs84 block with reprojection off")
+	}
+}
+
 // readyQuerier is a minimal query-service registry reporting a single ready
 // source with no features, so a per-source /query returns 200 (empty results).
 // That is enough to assert the per-source response envelope (the wgs84 block)
@@ -145,9 +177,21 @@ func (r readyQuerier) GetSource(context.Context, string) (*domain.Source, error)
 func (r readyQuerier) Query(context.Context, string, string, domain.Coordinate) ([]domain.Feature, error) {
 	return nil, nil
 }
+func (r readyQuerier) QueryEncoded(context.Context, string, string, domain.Coordinate, domain.GeometryOptions) ([]domain.Feature, error) {
+	return nil, nil
+}
 func (r readyQuerier) QueryPoints(_ context.Context, _, _ string, coords []domain.Coordinate) ([][]domain.Feature, error) {
 	return make([][]domain.Feature, len(coords)), nil
 }
+func (r readyQuerier) QueryRoute(context.Context, string, string, domain.Route) ([]domain.Feature, error) {
+	return nil, nil
+}
+func (r readyQuerier) QueryArea(context.Context, string, string, domain.Area, domain.SpatialRelation) ([]domain.Feature, error) {
+	return nil, nil
+}
+func (r readyQuerier) Aggregate(context.Context, string, string, domain.AggregateQuery) (domain.LayerAggregate, error) {
+	return domain.LayerAggregate{}, nil
+}
 
 // newQuerySourceServer builds a Server whose query service has one ready source,
 // so GET /api/v1/query/{sourceId} reaches 200.
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// geocodeParams are the query parameters of GET /api/v1/geocode.
+type geocodeParams struct {
+	Q     string `query:"q"`
+	Limit int    `query:"limit"`
+	Query bool   `query:"query"`
+}
+
+// handleGeocode resolves an address to places with the configured geocoder
+// (GET /api/v1/geocode?q=). With ?query=true it also runs the point query at
+// the best match and embeds the answer, so an address search takes a single
+// round trip. It is registered only when a geocoder is configured.
+func (s *Server) handleGeocode(w http.ResponseWriter, r *http.Request) {
+	var params geocodeParams
+	if err := decodeQuery(r.URL.Query(), &params); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	matches, err := s.geocoder.Geocode(r.Context(), params.Q, params.Limit)
+	if err != nil {
+		s.handleQueryError(w, r, err)
+		return
+	}
+
+	out := GeocodeResponseDTO{
+		Q:       params.Q,
+		Results: make([]GeocodeResultDTO, len(matches)),
+		Count:   len(matches),
+		License: formatLicense(s.geocoder.License()),
+	}
+	for i, m := range matches {
+		out.Results[i] = GeocodeResultDTO{Label: m.Label, Lon: m.Coordinate.X, Lat: m.Coordinate.Y}
+		if e := m.Extent; e != (domain.Extent{}) {
+			out.Results[i].BBox = &ExtentDTO{MinX: e.MinX, MinY: e.MinY, MaxX: e.MaxX, MaxY: e.MaxY}
+		}
+	}
+
+	if params.Query && len(matches) > 0 {
+		coord := matches[0].Coordinate
+		req := domain.QueryRequest{
+			Coordinate: coord,
+			SourceSRID: coord.SRID,
+			Geometry:   domain.GeometryOptions{Format: domain.GeometryFormatWKT},
+			NoForward:  r.Header.Get(output.ForwardedHeader) != "",
+		}
+		ticket, ok := s.admitQuery(w, r)
+		if !ok {
+			return
+		}
+		defer ticket.release()
+		response, err := s.queryService.QueryPoint(r.Context(), req)
+		if err != nil {
+			s.handleQueryError(w, r, err)
+			return
+		}
+		ticket.charge(estimateResponseBytes(response))
+		q := s.formatQueryResponse(response)
+		q.WGS84 = wgs84Block(coord)
+		q.Gazetteer = s.gazetteerEnrichment(r, coord)
+		out.Query = &q
+	}
+	s.writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/application"
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// fakeGeocoder is a canned geocoder for handler tests. It satisfies both the
+// output port (behind application.GeocodeService) and the input port.
+type fakeGeocoder struct {
+	matches []domain.GeocodeMatch
+	err     error
+}
+
+func (f *fakeGeocoder) Geocode(context.Context, string, int) ([]domain.GeocodeMatch, error) {
+	return f.matches, f.err
+}
+func (f *fakeGeocoder) License() domain.License {
+	return domain.License{Name: "ODbL 1.0", Attribution: "Data © OpenStreetMap contributors"}
+}
+
+// newGeocodeServer wires geo behind the real GeocodeService, so the handler
+// sees its validation and limits.
+func newGeocodeServer(t *testing.T, geo *fakeGeocoder) *Server {
+	srv := newGazetteerServer(t, fakeGazetteer{loc: sampleLocality(), fix: sampleFix()})
+	srv.geocoder = application.NewGeocodeService(geo, 0)
+	return srv
+}
+
+func TestGeocodeEndpoint(t *testing.T) {
+	srv := newGeocodeServer(t, &fakeGeocoder{matches: []domain.GeocodeMatch{
+		{Label: "Residenz, Würzburg", Coordinate: domain.NewWGS84Coordinate(9.9385, 49.7929),
+			Extent: domain.Extent{MinX: 9.936, MinY: 49.7919, MaxX: 9.941, MaxY: 49.794, SRID: domain.SRIDWGS84}},
+		{Label: "Würzburg", Coordinate: domain.NewWGS84Coordinate(9.93, 49.79)},
+	}})
+	rec, body := doGET(t, srv, "/api/v1/geocode?q=Residenz+W%C3%BCrzburg")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if body["q"] != "Residenz Würzburg" || body["count"] != float64(2) {
+		t.Errorf("q, count = %v, %v", body["q"], body["count"])
+	}
+	results, _ := body["results"].([]any)
+	if len(results) != 2 {
+		t.Fatalf("results = %v, want 2", body["results"])
+	}
+	first, _ := results[0].(map[string]any)
+	if first["label"] != "Residenz, Würzburg" || first["lon"] != 9.9385 || first["lat"] != 49.7929 {
+		t.Errorf("first = %v", first)
+	}
+	if bbox, ok := first["bbox"].(map[string]any); !ok || bbox["min_x"] != 9.936 {
+		t.Errorf("first bbox = %v", first["bbox"])
+	}
+	if second, _ := results[1].(map[string]any); second["bbox"] != nil {
+		t.Errorf("second bbox = %v, want omitted", second["bbox"])
+	}
+	if lic, ok := body["license"].(map[string]any); !ok || lic["name"] != "ODbL 1.0" {
+		t.Errorf("license = %v, want the geocoder's", body["license"])
+	}
+	if _, ok := body["query"]; ok {
+		t.Errorf("query = %v, want omitted without ?query=true", body["query"])
+	}
+}
+
+func TestGeocodeEndpointWithQuery(t *testing.T) {
+	srv := newGeocodeServer(t, &fakeGeocoder{matches: []domain.GeocodeMatch{
+		{Label: "Residenz, Würzburg", Coordinate: domain.NewWGS84Coordinate(9.9385, 49.7929)},
+	}})
+	rec, body := doGET(t, srv, "/api/v1/geocode?q=Residenz&query=true")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	query, ok := body["query"].(map[string]any)
+	if !ok {
+		t.Fatalf("query = %v, want the embedded point query", body["query"])
+	}
+	coord, _ := query["coordinate"].(map[string]any)
+	if coord["x"] != 9.9385 || coord["y"] != 49.7929 {
+		t.Errorf("query coordinate = %v, want the best match", query["coordinate"])
+	}
+	if _, ok := query["gazetteer"].(map[string]any); !ok {
+		t.Errorf("query gazetteer = %v, want the enrichment", query["gazetteer"])
+	}
+}
+
+func TestGeocodeEndpointNoMatch(t *testing.T) {
+	srv := newGeocodeServer(t, &fakeGeocoder{})
+	rec, body := doGET(t, srv, "/api/v1/geocode?q=nowhere&query=true")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if results, ok := body["results"].([]any); !ok || len(results) != 0 || body["count"] != float64(0) {
+		t.Errorf("results, count = %v, %v, want an empty list", body["results"], body["count"])
+	}
+	if _, ok := body["query"]; ok {
+		t.Errorf("query = %v, want omitted without a match", body["query"])
+	}
+}
+
+func TestGeocodeEndpointErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		err  error
+		want int
+	}{
+		{"missing q", "/api/v1/geocode", nil, http.StatusBadRequest},
+		{"blank q", "/api/v1/geocode?q=+", nil, http.StatusBadRequest},
+		{"negative limit", "/api/v1/geocode?q=x&limit=-1", nil, http.StatusBadRequest},
+		{"bad limit", "/api/v1/geocode?q=x&limit=many", nil, http.StatusBadRequest},
+		{"unavailable", "/api/v1/geocode?q=x", fmt.Errorf("geocoder nominatim: %w", domain.ErrUnavailable), http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newGeocodeServer(t, &fakeGeocoder{err: tt.err})
+			if rec, _ := doGET(t, srv, tt.path); rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestGeocodeRouteAbsentWhenDisabled(t *testing.T) {
+	srv := newTestServer(nil, nil, nil) // no geocoder wired
+	if rec, _ := doGET(t, srv, "/api/v1/geocode?q=x"); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 (route not registered)", rec.Code)
+	}
+}
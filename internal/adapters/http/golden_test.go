@@ -0,0 +1,265 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/jobrunner/ortus/internal/application"
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// updateGolden regenerates testdata/golden/*.json:
+//
+//	go test ./internal/adapters/http/ -run TestAPIResponseGolden -update-golden
+var updateGolden = flag.Bool("update-golden", false, "rewrite the API response golden files")
+
+// fixtureRepository serves one canned polygon layer per source with a single
+// deterministic feature, so response goldens depend only on the handlers.
+type fixtureRepository struct{}
+
+func (fixtureRepository) Open(_ context.Context, id, path string) (*domain.Source, error) {
+	return &domain.Source{
+		ID:      id,
+		Name:    filepath.Base(path),
+		Path:    path,
+		Size:    4096,
+		Indexed: true,
+		Layers: []domain.Layer{{
+			Name: "districts", GeometryType: "MULTIPOLYGON", GeometryColumn: "geom",
+			SRID: 4326, FeatureCount: 12, HasIndex: true,
+		}},
+		License: domain.License{Name: "dl-de/by-2-0", URL: "https://www.govdata.de/dl-de/by-2-0", Attribution: "© GeoBasis-DE"},
+	}, nil
+}
+
+func (fixtureRepository) Close(context.Context, string) error           { return nil }
+func (fixtureRepository) Supports(string) bool                          { return true }
+func (fixtureRepository) Prepare(context.Context, string, string) error { return nil }
+
+func (fixtureRepository) GetLayers(context.Context, string) ([]domain.Layer, error) {
+	return nil, nil
+}
+
+func (r fixtureRepository) QueryPoint(ctx context.Context, sourceID, layer string, coord domain.Coordinate) ([]domain.Feature, error) {
+	return r.QueryPointEncoded(ctx, sourceID, layer, coord, domain.GeometryOptions{})
+}
+
+func (fixtureRepository) QueryPointEncoded(_ context.Context, _, layer string, _ domain.Coordinate, opts domain.GeometryOptions) ([]domain.Feature, error) {
+	g := domain.Geometry{Type: "POLYGON", WKT: "POLYGON((9 49,10 49,10 50,9 50,9 49))"}
+	if opts.Simplify > 0 {
+		g.WKT = "POLYGON((9 49,10 50,9 50,9 49))"
+	}
+	switch opts.Format {
+	case domain.GeometryFormatWKB:
+		g.WKB = []byte{0x01, 0x03, 0x00, 0x00, 0x00}
+	case domain.GeometryFormatGeoJSON:
+		g.GeoJSON = `{"type":"Polygon","coordinates":[[[9,49],[10,49],[10,50],[9,50],[9,49]]]}`
+	case domain.GeometryFormatGML:
+		g.GML = `<gml:Polygon srsName="EPSG:4326"/>`
+	}
+	return []domain.Feature{{
+		ID:         42,
+		LayerName:  layer,
+		Geometry:   g,
+		Properties: map[string]interface{}{"name": "Würzburg", "population": int64(127934)},
+	}}, nil
+}
+
+func (fixtureRepository) CreateSpatialIndex(context.Context, string, string) error { return nil }
+func (fixtureRepository) HasSpatialIndex(context.Context, string, string) (bool, error) {
+	return true, nil
+}
+
+// newGoldenServer wires the real services over fixtureRepository with the
+// districts and parcels fixture sources loaded, geometries enabled and a
+// canned gazetteer.
+func newGoldenServer(t *testing.T) *Server {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	reg := application.NewSourceRegistry(
+		[]output.SpatialSource{fixtureRepository{}}, &mockStorage{},
+		noop.NewMeterProvider().Meter("test"), output.NoOpTracer{}, logger, "/tmp",
+	)
+	ctx := context.Background()
+	_ = reg.LoadAll(ctx)
+	for _, p := range []string{"/tmp/districts.gpkg", "/tmp/parcels.gpkg"} {
+		if err := reg.LoadSource(ctx, p); err != nil {
+			t.Fatalf("LoadSource(%q): %v", p, err)
+		}
+	}
+	health := application.NewHealthService(reg, true, output.NoOpTracer{})
+	query := application.NewQueryService(reg, nil, noop.NewMeterProvider().Meter("test"),
+		output.NoOpTracer{}, logger, application.QueryServiceConfig{})
+	return NewServer(
+		config.ServerConfig{Host: "localhost", Port: 8080, ReadTimeout: time.Second, WriteTimeout: time.Second},
+		query, reg, health, nil, logger, true,
+		ServerOptions{
+			Gazetteer:        fakeGazetteer{loc: sampleLocality(), fix: sampleFix()},
+			GazetteerLicense: sampleGazetteerLicense(),
+		},
+	)
+}
+
+// volatileKeys are response fields whose values depend on wall-clock time;
+// normalizeGolden replaces them so the goldens pin shape, not timing.
+var volatileKeys = map[string]bool{
+	"loaded_at":    true,
+	"last_queried": true,
+	"timestamp":    true,
+	"uptime":       true,
+}
+
+func normalizeGolden(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if volatileKeys[k] || strings.HasSuffix(k, "_ms") {
+				t[k] = "<volatile>"
+				continue
+			}
+			t[k] = normalizeGolden(val)
+		}
+	case []any:
+		for i := range t {
+			t[i] = normalizeGolden(t[i])
+		}
+	}
+	return v
+}
+
+// goldenBody decodes a JSON or NDJSON response body into a normalized value.
+// NDJSON becomes an array of its lines so the framing is pinned as well; CSV
+// is kept verbatim as an array of its lines.
+func goldenBody(t *testing.T, contentType string, body []byte) any {
+	t.Helper()
+	if strings.HasPrefix(contentType, "text/csv") {
+		return strings.Split(strings.TrimRight(string(body), "\n"), "\n")
+	}
+	if strings.HasPrefix(contentType, "application/x-ndjson") {
+		var lines []any
+		for _, line := range bytes.Split(bytes.TrimSpace(body), []byte("\n")) {
+			var v any
+			if err := json.Unmarshal(line, &v); err != nil {
+				t.Fatalf("ndjson line %q: %v", line, err)
+			}
+			lines = append(lines, normalizeGolden(v))
+		}
+		return lines
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		t.Fatalf("response is not JSON (%s): %v\n%s", contentType, err, body)
+	}
+	return normalizeGolden(v)
+}
+
+// TestAPIResponseGolden freezes the canonical responses of every /api/v1
+// endpoint and the health endpoints — across geometry formats, the json, csv
+// and ndjson query formats, the batch JSON and NDJSON deliveries, and the error
+// envelope — against fixture sources.
+// Consumers parse these layouts field by field, so any drift must be a
+// deliberate, reviewed golden update.
+func TestAPIResponseGolden(t *testing.T) {
+	srv := newGoldenServer(t)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		accept string
+	}{
+		{name: "query", path: "/api/v1/query?lon=9.93&lat=49.79"},
+		{name: "query_properties", path: "/api/v1/query?lon=9.93&lat=49.79&properties=name"},
+		{name: "query_geometry_wkb", path: "/api/v1/query?lon=9.93&lat=49.79&geometry_format=wkb"},
+		{name: "query_geometry_geojson", path: "/api/v1/query?lon=9.93&lat=49.79&geometry_format=geojson"},
+		{name: "query_geometry_gml", path: "/api/v1/query?lon=9.93&lat=49.79&geometry_format=gml"},
+		{name: "query_simplify", path: "/api/v1/query?lon=9.93&lat=49.79&simplify=0.5"},
+		{name: "query_with_gazetteer", path: "/api/v1/query?lon=9.93&lat=49.79&with-gazetteer=true"},
+		{name: "query_csv", path: "/api/v1/query?lon=9.93&lat=49.79&format=csv"},
+		{name: "query_ndjson", path: "/api/v1/query?lon=9.93&lat=49.79&format=ndjson"},
+		{name: "query_source", path: "/api/v1/query/districts?lon=9.93&lat=49.79"},
+		{name: "query_source_csv", path: "/api/v1/query/districts?lon=9.93&lat=49.79&format=csv&properties=name"},
+		{name: "query_source_not_found", path: "/api/v1/query/missing?lon=9.93&lat=49.79"},
+		{name: "query_bad_request", path: "/api/v1/query?lon=9.93&lat=49.79&geometry_format=kml"},
+		{
+			name: "query_batch", method: http.MethodPost, path: "/api/v1/query/batch",
+			body: `{"sources":["districts"],"points":[{"id":"a","lon":9.93,"lat":49.79},{"id":"b","lon":9.5,"lat":49.5}]}`,
+		},
+		{
+			name: "query_batch_ndjson", method: http.MethodPost, path: "/api/v1/query/batch",
+			body:   `{"sources":["districts"],"points":[{"id":"a","lon":9.93,"lat":49.79},{"id":"b","lon":9.5,"lat":49.5}]}`,
+			accept: "application/x-ndjson",
+		},
+		{name: "gazetteer", path: "/api/v1/gazetteer?lon=9.93&lat=49.79"},
+		{name: "sources", path: "/api/v1/sources"},
+		{name: "source", path: "/api/v1/sources/districts"},
+		{name: "source_layers", path: "/api/v1/sources/districts/layers"},
+		{name: "health", path: "/health"},
+		{name: "health_live", path: "/health/live"},
+		{name: "health_ready", path: "/health/ready"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tc.path, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			srv.Router().ServeHTTP(rec, req)
+
+			contentType := rec.Header().Get("Content-Type")
+			got, err := json.MarshalIndent(map[string]any{
+				"status":       rec.Code,
+				"content_type": contentType,
+				"body":         goldenBody(t, contentType, rec.Body.Bytes()),
+			}, "", "  ")
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+
+			golden := filepath.Join("testdata", "golden", tc.name+".json")
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(golden), 0o750); err != nil {
+					t.Fatalf("mkdir: %v", err)
+				}
+				if err := os.WriteFile(golden, append(got, '\n'), 0o600); err != nil {
+					t.Fatalf("write golden: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden (run with -update-golden to create): %v", err)
+			}
+			if !bytes.Equal(bytes.TrimSpace(want), bytes.TrimSpace(got)) {
+				t.Errorf("response drift for %s %s.\nIf intended, regenerate:\n"+
+					"  go test ./internal/adapters/http/ -run TestAPIResponseGolden -update-golden\n\n--- got ---\n%s",
+					method, tc.path, got)
+			}
+		})
+	}
+}
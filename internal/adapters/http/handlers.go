@@ -2,32 +2,62 @@ package http
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
 
+	"github.com/jobrunner/ortus/internal/config"
 	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+	"github.com/jobrunner/ortus/internal/ports/output"
 )
 
+// Axis conventions of the coords parameter.
+const (
+	coordsLonLat = "lonlat"
+	coordsXY     = "xy"
+)
+
+// CoordinateParams are the query parameters that locate a point, either
+// lon/lat or x/y in srid, or a geohash or Plus Code standing for a WGS84
+// cell. The pairs are pointers so that a coordinate at 0 is told apart from
+// a missing one.
+type CoordinateParams struct {
+	Lon      *float64 `json:"lon,omitempty" query:"lon"`
+	Lat      *float64 `json:"lat,omitempty" query:"lat"`
+	X        *float64 `json:"x,omitempty" query:"x"`
+	Y        *float64 `json:"y,omitempty" query:"y"`
+	SRID     int      `json:"srid" query:"srid"`
+	Coords   string   `json:"coords,omitempty" query:"coords"`
+	Geohash  string   `json:"geohash,omitempty" query:"geohash"`
+	PlusCode string   `json:"pluscode,omitempty" query:"pluscode"`
+}
+
 // QueryParams represents the query parameters for a point query.
 type QueryParams struct {
-	Lon        float64  `json:"lon"`
-	Lat        float64  `json:"lat"`
-	X          float64  `json:"x"`
-	Y          float64  `json:"y"`
-	SRID       int      `json:"srid"`
-	Properties []string `json:"properties,omitempty"`
+	CoordinateParams
+	Z              float64               `json:"z,omitempty" query:"z"`
+	Properties     []string              `json:"properties,omitempty" query:"properties"`
+	GeometryFormat domain.GeometryFormat `json:"geometry_format,omitempty" query:"geometry_format"`
+	Simplify       float64               `json:"simplify,omitempty" query:"simplify"`
+	Format         string                `json:"format,omitempty" query:"format"`
+	Explain        bool                  `json:"explain,omitempty" query:"explain"`
 }
 
 // handleQuery handles point queries across all sources.
 func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 	params, err := s.parseQueryParams(r)
 	if err != nil {
-		s.writeError(w, http.StatusBadRequest, err.Error())
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -35,15 +65,32 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 		Coordinate: s.paramsToCoordinate(params),
 		SourceSRID: params.SRID,
 		Properties: params.Properties,
+		Geometry:   geometryOptions(params),
+		NoForward:  r.Header.Get(output.ForwardedHeader) != "",
 	}
 
-	response, err := s.queryService.QueryPoint(r.Context(), req)
+	ctx, explain, ok := s.startExplain(w, r, params)
+	if !ok {
+		return
+	}
+	ticket, ok := s.admitQuery(w, r)
+	if !ok {
+		return
+	}
+	defer ticket.release()
+	response, err := s.queryService.QueryPoint(ctx, req)
 	if err != nil {
-		s.handleQueryError(w, err)
+		s.handleQueryError(w, r, err)
+		return
+	}
+	ticket.charge(estimateResponseBytes(response))
+	if params.Format != formatJSON {
+		s.writeQueryExport(w, r, params.Format, response)
 		return
 	}
 
 	out := s.formatQueryResponse(response)
+	out.Explain = formatExplain(explain)
 	// Reproject the query point to WGS84 once (see wgs84OrLog): it powers the wgs84
 	// block (a geographic coordinate other services can compute with / store) and
 	// the gazetteer enrichment — the gazetteer dataset is EPSG:4326, so a non-4326
@@ -52,8 +99,8 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 	// pair) or the transform itself failed — and then both blocks are omitted while
 	// the core query result still returns.
 	if wgs, ok := s.wgs84OrLog(r, req.Coordinate); ok {
-		out["wgs84"] = wgs84Block(wgs)
-		s.attachGazetteer(r, wgs, out)
+		out.WGS84 = wgs84Block(wgs)
+		out.Gazetteer = s.gazetteerEnrichment(r, wgs)
 	}
 	s.writeJSON(w, http.StatusOK, out)
 }
@@ -65,7 +112,7 @@ func (s *Server) handleQuerySource(w http.ResponseWriter, r *http.Request) {
 
 	params, err := s.parseQueryParams(r)
 	if err != nil {
-		s.writeError(w, http.StatusBadRequest, err.Error())
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -74,19 +121,36 @@ func (s *Server) handleQuerySource(w http.ResponseWriter, r *http.Request) {
 		SourceSRID: params.SRID,
 		Properties: params.Properties,
 		SourceID:   sourceID,
+		Geometry:   geometryOptions(params),
+		NoForward:  r.Header.Get(output.ForwardedHeader) != "",
 	}
 
-	response, err := s.queryService.QueryPoint(r.Context(), req)
+	ctx, explain, ok := s.startExplain(w, r, params)
+	if !ok {
+		return
+	}
+	ticket, ok := s.admitQuery(w, r)
+	if !ok {
+		return
+	}
+	defer ticket.release()
+	response, err := s.queryService.QueryPoint(ctx, req)
 	if err != nil {
-		s.handleQueryError(w, err)
+		s.handleQueryError(w, r, err)
+		return
+	}
+	ticket.charge(estimateResponseBytes(response))
+	if params.Format != formatJSON {
+		s.writeQueryExport(w, r, params.Format, response)
 		return
 	}
 
 	out := s.formatQueryResponse(response)
+	out.Explain = formatExplain(explain)
 	// The wgs84 block travels on every query response (single-source too), even
 	// though single-source queries don't attach the gazetteer block.
 	if wgs, ok := s.wgs84OrLog(r, req.Coordinate); ok {
-		out["wgs84"] = wgs84Block(wgs)
+		out.WGS84 = wgs84Block(wgs)
 	}
 	s.writeJSON(w, http.StatusOK, out)
 }
@@ -107,6 +171,7 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		"sources_ready":  details.SourcesReady,
 		"sources":        details.Sources,
 		"components":     details.Components,
+		"load":           details.Load,
 	})
 }
 
@@ -121,6 +186,12 @@ func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
 
 // handleReadiness returns readiness status.
 func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	if s.maintenance != nil && s.maintenance.MaintenanceStatus().Active {
+		// Same 503 the health service would give, with the reason spelled out
+		// for whoever is watching the drain.
+		s.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "maintenance"})
+		return
+	}
 	if s.health.IsReady(r.Context()) {
 		s.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 	} else {
@@ -128,22 +199,42 @@ func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleStartup returns startup status: 200 once the startup pass has ended,
+// 503 while it runs, so a Kubernetes startupProbe holds off the liveness probe
+// through a long initial indexing run.
+func (s *Server) handleStartup(w http.ResponseWriter, r *http.Request) {
+	st := s.health.StartupStatus(r.Context())
+	if st.Started {
+		s.writeJSON(w, http.StatusOK, map[string]string{"status": "started"})
+		return
+	}
+	status := "starting"
+	if st.TimedOut {
+		status = "timeout"
+	}
+	s.writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+		"status":          status,
+		"elapsed_seconds": int(st.Elapsed.Seconds()),
+		"load":            st.Load,
+	})
+}
+
 // handleListSources returns all registered sources.
 func (s *Server) handleListSources(w http.ResponseWriter, r *http.Request) {
 	sources, err := s.registry.ListSources(r.Context())
 	if err != nil {
-		s.writeError(w, http.StatusInternalServerError, "Failed to list sources")
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to list sources")
 		return
 	}
 
-	response := make([]map[string]interface{}, len(sources))
+	response := make([]SourceDTO, len(sources))
 	for i := range sources {
 		response[i] = s.formatSource(&sources[i])
 	}
 
-	s.writeJSON(w, http.StatusOK, map[string]interface{}{
-		"sources": response,
-		"count":   len(sources),
+	s.writeJSON(w, http.StatusOK, SourceListDTO{
+		Sources: response,
+		Count:   len(sources),
 	})
 }
 
@@ -155,10 +246,10 @@ func (s *Server) handleGetSource(w http.ResponseWriter, r *http.Request) {
 	pkg, err := s.registry.GetSource(r.Context(), sourceID)
 	if err != nil {
 		if errors.Is(err, domain.ErrSourceNotFound) {
-			s.writeError(w, http.StatusNotFound, "Source not found")
+			s.writeError(w, r, http.StatusNotFound, "Source not found")
 			return
 		}
-		s.writeError(w, http.StatusInternalServerError, "Failed to get source")
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to get source")
 		return
 	}
 
@@ -173,207 +264,338 @@ func (s *Server) handleGetLayers(w http.ResponseWriter, r *http.Request) {
 	pkg, err := s.registry.GetSource(r.Context(), sourceID)
 	if err != nil {
 		if errors.Is(err, domain.ErrSourceNotFound) {
-			s.writeError(w, http.StatusNotFound, "Source not found")
+			s.writeError(w, r, http.StatusNotFound, "Source not found")
 			return
 		}
-		s.writeError(w, http.StatusInternalServerError, "Failed to get source")
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to get source")
 		return
 	}
 
-	layers := make([]map[string]interface{}, len(pkg.Layers))
+	layers := make([]LayerDTO, len(pkg.Layers))
 	for i, l := range pkg.Layers {
-		layers[i] = map[string]interface{}{
-			"name":            l.Name,
-			"description":     l.Description,
-			"geometry_type":   l.GeometryType,
-			"geometry_column": l.GeometryColumn,
-			"srid":            l.SRID,
-			"has_index":       l.HasIndex,
-			"feature_count":   l.FeatureCount,
-		}
-		if l.Extent != nil {
-			layers[i]["extent"] = map[string]interface{}{
-				"min_x": l.Extent.MinX,
-				"min_y": l.Extent.MinY,
-				"max_x": l.Extent.MaxX,
-				"max_y": l.Extent.MaxY,
-			}
+		layers[i] = LayerDTO{
+			Name:           l.Name,
+			Description:    l.Description,
+			GeometryType:   l.GeometryType,
+			GeometryColumn: l.GeometryColumn,
+			SRID:           l.SRID,
+			HasIndex:       l.HasIndex,
+			FeatureCount:   l.FeatureCount,
+			Extent:         formatExtent(l.Extent),
 		}
 	}
 
-	s.writeJSON(w, http.StatusOK, map[string]interface{}{
-		"source_id": sourceID,
-		"layers":    layers,
-		"count":     len(layers),
+	s.writeJSON(w, http.StatusOK, LayerListDTO{
+		SourceID: sourceID,
+		Layers:   layers,
+		Count:    len(layers),
 	})
 }
 
-// handleOpenAPI returns the OpenAPI specification.
-func (s *Server) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
-	spec, err := getOpenAPIJSON()
+// handleOpenAPI returns the OpenAPI document of this server (see
+// openAPIDocument).
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	doc, err := s.openAPIDocument(r)
 	if err != nil {
 		s.logger.Error("failed to get OpenAPI spec", "error", err)
-		s.writeError(w, http.StatusInternalServerError, "Failed to load OpenAPI specification")
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to load OpenAPI specification")
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	_, _ = w.Write(spec)
+	s.writeJSON(w, http.StatusOK, doc)
+}
+
+// geometryOptions returns the geometry options of a point query. The geojson
+// response format embeds GeoJSON geometries, whatever geometry_format says.
+func geometryOptions(params *QueryParams) domain.GeometryOptions {
+	opts := domain.GeometryOptions{Format: params.GeometryFormat, Simplify: params.Simplify}
+	if params.Format == formatGeoJSON {
+		opts.Format = domain.GeometryFormatGeoJSON
+	}
+	return opts
 }
 
 // parseQueryParams parses query parameters from the request.
 func (s *Server) parseQueryParams(r *http.Request) (*QueryParams, error) {
-	params := &QueryParams{
-		SRID: domain.SRIDWGS84, // Default
+	params := &QueryParams{}
+	params.SRID = domain.SRIDWGS84 // Default
+	if err := decodeQuery(r.URL.Query(), params); err != nil {
+		return nil, err
+	}
+	if err := params.validateCoordinates(); err != nil {
+		return nil, err
 	}
 
-	q := r.URL.Query()
+	format, err := domain.ParseGeometryFormat(string(params.GeometryFormat))
+	if err != nil {
+		return nil, errors.New("invalid geometry_format parameter (wkt, wkb, geojson, gml)")
+	}
+	params.GeometryFormat = format
+	// An encoding on top of WKT is pointless when no geometry is returned.
+	// A forwarded query is spared: the origin asked for it, and this peer's
+	// features are still worth more without geometry than not at all.
+	if !s.withGeometry && format != domain.GeometryFormatWKT && r.Header.Get(output.ForwardedHeader) == "" {
+		return nil, errors.New("geometry_format needs geometries, which this server does not return (query.with_geometry)")
+	}
 
-	// Parse coordinates (lon/lat or x/y)
-	if lon := q.Get("lon"); lon != "" {
-		v, err := strconv.ParseFloat(lon, 64)
-		if err != nil {
-			return nil, errors.New("invalid lon parameter")
-		}
-		params.Lon = v
+	if (domain.GeometryOptions{Simplify: params.Simplify}).Validate() != nil {
+		return nil, errors.New("invalid simplify parameter (non-negative tolerance in layer CRS units)")
 	}
 
-	if lat := q.Get("lat"); lat != "" {
-		v, err := strconv.ParseFloat(lat, 64)
-		if err != nil {
-			return nil, errors.New("invalid lat parameter")
-		}
-		params.Lat = v
+	params.Format, err = parseResponseFormat(params.Format)
+	if err != nil {
+		return nil, err
 	}
 
-	if x := q.Get("x"); x != "" {
-		v, err := strconv.ParseFloat(x, 64)
-		if err != nil {
-			return nil, errors.New("invalid x parameter")
-		}
-		params.X = v
+	return params, nil
+}
+
+// parseCoordinateParams parses the coordinate query parameters alone.
+func parseCoordinateParams(r *http.Request) (*CoordinateParams, error) {
+	params := &CoordinateParams{SRID: domain.SRIDWGS84}
+	if err := decodeQuery(r.URL.Query(), params); err != nil {
+		return nil, err
+	}
+	if err := params.validateCoordinates(); err != nil {
+		return nil, err
 	}
+	return params, nil
+}
 
-	if y := q.Get("y"); y != "" {
-		v, err := strconv.ParseFloat(y, 64)
-		if err != nil {
-			return nil, errors.New("invalid y parameter")
+// validateCoordinates checks that a point was given and settles Coords, the
+// convention it is read in: lon/lat when those are given, x/y otherwise,
+// unless coords names one. lon/lat in an SRID known to be projected is
+// refused rather than queried as metres next to the false origin; coords=xy
+// reads such values as x/y. A geohash or Plus Code is decoded into lon/lat.
+func (p *CoordinateParams) validateCoordinates() error {
+	if p.Geohash != "" || p.PlusCode != "" {
+		return p.decodeCell()
+	}
+	if (p.Lon == nil) != (p.Lat == nil) {
+		return errors.New("lon and lat must be given together")
+	}
+	if (p.X == nil) != (p.Y == nil) {
+		return errors.New("x and y must be given together")
+	}
+	if p.Lon == nil && p.X == nil {
+		return errors.New("coordinates required: use lon/lat, x/y, geohash or pluscode")
+	}
+	switch p.Coords {
+	case "":
+		p.Coords = coordsXY
+		if p.Lon != nil {
+			p.Coords = coordsLonLat
 		}
-		params.Y = v
+	case coordsLonLat, coordsXY:
+	default:
+		return errors.New("invalid coords parameter (lonlat, xy)")
 	}
-
-	// Validate that we have coordinates
-	if params.Lon == 0 && params.Lat == 0 && params.X == 0 && params.Y == 0 {
-		return nil, errors.New("coordinates required: use lon/lat or x/y")
+	if _, projected := domain.ProjectedBounds(p.SRID); projected && p.Coords == coordsLonLat {
+		return fmt.Errorf("EPSG:%d is projected: give x/y instead of lon/lat, or coords=xy if the values are eastings and northings", p.SRID)
 	}
+	return nil
+}
+
+// paramsToCoordinate converts query params to a coordinate.
+func (s *Server) paramsToCoordinate(params *QueryParams) domain.Coordinate {
+	c := params.Coordinate()
+	c.Z = params.Z
+	return c
+}
 
-	// Parse SRID
-	if srid := q.Get("srid"); srid != "" {
-		v, err := strconv.Atoi(srid)
-		if err != nil {
-			return nil, errors.New("invalid srid parameter")
+// decodeCell sets lon/lat to the center of the cell the geohash or Plus Code
+// stands for. Both are WGS84, so they take no other point parameter and no
+// srid but 4326.
+func (p *CoordinateParams) decodeCell() error {
+	if p.Geohash != "" && p.PlusCode != "" || p.Lon != nil || p.Lat != nil || p.X != nil || p.Y != nil || p.Coords != "" {
+		return errors.New("geohash and pluscode locate the point on their own: give one of lon/lat, x/y, geohash or pluscode")
+	}
+	if p.SRID != domain.SRIDWGS84 {
+		return errors.New("geohash and pluscode are WGS84: srid must be 4326")
+	}
+	var cell domain.Extent
+	var err error
+	if p.Geohash != "" {
+		cell, err = domain.DecodeGeohash(p.Geohash)
+	} else {
+		// An unescaped '+' arrives as a space; no Plus Code holds one.
+		cell, err = domain.DecodePlusCode(strings.ReplaceAll(p.PlusCode, " ", "+"))
+	}
+	if err != nil {
+		var verr *domain.ValidationError
+		if errors.As(err, &verr) {
+			return fmt.Errorf("invalid %s parameter: %s", verr.Field, verr.Message)
 		}
-		params.SRID = v
+		return err
 	}
+	center := cell.Center()
+	p.Lon, p.Lat, p.Coords = &center.X, &center.Y, coordsLonLat
+	return nil
+}
 
-	// Parse properties filter
-	if props := q.Get("properties"); props != "" {
-		params.Properties = strings.Split(props, ",")
+// Coordinate returns the point the parameters locate: from the pair Coords
+// names if it is given, from the other pair otherwise. Without Coords lon/lat
+// wins if both are set.
+func (p *CoordinateParams) Coordinate() domain.Coordinate {
+	x, y := p.Lon, p.Lat
+	if (p.Coords == coordsXY || x == nil) && p.X != nil {
+		x, y = p.X, p.Y
 	}
+	if x == nil || y == nil {
+		return domain.Coordinate{SRID: p.SRID}
+	}
+	return domain.Coordinate{X: *x, Y: *y, SRID: p.SRID}
+}
 
-	return params, nil
+// formatQueryResponse formats the query response for JSON output.
+func (s *Server) formatQueryResponse(resp *domain.QueryResponse) QueryResponseDTO {
+	return FormatQueryResponse(resp, s.withGeometry)
 }
 
-// paramsToCoordinate converts query params to a coordinate.
-func (s *Server) paramsToCoordinate(params *QueryParams) domain.Coordinate {
-	// Prefer lon/lat if both are set
-	if params.Lon != 0 || params.Lat != 0 {
-		return domain.Coordinate{
-			X:    params.Lon,
-			Y:    params.Lat,
-			SRID: params.SRID,
+// jsonSafeProperties returns props with NaN and ±Inf floats, which JSON
+// cannot represent, replaced by null. encoding/json refuses such a value,
+// and a response failing half-way, after its status line, reaches the client
+// truncated. props itself is returned when it holds none.
+func jsonSafeProperties(props map[string]interface{}) map[string]interface{} {
+	var safe map[string]interface{}
+	for k, v := range props {
+		if f, ok := v.(float64); !ok || (!math.IsNaN(f) && !math.IsInf(f, 0)) {
+			continue
+		}
+		if safe == nil {
+			safe = maps.Clone(props)
 		}
+		safe[k] = nil
 	}
-	return domain.Coordinate{
-		X:    params.X,
-		Y:    params.Y,
-		SRID: params.SRID,
+	if safe == nil {
+		return props
 	}
+	return safe
 }
 
-// formatQueryResponse formats the query response for JSON output.
-func (s *Server) formatQueryResponse(resp *domain.QueryResponse) map[string]interface{} {
-	results := make([]map[string]interface{}, len(resp.Results))
+// FormatQueryResponse renders a query response in the /api/v1/query JSON
+// shape. withGeometry adds each feature's geometry. The CLI's query command
+// prints the same shape.
+func FormatQueryResponse(resp *domain.QueryResponse, withGeometry bool) QueryResponseDTO {
+	results := make([]QueryResultDTO, len(resp.Results))
 	for i := range resp.Results {
 		r := &resp.Results[i]
-		features := make([]map[string]interface{}, len(r.Features))
+		features := make([]FeatureDTO, len(r.Features))
 		for j := range r.Features {
 			f := &r.Features[j]
-			features[j] = map[string]interface{}{
-				"id":         f.ID,
-				"layer":      f.LayerName,
-				"properties": f.Properties,
+			features[j] = FeatureDTO{
+				ID:         f.ID,
+				Layer:      f.LayerName,
+				Properties: jsonSafeProperties(f.Properties),
+			}
+			if m := f.Match; m.Kind != "" {
+				features[j].Match = &MatchDTO{Kind: string(m.Kind), DistanceM: m.DistanceM, SRID: m.SRID}
 			}
 			// Only include geometry if explicitly enabled via --with-geometry or ORTUS_RESULTS_WITH_GEOMETRY
-			if s.withGeometry && f.Geometry.WKT != "" {
-				features[j]["geometry"] = map[string]interface{}{
-					"type": f.Geometry.Type,
-					"wkt":  f.Geometry.WKT,
-				}
+			if withGeometry && f.Geometry.WKT != "" {
+				g := formatGeometry(&f.Geometry)
+				features[j].Geometry = &g
 			}
 		}
 
-		results[i] = map[string]interface{}{
-			"source_id":     r.SourceID,
-			"source_name":   r.SourceName,
-			"features":      features,
-			"feature_count": r.FeatureCount(),
-			"query_time_ms": r.QueryTime.Milliseconds(),
+		results[i] = QueryResultDTO{
+			SourceID:     r.SourceID,
+			SourceName:   r.SourceName,
+			Features:     features,
+			FeatureCount: r.FeatureCount(),
+			QueryTimeMS:  r.QueryTime.Milliseconds(),
+			License:      formatLicense(r.License),
+			Incomplete:   r.Incomplete,
+			Peer:         r.Peer,
 		}
-
-		if !r.License.IsEmpty() {
-			results[i]["license"] = map[string]interface{}{
-				"name":        r.License.Name,
-				"url":         r.License.URL,
-				"attribution": r.License.Attribution,
-			}
+		for _, n := range r.Notes {
+			results[i].Notes = append(results[i].Notes, NoteDTO{Layer: n.Layer, Text: n.Text})
 		}
 	}
 
-	return map[string]interface{}{
-		"coordinate": map[string]interface{}{
-			"x":    resp.Coordinate.X,
-			"y":    resp.Coordinate.Y,
-			"srid": resp.Coordinate.SRID,
+	out := QueryResponseDTO{
+		Coordinate: CoordinateDTO{
+			X:    resp.Coordinate.X,
+			Y:    resp.Coordinate.Y,
+			Z:    resp.Coordinate.Z,
+			SRID: resp.Coordinate.SRID,
 		},
-		"results":            results,
-		"total_features":     resp.TotalFeatures,
-		"processing_time_ms": resp.ProcessingTime.Milliseconds(),
-	}
-}
-
-// formatSource formats a source for JSON output.
-func (s *Server) formatSource(pkg *domain.Source) map[string]interface{} {
-	out := map[string]interface{}{
-		"id":           pkg.ID,
-		"name":         pkg.Name,
-		"path":         pkg.Path,
-		"size":         pkg.Size,
-		"layer_count":  pkg.LayerCount(),
-		"indexed":      pkg.Indexed,
-		"ready":        pkg.IsReady(),
-		"loaded_at":    pkg.LoadedAt,
-		"last_queried": pkg.LastQueried,
-	}
-	if !pkg.License.IsEmpty() {
-		out["license"] = map[string]interface{}{
-			"name":        pkg.License.Name,
-			"url":         pkg.License.URL,
-			"attribution": pkg.License.Attribution,
-		}
+		Results:          results,
+		TotalFeatures:    resp.TotalFeatures,
+		ProcessingTimeMS: resp.ProcessingTime.Milliseconds(),
+		// Only present when the query deadline cut the search short, so
+		// complete responses keep their shape.
+		Incomplete: resp.Incomplete,
+	}
+	// Only present when federation forwarded the query.
+	for _, p := range resp.Peers {
+		out.Peers = append(out.Peers, PeerStatusDTO{
+			Name:         p.Peer,
+			Status:       p.Status,
+			DurationMS:   p.Duration.Milliseconds(),
+			FeatureCount: p.Features,
+			Incomplete:   p.Incomplete,
+		})
+	}
+	return out
+}
+
+// formatGeometry renders a feature geometry: the WKT always, plus whichever
+// extra encoding the query requested (?geometry_format). WKB is hex-encoded,
+// the form PostGIS accepts directly as a geometry literal.
+func formatGeometry(g *domain.Geometry) GeometryDTO {
+	out := GeometryDTO{Type: g.Type, WKT: g.WKT, GML: g.GML}
+	if len(g.WKB) > 0 {
+		out.WKB = hex.EncodeToString(g.WKB)
+	}
+	if g.GeoJSON != "" && json.Valid([]byte(g.GeoJSON)) {
+		out.GeoJSON = json.RawMessage(g.GeoJSON)
 	}
 	return out
 }
 
+// formatLicense returns the license block, or nil when there is no license.
+func formatLicense(l domain.License) *LicenseDTO {
+	if l.IsEmpty() {
+		return nil
+	}
+	return &LicenseDTO{Name: l.Name, URL: l.URL, Attribution: l.Attribution}
+}
+
+// formatExtent returns the extent block, or nil for an unknown extent.
+func formatExtent(e *domain.Extent) *ExtentDTO {
+	if e == nil {
+		return nil
+	}
+	return &ExtentDTO{MinX: e.MinX, MinY: e.MinY, MaxX: e.MaxX, MaxY: e.MaxY}
+}
+
+// formatSource formats a source for JSON output. Of the ISO 19139 citation
+// fields (title, creator, dates, constraints) each appears only when set.
+func (s *Server) formatSource(pkg *domain.Source) SourceDTO {
+	md := &pkg.Metadata
+	return SourceDTO{
+		ID:          pkg.ID,
+		Name:        pkg.Name,
+		Path:        pkg.Path,
+		Size:        pkg.Size,
+		LayerCount:  pkg.LayerCount(),
+		Indexed:     pkg.Indexed,
+		Ready:       pkg.IsReady(),
+		LoadedAt:    pkg.LoadedAt,
+		LastQueried: pkg.LastQueried,
+		License:     formatLicense(pkg.License),
+		Description: md.Description,
+		Keywords:    md.Keywords,
+		Title:       md.Title,
+		Creator:     md.Creator,
+		CreatedAt:   md.CreatedAt,
+		UpdatedAt:   md.UpdatedAt,
+		Constraints: md.Constraints,
+		Tags:        pkg.Tags,
+		TileSets:    formatTileSets(pkg.ID, pkg.TileSets),
+	}
+}
+
 // StatusClientClosedRequest is the non-standard 499 status (nginx convention)
 // used when the client cancels the request mid-query — net/http has no constant
 // for it, and no standard 4xx fits "the caller went away".
@@ -384,57 +606,120 @@ const StatusClientClosedRequest = 499
 // / ErrUnavailable), so the specific errors that wrap them (ErrInvalidCoordinate,
 // ErrInvalidSRID, ErrUnsupportedProjection, StorageError, …) are classified
 // correctly instead of all falling through to 500.
-func (s *Server) handleQueryError(w http.ResponseWriter, err error) {
+func (s *Server) handleQueryError(w http.ResponseWriter, r *http.Request, err error) {
 	var validationErr *domain.ValidationError
 	var storageErr *domain.StorageError
+	var outsideErr *domain.OutsideExtentError
 	switch {
 	case errors.As(err, &validationErr):
-		s.writeError(w, http.StatusBadRequest, validationErr.Message)
+		s.writeError(w, r, http.StatusBadRequest, validationErr.Message)
+	case errors.As(err, &outsideErr):
+		// Strict extent mode: tell "wrong place" apart from "no data here" and
+		// show where data actually is.
+		e := outsideErr.Extent
+		s.writeErrorDetail(w, r, http.StatusUnprocessableEntity, "Query point is outside the extent of all loaded data", errorDetail{
+			extent: &DataExtentDTO{
+				ExtentDTO: ExtentDTO{MinX: e.MinX, MinY: e.MinY, MaxX: e.MaxX, MaxY: e.MaxY},
+				SRID:      e.SRID,
+			},
+		})
 	case errors.Is(err, domain.ErrSourceNotFound):
-		s.writeError(w, http.StatusNotFound, "Source not found")
+		s.writeError(w, r, http.StatusNotFound, "Source not found")
 	case errors.Is(err, domain.ErrLayerNotFound):
-		s.writeError(w, http.StatusNotFound, "Layer not found")
+		s.writeError(w, r, http.StatusNotFound, "Layer not found")
 	case errors.Is(err, domain.ErrInvalidInput):
 		// Bad coordinate / SRID / other invalid input.
-		s.writeError(w, http.StatusBadRequest, "Invalid query parameters")
+		s.writeError(w, r, http.StatusBadRequest, "Invalid query parameters")
 	case errors.Is(err, domain.ErrUnsupported):
 		// Unsupported projection or source kind.
-		s.writeError(w, http.StatusUnprocessableEntity, "Unsupported query")
+		s.writeError(w, r, http.StatusUnprocessableEntity, "Unsupported query")
 	case errors.As(err, &storageErr), errors.Is(err, domain.ErrUnavailable):
 		s.logger.Error("query unavailable", "error", err)
-		s.writeError(w, http.StatusServiceUnavailable, "Service temporarily unavailable")
+		s.writeError(w, r, http.StatusServiceUnavailable, "Service temporarily unavailable")
 	case errors.Is(err, context.DeadlineExceeded):
 		// The server's query.timeout elapsed — a real "too slow" failure, not empty success.
 		s.logger.Warn("query timed out", "error", err)
-		s.writeError(w, http.StatusGatewayTimeout, "Query timed out")
+		s.writeError(w, r, http.StatusGatewayTimeout, "Query timed out")
 	case errors.Is(err, context.Canceled):
 		// Client went away mid-query; nothing useful to send and not a server fault.
-		// net/http has no reason phrase for the non-standard 499, so set the error
-		// label explicitly (writeError would leave "error" empty via StatusText).
 		s.logger.Debug("query canceled by client", "error", err)
-		s.writeJSON(w, StatusClientClosedRequest, map[string]interface{}{
-			"error":   "Client Closed Request",
-			"message": "The request was canceled by the client",
-		})
+		s.writeError(w, r, StatusClientClosedRequest, "The request was canceled by the client")
 	default:
 		// QueryError / IndexError / unexpected — a server-side failure.
 		s.logger.Error("query error", "error", err)
-		s.writeError(w, http.StatusInternalServerError, "Query failed")
+		s.writeError(w, r, http.StatusInternalServerError, "Query failed")
 	}
 }
 
 // writeJSON writes a JSON response.
 func (s *Server) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	// Encode before the status line is out: a value JSON cannot represent
+	// then fails the request with a 500 instead of an empty 200.
+	body, err := json.Marshal(data)
+	if err != nil {
+		s.logger.Error("encoding JSON response failed", "error", err)
+		s.writeEncodingError(w)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(data)
+	_, _ = w.Write(append(body, '\n'))
 }
 
-// writeError writes an error response.
-func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
-	s.writeJSON(w, status, map[string]interface{}{
-		"error":   http.StatusText(status),
-		"message": message,
+// writeEncodingError answers 500 for a response that could not be encoded,
+// in the configured error format.
+func (s *Server) writeEncodingError(w http.ResponseWriter) {
+	const message = "the response could not be encoded"
+	title := http.StatusText(http.StatusInternalServerError)
+	var body []byte
+	if s.config.ErrorFormat == config.ErrorFormatLegacy {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ = json.Marshal(ErrorDTO{Error: title, Message: message})
+	} else {
+		w.Header().Set("Content-Type", "application/problem+json")
+		body, _ = json.Marshal(ProblemDTO{Type: problemTypeBlank, Title: title, Status: http.StatusInternalServerError, Detail: message})
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+// problemTypeBlank is the RFC 7807 problem type of an error the status code
+// already describes.
+const problemTypeBlank = "about:blank"
+
+// writeError writes an error response: RFC 7807 problem details, or the
+// legacy {error, message} envelope with server.error_format: legacy.
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	s.writeErrorDetail(w, r, status, message, errorDetail{})
+}
+
+// errorDetail is what an error response carries besides its message.
+type errorDetail struct {
+	extent     *DataExtentDTO // where data is, for a point outside it
+	retryAfter int            // seconds until a rate-limited call is accepted
+}
+
+// writeErrorDetail is writeError with the fields of detail attached.
+func (s *Server) writeErrorDetail(w http.ResponseWriter, r *http.Request, status int, message string, detail errorDetail) {
+	title := http.StatusText(status)
+	if status == StatusClientClosedRequest {
+		// net/http has no reason phrase for the non-standard 499.
+		title = "Client Closed Request"
+	}
+	if s.config.ErrorFormat == config.ErrorFormatLegacy {
+		s.writeJSON(w, status, ErrorDTO{Error: title, Message: message, Extent: detail.extent, RetryAfter: detail.retryAfter})
+		return
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ProblemDTO{
+		Type:       problemTypeBlank,
+		Title:      title,
+		Status:     status,
+		Detail:     message,
+		Instance:   r.URL.Path,
+		Extent:     detail.extent,
+		RetryAfter: detail.retryAfter,
 	})
 }
 
@@ -445,22 +730,60 @@ func boolToStatus(b bool) string {
 	return "unhealthy"
 }
 
+// syncRequest is the optional POST /api/v1/sync body.
+type syncRequest struct {
+	Sources  []string `json:"sources"`  // loaded source ids to refresh
+	Prefixes []string `json:"prefixes"` // refresh the object keys starting with these
+}
+
 // handleSync handles the sync trigger endpoint.
 func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
 	if s.syncService == nil {
-		s.writeError(w, http.StatusNotFound, "Sync service not available")
+		s.writeError(w, r, http.StatusNotFound, "Sync service not available")
 		return
 	}
 
-	result, err := s.syncService.TriggerSync(r.Context())
+	// An optional body selects the sources to refresh; none syncs all.
+	var req syncRequest
+	if r.Body != nil && r.ContentLength != 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, 64*1024)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid JSON body: %v", err))
+			return
+		}
+	}
+	for _, p := range req.Prefixes {
+		if p == "" {
+			s.writeError(w, r, http.StatusBadRequest, "prefixes must not contain an empty prefix; send no body for a full sync")
+			return
+		}
+	}
+
+	// The sync endpoint is unauthenticated: audit it under the client's IP.
+	ctx := domain.WithActor(r.Context(), clientIP(r, s.settings().trustedProxies))
+	result, err := s.syncService.TriggerSync(ctx, input.SyncSelection{SourceIDs: req.Sources, Prefixes: req.Prefixes})
 	if err != nil {
-		if errors.Is(err, domain.ErrRateLimited) {
-			w.Header().Set("Retry-After", "30")
-			s.writeError(w, http.StatusTooManyRequests, "Rate limit exceeded. Try again in 30 seconds.")
+		var rateErr *domain.RateLimitError
+		if errors.As(err, &rateErr) {
+			secs := int(math.Ceil(rateErr.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+			s.writeErrorDetail(w, r, http.StatusTooManyRequests, fmt.Sprintf("Rate limit exceeded. Try again in %d seconds.", secs), errorDetail{retryAfter: secs})
+			return
+		}
+		if errors.Is(err, domain.ErrMaintenance) {
+			s.writeError(w, r, http.StatusServiceUnavailable, "Maintenance mode is on; sync is blocked")
+			return
+		}
+		if errors.Is(err, domain.ErrSourceNotFound) {
+			s.writeError(w, r, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, domain.ErrUnsupported) {
+			s.writeError(w, r, http.StatusConflict, "Selective sync is not available with leader election; send no body for a full sync")
 			return
 		}
 		s.logger.Error("sync failed", "error", err)
-		s.writeError(w, http.StatusInternalServerError, "Sync failed")
+		s.writeError(w, r, http.StatusInternalServerError, "Sync failed")
 		return
 	}
 
@@ -1,9 +1,14 @@
 package http
 
 import (
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
 )
 
 var (
@@ -39,3 +44,57 @@ func BenchmarkRateLimiterAllow(b *testing.B) {
 	}
 	sinkAllow = ok
 }
+
+// benchQueryResponse is a response of 50 features with ten properties and a
+// WKT polygon each, the size of a query hitting nested admin boundaries.
+func benchQueryResponse() *domain.QueryResponse {
+	features := make([]domain.Feature, 50)
+	for i := range features {
+		props := make(map[string]interface{}, 10)
+		for k := 0; k < 5; k++ {
+			props[fmt.Sprintf("name_%d", k)] = fmt.Sprintf("Feature %d/%d", i, k)
+			props[fmt.Sprintf("value_%d", k)] = float64(i*k) + 0.5
+		}
+		features[i] = domain.Feature{
+			ID:         int64(i),
+			LayerName:  "admin_levels",
+			Properties: props,
+			Geometry: domain.Geometry{
+				Type: "POLYGON",
+				WKT:  "POLYGON((33.38 35.18, 33.39 35.18, 33.39 35.19, 33.38 35.19, 33.38 35.18))",
+			},
+		}
+	}
+	return &domain.QueryResponse{
+		Coordinate:    domain.NewWGS84Coordinate(33.3823, 35.1856),
+		Results:       []domain.QueryResult{{SourceID: "admin", SourceName: "Admin", Features: features}},
+		TotalFeatures: len(features),
+	}
+}
+
+var sinkDTO QueryResponseDTO
+
+func BenchmarkFormatQueryResponse(b *testing.B) {
+	s := &Server{withGeometry: true}
+	resp := benchQueryResponse()
+	var out QueryResponseDTO
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out = s.formatQueryResponse(resp)
+	}
+	sinkDTO = out
+}
+
+// BenchmarkWriteQueryJSON measures the whole JSON rendering of a query
+// response: formatting and encoding.
+func BenchmarkWriteQueryJSON(b *testing.B) {
+	s := &Server{withGeometry: true, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	resp := benchQueryResponse()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rec := httptest.NewRecorder()
+		s.writeJSON(rec, http.StatusOK, s.formatQueryResponse(resp))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/jobrunner/ortus/internal/config"
 	"github.com/jobrunner/ortus/internal/domain"
 )
 
@@ -35,11 +36,12 @@ func TestHandleQueryErrorStatusMapping(t *testing.T) {
 		{"unexpected", errors.New("boom"), http.StatusInternalServerError},
 		{"deadline exceeded", context.DeadlineExceeded, http.StatusGatewayTimeout},
 		{"canceled", context.Canceled, StatusClientClosedRequest},
+		{"outside extent", &domain.OutsideExtentError{}, http.StatusUnprocessableEntity},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			rr := httptest.NewRecorder()
-			srv.handleQueryError(rr, tt.err)
+			srv.handleQueryError(rr, httptest.NewRequest(http.MethodGet, "/api/v1/query", nil), tt.err)
 			if rr.Code != tt.want {
 				t.Errorf("handleQueryError(%v) status = %d, want %d", tt.err, rr.Code, tt.want)
 			}
@@ -48,24 +50,56 @@ func TestHandleQueryErrorStatusMapping(t *testing.T) {
 }
 
 // TestHandleQueryErrorCanceledBodyLabel guards the non-standard 499 response: it
-// must carry a non-empty "error" label (http.StatusText(499) is "", so a naive
-// writeError would emit "error":"" and make client-side handling ambiguous).
+// must carry a non-empty title (http.StatusText(499) is "", so a naive
+// writeError would emit an empty one and make client-side handling ambiguous).
 func TestHandleQueryErrorCanceledBodyLabel(t *testing.T) {
 	srv := newTestServer(nil, nil, nil)
 	rr := httptest.NewRecorder()
-	srv.handleQueryError(rr, context.Canceled)
+	srv.handleQueryError(rr, httptest.NewRequest(http.MethodGet, "/api/v1/query", nil), context.Canceled)
+
+	var body ProblemDTO
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+	if body.Title == "" {
+		t.Errorf("canceled response has an empty title; body = %s", rr.Body.String())
+	}
+	if body.Detail == "" || body.Status != StatusClientClosedRequest || rr.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("canceled response = %s, want detail and status 499", rr.Body.String())
+	}
+
+	// The legacy envelope labels it the same way.
+	srv.config.ErrorFormat = config.ErrorFormatLegacy
+	rr = httptest.NewRecorder()
+	srv.handleQueryError(rr, httptest.NewRequest(http.MethodGet, "/api/v1/query", nil), context.Canceled)
+	var legacy ErrorDTO
+	if err := json.Unmarshal(rr.Body.Bytes(), &legacy); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+	if legacy.Error != body.Title || legacy.Message == "" || rr.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("legacy canceled response = %s", rr.Body.String())
+	}
+}
+
+// TestHandleQueryErrorOutsideExtentBody: the strict-extent 422 carries the
+// union extent so the client can see where data is.
+func TestHandleQueryErrorOutsideExtentBody(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	rr := httptest.NewRecorder()
+	srv.handleQueryError(rr, httptest.NewRequest(http.MethodGet, "/api/v1/query", nil), &domain.OutsideExtentError{
+		Extent: domain.Extent{MinX: 5.8, MinY: 47.2, MaxX: 15.1, MaxY: 55.1, SRID: domain.SRIDWGS84},
+	})
 
 	var body struct {
-		Error   string `json:"error"`
-		Message string `json:"message"`
+		Extent map[string]float64 `json:"extent"`
 	}
 	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
 		t.Fatalf("unmarshal body: %v", err)
 	}
-	if body.Error == "" {
-		t.Errorf("canceled response has empty \"error\" label; body = %s", rr.Body.String())
-	}
-	if body.Message == "" {
-		t.Error("canceled response should include a message")
+	want := map[string]float64{"min_x": 5.8, "min_y": 47.2, "max_x": 15.1, "max_y": 55.1, "srid": 4326}
+	for k, v := range want {
+		if body.Extent[k] != v {
+			t.Errorf("extent[%s] = %v, want %v (body %s)", k, body.Extent[k], v, rr.Body.String())
+		}
 	}
 }
@@ -9,13 +9,14 @@ import (
 // FuzzParseQueryParams feeds arbitrary raw query strings (the rawest external
 // client input) through the query-param parser. The invariant is simply that
 // it never panics regardless of input — we intentionally do NOT assert on the
-// parsed values, to avoid coupling the fuzz test to parser quirks like the
-// current all-zero-coordinate rejection.
+// parsed values, to avoid coupling the fuzz test to the parser's rules on
+// which coordinate parameters combine.
 func FuzzParseQueryParams(f *testing.F) {
 	for _, q := range []string{
 		"", "lon=1&lat=2", "x=1&y=2&srid=25832", "lon=0&lat=0",
 		"lon=abc", "lat=", "srid=notint", "lon=1e999&lat=2",
 		"properties=a,b,c", "lon=1&lon=2&lat=3", "%zz", "&&&", "lon=NaN&lat=Inf",
+		"geohash=u33db8", "pluscode=9F4MGC5X+", "pluscode=8FVC0000+", "coords=xy&lon=1&lat=2",
 	} {
 		f.Add(q)
 	}
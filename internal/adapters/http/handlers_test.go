@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -208,6 +210,32 @@ func TestHandleReadiness(t *testing.T) {
 	}
 }
 
+// TestHandleStartup: 503 "starting" while the startup pass runs, 200
+// "started" once it has ended.
+func TestHandleStartup(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	health := srv.health.(*application.HealthService)
+
+	serve := func() (int, map[string]interface{}) {
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health/startup", nil))
+		var resp map[string]interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		return rr.Code, resp
+	}
+
+	health.SetStarting(true)
+	if code, resp := serve(); code != http.StatusServiceUnavailable || resp["status"] != "starting" || resp["load"] == nil {
+		t.Errorf("during startup = %d %v, want 503 starting with load progress", code, resp)
+	}
+	health.SetStarting(false)
+	if code, resp := serve(); code != http.StatusOK || resp["status"] != "started" {
+		t.Errorf("after startup = %d %v, want 200 started", code, resp)
+	}
+}
+
 func TestHandleListSources(t *testing.T) {
 	srv := newTestServer(nil, nil, nil)
 
@@ -230,14 +258,40 @@ func TestHandleListSources(t *testing.T) {
 	}
 }
 
+// jsonObject marshals v and decodes it back into a generic object, so tests
+// see a response exactly as a client does, omitted fields included.
+func jsonObject(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(body, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// jsonObjects returns the objects of a decoded JSON array.
+func jsonObjects(v interface{}) []map[string]interface{} {
+	items, _ := v.([]interface{})
+	out := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		m, _ := item.(map[string]interface{})
+		out = append(out, m)
+	}
+	return out
+}
+
 func TestFormatSourceLicense(t *testing.T) {
 	srv := newTestServer(nil, nil, nil)
 
 	// A licensed source exposes the license block in the sources listing.
-	licensed := srv.formatSource(&domain.Source{
+	licensed := jsonObject(t, srv.formatSource(&domain.Source{
 		ID:      "with-license",
 		License: domain.License{Name: "CC-BY-4.0", URL: "https://example/lic", Attribution: "© Provider"},
-	})
+	}))
 	lic, ok := licensed["license"].(map[string]interface{})
 	if !ok {
 		t.Fatalf("license missing from formatSource output: %v", licensed)
@@ -247,12 +301,35 @@ func TestFormatSourceLicense(t *testing.T) {
 	}
 
 	// A source without license omits the block entirely (not an empty object).
-	unlicensed := srv.formatSource(&domain.Source{ID: "no-license"})
+	unlicensed := jsonObject(t, srv.formatSource(&domain.Source{ID: "no-license"}))
 	if _, present := unlicensed["license"]; present {
 		t.Errorf("license present for unlicensed source, want omitted: %v", unlicensed)
 	}
 }
 
+func TestFormatQueryResponseIncomplete(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+
+	complete := jsonObject(t, srv.formatQueryResponse(&domain.QueryResponse{
+		Results: []domain.QueryResult{{SourceID: "a"}},
+	}))
+	if _, present := complete["incomplete"]; present {
+		t.Errorf("incomplete present on a complete response: %v", complete)
+	}
+
+	partial := jsonObject(t, srv.formatQueryResponse(&domain.QueryResponse{
+		Results:    []domain.QueryResult{{SourceID: "a", Incomplete: true}},
+		Incomplete: true,
+	}))
+	if partial["incomplete"] != true {
+		t.Errorf("incomplete = %v, want true", partial["incomplete"])
+	}
+	results := jsonObjects(partial["results"])
+	if len(results) != 1 || results[0]["incomplete"] != true {
+		t.Errorf("per-source incomplete missing: %v", partial["results"])
+	}
+}
+
 func TestHandleQueryMissingCoordinates(t *testing.T) {
 	srv := newTestServer(nil, nil, nil)
 
@@ -277,6 +354,7 @@ func TestHandleQueryInvalidCoordinates(t *testing.T) {
 		{"invalid lat", "/api/v1/query?lon=10&lat=abc"},
 		{"invalid x", "/api/v1/query?x=abc&y=50"},
 		{"invalid y", "/api/v1/query?x=10&y=abc"},
+		{"invalid z", "/api/v1/query?lon=10&lat=50&z=roof"},
 		{"invalid srid", "/api/v1/query?lon=10&lat=50&srid=abc"},
 	}
 
@@ -392,6 +470,7 @@ func TestHandleOpenAPI(t *testing.T) {
 
 func TestParseQueryParams(t *testing.T) {
 	srv := newTestServer(nil, nil, nil)
+	srv.withGeometry = true
 
 	tests := []struct {
 		name    string
@@ -403,10 +482,7 @@ func TestParseQueryParams(t *testing.T) {
 			name: "lon/lat coordinates",
 			url:  "/query?lon=9.9&lat=52.5",
 			check: func(p *QueryParams) error {
-				if p.Lon != 9.9 {
-					return domain.ErrInvalidCoordinate
-				}
-				if p.Lat != 52.5 {
+				if c := p.Coordinate(); c.X != 9.9 || c.Y != 52.5 {
 					return domain.ErrInvalidCoordinate
 				}
 				return nil
@@ -416,10 +492,7 @@ func TestParseQueryParams(t *testing.T) {
 			name: "x/y coordinates",
 			url:  "/query?x=500000&y=5700000",
 			check: func(p *QueryParams) error {
-				if p.X != 500000 {
-					return domain.ErrInvalidCoordinate
-				}
-				if p.Y != 5700000 {
+				if c := p.Coordinate(); c.X != 500000 || c.Y != 5700000 {
 					return domain.ErrInvalidCoordinate
 				}
 				return nil
@@ -427,7 +500,7 @@ func TestParseQueryParams(t *testing.T) {
 		},
 		{
 			name: "custom SRID",
-			url:  "/query?lon=10&lat=50&srid=25832",
+			url:  "/query?x=500000&y=5700000&srid=25832",
 			check: func(p *QueryParams) error {
 				if p.SRID != 25832 {
 					return domain.ErrInvalidSRID
@@ -455,11 +528,176 @@ func TestParseQueryParams(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "height",
+			url:  "/query?x=389283&y=5819450&z=42.5&srid=25832",
+			check: func(p *QueryParams) error {
+				if c := srv.paramsToCoordinate(p); c.Z != 42.5 || c.WKT() != "POINT Z(389283.000000 5819450.000000 42.500000)" {
+					return domain.ErrInvalidInput
+				}
+				return nil
+			},
+		},
+		{
+			name: "default geometry format",
+			url:  "/query?lon=10&lat=50",
+			check: func(p *QueryParams) error {
+				if p.GeometryFormat != domain.GeometryFormatWKT {
+					return domain.ErrInvalidGeometryFormat
+				}
+				return nil
+			},
+		},
+		{
+			name: "geometry format",
+			url:  "/query?lon=10&lat=50&geometry_format=GeoJSON",
+			check: func(p *QueryParams) error {
+				if p.GeometryFormat != domain.GeometryFormatGeoJSON {
+					return domain.ErrInvalidGeometryFormat
+				}
+				return nil
+			},
+		},
+		{
+			name: "simplify tolerance",
+			url:  "/query?lon=10&lat=50&simplify=0.001",
+			check: func(p *QueryParams) error {
+				if p.Simplify != 0.001 {
+					return domain.ErrInvalidSimplify
+				}
+				return nil
+			},
+		},
+		{
+			name:    "negative simplify tolerance",
+			url:     "/query?lon=10&lat=50&simplify=-1",
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric simplify tolerance",
+			url:     "/query?lon=10&lat=50&simplify=coarse",
+			wantErr: true,
+		},
+		{
+			name:    "invalid geometry format",
+			url:     "/query?lon=10&lat=50&geometry_format=kml",
+			wantErr: true,
+		},
 		{
 			name:    "missing coordinates",
 			url:     "/query",
 			wantErr: true,
 		},
+		{
+			name: "coordinates at zero",
+			url:  "/query?lon=0&lat=0",
+			check: func(p *QueryParams) error {
+				if c := p.Coordinate(); c.X != 0 || c.Y != 0 || p.Coords != coordsLonLat {
+					return domain.ErrInvalidCoordinate
+				}
+				return nil
+			},
+		},
+		{
+			name:    "lon without lat",
+			url:     "/query?lon=10",
+			wantErr: true,
+		},
+		{
+			name:    "x without y",
+			url:     "/query?x=500000&lon=10&lat=50",
+			wantErr: true,
+		},
+		{
+			name:    "lon/lat in a projected SRID",
+			url:     "/query?lon=10&lat=50&srid=25832",
+			wantErr: true,
+		},
+		{
+			name: "lon/lat in an unknown SRID",
+			url:  "/query?lon=10&lat=50&srid=4258",
+			check: func(p *QueryParams) error {
+				if c := p.Coordinate(); c.X != 10 || c.SRID != 4258 {
+					return domain.ErrInvalidCoordinate
+				}
+				return nil
+			},
+		},
+		{
+			name: "lon/lat read as x/y",
+			url:  "/query?lon=500000&lat=5700000&srid=25832&coords=xy",
+			check: func(p *QueryParams) error {
+				if c := p.Coordinate(); c.X != 500000 || c.Y != 5700000 {
+					return domain.ErrInvalidCoordinate
+				}
+				return nil
+			},
+		},
+		{
+			name: "coords picks x/y over lon/lat",
+			url:  "/query?lon=10&lat=50&x=500000&y=5700000&srid=25832&coords=xy",
+			check: func(p *QueryParams) error {
+				if c := p.Coordinate(); c.X != 500000 || c.Y != 5700000 {
+					return domain.ErrInvalidCoordinate
+				}
+				return nil
+			},
+		},
+		{
+			name:    "coords=lonlat in a projected SRID",
+			url:     "/query?x=500000&y=5700000&srid=25832&coords=lonlat",
+			wantErr: true,
+		},
+		{
+			name:    "invalid coords",
+			url:     "/query?lon=10&lat=50&coords=latlon",
+			wantErr: true,
+		},
+		{
+			name: "geohash",
+			url:  "/query?geohash=ezs42",
+			check: func(p *QueryParams) error {
+				if c := p.Coordinate(); math.Abs(c.X+5.603) > 0.001 || math.Abs(c.Y-42.605) > 0.001 || c.SRID != domain.SRIDWGS84 {
+					return domain.ErrInvalidCoordinate
+				}
+				return nil
+			},
+		},
+		{
+			name: "plus code with an unescaped +",
+			url:  "/query?pluscode=9F4MGC5X+",
+			check: func(p *QueryParams) error {
+				if c := p.Coordinate(); math.Abs(c.X-13.44875) > 1e-9 || math.Abs(c.Y-52.50875) > 1e-9 {
+					return domain.ErrInvalidCoordinate
+				}
+				return nil
+			},
+		},
+		{
+			name:    "short plus code",
+			url:     "/query?pluscode=GC5X%2B9F",
+			wantErr: true,
+		},
+		{
+			name:    "invalid geohash",
+			url:     "/query?geohash=ezs4a",
+			wantErr: true,
+		},
+		{
+			name:    "geohash with lon/lat",
+			url:     "/query?geohash=ezs42&lon=10&lat=50",
+			wantErr: true,
+		},
+		{
+			name:    "geohash with plus code",
+			url:     "/query?geohash=ezs42&pluscode=9F4MGC5X%2B",
+			wantErr: true,
+		},
+		{
+			name:    "geohash with a projected SRID",
+			url:     "/query?geohash=ezs42&srid=25832",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -481,6 +719,54 @@ func TestParseQueryParams(t *testing.T) {
 	}
 }
 
+// TestParseQueryParamsGeometryFormatWithoutGeometry verifies an encoding
+// other than the default WKT is refused when the server returns no
+// geometries, except on a query another instance forwarded.
+func TestParseQueryParamsGeometryFormatWithoutGeometry(t *testing.T) {
+	srv := newTestServer(nil, nil, nil) // query.with_geometry: false
+
+	if _, err := srv.parseQueryParams(httptest.NewRequest(http.MethodGet, "/query?lon=10&lat=50&geometry_format=wkb", nil)); err == nil {
+		t.Error("geometry_format=wkb without geometries: err = nil, want an error")
+	}
+	if _, err := srv.parseQueryParams(httptest.NewRequest(http.MethodGet, "/query?lon=10&lat=50&geometry_format=wkt", nil)); err != nil {
+		t.Errorf("geometry_format=wkt without geometries: %v", err)
+	}
+	forwarded := httptest.NewRequest(http.MethodGet, "/query?lon=10&lat=50&geometry_format=wkb", nil)
+	forwarded.Header.Set(output.ForwardedHeader, "1")
+	if _, err := srv.parseQueryParams(forwarded); err != nil {
+		t.Errorf("forwarded geometry_format=wkb: %v", err)
+	}
+}
+
+func TestFormatGeometry(t *testing.T) {
+	g := &domain.Geometry{Type: "POINT", WKT: "POINT(1 2)"}
+	out := jsonObject(t, formatGeometry(g))
+	if len(out) != 2 || out["wkt"] != "POINT(1 2)" {
+		t.Errorf("WKT-only geometry = %v, want type and wkt only", out)
+	}
+
+	g.WKB = []byte{0x01, 0xff}
+	g.GeoJSON = `{"type":"Point","coordinates":[1,2]}`
+	g.GML = "<gml:Point/>"
+	body, err := json.Marshal(formatGeometry(g))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["wkb"] != "01ff" {
+		t.Errorf("wkb = %v, want hex 01ff", got["wkb"])
+	}
+	if gj, ok := got["geojson"].(map[string]interface{}); !ok || gj["type"] != "Point" {
+		t.Errorf("geojson = %v, want embedded object", got["geojson"])
+	}
+	if got["gml"] != "<gml:Point/>" {
+		t.Errorf("gml = %v", got["gml"])
+	}
+}
+
 func TestBoolToStatus(t *testing.T) {
 	if boolToStatus(true) != "ok" {
 		t.Error("boolToStatus(true) should return 'ok'")
@@ -494,8 +780,8 @@ func TestBoolToStatus(t *testing.T) {
 
 type mockRepository struct{}
 
-func (m *mockRepository) Open(_ context.Context, path string) (*domain.Source, error) {
-	return &domain.Source{ID: path, Name: path, Path: path}, nil
+func (m *mockRepository) Open(_ context.Context, id, path string) (*domain.Source, error) {
+	return &domain.Source{ID: id, Name: path, Path: path}, nil
 }
 
 func (m *mockRepository) Close(_ context.Context, _ string) error {
@@ -539,3 +825,76 @@ func (m *mockStorage) GetReader(_ context.Context, _ string) (io.ReadCloser, err
 func (m *mockStorage) Exists(_ context.Context, _ string) (bool, error) {
 	return true, nil
 }
+
+func TestFormatQueryResponseNotes(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+
+	out := jsonObject(t, srv.formatQueryResponse(&domain.QueryResponse{
+		Results: []domain.QueryResult{
+			{SourceID: "a", Notes: []domain.LayerNote{{Layer: "soil", Text: "accurate to ±100 m"}}},
+			{SourceID: "b"},
+		},
+	}))
+	results := jsonObjects(out["results"])
+	if len(results) != 2 {
+		t.Fatalf("results = %v", out["results"])
+	}
+	notes := jsonObjects(results[0]["notes"])
+	if len(notes) != 1 || notes[0]["layer"] != "soil" || notes[0]["text"] != "accurate to ±100 m" {
+		t.Errorf("notes = %v", results[0]["notes"])
+	}
+	if _, present := results[1]["notes"]; present {
+		t.Errorf("notes present on a result without notes: %v", results[1])
+	}
+}
+
+func TestFormatQueryResponseMatch(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+
+	out := jsonObject(t, srv.formatQueryResponse(&domain.QueryResponse{
+		Results: []domain.QueryResult{{SourceID: "a", Features: []domain.Feature{
+			{ID: 1, Match: domain.Match{Kind: domain.MatchBBox, DistanceM: 12.5, SRID: 25832}},
+			{ID: 2},
+		}}},
+	}))
+	features := jsonObjects(jsonObjects(out["results"])[0]["features"])
+	match, _ := features[0]["match"].(map[string]interface{})
+	if match["kind"] != "bbox" || match["distance_m"] != 12.5 || match["srid"] != 25832.0 {
+		t.Errorf("match = %v", features[0]["match"])
+	}
+	if _, present := features[1]["match"]; present {
+		t.Errorf("match present on a feature without one: %v", features[1])
+	}
+}
+
+func TestFormatQueryResponseNonFiniteFloats(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	props := map[string]interface{}{"area": math.NaN(), "depth": math.Inf(-1), "name": "Mitte", "pop": 1.5}
+
+	out := jsonObject(t, srv.formatQueryResponse(&domain.QueryResponse{
+		Results: []domain.QueryResult{{SourceID: "a", Features: []domain.Feature{{ID: 1, Properties: props}}}},
+	}))
+	got := jsonObjects(jsonObjects(out["results"])[0]["features"])[0]["properties"].(map[string]interface{})
+	want := map[string]interface{}{"area": nil, "depth": nil, "name": "Mitte", "pop": 1.5}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("properties = %v, want %v", got, want)
+	}
+	if !math.IsNaN(props["area"].(float64)) {
+		t.Error("the feature's own properties were modified")
+	}
+}
+
+func TestWriteJSONUnencodable(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	rec := httptest.NewRecorder()
+
+	srv.writeJSON(rec, http.StatusOK, map[string]interface{}{"x": math.Inf(1)})
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500 rather than a 200 with an empty body", rec.Code)
+	}
+	var problem map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil || problem["status"] != 500.0 {
+		t.Errorf("body = %q, want a problem document", rec.Body.String())
+	}
+}
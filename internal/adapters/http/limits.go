@@ -0,0 +1,31 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// requestLimitsMiddleware enforces server.max_query_length and
+// server.max_body_mb before a handler parses anything: a longer query string
+// gets a 414, a body declared larger a 413. A body without a declared length
+// is cut off at the limit, so its read fails with an *http.MaxBytesError,
+// which the query POST handlers answer with a 413 too. Their own tighter
+// limits, derived from the point caps, still apply.
+func (s *Server) requestLimitsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		maxQuery := s.config.MaxQueryLength
+		maxBody := int64(s.config.MaxBodyMB) * 1024 * 1024
+		if maxQuery > 0 && len(r.URL.RawQuery) > maxQuery {
+			s.writeError(w, r, http.StatusRequestURITooLong, fmt.Sprintf("query string longer than %d bytes", maxQuery))
+			return
+		}
+		if maxBody > 0 && r.Body != nil && r.Body != http.NoBody {
+			if r.ContentLength > maxBody {
+				s.writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body larger than %d bytes", maxBody))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
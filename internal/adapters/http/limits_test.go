@@ -0,0 +1,48 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestLimits(t *testing.T) {
+	// The batch endpoint's own limit (10000 points ⇒ ~5 MiB) is above the
+	// 1 MiB server limit, so the latter is what answers.
+	srv := newBatchServer(t, nil, 1000, 10000)
+	srv.config.MaxBodyMB = 1
+	srv.config.MaxQueryLength = 64
+
+	big := `{"points":[{"id":"` + strings.Repeat("x", 1<<20) + `","lon":9.93,"lat":49.79}]}`
+	if rec := doBatch(t, srv, big, ""); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("declared oversized body: status = %d, want 413", rec.Code)
+	}
+
+	// Without a declared length the body is cut off while it is read.
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/query/batch", io.MultiReader(strings.NewReader(big)))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("streamed oversized body: status = %d, want 413", rec.Code)
+	}
+
+	if rec := doBatch(t, srv, `{"points":[{"lon":9.93,"lat":49.79}]}`, ""); rec.Code != http.StatusOK {
+		t.Errorf("small body: status = %d, want 200", rec.Code)
+	}
+
+	if rec, _ := doGET(t, srv, "/api/v1/sources?q="+strings.Repeat("x", 64)); rec.Code != http.StatusRequestURITooLong {
+		t.Errorf("long query string: status = %d, want 414", rec.Code)
+	}
+	if rec, _ := doGET(t, srv, "/api/v1/sources?q=x"); rec.Code != http.StatusOK {
+		t.Errorf("short query string: status = %d, want 200", rec.Code)
+	}
+
+	// 0 lifts both limits.
+	srv.config.MaxBodyMB, srv.config.MaxQueryLength = 0, 0
+	if rec, _ := doGET(t, srv, "/api/v1/sources?q="+strings.Repeat("x", 64)); rec.Code != http.StatusOK {
+		t.Errorf("long query string without a limit: status = %d, want 200", rec.Code)
+	}
+}
@@ -3,8 +3,13 @@ package http
 import (
 	"embed"
 	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
 	"sync"
 
+	"github.com/gorilla/mux"
 	"gopkg.in/yaml.v3"
 )
 
@@ -12,22 +17,32 @@ import (
 var openAPIYAML embed.FS
 
 var (
-	openAPIJSON     []byte
-	openAPIJSONOnce sync.Once
-	openAPIJSONErr  error
+	openAPISpec     map[string]interface{}
+	openAPISpecOnce sync.Once
+	openAPISpecErr  error
 )
 
-// getOpenAPIJSON returns the OpenAPI specification as JSON.
-// The YAML is converted to JSON on first access and cached.
-func getOpenAPIJSON() ([]byte, error) {
-	openAPIJSONOnce.Do(func() {
-		openAPIJSON, openAPIJSONErr = convertOpenAPIToJSON()
+// getOpenAPISpec returns the embedded OpenAPI specification as a JSON-ready
+// map. The YAML is parsed on first access and cached; callers must not modify
+// the result.
+func getOpenAPISpec() (map[string]interface{}, error) {
+	openAPISpecOnce.Do(func() {
+		openAPISpec, openAPISpecErr = parseOpenAPISpec()
 	})
-	return openAPIJSON, openAPIJSONErr
+	return openAPISpec, openAPISpecErr
+}
+
+// getOpenAPIJSON returns the embedded OpenAPI specification as JSON.
+func getOpenAPIJSON() ([]byte, error) {
+	spec, err := getOpenAPISpec()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(spec, "", "  ")
 }
 
-// convertOpenAPIToJSON reads the embedded YAML and converts it to JSON.
-func convertOpenAPIToJSON() ([]byte, error) {
+// parseOpenAPISpec reads the embedded YAML into a JSON-compatible structure.
+func parseOpenAPISpec() (map[string]interface{}, error) {
 	yamlData, err := openAPIYAML.ReadFile("openapi.yaml")
 	if err != nil {
 		return nil, err
@@ -39,9 +54,193 @@ func convertOpenAPIToJSON() ([]byte, error) {
 	}
 
 	// Convert YAML structure to JSON-compatible structure
-	spec = convertYAMLToJSON(spec)
+	doc, ok := convertYAMLToJSON(spec).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("openapi.yaml is not a mapping")
+	}
+	return doc, nil
+}
 
-	return json.MarshalIndent(spec, "", "  ")
+// openAPIDocument returns the OpenAPI document this server serves: the
+// embedded spec without the paths it registers no route for (optional
+// features that are off, the admin routes without server.admin.enabled), and
+// with its server URLs made absolute against the origin the client used, so
+// "Try it out" in /docs targets this instance.
+func (s *Server) openAPIDocument(r *http.Request) (map[string]interface{}, error) {
+	spec, err := getOpenAPISpec()
+	if err != nil {
+		return nil, err
+	}
+	doc := make(map[string]interface{}, len(spec))
+	for k, v := range spec {
+		doc[k] = v
+	}
+
+	if paths, ok := spec["paths"].(map[string]interface{}); ok {
+		declared := s.queryParamStructs()
+		kept := make(map[string]interface{}, len(paths))
+		for p, item := range paths {
+			if s.routePaths[p] || s.routePaths["/api/v1"+p] {
+				kept[p] = withQueryParameters(spec, p, item, declared)
+			}
+		}
+		doc["paths"] = kept
+	}
+
+	if servers, ok := spec["servers"].([]interface{}); ok {
+		origin := requestOrigin(r, s.settings().trustedProxies)
+		absolute := make([]interface{}, len(servers))
+		for i, srv := range servers {
+			absolute[i] = srv
+			m, ok := srv.(map[string]interface{})
+			if url, _ := m["url"].(string); ok && strings.HasPrefix(url, "/") {
+				copied := make(map[string]interface{}, len(m))
+				for k, v := range m {
+					copied[k] = v
+				}
+				copied["url"] = origin + url
+				absolute[i] = copied
+			}
+		}
+		doc["servers"] = absolute
+	}
+	return doc, nil
+}
+
+// queryParamStructs maps the operations ("METHOD path", paths as in the
+// spec) whose query parameters the handlers decode with decodeQuery to the
+// structs declaring them. Operations not listed keep the parameters written in
+// openapi.yaml; that is the table reader, whose query doubles as a column
+// filter.
+func (s *Server) queryParamStructs() map[string][]interface{} {
+	ops := map[string][]interface{}{
+		"GET /query":            {QueryParams{}},
+		"GET /query/{sourceId}": {QueryParams{}},
+		"GET /gazetteer":        {CoordinateParams{}},
+		"GET /popularity":       {popularityParams{}},
+		"GET /catalog":          {catalogParams{}},
+		"GET /admin/audit":      {auditParams{}},
+		"GET /geocode":          {geocodeParams{}},
+	}
+	if s.gazetteer != nil {
+		ops["GET /query"] = append(ops["GET /query"], gazetteerParams{})
+	}
+	return ops
+}
+
+// withQueryParameters returns the path item with the query parameters of its
+// declared operations generated from code: exactly the declared parameters,
+// each taken from openapi.yaml when documented there (for its description and
+// constraints) and generated from the field type otherwise. Path parameters
+// are kept as written. The cached spec is not modified.
+func withQueryParameters(spec map[string]interface{}, path string, item interface{}, declared map[string][]interface{}) interface{} {
+	ops, ok := item.(map[string]interface{})
+	if !ok {
+		return item
+	}
+	out := make(map[string]interface{}, len(ops))
+	for method, op := range ops {
+		out[method] = op
+		structs, ok := declared[strings.ToUpper(method)+" "+path]
+		opMap, isMap := op.(map[string]interface{})
+		if !ok || !isMap {
+			continue
+		}
+		copied := make(map[string]interface{}, len(opMap))
+		for k, v := range opMap {
+			copied[k] = v
+		}
+		copied["parameters"] = operationParameters(spec, opMap, structs)
+		out[method] = copied
+	}
+	return out
+}
+
+// operationParameters returns the parameters of op with its query parameters
+// replaced by those the structs declare.
+func operationParameters(spec, op map[string]interface{}, structs []interface{}) []interface{} {
+	documented := map[string]interface{}{}
+	var params []interface{}
+	existing, _ := op["parameters"].([]interface{})
+	for _, param := range existing {
+		resolved := resolveParameter(spec, param)
+		if resolved["in"] != "query" {
+			params = append(params, param)
+			continue
+		}
+		name, _ := resolved["name"].(string)
+		documented[name] = param
+	}
+	for _, gen := range queryParameters(structs...) {
+		if doc, ok := documented[gen["name"].(string)]; ok {
+			params = append(params, doc)
+		} else {
+			params = append(params, gen)
+		}
+	}
+	return params
+}
+
+// resolveParameter returns the parameter object param stands for, following a
+// $ref into components.parameters.
+func resolveParameter(spec map[string]interface{}, param interface{}) map[string]interface{} {
+	m, _ := param.(map[string]interface{})
+	ref, ok := m["$ref"].(string)
+	if !ok {
+		return m
+	}
+	name, ok := strings.CutPrefix(ref, "#/components/parameters/")
+	if !ok {
+		return m
+	}
+	components, _ := spec["components"].(map[string]interface{})
+	parameters, _ := components["parameters"].(map[string]interface{})
+	resolved, _ := parameters[name].(map[string]interface{})
+	return resolved
+}
+
+// routeTemplates returns the path templates registered on router.
+func routeTemplates(router *mux.Router) map[string]bool {
+	paths := map[string]bool{}
+	_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		// Matcher-only routes have no template; they are not documentable.
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			paths[tmpl] = true
+		}
+		return nil
+	})
+	return paths
+}
+
+// requestOrigin returns the scheme://host the client addressed.
+// X-Forwarded-Proto and X-Forwarded-Host count only when the direct peer is a
+// trusted proxy, as X-Forwarded-For does in clientIP; with several hops the
+// first (client-facing) value wins.
+func requestOrigin(r *http.Request, trusted []*net.IPNet) string {
+	scheme, host := "http", r.Host
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if ip := net.ParseIP(peer); ip != nil && ipInAny(ip, trusted) {
+		if p := firstForwarded(r.Header.Get("X-Forwarded-Proto")); p == "http" || p == "https" {
+			scheme = p
+		}
+		if h := firstForwarded(r.Header.Get("X-Forwarded-Host")); h != "" {
+			host = h
+		}
+	}
+	return scheme + "://" + host
+}
+
+// firstForwarded returns the first entry of a comma-separated forwarding
+// header.
+func firstForwarded(v string) string {
+	first, _, _ := strings.Cut(v, ",")
+	return strings.TrimSpace(first)
 }
 
 // convertYAMLToJSON recursively converts YAML map keys to strings
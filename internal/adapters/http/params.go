@@ -0,0 +1,140 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Query parameters are declared once, as struct fields tagged `query:"name"`:
+// decodeQuery parses a request into such a struct, and queryParameters
+// derives the OpenAPI parameter objects of the served document from the same
+// struct (see Server.openAPIDocument). A parameter a handler reads therefore
+// cannot be missing from /openapi.json, and TestOpenAPIQueryParameters keeps
+// the hand-written prose in openapi.yaml in step with the code.
+//
+// Supported field types are string (and named string types), int, float64,
+// []string (comma-separated), pointers to these for parameters whose absence
+// must be told apart from the zero value, and embedded structs, whose fields
+// are flattened.
+
+// decodeQuery sets the tagged fields of the struct dst points to from values.
+// Absent or empty parameters leave their field untouched, so defaults set
+// before the call survive. A value that does not parse as the field's type
+// yields "invalid <name> parameter".
+func decodeQuery(values url.Values, dst interface{}) error {
+	return decodeQueryStruct(values, reflect.ValueOf(dst).Elem())
+}
+
+func decodeQueryStruct(values url.Values, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := decodeQueryStruct(values, v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		name := f.Tag.Get("query")
+		raw := values.Get(name)
+		if name == "" || raw == "" {
+			continue
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Pointer {
+			ptr := reflect.New(field.Type().Elem())
+			if err := setQueryValue(ptr.Elem(), raw); err != nil {
+				return fmt.Errorf("invalid %s parameter", name)
+			}
+			field.Set(ptr)
+			continue
+		}
+		if err := setQueryValue(field, raw); err != nil {
+			return fmt.Errorf("invalid %s parameter", name)
+		}
+	}
+	return nil
+}
+
+// setQueryValue parses raw into field according to its kind.
+func setQueryValue(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(strings.Split(raw, ",")))
+	default:
+		return fmt.Errorf("unsupported query field kind %s", field.Kind())
+	}
+	return nil
+}
+
+// queryParameters returns the OpenAPI parameter objects for the tagged fields
+// of the given structs, in declaration order. Only name, location and schema
+// come from the code; descriptions live in openapi.yaml.
+func queryParameters(structs ...interface{}) []map[string]interface{} {
+	var params []map[string]interface{}
+	for _, s := range structs {
+		params = appendQueryParameters(params, reflect.TypeOf(s))
+	}
+	return params
+}
+
+func appendQueryParameters(params []map[string]interface{}, t reflect.Type) []map[string]interface{} {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			params = appendQueryParameters(params, f.Type)
+			continue
+		}
+		name := f.Tag.Get("query")
+		if name == "" {
+			continue
+		}
+		params = append(params, map[string]interface{}{
+			"name":   name,
+			"in":     "query",
+			"schema": querySchema(f.Type),
+		})
+	}
+	return params
+}
+
+// querySchema returns the OpenAPI schema of a query field type. A []string
+// travels as one comma-separated string.
+func querySchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
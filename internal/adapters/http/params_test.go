@@ -0,0 +1,108 @@
+package http
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type testParams struct {
+	embeddedParams
+	Name  string   `query:"name"`
+	Count int      `query:"count"`
+	Tags  []string `query:"tags"`
+	Max   *int     `query:"max"`
+	Skip  string
+}
+
+type embeddedParams struct {
+	Ratio float64 `query:"ratio"`
+}
+
+func TestDecodeQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    testParams
+		wantErr string
+	}{
+		{name: "empty keeps defaults", query: "", want: testParams{Count: 7}},
+		{
+			name:  "all fields",
+			query: "name=a&count=3&tags=x,y&max=0&ratio=0.5&Skip=no",
+			want:  testParams{embeddedParams: embeddedParams{Ratio: 0.5}, Name: "a", Count: 3, Tags: []string{"x", "y"}, Max: new(int)},
+		},
+		{name: "empty value ignored", query: "count=", want: testParams{Count: 7}},
+		{name: "bad int", query: "count=x", wantErr: "invalid count parameter"},
+		{name: "bad pointer", query: "max=x", wantErr: "invalid max parameter"},
+		{name: "bad embedded float", query: "ratio=half", wantErr: "invalid ratio parameter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			got := testParams{Count: 7}
+			err := decodeQuery(values, &got)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("decodeQuery = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeQuery: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeQuery = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryParameters(t *testing.T) {
+	got := queryParameters(testParams{})
+	want := []string{"ratio:number", "name:string", "count:integer", "tags:string", "max:integer"}
+	if len(got) != len(want) {
+		t.Fatalf("queryParameters = %v, want %v", got, want)
+	}
+	for i, p := range got {
+		if s := p["name"].(string) + ":" + p["schema"].(map[string]interface{})["type"].(string); s != want[i] || p["in"] != "query" {
+			t.Errorf("parameter %d = %v, want %s", i, p, want[i])
+		}
+	}
+}
+
+func TestWithQueryParameters(t *testing.T) {
+	spec := map[string]interface{}{
+		"components": map[string]interface{}{
+			"parameters": map[string]interface{}{
+				"NameParam": map[string]interface{}{"name": "name", "in": "query", "description": "documented"},
+			},
+		},
+	}
+	item := map[string]interface{}{
+		"get": map[string]interface{}{
+			"parameters": []interface{}{
+				map[string]interface{}{"name": "id", "in": "path"},
+				map[string]interface{}{"$ref": "#/components/parameters/NameParam"},
+				map[string]interface{}{"name": "stale", "in": "query"},
+			},
+		},
+	}
+	declared := map[string][]interface{}{"GET /things/{id}": {testParams{}}}
+
+	out := withQueryParameters(spec, "/things/{id}", item, declared).(map[string]interface{})
+	params := out["get"].(map[string]interface{})["parameters"].([]interface{})
+	var names []string
+	for _, p := range params {
+		names = append(names, resolveParameter(spec, p)["name"].(string))
+	}
+	if want := []string{"id", "ratio", "name", "count", "tags", "max"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("parameters = %v, want %v", names, want)
+	}
+	if _, ok := params[2].(map[string]interface{})["$ref"]; !ok {
+		t.Errorf("documented parameter not kept as written: %v", params[2])
+	}
+	if got := item["get"].(map[string]interface{})["parameters"].([]interface{}); len(got) != 3 {
+		t.Errorf("input path item modified: %v", got)
+	}
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+	"time"
+)
+
+// handlePeers lists the federation peers with the sources each one serves,
+// as of the last catalog fetch.
+func (s *Server) handlePeers(w http.ResponseWriter, _ *http.Request) {
+	infos := s.peers.Peers()
+	peers := make([]map[string]interface{}, len(infos))
+	for i, p := range infos {
+		entry := map[string]interface{}{
+			"name":         p.Name,
+			"sources":      p.SourceIDs,
+			"source_count": len(p.SourceIDs),
+		}
+		if !p.RefreshedAt.IsZero() {
+			entry["refreshed_at"] = p.RefreshedAt.UTC().Format(time.RFC3339)
+		}
+		if p.Error != "" {
+			entry["error"] = p.Error
+		}
+		peers[i] = entry
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"peers": peers,
+		"count": len(peers),
+	})
+}
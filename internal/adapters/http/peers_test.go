@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+)
+
+type fakePeerCatalog []input.PeerInfo
+
+func (f fakePeerCatalog) Peers() []input.PeerInfo { return f }
+
+func TestPeersEndpoint(t *testing.T) {
+	srv := newGazetteerServer(t, fakeGazetteer{})
+	srv.peers = fakePeerCatalog{
+		{Name: "bavaria", SourceIDs: []string{"alkis_by"}, RefreshedAt: time.Date(2026, 7, 6, 12, 0, 0, 0, time.UTC)},
+		{Name: "saxony", SourceIDs: []string{}, Error: "connection refused"},
+	}
+
+	rec, body := doGET(t, srv, "/api/v1/peers")
+	if rec.Code != http.StatusOK || body["count"] != float64(2) {
+		t.Fatalf("status = %d, body = %v", rec.Code, body)
+	}
+	peers, _ := body["peers"].([]any)
+	first, _ := peers[0].(map[string]any)
+	second, _ := peers[1].(map[string]any)
+	if first["refreshed_at"] != "2026-07-06T12:00:00Z" || first["source_count"] != float64(1) || first["error"] != nil {
+		t.Errorf("first peer = %v", first)
+	}
+	if second["error"] != "connection refused" || second["refreshed_at"] != nil {
+		t.Errorf("second peer = %v", second)
+	}
+}
+
+func TestFormatQueryResponsePeers(t *testing.T) {
+	resp := &domain.QueryResponse{
+		Results: []domain.QueryResult{{SourceID: "alkis_by", Peer: "bavaria"}, {SourceID: "local"}},
+		Peers: []domain.PeerOutcome{
+			{Peer: "bavaria", Status: domain.PeerStatusOK, Duration: 38 * time.Millisecond, Features: 3},
+			{Peer: "saxony", Status: domain.PeerStatusTimeout, Incomplete: true},
+		},
+	}
+	out := jsonObject(t, FormatQueryResponse(resp, false))
+	results := jsonObjects(out["results"])
+	if results[0]["peer"] != "bavaria" || results[1]["peer"] != nil {
+		t.Errorf("result peers = %v, %v", results[0]["peer"], results[1]["peer"])
+	}
+	peers := jsonObjects(out["peers"])
+	if peers[0]["duration_ms"] != 38.0 || peers[0]["feature_count"] != 3.0 || peers[1]["incomplete"] != true {
+		t.Errorf("peers = %v", peers)
+	}
+
+	if _, ok := jsonObject(t, FormatQueryResponse(&domain.QueryResponse{}, false))["peers"]; ok {
+		t.Error("peers present on a local-only response")
+	}
+}
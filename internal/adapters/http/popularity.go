@@ -0,0 +1,65 @@
+package http
+
+import (
+	"net/http"
+	"time"
+)
+
+// Window bounds of GET /api/v1/popularity; hits are only kept for a day.
+const (
+	defaultPopularityWindow = time.Hour
+	maxPopularityWindow     = 24 * time.Hour
+)
+
+// popularityParams are the query parameters of GET /api/v1/popularity.
+type popularityParams struct {
+	Window *string `query:"window"`
+	Limit  *int    `query:"limit"`
+}
+
+// handlePopularity ranks the sources by the point queries they answered with
+// features in the last ?window= (default 1h, at most 24h), optionally cut to
+// the top ?limit= entries.
+func (s *Server) handlePopularity(w http.ResponseWriter, r *http.Request) {
+	var params popularityParams
+	if err := decodeQuery(r.URL.Query(), &params); err != nil {
+		// Only limit can fail to decode; window is parsed below.
+		s.writeError(w, r, http.StatusBadRequest, "limit must be a positive integer")
+		return
+	}
+	window := defaultPopularityWindow
+	if params.Window != nil {
+		d, err := time.ParseDuration(*params.Window)
+		if err != nil || d < time.Minute || d > maxPopularityWindow {
+			s.writeError(w, r, http.StatusBadRequest, "window must be a duration between 1m and 24h")
+			return
+		}
+		window = d
+	}
+	limit := 0
+	if params.Limit != nil {
+		if *params.Limit < 1 {
+			s.writeError(w, r, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = *params.Limit
+	}
+
+	ranking := s.popularity.Ranking(window)
+	if limit > 0 && len(ranking) > limit {
+		ranking = ranking[:limit]
+	}
+	sources := make([]map[string]interface{}, len(ranking))
+	for i, p := range ranking {
+		sources[i] = map[string]interface{}{
+			"rank":      p.Rank,
+			"source_id": p.SourceID,
+			"hits":      p.Hits,
+		}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"window":  window.String(),
+		"sources": sources,
+		"count":   len(sources),
+	})
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/ports/input"
+)
+
+// fakePopularity returns a canned ranking and remembers the window asked for.
+type fakePopularity struct {
+	ranking []input.SourcePopularity
+	window  time.Duration
+}
+
+func (f *fakePopularity) Ranking(window time.Duration) []input.SourcePopularity {
+	f.window = window
+	return f.ranking
+}
+
+func TestPopularityEndpoint(t *testing.T) {
+	srv := newGazetteerServer(t, fakeGazetteer{})
+	pop := &fakePopularity{ranking: []input.SourcePopularity{
+		{Rank: 1, SourceID: "districts", Hits: 412},
+		{Rank: 2, SourceID: "parcels", Hits: 97},
+	}}
+	srv.popularity = pop
+
+	rec, body := doGET(t, srv, "/api/v1/popularity")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if pop.window != time.Hour || body["window"] != "1h0m0s" {
+		t.Errorf("default window = %v (%v), want 1h", pop.window, body["window"])
+	}
+	if body["count"] != float64(2) {
+		t.Errorf("count = %v, want 2", body["count"])
+	}
+
+	rec, body = doGET(t, srv, "/api/v1/popularity?window=15m&limit=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	sources, _ := body["sources"].([]any)
+	if pop.window != 15*time.Minute || len(sources) != 1 {
+		t.Fatalf("window = %v, sources = %v; want 15m and one entry", pop.window, sources)
+	}
+	first, _ := sources[0].(map[string]any)
+	if first["source_id"] != "districts" || first["rank"] != float64(1) || first["hits"] != float64(412) {
+		t.Errorf("first entry = %v", first)
+	}
+
+	for _, q := range []string{"window=30s", "window=48h", "window=soon", "limit=0", "limit=x"} {
+		if rec, _ := doGET(t, srv, "/api/v1/popularity?"+q); rec.Code != http.StatusBadRequest {
+			t.Errorf("?%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
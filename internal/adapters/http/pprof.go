@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+	"net/http/pprof" // #nosec G108 -- mounted on our router behind the admin token, not on http.DefaultServeMux by import
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// setupPprofRoutes serves the runtime profiles under /debug/pprof for
+// diagnosing hotspots in production (server.admin.pprof). They reveal the
+// command line and the code, so they sit behind the admin token like the
+// other operator endpoints:
+//
+//	curl -H "Authorization: Bearer $ORTUS_ADMIN_TOKEN" \
+//	    -o cpu.pprof 'http://localhost:8080/debug/pprof/profile?seconds=30'
+func (s *Server) setupPprofRoutes(debug *mux.Router) {
+	debug.Use(s.adminAuthMiddleware)
+	debug.HandleFunc("/cmdline", pprof.Cmdline).Methods(http.MethodGet)
+	debug.HandleFunc("/profile", withoutWriteDeadline(pprof.Profile)).Methods(http.MethodGet)
+	debug.HandleFunc("/symbol", pprof.Symbol).Methods(http.MethodGet, http.MethodPost)
+	debug.HandleFunc("/trace", withoutWriteDeadline(pprof.Trace)).Methods(http.MethodGet)
+	// Index lists the profiles and serves the named ones (heap, goroutine,
+	// allocs, block, mutex, threadcreate) from the path.
+	debug.PathPrefix("/").HandlerFunc(pprof.Index).Methods(http.MethodGet)
+}
+
+// withoutWriteDeadline lifts server.write_timeout for a handler that samples
+// for ?seconds= before it writes: a 30-second CPU profile would otherwise be
+// cut off by a write timeout of the same length.
+func withoutWriteDeadline(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		h(w, r)
+	}
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/jobrunner/ortus/internal/application"
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+func newPprofTestServer(t *testing.T, pprof bool) *Server {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	registry := application.NewSourceRegistry([]output.SpatialSource{&mockRepository{}}, &mockStorage{},
+		noop.NewMeterProvider().Meter("test"), output.NoOpTracer{}, logger, "/tmp")
+	_ = registry.LoadAll(context.Background())
+	health := application.NewHealthService(registry, true, output.NoOpTracer{})
+	query := application.NewQueryService(registry, nil, noop.NewMeterProvider().Meter("test"),
+		output.NoOpTracer{}, logger, application.QueryServiceConfig{})
+	return NewServer(
+		config.ServerConfig{Host: "localhost", Port: 8080, Admin: config.AdminConfig{Enabled: true, Token: "s3cret", Pprof: pprof}},
+		query, registry, health, nil, logger, false, ServerOptions{},
+	)
+}
+
+func pprofRequest(srv *Server, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestPprofRequiresToken(t *testing.T) {
+	srv := newPprofTestServer(t, true)
+	for _, token := range []string{"", "wrong"} {
+		if rr := pprofRequest(srv, "/debug/pprof/", token); rr.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, rr.Code)
+		}
+	}
+}
+
+func TestPprofServesProfiles(t *testing.T) {
+	srv := newPprofTestServer(t, true)
+	rr := pprofRequest(srv, "/debug/pprof/", "s3cret")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "goroutine") {
+		t.Fatalf("index = %d %q, want 200 listing the profiles", rr.Code, rr.Body.String())
+	}
+	if rr := pprofRequest(srv, "/debug/pprof/goroutine?debug=1", "s3cret"); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "goroutine profile") {
+		t.Errorf("goroutine profile = %d, want 200 with the profile", rr.Code)
+	}
+	if rr := pprofRequest(srv, "/debug/pprof/cmdline", "s3cret"); rr.Code != http.StatusOK {
+		t.Errorf("cmdline = %d, want 200", rr.Code)
+	}
+}
+
+func TestPprofOffByDefault(t *testing.T) {
+	srv := newPprofTestServer(t, false)
+	if rr := pprofRequest(srv, "/debug/pprof/", "s3cret"); rr.Code == http.StatusOK {
+		t.Errorf("pprof served without server.admin.pprof")
+	}
+}
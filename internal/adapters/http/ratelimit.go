@@ -44,6 +44,15 @@ func newIPRateLimiter(r float64, burst int) *ipRateLimiter {
 	}
 }
 
+// sameLimit reports whether o enforces the same rate and burst as l. Either
+// may be nil.
+func (l *ipRateLimiter) sameLimit(o *ipRateLimiter) bool {
+	if l == nil || o == nil {
+		return l == o
+	}
+	return l.rate == o.rate && l.burst == o.burst
+}
+
 // allow reports whether a request from ip may proceed now.
 func (l *ipRateLimiter) allow(ip string) bool {
 	l.mu.Lock()
@@ -95,11 +104,13 @@ func ipInAny(ip net.IP, nets []*net.IPNet) bool {
 	return false
 }
 
-// clientIP resolves the request's client IP for rate-limiting. By default it is
-// the direct peer (RemoteAddr). X-Forwarded-For is consulted ONLY when the direct
-// peer is itself a trusted proxy; even then the client is the RIGHT-most entry
-// that is not a trusted proxy — never the left-most, which a client can spoof
-// (proxies append to XFF rather than overwrite it).
+// clientIP resolves the request's client IP for logs, rate limiting and audit
+// records. By default it is the direct peer (RemoteAddr). X-Forwarded-For is
+// consulted ONLY when the direct peer is itself a trusted proxy; even then the
+// client is the RIGHT-most entry that is not a trusted proxy — never the
+// left-most, which a client can spoof (proxies append to XFF rather than
+// overwrite it). A trusted proxy that sends no X-Forwarded-For may name the
+// client in X-Real-IP instead.
 func clientIP(r *http.Request, trusted []*net.IPNet) string {
 	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
@@ -111,7 +122,8 @@ func clientIP(r *http.Request, trusted []*net.IPNet) string {
 	}
 	// Direct peer is a trusted proxy: walk XFF right-to-left, skipping further
 	// trusted hops; the first non-trusted address is the real client.
-	parts := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	xff := r.Header.Get("X-Forwarded-For")
+	parts := strings.Split(xff, ",")
 	for i := len(parts) - 1; i >= 0; i-- {
 		ip := strings.TrimSpace(parts[i])
 		parsed := net.ParseIP(ip)
@@ -120,18 +132,29 @@ func clientIP(r *http.Request, trusted []*net.IPNet) string {
 		}
 		return ip
 	}
+	if xff == "" {
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+			return ip
+		}
+	}
 	// All XFF entries are trusted proxies (or none present) — fall back to peer.
 	return host
 }
 
 // rateLimitMiddleware enforces the per-IP limit. Only mounted on the /api/v1
-// subrouter (health/probe endpoints are never rate-limited).
+// subrouter (health/probe endpoints are never rate-limited); a no-op while
+// rate limiting is off.
 func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := clientIP(r, s.trustedProxies)
-		if !s.rateLimiter.allow(ip) {
+		live := s.settings()
+		if live.rateLimiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ip := clientIP(r, live.trustedProxies)
+		if !live.rateLimiter.allow(ip) {
 			w.Header().Set("Retry-After", "1")
-			s.writeError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+			s.writeError(w, r, http.StatusTooManyRequests, "Rate limit exceeded")
 			return
 		}
 		next.ServeHTTP(w, r)
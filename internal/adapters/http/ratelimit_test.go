@@ -1,10 +1,13 @@
 package http
 
 import (
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/jobrunner/ortus/internal/config"
 )
 
 func TestIPRateLimiterAllow(t *testing.T) {
@@ -59,11 +62,40 @@ func TestClientIP(t *testing.T) {
 	}
 }
 
+func TestClientIPRealIP(t *testing.T) {
+	trusted, _ := parseCIDRs([]string{"10.0.0.0/8"})
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		realIP string
+		want   string
+	}{
+		{"x-real-ip from trusted proxy", "10.0.0.1:5678", "", "9.9.9.9", "9.9.9.9"},
+		{"x-real-ip ignored from untrusted peer", "1.2.3.4:5678", "", "9.9.9.9", "1.2.3.4"},
+		{"x-forwarded-for wins", "10.0.0.1:5678", "8.8.8.8", "9.9.9.9", "8.8.8.8"},
+		{"invalid x-real-ip falls back to peer", "10.0.0.1:5678", "", "not-an-ip", "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			r.Header.Set("X-Real-IP", tt.realIP)
+			if got := clientIP(r, trusted); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRateLimitMiddleware(t *testing.T) {
 	s := &Server{
-		rateLimiter: newIPRateLimiter(1, 1), // 1/s, burst 1
-		logger:      slog.New(slog.NewTextHandler(httptest.NewRecorder().Body, nil)),
+		logger: slog.New(slog.NewTextHandler(httptest.NewRecorder().Body, nil)),
 	}
+	s.live.Store(&liveSettings{rateLimiter: newIPRateLimiter(1, 1)}) // 1/s, burst 1
 	h := s.rateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
@@ -87,3 +119,27 @@ func TestRateLimitMiddleware(t *testing.T) {
 		t.Error("429 response should set a Retry-After header")
 	}
 }
+
+func TestReconfigureKeepsUnchangedRateLimiter(t *testing.T) {
+	s := &Server{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	limit := config.ServerConfig{RateLimit: config.RateLimitConfig{Enabled: true, Rate: 5, Burst: 10}}
+	s.Reconfigure(limit)
+	before := s.settings().rateLimiter
+
+	limit.CORS.AllowedOrigins = []string{"https://example.com"}
+	s.Reconfigure(limit)
+	if s.settings().rateLimiter != before {
+		t.Error("an unchanged rate limit must keep its per-IP buckets")
+	}
+
+	limit.RateLimit.Rate = 1
+	s.Reconfigure(limit)
+	if l := s.settings().rateLimiter; l == before || l == nil {
+		t.Error("a changed rate must install a new limiter")
+	}
+
+	s.Reconfigure(config.ServerConfig{})
+	if s.settings().rateLimiter != nil {
+		t.Error("disabling rate limiting must drop the limiter")
+	}
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jobrunner/ortus/internal/config"
+)
+
+// requestLogRule is a parsed logging.requests entry.
+type requestLogRule struct {
+	path   string // exact path, or the prefix of a path ending in *
+	prefix bool
+	level  slog.Level
+	off    bool
+	every  int64        // log 1 in every requests; 1 logs all
+	seen   atomic.Int64 // requests matched so far, for sampling
+}
+
+func newRequestLogRules(rules []config.RequestLogRule) []*requestLogRule {
+	parsed := make([]*requestLogRule, 0, len(rules))
+	for _, r := range rules {
+		rule := &requestLogRule{
+			level: r.SlogLevel(),
+			off:   r.Level == config.RequestLogOff,
+			every: int64(max(r.Sample, 1)),
+		}
+		rule.path, rule.prefix = strings.CutSuffix(r.Path, "*")
+		parsed = append(parsed, rule)
+	}
+	return parsed
+}
+
+// matches reports whether the rule applies to path.
+func (r *requestLogRule) matches(path string) bool {
+	if r.prefix {
+		return strings.HasPrefix(path, r.path)
+	}
+	return path == r.path
+}
+
+// requestLogLevel decides whether the request line of a request to path
+// that answered status is logged, and at which level. Without a matching
+// rule every request is logged at info. Sampling never drops a 5xx.
+func (s *Server) requestLogLevel(path string, status int) (slog.Level, bool) {
+	for _, rule := range s.requestLogRules {
+		if !rule.matches(path) {
+			continue
+		}
+		if rule.off {
+			return 0, false
+		}
+		sampled := (rule.seen.Add(1)-1)%rule.every == 0
+		return rule.level, sampled || status >= http.StatusInternalServerError
+	}
+	return slog.LevelInfo, true
+}
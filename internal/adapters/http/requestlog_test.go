@@ -0,0 +1,66 @@
+package http
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/config"
+)
+
+func TestRequestLogRules(t *testing.T) {
+	var buf bytes.Buffer
+	s := &Server{
+		logger: slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		requestLogRules: newRequestLogRules([]config.RequestLogRule{
+			{Path: "/health/*", Level: "debug", Sample: 3},
+			{Path: "/openapi.json", Level: config.RequestLogOff},
+			{Path: "/api/v1/sync", Level: "warn"},
+		}),
+	}
+	status := http.StatusOK
+	h := s.loggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	serve := func(path string) string {
+		buf.Reset()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		return buf.String()
+	}
+
+	// 1 in 3 probes is logged, at debug.
+	var logged int
+	for range 6 {
+		if line := serve("/health/live"); line != "" {
+			logged++
+			if !strings.Contains(line, "level=DEBUG") {
+				t.Errorf("probe logged as %q, want debug", line)
+			}
+		}
+	}
+	if logged != 2 {
+		t.Errorf("logged %d of 6 probes, want 2", logged)
+	}
+
+	// A 5xx is never sampled away.
+	status = http.StatusServiceUnavailable
+	for range 3 {
+		if serve("/health/ready") == "" {
+			t.Error("5xx probe was sampled away")
+		}
+	}
+	status = http.StatusOK
+
+	if line := serve("/openapi.json"); line != "" {
+		t.Errorf("off route logged %q", line)
+	}
+	if line := serve("/api/v1/sync"); !strings.Contains(line, "level=WARN") {
+		t.Errorf("exact route logged %q, want warn", line)
+	}
+	if line := serve("/api/v1/sync/events"); !strings.Contains(line, "level=INFO") {
+		t.Errorf("unmatched route logged %q, want info", line)
+	}
+}
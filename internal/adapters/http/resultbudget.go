@@ -0,0 +1,177 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// Size estimates of a query result in memory, in bytes: a feature's fixed
+// overhead (struct, map header, layer name) and a property's beyond its key
+// and value bytes.
+const (
+	featureOverheadBytes  = 256
+	propertyOverheadBytes = 48
+	// initialResultEstimate is reserved per query until the budget has seen
+	// actual results to average over.
+	initialResultEstimate = 64 * 1024
+)
+
+// errBudgetExhausted rejects a query that found no room in the budget within
+// the wait.
+var errBudgetExhausted = errors.New("query memory budget exhausted")
+
+// resultBudget bounds the memory held by the result sets of the queries in
+// flight (query.memory). A query is admitted when the estimate of its result,
+// the running average of the results so far, still fits; otherwise it waits up
+// to wait for earlier queries to finish, then is rejected. Once a query has its
+// result, the reservation is replaced by the result's estimated size, which
+// may take the budget past its limit: the query runs to the end, and new
+// queries wait until the memory is released. A single query is always
+// admitted into an empty budget, so a large result is slow rather than
+// impossible.
+type resultBudget struct {
+	limit int64
+	wait  time.Duration
+
+	mu      sync.Mutex
+	used    int64
+	average int64         // running average of the charged result sizes
+	freed   chan struct{} // closed (and replaced) on every release to wake waiters
+}
+
+func newResultBudget(limit int64, wait time.Duration) *resultBudget {
+	return &resultBudget{limit: limit, wait: wait, average: initialResultEstimate, freed: make(chan struct{})}
+}
+
+// budgetTicket is an admitted query's share of the budget. Its methods do
+// nothing on a nil ticket, so handlers run the same calls without a budget.
+type budgetTicket struct {
+	b        *resultBudget
+	reserved int64
+	charged  bool
+}
+
+// admit reserves room for one query's result, waiting for it as long as the
+// budget allows. It returns errBudgetExhausted when the wait runs out, or the
+// context's error when the client goes away first.
+func (b *resultBudget) admit(ctx context.Context) (*budgetTicket, error) {
+	t, freed := b.tryAdmit()
+	if t != nil {
+		return t, nil
+	}
+	if b.wait <= 0 {
+		return nil, errBudgetExhausted
+	}
+	timer := time.NewTimer(b.wait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-freed:
+		case <-timer.C:
+			return nil, errBudgetExhausted
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if t, freed = b.tryAdmit(); t != nil {
+			return t, nil
+		}
+	}
+}
+
+// tryAdmit reserves the average result size if it fits. Otherwise it returns
+// the channel that is closed on the next release.
+func (b *resultBudget) tryAdmit() (*budgetTicket, <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used == 0 || b.used+b.average <= b.limit {
+		t := &budgetTicket{b: b, reserved: b.average}
+		b.used += t.reserved
+		return t, nil
+	}
+	return nil, b.freed
+}
+
+// charge replaces the ticket's reservation with size, the estimate of the
+// result the query produced, and folds it into the running average.
+func (t *budgetTicket) charge(size int64) {
+	if t == nil || t.charged {
+		return
+	}
+	b := t.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used += size - t.reserved
+	t.reserved, t.charged = size, true
+	// Weight the newest result by 1/8, enough to follow a shift in traffic
+	// without one outlier doubling every reservation.
+	b.average += (max(size, 1024) - b.average) / 8
+}
+
+// release returns the ticket's share once the response is written.
+func (t *budgetTicket) release() {
+	if t == nil {
+		return
+	}
+	b := t.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= t.reserved
+	t.reserved = 0
+	close(b.freed)
+	b.freed = make(chan struct{})
+}
+
+// admitQuery admits a query into the result budget. On rejection it writes a
+// 503 with Retry-After and returns false; a query without a budget gets a nil
+// ticket.
+func (s *Server) admitQuery(w http.ResponseWriter, r *http.Request) (*budgetTicket, bool) {
+	if s.resultBudget == nil {
+		return nil, true
+	}
+	t, err := s.resultBudget.admit(r.Context())
+	if err == nil {
+		return t, true
+	}
+	if errors.Is(err, errBudgetExhausted) {
+		s.logger.Warn("query rejected: memory budget exhausted", "path", r.URL.Path)
+		w.Header().Set("Retry-After", "1")
+		s.writeError(w, r, http.StatusServiceUnavailable, "Server is busy with large queries; retry shortly")
+	}
+	// Otherwise the client is gone; there is no one to answer.
+	return nil, false
+}
+
+// estimateResponseBytes estimates the memory a query response holds: its
+// features with their geometry encodings and properties.
+func estimateResponseBytes(resp *domain.QueryResponse) int64 {
+	if resp == nil {
+		return 0
+	}
+	var n int64
+	for i := range resp.Results {
+		for j := range resp.Results[i].Features {
+			n += estimateFeatureBytes(&resp.Results[i].Features[j])
+		}
+	}
+	return n
+}
+
+func estimateFeatureBytes(f *domain.Feature) int64 {
+	g := &f.Geometry
+	n := int64(featureOverheadBytes + len(g.WKT) + len(g.WKB) + len(g.GeoJSON) + len(g.GML))
+	for k, v := range f.Properties {
+		n += int64(propertyOverheadBytes + len(k))
+		switch x := v.(type) {
+		case string:
+			n += int64(len(x))
+		case []byte:
+			n += int64(len(x))
+		}
+	}
+	return n
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// TestResultBudgetAdmission: queries are admitted while the average result
+// fits, the first one always, and a charged result past the limit holds off
+// the next query until it is released.
+func TestResultBudgetAdmission(t *testing.T) {
+	b := newResultBudget(2*initialResultEstimate, 0)
+	ctx := context.Background()
+
+	first, err := b.admit(ctx)
+	if err != nil {
+		t.Fatalf("first admit: %v", err)
+	}
+	second, err := b.admit(ctx)
+	if err != nil {
+		t.Fatalf("second admit: %v", err)
+	}
+	if _, err := b.admit(ctx); !errors.Is(err, errBudgetExhausted) {
+		t.Fatalf("third admit err = %v, want errBudgetExhausted", err)
+	}
+
+	second.release()
+	first.charge(10 * initialResultEstimate) // larger than estimated: still completes
+	if _, err := b.admit(ctx); !errors.Is(err, errBudgetExhausted) {
+		t.Fatalf("admit past the limit err = %v, want errBudgetExhausted", err)
+	}
+	first.release()
+	if b.used != 0 {
+		t.Fatalf("used after release = %d, want 0", b.used)
+	}
+	if b.average <= initialResultEstimate {
+		t.Errorf("average = %d, want it raised by the large result", b.average)
+	}
+	huge, err := b.admit(ctx)
+	if err != nil {
+		t.Fatalf("admit into an empty budget: %v", err)
+	}
+	huge.release()
+}
+
+// TestResultBudgetWait: a waiting query is admitted as soon as a release makes
+// room, and gives up when the client goes away.
+func TestResultBudgetWait(t *testing.T) {
+	b := newResultBudget(initialResultEstimate, time.Minute)
+	held, err := b.admit(context.Background())
+	if err != nil {
+		t.Fatalf("admit: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		tk, err := b.admit(context.Background())
+		if err == nil {
+			tk.release()
+		}
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	held.release()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("waiting admit: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting admit not woken by the release")
+	}
+
+	held, _ = b.admit(context.Background())
+	defer held.release()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.admit(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("admit with a canceled context err = %v, want context.Canceled", err)
+	}
+}
+
+func TestNilBudgetTicket(_ *testing.T) {
+	var tk *budgetTicket
+	tk.charge(1)
+	tk.release()
+}
+
+func TestEstimateResponseBytes(t *testing.T) {
+	resp := &domain.QueryResponse{Results: []domain.QueryResult{{Features: []domain.Feature{
+		{Properties: map[string]interface{}{"name": "Mitte", "pop": int64(1)}, Geometry: domain.Geometry{WKT: "POINT(1 2)"}},
+	}}}}
+	want := int64(featureOverheadBytes + len("POINT(1 2)") +
+		propertyOverheadBytes + len("name") + len("Mitte") +
+		propertyOverheadBytes + len("pop"))
+	if got := estimateResponseBytes(resp); got != want {
+		t.Errorf("estimateResponseBytes = %d, want %d", got, want)
+	}
+	if got := estimateResponseBytes(nil); got != 0 {
+		t.Errorf("estimateResponseBytes(nil) = %d, want 0", got)
+	}
+}
+
+// TestQueryRejectedOverBudget: a query that finds the budget full gets a 503
+// with Retry-After.
+func TestQueryRejectedOverBudget(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	srv.resultBudget = newResultBudget(1, 0)
+	held, err := srv.resultBudget.admit(context.Background())
+	if err != nil {
+		t.Fatalf("admit: %v", err)
+	}
+	defer held.release()
+
+	rec := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/query?lon=10&lat=50", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q, want 1", rec.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// routeRequest is the POST /api/v1/query/route body.
+type routeRequest struct {
+	// Route is a GeoJSON LineString (a geometry or a Feature holding one) or
+	// a WKT LINESTRING string.
+	Route      json.RawMessage `json:"route"`
+	SRID       int             `json:"srid"`       // SRID of the route's coordinates; default 4326
+	Sources    []string        `json:"sources"`    // optional: restrict to these source ids
+	Properties []string        `json:"properties"` // optional: only these feature properties
+}
+
+// geoJSONLine is a GeoJSON LineString, or a Feature whose geometry is one.
+type geoJSONLine struct {
+	Type        string       `json:"type"`
+	Coordinates [][]float64  `json:"coordinates"`
+	Geometry    *geoJSONLine `json:"geometry"`
+}
+
+// parseRoute resolves the route of the request body to a domain.Route.
+func (req *routeRequest) parseRoute() (domain.Route, error) {
+	srid := req.SRID
+	if srid == 0 {
+		srid = domain.SRIDWGS84
+	}
+	if len(req.Route) == 0 || string(req.Route) == "null" {
+		return domain.Route{}, errors.New("route required: provide a GeoJSON LineString or a WKT LINESTRING")
+	}
+	if req.Route[0] == '"' {
+		var wkt string
+		if err := json.Unmarshal(req.Route, &wkt); err != nil {
+			return domain.Route{}, fmt.Errorf("invalid route: %w", err)
+		}
+		route, err := domain.ParseRouteWKT(wkt, srid)
+		if err != nil {
+			var ve *domain.ValidationError
+			if errors.As(err, &ve) {
+				return domain.Route{}, errors.New(ve.Message)
+			}
+			return domain.Route{}, err
+		}
+		return route, nil
+	}
+
+	var line geoJSONLine
+	if err := json.Unmarshal(req.Route, &line); err != nil {
+		return domain.Route{}, fmt.Errorf("invalid route: %w", err)
+	}
+	if line.Type == "Feature" && line.Geometry != nil {
+		line = *line.Geometry
+	}
+	if line.Type != "LineString" {
+		return domain.Route{}, fmt.Errorf("route must be a GeoJSON LineString, got %q", line.Type)
+	}
+	points := make([]domain.Coordinate, len(line.Coordinates))
+	for i, c := range line.Coordinates {
+		if len(c) < 2 {
+			return domain.Route{}, fmt.Errorf("route position %d needs at least x and y", i)
+		}
+		points[i] = domain.Coordinate{X: c[0], Y: c[1]}
+	}
+	return domain.NewRoute(points, srid), nil
+}
+
+// handleQueryRoute returns the features along a route — every feature whose
+// geometry the line intersects — per source, in one request instead of a
+// point query per position of a track.
+func (s *Server) handleQueryRoute(w http.ResponseWriter, r *http.Request) {
+	// ~64 B per GeoJSON position, plus headroom for the rest of the body.
+	r.Body = http.MaxBytesReader(w, r.Body, int64(s.routeMaxPoints)*64+64*1024)
+	var req routeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			s.writeError(w, r, http.StatusRequestEntityTooLarge, "request body too large — send a route with fewer points")
+			return
+		}
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid JSON body: %v", err))
+		return
+	}
+	route, err := req.parseRoute()
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(route.Points) > s.routeMaxPoints {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Sprintf("route of %d points exceeds the limit of %d", len(route.Points), s.routeMaxPoints))
+		return
+	}
+
+	ticket, ok := s.admitQuery(w, r)
+	if !ok {
+		return
+	}
+	defer ticket.release()
+
+	resp, err := s.queryService.QueryRoute(r.Context(), route, req.Sources, req.Properties)
+	if err != nil {
+		s.handleQueryError(w, r, err)
+		return
+	}
+	ticket.charge(estimateResponseBytes(resp))
+	out := s.formatQueryResponse(resp)
+	s.writeJSON(w, http.StatusOK, RouteResponseDTO{
+		Route:            RouteDTO{Points: len(route.Points), SRID: route.SRID},
+		Results:          out.Results,
+		TotalFeatures:    out.TotalFeatures,
+		ProcessingTimeMS: out.ProcessingTimeMS,
+	})
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/jobrunner/ortus/internal/application"
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// newRouteServer builds a Server for route tests with a mock (empty) source
+// pool and a route point limit of maxPoints.
+func newRouteServer(t *testing.T, maxPoints int) *Server {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	reg := application.NewSourceRegistry(
+		[]output.SpatialSource{&mockRepository{}}, &mockStorage{},
+		noop.NewMeterProvider().Meter("test"), output.NoOpTracer{}, logger, "/tmp")
+	_ = reg.LoadAll(context.Background())
+	health := application.NewHealthService(reg, true, output.NoOpTracer{})
+	query := application.NewQueryService(reg, nil, noop.NewMeterProvider().Meter("test"),
+		output.NoOpTracer{}, logger, application.QueryServiceConfig{})
+	return NewServer(
+		config.ServerConfig{Host: "localhost", Port: 8080, ReadTimeout: time.Second, WriteTimeout: time.Second},
+		query, reg, health, nil, logger, false,
+		ServerOptions{RouteMaxPoints: maxPoints},
+	)
+}
+
+func doRoute(t *testing.T, srv *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/query/route", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rec, req)
+	return rec
+}
+
+// TestQueryRouteAcceptsGeoJSONAndWKT: a GeoJSON LineString, a Feature holding
+// one and a WKT string all answer 200 with the route echoed.
+func TestQueryRouteAcceptsGeoJSONAndWKT(t *testing.T) {
+	srv := newRouteServer(t, 100)
+	bodies := map[string]string{
+		"geojson": `{"route":{"type":"LineString","coordinates":[[9.93,49.79],[10.1,49.8],[10.2,49.9]]}}`,
+		"feature": `{"route":{"type":"Feature","geometry":{"type":"LineString","coordinates":[[9.93,49.79],[10.1,49.8],[10.2,49.9]]}}}`,
+		"wkt":     `{"route":"LINESTRING(9.93 49.79, 10.1 49.8, 10.2 49.9)"}`,
+	}
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			rec := doRoute(t, srv, body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+			}
+			var resp RouteResponseDTO
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if resp.Route.Points != 3 || resp.Route.SRID != 4326 {
+				t.Errorf("route = %+v, want 3 points in 4326", resp.Route)
+			}
+		})
+	}
+}
+
+// TestQueryRouteBadRequest: a missing, malformed or too short route, and one
+// over the point limit, are 400.
+func TestQueryRouteBadRequest(t *testing.T) {
+	srv := newRouteServer(t, 3)
+	tests := map[string]string{
+		"no route":     `{}`,
+		"invalid json": `{"route":`,
+		"polygon":      `{"route":{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,1],[0,0]]]}}`,
+		"bad wkt":      `{"route":"POINT(1 2)"}`,
+		"one point":    `{"route":"LINESTRING(1 2)"}`,
+		"out of range": `{"route":"LINESTRING(1 2, 200 2)"}`,
+		"too many":     `{"route":"LINESTRING(1 1, 2 2, 3 3, 4 4)"}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			if rec := doRoute(t, srv, body); rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400 (body: %s)", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestQueryRouteBodyTooLarge: a body far beyond what the point limit allows
+// is cut off with 413.
+func TestQueryRouteBodyTooLarge(t *testing.T) {
+	srv := newRouteServer(t, 1)
+	body := `{"route":"LINESTRING(` + strings.Repeat("1 1, ", 20000) + `2 2)"}`
+	if rec := doRoute(t, srv, body); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", rec.Code)
+	}
+}
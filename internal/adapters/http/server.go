@@ -4,9 +4,11 @@ package http
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -36,17 +38,48 @@ type Server struct {
 	transformer      output.CoordinateTransformer // reprojects a non-WGS84 query coord to WGS84 for the wgs84 block + gazetteer enrichment; nil ⇒ only WGS84 inputs are enriched
 	logger           *slog.Logger
 	config           config.ServerConfig
-	withGeometry     bool                 // Include geometry in query results
-	tracerProvider   trace.TracerProvider // Used by otelmux middleware; may be nil
-	serviceName      string               // Used as otelmux service name; defaults to "ortus"
-	httpMetrics      *httpMetrics         // HTTP-level instruments; nil when metrics disabled
-	rateLimiter      *ipRateLimiter       // per-IP limiter; nil unless server.rate_limit.enabled
-	trustedProxies   []*net.IPNet         // proxy CIDRs allowed to set X-Forwarded-For
-	version          string               // build version, shown in the frontend footer
-	frontendPage     []byte               // frontend HTML pre-rendered with the version, built once in NewServer
-	batchMaxPoints   int                  // POST /query/batch hard cap
-	batchMaxSync     int                  // POST /query/batch sync-JSON cap (over → 413, stream instead)
-	batchConcurrency int                  // per-point gazetteer-enrichment worker pool for batch
+	withGeometry     bool                         // Include geometry in query results
+	tracerProvider   trace.TracerProvider         // Used by otelmux middleware; may be nil
+	serviceName      string                       // Used as otelmux service name; defaults to "ortus"
+	httpMetrics      *httpMetrics                 // HTTP-level instruments; nil when metrics disabled
+	live             atomic.Pointer[liveSettings] // CORS and rate limiting; swapped by Reconfigure
+	version          string                       // build version, shown in the frontend footer
+	frontendPages    map[string][]byte            // frontend HTML per language, pre-rendered with the version in NewServer
+	routePaths       map[string]bool              // registered path templates; /openapi.json documents only these
+	batchMaxPoints   int                          // POST /query/batch hard cap
+	batchMaxSync     int                          // POST /query/batch sync-JSON cap (over → 413, stream instead)
+	batchConcurrency int                          // per-point gazetteer-enrichment worker pool for batch
+	routeMaxPoints   int                          // POST /query/route cap on route points
+	areaMaxPoints    int                          // POST /query/area cap on polygon points
+	aggMaxGroups     int                          // POST /query/aggregate cap on groups per layer
+	flags            output.FeatureFlags          // runtime kill-switches; nil ⇒ every flag on
+	maintenance      input.Maintenance            // operator maintenance switch; nil ⇒ no /admin routes
+	popularity       input.Popularity             // per-source hit ranking; nil ⇒ no /popularity route
+	peers            input.PeerCatalog            // federation peer catalog; nil ⇒ no /peers route
+	geocoder         input.Geocoder               // address search; nil ⇒ no /geocode route
+	sourceHealth     input.SourceHealthReporter   // per-source health; nil ⇒ no /sources/{id}/health route
+	tables           input.AttributeTables        // attribute tables; nil ⇒ no /sources/{id}/tables routes
+	tiles            input.Tiles                  // tile pyramids; nil ⇒ no /tiles route
+	configReloader   input.ConfigReloader         // config hot-reload; nil ⇒ no /admin/reload-config route
+	audit            input.AuditTrail             // audit log; nil ⇒ no /admin/audit route
+	optimizer        input.SourceOptimizer        // source maintenance; nil ⇒ no /admin/sources/{id}/optimize route
+	stager           input.SourceStager           // candidate versions; nil ⇒ no /admin/sources/{id}/stage routes
+	changeSyncer     input.ChangeSyncer           // storage change sync; nil ⇒ no /sync/events route
+	syncEventsToken  string                       // ?token= the /sync/events webhook requires
+	accessLog        *accessLog                   // per-request traffic log; nil ⇒ off
+	requestLogRules  []*requestLogRule            // per-path level and sampling of the request log
+	resultBudget     *resultBudget                // memory budget of in-flight query results; nil ⇒ unbounded
+	queryStats       *queryStats                  // recent query rate and latency for /stats/summary
+	syncStatus       input.SyncStatus             // last sync time; nil ⇒ no last_sync in /stats/summary
+	cacheStats       output.CacheStatsReporter    // tile cache lookups; nil ⇒ no cache_hit_rate in /stats/summary
+}
+
+// liveSettings are the server settings a config reload can change. They are
+// swapped as a whole, so a request never sees half of a reload.
+type liveSettings struct {
+	corsOrigins    []string       // empty ⇒ no CORS headers
+	rateLimiter    *ipRateLimiter // per-IP limiter; nil unless server.rate_limit.enabled
+	trustedProxies []*net.IPNet   // proxy CIDRs allowed to set X-Forwarded-For and X-Real-IP
 }
 
 // ServerOptions wraps optional dependencies the HTTP server can use, such as
@@ -60,6 +93,9 @@ type ServerOptions struct {
 	BearingPolicy    domain.BearingPolicy // optional bearing tuning; zero value falls back to DefaultBearingPolicy
 	GazetteerLicense domain.License       // optional dataset license/attribution surfaced in the gazetteer block
 	Version          string               // build version shown in the frontend footer (defaults to "dev")
+	// Branding brands the frontend (logo, accent color, footer text). Optional:
+	// the zero value keeps the stock look.
+	Branding config.FrontendBrandingConfig
 	// Transformer reprojects a non-WGS84 query coordinate to WGS84 so the response
 	// carries a `wgs84` block and the gazetteer can enrich any SRID. Optional: when
 	// nil, only WGS84 inputs get the wgs84 block + gazetteer enrichment.
@@ -68,6 +104,85 @@ type ServerOptions struct {
 	BatchMaxPoints     int // hard cap per request
 	BatchMaxSyncPoints int // sync-JSON cap; over this → 413 (stream instead)
 	BatchConcurrency   int // per-point gazetteer-enrichment worker pool
+	// RouteMaxPoints caps the points of a POST /api/v1/query/route route
+	// (0 ⇒ built-in default).
+	RouteMaxPoints int
+	// AreaMaxPoints caps the points of a POST /api/v1/query/area polygon, all
+	// rings together (0 ⇒ built-in default).
+	AreaMaxPoints int
+	// AggregateMaxGroups caps the groups a POST /api/v1/query/aggregate
+	// returns per layer (0 ⇒ built-in default).
+	AggregateMaxGroups int
+	// Flags gates rollout subsystems (wgs84 reprojection, parallel batch
+	// enrichment) at runtime. Optional: nil leaves them all on.
+	Flags output.FeatureFlags
+	// Maintenance is the operator maintenance switch, served under /admin when
+	// server.admin.enabled. Optional: nil serves no admin routes.
+	Maintenance input.Maintenance
+	// Popularity ranks sources by recent hits for GET /api/v1/popularity.
+	// Optional: nil serves no such route.
+	Popularity input.Popularity
+	// Peers lists the federation peers for GET /api/v1/peers. Optional: nil
+	// (federation off) serves no such route.
+	Peers input.PeerCatalog
+	// Geocoder resolves addresses for GET /api/v1/geocode. Optional: nil (no
+	// geocoder.provider) serves no such route.
+	Geocoder input.Geocoder
+	// SourceHealth reports a single source's load state for
+	// GET /api/v1/sources/{sourceId}/health. Optional: nil serves no such route.
+	SourceHealth input.SourceHealthReporter
+	// Tables serves the sources' attribute (non-spatial) tables under
+	// GET /api/v1/sources/{sourceId}/tables. Optional: nil serves no such routes.
+	Tables input.AttributeTables
+	// Tiles serves the sources' tile pyramids under
+	// GET /api/v1/tiles/{sourceId}/{table}/{z}/{x}/{y}. Optional: nil serves
+	// no such route.
+	Tiles input.Tiles
+	// ConfigReloader re-reads the config for POST /admin/reload-config.
+	// Optional: nil serves no such route.
+	ConfigReloader input.ConfigReloader
+	// Audit serves the audit log at GET /admin/audit. Optional: nil serves
+	// no such route.
+	Audit input.AuditTrail
+	// Optimizer rebuilds indexes, analyzes and vacuums sources for
+	// /admin/sources/{sourceId}/optimize. Optional: nil serves no such route.
+	Optimizer input.SourceOptimizer
+	// Stager stages and promotes candidate versions of sources for
+	// /admin/sources/{sourceId}/stage and /promote, and serves them to
+	// queries with ?stage=candidate. Optional: nil serves no such routes.
+	Stager input.SourceStager
+	// ChangeSyncer applies the Azure Event Grid blob events posted to
+	// POST /api/v1/sync/events, authenticated by SyncEventsToken in the
+	// query string. Optional: nil serves no such route.
+	ChangeSyncer    input.ChangeSyncer
+	SyncEventsToken string
+	// AccessLog receives one line per request in AccessLogFormat (common,
+	// combined — the default — or json). Optional: nil writes no access log.
+	AccessLog       io.Writer
+	AccessLogFormat string
+	// RequestLog sets the level and sampling of the request log per path
+	// (logging.requests). Optional: nil logs every request at info.
+	RequestLog []config.RequestLogRule
+	// ResultBudgetBytes bounds the estimated memory of the query results in
+	// flight; a query that does not fit waits up to ResultBudgetWait, then
+	// gets a 503. Optional: 0 leaves the results unbounded.
+	ResultBudgetBytes int64
+	ResultBudgetWait  time.Duration
+	// SyncStatus reports the last storage sync for GET /api/v1/stats/summary.
+	// Optional: nil leaves last_sync out.
+	SyncStatus input.SyncStatus
+	// CacheStats reports the query-path cache lookups for
+	// GET /api/v1/stats/summary. Optional: nil leaves cache_hit_rate out.
+	CacheStats output.CacheStatsReporter
+}
+
+// flagEnabled evaluates a rollout flag; without a flags provider every flag
+// is on.
+func (s *Server) flagEnabled(ctx context.Context, flag domain.FeatureFlag) bool {
+	if s.flags == nil {
+		return true
+	}
+	return s.flags.Enabled(ctx, flag, true)
 }
 
 // NewServer creates a new HTTP server.
@@ -93,10 +208,10 @@ func NewServer(
 
 	// Pre-render the frontend once, but only when the route is actually served —
 	// an API-only deployment (frontend_enabled: false) shouldn't hold a copy of
-	// the embedded HTML.
-	var frontendPage []byte
-	if cfg.FrontendEnabled {
-		frontendPage = renderFrontend(version)
+	// the embedded HTML, and neither should one serving its own (static_dir).
+	var frontendPages map[string][]byte
+	if cfg.FrontendEnabled && cfg.StaticDir == "" {
+		frontendPages = renderFrontendPages(version, opts.Branding)
 	}
 
 	var httpM *httpMetrics
@@ -120,44 +235,95 @@ func NewServer(
 		serviceName:      serviceName,
 		httpMetrics:      httpM,
 		version:          version,
-		frontendPage:     frontendPage,
+		frontendPages:    frontendPages,
 		batchMaxPoints:   firstPositive(opts.BatchMaxPoints, 10000),
 		batchMaxSync:     firstPositive(opts.BatchMaxSyncPoints, 1000),
 		batchConcurrency: firstPositive(opts.BatchConcurrency, 4),
+		routeMaxPoints:   firstPositive(opts.RouteMaxPoints, 10000),
+		areaMaxPoints:    firstPositive(opts.AreaMaxPoints, 10000),
+		aggMaxGroups:     firstPositive(opts.AggregateMaxGroups, 1000),
+		flags:            opts.Flags,
+		maintenance:      opts.Maintenance,
+		popularity:       opts.Popularity,
+		peers:            opts.Peers,
+		geocoder:         opts.Geocoder,
+		sourceHealth:     opts.SourceHealth,
+		tables:           opts.Tables,
+		tiles:            opts.Tiles,
+		configReloader:   opts.ConfigReloader,
+		audit:            opts.Audit,
+		optimizer:        opts.Optimizer,
+		stager:           opts.Stager,
+		changeSyncer:     opts.ChangeSyncer,
+		syncEventsToken:  opts.SyncEventsToken,
+		requestLogRules:  newRequestLogRules(opts.RequestLog),
+		queryStats:       newQueryStats(),
+		syncStatus:       opts.SyncStatus,
+		cacheStats:       opts.CacheStats,
+	}
+	if opts.AccessLog != nil {
+		s.accessLog = newAccessLog(opts.AccessLog, opts.AccessLogFormat)
+	}
+	if opts.ResultBudgetBytes > 0 {
+		s.resultBudget = newResultBudget(opts.ResultBudgetBytes, opts.ResultBudgetWait)
 	}
+	s.Reconfigure(cfg)
+
+	s.router = s.setupRoutes()
+	s.routePaths = routeTemplates(s.router)
+
+	s.server = &http.Server{
+		Addr:         cfg.Address(),
+		Handler:      s.Handler(),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	}
+
+	return s
+}
+
+// Reconfigure swaps in the CORS origins and rate limit of cfg. The other
+// server settings (address, timeouts, admin token, …) only take effect on a
+// restart. The per-IP buckets survive a reload that leaves rate and burst
+// unchanged.
+func (s *Server) Reconfigure(cfg config.ServerConfig) {
+	next := &liveSettings{corsOrigins: cfg.CORS.AllowedOrigins}
+
+	trusted, invalid := parseCIDRs(cfg.TrustedProxyCIDRs())
+	if len(invalid) > 0 {
+		s.logger.Warn("ignoring invalid trusted_proxies CIDRs — X-Forwarded-For will not be trusted for these",
+			"invalid", invalid)
+	}
+	next.trustedProxies = trusted
 
 	// Opt-in per-IP rate limiting (off by default). Only the /api/v1 surface is
 	// limited; health/probe endpoints are never throttled.
-	if cfg.RateLimit.Enabled {
-		if cfg.RateLimit.Rate <= 0 {
+	if rl := cfg.RateLimit; rl.Enabled {
+		if rl.Rate <= 0 {
 			// Fail safe: a non-positive rate would deny all traffic after the
 			// burst. Treat as a misconfiguration and leave limiting OFF.
-			logger.Warn("rate limiting requested but rate <= 0 — leaving it DISABLED",
-				"rate", cfg.RateLimit.Rate)
+			s.logger.Warn("rate limiting requested but rate <= 0 — leaving it DISABLED",
+				"rate", rl.Rate)
 		} else {
-			trusted, invalid := parseCIDRs(cfg.RateLimit.TrustedProxies)
-			if len(invalid) > 0 {
-				logger.Warn("ignoring invalid trusted_proxies CIDRs — X-Forwarded-For will not be trusted for these",
-					"invalid", invalid)
+			next.rateLimiter = newIPRateLimiter(rl.Rate, rl.Burst)
+			if prev := s.live.Load(); prev != nil && prev.rateLimiter.sameLimit(next.rateLimiter) {
+				next.rateLimiter = prev.rateLimiter
 			}
-			s.rateLimiter = newIPRateLimiter(cfg.RateLimit.Rate, cfg.RateLimit.Burst)
-			s.trustedProxies = trusted
-			logger.Info("rate limiting enabled",
-				"rate", cfg.RateLimit.Rate, "burst", cfg.RateLimit.Burst,
+			s.logger.Info("rate limiting enabled",
+				"rate", rl.Rate, "burst", rl.Burst,
 				"trusted_proxies", len(trusted))
 		}
 	}
 
-	s.router = s.setupRoutes()
+	s.live.Store(next)
+}
 
-	s.server = &http.Server{
-		Addr:         cfg.Address(),
-		Handler:      s.router,
-		ReadTimeout:  cfg.ReadTimeout,
-		WriteTimeout: cfg.WriteTimeout,
+// settings returns the current live settings.
+func (s *Server) settings() *liveSettings {
+	if l := s.live.Load(); l != nil {
+		return l
 	}
-
-	return s
+	return &liveSettings{}
 }
 
 // setupRoutes configures all HTTP routes.
@@ -201,27 +367,46 @@ func (s *Server) setupRoutes() *mux.Router {
 	// code. If we ever want to count unmatched traffic, the fix is to wrap
 	// those handlers with the same middleware chain manually.
 
-	// Add CORS middleware if configured
-	if s.config.CORS.Enabled() {
-		r.Use(s.corsMiddleware)
-	}
+	// CORS is always mounted: a config reload can switch it on. Without
+	// allowed origins the middleware passes requests through untouched.
+	r.Use(s.corsMiddleware)
+
+	// Request size limits come after CORS, so a browser can read the 413 or
+	// 414 they answer with.
+	r.Use(s.requestLimitsMiddleware)
 
 	// Health endpoints
 	r.HandleFunc("/health", s.handleHealth).Methods(http.MethodGet)
 	r.HandleFunc("/health/live", s.handleLiveness).Methods(http.MethodGet)
 	r.HandleFunc("/health/ready", s.handleReadiness).Methods(http.MethodGet)
+	r.HandleFunc("/health/startup", s.handleStartup).Methods(http.MethodGet)
+
+	// Operator endpoints: token-protected, and like the health probes never
+	// rate limited, so an operator can always switch maintenance back off.
+	if s.config.Admin.Enabled && (s.maintenance != nil || s.configReloader != nil || s.audit != nil || s.optimizer != nil || s.stager != nil) {
+		s.setupAdminRoutes(r.PathPrefix("/admin").Subrouter())
+	}
+	if s.config.Admin.Enabled && s.config.Admin.Pprof {
+		s.setupPprofRoutes(r.PathPrefix("/debug/pprof").Subrouter())
+	}
 
 	// API v1
 	api := r.PathPrefix("/api/v1").Subrouter()
 
 	// Per-IP rate limiting on the API surface only (never on /health probes).
-	if s.rateLimiter != nil {
-		api.Use(s.rateLimitMiddleware)
+	// Mounted unconditionally so a config reload can switch it on.
+	api.Use(s.rateLimitMiddleware)
+	if s.config.Admin.Enabled && s.stager != nil {
+		api.Use(s.stageMiddleware)
 	}
+	api.Use(s.queryStatsMiddleware)
 
 	// Query endpoints
 	api.HandleFunc("/query", s.handleQuery).Methods(http.MethodGet)
 	api.HandleFunc("/query/batch", s.handleQueryBatch).Methods(http.MethodPost)
+	api.HandleFunc("/query/route", s.handleQueryRoute).Methods(http.MethodPost)
+	api.HandleFunc("/query/area", s.handleQueryArea).Methods(http.MethodPost)
+	api.HandleFunc("/query/aggregate", s.handleQueryAggregate).Methods(http.MethodPost)
 	api.HandleFunc("/query/{sourceId}", s.handleQuerySource).Methods(http.MethodGet)
 
 	// Gazetteer endpoint (reverse geocode + bearing) — only when the feature is wired.
@@ -231,36 +416,106 @@ func (s *Server) setupRoutes() *mux.Router {
 
 	// Source management endpoints
 	api.HandleFunc("/sources", s.handleListSources).Methods(http.MethodGet)
+	api.HandleFunc("/catalog", s.handleCatalog).Methods(http.MethodGet)
 	api.HandleFunc("/sources/{sourceId}", s.handleGetSource).Methods(http.MethodGet)
 	api.HandleFunc("/sources/{sourceId}/layers", s.handleGetLayers).Methods(http.MethodGet)
+	if s.sourceHealth != nil {
+		api.HandleFunc("/sources/{sourceId}/health", s.handleSourceHealth).Methods(http.MethodGet)
+	}
+	if s.tables != nil {
+		api.HandleFunc("/sources/{sourceId}/tables", s.handleListTables).Methods(http.MethodGet)
+		api.HandleFunc("/sources/{sourceId}/tables/{table}", s.handleReadTable).Methods(http.MethodGet)
+	}
+	if s.tiles != nil {
+		api.HandleFunc("/tiles/{sourceId}/{table}/{z}/{x}/{y}", s.handleTile).Methods(http.MethodGet)
+	}
+	if s.popularity != nil {
+		api.HandleFunc("/popularity", s.handlePopularity).Methods(http.MethodGet)
+	}
+	if s.peers != nil {
+		api.HandleFunc("/peers", s.handlePeers).Methods(http.MethodGet)
+	}
+	if s.geocoder != nil {
+		api.HandleFunc("/geocode", s.handleGeocode).Methods(http.MethodGet)
+	}
+	api.HandleFunc("/stats/summary", s.handleStatsSummary).Methods(http.MethodGet)
 
 	// Sync endpoint (only if sync service is configured)
 	if s.syncService != nil {
 		api.HandleFunc("/sync", s.handleSync).Methods(http.MethodPost)
 	}
+	if s.changeSyncer != nil {
+		api.HandleFunc("/sync/events", s.handleSyncEvents).Methods(http.MethodPost)
+	}
 
-	// OpenAPI spec and Swagger UI
+	// STAC catalog of the sources, for catalog harvesters
+	r.HandleFunc("/stac", s.handleSTACCatalog).Methods(http.MethodGet)
+	r.HandleFunc("/stac/collections/{sourceId}", s.handleSTACCollection).Methods(http.MethodGet)
+
+	// OpenAPI spec, XML response schema and Swagger UI
 	r.HandleFunc("/openapi.json", s.handleOpenAPI).Methods(http.MethodGet)
+	r.HandleFunc("/schemas/query-response.xsd", s.handleQueryResponseXSD).Methods(http.MethodGet)
 	r.HandleFunc("/docs", s.handleSwaggerUI).Methods(http.MethodGet)
 	r.HandleFunc("/swagger", s.handleSwaggerUI).Methods(http.MethodGet)
-
-	// Frontend for coordinate queries (if enabled)
-	if s.config.FrontendEnabled {
+	r.PathPrefix(swaggerUIAssetPath).Handler(swaggerUIAssetHandler()).Methods(http.MethodGet)
+
+	// Frontend for coordinate queries (if enabled): the operator's own files
+	// from server.static_dir, else the embedded page. The static catch-all is
+	// registered last, so every route above takes precedence.
+	switch {
+	case s.config.FrontendEnabled && s.config.StaticDir != "":
+		r.PathPrefix("/").Handler(staticHandler(s.config.StaticDir)).Methods(http.MethodGet, http.MethodHead)
+	case s.config.FrontendEnabled:
 		r.HandleFunc("/", s.handleFrontend).Methods(http.MethodGet)
 	}
 
 	return r
 }
 
+// setupAdminRoutes registers the operator endpoints that are wired.
+func (s *Server) setupAdminRoutes(admin *mux.Router) {
+	admin.Use(s.adminAuthMiddleware)
+	if s.maintenance != nil {
+		admin.HandleFunc("/maintenance", s.handleGetMaintenance).Methods(http.MethodGet)
+		admin.HandleFunc("/maintenance", s.handleEnterMaintenance).Methods(http.MethodPut)
+		admin.HandleFunc("/maintenance", s.handleLeaveMaintenance).Methods(http.MethodDelete)
+	}
+	if s.configReloader != nil {
+		admin.HandleFunc("/reload-config", s.handleReloadConfig).Methods(http.MethodPost)
+	}
+	if s.audit != nil {
+		admin.HandleFunc("/audit", s.handleAudit).Methods(http.MethodGet)
+	}
+	if s.optimizer != nil {
+		admin.HandleFunc("/sources/{sourceId}/optimize", s.handleStartOptimize).Methods(http.MethodPost)
+		admin.HandleFunc("/sources/{sourceId}/optimize", s.handleOptimizeProgress).Methods(http.MethodGet)
+	}
+	if s.stager != nil {
+		admin.HandleFunc("/sources/{sourceId}/stage", s.handleStageCandidate).Methods(http.MethodPost)
+		admin.HandleFunc("/sources/{sourceId}/stage", s.handleGetCandidate).Methods(http.MethodGet)
+		admin.HandleFunc("/sources/{sourceId}/stage", s.handleDiscardCandidate).Methods(http.MethodDelete)
+		admin.HandleFunc("/sources/{sourceId}/promote", s.handlePromoteCandidate).Methods(http.MethodPost)
+	}
+}
+
 // Router returns the mux router.
 func (s *Server) Router() *mux.Router {
 	return s.router
 }
 
-// Start starts the HTTP server.
-func (s *Server) Start() error {
-	s.logger.Info("starting HTTP server", "address", s.config.Address())
-	return s.server.ListenAndServe()
+// Handler returns the handler to serve: the router, wrapped in the access
+// log when one is configured.
+func (s *Server) Handler() http.Handler {
+	if s.accessLog == nil {
+		return s.router
+	}
+	return s.accessLogMiddleware(s.router)
+}
+
+// Serve serves HTTP on ln until Shutdown, which also closes ln.
+func (s *Server) Serve(ln net.Listener) error {
+	s.logger.Info("starting HTTP server", "address", ln.Addr().String())
+	return s.server.Serve(ln)
 }
 
 // Shutdown gracefully shuts down the server.
@@ -299,17 +554,22 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 
 		next.ServeHTTP(wrapped, r)
 
+		level, ok := s.requestLogLevel(r.URL.Path, wrapped.statusCode)
+		if !ok {
+			return
+		}
 		fields := []any{
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", wrapped.statusCode,
 			"duration", time.Since(start),
 			"remote_addr", r.RemoteAddr,
+			"client_ip", clientIP(r, s.settings().trustedProxies),
 		}
 		if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
 			fields = append(fields, "trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
 		}
-		s.logger.Info("request", fields...)
+		s.logger.Log(r.Context(), level, "request", fields...)
 	})
 }
 
@@ -327,7 +587,7 @@ func (s *Server) recoveryMiddleware(next http.Handler) http.Handler {
 					span.SetStatus(otelcodes.Error, "panic recovered")
 				}
 				s.logger.Error("panic recovered", fields...)
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				s.writeError(w, r, http.StatusInternalServerError, "Internal Server Error")
 			}
 		}()
 		next.ServeHTTP(w, r)
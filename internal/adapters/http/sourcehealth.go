@@ -0,0 +1,68 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// handleSourceHealth reports one source's status, last load or indexing
+// error, per-layer index coverage and SRID warnings, and last sync time. Unlike
+// /sources/{sourceId} it also answers for a source that failed to load.
+func (s *Server) handleSourceHealth(w http.ResponseWriter, r *http.Request) {
+	sourceID := mux.Vars(r)["sourceId"]
+
+	h, err := s.sourceHealth.SourceHealth(r.Context(), sourceID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSourceNotFound) {
+			s.writeError(w, r, http.StatusNotFound, "Source not found")
+			return
+		}
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to get source health")
+		return
+	}
+
+	layers := make([]map[string]interface{}, len(h.Layers))
+	indexed := 0
+	for i, l := range h.Layers {
+		layers[i] = map[string]interface{}{
+			"name":          l.Name,
+			"indexed":       l.Indexed,
+			"feature_count": l.FeatureCount,
+		}
+		if l.SRIDWarning != "" {
+			layers[i]["srid_warning"] = l.SRIDWarning
+		}
+		if l.InvalidGeometries > 0 {
+			layers[i]["invalid_geometries"] = l.InvalidGeometries
+		}
+		if l.Indexed {
+			indexed++
+		}
+	}
+	out := map[string]interface{}{
+		"id":     h.ID,
+		"status": string(h.Status),
+		"ready":  h.Status == domain.StatusReady,
+		"index_coverage": map[string]interface{}{
+			"indexed": indexed,
+			"total":   len(h.Layers),
+		},
+		"layers": layers,
+	}
+	if h.LastError != "" {
+		out["last_error"] = h.LastError
+		out["last_error_at"] = h.LastErrorAt.UTC().Format(time.RFC3339)
+	}
+	if !h.LoadedAt.IsZero() {
+		out["loaded_at"] = h.LoadedAt.UTC().Format(time.RFC3339)
+	}
+	if !h.SyncedAt.IsZero() {
+		out["last_sync"] = h.SyncedAt.UTC().Format(time.RFC3339)
+	}
+	s.writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+)
+
+type fakeSourceHealth map[string]input.SourceHealth
+
+func (f fakeSourceHealth) SourceHealth(_ context.Context, id string) (input.SourceHealth, error) {
+	h, ok := f[id]
+	if !ok {
+		return input.SourceHealth{}, domain.ErrSourceNotFound
+	}
+	return h, nil
+}
+
+func TestSourceHealthEndpoint(t *testing.T) {
+	at := time.Date(2026, 7, 6, 12, 0, 0, 0, time.UTC)
+	srv := newGazetteerServer(t, fakeGazetteer{})
+	srv.sourceHealth = fakeSourceHealth{
+		"districts": {
+			ID: "districts", Status: domain.StatusReady, LoadedAt: at, SyncedAt: at,
+			LastError: `layer "labels": locked`, LastErrorAt: at,
+			Layers: []input.LayerHealth{{Name: "districts", Indexed: true, FeatureCount: 12}, {Name: "labels", SRIDWarning: "layer declares the undefined SRID 0", InvalidGeometries: 3}},
+		},
+		"broken": {ID: "broken", Status: domain.StatusError, LastError: "not a GeoPackage", LastErrorAt: at},
+	}
+
+	rec, body := doGET(t, srv, "/api/v1/sources/districts/health")
+	if rec.Code != http.StatusOK || body["ready"] != true || body["last_sync"] != "2026-07-06T12:00:00Z" {
+		t.Fatalf("status = %d, body = %v", rec.Code, body)
+	}
+	coverage, _ := body["index_coverage"].(map[string]any)
+	if coverage["indexed"] != float64(1) || coverage["total"] != float64(2) || body["last_error"] != `layer "labels": locked` {
+		t.Errorf("body = %v", body)
+	}
+	layers, _ := body["layers"].([]any)
+	if len(layers) != 2 {
+		t.Fatalf("layers = %v", body["layers"])
+	}
+	if first := layers[0].(map[string]any); first["srid_warning"] != nil || first["invalid_geometries"] != nil {
+		t.Errorf("layer without warning: %v", first)
+	}
+	if second := layers[1].(map[string]any); second["srid_warning"] != "layer declares the undefined SRID 0" || second["invalid_geometries"] != float64(3) {
+		t.Errorf("layer with warning: %v", second)
+	}
+
+	// A source that failed to load is reported, not 404.
+	rec, body = doGET(t, srv, "/api/v1/sources/broken/health")
+	if rec.Code != http.StatusOK || body["status"] != "error" || body["ready"] != false || body["loaded_at"] != nil {
+		t.Errorf("failed source: status = %d, body = %v", rec.Code, body)
+	}
+
+	rec, _ = doGET(t, srv, "/api/v1/sources/unknown/health")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown source status = %d, want 404", rec.Code)
+	}
+}
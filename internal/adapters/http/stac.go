@@ -0,0 +1,169 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// stacVersion is the STAC specification version the documents follow.
+const stacVersion = "1.0.0"
+
+// spdxID matches a license name usable as a STAC license: the shape of an
+// SPDX identifier such as "CC-BY-4.0".
+var spdxID = regexp.MustCompile(`^[A-Za-z0-9.+-]+$`)
+
+// handleSTACCatalog serves the root STAC Catalog: one child link per loaded
+// source, each to its collection.
+func (s *Server) handleSTACCatalog(w http.ResponseWriter, r *http.Request) {
+	sources, err := s.registry.ListSources(r.Context())
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to list sources")
+		return
+	}
+	origin := requestOrigin(r, s.settings().trustedProxies)
+	links := []STACLinkDTO{
+		{Rel: "self", Href: origin + "/stac", Type: "application/json"},
+		{Rel: "root", Href: origin + "/stac", Type: "application/json"},
+	}
+	for i := range sources {
+		links = append(links, STACLinkDTO{
+			Rel:   "child",
+			Href:  stacCollectionURL(origin, sources[i].ID),
+			Type:  "application/json",
+			Title: stacTitle(&sources[i]),
+		})
+	}
+	s.writeJSON(w, http.StatusOK, STACCatalogDTO{
+		Type:        "Catalog",
+		STACVersion: stacVersion,
+		ID:          s.serviceName,
+		Title:       s.serviceName,
+		Description: "Datasets served by " + s.serviceName,
+		Links:       links,
+	})
+}
+
+// handleSTACCollection serves the STAC Collection of a source.
+func (s *Server) handleSTACCollection(w http.ResponseWriter, r *http.Request) {
+	src, err := s.registry.GetSource(r.Context(), mux.Vars(r)["sourceId"])
+	if err != nil {
+		if errors.Is(err, domain.ErrSourceNotFound) {
+			s.writeError(w, r, http.StatusNotFound, "Source not found")
+			return
+		}
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to get source")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, s.formatSTACCollection(r.Context(), src, requestOrigin(r, s.settings().trustedProxies)))
+}
+
+// formatSTACCollection describes src as a STAC Collection with links
+// absolute against origin. Its bbox is the union of the layer extents in
+// WGS84, leaving out those that cannot be reprojected, and the whole world
+// when none is left; its temporal interval runs from the creation to the
+// last revision date, open where a date is unknown. A license whose name is
+// no SPDX identifier is "proprietary", with a license link when it has a URL.
+func (s *Server) formatSTACCollection(ctx context.Context, src *domain.Source, origin string) STACCollectionDTO {
+	md := &src.Metadata
+	self := stacCollectionURL(origin, src.ID)
+	c := STACCollectionDTO{
+		Type:        "Collection",
+		STACVersion: stacVersion,
+		ID:          src.ID,
+		Title:       stacTitle(src),
+		Description: md.Description,
+		Keywords:    stacKeywords(src),
+		License:     "proprietary",
+		Links: []STACLinkDTO{
+			{Rel: "self", Href: self, Type: "application/json"},
+			{Rel: "root", Href: origin + "/stac", Type: "application/json"},
+			{Rel: "parent", Href: origin + "/stac", Type: "application/json"},
+			{Rel: "alternate", Href: origin + "/api/v1/sources/" + url.PathEscape(src.ID), Type: "application/json", Title: "Source"},
+		},
+	}
+	if c.Description == "" {
+		c.Description = c.Title
+	}
+	if name := strings.TrimSpace(src.License.Name); spdxID.MatchString(name) {
+		c.License = name
+	}
+	if src.License.URL != "" {
+		c.Links = append(c.Links, STACLinkDTO{Rel: "license", Href: src.License.URL, Title: src.License.String()})
+	}
+	if md.Creator != "" {
+		c.Providers = []STACProviderDTO{{Name: md.Creator, Roles: []string{"producer"}}}
+	}
+	c.Extent.Spatial.BBox = [][]float64{s.stacBBox(ctx, src)}
+	c.Extent.Temporal.Interval = [][]*time.Time{{stacTime(md.CreatedAt), stacTime(md.UpdatedAt)}}
+	return c
+}
+
+// stacBBox returns the union of the layer extents of src in WGS84.
+func (s *Server) stacBBox(ctx context.Context, src *domain.Source) []float64 {
+	union := domain.Extent{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+	for _, l := range src.Layers {
+		if l.Extent == nil {
+			continue
+		}
+		e := *l.Extent
+		if e.SRID == 0 {
+			e.SRID = l.SRID
+		}
+		wgs, ok := s.reprojectBBox(ctx, e, domain.SRIDWGS84)
+		if !ok {
+			continue
+		}
+		union.MinX, union.MaxX = math.Min(union.MinX, wgs.MinX), math.Max(union.MaxX, wgs.MaxX)
+		union.MinY, union.MaxY = math.Min(union.MinY, wgs.MinY), math.Max(union.MaxY, wgs.MaxY)
+	}
+	if !union.IsValid() {
+		return []float64{-180, -90, 180, 90}
+	}
+	return []float64{union.MinX, union.MinY, union.MaxX, union.MaxY}
+}
+
+// stacCollectionURL returns the URL of the collection of source id.
+func stacCollectionURL(origin, id string) string {
+	return origin + "/stac/collections/" + url.PathEscape(id)
+}
+
+// stacTitle returns the title of a source's collection: its metadata title,
+// else its name.
+func stacTitle(src *domain.Source) string {
+	if src.Metadata.Title != "" {
+		return src.Metadata.Title
+	}
+	return src.Name
+}
+
+// stacKeywords returns the keywords of a source followed by its tags, each
+// once.
+func stacKeywords(src *domain.Source) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, k := range append(append([]string(nil), src.Metadata.Keywords...), src.Tags...) {
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// stacTime returns t for a temporal interval, nil (open) when unknown.
+func stacTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
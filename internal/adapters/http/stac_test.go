@@ -0,0 +1,81 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func TestSTACCatalog(t *testing.T) {
+	srv := newGazetteerServer(t, fakeGazetteer{})
+	parcels := domain.Source{
+		ID: "parcels", Name: "parcels.gpkg", Tags: []string{"hamburg", "cadastre"},
+		Metadata: domain.Metadata{
+			Title: "Flurstücke", Creator: "LGV Hamburg", Keywords: []string{"cadastre"},
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		License: domain.License{Name: "CC-BY-4.0", URL: "https://creativecommons.org/licenses/by/4.0/"},
+		Layers: []domain.Layer{
+			{Name: "parcels", SRID: 4326, Extent: &domain.Extent{MinX: 9.7, MinY: 53.4, MaxX: 10.0, MaxY: 53.6}},
+			{Name: "buildings", SRID: 4326, Extent: &domain.Extent{MinX: 9.9, MinY: 53.5, MaxX: 10.3, MaxY: 53.7, SRID: 4326}},
+			{Name: "districts", SRID: 25832, Extent: &domain.Extent{MinX: 500000, MinY: 5900000, MaxX: 600000, MaxY: 6000000}},
+		},
+	}
+	srv.registry = &mockSourceRegistry{packages: []domain.Source{parcels}, getPackage: &parcels}
+
+	rec, body := doGET(t, srv, "/stac")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("catalog status = %d, want 200", rec.Code)
+	}
+	if body["type"] != "Catalog" || body["stac_version"] != stacVersion || body["id"] != "ortus" {
+		t.Errorf("catalog = %v", body)
+	}
+	links, _ := body["links"].([]any)
+	if len(links) != 3 {
+		t.Fatalf("catalog links = %v, want self, root and one child", links)
+	}
+	child := links[2].(map[string]any)
+	if child["rel"] != "child" || child["href"] != "http://example.com/stac/collections/parcels" || child["title"] != "Flurstücke" {
+		t.Errorf("child link = %v", child)
+	}
+
+	rec, body = doGET(t, srv, "/stac/collections/parcels")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("collection status = %d, want 200", rec.Code)
+	}
+	if body["type"] != "Collection" || body["id"] != "parcels" || body["description"] != "Flurstücke" {
+		t.Errorf("collection = %v", body)
+	}
+	if body["license"] != "CC-BY-4.0" {
+		t.Errorf("license = %v, want CC-BY-4.0", body["license"])
+	}
+	if kw, _ := body["keywords"].([]any); len(kw) != 2 || kw[0] != "cadastre" || kw[1] != "hamburg" {
+		t.Errorf("keywords = %v, want [cadastre hamburg]", body["keywords"])
+	}
+	extent := body["extent"].(map[string]any)
+	// Without a transformer the 25832 layer is left out of the bbox.
+	bbox := extent["spatial"].(map[string]any)["bbox"].([]any)[0].([]any)
+	if want := []any{9.7, 53.4, 10.3, 53.7}; len(bbox) != 4 || bbox[0] != want[0] || bbox[1] != want[1] || bbox[2] != want[2] || bbox[3] != want[3] {
+		t.Errorf("bbox = %v, want %v", bbox, want)
+	}
+	interval := extent["temporal"].(map[string]any)["interval"].([]any)[0].([]any)
+	if interval[0] != "2024-01-01T00:00:00Z" || interval[1] != nil {
+		t.Errorf("interval = %v, want [2024-01-01T00:00:00Z <nil>]", interval)
+	}
+
+	// No extent at all: the whole world, and no SPDX name means proprietary.
+	bare := domain.Source{ID: "bare", Name: "bare.gpkg", License: domain.License{Name: "Nutzung nur intern"}}
+	srv.registry = &mockSourceRegistry{getPackage: &bare}
+	_, body = doGET(t, srv, "/stac/collections/bare")
+	bbox = body["extent"].(map[string]any)["spatial"].(map[string]any)["bbox"].([]any)[0].([]any)
+	if bbox[0] != float64(-180) || bbox[3] != float64(90) || body["license"] != "proprietary" {
+		t.Errorf("bare collection: bbox = %v, license = %v", bbox, body["license"])
+	}
+
+	srv.registry = &mockSourceRegistry{getErr: domain.ErrSourceNotFound}
+	if rec, _ := doGET(t, srv, "/stac/collections/roads"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown collection: status = %d, want 404", rec.Code)
+	}
+}
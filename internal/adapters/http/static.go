@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// staticHandler serves an operator-provided frontend from dir
+// (server.static_dir) in place of the embedded page. A path that names no
+// file falls back to index.html, so a single-page app can route client-side.
+// Paths under /api/ and /admin/ never fall back — an unknown API route stays
+// a 404 instead of answering with HTML — and neither does a path with a file
+// extension unless the browser navigates to it (Accept: text/html), so a
+// missing asset is a real 404 while a route like /at/52.5/13.4 still works.
+// Dotfiles are never served, and directories without an index.html are not
+// listed.
+func staticHandler(dir string) http.Handler {
+	root := http.Dir(dir)
+	files := http.FileServer(root)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := path.Clean("/" + r.URL.Path)
+		if strings.HasPrefix(p, "/api/") || strings.HasPrefix(p, "/admin/") || hasDotSegment(p) {
+			http.NotFound(w, r)
+			return
+		}
+		if staticFileExists(root, p) {
+			files.ServeHTTP(w, r)
+			return
+		}
+		if path.Ext(p) != "" && !strings.Contains(r.Header.Get("Accept"), "text/html") {
+			http.NotFound(w, r)
+			return
+		}
+		serveStaticIndex(w, r, root)
+	})
+}
+
+// staticFileExists reports whether p is a file, or a directory the file
+// server answers with its index.html.
+func staticFileExists(root http.FileSystem, p string) bool {
+	f, err := root.Open(p)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	if !fi.IsDir() {
+		return true
+	}
+	return staticFileExists(root, path.Join(p, "index.html"))
+}
+
+// serveStaticIndex answers with the root index.html. It is served with
+// ServeContent rather than ServeFile, which would redirect a request path
+// ending in /index.html.
+func serveStaticIndex(w http.ResponseWriter, r *http.Request, root http.FileSystem) {
+	f, err := root.Open("/index.html")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, "index.html", fi.ModTime(), f)
+}
+
+// hasDotSegment reports whether a segment of p starts with a dot (.git,
+// .env, ...).
+func hasDotSegment(p string) bool {
+	for _, seg := range strings.Split(p, "/") {
+		if strings.HasPrefix(seg, ".") {
+			return true
+		}
+	}
+	return false
+}
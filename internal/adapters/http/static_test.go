@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/jobrunner/ortus/internal/application"
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// newStaticServer builds a server whose frontend is served from a temp
+// static_dir holding a small single-page app.
+func newStaticServer(t *testing.T) *Server {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"index.html":          "<!doctype html><title>spa</title>",
+		"assets/app.js":       "console.log('app')",
+		"docs/index.html":     "<!doctype html><title>docs</title>",
+		".env":                "SECRET=1",
+		"empty/.keep":         "",
+		"assets/.hidden/x.js": "x",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	registry := application.NewSourceRegistry([]output.SpatialSource{&mockRepository{}}, &mockStorage{},
+		noop.NewMeterProvider().Meter("test"), output.NoOpTracer{}, logger, "/tmp")
+	_ = registry.LoadAll(context.Background())
+	health := application.NewHealthService(registry, true, output.NoOpTracer{})
+	query := application.NewQueryService(registry, nil, noop.NewMeterProvider().Meter("test"),
+		output.NoOpTracer{}, logger, application.QueryServiceConfig{})
+
+	return NewServer(
+		config.ServerConfig{Host: "localhost", Port: 8080, FrontendEnabled: true, StaticDir: dir},
+		query, registry, health, nil, logger, false, ServerOptions{},
+	)
+}
+
+func TestStaticDir(t *testing.T) {
+	srv := newStaticServer(t)
+	if srv.frontendPages != nil {
+		t.Error("embedded frontend rendered although static_dir replaces it")
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		accept     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "root", path: "/", wantStatus: http.StatusOK, wantBody: "<title>spa</title>"},
+		{name: "asset", path: "/assets/app.js", wantStatus: http.StatusOK, wantBody: "console.log"},
+		{name: "directory index", path: "/docs/", wantStatus: http.StatusOK, wantBody: "<title>docs</title>"},
+		{name: "client route", path: "/map", wantStatus: http.StatusOK, wantBody: "<title>spa</title>"},
+		{name: "client route with dots", path: "/at/52.5/13.4", accept: "text/html,*/*;q=0.8", wantStatus: http.StatusOK, wantBody: "<title>spa</title>"},
+		{name: "directory without index", path: "/empty/", wantStatus: http.StatusOK, wantBody: "<title>spa</title>"},
+		{name: "missing asset", path: "/assets/missing.js", wantStatus: http.StatusNotFound},
+		{name: "dotfile", path: "/.env", wantStatus: http.StatusNotFound},
+		{name: "dot directory", path: "/assets/.hidden/x.js", wantStatus: http.StatusNotFound},
+		{name: "unknown api route", path: "/api/v1/nope", wantStatus: http.StatusNotFound},
+		{name: "api route wins", path: "/api/v1/sources", wantStatus: http.StatusOK, wantBody: `"sources"`},
+		{name: "health wins", path: "/health/live", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+			srv.router.ServeHTTP(rr, req)
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("GET %s = %d, want %d", tt.path, rr.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rr.Body.String(), tt.wantBody) {
+				t.Errorf("GET %s body = %q, want it to contain %q", tt.path, rr.Body.String(), tt.wantBody)
+			}
+			if strings.Contains(rr.Body.String(), "SECRET") {
+				t.Errorf("GET %s leaked a dotfile", tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// queryRateWindow is the number of one-second buckets the query rate is
+// averaged over.
+const queryRateWindow = 60
+
+// queryLatencySamples is the number of most recent query durations the
+// latency percentiles are taken from.
+const queryLatencySamples = 1024
+
+// queryStats keeps the recent rate and latency of the query endpoints for
+// GET /api/v1/stats/summary: request counts in one-second buckets over the
+// last minute, and the durations of the last queryLatencySamples requests.
+// A nil *queryStats records nothing.
+type queryStats struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	// second holds the Unix second a bucket was last written for, so a
+	// bucket left over from an earlier lap is recognized as stale.
+	second [queryRateWindow]int64
+	count  [queryRateWindow]int64
+
+	latency [queryLatencySamples]time.Duration
+	next    int // ring position of the next sample
+	samples int // samples held, up to queryLatencySamples
+}
+
+func newQueryStats() *queryStats {
+	return &queryStats{now: time.Now}
+}
+
+// record counts one query that took d.
+func (q *queryStats) record(d time.Duration) {
+	if q == nil {
+		return
+	}
+	sec := q.now().Unix()
+	slot := int(sec % queryRateWindow)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.second[slot] != sec {
+		q.second[slot] = sec
+		q.count[slot] = 0
+	}
+	q.count[slot]++
+	q.latency[q.next] = d
+	q.next = (q.next + 1) % queryLatencySamples
+	if q.samples < queryLatencySamples {
+		q.samples++
+	}
+}
+
+// rate returns the queries per second over the last minute.
+func (q *queryStats) rate() float64 {
+	now := q.now().Unix()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var total int64
+	for i := range q.second {
+		if now-q.second[i] < queryRateWindow {
+			total += q.count[i]
+		}
+	}
+	return float64(total) / queryRateWindow
+}
+
+// percentiles returns the median and 99th percentile of the recent query
+// durations, and false before the first query.
+func (q *queryStats) percentiles() (p50, p99 time.Duration, ok bool) {
+	q.mu.Lock()
+	durations := append([]time.Duration(nil), q.latency[:q.samples]...)
+	q.mu.Unlock()
+	if len(durations) == 0 {
+		return 0, 0, false
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	at := func(p float64) time.Duration {
+		return durations[int(p*float64(len(durations)-1)+0.5)]
+	}
+	return at(0.50), at(0.99), true
+}
+
+// queryStatsMiddleware records the duration of every request to a query
+// endpoint (/api/v1/query and below).
+func (s *Server) queryStatsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(routePath(r), "/api/v1/query") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		s.queryStats.record(time.Since(start))
+	})
+}
+
+// handleStatsSummary returns a compact snapshot of the instance for status
+// pages that cannot scrape Prometheus: the query rate over the last minute,
+// the median and 99th percentile latency of the recent queries, the source
+// counts, the hit rate of the query-path cache and the last storage sync.
+// Figures that are not known yet, or not wired, are left out.
+func (s *Server) handleStatsSummary(w http.ResponseWriter, r *http.Request) {
+	details := s.health.GetHealthDetails(r.Context())
+	out := StatsSummaryDTO{
+		QPS:           s.queryStats.rate(),
+		SourcesLoaded: details.SourcesLoaded,
+		SourcesReady:  details.SourcesReady,
+	}
+	if p50, p99, ok := s.queryStats.percentiles(); ok {
+		p50ms, p99ms := durationMS(p50), durationMS(p99)
+		out.LatencyP50MS, out.LatencyP99MS = &p50ms, &p99ms
+	}
+	if s.cacheStats != nil {
+		if rate, ok := s.cacheStats.CacheStats().HitRate(); ok {
+			out.CacheHitRate = &rate
+		}
+	}
+	if s.syncStatus != nil {
+		if t := s.syncStatus.LastSync(); !t.IsZero() {
+			t = t.UTC()
+			out.LastSync = &t
+		}
+	}
+	s.writeJSON(w, http.StatusOK, out)
+}
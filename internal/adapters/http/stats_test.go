@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+type fakeCacheStats output.CacheStats
+
+func (f fakeCacheStats) CacheStats() output.CacheStats { return output.CacheStats(f) }
+
+type fakeSyncStatus time.Time
+
+func (f fakeSyncStatus) LastSync() time.Time { return time.Time(f) }
+
+func TestQueryStats(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	q := newQueryStats()
+	q.now = func() time.Time { return now }
+
+	if _, _, ok := q.percentiles(); ok {
+		t.Error("percentiles before the first query: ok = true")
+	}
+	for i := 1; i <= 100; i++ {
+		q.record(time.Duration(i) * time.Millisecond)
+	}
+	now = now.Add(30 * time.Second)
+	for i := 0; i < 20; i++ {
+		q.record(time.Millisecond)
+	}
+	if got := q.rate(); got != 2 {
+		t.Errorf("rate = %v, want 120 queries / 60s = 2", got)
+	}
+	// The first 100 queries leave the window a minute after they were made.
+	now = now.Add(45 * time.Second)
+	if got := q.rate(); got != 20.0/60 {
+		t.Errorf("rate after a minute = %v, want 20/60", got)
+	}
+
+	p50, p99, ok := q.percentiles()
+	if !ok || p50 != 41*time.Millisecond || p99 != 99*time.Millisecond {
+		t.Errorf("percentiles = %v, %v, %v; want 41ms, 99ms, true", p50, p99, ok)
+	}
+}
+
+func TestStatsSummary(t *testing.T) {
+	srv := newGazetteerServer(t, fakeGazetteer{loc: sampleLocality(), fix: sampleFix()})
+
+	rec, body := doGET(t, srv, "/api/v1/stats/summary")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if body["qps"] != float64(0) {
+		t.Errorf("qps = %v, want 0", body["qps"])
+	}
+	for _, key := range []string{"latency_p50_ms", "latency_p99_ms", "cache_hit_rate", "last_sync"} {
+		if _, ok := body[key]; ok {
+			t.Errorf("%s = %v before any query, lookup or sync; want it left out", key, body[key])
+		}
+	}
+
+	// Query requests count, others do not.
+	doGET(t, srv, "/api/v1/query?lon=9.99&lat=53.55")
+	doGET(t, srv, "/api/v1/sources")
+	srv.cacheStats = fakeCacheStats{Hits: 3, Misses: 1}
+	srv.syncStatus = fakeSyncStatus(time.Date(2026, 7, 6, 12, 0, 0, 0, time.UTC))
+
+	_, body = doGET(t, srv, "/api/v1/stats/summary")
+	if body["qps"] != 1.0/60 {
+		t.Errorf("qps = %v, want one query in the last minute", body["qps"])
+	}
+	if _, ok := body["latency_p99_ms"].(float64); !ok {
+		t.Errorf("latency_p99_ms = %v, want a number", body["latency_p99_ms"])
+	}
+	if body["cache_hit_rate"] != 0.75 {
+		t.Errorf("cache_hit_rate = %v, want 0.75", body["cache_hit_rate"])
+	}
+	if body["last_sync"] != "2026-07-06T12:00:00Z" {
+		t.Errorf("last_sync = %v, want 2026-07-06T12:00:00Z", body["last_sync"])
+	}
+}
@@ -1,18 +1,32 @@
 package http
 
 import (
+	"embed"
+	"io/fs"
 	"net/http"
+	"strings"
 )
 
-// swaggerUIHTML is the HTML template for Swagger UI.
-// It loads Swagger UI from unpkg CDN and points to our OpenAPI spec.
+// swaggerUIFiles holds the vendored swagger-ui-dist assets (see
+// scripts/vendor-swagger-ui.sh), their LICENSE and the VERSION file pinning
+// their release. The /docs page loads nothing from elsewhere.
+//
+//go:embed swaggerui
+var swaggerUIFiles embed.FS
+
+// swaggerUIAssetPath is where the embedded assets are served.
+const swaggerUIAssetPath = "/docs/assets/"
+
+// swaggerUIHTML is the HTML template for Swagger UI. {{assets}} is replaced
+// with swaggerUIAssetPath; the page loads this server's /openapi.json and
+// keeps a token entered under "Authorize" across reloads.
 const swaggerUIHTML = `<!DOCTYPE html>
 <html lang="de">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>Ortus API Dokumentation</title>
-    <link rel="stylesheet" type="text/css" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+    <link rel="stylesheet" type="text/css" href="{{assets}}/swagger-ui.css">
     <style>
         html { box-sizing: border-box; overflow-y: scroll; }
         *, *:before, *:after { box-sizing: inherit; }
@@ -24,8 +38,8 @@ const swaggerUIHTML = `<!DOCTYPE html>
 </head>
 <body>
     <div id="swagger-ui"></div>
-    <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" charset="UTF-8"></script>
-    <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-standalone-preset.js" charset="UTF-8"></script>
+    <script src="{{assets}}/swagger-ui-bundle.js" charset="UTF-8"></script>
+    <script src="{{assets}}/swagger-ui-standalone-preset.js" charset="UTF-8"></script>
     <script>
         window.onload = function() {
             const ui = SwaggerUIBundle({
@@ -46,7 +60,8 @@ const swaggerUIHTML = `<!DOCTYPE html>
                 filter: true,
                 showExtensions: true,
                 showCommonExtensions: true,
-                tryItOutEnabled: true
+                tryItOutEnabled: true,
+                persistAuthorization: true
             });
             window.ui = ui;
         };
@@ -54,8 +69,26 @@ const swaggerUIHTML = `<!DOCTYPE html>
 </body>
 </html>`
 
+// swaggerUIPage is swaggerUIHTML with the asset path filled in.
+var swaggerUIPage = []byte(strings.ReplaceAll(swaggerUIHTML, "{{assets}}", strings.TrimSuffix(swaggerUIAssetPath, "/")))
+
+// swaggerUIAssetHandler serves the embedded assets under swaggerUIAssetPath.
+// They change only with the binary, so clients may cache them for a day.
+func swaggerUIAssetHandler() http.Handler {
+	assets, _ := fs.Sub(swaggerUIFiles, "swaggerui")
+	files := http.StripPrefix(swaggerUIAssetPath, http.FileServer(http.FS(assets)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r) // no directory listing
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		files.ServeHTTP(w, r)
+	})
+}
+
 // handleSwaggerUI serves the Swagger UI HTML page.
 func (s *Server) handleSwaggerUI(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	_, _ = w.Write([]byte(swaggerUIHTML))
+	_, _ = w.Write(swaggerUIPage)
 }
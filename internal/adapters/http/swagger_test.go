@@ -0,0 +1,115 @@
+package http
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSwaggerUI(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK || !strings.Contains(body, `src="/docs/assets/swagger-ui-bundle.js"`) ||
+		!strings.Contains(body, "persistAuthorization: true") || strings.Contains(body, "{{assets}}") {
+		t.Errorf("status = %d, page = %s", rr.Code, body)
+	}
+	if strings.Contains(body, "https://") {
+		t.Error("page loads assets from outside this server")
+	}
+
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs/assets/VERSION", nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Cache-Control") == "" {
+		t.Errorf("asset: status = %d, headers = %v", rr.Code, rr.Header())
+	}
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs/assets/", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("asset directory: status = %d, want 404", rr.Code)
+	}
+}
+
+// TestSwaggerUIAssetsEmbedded fails a build whose Swagger UI assets were not
+// vendored: run make swagger-ui and commit the result.
+func TestSwaggerUIAssetsEmbedded(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	for _, name := range []string{"swagger-ui.css", "swagger-ui-bundle.js", "swagger-ui-standalone-preset.js", "LICENSE"} {
+		if info, err := fs.Stat(swaggerUIFiles, "swaggerui/"+name); err != nil || info.Size() == 0 {
+			t.Errorf("embedded %s: %v; run make swagger-ui", name, err)
+			continue
+		}
+		rr := httptest.NewRecorder()
+		srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/docs/assets/"+name, nil))
+		if rr.Code != http.StatusOK || rr.Body.Len() == 0 {
+			t.Errorf("GET /docs/assets/%s: status = %d, %d bytes", name, rr.Code, rr.Body.Len())
+		}
+	}
+}
+
+func TestOpenAPIDocumentReflectsServer(t *testing.T) {
+	served := func(srv *Server) map[string]interface{} {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+		req.Host = "ortus.example:8080"
+		rr := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rr, req)
+		var doc map[string]interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("decoding /openapi.json: %v", err)
+		}
+		return doc
+	}
+
+	doc := served(newTestServer(nil, nil, nil))
+	servers, _ := doc["servers"].([]interface{})
+	first, _ := servers[0].(map[string]interface{})
+	if first["url"] != "http://ortus.example:8080/api/v1" {
+		t.Errorf("servers = %v", doc["servers"])
+	}
+	paths, _ := doc["paths"].(map[string]interface{})
+	for _, p := range []string{"/query", "/health"} {
+		if _, ok := paths[p]; !ok {
+			t.Errorf("registered path %s missing", p)
+		}
+	}
+	for _, p := range []string{"/gazetteer", "/sources/{sourceId}/tables", "/admin/maintenance"} {
+		if _, ok := paths[p]; ok {
+			t.Errorf("path %s documented although this server has no such route", p)
+		}
+	}
+
+	paths, _ = served(newGazetteerServer(t, fakeGazetteer{}))["paths"].(map[string]interface{})
+	if _, ok := paths["/gazetteer"]; !ok {
+		t.Error("/gazetteer missing on a server with the gazetteer wired")
+	}
+}
+
+func TestRequestOrigin(t *testing.T) {
+	trusted, _ := parseCIDRs([]string{"10.0.0.0/8"})
+	tests := []struct {
+		name   string
+		remote string
+		want   string
+	}{
+		{"trusted proxy", "10.0.0.2:4000", "https://maps.example"},
+		{"untrusted peer", "1.2.3.4:4000", "http://internal:8080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+			r.Host = "internal:8080"
+			r.RemoteAddr = tt.remote
+			r.Header.Set("X-Forwarded-Proto", "https")
+			r.Header.Set("X-Forwarded-Host", "maps.example, lb.internal")
+			if got := requestOrigin(r, trusted); got != tt.want {
+				t.Errorf("requestOrigin() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
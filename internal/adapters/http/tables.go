@@ -0,0 +1,118 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// Page size bounds for GET /sources/{sourceId}/tables/{table}.
+const (
+	defaultTableLimit = 100
+	maxTableLimit     = 1000
+)
+
+// handleListTables lists the attribute (non-spatial) tables of a source.
+func (s *Server) handleListTables(w http.ResponseWriter, r *http.Request) {
+	sourceID := mux.Vars(r)["sourceId"]
+
+	tables, err := s.tables.Tables(r.Context(), sourceID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSourceNotFound) {
+			s.writeError(w, r, http.StatusNotFound, "Source not found")
+			return
+		}
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to list tables")
+		return
+	}
+
+	out := make([]map[string]interface{}, len(tables))
+	for i, t := range tables {
+		out[i] = map[string]interface{}{
+			"name":        t.Name,
+			"description": t.Description,
+			"columns":     t.Columns,
+			"row_count":   t.RowCount,
+		}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"source_id": sourceID,
+		"tables":    out,
+		"count":     len(out),
+	})
+}
+
+// handleReadTable returns a page of rows from an attribute table. limit and
+// offset page through it; every other query parameter filters on equality
+// with the column of that name.
+func (s *Server) handleReadTable(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sourceID, table := vars["sourceId"], vars["table"]
+
+	q, err := parseTableQuery(r.URL.Query())
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rows, err := s.tables.ReadTable(r.Context(), sourceID, table, q)
+	switch {
+	case err == nil:
+	case errors.Is(err, domain.ErrSourceNotFound):
+		s.writeError(w, r, http.StatusNotFound, "Source not found")
+		return
+	case errors.Is(err, domain.ErrTableNotFound):
+		s.writeError(w, r, http.StatusNotFound, "Table not found")
+		return
+	case errors.Is(err, domain.ErrInvalidInput):
+		s.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	default:
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to read table")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"source_id": sourceID,
+		"table":     table,
+		"columns":   rows.Columns,
+		"rows":      rows.Rows,
+		"count":     len(rows.Rows),
+		"total":     rows.Total,
+		"limit":     q.Limit,
+		"offset":    q.Offset,
+	})
+}
+
+// parseTableQuery reads limit, offset and the column filters.
+func parseTableQuery(values url.Values) (domain.TableQuery, error) {
+	q := domain.TableQuery{Limit: defaultTableLimit, Filters: map[string]string{}}
+	for name, vs := range values {
+		switch name {
+		case "limit":
+			n, err := strconv.Atoi(vs[0])
+			if err != nil || n < 1 || n > maxTableLimit {
+				return q, fmt.Errorf("limit must be an integer between 1 and %d", maxTableLimit)
+			}
+			q.Limit = n
+		case "offset":
+			n, err := strconv.Atoi(vs[0])
+			if err != nil || n < 0 {
+				return q, fmt.Errorf("offset must be a non-negative integer")
+			}
+			q.Offset = n
+		default:
+			if len(vs) > 1 {
+				return q, fmt.Errorf("filter %q given more than once", name)
+			}
+			q.Filters[name] = vs[0]
+		}
+	}
+	return q, nil
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+type fakeTables struct {
+	last domain.TableQuery
+}
+
+func (f *fakeTables) Tables(_ context.Context, id string) ([]domain.AttributeTable, error) {
+	if id != "districts" {
+		return nil, domain.ErrSourceNotFound
+	}
+	return []domain.AttributeTable{{Name: "codes", Columns: []string{"fid", "code"}, RowCount: 3}}, nil
+}
+
+func (f *fakeTables) ReadTable(_ context.Context, id, table string, q domain.TableQuery) (domain.TableRows, error) {
+	f.last = q
+	switch {
+	case id != "districts":
+		return domain.TableRows{}, domain.ErrSourceNotFound
+	case table != "codes":
+		return domain.TableRows{}, domain.ErrTableNotFound
+	case q.Filters["nope"] != "":
+		return domain.TableRows{}, fmt.Errorf("%w: unknown column %q", domain.ErrInvalidInput, "nope")
+	}
+	return domain.TableRows{
+		Columns: []string{"fid", "code"},
+		Rows:    []map[string]interface{}{{"fid": int64(2), "code": "OT"}},
+		Total:   3,
+	}, nil
+}
+
+func TestTableEndpoints(t *testing.T) {
+	srv := newGazetteerServer(t, fakeGazetteer{})
+	tables := &fakeTables{}
+	srv.tables = tables
+
+	rec, body := doGET(t, srv, "/api/v1/sources/districts/tables")
+	if rec.Code != http.StatusOK || body["count"] != float64(1) {
+		t.Fatalf("list: status = %d, body = %v", rec.Code, body)
+	}
+
+	rec, body = doGET(t, srv, "/api/v1/sources/districts/tables/codes?code=OT&limit=1&offset=1")
+	if rec.Code != http.StatusOK || body["total"] != float64(3) || body["count"] != float64(1) || body["limit"] != float64(1) {
+		t.Fatalf("read: status = %d, body = %v", rec.Code, body)
+	}
+	if tables.last.Filters["code"] != "OT" || len(tables.last.Filters) != 1 || tables.last.Offset != 1 {
+		t.Errorf("query = %+v", tables.last)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/sources/districts/tables/codes":          http.StatusOK,
+		"/api/v1/sources/districts/tables/codes?limit=0":  http.StatusBadRequest,
+		"/api/v1/sources/districts/tables/codes?offset=x": http.StatusBadRequest,
+		"/api/v1/sources/districts/tables/codes?nope=1":   http.StatusBadRequest,
+		"/api/v1/sources/districts/tables/missing":        http.StatusNotFound,
+		"/api/v1/sources/unknown/tables/codes":            http.StatusNotFound,
+		"/api/v1/sources/unknown/tables":                  http.StatusNotFound,
+	} {
+		if rec, _ := doGET(t, srv, path); rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	doGET(t, srv, "/api/v1/sources/districts/tables/codes")
+	if tables.last.Limit != defaultTableLimit || tables.last.Offset != 0 {
+		t.Errorf("default page = %+v", tables.last)
+	}
+}
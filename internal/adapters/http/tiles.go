@@ -0,0 +1,75 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// tileCacheControl lets browsers and proxies keep tiles for a while: a tile
+// changes only when its package is replaced.
+const tileCacheControl = "public, max-age=3600"
+
+// handleTile serves one tile of a source's tile pyramid. The content type is
+// sniffed from the tile itself, since GeoPackages may mix PNG and JPEG (and
+// WebP) tiles in one pyramid.
+func (s *Server) handleTile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var zxy [3]int
+	for i, name := range []string{"z", "x", "y"} {
+		n, err := strconv.Atoi(vars[name])
+		if err != nil || n < 0 {
+			s.writeError(w, r, http.StatusBadRequest, name+" must be a non-negative integer")
+			return
+		}
+		zxy[i] = n
+	}
+
+	data, err := s.tiles.Tile(r.Context(), vars["sourceId"], vars["table"], zxy[0], zxy[1], zxy[2])
+	switch {
+	case err == nil:
+	case errors.Is(err, domain.ErrSourceNotFound):
+		s.writeError(w, r, http.StatusNotFound, "Source not found")
+		return
+	case errors.Is(err, domain.ErrTableNotFound):
+		s.writeError(w, r, http.StatusNotFound, "Tile set not found")
+		return
+	case errors.Is(err, domain.ErrTileNotFound):
+		s.writeError(w, r, http.StatusNotFound, "Tile not found")
+		return
+	default:
+		s.writeError(w, r, http.StatusInternalServerError, "Failed to read tile")
+		return
+	}
+
+	w.Header().Set("Content-Type", http.DetectContentType(data))
+	w.Header().Set("Cache-Control", tileCacheControl)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// formatTileSets lists a source's tile pyramids with the URL template of
+// their tiles.
+func formatTileSets(sourceID string, sets []domain.TileSet) []TileSetDTO {
+	if len(sets) == 0 {
+		return nil
+	}
+	out := make([]TileSetDTO, len(sets))
+	for i, ts := range sets {
+		out[i] = TileSetDTO{
+			Name:        ts.Name,
+			Description: ts.Description,
+			SRID:        ts.SRID,
+			MinZoom:     ts.MinZoom,
+			MaxZoom:     ts.MaxZoom,
+			Tiles:       "/api/v1/tiles/" + url.PathEscape(sourceID) + "/" + url.PathEscape(ts.Name) + "/{z}/{x}/{y}",
+			Extent:      formatExtent(ts.Extent),
+		}
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+type fakeTiles map[string][]byte
+
+func (f fakeTiles) Tile(_ context.Context, id, tileSet string, z, x, y int) ([]byte, error) {
+	if id != "basemap" {
+		return nil, domain.ErrSourceNotFound
+	}
+	if tileSet != "ortho" {
+		return nil, domain.ErrTableNotFound
+	}
+	data, ok := f[fmt.Sprintf("%d/%d/%d", z, x, y)]
+	if !ok {
+		return nil, domain.ErrTileNotFound
+	}
+	return data, nil
+}
+
+func TestTileEndpoint(t *testing.T) {
+	srv := newGazetteerServer(t, fakeGazetteer{})
+	srv.tiles = fakeTiles{
+		"1/0/1": []byte("\x89PNG\r\n\x1a\n png"),
+		"1/1/1": []byte("\xff\xd8\xff\xe0 jpeg"),
+	}
+
+	for path, want := range map[string]string{
+		"/api/v1/tiles/basemap/ortho/1/0/1": "image/png",
+		"/api/v1/tiles/basemap/ortho/1/1/1": "image/jpeg",
+	} {
+		rec := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != want || rec.Header().Get("Cache-Control") == "" {
+			t.Errorf("%s: status = %d, headers = %v", path, rec.Code, rec.Header())
+		}
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/tiles/basemap/ortho/1/0/-1":  http.StatusBadRequest,
+		"/api/v1/tiles/basemap/ortho/z/0/1":   http.StatusBadRequest,
+		"/api/v1/tiles/basemap/ortho/2/0/0":   http.StatusNotFound,
+		"/api/v1/tiles/basemap/streets/1/0/1": http.StatusNotFound,
+		"/api/v1/tiles/unknown/ortho/1/0/1":   http.StatusNotFound,
+	} {
+		if rec, _ := doGET(t, srv, path); rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestFormatTileSets(t *testing.T) {
+	sets := formatTileSets("base map", []domain.TileSet{{Name: "ortho", SRID: 3857, MinZoom: 10, MaxZoom: 14}})
+	if len(sets) != 1 || sets[0].Tiles != "/api/v1/tiles/base%20map/ortho/{z}/{x}/{y}" || sets[0].Extent != nil {
+		t.Errorf("formatTileSets = %v", sets)
+	}
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	_ "embed"
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// xmlNamespace is the target namespace of query-response.xsd. The GML
+// namespace is declared on the root as well: SpatiaLite's AsGML fragments use
+// the gml: prefix without declaring it.
+const (
+	xmlNamespace = "https://ortus.dev/schema/query-response/1"
+	gmlNamespace = "http://www.opengis.net/gml"
+)
+
+// queryResponseXSD is the schema of ?format=xml, served at
+// /schemas/query-response.xsd. api/xsd/query-response.xsd is the published
+// copy and must stay identical.
+//
+//go:embed query-response.xsd
+var queryResponseXSD []byte
+
+// The xml* types mirror query-response.xsd. Unlike the flat csv/ndjson
+// exports, the XML keeps the per-source grouping, license and notes of the
+// JSON response: the consumers are systems that cannot read JSON, not
+// spreadsheets.
+type xmlQueryResponse struct {
+	XMLName       xml.Name      `xml:"QueryResponse"`
+	Namespace     string        `xml:"xmlns,attr"`
+	GMLNamespace  string        `xml:"xmlns:gml,attr"`
+	TotalFeatures int           `xml:"totalFeatures,attr"`
+	Incomplete    bool          `xml:"incomplete,attr"`
+	Coordinate    xmlCoordinate `xml:"Coordinate"`
+	Results       []xmlResult   `xml:"Result"`
+}
+
+type xmlCoordinate struct {
+	X    float64 `xml:"x,attr"`
+	Y    float64 `xml:"y,attr"`
+	SRID int     `xml:"srid,attr"`
+}
+
+type xmlResult struct {
+	SourceID   string       `xml:"sourceId,attr"`
+	SourceName string       `xml:"sourceName,attr"`
+	Peer       string       `xml:"peer,attr,omitempty"`
+	License    *xmlLicense  `xml:"License,omitempty"`
+	Notes      []xmlNote    `xml:"Note"`
+	Features   []xmlFeature `xml:"Feature"`
+}
+
+type xmlLicense struct {
+	Name        string `xml:"name,attr,omitempty"`
+	URL         string `xml:"url,attr,omitempty"`
+	Attribution string `xml:"attribution,attr,omitempty"`
+}
+
+type xmlNote struct {
+	Layer string `xml:"layer,attr"`
+	Text  string `xml:",chardata"`
+}
+
+type xmlFeature struct {
+	ID         int64         `xml:"id,attr"`
+	Layer      string        `xml:"layer,attr"`
+	Properties []xmlProperty `xml:"Property"`
+	Geometry   *xmlGeometry  `xml:"Geometry,omitempty"`
+}
+
+type xmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Null  bool   `xml:"null,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type xmlGeometry struct {
+	SRID int    `xml:"srid,attr,omitempty"`
+	WKT  string `xml:"WKT"`
+	// GML is the adapter's GML 3 fragment, embedded as is when the query
+	// asked for geometry_format=gml.
+	GML *xmlInner `xml:"GML,omitempty"`
+}
+
+type xmlInner struct {
+	Inner string `xml:",innerxml"`
+}
+
+// writeQueryXML writes the response as an XML document valid against
+// query-response.xsd. Properties are sorted by name so the output is stable.
+func (s *Server) writeQueryXML(w http.ResponseWriter, resp *domain.QueryResponse) {
+	doc := xmlQueryResponse{
+		Namespace:     xmlNamespace,
+		GMLNamespace:  gmlNamespace,
+		TotalFeatures: resp.TotalFeatures,
+		Incomplete:    resp.Incomplete,
+		Coordinate: xmlCoordinate{
+			X: resp.Coordinate.X, Y: resp.Coordinate.Y, SRID: resp.Coordinate.SRID,
+		},
+		Results: make([]xmlResult, 0, len(resp.Results)),
+	}
+	for i := range resp.Results {
+		doc.Results = append(doc.Results, s.xmlResult(&resp.Results[i]))
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		s.logger.Debug("xml export write failed", "error", err)
+		return
+	}
+	_, _ = w.Write([]byte("\n"))
+}
+
+func (s *Server) xmlResult(res *domain.QueryResult) xmlResult {
+	out := xmlResult{SourceID: res.SourceID, SourceName: res.SourceName, Peer: res.Peer}
+	if !res.License.IsEmpty() {
+		out.License = &xmlLicense{
+			Name:        res.License.Name,
+			URL:         res.License.URL,
+			Attribution: res.License.Attribution,
+		}
+	}
+	for _, n := range res.Notes {
+		out.Notes = append(out.Notes, xmlNote{Layer: n.Layer, Text: n.Text})
+	}
+	for j := range res.Features {
+		f := &res.Features[j]
+		xf := xmlFeature{ID: f.ID, Layer: f.LayerName}
+		keys := make([]string, 0, len(f.Properties))
+		for k := range f.Properties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := f.Properties[k]
+			xf.Properties = append(xf.Properties, xmlProperty{Name: k, Null: v == nil, Value: csvValue(v)})
+		}
+		if s.withGeometry && f.Geometry.WKT != "" {
+			g := &xmlGeometry{SRID: f.Geometry.SRID, WKT: f.Geometry.WKT}
+			if gml := strings.TrimSpace(f.Geometry.GML); gml != "" {
+				g.GML = &xmlInner{Inner: gml}
+			}
+			xf.Geometry = g
+		}
+		out.Features = append(out.Features, xf)
+	}
+	return out
+}
+
+// handleQueryResponseXSD serves the schema of ?format=xml.
+func (s *Server) handleQueryResponseXSD(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(queryResponseXSD)))
+	_, _ = w.Write(queryResponseXSD)
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func TestWriteQueryXML(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	srv.withGeometry = true
+	resp := &domain.QueryResponse{
+		Coordinate:    domain.NewWGS84Coordinate(13.4, 52.5),
+		TotalFeatures: 1,
+		Results: []domain.QueryResult{{
+			SourceID:   "admin",
+			SourceName: "Districts",
+			License:    domain.License{Name: "CC-BY-4.0", Attribution: "© Example"},
+			Notes:      []domain.LayerNote{{Layer: "districts", Text: "boundaries as of 2024"}},
+			Features: []domain.Feature{{
+				ID: 1, LayerName: "districts",
+				Properties: map[string]interface{}{"name": "Mitte & Tiergarten", "pop": int64(384172), "code": nil},
+				Geometry: domain.Geometry{
+					WKT:  "POINT(13.4 52.5)",
+					GML:  `<gml:Point srsName="EPSG:4326"><gml:pos>13.4 52.5</gml:pos></gml:Point>`,
+					SRID: 4326,
+				},
+			}},
+		}},
+	}
+
+	rec := httptest.NewRecorder()
+	srv.writeQueryExport(rec, httptest.NewRequest(http.MethodGet, "/api/v1/query?format=xml", nil), formatXML, resp)
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Errorf("Content-Type = %q, want application/xml", ct)
+	}
+	body := rec.Body.Bytes()
+	if !bytes.HasPrefix(body, []byte(xml.Header)) {
+		t.Errorf("missing XML declaration: %.60s", body)
+	}
+
+	var doc struct {
+		XMLName xml.Name
+		Total   int `xml:"totalFeatures,attr"`
+		Results []struct {
+			SourceID string `xml:"sourceId,attr"`
+			License  struct {
+				Name string `xml:"name,attr"`
+			} `xml:"License"`
+			Notes    []string `xml:"Note"`
+			Features []struct {
+				Properties []struct {
+					Name  string `xml:"name,attr"`
+					Null  bool   `xml:"null,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"Property"`
+				Geometry struct {
+					WKT string `xml:"WKT"`
+					GML struct {
+						Point struct {
+							XMLName xml.Name
+							Pos     string `xml:"pos"`
+						} `xml:"Point"`
+					} `xml:"GML"`
+				} `xml:"Geometry"`
+			} `xml:"Feature"`
+		} `xml:"Result"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("response is not well-formed XML: %v\n%s", err, body)
+	}
+	if doc.XMLName.Space != xmlNamespace || doc.XMLName.Local != "QueryResponse" || doc.Total != 1 {
+		t.Errorf("root = %v total=%d", doc.XMLName, doc.Total)
+	}
+	if len(doc.Results) != 1 || doc.Results[0].License.Name != "CC-BY-4.0" || len(doc.Results[0].Notes) != 1 {
+		t.Fatalf("results = %+v", doc.Results)
+	}
+	f := doc.Results[0].Features[0]
+	// Sorted by name; NULL is flagged, not rendered as text.
+	if len(f.Properties) != 3 || f.Properties[0].Name != "code" || !f.Properties[0].Null ||
+		f.Properties[1].Value != "Mitte & Tiergarten" || f.Properties[2].Value != "384172" {
+		t.Errorf("properties = %+v", f.Properties)
+	}
+	if f.Geometry.WKT != "POINT(13.4 52.5)" || f.Geometry.GML.Point.Pos != "13.4 52.5" ||
+		f.Geometry.GML.Point.XMLName.Space != gmlNamespace {
+		t.Errorf("geometry = %+v", f.Geometry)
+	}
+}
+
+func TestQueryResponseXSDServed(t *testing.T) {
+	srv := newTestServer(nil, nil, nil)
+	rec := httptest.NewRecorder()
+	srv.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/schemas/query-response.xsd", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if !bytes.Contains(rec.Body.Bytes(), []byte(`targetNamespace="`+xmlNamespace+`"`)) {
+		t.Error("served XSD does not declare the response namespace")
+	}
+}
+
+// TestQueryResponseXSDPublishedCopy keeps api/xsd/query-response.xsd, the copy
+// consumers download from the repository, identical to the served schema.
+func TestQueryResponseXSDPublishedCopy(t *testing.T) {
+	published, err := os.ReadFile("../../../api/xsd/query-response.xsd")
+	if err != nil {
+		t.Fatalf("reading published XSD: %v", err)
+	}
+	if !bytes.Equal(published, queryResponseXSD) {
+		t.Error("api/xsd/query-response.xsd differs from internal/adapters/http/query-response.xsd")
+	}
+}
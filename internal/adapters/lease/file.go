@@ -0,0 +1,134 @@
+// Package lease provides a leader lease kept in a file on a volume the
+// replicas share, for sync.leader_election without a coordination service.
+package lease
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// record is the lease file's content.
+type record struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FileLease keeps a leader lease in a JSON file (holder and expiry). Every
+// read-modify-write of the file runs under a lock file created exclusively
+// next to it, which works on local disks and NFS alike; the lease itself is
+// replaced by an atomic rename, so a reader never sees half a record. A lock
+// file older than the lease's ttl was left by a replica that died mid-update
+// and is broken.
+type FileLease struct {
+	path string
+	now  func() time.Time // replaced in tests
+}
+
+// NewFileLease returns the lease kept in the file at path. Its directory
+// must exist.
+func NewFileLease(path string) *FileLease {
+	return &FileLease{path: filepath.Clean(path), now: time.Now}
+}
+
+// TryAcquire implements output.LeaderLease. A lock held by another replica
+// mid-update reports false; the caller tries again on its next round.
+func (l *FileLease) TryAcquire(_ context.Context, holder string, ttl time.Duration) (bool, error) {
+	unlock, ok, err := l.lock(ttl)
+	if err != nil || !ok {
+		return false, err
+	}
+	defer unlock()
+
+	now := l.now()
+	cur, err := l.read()
+	if err != nil {
+		return false, err
+	}
+	if cur.Holder != "" && cur.Holder != holder && now.Before(cur.ExpiresAt) {
+		return false, nil
+	}
+	return true, l.write(record{Holder: holder, ExpiresAt: now.Add(ttl)})
+}
+
+// Release implements output.LeaderLease.
+func (l *FileLease) Release(_ context.Context, holder string) error {
+	unlock, ok, err := l.lock(0)
+	if err != nil || !ok {
+		return err
+	}
+	defer unlock()
+
+	cur, err := l.read()
+	if err != nil || cur.Holder != holder {
+		return err
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("releasing lease: %w", err)
+	}
+	return nil
+}
+
+// lock creates the lock file exclusively. ok is false while another replica
+// holds it; one older than staleAfter (> 0) is broken and taken over.
+func (l *FileLease) lock(staleAfter time.Duration) (unlock func(), ok bool, err error) {
+	name := l.path + ".lock"
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(name) }, true, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, false, fmt.Errorf("locking lease: %w", err)
+		}
+		info, err := os.Stat(name)
+		if err != nil || staleAfter <= 0 || l.now().Sub(info.ModTime()) < staleAfter {
+			return nil, false, nil
+		}
+		_ = os.Remove(name)
+	}
+	return nil, false, nil
+}
+
+// read returns the current lease; the zero record when there is none.
+func (l *FileLease) read() (record, error) {
+	var r record
+	data, err := os.ReadFile(l.path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return r, fmt.Errorf("reading lease: %w", err)
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		// A corrupt lease guards nothing; the next write replaces it.
+		return record{}, nil
+	}
+	return r, nil
+}
+
+// write replaces the lease file atomically.
+func (l *FileLease) write(r record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing lease: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("writing lease: %w", err)
+	}
+	return nil
+}
+
+var _ output.LeaderLease = (*FileLease)(nil)
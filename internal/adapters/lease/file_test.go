@@ -0,0 +1,87 @@
+package lease
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestLease returns a lease in a temp dir with a clock the test moves.
+func newTestLease(t *testing.T) (*FileLease, *time.Time) {
+	t.Helper()
+	l := NewFileLease(filepath.Join(t.TempDir(), ".ortus-leader"))
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestFileLease_ExclusiveUntilExpiry(t *testing.T) {
+	l, now := newTestLease(t)
+	ctx := context.Background()
+
+	if ok, err := l.TryAcquire(ctx, "a", time.Minute); err != nil || !ok {
+		t.Fatalf("a acquires a free lease = %v, %v; want true", ok, err)
+	}
+	if ok, err := l.TryAcquire(ctx, "b", time.Minute); err != nil || ok {
+		t.Fatalf("b acquires a's lease = %v, %v; want false", ok, err)
+	}
+	*now = now.Add(30 * time.Second)
+	if ok, _ := l.TryAcquire(ctx, "a", time.Minute); !ok {
+		t.Fatal("a renews its lease = false, want true")
+	}
+	*now = now.Add(59 * time.Second)
+	if ok, _ := l.TryAcquire(ctx, "b", time.Minute); ok {
+		t.Fatal("b acquires before the renewed lease expired = true, want false")
+	}
+	*now = now.Add(time.Second)
+	if ok, _ := l.TryAcquire(ctx, "b", time.Minute); !ok {
+		t.Fatal("b acquires the expired lease = false, want true")
+	}
+}
+
+func TestFileLease_Release(t *testing.T) {
+	l, _ := newTestLease(t)
+	ctx := context.Background()
+	if ok, _ := l.TryAcquire(ctx, "a", time.Minute); !ok {
+		t.Fatal("a acquires a free lease = false")
+	}
+
+	if err := l.Release(ctx, "b"); err != nil {
+		t.Fatalf("b releases a's lease: %v", err)
+	}
+	if ok, _ := l.TryAcquire(ctx, "b", time.Minute); ok {
+		t.Fatal("b acquires after releasing a lease it never held = true, want false")
+	}
+	if err := l.Release(ctx, "a"); err != nil {
+		t.Fatalf("a releases: %v", err)
+	}
+	if ok, _ := l.TryAcquire(ctx, "b", time.Minute); !ok {
+		t.Error("b acquires the released lease = false, want true")
+	}
+}
+
+// TestFileLease_Lock: a lock file held mid-update makes others back off; one
+// left behind by a replica that died is broken after the ttl.
+func TestFileLease_Lock(t *testing.T) {
+	l, now := newTestLease(t)
+	ctx := context.Background()
+	if err := os.WriteFile(l.path+".lock", nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(l.path+".lock", *now, *now); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := l.TryAcquire(ctx, "a", time.Minute); err != nil || ok {
+		t.Fatalf("acquire while locked = %v, %v; want false", ok, err)
+	}
+	*now = now.Add(time.Minute)
+	if ok, err := l.TryAcquire(ctx, "a", time.Minute); err != nil || !ok {
+		t.Fatalf("acquire past a stale lock = %v, %v; want true", ok, err)
+	}
+	if _, err := os.Stat(l.path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+// Package logfile provides a size-rotated log file for deployments whose
+// stdout nobody captures.
+package logfile
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat stamps rotated files; it sorts chronologically.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// Writer appends to a log file and rotates it once a write would take it
+// past the size limit: the file is renamed to NAME-TIMESTAMP.EXT (then
+// gzip-compressed to NAME-TIMESTAMP.EXT.gz when enabled) and a fresh one is
+// started. Only the newest backups are kept. Compression and pruning run in
+// the background, so a rotation does not stall logging; Close waits for them.
+type Writer struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	compress   bool
+	now        func() time.Time // injectable for tests
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	post sync.Mutex     // serializes compression and pruning
+	wg   sync.WaitGroup // pending post-rotation work
+}
+
+// New opens path for appending, creating it and its directory as needed.
+// maxSizeMB is the size limit in megabytes; maxBackups the number of rotated
+// files kept, 0 keeping all of them.
+func New(path string, maxSizeMB, maxBackups int, compress bool) (*Writer, error) {
+	w := &Writer{
+		path:       filepath.Clean(path),
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+		compress:   compress,
+		now:        time.Now,
+	}
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o750); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write implements io.Writer. A single write larger than the limit still
+// goes to one file, rotated just before it.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the file once pending compression and pruning are done.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.wg.Wait()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	w.file, w.size = f, fi.Size()
+	return nil
+}
+
+// rotate moves the current file aside and opens a fresh one. The caller
+// holds w.mu.
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	w.file = nil
+	ext := filepath.Ext(w.path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(w.path, ext), w.now().UTC().Format(backupTimeFormat), ext)
+	if err := os.Rename(w.path, backup); err != nil {
+		// Keep logging to the file as it is rather than not at all.
+		return errors.Join(fmt.Errorf("rotating log file: %w", err), w.open())
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.post.Lock()
+		defer w.post.Unlock()
+		if w.compress {
+			_ = compressFile(backup)
+		}
+		_ = w.prune()
+	}()
+	return nil
+}
+
+// prune removes the oldest backups beyond maxBackups.
+func (w *Writer) prune() error {
+	if w.maxBackups == 0 {
+		return nil
+	}
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+	if len(backups) <= w.maxBackups {
+		return nil
+	}
+	// The timestamp makes the names sort oldest first.
+	sort.Strings(backups)
+	var errs []error
+	for _, old := range backups[:len(backups)-w.maxBackups] {
+		errs = append(errs, os.Remove(old))
+	}
+	return errors.Join(errs...)
+}
+
+// backups lists the rotated files of w, compressed or not. Only names that
+// carry a rotation timestamp count, so a neighbour such as ortus-access.log
+// next to ortus.log is never taken for a backup.
+func (w *Writer) backups() ([]string, error) {
+	ext := filepath.Ext(w.path)
+	prefix := strings.TrimSuffix(w.path, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*")
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(m, prefix), ".gz"), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, m)
+		}
+	}
+	return backups, nil
+}
+
+// compressFile gzips path to path.gz and removes path.
+func compressFile(path string) error {
+	src, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	err = errors.Join(err, zw.Close(), dst.Close())
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,131 @@
+package logfile
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestWriter returns a Writer with a 10-byte limit whose clock advances a
+// second per rotation, so backups get distinct names.
+func newTestWriter(t *testing.T, path string, maxBackups int, compress bool) *Writer {
+	t.Helper()
+	w, err := New(path, 1, maxBackups, compress)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	w.maxSize = 10
+	clock := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	w.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	return w
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestWriterRotatesAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "ortus.log")
+	// A neighbour that merely shares the prefix is not a backup.
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "logs", "ortus-access.log"), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w := newTestWriter(t, path, 2, false)
+	for _, line := range []string{"one 12345\n", "two 12345\n", "three 123\n", "four 1234\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"ortus-2026-10-15T12-00-02.000.log",
+		"ortus-2026-10-15T12-00-03.000.log",
+		"ortus-access.log",
+		"ortus.log",
+	}
+	if got := dirNames(t, filepath.Dir(path)); !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "four 1234\n" {
+		t.Errorf("current file = %q, want the last line", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "logs", "ortus-2026-10-15T12-00-03.000.log")); string(b) != "three 123\n" {
+		t.Errorf("newest backup = %q, want the third line", b)
+	}
+}
+
+func TestWriterCompresses(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ortus.log")
+	w := newTestWriter(t, path, 0, true)
+	for _, line := range []string{"one 12345\n", "two 12345\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "ortus-2026-10-15T12-00-01.000.log.gz"))
+	if err != nil {
+		t.Fatalf("compressed backup: %v (files %v)", err, dirNames(t, dir))
+	}
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil || string(b) != "one 12345\n" {
+		t.Errorf("backup content = %q, %v", b, err)
+	}
+	for _, name := range dirNames(t, dir) {
+		if strings.HasSuffix(name, "000.log") {
+			t.Errorf("uncompressed backup %s left behind", name)
+		}
+	}
+}
+
+func TestWriterAppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ortus.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWriter(t, path, 0, false)
+	if _, err := w.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	if b, _ := os.ReadFile(path); string(b) != "old\nnew\n" {
+		t.Errorf("file = %q, want the new line appended", b)
+	}
+	if _, err := w.Write([]byte("late\n")); err == nil {
+		t.Error("Write after Close succeeded")
+	}
+}
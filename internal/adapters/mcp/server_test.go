@@ -24,7 +24,7 @@ import (
 // fakeRepo is a no-DB SpatialSource.
 type fakeRepo struct{}
 
-func (fakeRepo) Open(_ context.Context, path string) (*domain.Source, error) {
+func (fakeRepo) Open(_ context.Context, _, path string) (*domain.Source, error) {
 	return &domain.Source{
 		ID: "fake", Name: "fake.gpkg", Path: path,
 		Layers: []domain.Layer{{Name: "regions", GeometryColumn: "geom", GeometryType: "POLYGON", SRID: 4326, HasIndex: true}},
@@ -1,6 +1,6 @@
 // Package metrics provides the OpenTelemetry meter provider that exports
-// metrics both via Prometheus scrape (/metrics) and, optionally, via OTLP
-// push to an external collector. The provider is constructed once at
+// metrics via Prometheus scrape (/metrics), StatsD or OTLP push, the latter
+// optionally alongside either of the others. The provider is constructed once at
 // startup; services pull the *otel-go* meter from it and define their own
 // instruments — there is no per-instrument port abstraction here. The
 // per-signal config lives in config.MetricsConfig.
@@ -29,9 +29,20 @@ import (
 // prefix knob here, since the OTel Prometheus exporter derives the
 // scrape-format name directly from the instrument name.
 type Options struct {
+	// Exporter selects the primary export: config.MetricsExporterPrometheus
+	// (the default when empty), MetricsExporterStatsD or MetricsExporterOTLP,
+	// which has only the OTLP reader.
+	Exporter string
+
+	// StatsD push configuration, used by MetricsExporterStatsD.
+	StatsDAddress  string
+	StatsDPrefix   string
+	StatsDInterval time.Duration
+	StatsDTags     bool
+
 	// OTLP push configuration. When OTLPEnabled is true, the meter provider
 	// gets a PeriodicReader exporting via the chosen transport in addition
-	// to the Prometheus scrape Reader.
+	// to the reader of Exporter.
 	OTLPEnabled   bool
 	OTLPEndpoint  string
 	OTLPTransport string
@@ -46,9 +57,9 @@ type Collector struct {
 	provider *sdkmetric.MeterProvider
 }
 
-// New constructs the meter provider with a Prometheus reader and optionally
-// an OTLP PeriodicReader. The Prometheus exporter registers with the
-// default prometheus registerer, so promhttp.Handler() picks it up.
+// New constructs the meter provider with the reader of opts.Exporter and
+// optionally an OTLP PeriodicReader. The Prometheus exporter registers with
+// the default prometheus registerer, so promhttp.Handler() picks it up.
 func New(ctx context.Context, opts Options, logger *slog.Logger) (*Collector, error) {
 	if logger == nil {
 		logger = slog.Default()
@@ -56,13 +67,27 @@ func New(ctx context.Context, opts Options, logger *slog.Logger) (*Collector, er
 
 	readers := make([]sdkmetric.Option, 0, 3)
 
-	promExporter, err := otelprom.New()
-	if err != nil {
-		return nil, fmt.Errorf("creating prometheus exporter: %w", err)
+	switch opts.Exporter {
+	case "", config.MetricsExporterPrometheus:
+		promExporter, err := otelprom.New()
+		if err != nil {
+			return nil, fmt.Errorf("creating prometheus exporter: %w", err)
+		}
+		readers = append(readers, sdkmetric.WithReader(promExporter))
+	case config.MetricsExporterStatsD:
+		interval := opts.StatsDInterval
+		if interval <= 0 {
+			interval = 10 * time.Second
+		}
+		exporter := newStatsDExporter(opts.StatsDAddress, opts.StatsDPrefix, opts.StatsDTags)
+		readers = append(readers, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval))))
+		logger.Info("StatsD metric exporter configured",
+			"address", opts.StatsDAddress,
+			"interval", interval,
+		)
 	}
-	readers = append(readers, sdkmetric.WithReader(promExporter))
 
-	if opts.OTLPEnabled {
+	if opts.OTLPEnabled || opts.Exporter == config.MetricsExporterOTLP {
 		otlpReader, err := buildOTLPMetricReader(ctx, opts)
 		if err != nil {
 			return nil, fmt.Errorf("creating OTLP metric exporter: %w", err)
@@ -0,0 +1,219 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// statsdMaxPacket keeps a datagram within a typical Ethernet MTU, so lines
+// are not dropped by fragmentation on the way to the daemon.
+const statsdMaxPacket = 1432
+
+// statsdExporter pushes the collected metrics to a StatsD daemon over UDP in
+// the line protocol ("name:value|type"). Counters and histograms are
+// exported as deltas: a counter becomes a "|c" line of its increase since
+// the last export, a histogram "<name>.count" and "<name>.sum" counters.
+// Up-down counters and gauges are "|g" lines of their current value. With
+// tags, attributes are appended in the DogStatsD form "|#key:value,...",
+// which Datadog, Telegraf and statsd_exporter understand; without, series
+// that differ only by attributes are sent alike.
+type statsdExporter struct {
+	address string
+	prefix  string
+	tags    bool
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+var _ sdkmetric.Exporter = (*statsdExporter)(nil)
+
+func newStatsDExporter(address, prefix string, tags bool) *statsdExporter {
+	return &statsdExporter{address: address, prefix: prefix, tags: tags}
+}
+
+// Temporality implements sdkmetric.Exporter: deltas for the instruments
+// StatsD counts, cumulative values for those it gauges.
+func (e *statsdExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	switch kind {
+	case sdkmetric.InstrumentKindCounter, sdkmetric.InstrumentKindObservableCounter, sdkmetric.InstrumentKindHistogram:
+		return metricdata.DeltaTemporality
+	default:
+		return metricdata.CumulativeTemporality
+	}
+}
+
+// Aggregation implements sdkmetric.Exporter.
+func (e *statsdExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+// Export implements sdkmetric.Exporter. The connection is dialed on first
+// use, so a daemon whose name does not resolve yet at startup is picked up
+// by a later export.
+func (e *statsdExporter) Export(_ context.Context, rm *metricdata.ResourceMetrics) error {
+	var lines []string
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			lines = e.appendLines(lines, m)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		conn, err := net.Dial("udp", e.address)
+		if err != nil {
+			return err
+		}
+		e.conn = conn
+	}
+	for _, packet := range statsdPackets(lines) {
+		if _, err := e.conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForceFlush implements sdkmetric.Exporter; nothing is buffered.
+func (e *statsdExporter) ForceFlush(context.Context) error { return nil }
+
+// Shutdown implements sdkmetric.Exporter.
+func (e *statsdExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// appendLines appends the StatsD lines of m.
+func (e *statsdExporter) appendLines(lines []string, m metricdata.Metrics) []string {
+	switch data := m.Data.(type) {
+	case metricdata.Sum[int64]:
+		return appendSumLines(e, lines, m.Name, data)
+	case metricdata.Sum[float64]:
+		return appendSumLines(e, lines, m.Name, data)
+	case metricdata.Gauge[int64]:
+		return appendGaugeLines(e, lines, m.Name, data)
+	case metricdata.Gauge[float64]:
+		return appendGaugeLines(e, lines, m.Name, data)
+	case metricdata.Histogram[int64]:
+		return appendHistogramLines(e, lines, m.Name, data)
+	case metricdata.Histogram[float64]:
+		return appendHistogramLines(e, lines, m.Name, data)
+	}
+	return lines
+}
+
+func appendSumLines[N int64 | float64](e *statsdExporter, lines []string, name string, s metricdata.Sum[N]) []string {
+	counter := s.IsMonotonic && s.Temporality == metricdata.DeltaTemporality
+	for _, dp := range s.DataPoints {
+		switch {
+		case !counter:
+			lines = append(lines, e.line(name, float64(dp.Value), "g", dp.Attributes))
+		case dp.Value != 0:
+			lines = append(lines, e.line(name, float64(dp.Value), "c", dp.Attributes))
+		}
+	}
+	return lines
+}
+
+func appendGaugeLines[N int64 | float64](e *statsdExporter, lines []string, name string, g metricdata.Gauge[N]) []string {
+	for _, dp := range g.DataPoints {
+		lines = append(lines, e.line(name, float64(dp.Value), "g", dp.Attributes))
+	}
+	return lines
+}
+
+func appendHistogramLines[N int64 | float64](e *statsdExporter, lines []string, name string, h metricdata.Histogram[N]) []string {
+	for _, dp := range h.DataPoints {
+		if dp.Count == 0 {
+			continue
+		}
+		lines = append(lines,
+			e.line(name+".count", float64(dp.Count), "c", dp.Attributes),
+			e.line(name+".sum", float64(dp.Sum), "c", dp.Attributes),
+		)
+	}
+	return lines
+}
+
+// line formats one StatsD line.
+func (e *statsdExporter) line(name string, value float64, typ string, attrs attribute.Set) string {
+	var b strings.Builder
+	b.WriteString(statsdName(e.prefix + name))
+	b.WriteByte(':')
+	b.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	b.WriteByte('|')
+	b.WriteString(typ)
+	if e.tags && attrs.Len() > 0 {
+		b.WriteString("|#")
+		for i, kv := range attrs.ToSlice() {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(statsdName(string(kv.Key)))
+			b.WriteByte(':')
+			b.WriteString(statsdTagValue(kv.Value.Emit()))
+		}
+	}
+	return b.String()
+}
+
+// statsdName replaces the characters the line protocol reserves in names.
+func statsdName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', '#', ',', ' ', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// statsdTagValue replaces the characters that end a DogStatsD tag.
+func statsdTagValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '|', '#', ',', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// statsdPackets joins lines with newlines into datagrams of at most
+// statsdMaxPacket bytes; a longer line goes alone.
+func statsdPackets(lines []string) [][]byte {
+	var packets [][]byte
+	var cur []byte
+	for _, l := range lines {
+		if len(cur) > 0 && len(cur)+1+len(l) > statsdMaxPacket {
+			packets = append(packets, cur)
+			cur = nil
+		}
+		if len(cur) > 0 {
+			cur = append(cur, '\n')
+		}
+		cur = append(cur, l...)
+	}
+	if len(cur) > 0 {
+		packets = append(packets, cur)
+	}
+	return packets
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestStatsDExporter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	exporter := newStatsDExporter(conn.LocalAddr().String(), "app.", true)
+	reader := sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(time.Hour))
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	meter := provider.Meter("test")
+	ctx := context.Background()
+
+	requests, _ := meter.Int64Counter("ortus.http.requests")
+	duration, _ := meter.Float64Histogram("ortus.query.duration")
+	loaded, _ := meter.Int64UpDownCounter("ortus.sources.loaded")
+	requests.Add(ctx, 3, metric.WithAttributes(attribute.String("path", "/api/v1/query"), attribute.String("status", "2xx")))
+	duration.Record(ctx, 0.5)
+	duration.Record(ctx, 1.5)
+	loaded.Add(ctx, 2)
+
+	if err := reader.ForceFlush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	buf := make([]byte, statsdMaxPacket)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	got := strings.Split(string(buf[:n]), "\n")
+	sort.Strings(got)
+	want := []string{
+		"app.ortus.http.requests:3|c|#path:/api/v1/query,status:2xx",
+		"app.ortus.query.duration.count:2|c",
+		"app.ortus.query.duration.sum:2|c",
+		"app.ortus.sources.loaded:2|g",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("lines =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Counters are deltas: nothing new, no counter line.
+	requests.Add(ctx, 0)
+	if err := reader.ForceFlush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	n, _, err = conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if got := string(buf[:n]); got != "app.ortus.sources.loaded:2|g" {
+		t.Errorf("second export = %q, want only the gauge", got)
+	}
+	_ = provider.Shutdown(ctx)
+}
+
+func TestStatsDPackets(t *testing.T) {
+	line := strings.Repeat("x", 600)
+	packets := statsdPackets([]string{line, line, line, strings.Repeat("y", 2000)})
+	if len(packets) != 3 {
+		t.Fatalf("%d packets, want 3", len(packets))
+	}
+	if len(packets[0]) != 2*600+1 || len(packets[1]) != 600 || len(packets[2]) != 2000 {
+		t.Errorf("packet sizes = %d, %d, %d", len(packets[0]), len(packets[1]), len(packets[2]))
+	}
+}
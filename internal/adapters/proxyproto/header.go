@@ -0,0 +1,117 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// v2Signature starts a v2 header; a v1 header starts with "PROXY ".
+var v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// v1MaxLength is the longest v1 header line, CRLF included.
+const v1MaxLength = 107
+
+// errNoHeader is returned for a connection that does not start with a
+// PROXY protocol header.
+var errNoHeader = errors.New("proxy protocol: connection does not start with a PROXY header")
+
+// readHeader consumes the header at the start of r and returns the client
+// address it carries, nil when it carries none (v1 UNKNOWN, v2 LOCAL or an
+// address family other than TCP over IPv4/IPv6).
+func readHeader(r *bufio.Reader) (net.Addr, error) {
+	if prefix, err := r.Peek(len(v2Signature)); err == nil && bytes.Equal(prefix, v2Signature) {
+		return readV2(r)
+	}
+	if prefix, err := r.Peek(6); err == nil && string(prefix) == "PROXY " {
+		return readV1(r)
+	}
+	return nil, errNoHeader
+}
+
+// readV1 parses "PROXY TCP4|TCP6|UNKNOWN src dst srcport dstport\r\n".
+func readV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for len(line) < v1MaxLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("proxy protocol: reading v1 header: %w", err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	text, ok := strings.CutSuffix(string(line), "\r\n")
+	if !ok {
+		return nil, errors.New("proxy protocol: v1 header not terminated by CRLF")
+	}
+
+	fields := strings.Split(text, " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("proxy protocol: malformed v1 header %q", text)
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil || (fields[1] == "TCP4") != (ip.To4() != nil) {
+		return nil, fmt.Errorf("proxy protocol: malformed v1 header %q", text)
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readV2 parses the binary header: the signature, version and command,
+// address family and protocol, the length of the rest, then the addresses
+// and any TLVs, which are skipped.
+func readV2(r *bufio.Reader) (net.Addr, error) {
+	var head [16]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, fmt.Errorf("proxy protocol: reading v2 header: %w", err)
+	}
+	if head[12]>>4 != 2 {
+		return nil, fmt.Errorf("proxy protocol: unsupported version %d", head[12]>>4)
+	}
+	body := make([]byte, binary.BigEndian.Uint16(head[14:16]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("proxy protocol: reading v2 header: %w", err)
+	}
+
+	const (
+		cmdLocal = 0x0
+		cmdProxy = 0x1
+		tcpIPv4  = 0x11
+		tcpIPv6  = 0x21
+	)
+	switch head[12] & 0x0f {
+	case cmdLocal:
+		return nil, nil
+	case cmdProxy:
+	default:
+		return nil, fmt.Errorf("proxy protocol: unsupported v2 command %d", head[12]&0x0f)
+	}
+
+	var ipLen int
+	switch head[13] {
+	case tcpIPv4:
+		ipLen = net.IPv4len
+	case tcpIPv6:
+		ipLen = net.IPv6len
+	default:
+		return nil, nil
+	}
+	// source address, destination address, source port, destination port
+	if len(body) < 2*ipLen+4 {
+		return nil, errors.New("proxy protocol: v2 address block too short")
+	}
+	ip := net.IP(bytes.Clone(body[:ipLen]))
+	port := binary.BigEndian.Uint16(body[2*ipLen:])
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
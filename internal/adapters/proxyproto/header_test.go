@@ -0,0 +1,68 @@
+package proxyproto
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// v2Header builds a v2 header of command cmd and family fam around addrs.
+func v2Header(cmd, fam byte, addrs []byte) string {
+	head := append([]byte{}, v2Signature...)
+	head = append(head, 0x20|cmd, fam, 0, 0)
+	binary.BigEndian.PutUint16(head[14:], uint16(len(addrs)))
+	return string(append(head, addrs...))
+}
+
+func TestReadHeader(t *testing.T) {
+	ipv4 := []byte{203, 0, 113, 7, 10, 0, 0, 1, 0x30, 0x39, 0x01, 0xbb}
+	ipv6 := append(append(net.ParseIP("2001:db8::7").To16(), net.ParseIP("2001:db8::1").To16()...), 0x30, 0x39, 0x01, 0xbb)
+	tlv := append(append([]byte{}, ipv4...), 0x04, 0x00, 0x01, 'x')
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string // client address; "" for none
+		wantErr bool
+	}{
+		{name: "v1 tcp4", input: "PROXY TCP4 203.0.113.7 10.0.0.1 12345 443\r\n", want: "203.0.113.7:12345"},
+		{name: "v1 tcp6", input: "PROXY TCP6 2001:db8::7 2001:db8::1 12345 443\r\n", want: "[2001:db8::7]:12345"},
+		{name: "v1 unknown", input: "PROXY UNKNOWN\r\n"},
+		{name: "v1 family mismatch", input: "PROXY TCP4 2001:db8::7 2001:db8::1 12345 443\r\n", wantErr: true},
+		{name: "v1 bad port", input: "PROXY TCP4 203.0.113.7 10.0.0.1 99999 443\r\n", wantErr: true},
+		{name: "v1 without crlf", input: "PROXY TCP4 203.0.113.7 10.0.0.1 12345 443\n", wantErr: true},
+		{name: "v1 overlong", input: "PROXY " + strings.Repeat("x", 200) + "\r\n", wantErr: true},
+		{name: "v2 ipv4", input: v2Header(1, 0x11, ipv4), want: "203.0.113.7:12345"},
+		{name: "v2 ipv6", input: v2Header(1, 0x21, ipv6), want: "[2001:db8::7]:12345"},
+		{name: "v2 with tlvs", input: v2Header(1, 0x11, tlv), want: "203.0.113.7:12345"},
+		{name: "v2 local", input: v2Header(0, 0x00, nil)},
+		{name: "v2 unix family", input: v2Header(1, 0x31, make([]byte, 216))},
+		{name: "v2 short address block", input: v2Header(1, 0x11, ipv4[:8]), wantErr: true},
+		{name: "no header", input: "GET / HTTP/1.1\r\n\r\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.input + "GET /"))
+			addr, err := readHeader(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readHeader() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := ""
+			if addr != nil {
+				got = addr.String()
+			}
+			if got != tt.want {
+				t.Errorf("readHeader() = %q, want %q", got, tt.want)
+			}
+			if rest, _ := io.ReadAll(r); string(rest) != "GET /" {
+				t.Errorf("data after the header = %q, want %q", rest, "GET /")
+			}
+		})
+	}
+}
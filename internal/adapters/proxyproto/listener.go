@@ -0,0 +1,110 @@
+// Package proxyproto reads the PROXY protocol header (v1 text or v2 binary)
+// that load balancers such as HAProxy, AWS NLB or nginx stream put in front
+// of a forwarded TCP connection, so the server sees the client's address
+// rather than the balancer's.
+package proxyproto
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// headerTimeout bounds reading the header, so a peer that connects and
+// stays silent does not hold a connection open indefinitely.
+var headerTimeout = 10 * time.Second
+
+// Listener reads a PROXY protocol header on the connections it accepts from
+// trusted peers; a connection from any other peer is passed through as is,
+// so its (spoofable) header would be served as a malformed request. With no
+// trusted networks every peer must send the header.
+type Listener struct {
+	net.Listener
+	trusted []*net.IPNet
+}
+
+// NewListener wraps ln. trustedCIDRs are the networks of the load balancers
+// that send the header.
+func NewListener(ln net.Listener, trustedCIDRs []string) (*Listener, error) {
+	l := &Listener{Listener: ln}
+	for _, cidr := range trustedCIDRs {
+		_, n, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q for the PROXY protocol: %w", cidr, err)
+		}
+		l.trusted = append(l.trusted, n)
+	}
+	return l, nil
+}
+
+// Accept returns the next connection. The header is read lazily, on the
+// connection's first Read or RemoteAddr, so a slow peer never blocks Accept.
+func (l *Listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if !l.trusts(c.RemoteAddr()) {
+		return c, nil
+	}
+	return &conn{Conn: c}, nil
+}
+
+// trusts reports whether the header of a peer at addr is honored.
+func (l *Listener) trusts(addr net.Addr) bool {
+	if len(l.trusted) == 0 {
+		return true
+	}
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, n := range l.trusted {
+		if n.Contains(tcp.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// conn is a connection that starts with a PROXY protocol header.
+type conn struct {
+	net.Conn
+	once   sync.Once
+	r      *bufio.Reader
+	source net.Addr // client address from the header; nil keeps the peer's
+	err    error
+}
+
+// init reads the header, once.
+func (c *conn) init() {
+	c.once.Do(func() {
+		_ = c.SetReadDeadline(time.Now().Add(headerTimeout))
+		c.r = bufio.NewReader(c.Conn)
+		c.source, c.err = readHeader(c.r)
+		_ = c.SetReadDeadline(time.Time{})
+	})
+}
+
+// Read reads the data following the header; a missing or malformed header
+// fails every Read.
+func (c *conn) Read(b []byte) (int, error) {
+	c.init()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(b)
+}
+
+// RemoteAddr returns the client address the header carries, or the peer's
+// own for a header without one (a balancer's health check, say).
+func (c *conn) RemoteAddr() net.Addr {
+	c.init()
+	if c.source != nil {
+		return c.source
+	}
+	return c.Conn.RemoteAddr()
+}
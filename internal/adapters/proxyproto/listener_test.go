@@ -0,0 +1,86 @@
+package proxyproto
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+// roundTrip sends payload over a connection to l and returns the address
+// the accepted connection reports and the data read from it.
+func roundTrip(t *testing.T, l *Listener, payload string) (remote string, data string, err error) {
+	t.Helper()
+	go func() {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			return
+		}
+		_, _ = c.Write([]byte(payload))
+		_ = c.Close()
+	}()
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c.Close() }()
+	b, err := io.ReadAll(c)
+	return c.RemoteAddr().String(), string(b), err
+}
+
+func newTestListener(t *testing.T, trusted ...string) *Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewListener(ln, trusted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	return l
+}
+
+func TestListener(t *testing.T) {
+	const header = "PROXY TCP4 203.0.113.7 10.0.0.1 12345 443\r\n"
+
+	t.Run("trusted peer", func(t *testing.T) {
+		l := newTestListener(t, "127.0.0.0/8")
+		remote, data, err := roundTrip(t, l, header+"hello")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if remote != "203.0.113.7:12345" || data != "hello" {
+			t.Errorf("got %s %q, want the client address and the payload", remote, data)
+		}
+	})
+
+	t.Run("every peer without trusted networks", func(t *testing.T) {
+		l := newTestListener(t)
+		if _, _, err := roundTrip(t, l, "hello"); err == nil {
+			t.Error("connection without a header was accepted")
+		}
+	})
+
+	t.Run("untrusted peer passes through", func(t *testing.T) {
+		l := newTestListener(t, "10.0.0.0/8")
+		remote, data, err := roundTrip(t, l, header+"hello")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if remote == "203.0.113.7:12345" || data != header+"hello" {
+			t.Errorf("untrusted header was honored: %s %q", remote, data)
+		}
+	})
+}
+
+func TestNewListenerRejectsInvalidCIDR(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	if _, err := NewListener(ln, []string{"10.0.0.1"}); err == nil {
+		t.Error("NewListener accepted a bare IP as CIDR")
+	}
+}
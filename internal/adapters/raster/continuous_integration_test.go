@@ -175,14 +175,14 @@ func TestBundleExtractionCap(t *testing.T) {
 	tiny := NewRepository(t.TempDir())
 	tiny.SetMaxBundleBytes(1024) // 1 KiB — far below the fixture COG
 	t.Cleanup(func() { _ = tiny.Close(context.Background(), "regions") })
-	if _, err := tiny.Open(context.Background(), zipPath); err == nil {
+	if _, err := tiny.Open(context.Background(), domain.DeriveSourceID(zipPath), zipPath); err == nil {
 		t.Fatal("expected an extraction-cap error with a 1 KiB cap, got nil")
 	}
 
 	ok := NewRepository(t.TempDir())
 	ok.SetMaxBundleBytes(64 << 20) // 64 MiB — ample
 	t.Cleanup(func() { _ = ok.Close(context.Background(), "regions") })
-	if _, err := ok.Open(context.Background(), zipPath); err != nil {
+	if _, err := ok.Open(context.Background(), domain.DeriveSourceID(zipPath), zipPath); err != nil {
 		t.Fatalf("open with a generous cap should succeed: %v", err)
 	}
 }
@@ -248,7 +248,7 @@ func TestTiledLayerRouting(t *testing.T) {
 
 	repo := NewRepository(t.TempDir())
 	t.Cleanup(func() { _ = repo.Close(context.Background(), "dem") })
-	if _, err := repo.Open(context.Background(), zipPath); err != nil {
+	if _, err := repo.Open(context.Background(), domain.DeriveSourceID(zipPath), zipPath); err != nil {
 		t.Fatalf("Open: %v", err)
 	}
 
@@ -269,6 +269,15 @@ func TestTiledLayerRouting(t *testing.T) {
 	if len(none) != 0 {
 		t.Fatalf("absent tile = %+v, want no feature", none)
 	}
+
+	// The first query opened the tile; a second finds it open. An absent
+	// tile is no lookup at all.
+	if _, err := repo.QueryPoint(context.Background(), "dem", "elevation", wgs84c(20.5, 20.5)); err != nil {
+		t.Fatalf("second present-tile query: %v", err)
+	}
+	if got := repo.CacheStats(); got.Hits != 1 || got.Misses != 1 {
+		t.Errorf("CacheStats = %+v, want 1 hit and 1 miss", got)
+	}
 }
 
 // wgs84c is a WGS84 coordinate helper for raster tests.
@@ -292,7 +301,7 @@ func TestTiledConcurrentQueries(t *testing.T) {
 	repo := NewRepository(t.TempDir())
 	repo.SetTileCacheSize(1) // 1 < 2 tiles → eviction races reads under load
 	t.Cleanup(func() { _ = repo.Close(context.Background(), "dem") })
-	if _, err := repo.Open(context.Background(), zipPath); err != nil {
+	if _, err := repo.Open(context.Background(), domain.DeriveSourceID(zipPath), zipPath); err != nil {
 		t.Fatalf("Open: %v", err)
 	}
 
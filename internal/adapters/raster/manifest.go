@@ -66,6 +66,7 @@ type layerSpec struct {
 	Offset         *float64                       `yaml:"offset"`          // continuous only; default 0
 	Mapping        map[int]map[string]interface{} `yaml:"mapping"`
 	ValueMapping   string                         `yaml:"value_mapping"`
+	Notes          []string                       `yaml:"notes"` // caveats attached to results from this layer
 }
 
 // tilesSpec describes a multi-tile layer: many COGs on a regular degree grid,
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
 )
 
 // persistentRepo builds a Repository in persistent (content-addressed cache) mode
@@ -35,7 +37,7 @@ func TestPersistentReuseSkipsReextract(t *testing.T) {
 	zipPath := buildBundle(t, t.TempDir(), "regions", validManifest)
 
 	r1 := persistentRepo(cache)
-	if _, err := r1.Open(ctx, zipPath); err != nil {
+	if _, err := r1.Open(ctx, domain.DeriveSourceID(zipPath), zipPath); err != nil {
 		t.Fatalf("first Open: %v", err)
 	}
 	dirs := cacheDirs(t, cache)
@@ -63,7 +65,7 @@ func TestPersistentReuseSkipsReextract(t *testing.T) {
 
 	// Fresh repo, same cache volume = a container restart.
 	r2 := persistentRepo(cache)
-	if _, err := r2.Open(ctx, zipPath); err != nil {
+	if _, err := r2.Open(ctx, domain.DeriveSourceID(zipPath), zipPath); err != nil {
 		t.Fatalf("second Open: %v", err)
 	}
 	if got := cacheDirs(t, cache); len(got) != 1 || got[0] != dir {
@@ -81,14 +83,14 @@ func TestPersistentReextractsOnContentChange(t *testing.T) {
 	cache := t.TempDir()
 
 	zip1 := buildBundle(t, t.TempDir(), "regions", validManifest)
-	if _, err := persistentRepo(cache).Open(ctx, zip1); err != nil {
+	if _, err := persistentRepo(cache).Open(ctx, domain.DeriveSourceID(zip1), zip1); err != nil {
 		t.Fatalf("open v1: %v", err)
 	}
 
 	// Same id/filename, changed manifest bytes → different central-dir CRC → new fp.
 	v2 := validManifest + "\ndescription: changed\n"
 	zip2 := buildBundle(t, t.TempDir(), "regions", v2)
-	if _, err := persistentRepo(cache).Open(ctx, zip2); err != nil {
+	if _, err := persistentRepo(cache).Open(ctx, domain.DeriveSourceID(zip2), zip2); err != nil {
 		t.Fatalf("open v2: %v", err)
 	}
 
@@ -134,13 +136,13 @@ func TestPersistentPrune(t *testing.T) {
 
 	r1 := persistentRepo(cache)
 	r1.SetPrune(true)
-	if _, err := r1.Open(ctx, buildBundle(t, t.TempDir(), "regions", validManifest)); err != nil {
+	if _, err := r1.Open(ctx, "regions", buildBundle(t, t.TempDir(), "regions", validManifest)); err != nil {
 		t.Fatalf("open v1: %v", err)
 	}
 
 	r2 := persistentRepo(cache)
 	r2.SetPrune(true)
-	if _, err := r2.Open(ctx, buildBundle(t, t.TempDir(), "regions", validManifest+"\ndescription: v2\n")); err != nil {
+	if _, err := r2.Open(ctx, "regions", buildBundle(t, t.TempDir(), "regions", validManifest+"\ndescription: v2\n")); err != nil {
 		t.Fatalf("open v2: %v", err)
 	}
 	if dirs := cacheDirs(t, cache); len(dirs) != 1 {
@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/paulmach/orb"
 	"github.com/tingold/gocog"
@@ -32,6 +33,11 @@ type Repository struct {
 	maxBundleBytes int64 // per-bundle extraction cap; 0 → defaultMaxBundleBytes
 	persistent     bool  // content-addressed cache: reuse extractions across restarts
 	prune          bool  // remove older cached extractions of a source after a new one loads
+
+	// Lookups in the tiled layers' open-handle LRUs, over all bundles ever
+	// loaded; see CacheStats.
+	tileHits   atomic.Int64
+	tileMisses atomic.Int64
 }
 
 type bundle struct {
@@ -118,6 +124,12 @@ func (r *Repository) SetTileCacheSize(n int) { r.tileCacheSize = n }
 // bundles such as continental DEM tile sets. Set before sources load.
 func (r *Repository) SetMaxBundleBytes(n int64) { r.maxBundleBytes = n }
 
+// CacheStats implements output.CacheStatsReporter: the lookups in the tiled
+// layers' open-handle LRUs. A hit found the tile already open; a miss opened it.
+func (r *Repository) CacheStats() output.CacheStats {
+	return output.CacheStats{Hits: r.tileHits.Load(), Misses: r.tileMisses.Load()}
+}
+
 // bundleCap returns the effective per-bundle extraction cap.
 func (r *Repository) bundleCap() int64 {
 	if r.maxBundleBytes > 0 {
@@ -183,15 +195,14 @@ func (r *Repository) Prepare(_ context.Context, _ string, _ string) error {
 }
 
 // Open unpacks (ephemeral mode) or reuses a content-addressed extraction
-// (persistent mode) of a raster bundle and returns its domain.Source.
-func (r *Repository) Open(ctx context.Context, path string) (*domain.Source, error) {
+// (persistent mode) of a raster bundle and returns its domain.Source under
+// sourceID.
+func (r *Repository) Open(ctx context.Context, sourceID, path string) (*domain.Source, error) {
 	_, span := r.tracer.Start(ctx, "raster.Open",
 		output.WithAttributes(output.String("ortus.source.path", path)),
 	)
 	defer span.End()
 
-	sourceID := domain.DeriveSourceID(path)
-
 	r.mu.RLock()
 	existing, ok := r.sources[sourceID]
 	r.mu.RUnlock()
@@ -252,10 +263,11 @@ func (r *Repository) loadBundle(dir, sourceID, path string) (*bundle, error) {
 		return nil, err
 	}
 
-	// The bundle filename stem must equal the manifest id so the registry's
-	// filename-based dedup (derive*ID) stays consistent.
-	if m.ID != sourceID {
-		return nil, fmt.Errorf("bundle filename stem %q does not match manifest id %q", sourceID, m.ID)
+	// The bundle filename stem must equal the manifest id, so a bundle always
+	// says which file it belongs to. The id it is served under is the one the
+	// registry assigned (the stem, under the default strategy).
+	if stem := domain.DeriveSourceID(path); m.ID != stem {
+		return nil, fmt.Errorf("bundle filename stem %q does not match manifest id %q", stem, m.ID)
 	}
 
 	srid, err := parseEPSG(m.CRS)
@@ -268,7 +280,7 @@ func (r *Repository) loadBundle(dir, sourceID, path string) (*bundle, error) {
 		layers: make(map[string]*rasterLayer),
 	}
 	src := &domain.Source{
-		ID:      m.ID,
+		ID:      sourceID,
 		Name:    m.Name,
 		Path:    path,
 		Kind:    domain.SourceKindRaster,
@@ -305,6 +317,7 @@ func (r *Repository) loadBundle(dir, sourceID, path string) (*bundle, error) {
 			GeometryType: string(domain.GeomRaster),
 			SRID:         srid,
 			HasIndex:     true,
+			Notes:        spec.Notes,
 		})
 	}
 
@@ -572,6 +585,7 @@ func (r *Repository) QueryPoint(ctx context.Context, sourceID, layerName string,
 		return []domain.Feature{{
 			LayerName:  layerName,
 			Properties: map[string]interface{}{layer.outputProp: f*layer.scale + layer.offset},
+			Match:      domain.Match{Kind: domain.MatchContains},
 		}}, nil
 	}
 
@@ -593,6 +607,7 @@ func (r *Repository) QueryPoint(ctx context.Context, sourceID, layerName string,
 		ID:         value,
 		LayerName:  layerName,
 		Properties: props,
+		Match:      domain.Match{Kind: domain.MatchContains},
 	}}, nil
 }
 
@@ -607,7 +622,12 @@ func (r *Repository) queryTiled(sourceID, layerName string, coord domain.Coordin
 		return nil, nil // no tile → sea level / no coverage
 	}
 	key := [2]int{latDeg, lonDeg}
-	ot, err := ts.acquire(key, name)
+	ot, hit, err := ts.acquire(key, name)
+	if hit {
+		r.tileHits.Add(1)
+	} else {
+		r.tileMisses.Add(1)
+	}
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(output.StatusError, "tile open failed")
@@ -641,6 +661,7 @@ func (r *Repository) queryTiled(sourceID, layerName string, coord domain.Coordin
 	return []domain.Feature{{
 		LayerName:  layerName,
 		Properties: map[string]interface{}{ts.outputProp: f*ts.scale + ts.offset},
+		Match:      domain.Match{Kind: domain.MatchContains},
 	}}, nil
 }
 
@@ -74,7 +74,7 @@ func openBundleForTest(t *testing.T, manifestYAML string) (*Repository, *domain.
 
 	repo := NewRepository(t.TempDir())
 	t.Cleanup(func() { _ = repo.Close(context.Background(), "regions") })
-	src, err := repo.Open(context.Background(), zipPath)
+	src, err := repo.Open(context.Background(), domain.DeriveSourceID(zipPath), zipPath)
 	if err != nil {
 		t.Fatalf("Open: %v", err)
 	}
@@ -200,7 +200,7 @@ func TestFilenameMustMatchManifestID(t *testing.T) {
 	// bundle file is wrongname.zip but manifest id is "regions"
 	zipPath := buildBundle(t, dir, "wrongname", validManifest)
 	repo := NewRepository(t.TempDir())
-	if _, err := repo.Open(context.Background(), zipPath); err == nil {
+	if _, err := repo.Open(context.Background(), domain.DeriveSourceID(zipPath), zipPath); err == nil {
 		t.Error("expected error when filename stem != manifest id")
 	}
 }
@@ -244,7 +244,7 @@ layers:
 			dir := t.TempDir()
 			zipPath := buildBundle(t, dir, "regions", m)
 			repo := NewRepository(t.TempDir())
-			if _, err := repo.Open(context.Background(), zipPath); err == nil {
+			if _, err := repo.Open(context.Background(), domain.DeriveSourceID(zipPath), zipPath); err == nil {
 				t.Errorf("expected rejection for %s", name)
 			}
 		})
@@ -312,7 +312,7 @@ layers:
 			})
 			repo := NewRepository(t.TempDir())
 			t.Cleanup(func() { _ = repo.Close(context.Background(), "regions") })
-			if _, err := repo.Open(context.Background(), zipPath); err != nil {
+			if _, err := repo.Open(context.Background(), domain.DeriveSourceID(zipPath), zipPath); err != nil {
 				t.Fatalf("Open: %v", err)
 			}
 			feats, err := repo.QueryPoint(context.Background(), "regions", "main", domain.NewWGS84Coordinate(20, 20))
@@ -332,7 +332,7 @@ func TestReopenReturnsSameSource(t *testing.T) {
 	// with the same id.)
 	dir := t.TempDir()
 	zipPath := buildBundle(t, dir, "regions", validManifest)
-	src2, err := repo.Open(context.Background(), zipPath)
+	src2, err := repo.Open(context.Background(), domain.DeriveSourceID(zipPath), zipPath)
 	if err != nil {
 		t.Fatalf("reopen: %v", err)
 	}
@@ -350,7 +350,7 @@ func TestCloseRemovesBundle(t *testing.T) {
 	zipPath := buildBundle(t, dir, "regions", validManifest)
 	cache := t.TempDir()
 	repo := NewRepository(cache)
-	if _, err := repo.Open(context.Background(), zipPath); err != nil {
+	if _, err := repo.Open(context.Background(), domain.DeriveSourceID(zipPath), zipPath); err != nil {
 		t.Fatal(err)
 	}
 	if err := repo.Close(context.Background(), "regions"); err != nil {
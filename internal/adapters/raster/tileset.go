@@ -129,9 +129,10 @@ func tileFileName(pattern string, latDeg, lonDeg int) string {
 func (t *tileset) present(name string) bool { return t.files[name] }
 
 // acquire returns the open handle for a cell, opening (and LRU-caching) it on a
-// miss, and pins it (refs++) so eviction cannot close it mid-read. The caller
-// MUST call release(key) when done reading. The caller holds no lock.
-func (t *tileset) acquire(key [2]int, name string) (*openTile, error) {
+// miss, and pins it (refs++) so eviction cannot close it mid-read. hit reports
+// whether the handle was already open. The caller MUST call release(key) when
+// done reading. The caller holds no lock.
+func (t *tileset) acquire(key [2]int, name string) (ot *openTile, hit bool, err error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -139,17 +140,17 @@ func (t *tileset) acquire(key [2]int, name string) (*openTile, error) {
 		t.ll.MoveToFront(el)
 		e := el.Value.(*lruEntry)
 		e.refs++
-		return e.tile, nil
+		return e.tile, true, nil
 	}
 
-	ot, err := t.openTile(name)
+	ot, err = t.openTile(name)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	el := t.ll.PushFront(&lruEntry{key: key, tile: ot, refs: 1})
 	t.byKey[key] = el
 	t.evictLocked()
-	return ot, nil
+	return ot, false, nil
 }
 
 // release drops one reference taken by acquire, letting the tile become evictable.
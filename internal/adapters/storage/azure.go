@@ -107,10 +107,10 @@ func (s *AzureStorage) List(ctx context.Context) ([]output.StorageObject, error)
 func (s *AzureStorage) blobToStorageObject(blob *container.BlobItem) (output.StorageObject, bool) {
 	name := *blob.Name
 
-	// Only include supported source files (GeoPackage + raster bundles) —
-	// same rule as the local/http backends so raster sources aren't
-	// silently dropped on Azure.
-	if !domain.IsSupportedSourceFile(name) {
+	// Only include supported source files (GeoPackage + raster bundles, and
+	// compressed GeoPackages) — same rule as the s3/http backends so raster
+	// sources aren't silently dropped on Azure.
+	if !domain.IsStoredSourceFile(name) {
 		return output.StorageObject{}, false
 	}
 
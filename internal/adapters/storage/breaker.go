@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// ErrCircuitOpen is returned without calling the backend while the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("storage circuit breaker open")
+
+// Circuit breaker states, as reported by State.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// BreakerConfig configures CircuitBreakerStorage.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed calls that opens
+	// the circuit.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a probe call is
+	// let through.
+	OpenTimeout time.Duration
+	// OnStateChange, if set, is called on every transition, outside the lock.
+	OnStateChange func(from, to string)
+}
+
+// CircuitBreakerStorage decorates an ObjectStorage with a circuit breaker
+// (storage.circuit_breaker): after FailureThreshold consecutive failures the
+// backend is left alone for OpenTimeout, with every call failing fast with
+// ErrCircuitOpen, instead of each sync and download waiting out timeouts
+// against an endpoint that is down. Then a single probe call is let through
+// (half-open); its success closes the circuit, its failure opens it again.
+// Calls abandoned by their caller (context canceled) count neither way; a
+// missing object (os.ErrNotExist) is an answer, so it counts as success.
+type CircuitBreakerStorage struct {
+	inner output.ObjectStorage
+	cfg   BreakerConfig
+	now   func() time.Time // replaced in tests
+
+	mu       sync.Mutex
+	state    string
+	failures int       // consecutive failures while closed
+	openedAt time.Time // when the circuit last opened
+	probing  bool      // a half-open probe is in flight
+}
+
+// NewCircuitBreakerStorage wraps inner with a closed circuit breaker.
+func NewCircuitBreakerStorage(inner output.ObjectStorage, cfg BreakerConfig) *CircuitBreakerStorage {
+	return &CircuitBreakerStorage{inner: inner, cfg: cfg, now: time.Now, state: CircuitClosed}
+}
+
+// State returns the breaker's current state: CircuitClosed, CircuitOpen or
+// CircuitHalfOpen. An open circuit whose timeout has run out reports
+// half-open, since the next call will probe. A nil breaker is closed.
+func (b *CircuitBreakerStorage) State() string {
+	if b == nil {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a call may go to the backend, moving an open circuit
+// to half-open once its timeout has run out.
+func (b *CircuitBreakerStorage) allow() bool {
+	b.mu.Lock()
+	var from string
+	defer func() {
+		b.mu.Unlock()
+		b.notify(from, CircuitHalfOpen)
+	}()
+	switch b.state {
+	case CircuitClosed:
+		return true
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.OpenTimeout {
+			return false
+		}
+		from, b.state = b.state, CircuitHalfOpen
+	}
+	// Half-open: one probe at a time; the others keep failing fast.
+	if b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record feeds a call's outcome into the breaker.
+func (b *CircuitBreakerStorage) record(ctx context.Context, err error) {
+	if err != nil && ctx.Err() != nil {
+		// The caller gave up; that says nothing about the backend. A probe
+		// abandoned this way frees the slot for the next one.
+		b.mu.Lock()
+		b.probing = false
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Lock()
+	from, to := b.state, b.state
+	switch {
+	case err == nil || errors.Is(err, os.ErrNotExist):
+		b.failures = 0
+		to = CircuitClosed
+	case b.state == CircuitHalfOpen:
+		to = CircuitOpen
+	default:
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			to = CircuitOpen
+		}
+	}
+	if to == CircuitOpen && from != CircuitOpen {
+		b.openedAt = b.now()
+		b.failures = 0
+	}
+	b.state = to
+	b.probing = false
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+func (b *CircuitBreakerStorage) notify(from, to string) {
+	if from != "" && from != to && b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(from, to)
+	}
+}
+
+// List implements ObjectStorage.
+func (b *CircuitBreakerStorage) List(ctx context.Context) ([]output.StorageObject, error) {
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+	objs, err := b.inner.List(ctx)
+	b.record(ctx, err)
+	return objs, err
+}
+
+// Download implements ObjectStorage.
+func (b *CircuitBreakerStorage) Download(ctx context.Context, key, dest string) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := b.inner.Download(ctx, key, dest)
+	b.record(ctx, err)
+	return err
+}
+
+// DownloadVersion implements VersionedStorage when the wrapped storage does.
+func (b *CircuitBreakerStorage) DownloadVersion(ctx context.Context, key, versionID, dest string) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := downloadVersion(ctx, b.inner, key, versionID, dest)
+	b.record(ctx, err)
+	return err
+}
+
+// GetReader implements ObjectStorage.
+func (b *CircuitBreakerStorage) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+	r, err := b.inner.GetReader(ctx, key)
+	b.record(ctx, err)
+	return r, err
+}
+
+// Exists implements ObjectStorage.
+func (b *CircuitBreakerStorage) Exists(ctx context.Context, key string) (bool, error) {
+	if !b.allow() {
+		return false, ErrCircuitOpen
+	}
+	ok, err := b.inner.Exists(ctx, key)
+	b.record(ctx, err)
+	return ok, err
+}
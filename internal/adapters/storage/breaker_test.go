@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// countingInner fails while err is set and counts the calls that reach it.
+type countingInner struct {
+	fakeInner
+	calls int
+}
+
+func (c *countingInner) List(ctx context.Context) ([]output.StorageObject, error) {
+	c.calls++
+	return c.fakeInner.List(ctx)
+}
+
+// newTestBreaker returns a breaker over inner with a clock the test moves.
+func newTestBreaker(inner *countingInner, transitions *[]string) (*CircuitBreakerStorage, *time.Time) {
+	b := NewCircuitBreakerStorage(inner, BreakerConfig{
+		FailureThreshold: 3,
+		OpenTimeout:      time.Minute,
+		OnStateChange: func(from, to string) {
+			*transitions = append(*transitions, from+">"+to)
+		},
+	})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+// TestCircuitBreaker_OpensAndRecovers walks the breaker through its states:
+// consecutive failures open it, it fails fast while open, and a successful
+// probe after the timeout closes it again.
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	inner := &countingInner{fakeInner: fakeInner{err: errors.New("connection refused")}}
+	var transitions []string
+	b, now := newTestBreaker(inner, &transitions)
+	ctx := context.Background()
+
+	for range 3 {
+		_, _ = b.List(ctx)
+	}
+	if got := b.State(); got != CircuitOpen {
+		t.Fatalf("state after 3 failures = %q, want open", got)
+	}
+	if _, err := b.List(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("List while open err = %v, want ErrCircuitOpen", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("backend calls = %d, want 3 (none while open)", inner.calls)
+	}
+
+	*now = now.Add(time.Minute)
+	if got := b.State(); got != CircuitHalfOpen {
+		t.Fatalf("state after the timeout = %q, want half_open", got)
+	}
+	inner.err = nil
+	if _, err := b.List(ctx); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if got := b.State(); got != CircuitClosed {
+		t.Errorf("state after a successful probe = %q, want closed", got)
+	}
+	want := fmt.Sprint([]string{"closed>open", "open>half_open", "half_open>closed"})
+	if got := fmt.Sprint(transitions); got != want {
+		t.Errorf("transitions = %s, want %s", got, want)
+	}
+}
+
+// TestCircuitBreaker_FailedProbeReopens: a failing probe opens the circuit
+// for another full timeout.
+func TestCircuitBreaker_FailedProbeReopens(t *testing.T) {
+	inner := &countingInner{fakeInner: fakeInner{err: errors.New("503")}}
+	var transitions []string
+	b, now := newTestBreaker(inner, &transitions)
+	ctx := context.Background()
+	for range 3 {
+		_, _ = b.List(ctx)
+	}
+
+	*now = now.Add(time.Minute)
+	if _, err := b.List(ctx); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("probe err = %v, want the backend's error", err)
+	}
+	if got := b.State(); got != CircuitOpen {
+		t.Fatalf("state after a failed probe = %q, want open", got)
+	}
+	*now = now.Add(30 * time.Second)
+	if _, err := b.List(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("List half a timeout after the failed probe err = %v, want ErrCircuitOpen", err)
+	}
+}
+
+// TestCircuitBreaker_IgnoresNonBackendErrors: successes reset the count, and
+// neither a missing object nor a caller giving up counts as a failure.
+func TestCircuitBreaker_IgnoresNonBackendErrors(t *testing.T) {
+	inner := &countingInner{}
+	var transitions []string
+	b, _ := newTestBreaker(inner, &transitions)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, tc := range []struct {
+		ctx context.Context
+		err error
+	}{
+		{context.Background(), errors.New("timeout")},
+		{context.Background(), errors.New("timeout")},
+		{context.Background(), nil},
+		{context.Background(), errors.New("timeout")},
+		{context.Background(), fmt.Errorf("index.gpkg: %w", os.ErrNotExist)},
+		{canceled, context.Canceled},
+		{context.Background(), errors.New("timeout")},
+		{context.Background(), errors.New("timeout")},
+	} {
+		inner.err = tc.err
+		_, _ = b.List(tc.ctx)
+	}
+	if got := b.State(); got != CircuitClosed {
+		t.Errorf("state = %q, want closed: no three backend failures in a row", got)
+	}
+}
+
+func TestCircuitBreaker_NilIsClosed(t *testing.T) {
+	var b *CircuitBreakerStorage
+	if got := b.State(); got != CircuitClosed {
+		t.Errorf("nil breaker state = %q, want closed", got)
+	}
+}
@@ -0,0 +1,515 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// Catalog protocols understood by CatalogStorage.
+const (
+	CatalogProtocolCSW  = "csw"  // OGC CSW 2.0.2 GetRecords, Dublin Core records
+	CatalogProtocolDCAT = "dcat" // DCAT catalog as JSON / JSON-LD (DCAT-AP, data.json)
+)
+
+// geopackageMediaType is the registered media type of a GeoPackage.
+const geopackageMediaType = "application/geopackage+sqlite3"
+
+// maxCatalogPages bounds CSW paging so a catalog that never stops reporting a
+// next record cannot keep a sync busy forever.
+const maxCatalogPages = 100
+
+// CatalogStorage implements ObjectStorage over a data-portal catalog: List
+// harvests the catalog for GeoPackage distributions that pass the configured
+// filters, and Download fetches a distribution by the key List returned. It
+// replaces a hand-maintained HTTP index file with the portal's own metadata.
+//
+// Keys are the file names of the distribution URLs, so the usual source id
+// strategies apply. When two datasets publish the same file name, the one
+// with the lexically smaller URL wins and the other is skipped.
+type CatalogStorage struct {
+	client     *http.Client
+	catalogURL string
+	protocol   string
+	keywords   []string
+	titleRe    *regexp.Regexp
+	maxRecords int
+	username   string
+	password   string
+	mu         sync.Mutex
+	urls       map[string]string // key → download URL, from the last harvest
+}
+
+// CatalogConfig holds catalog harvester configuration.
+type CatalogConfig struct {
+	URL          string
+	Protocol     string   // csw or dcat
+	Keywords     []string // dataset must carry one of these (case-insensitive); empty = any
+	TitlePattern string   // regexp the dataset title must match; empty = any
+	MaxRecords   int      // CSW page size; default 100
+	Timeout      time.Duration
+	Username     string
+	Password     string
+}
+
+// catalogEntry is one GeoPackage distribution found in the catalog.
+type catalogEntry struct {
+	title    string
+	keywords []string
+	url      string
+}
+
+// NewCatalogStorage creates a catalog harvester storage adapter. The HTTP
+// client is wrapped with otelhttp like the plain HTTP adapter's.
+func NewCatalogStorage(cfg CatalogConfig) (*CatalogStorage, error) {
+	protocol := strings.ToLower(cfg.Protocol)
+	if protocol != CatalogProtocolCSW && protocol != CatalogProtocolDCAT {
+		return nil, fmt.Errorf("unknown catalog protocol %q (csw, dcat)", cfg.Protocol)
+	}
+	var titleRe *regexp.Regexp
+	if cfg.TitlePattern != "" {
+		re, err := regexp.Compile(cfg.TitlePattern)
+		if err != nil {
+			return nil, fmt.Errorf("catalog title pattern: %w", err)
+		}
+		titleRe = re
+	}
+	if cfg.MaxRecords <= 0 {
+		cfg.MaxRecords = 100
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Minute
+	}
+	keywords := make([]string, 0, len(cfg.Keywords))
+	for _, k := range cfg.Keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			keywords = append(keywords, k)
+		}
+	}
+
+	return &CatalogStorage{
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+		catalogURL: cfg.URL,
+		protocol:   protocol,
+		keywords:   keywords,
+		titleRe:    titleRe,
+		maxRecords: cfg.MaxRecords,
+		username:   cfg.Username,
+		password:   cfg.Password,
+		urls:       make(map[string]string),
+	}, nil
+}
+
+// List harvests the catalog and returns one object per matching GeoPackage
+// distribution. A failed harvest returns the error and keeps the previous
+// key → URL mapping, so downloads of already-known keys keep working.
+func (s *CatalogStorage) List(ctx context.Context) ([]output.StorageObject, error) {
+	var entries []catalogEntry
+	var err error
+	switch s.protocol {
+	case CatalogProtocolCSW:
+		entries, err = s.harvestCSW(ctx)
+	default:
+		entries, err = s.harvestDCAT(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make(map[string]string)
+	for _, e := range entries {
+		if !s.matches(e) {
+			continue
+		}
+		key, ok := catalogKey(e.url)
+		if !ok {
+			continue
+		}
+		if prev, dup := urls[key]; dup && prev <= e.url {
+			continue
+		}
+		urls[key] = e.url
+	}
+
+	s.mu.Lock()
+	s.urls = urls
+	s.mu.Unlock()
+
+	objects := make([]output.StorageObject, 0, len(urls))
+	for key := range urls {
+		objects = append(objects, output.StorageObject{Key: key})
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// matches applies the keyword and title filters to a catalog entry.
+func (s *CatalogStorage) matches(e catalogEntry) bool {
+	if s.titleRe != nil && !s.titleRe.MatchString(e.title) {
+		return false
+	}
+	if len(s.keywords) == 0 {
+		return true
+	}
+	for _, k := range e.keywords {
+		k = strings.ToLower(strings.TrimSpace(k))
+		for _, want := range s.keywords {
+			if k == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// catalogKey derives the storage key (the file name) from a distribution URL.
+// Only http(s) URLs are accepted; a URL without a .gpkg file name gets one, as
+// the media type already identified it as a GeoPackage.
+func catalogKey(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" || name == ".." {
+		return "", false
+	}
+	return domain.EnsureGeoPackageExt(name), true
+}
+
+// isGeoPackageDistribution reports whether a distribution is a GeoPackage,
+// judged by media type, format label or the URL's file extension.
+func isGeoPackageDistribution(rawURL string, formats ...string) bool {
+	for _, f := range formats {
+		f = strings.ToLower(strings.TrimSpace(f))
+		// The registered media type, IANA/EU vocabulary URIs ending in /GPKG,
+		// and free-text labels such as "GeoPackage" or "OGC GeoPackage".
+		if f == geopackageMediaType || strings.Contains(f, "geopackage") || f == "gpkg" || strings.HasSuffix(f, "/gpkg") {
+			return true
+		}
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		_, compressed := domain.DecompressedSourceKey(u.Path)
+		return compressed || domain.IsGeoPackageFile(u.Path)
+	}
+	return false
+}
+
+// urlFor returns the download URL of key, harvesting once if the key is not
+// known yet (e.g. Download called before any List).
+func (s *CatalogStorage) urlFor(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	u, ok := s.urls[key]
+	s.mu.Unlock()
+	if ok {
+		return u, nil
+	}
+	if _, err := s.List(ctx); err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	u, ok = s.urls[key]
+	s.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("%s: %w", key, os.ErrNotExist)
+	}
+	return u, nil
+}
+
+// do issues a request with the configured basic auth.
+func (s *CatalogStorage) do(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.username != "" && s.password != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	return s.client.Do(req)
+}
+
+// fetch GETs rawURL and returns the body of a 200 response.
+func (s *CatalogStorage) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching catalog: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("catalog returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Download downloads a harvested distribution to the local filesystem.
+func (s *CatalogStorage) Download(ctx context.Context, key string, dest string) error {
+	fileURL, err := s.urlFor(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0750); err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodGet, fileURL)
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", key, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download returned status %d for %s", resp.StatusCode, key)
+	}
+
+	f, err := os.Create(filepath.Clean(dest))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
+
+// GetReader returns a reader for a harvested distribution.
+func (s *CatalogStorage) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	fileURL, err := s.urlFor(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, fileURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("HTTP %d for %s", resp.StatusCode, key)
+	}
+	return resp.Body, nil
+}
+
+// Exists reports whether key is in the last harvest and its distribution URL
+// answers a HEAD request.
+func (s *CatalogStorage) Exists(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	fileURL, ok := s.urls[key]
+	s.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+
+	resp, err := s.do(ctx, http.MethodHead, fileURL)
+	if err != nil {
+		return false, fmt.Errorf("checking existence of %s: %w", key, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("checking existence of %s: unexpected status %d", key, resp.StatusCode)
+	}
+}
+
+// cswResponse is the part of a CSW 2.0.2 GetRecordsResponse the harvester
+// reads. Elements are matched by local name, so any namespace prefix works.
+type cswResponse struct {
+	Results struct {
+		Matched    int         `xml:"numberOfRecordsMatched,attr"`
+		NextRecord int         `xml:"nextRecord,attr"`
+		Records    []cswRecord `xml:"Record"`
+	} `xml:"SearchResults"`
+}
+
+type cswRecord struct {
+	Title      string   `xml:"title"`
+	Subjects   []string `xml:"subject"`
+	Formats    []string `xml:"format"`
+	URIs       []cswURI `xml:"URI"`
+	References []cswURI `xml:"references"`
+}
+
+// cswURI is a dc:URI (protocol attribute) or dct:references (scheme attribute).
+type cswURI struct {
+	Protocol string `xml:"protocol,attr"`
+	Scheme   string `xml:"scheme,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// harvestCSW pages through GetRecords (KVP, full Dublin Core records) until
+// the catalog reports no next record.
+func (s *CatalogStorage) harvestCSW(ctx context.Context) ([]catalogEntry, error) {
+	var entries []catalogEntry
+	start := 1
+	for page := 0; page < maxCatalogPages; page++ {
+		body, err := s.fetch(ctx, s.cswPageURL(start))
+		if err != nil {
+			return nil, err
+		}
+		var resp cswResponse
+		if err := xml.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("parsing CSW response: %w", err)
+		}
+		for _, rec := range resp.Results.Records {
+			entries = append(entries, rec.entries()...)
+		}
+		next := resp.Results.NextRecord
+		if next <= start || (resp.Results.Matched > 0 && next > resp.Results.Matched) {
+			return entries, nil
+		}
+		start = next
+	}
+	return entries, nil
+}
+
+// cswPageURL builds the GetRecords request for the page starting at start.
+// Parameters already on the configured URL (e.g. a constraint) are kept.
+func (s *CatalogStorage) cswPageURL(start int) string {
+	u, err := url.Parse(s.catalogURL)
+	if err != nil {
+		return s.catalogURL
+	}
+	q := u.Query()
+	set := func(k, v string) {
+		if q.Get(k) == "" {
+			q.Set(k, v)
+		}
+	}
+	set("service", "CSW")
+	set("version", "2.0.2")
+	set("request", "GetRecords")
+	set("typeNames", "csw:Record")
+	set("resultType", "results")
+	set("elementSetName", "full")
+	set("outputSchema", "http://www.opengis.net/cat/csw/2.0.2")
+	q.Set("maxRecords", strconv.Itoa(s.maxRecords))
+	q.Set("startPosition", strconv.Itoa(start))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// entries returns the record's GeoPackage links as catalog entries.
+func (r cswRecord) entries() []catalogEntry {
+	var out []catalogEntry
+	for _, link := range append(append([]cswURI{}, r.URIs...), r.References...) {
+		v := strings.TrimSpace(link.Value)
+		if v == "" || !isGeoPackageDistribution(v, append([]string{link.Protocol}, r.Formats...)...) {
+			continue
+		}
+		out = append(out, catalogEntry{title: strings.TrimSpace(r.Title), keywords: r.Subjects, url: v})
+	}
+	return out
+}
+
+// harvestDCAT reads a DCAT catalog serialized as JSON: a catalog object with a
+// dataset array (DCAT-AP JSON, data.json), with or without dcat:/dct: prefixes.
+func (s *CatalogStorage) harvestDCAT(ctx context.Context) ([]catalogEntry, error) {
+	body, err := s.fetch(ctx, s.catalogURL)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("parsing DCAT catalog: %w", err)
+	}
+
+	var entries []catalogEntry
+	for _, ds := range jsonObjects(dcatField(doc, "dataset")) {
+		title := firstString(dcatField(ds, "title"))
+		keywords := jsonStrings(dcatField(ds, "keyword"))
+		for _, dist := range jsonObjects(dcatField(ds, "distribution")) {
+			u := firstString(dcatField(dist, "downloadURL"))
+			if u == "" {
+				u = firstString(dcatField(dist, "accessURL"))
+			}
+			formats := append(jsonStrings(dcatField(dist, "mediaType")), jsonStrings(dcatField(dist, "format"))...)
+			if u == "" || !isGeoPackageDistribution(u, formats...) {
+				continue
+			}
+			entries = append(entries, catalogEntry{title: title, keywords: keywords, url: u})
+		}
+	}
+	return entries, nil
+}
+
+// dcatField looks a property up by plain name or with its dcat:/dct: prefix.
+func dcatField(obj map[string]interface{}, name string) interface{} {
+	for _, k := range []string{name, "dcat:" + name, "dct:" + name} {
+		if v, ok := obj[k]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// jsonObjects returns v as a list of objects (a single object counts as one).
+func jsonObjects(v interface{}) []map[string]interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{t}
+	case []interface{}:
+		out := make([]map[string]interface{}, 0, len(t))
+		for _, e := range t {
+			if m, ok := e.(map[string]interface{}); ok {
+				out = append(out, m)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// jsonStrings flattens a JSON-LD value — a string, a {"@value"}/{"@id"}
+// object, or a list of those — to its strings.
+func jsonStrings(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case map[string]interface{}:
+		for _, k := range []string{"@value", "@id"} {
+			if s, ok := t[k].(string); ok {
+				return []string{s}
+			}
+		}
+		return nil
+	case []interface{}:
+		var out []string
+		for _, e := range t {
+			out = append(out, jsonStrings(e)...)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func firstString(v interface{}) string {
+	if s := jsonStrings(v); len(s) > 0 {
+		return strings.TrimSpace(s[0])
+	}
+	return ""
+}
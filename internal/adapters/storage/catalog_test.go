@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dcatCatalog mixes plain and prefixed DCAT keys: a matching GeoPackage by
+// media type (URL without extension), one by file extension, a dataset with a
+// non-matching keyword and a matching dataset whose only distribution is a CSV.
+const dcatCatalog = `{
+  "@context": "https://www.w3.org/ns/dcat.jsonld",
+  "dataset": [
+    {"title": "Bezirke", "keyword": ["ortus", "admin"],
+     "distribution": [{"downloadURL": "%[1]s/download?id=7", "mediaType": "application/geopackage+sqlite3"}]},
+    {"dct:title": {"@value": "Parcels"}, "dcat:keyword": "ortus",
+     "dcat:distribution": {"dcat:accessURL": {"@id": "%[1]s/files/parcels.gpkg"}}},
+    {"title": "Roads", "keyword": ["transport"],
+     "distribution": [{"downloadURL": "%[1]s/files/roads.gpkg"}]},
+    {"title": "Stats", "keyword": ["ortus"],
+     "distribution": [{"downloadURL": "%[1]s/files/stats.csv", "format": "CSV"}]}
+  ]
+}`
+
+func newDCATFixture(t *testing.T) (*httptest.Server, *CatalogStorage) {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/catalog.jsonld":
+			_, _ = fmt.Fprintf(w, dcatCatalog, srv.URL)
+		case "/download", "/files/parcels.gpkg":
+			if r.Method == http.MethodHead {
+				return
+			}
+			_, _ = w.Write([]byte("gpkg:" + r.URL.RequestURI()))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	s, err := NewCatalogStorage(CatalogConfig{URL: srv.URL + "/catalog.jsonld", Protocol: "dcat", Keywords: []string{"ORTUS"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, s
+}
+
+func TestCatalogStorageDCAT(t *testing.T) {
+	ctx := context.Background()
+	_, s := newDCATFixture(t)
+
+	objs, err := s.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var keys []string
+	for _, o := range objs {
+		keys = append(keys, o.Key)
+	}
+	if strings.Join(keys, ",") != "download.gpkg,parcels.gpkg" {
+		t.Fatalf("keys = %v, want [download.gpkg parcels.gpkg]", keys)
+	}
+
+	dest := filepath.Join(t.TempDir(), "download.gpkg")
+	if err := s.Download(ctx, "download.gpkg", dest); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if b, _ := os.ReadFile(dest); string(b) != "gpkg:/download?id=7" {
+		t.Errorf("downloaded %q, want the distribution URL's body", b)
+	}
+
+	if ok, err := s.Exists(ctx, "parcels.gpkg"); err != nil || !ok {
+		t.Errorf("Exists(parcels.gpkg) = %v, %v; want true", ok, err)
+	}
+	if ok, err := s.Exists(ctx, "roads.gpkg"); err != nil || ok {
+		t.Errorf("Exists(roads.gpkg) = %v, %v; want false (filtered out)", ok, err)
+	}
+}
+
+// TestCatalogStorageDownloadBeforeList: a fresh adapter harvests on demand, and
+// a key the catalog does not offer is reported as not existing.
+func TestCatalogStorageDownloadBeforeList(t *testing.T) {
+	ctx := context.Background()
+	_, s := newDCATFixture(t)
+
+	r, err := s.GetReader(ctx, "parcels.gpkg")
+	if err != nil {
+		t.Fatalf("GetReader: %v", err)
+	}
+	_ = r.Close()
+
+	err = s.Download(ctx, "roads.gpkg", filepath.Join(t.TempDir(), "roads.gpkg"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Download of a filtered key = %v, want os.ErrNotExist", err)
+	}
+}
+
+// TestCatalogStorageCSWPaging: records are read across GetRecords pages until
+// nextRecord runs past numberOfRecordsMatched, and the title filter applies.
+func TestCatalogStorageCSWPaging(t *testing.T) {
+	const page = `<?xml version="1.0"?>
+<csw:GetRecordsResponse xmlns:csw="http://www.opengis.net/cat/csw/2.0.2"
+    xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dct="http://purl.org/dc/terms/">
+  <csw:SearchResults numberOfRecordsMatched="3" numberOfRecordsReturned="%d" nextRecord="%d">%s</csw:SearchResults>
+</csw:GetRecordsResponse>`
+	const rec = `<csw:Record><dc:title>%s</dc:title><dc:subject>ortus</dc:subject>%s</csw:Record>`
+
+	var starts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("request") != "GetRecords" || q.Get("constraint") != "keep" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		starts = append(starts, q.Get("startPosition"))
+		switch q.Get("startPosition") {
+		case "1":
+			_, _ = fmt.Fprintf(w, page, 2, 3,
+				fmt.Sprintf(rec, "Bezirke Berlin", `<dc:URI protocol="WWW:DOWNLOAD">https://data.example.org/bezirke.gpkg</dc:URI>`)+
+					fmt.Sprintf(rec, "Bezirke Hamburg", `<dct:references scheme="OGC:WMS">https://data.example.org/wms</dct:references>`))
+		default:
+			_, _ = fmt.Fprintf(w, page, 1, 0,
+				fmt.Sprintf(rec, "Gemeinden", `<dc:URI>https://data.example.org/gemeinden.gpkg</dc:URI>`))
+		}
+	}))
+	defer srv.Close()
+
+	s, err := NewCatalogStorage(CatalogConfig{
+		URL: srv.URL + "/csw?constraint=keep", Protocol: "csw", TitlePattern: "^Bezirke", MaxRecords: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	objs, err := s.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objs) != 1 || objs[0].Key != "bezirke.gpkg" {
+		t.Errorf("List = %+v, want [bezirke.gpkg]", objs)
+	}
+	if strings.Join(starts, ",") != "1,3" {
+		t.Errorf("startPositions = %v, want [1 3]", starts)
+	}
+}
+
+func TestCatalogKey(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"https://x.org/a/parcels.gpkg", "parcels.gpkg", true},
+		{"https://x.org/a/Parcels.GPKG?v=2", "Parcels.GPKG", true},
+		{"https://x.org/download", "download.gpkg", true},
+		{"https://x.org/", "", false},
+		{"ftp://x.org/parcels.gpkg", "", false},
+		{"file:///etc/passwd", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := catalogKey(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("catalogKey(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNewCatalogStorageRejectsBadConfig(t *testing.T) {
+	if _, err := NewCatalogStorage(CatalogConfig{URL: "https://x.org", Protocol: "oai-pmh"}); err == nil {
+		t.Error("unknown protocol accepted")
+	}
+	if _, err := NewCatalogStorage(CatalogConfig{URL: "https://x.org", Protocol: "dcat", TitlePattern: "("}); err == nil {
+		t.Error("invalid title pattern accepted")
+	}
+}
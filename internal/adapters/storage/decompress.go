@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// DecompressingStorage decorates a remote ObjectStorage so compressed
+// GeoPackages ("parcels.gpkg.gz", or a "parcels.gpkg.zip" holding one
+// .gpkg) are listed under their unpacked key ("parcels.gpkg") and unpacked
+// on download. The rest of ortus never sees the archives. When both forms of
+// a key exist, the uncompressed object wins.
+type DecompressingStorage struct {
+	inner output.ObjectStorage
+
+	mu       sync.Mutex
+	archives map[string]string // unpacked key -> archive key, from the last List
+}
+
+// NewDecompressingStorage wraps inner so compressed GeoPackages are unpacked.
+func NewDecompressingStorage(inner output.ObjectStorage) *DecompressingStorage {
+	return &DecompressingStorage{inner: inner, archives: make(map[string]string)}
+}
+
+// List implements ObjectStorage.
+func (s *DecompressingStorage) List(ctx context.Context) ([]output.StorageObject, error) {
+	objs, err := s.inner.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plain := make(map[string]bool, len(objs))
+	for _, obj := range objs {
+		plain[obj.Key] = true
+	}
+
+	archives := make(map[string]string)
+	listed := make([]output.StorageObject, 0, len(objs))
+	for _, obj := range objs {
+		if key, ok := domain.DecompressedSourceKey(obj.Key); ok {
+			if plain[key] || archives[key] != "" {
+				continue
+			}
+			archives[key] = obj.Key
+			obj.Key = key
+		}
+		listed = append(listed, obj)
+	}
+
+	s.mu.Lock()
+	s.archives = archives
+	s.mu.Unlock()
+	return listed, nil
+}
+
+// archive returns the archive key behind key, or "" when key is stored as is.
+func (s *DecompressingStorage) archive(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.archives[key]
+}
+
+// Download implements ObjectStorage. An archive is fetched next to dest and
+// unpacked into dest.
+func (s *DecompressingStorage) Download(ctx context.Context, key, dest string) error {
+	return s.download(key, dest, func(key, dest string) error {
+		return s.inner.Download(ctx, key, dest)
+	})
+}
+
+// DownloadVersion implements VersionedStorage when the wrapped storage does;
+// for an archive the version is the archive's.
+func (s *DecompressingStorage) DownloadVersion(ctx context.Context, key, versionID, dest string) error {
+	return s.download(key, dest, func(key, dest string) error {
+		return downloadVersion(ctx, s.inner, key, versionID, dest)
+	})
+}
+
+// download fetches key with get, unpacking it when it is an archive.
+func (s *DecompressingStorage) download(key, dest string, get func(key, dest string) error) error {
+	archive := s.archive(key)
+	if archive == "" {
+		return get(key, dest)
+	}
+
+	tmp := filepath.Clean(dest + ".archive")
+	defer func() { _ = os.Remove(tmp) }()
+	if err := get(archive, tmp); err != nil {
+		return err
+	}
+	if strings.HasSuffix(strings.ToLower(archive), ".gz") {
+		return gunzipFile(tmp, dest)
+	}
+	return unzipGeoPackage(tmp, dest)
+}
+
+// GetReader implements ObjectStorage. A gzip archive is decompressed on the
+// fly; a zip archive needs random access and can only be downloaded.
+func (s *DecompressingStorage) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	archive := s.archive(key)
+	if archive == "" {
+		return s.inner.GetReader(ctx, key)
+	}
+	if !strings.HasSuffix(strings.ToLower(archive), ".gz") {
+		return nil, fmt.Errorf("%s is zipped; download it instead of streaming", archive)
+	}
+	rc, err := s.inner.GetReader(ctx, archive)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, fmt.Errorf("reading %s: %w", archive, err)
+	}
+	return gzipReadCloser{Reader: gz, archive: rc}, nil
+}
+
+// Exists implements ObjectStorage.
+func (s *DecompressingStorage) Exists(ctx context.Context, key string) (bool, error) {
+	if archive := s.archive(key); archive != "" {
+		return s.inner.Exists(ctx, archive)
+	}
+	return s.inner.Exists(ctx, key)
+}
+
+// gzipReadCloser closes both the gzip stream and the archive under it.
+type gzipReadCloser struct {
+	*gzip.Reader
+	archive io.Closer
+}
+
+func (g gzipReadCloser) Close() error {
+	_ = g.Reader.Close()
+	return g.archive.Close()
+}
+
+// gunzipFile decompresses the gzip file src into dest.
+func gunzipFile(src, dest string) error {
+	in, err := os.Open(filepath.Clean(src))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("reading gzip archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+	return writeUnpacked(gz, dest)
+}
+
+// unzipGeoPackage extracts the single .gpkg in the zip file src into dest.
+func unzipGeoPackage(src, dest string) error {
+	zr, err := zip.OpenReader(filepath.Clean(src))
+	if err != nil {
+		return fmt.Errorf("reading zip archive: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	var gpkg *zip.File
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(filepath.Ext(f.Name), filepath.Ext(dest)) {
+			continue
+		}
+		if gpkg != nil {
+			return fmt.Errorf("zip archive holds more than one GeoPackage (%s, %s)", gpkg.Name, f.Name)
+		}
+		gpkg = f
+	}
+	if gpkg == nil {
+		return fmt.Errorf("zip archive holds no GeoPackage")
+	}
+	rc, err := gpkg.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+	return writeUnpacked(rc, dest)
+}
+
+// maxUnpackedBytes caps an unpacked GeoPackage, so a decompression bomb
+// cannot fill the disk.
+const maxUnpackedBytes int64 = 64 << 30
+
+// writeUnpacked writes r to dest, removing a partial file on failure.
+func writeUnpacked(r io.Reader, dest string) error {
+	dest = filepath.Clean(dest)
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	n, err := io.CopyN(out, r, maxUnpackedBytes+1) // +1 so an over-limit archive is detectable
+	if err == nil && n > maxUnpackedBytes {
+		err = fmt.Errorf("larger than %d GiB", maxUnpackedBytes>>30)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		_ = out.Close()
+		_ = os.Remove(dest)
+		return fmt.Errorf("unpacking %s: %w", filepath.Base(dest), err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// memStorage serves objects from memory.
+type memStorage map[string][]byte
+
+func (m memStorage) List(context.Context) ([]output.StorageObject, error) {
+	objs := make([]output.StorageObject, 0, len(m))
+	for key, body := range m {
+		objs = append(objs, output.StorageObject{Key: key, Size: int64(len(body))})
+	}
+	sort.Slice(objs, func(i, j int) bool { return objs[i].Key < objs[j].Key })
+	return objs, nil
+}
+
+func (m memStorage) Download(_ context.Context, key, dest string) error {
+	body, ok := m[key]
+	if !ok {
+		return os.ErrNotExist
+	}
+	return os.WriteFile(dest, body, 0o600)
+}
+
+func (m memStorage) GetReader(_ context.Context, key string) (io.ReadCloser, error) {
+	body, ok := m[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(body)), nil
+}
+
+func (m memStorage) Exists(_ context.Context, key string) (bool, error) {
+	_, ok := m[key]
+	return ok, nil
+}
+
+func gzipped(t *testing.T, body string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompressingStorage(t *testing.T) {
+	inner := memStorage{
+		"eu/parcels.gpkg.gz": gzipped(t, "parcels-bytes"),
+		"roads.GPKG.zip":     zipped(t, map[string]string{"README.txt": "x", "data/roads.gpkg": "roads-bytes"}),
+		"rivers.gpkg":        []byte("plain-rivers"),
+		"rivers.gpkg.gz":     gzipped(t, "stale-rivers"),
+		"dem.zip":            []byte("raster-bundle"),
+		"broken.gpkg.zip":    zipped(t, map[string]string{"a.gpkg": "a", "b.gpkg": "b"}),
+	}
+	s := NewDecompressingStorage(inner)
+	ctx := context.Background()
+
+	objs, err := s.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make(map[string]bool)
+	for _, o := range objs {
+		keys[o.Key] = true
+	}
+	for _, want := range []string{"eu/parcels.gpkg", "roads.GPKG", "rivers.gpkg", "dem.zip", "broken.gpkg"} {
+		if !keys[want] {
+			t.Errorf("List misses %s: %v", want, keys)
+		}
+	}
+	if keys["eu/parcels.gpkg.gz"] || keys["rivers.gpkg.gz"] {
+		t.Errorf("List should hide the archives: %v", keys)
+	}
+
+	dir := t.TempDir()
+	for key, want := range map[string]string{
+		"eu/parcels.gpkg": "parcels-bytes",
+		"roads.GPKG":      "roads-bytes",
+		"rivers.gpkg":     "plain-rivers",
+	} {
+		dest := filepath.Join(dir, filepath.Base(key))
+		if err := s.Download(ctx, key, dest); err != nil {
+			t.Fatalf("Download(%s): %v", key, err)
+		}
+		if got, _ := os.ReadFile(dest); string(got) != want {
+			t.Errorf("Download(%s) = %q, want %q", key, got, want)
+		}
+		if _, err := os.Stat(dest + ".archive"); !os.IsNotExist(err) {
+			t.Errorf("Download(%s) left the archive behind", key)
+		}
+	}
+
+	if err := s.Download(ctx, "broken.gpkg", filepath.Join(dir, "broken.gpkg")); err == nil {
+		t.Error("a zip with two GeoPackages should fail")
+	}
+	if ok, _ := s.Exists(ctx, "eu/parcels.gpkg"); !ok {
+		t.Error("Exists should find the archive behind an unpacked key")
+	}
+
+	rc, err := s.GetReader(ctx, "eu/parcels.gpkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = rc.Close() }()
+	if got, _ := io.ReadAll(rc); string(got) != "parcels-bytes" {
+		t.Errorf("GetReader = %q", got)
+	}
+}
@@ -23,6 +23,14 @@ func NewErrorWrappingStorage(inner output.ObjectStorage) *ErrorWrappingStorage {
 	return &ErrorWrappingStorage{inner: inner}
 }
 
+// Storage operations as recorded in domain.StorageError.Operation.
+const (
+	opList      = "list"
+	opDownload  = "download"
+	opGetReader = "get_reader"
+	opExists    = "exists"
+)
+
 // wrapStorage normalizes err to a *domain.StorageError. nil stays nil; an error
 // that already is (or wraps) a *domain.StorageError passes through unchanged so
 // the operation/key set closest to the failure is preserved.
@@ -40,22 +48,27 @@ func wrapStorage(op, key string, err error) error {
 // List implements ObjectStorage.
 func (s *ErrorWrappingStorage) List(ctx context.Context) ([]output.StorageObject, error) {
 	objs, err := s.inner.List(ctx)
-	return objs, wrapStorage("list", "", err)
+	return objs, wrapStorage(opList, "", err)
 }
 
 // Download implements ObjectStorage.
 func (s *ErrorWrappingStorage) Download(ctx context.Context, key, dest string) error {
-	return wrapStorage("download", key, s.inner.Download(ctx, key, dest))
+	return wrapStorage(opDownload, key, s.inner.Download(ctx, key, dest))
+}
+
+// DownloadVersion implements VersionedStorage when the wrapped storage does.
+func (s *ErrorWrappingStorage) DownloadVersion(ctx context.Context, key, versionID, dest string) error {
+	return wrapStorage(opDownload, key, downloadVersion(ctx, s.inner, key, versionID, dest))
 }
 
 // GetReader implements ObjectStorage.
 func (s *ErrorWrappingStorage) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
 	r, err := s.inner.GetReader(ctx, key)
-	return r, wrapStorage("get_reader", key, err)
+	return r, wrapStorage(opGetReader, key, err)
 }
 
 // Exists implements ObjectStorage.
 func (s *ErrorWrappingStorage) Exists(ctx context.Context, key string) (bool, error) {
 	ok, err := s.inner.Exists(ctx, key)
-	return ok, wrapStorage("exists", key, err)
+	return ok, wrapStorage(opExists, key, err)
 }
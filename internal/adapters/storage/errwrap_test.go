@@ -36,6 +36,14 @@ func TestErrorWrappingStorage_WrapsErrors(t *testing.T) {
 	check("Exists", err, "exists")
 }
 
+func TestErrorWrappingStorage_DownloadVersionUnsupported(t *testing.T) {
+	s := NewErrorWrappingStorage(&fakeInner{})
+	err := s.DownloadVersion(context.Background(), "k", "v1", "/tmp/d")
+	if !errors.Is(err, domain.ErrUnsupported) {
+		t.Errorf("DownloadVersion on storage without versions = %v, want ErrUnsupported", err)
+	}
+}
+
 func TestErrorWrappingStorage_PassesThroughSuccessAndTypedErrors(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// ErrInjectedFault is the error FaultInjectingStorage fails a call with.
+var ErrInjectedFault = errors.New("injected storage fault")
+
+// FaultConfig configures FaultInjectingStorage.
+type FaultConfig struct {
+	ErrorRate float64       // share of calls that fail, 0 to 1
+	Latency   time.Duration // delay added before every call
+	Jitter    time.Duration // random extra delay, up to this much
+	// Operations limits the faults to these operations (list, download,
+	// get_reader, exists); empty means all of them.
+	Operations []string
+}
+
+// FaultInjectingStorage decorates an ObjectStorage with random failures and
+// delays, so sync, retry and degraded-source handling can be exercised
+// against a backend that misbehaves on purpose (storage.faults). It sits
+// directly on the backend, under ErrorWrappingStorage and tracing, so an
+// injected fault takes the path a real one would. Meant for test and staging
+// deployments only.
+type FaultInjectingStorage struct {
+	inner output.ObjectStorage
+	cfg   FaultConfig
+	ops   map[string]bool // nil: all operations
+	// roll returns a random number in [0, 1); replaced in tests.
+	roll func() float64
+}
+
+// NewFaultInjectingStorage wraps inner with the faults of cfg.
+func NewFaultInjectingStorage(inner output.ObjectStorage, cfg FaultConfig) *FaultInjectingStorage {
+	s := &FaultInjectingStorage{inner: inner, cfg: cfg, roll: randomFraction}
+	if len(cfg.Operations) > 0 {
+		s.ops = make(map[string]bool, len(cfg.Operations))
+		for _, op := range cfg.Operations {
+			s.ops[op] = true
+		}
+	}
+	return s
+}
+
+// inject delays the operation and then decides whether it fails. It returns
+// the context's error when the caller gives up during the delay.
+func (s *FaultInjectingStorage) inject(ctx context.Context, op, key string) error {
+	if s.ops != nil && !s.ops[op] {
+		return nil
+	}
+	delay := s.cfg.Latency
+	if s.cfg.Jitter > 0 {
+		delay += time.Duration(s.roll() * float64(s.cfg.Jitter))
+	}
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if s.cfg.ErrorRate > 0 && s.roll() < s.cfg.ErrorRate {
+		if key == "" {
+			return fmt.Errorf("%s: %w", op, ErrInjectedFault)
+		}
+		return fmt.Errorf("%s %s: %w", op, key, ErrInjectedFault)
+	}
+	return nil
+}
+
+// List implements ObjectStorage.
+func (s *FaultInjectingStorage) List(ctx context.Context) ([]output.StorageObject, error) {
+	if err := s.inject(ctx, opList, ""); err != nil {
+		return nil, err
+	}
+	return s.inner.List(ctx)
+}
+
+// Download implements ObjectStorage.
+func (s *FaultInjectingStorage) Download(ctx context.Context, key, dest string) error {
+	if err := s.inject(ctx, opDownload, key); err != nil {
+		return err
+	}
+	return s.inner.Download(ctx, key, dest)
+}
+
+// DownloadVersion implements VersionedStorage when the wrapped storage does.
+func (s *FaultInjectingStorage) DownloadVersion(ctx context.Context, key, versionID, dest string) error {
+	if err := s.inject(ctx, opDownload, key); err != nil {
+		return err
+	}
+	return downloadVersion(ctx, s.inner, key, versionID, dest)
+}
+
+// GetReader implements ObjectStorage.
+func (s *FaultInjectingStorage) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := s.inject(ctx, opGetReader, key); err != nil {
+		return nil, err
+	}
+	return s.inner.GetReader(ctx, key)
+}
+
+// Exists implements ObjectStorage.
+func (s *FaultInjectingStorage) Exists(ctx context.Context, key string) (bool, error) {
+	if err := s.inject(ctx, opExists, key); err != nil {
+		return false, err
+	}
+	return s.inner.Exists(ctx, key)
+}
+
+// randomFraction returns a uniformly distributed number in [0, 1).
+func randomFraction() float64 {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return float64(binary.BigEndian.Uint64(b[:])>>11) / (1 << 53)
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// fixedRoll makes a FaultInjectingStorage roll the same number every time.
+func fixedRoll(s *FaultInjectingStorage, v float64) *FaultInjectingStorage {
+	s.roll = func() float64 { return v }
+	return s
+}
+
+func TestFaultInjectingStorage_ErrorRate(t *testing.T) {
+	inner := &fakeInner{objs: []output.StorageObject{{Key: "a.gpkg"}}}
+	ctx := context.Background()
+
+	failing := fixedRoll(NewFaultInjectingStorage(inner, FaultConfig{ErrorRate: 0.5}), 0.49)
+	if _, err := failing.List(ctx); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("List below the error rate err = %v, want ErrInjectedFault", err)
+	}
+	if err := failing.Download(ctx, "a.gpkg", t.TempDir()+"/a.gpkg"); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("Download below the error rate err = %v, want ErrInjectedFault", err)
+	}
+
+	passing := fixedRoll(NewFaultInjectingStorage(inner, FaultConfig{ErrorRate: 0.5}), 0.5)
+	if objs, err := passing.List(ctx); err != nil || len(objs) != 1 {
+		t.Errorf("List at the error rate = %v, %v; want the inner listing", objs, err)
+	}
+	if ok, err := passing.Exists(ctx, "a.gpkg"); err != nil || !ok {
+		t.Errorf("Exists at the error rate = %v, %v; want true, nil", ok, err)
+	}
+}
+
+// TestFaultInjectingStorage_Operations: faults hit only the listed operations.
+func TestFaultInjectingStorage_Operations(t *testing.T) {
+	s := fixedRoll(NewFaultInjectingStorage(&fakeInner{}, FaultConfig{ErrorRate: 1, Operations: []string{opDownload}}), 0)
+	ctx := context.Background()
+	if err := s.Download(ctx, "a.gpkg", "/tmp/a.gpkg"); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("Download err = %v, want ErrInjectedFault", err)
+	}
+	if _, err := s.List(ctx); err != nil {
+		t.Errorf("List err = %v, want no fault outside operations", err)
+	}
+	r, err := s.GetReader(ctx, "a.gpkg")
+	if err != nil {
+		t.Fatalf("GetReader err = %v, want no fault outside operations", err)
+	}
+	_ = r.Close()
+}
+
+func TestFaultInjectingStorage_Latency(t *testing.T) {
+	s := fixedRoll(NewFaultInjectingStorage(&fakeInner{}, FaultConfig{Latency: 20 * time.Millisecond, Jitter: 20 * time.Millisecond}), 0.5)
+	start := time.Now()
+	if _, err := s.Exists(context.Background(), "a.gpkg"); err != nil {
+		t.Fatalf("Exists: %v", err)
+	}
+	if took := time.Since(start); took < 30*time.Millisecond {
+		t.Errorf("Exists took %v, want at least latency plus half the jitter (30ms)", took)
+	}
+
+	slow := NewFaultInjectingStorage(&fakeInner{}, FaultConfig{Latency: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := slow.List(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("List with a canceled context err = %v, want context.Canceled", err)
+	}
+}
+
+// TestFaultInjectingStorage_SurfacesAsStorageError: wrapped the way the app
+// stacks the decorators, an injected fault reaches callers as the
+// *domain.StorageError a real backend failure would be.
+func TestFaultInjectingStorage_SurfacesAsStorageError(t *testing.T) {
+	s := NewErrorWrappingStorage(fixedRoll(NewFaultInjectingStorage(&fakeInner{}, FaultConfig{ErrorRate: 1}), 0))
+	_, err := s.List(context.Background())
+	var se *domain.StorageError
+	if !errors.As(err, &se) || se.Operation != opList {
+		t.Fatalf("err = %v, want a *domain.StorageError for list", err)
+	}
+	if !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("err = %v, want it to wrap ErrInjectedFault", err)
+	}
+}
+
+func TestRandomFraction(t *testing.T) {
+	for range 1000 {
+		if v := randomFraction(); v < 0 || v >= 1 {
+			t.Fatalf("randomFraction() = %v, want [0, 1)", v)
+		}
+	}
+}
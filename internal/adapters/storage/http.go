@@ -1,14 +1,16 @@
 package storage
 
 import (
-	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -24,15 +26,22 @@ type HTTPStorage struct {
 	indexFile string
 	username  string
 	password  string
+	autoindex bool
+
+	mu        sync.Mutex
+	checksums map[string]string // key -> SHA-256 from the last listed index
 }
 
 // HTTPConfig holds HTTP storage configuration.
 type HTTPConfig struct {
 	BaseURL   string
-	IndexFile string // default: index.txt
+	IndexFile string // default: index.txt; .json and .csv select a manifest format
 	Timeout   time.Duration
 	Username  string
 	Password  string
+	// Autoindex lists the files from the server's directory listing
+	// (Apache/Nginx HTML or S3 XML) instead of the index file.
+	Autoindex bool
 }
 
 // NewHTTPStorage creates a new HTTP storage adapter. The http.Client is
@@ -56,11 +65,53 @@ func NewHTTPStorage(cfg HTTPConfig) *HTTPStorage {
 		indexFile: cfg.IndexFile,
 		username:  cfg.Username,
 		password:  cfg.Password,
+		autoindex: cfg.Autoindex,
 	}
 }
 
-// List returns all GeoPackage files listed in the index file.
+// List returns all supported source files listed in the index file: plain
+// text, or a JSON or CSV manifest that also gives size, SHA-256 and last
+// modification time. In autoindex mode the files come from the server's
+// directory listing instead.
 func (s *HTTPStorage) List(ctx context.Context) ([]output.StorageObject, error) {
+	readIndex := s.readIndex
+	if s.autoindex {
+		readIndex = s.listAutoindex
+	}
+	entries, err := readIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []output.StorageObject
+	checksums := make(map[string]string)
+	for _, e := range entries {
+		// Only include sources: GeoPackages (possibly compressed) and raster
+		// bundles.
+		if e.Filename == "" || !domain.IsStoredSourceFile(e.Filename) {
+			continue
+		}
+		sum := strings.ToLower(e.SHA256)
+		if sum != "" {
+			checksums[e.Filename] = sum
+		}
+		objects = append(objects, output.StorageObject{
+			Key:          e.Filename,
+			Size:         e.Size,
+			LastModified: e.modTime(),
+			ETag:         sum,
+		})
+	}
+
+	s.mu.Lock()
+	s.checksums = checksums
+	s.mu.Unlock()
+
+	return objects, nil
+}
+
+// readIndex fetches and parses the index file.
+func (s *HTTPStorage) readIndex(ctx context.Context) ([]indexEntry, error) {
 	indexURL := s.baseURL + "/" + s.indexFile
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, nil)
@@ -82,32 +133,18 @@ func (s *HTTPStorage) List(ctx context.Context) ([]output.StorageObject, error)
 		return nil, fmt.Errorf("index file returned status %d", resp.StatusCode)
 	}
 
-	// Parse index file (one filename per line)
-	var objects []output.StorageObject
-	scanner := bufio.NewScanner(resp.Body)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Only include loadable sources: GeoPackages and raster bundles.
-		if !domain.IsSupportedSourceFile(line) {
-			continue
-		}
-
-		objects = append(objects, output.StorageObject{
-			Key: line,
-		})
-	}
-
-	if err := scanner.Err(); err != nil {
+	entries, err := parseIndex(resp.Body, indexFormat(s.indexFile, resp.Header.Get("Content-Type")))
+	if err != nil {
 		return nil, fmt.Errorf("reading index file: %w", err)
 	}
+	return entries, nil
+}
 
-	return objects, nil
+// checksum returns the SHA-256 the last listed index gave for key, or "".
+func (s *HTTPStorage) checksum(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checksums[key]
 }
 
 // Download downloads a file from HTTP to the local filesystem.
@@ -145,8 +182,24 @@ func (s *HTTPStorage) Download(ctx context.Context, key string, dest string) err
 	}
 	defer func() { _ = f.Close() }()
 
-	_, err = io.Copy(f, resp.Body)
-	return err
+	want := s.checksum(key)
+	if want == "" {
+		_, err = io.Copy(f, resp.Body)
+		return err
+	}
+
+	// The index carries a SHA-256: verify the download against it, and do
+	// not leave a corrupt file behind for the loader.
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		_ = f.Close()
+		_ = os.Remove(dest)
+		return fmt.Errorf("checksum mismatch for %s: index has sha256 %s, download has %s", key, want, got)
+	}
+	return nil
 }
 
 // GetReader returns a reader for the given file.
@@ -2,10 +2,13 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -163,3 +166,150 @@ func TestNewHTTPStorageDefaults(t *testing.T) {
 		t.Error("default timeout should be set")
 	}
 }
+
+// newManifestServer serves index (under indexPath) and regions.gpkg.
+func newManifestServer(t *testing.T, indexPath, contentType, index string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + indexPath:
+			if contentType != "" {
+				w.Header().Set("Content-Type", contentType)
+			}
+			_, _ = w.Write([]byte(index))
+		case "/regions.gpkg":
+			_, _ = w.Write([]byte("fake-geopackage-bytes"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestHTTPStorageListManifests(t *testing.T) {
+	const sum = "4F1A5A3B9C0D8E7F6A5B4C3D2E1F0A9B8C7D6E5F4A3B2C1D0E9F8A7B6C5D4E3F"
+	tests := []struct {
+		name, indexFile, contentType, index string
+	}{
+		{"json array", "index.json", "", `[
+			{"filename": "regions.gpkg", "size": 21, "sha256": "` + sum + `", "last_modified": "2025-12-22T12:00:00Z"},
+			{"filename": "readme.txt"}]`},
+		{"json object", "manifest", "application/json; charset=utf-8",
+			`{"files": [{"filename": "regions.gpkg", "size": 21, "sha256": "` + sum + `", "last_modified": "1766404800"}]}`},
+		{"csv", "index.csv", "", "# published nightly\nsize,filename,last-modified,sha256\n21,regions.gpkg,2025-12-22T12:00:00Z," + sum + "\n3,readme.txt,,\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := newManifestServer(t, tt.indexFile, tt.contentType, tt.index)
+			s := NewHTTPStorage(HTTPConfig{BaseURL: url, IndexFile: tt.indexFile})
+			objs, err := s.List(context.Background())
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(objs) != 1 {
+				t.Fatalf("List = %+v, want only regions.gpkg", objs)
+			}
+			o := objs[0]
+			if o.Key != "regions.gpkg" || o.Size != 21 || o.LastModified != 1766404800 || o.ETag != strings.ToLower(sum) {
+				t.Errorf("object = %+v", o)
+			}
+		})
+	}
+}
+
+func TestHTTPStorageListCSVWithoutFilename(t *testing.T) {
+	url := newManifestServer(t, "index.csv", "", "name,size\nregions.gpkg,21\n")
+	s := NewHTTPStorage(HTTPConfig{BaseURL: url, IndexFile: "index.csv"})
+	if _, err := s.List(context.Background()); err == nil {
+		t.Error("List should reject a CSV index without a filename column")
+	}
+}
+
+func TestHTTPStorageDownloadVerifiesChecksum(t *testing.T) {
+	good := sha256.Sum256([]byte("fake-geopackage-bytes"))
+	for _, tt := range []struct {
+		name    string
+		sum     string
+		wantErr bool
+	}{
+		{"match", hex.EncodeToString(good[:]), false},
+		{"mismatch", strings.Repeat("0", 64), true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			url := newManifestServer(t, "index.json", "", `[{"filename": "regions.gpkg", "sha256": "`+tt.sum+`"}]`)
+			s := NewHTTPStorage(HTTPConfig{BaseURL: url, IndexFile: "index.json"})
+			if _, err := s.List(context.Background()); err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			dest := filepath.Join(t.TempDir(), "regions.gpkg")
+			err := s.Download(context.Background(), "regions.gpkg", dest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Download error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, statErr := os.Stat(dest); tt.wantErr != os.IsNotExist(statErr) {
+				t.Errorf("dest exists = %v after %s", statErr == nil, tt.name)
+			}
+		})
+	}
+}
+
+func TestHTTPStorageAutoindexHTML(t *testing.T) {
+	pages := map[string]string{
+		"/gpkg/": `<html><body><h1>Index of /gpkg</h1>
+			<a href="?C=N;O=D">Name</a> <a href="/">Parent Directory</a> <a href="../">up</a>
+			<a href="regions.gpkg">regions.gpkg</a> <a href="notes.txt">notes.txt</a>
+			<a href="https://elsewhere.example/x.gpkg">mirror</a>
+			<a HREF='sub%20dir/'>sub dir/</a></body></html>`,
+		"/gpkg/sub dir/": `<a href="../">../</a><a href="area.gpkg">area.gpkg</a><a href="/gpkg/regions.gpkg">dup</a>`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if page, ok := pages[r.URL.Path]; ok {
+			_, _ = w.Write([]byte(page))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	s := NewHTTPStorage(HTTPConfig{BaseURL: srv.URL + "/gpkg", Autoindex: true})
+	objs, err := s.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	got := make([]string, 0, len(objs))
+	for _, o := range objs {
+		got = append(got, o.Key)
+	}
+	if strings.Join(got, ",") != "regions.gpkg,sub dir/area.gpkg" {
+		t.Errorf("keys = %v, want [regions.gpkg sub dir/area.gpkg]", got)
+	}
+}
+
+func TestHTTPStorageAutoindexS3(t *testing.T) {
+	pages := map[string]string{
+		"": `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>geodata</Name><IsTruncated>true</IsTruncated>
+  <Contents><Key>a/regions.gpkg</Key><Size>21</Size><LastModified>2025-12-22T12:00:00.000Z</LastModified></Contents>
+  <Contents><Key>a/readme.txt</Key><Size>3</Size></Contents>
+</ListBucketResult>`,
+		"a/readme.txt": `<ListBucketResult><IsTruncated>false</IsTruncated>
+  <Contents><Key>b/area.gpkg</Key><Size>7</Size></Contents></ListBucketResult>`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(pages[r.URL.Query().Get("marker")]))
+	}))
+	t.Cleanup(srv.Close)
+
+	s := NewHTTPStorage(HTTPConfig{BaseURL: srv.URL, Autoindex: true})
+	objs, err := s.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objs) != 2 || objs[0].Key != "a/regions.gpkg" || objs[0].Size != 21 || objs[0].LastModified != 1766404800 ||
+		objs[1].Key != "b/area.gpkg" {
+		t.Errorf("List = %+v", objs)
+	}
+}
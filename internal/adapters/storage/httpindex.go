@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Index manifest formats understood by HTTPStorage.List.
+const (
+	indexFormatText = "text" // one filename per line, "#" comments
+	indexFormatJSON = "json" // array of entries, or {"files": [...]}
+	indexFormatCSV  = "csv"  // header row naming the columns
+)
+
+// indexEntry is one file listed in an index manifest. Only Filename is
+// required; the other fields are empty when the manifest does not carry them.
+type indexEntry struct {
+	Filename     string `json:"filename"`
+	Size         int64  `json:"size"`
+	SHA256       string `json:"sha256"`
+	LastModified string `json:"last_modified"` // RFC 3339 or Unix seconds
+}
+
+// indexFormat picks the manifest format from the index file extension,
+// falling back to the response Content-Type.
+func indexFormat(indexFile, contentType string) string {
+	switch strings.ToLower(path.Ext(indexFile)) {
+	case ".json":
+		return indexFormatJSON
+	case ".csv":
+		return indexFormatCSV
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		return indexFormatJSON
+	case "text/csv":
+		return indexFormatCSV
+	}
+	return indexFormatText
+}
+
+// parseIndex reads an index manifest in the given format.
+func parseIndex(r io.Reader, format string) ([]indexEntry, error) {
+	switch format {
+	case indexFormatJSON:
+		return parseJSONIndex(r)
+	case indexFormatCSV:
+		return parseCSVIndex(r)
+	default:
+		return parseTextIndex(r)
+	}
+}
+
+func parseTextIndex(r io.Reader) ([]indexEntry, error) {
+	var entries []indexEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, indexEntry{Filename: line})
+	}
+	return entries, scanner.Err()
+}
+
+func parseJSONIndex(r io.Reader) ([]indexEntry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var entries []indexEntry
+	if err := json.Unmarshal(data, &entries); err == nil {
+		return entries, nil
+	}
+	var wrapped struct {
+		Files []indexEntry `json:"files"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("parsing JSON index: %w", err)
+	}
+	return wrapped.Files, nil
+}
+
+// parseCSVIndex reads a CSV manifest whose header row names the columns
+// filename, size, sha256 and last_modified (or last-modified), in any order.
+// Unknown columns are ignored.
+func parseCSVIndex(r io.Reader) ([]indexEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV index header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")] = i
+	}
+	if _, ok := cols["filename"]; !ok {
+		return nil, fmt.Errorf("CSV index has no filename column")
+	}
+
+	var entries []indexEntry
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV index: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		e := indexEntry{Filename: field("filename"), SHA256: field("sha256"), LastModified: field("last_modified")}
+		if size := field("size"); size != "" {
+			if e.Size, err = strconv.ParseInt(size, 10, 64); err != nil {
+				return nil, fmt.Errorf("CSV index: invalid size %q for %s", size, e.Filename)
+			}
+		}
+		entries = append(entries, e)
+	}
+}
+
+// modTime parses LastModified as RFC 3339 or Unix seconds; 0 when empty or
+// unparseable.
+func (e indexEntry) modTime() int64 {
+	if e.LastModified == "" {
+		return 0
+	}
+	if t, err := time.Parse(time.RFC3339, e.LastModified); err == nil {
+		return t.Unix()
+	}
+	if secs, err := strconv.ParseInt(e.LastModified, 10, 64); err == nil {
+		return secs
+	}
+	return 0
+}
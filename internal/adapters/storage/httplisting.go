@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// autoindexMaxDepth bounds how many subdirectory levels the autoindex crawl
+// follows below base_url.
+const autoindexMaxDepth = 8
+
+// hrefPattern finds link targets in an Apache/Nginx directory listing.
+var hrefPattern = regexp.MustCompile(`(?i)<a\s[^>]*href\s*=\s*["']([^"']+)["']`)
+
+// s3Listing is an S3-compatible ListObjects (v1) response.
+type s3Listing struct {
+	XMLName     xml.Name `xml:"ListBucketResult"`
+	Prefix      string   `xml:"Prefix"`
+	IsTruncated bool     `xml:"IsTruncated"`
+	NextMarker  string   `xml:"NextMarker"`
+	Contents    []struct {
+		Key          string `xml:"Key"`
+		Size         int64  `xml:"Size"`
+		LastModified string `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+// listAutoindex discovers source files from the server's directory listing
+// instead of an index file: an Apache/Nginx HTML autoindex, crawled into
+// subdirectories, or an S3-compatible XML bucket listing at base_url.
+func (s *HTTPStorage) listAutoindex(ctx context.Context) ([]indexEntry, error) {
+	body, err := s.fetchListing(ctx, s.baseURL+"/")
+	if err != nil {
+		return nil, err
+	}
+	if isS3Listing(body) {
+		return s.listS3(ctx, body)
+	}
+	return s.crawl(ctx, "", body, 0, map[string]bool{"": true})
+}
+
+// crawl collects the source files linked from the listing body of dir (a
+// path below base_url ending in "/", or "") and descends into the linked
+// subdirectories.
+func (s *HTTPStorage) crawl(ctx context.Context, dir string, body []byte, depth int, seen map[string]bool) ([]indexEntry, error) {
+	var entries []indexEntry
+	for _, m := range hrefPattern.FindAllSubmatch(body, -1) {
+		rel, ok := s.listingPath(dir, string(m[1]))
+		if !ok || seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		if !strings.HasSuffix(rel, "/") {
+			if domain.IsStoredSourceFile(rel) {
+				entries = append(entries, indexEntry{Filename: rel})
+			}
+			continue
+		}
+		if depth+1 > autoindexMaxDepth {
+			continue
+		}
+		sub, err := s.fetchListing(ctx, s.baseURL+"/"+escapePath(rel))
+		if err != nil {
+			return nil, err
+		}
+		found, err := s.crawl(ctx, rel, sub, depth+1, seen)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, found...)
+	}
+	return entries, nil
+}
+
+// listingPath resolves a listing link against dir and returns it relative to
+// base_url. Links that leave base_url (parent directory, other hosts) and
+// sort links like "?C=N;O=D" are dropped.
+func (s *HTTPStorage) listingPath(dir, href string) (string, bool) {
+	ref, err := url.Parse(href)
+	if err != nil || ref.RawQuery != "" || (ref.Path == "" && ref.Host == "") {
+		return "", false
+	}
+	base, err := url.Parse(s.baseURL + "/")
+	if err != nil {
+		return "", false
+	}
+	target := base.ResolveReference(&url.URL{Path: dir}).ResolveReference(ref)
+	if target.Host != base.Host {
+		return "", false
+	}
+	rel, ok := strings.CutPrefix(target.Path, base.Path)
+	if !ok || rel == "" {
+		return "", false
+	}
+	return rel, true
+}
+
+// listS3 reads an S3 bucket listing, following truncated pages by marker.
+func (s *HTTPStorage) listS3(ctx context.Context, body []byte) ([]indexEntry, error) {
+	var entries []indexEntry
+	for {
+		var page s3Listing
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("parsing bucket listing: %w", err)
+		}
+		for _, c := range page.Contents {
+			if domain.IsStoredSourceFile(c.Key) {
+				entries = append(entries, indexEntry{Filename: c.Key, Size: c.Size, LastModified: c.LastModified})
+			}
+		}
+		if !page.IsTruncated || len(page.Contents) == 0 {
+			return entries, nil
+		}
+		marker := page.NextMarker
+		if marker == "" {
+			marker = page.Contents[len(page.Contents)-1].Key
+		}
+		next, err := s.fetchListing(ctx, s.baseURL+"/?marker="+url.QueryEscape(marker))
+		if err != nil {
+			return nil, err
+		}
+		body = next
+	}
+}
+
+// fetchListing GETs a directory listing and returns the body of a 200
+// response.
+func (s *HTTPStorage) fetchListing(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.username != "" && s.password != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching directory listing: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("directory listing %s returned status %d", rawURL, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// isS3Listing reports whether body is an S3 ListBucketResult document.
+func isS3Listing(body []byte) bool {
+	head := body[:min(len(body), 512)]
+	return bytes.Contains(head, []byte("<ListBucketResult"))
+}
+
+// escapePath percent-encodes each segment of a slash-separated path.
+func escapePath(p string) string {
+	segs := strings.Split(p, "/")
+	for i, seg := range segs {
+		segs[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segs, "/")
+}
@@ -38,41 +38,90 @@ func safeJoin(base, key string) (string, error) {
 	return joined, nil
 }
 
-// LocalStorage implements ObjectStorage for local filesystem.
+// LocalStorage implements ObjectStorage for the local filesystem. It serves
+// one or more directories; with several, every key starts with the base name
+// of its directory ("staging/parcels.gpkg"), so equal relative paths in
+// different directories stay apart.
 type LocalStorage struct {
-	basePath string
+	roots     []localRoot
+	recursive bool
+	exclude   []string
 }
 
-// NewLocalStorage creates a new local storage adapter.
+// localRoot is one served directory.
+type localRoot struct {
+	dir    string
+	prefix string // key prefix; "" when it is the only directory
+}
+
+// LocalConfig holds local storage configuration.
+type LocalConfig struct {
+	Paths     []string // directories; base names must differ when there are several
+	Recursive bool     // list files in subdirectories too
+	Exclude   []string // domain.MatchKeyGlob patterns; matching files and directories are skipped
+}
+
+// NewLocalStorage creates a new local storage adapter for basePath and its
+// subdirectories.
 func NewLocalStorage(basePath string) *LocalStorage {
-	return &LocalStorage{basePath: basePath}
+	return NewLocalStorageWithConfig(LocalConfig{Paths: []string{basePath}, Recursive: true})
+}
+
+// NewLocalStorageWithConfig creates a local storage adapter serving cfg.Paths.
+func NewLocalStorageWithConfig(cfg LocalConfig) *LocalStorage {
+	s := &LocalStorage{recursive: cfg.Recursive, exclude: cfg.Exclude}
+	for _, dir := range cfg.Paths {
+		root := localRoot{dir: dir}
+		if len(cfg.Paths) > 1 {
+			root.prefix = filepath.Base(filepath.Clean(dir))
+		}
+		s.roots = append(s.roots, root)
+	}
+	return s
 }
 
-// List returns all GeoPackage files in the local directory.
+// List returns all loadable source files in the served directories.
 func (s *LocalStorage) List(_ context.Context) ([]output.StorageObject, error) {
 	var objects []output.StorageObject
+	for _, root := range s.roots {
+		found, err := s.listRoot(root)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, found...)
+	}
+	return objects, nil
+}
+
+// listRoot walks one directory, honoring the recursion and exclude settings.
+func (s *LocalStorage) listRoot(root localRoot) ([]output.StorageObject, error) {
+	var objects []output.StorageObject
 
-	err := filepath.Walk(s.basePath, func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(root.dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
+		relPath, err := filepath.Rel(root.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.Join(root.prefix, relPath)
+
 		if info.IsDir() {
+			if relPath != "." && (!s.recursive || s.Excluded(key)) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
 		// Only include loadable sources: GeoPackages and raster bundles.
-		if !domain.IsSupportedSourceFile(info.Name()) {
+		if !domain.IsSupportedSourceFile(info.Name()) || s.Excluded(key) {
 			return nil
 		}
 
-		relPath, err := filepath.Rel(s.basePath, path)
-		if err != nil {
-			return err
-		}
-
 		objects = append(objects, output.StorageObject{
-			Key:          relPath,
+			Key:          key,
 			Size:         info.Size(),
 			LastModified: info.ModTime().Unix(),
 		})
@@ -87,9 +136,63 @@ func (s *LocalStorage) List(_ context.Context) ([]output.StorageObject, error) {
 	return objects, nil
 }
 
+// Excluded reports whether key matches one of the exclude patterns.
+func (s *LocalStorage) Excluded(key string) bool {
+	for _, pattern := range s.exclude {
+		if domain.MatchKeyGlob(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalPath returns the file a key refers to, rejecting keys that would
+// escape their directory. The registry loads local objects in place.
+func (s *LocalStorage) LocalPath(key string) (string, error) {
+	root, rel, err := s.rootFor(key)
+	if err != nil {
+		return "", err
+	}
+	return safeJoin(root.dir, rel)
+}
+
+// ObjectKey maps a file path back to its key; false when the path lies
+// outside every served directory. Absolute paths (as the file watcher
+// reports them) match relative directories too.
+func (s *LocalStorage) ObjectKey(path string) (string, bool) {
+	for _, root := range s.roots {
+		dir := root.dir
+		if filepath.IsAbs(path) && !filepath.IsAbs(dir) {
+			if abs, err := filepath.Abs(dir); err == nil {
+				dir = abs
+			}
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			continue
+		}
+		return filepath.Join(root.prefix, rel), true
+	}
+	return "", false
+}
+
+// rootFor splits key into its directory and the path within it.
+func (s *LocalStorage) rootFor(key string) (localRoot, string, error) {
+	if len(s.roots) == 1 {
+		return s.roots[0], key, nil
+	}
+	prefix, rel, _ := strings.Cut(filepath.Clean(key), string(os.PathSeparator))
+	for _, root := range s.roots {
+		if root.prefix == prefix {
+			return root, rel, nil
+		}
+	}
+	return localRoot{}, "", fmt.Errorf("key %q names no served directory", key)
+}
+
 // Download copies a file to the destination (no-op for local storage).
 func (s *LocalStorage) Download(_ context.Context, key string, dest string) error {
-	srcPath, err := safeJoin(s.basePath, key)
+	srcPath, err := s.LocalPath(key)
 	if err != nil {
 		return err
 	}
@@ -105,7 +208,7 @@ func (s *LocalStorage) Download(_ context.Context, key string, dest string) erro
 	}
 
 	// Copy file
-	src, err := os.Open(srcPath) //#nosec G304 -- srcPath validated by safeJoin (cannot escape basePath)
+	src, err := os.Open(srcPath) //#nosec G304 -- srcPath validated by safeJoin (cannot escape its directory)
 	if err != nil {
 		return err
 	}
@@ -123,16 +226,20 @@ func (s *LocalStorage) Download(_ context.Context, key string, dest string) erro
 
 // GetReader returns a reader for the given object.
 func (s *LocalStorage) GetReader(_ context.Context, key string) (io.ReadCloser, error) {
-	path, err := safeJoin(s.basePath, key)
+	path, err := s.LocalPath(key)
 	if err != nil {
 		return nil, err
 	}
-	return os.Open(path) //#nosec G304 -- path validated by safeJoin (cannot escape basePath)
+	return os.Open(path) //#nosec G304 -- path validated by safeJoin (cannot escape its directory)
 }
 
 // Exists checks if a file exists.
 func (s *LocalStorage) Exists(_ context.Context, key string) (bool, error) {
-	_, err := os.Stat(filepath.Join(s.basePath, key))
+	root, rel, err := s.rootFor(key)
+	if err != nil {
+		return false, nil
+	}
+	_, err = os.Stat(filepath.Join(root.dir, rel))
 	if err == nil {
 		return true, nil
 	}
@@ -142,7 +249,12 @@ func (s *LocalStorage) Exists(_ context.Context, key string) (bool, error) {
 	return false, err
 }
 
-// FullPath returns the full path for a key.
+// FullPath returns the full path for a key ("" for a key that names no
+// served directory).
 func (s *LocalStorage) FullPath(key string) string {
-	return filepath.Join(s.basePath, key)
+	root, rel, err := s.rootFor(key)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(root.dir, rel)
 }
@@ -14,8 +14,8 @@ func TestNewLocalStorage(t *testing.T) {
 		t.Fatal("NewLocalStorage() returned nil")
 	}
 
-	if storage.basePath != "/tmp/test" {
-		t.Errorf("basePath = %q, want %q", storage.basePath, "/tmp/test")
+	if len(storage.roots) != 1 || storage.roots[0].dir != "/tmp/test" || !storage.recursive {
+		t.Errorf("roots = %+v, recursive = %v; want /tmp/test, recursive", storage.roots, storage.recursive)
 	}
 }
 
@@ -308,3 +308,78 @@ func TestLocalStorageFullPath(t *testing.T) {
 		})
 	}
 }
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func listKeys(t *testing.T, s *LocalStorage) map[string]bool {
+	t.Helper()
+	objects, err := s.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	keys := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		keys[obj.Key] = true
+	}
+	return keys
+}
+
+func TestLocalStorageListNonRecursiveWithExclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a.gpkg", "b.tmp.gpkg", "sub/c.gpkg")
+
+	flat := NewLocalStorageWithConfig(LocalConfig{Paths: []string{dir}, Exclude: []string{"*.tmp.gpkg"}})
+	if keys := listKeys(t, flat); len(keys) != 1 || !keys["a.gpkg"] {
+		t.Errorf("non-recursive keys = %v, want only a.gpkg", keys)
+	}
+
+	writeFiles(t, dir, "eu/tmp/d.gpkg", "eu/e.gpkg")
+	deep := NewLocalStorageWithConfig(LocalConfig{Paths: []string{dir}, Recursive: true, Exclude: []string{"**/tmp/**"}})
+	keys := listKeys(t, deep)
+	if len(keys) != 4 || keys[filepath.Join("eu", "tmp", "d.gpkg")] {
+		t.Errorf("recursive keys = %v, want all but eu/tmp/d.gpkg", keys)
+	}
+}
+
+func TestLocalStorageSeveralDirectories(t *testing.T) {
+	base := t.TempDir()
+	staging, production := filepath.Join(base, "staging"), filepath.Join(base, "production")
+	writeFiles(t, staging, "parcels.gpkg")
+	writeFiles(t, production, "parcels.gpkg", "roads.gpkg")
+
+	s := NewLocalStorageWithConfig(LocalConfig{Paths: []string{staging, production}, Recursive: true})
+	keys := listKeys(t, s)
+	for _, want := range []string{"staging/parcels.gpkg", "production/parcels.gpkg", "production/roads.gpkg"} {
+		if !keys[filepath.FromSlash(want)] {
+			t.Errorf("keys = %v, missing %s", keys, want)
+		}
+	}
+
+	path, err := s.LocalPath(filepath.Join("production", "roads.gpkg"))
+	if err != nil || path != filepath.Join(production, "roads.gpkg") {
+		t.Errorf("LocalPath = %q, %v", path, err)
+	}
+	if key, ok := s.ObjectKey(filepath.Join(staging, "parcels.gpkg")); !ok || key != filepath.Join("staging", "parcels.gpkg") {
+		t.Errorf("ObjectKey = %q, %v", key, ok)
+	}
+	if _, err := s.LocalPath("archive/parcels.gpkg"); err == nil {
+		t.Error("LocalPath accepted a key for an unknown directory")
+	}
+	if _, err := s.LocalPath(filepath.Join("staging", "..", "..", "etc", "passwd")); err == nil {
+		t.Error("LocalPath accepted a key escaping its directory")
+	}
+	if ok, err := s.Exists(context.Background(), filepath.Join("staging", "parcels.gpkg")); !ok || err != nil {
+		t.Errorf("Exists = %v, %v", ok, err)
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
 
 	"github.com/jobrunner/ortus/internal/domain"
@@ -32,6 +35,14 @@ type S3Config struct {
 	Endpoint        string
 	AccessKeyID     string
 	SecretAccessKey string
+	SessionToken    string
+
+	// RoleARN, when set, is assumed through STS on top of the base
+	// credentials (the keys above or the SDK default chain).
+	RoleARN         string
+	ExternalID      string
+	RoleSessionName string
+	RoleDuration    time.Duration
 }
 
 // NewS3Storage creates a new S3 storage adapter.
@@ -40,13 +51,14 @@ func NewS3Storage(ctx context.Context, cfg S3Config) (*S3Storage, error) {
 
 	opts = append(opts, config.WithRegion(cfg.Region))
 
-	// Use explicit credentials if provided
+	// Use explicit credentials if provided; otherwise the default chain
+	// (environment, shared config, IRSA web identity, ECS/EC2 roles) applies.
 	if cfg.AccessKeyID != "" && cfg.SecretAccessKey != "" {
 		opts = append(opts, config.WithCredentialsProvider(
 			credentials.NewStaticCredentialsProvider(
 				cfg.AccessKeyID,
 				cfg.SecretAccessKey,
-				"",
+				cfg.SessionToken,
 			),
 		))
 	}
@@ -55,6 +67,9 @@ func NewS3Storage(ctx context.Context, cfg S3Config) (*S3Storage, error) {
 	if err != nil {
 		return nil, err
 	}
+	if cfg.RoleARN != "" {
+		awsCfg.Credentials = aws.NewCredentialsCache(assumeRoleProvider(awsCfg, cfg))
+	}
 
 	// Wire OTel AWS-SDK middleware. Each S3 API call (GetObject, ListObjectsV2,
 	// HeadObject, ...) becomes its own child span with attempt-number, status,
@@ -79,6 +94,21 @@ func NewS3Storage(ctx context.Context, cfg S3Config) (*S3Storage, error) {
 	}, nil
 }
 
+// assumeRoleProvider returns credentials for cfg.RoleARN, obtained from STS
+// with the base credentials in awsCfg.
+func assumeRoleProvider(awsCfg aws.Config, cfg S3Config) *stscreds.AssumeRoleProvider {
+	return stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsCfg), cfg.RoleARN,
+		func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = cfg.RoleSessionName
+			if cfg.ExternalID != "" {
+				o.ExternalID = aws.String(cfg.ExternalID)
+			}
+			if cfg.RoleDuration > 0 {
+				o.Duration = cfg.RoleDuration
+			}
+		})
+}
+
 // List returns all GeoPackage files in the S3 bucket.
 func (s *S3Storage) List(ctx context.Context) ([]output.StorageObject, error) {
 	var objects []output.StorageObject
@@ -98,9 +128,10 @@ func (s *S3Storage) List(ctx context.Context) ([]output.StorageObject, error) {
 			key := aws.ToString(obj.Key)
 
 			// Only include supported source files (GeoPackage + raster
-			// bundles) — same rule as the local/http backends so raster
-			// sources aren't silently dropped on S3.
-			if !domain.IsSupportedSourceFile(key) {
+			// bundles, and compressed GeoPackages) — same rule as the
+			// azure/http backends so raster sources aren't silently dropped
+			// on S3.
+			if !domain.IsStoredSourceFile(key) {
 				continue
 			}
 
@@ -122,16 +153,30 @@ func (s *S3Storage) List(ctx context.Context) ([]output.StorageObject, error) {
 
 // Download downloads a file from S3 to the local filesystem.
 func (s *S3Storage) Download(ctx context.Context, key string, dest string) error {
+	return s.download(ctx, key, "", dest)
+}
+
+// DownloadVersion downloads one version of a file from a versioned bucket.
+func (s *S3Storage) DownloadVersion(ctx context.Context, key, versionID, dest string) error {
+	return s.download(ctx, key, versionID, dest)
+}
+
+// download fetches the object, the given version of it when versionID is set.
+func (s *S3Storage) download(ctx context.Context, key, versionID, dest string) error {
 	// Create destination directory
 	if err := os.MkdirAll(filepath.Dir(dest), 0750); err != nil {
 		return err
 	}
 
 	// Get object from S3
-	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+	in := &s3.GetObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(s.fullKey(key)),
-	})
+	}
+	if versionID != "" {
+		in.VersionId = aws.String(versionID)
+	}
+	resp, err := s.client.GetObject(ctx, in)
 	if err != nil {
 		return err
 	}
@@ -70,6 +70,28 @@ func (t *TracedStorage) Download(ctx context.Context, key string, dest string) e
 	return nil
 }
 
+// DownloadVersion implements VersionedStorage when the wrapped storage does.
+func (t *TracedStorage) DownloadVersion(ctx context.Context, key, versionID, dest string) error {
+	ctx, span := t.tracer.Start(ctx, "ObjectStorage.DownloadVersion",
+		output.WithSpanKind(output.SpanKindClient),
+		output.WithAttributes(
+			t.systemAttr,
+			output.String("storage.key", key),
+			output.String("storage.version_id", versionID),
+			output.String("storage.dest", dest),
+		),
+	)
+	defer span.End()
+
+	if err := downloadVersion(ctx, t.inner, key, versionID, dest); err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "download failed")
+		return err
+	}
+	span.SetStatus(output.StatusOK, "")
+	return nil
+}
+
 // GetReader implements ObjectStorage.
 func (t *TracedStorage) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
 	ctx, span := t.tracer.Start(ctx, "ObjectStorage.GetReader",
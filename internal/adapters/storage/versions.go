@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// downloadVersion forwards DownloadVersion to the wrapped storage, failing
+// with domain.ErrUnsupported when it keeps no versions.
+func downloadVersion(ctx context.Context, inner output.ObjectStorage, key, versionID, dest string) error {
+	v, ok := inner.(output.VersionedStorage)
+	if !ok {
+		return fmt.Errorf("object versions: %w", domain.ErrUnsupported)
+	}
+	return v.DownloadVersion(ctx, key, versionID, dest)
+}
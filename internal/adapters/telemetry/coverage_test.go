@@ -22,7 +22,7 @@ import (
 // run to completion without hitting SQLite.
 type coverageRepo struct{}
 
-func (coverageRepo) Open(_ context.Context, path string) (*domain.Source, error) {
+func (coverageRepo) Open(_ context.Context, _, path string) (*domain.Source, error) {
 	return &domain.Source{
 		ID:   "fake",
 		Name: "fake.gpkg",
@@ -204,10 +204,10 @@ type tracedFakeRepo struct {
 	tracer output.Tracer
 }
 
-func (r *tracedFakeRepo) Open(ctx context.Context, path string) (*domain.Source, error) {
+func (r *tracedFakeRepo) Open(ctx context.Context, id, path string) (*domain.Source, error) {
 	_, span := r.tracer.Start(ctx, "Repository.Open")
 	defer span.End()
-	return r.inner.Open(ctx, path)
+	return r.inner.Open(ctx, id, path)
 }
 func (r *tracedFakeRepo) Supports(_ string) bool { return true }
 func (r *tracedFakeRepo) Prepare(ctx context.Context, packageID, layerName string) error {
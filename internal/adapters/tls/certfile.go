@@ -0,0 +1,93 @@
+package tls
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// certCheckInterval is how often, at most, a handshake checks the
+// certificate files for changes.
+var certCheckInterval = 30 * time.Second
+
+// certFiles serves a certificate and key loaded from PEM files and picks up
+// replaced files without a restart. The check runs on a handshake, at most
+// every certCheckInterval, and compares the files' modification times — so a
+// rename or a Kubernetes secret's symlink swap is seen as well. A pair that
+// fails to load (e.g. the key not yet written next to a new certificate)
+// keeps the previous certificate in service and is retried on the next check.
+type certFiles struct {
+	certFile, keyFile string
+	logger            *slog.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	certMod   time.Time
+	keyMod    time.Time
+	lastCheck time.Time
+}
+
+// newCertFiles loads the pair once; unlike a later reload, failing here is
+// an error, as there is no previous certificate to fall back to.
+func newCertFiles(certFile, keyFile string, logger *slog.Logger) (*certFiles, error) {
+	c := &certFiles{certFile: certFile, keyFile: keyFile, logger: logger}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (c *certFiles) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.lastCheck) >= certCheckInterval {
+		c.lastCheck = time.Now()
+		if c.changed() {
+			if err := c.load(); err != nil {
+				c.logger.Warn("reloading TLS certificate failed, keeping the previous one", "error", err)
+			} else {
+				c.logger.Info("TLS certificate reloaded", "cert_file", c.certFile)
+			}
+		}
+	}
+	return c.cert, nil
+}
+
+// changed reports whether either file's modification time differs from the
+// loaded pair's.
+func (c *certFiles) changed() bool {
+	certMod, keyMod, err := c.modTimes()
+	return err == nil && (!certMod.Equal(c.certMod) || !keyMod.Equal(c.keyMod))
+}
+
+// load reads the pair and, if it parses, makes it the served certificate.
+func (c *certFiles) load() error {
+	certMod, keyMod, err := c.modTimes()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate %s: %w", c.certFile, err)
+	}
+	c.cert = &cert
+	c.certMod, c.keyMod = certMod, keyMod
+	c.lastCheck = time.Now()
+	return nil
+}
+
+func (c *certFiles) modTimes() (certMod, keyMod time.Time, err error) {
+	ci, err := os.Stat(c.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("reading TLS certificate: %w", err)
+	}
+	ki, err := os.Stat(c.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("reading TLS key: %w", err)
+	}
+	return ci.ModTime(), ki.ModTime(), nil
+}
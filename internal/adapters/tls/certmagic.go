@@ -1,12 +1,16 @@
-// Package tls provides TLS configuration using CertMagic.
+// Package tls provides TLS configuration: a certificate from files, or
+// automatic certificates using CertMagic.
 package tls
 
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/caddyserver/certmagic"
@@ -20,9 +24,28 @@ type Config struct {
 	Email    string
 	CacheDir string
 	Staging  bool // Use Let's Encrypt staging environment
-	DNS      DNSConfig
+	// Challenge is the ACME challenge type, one of the Challenge* constants;
+	// empty means DNS-01.
+	Challenge string
+	// HTTPPort is where the HTTP-01 challenge is answered; every other
+	// request on it is redirected to HTTPS.
+	HTTPPort int
+	// Port is the HTTPS port, where the TLS-ALPN-01 challenge is answered.
+	Port int
+	DNS  DNSConfig
+	// CertFile and KeyFile serve a certificate from PEM files instead of
+	// ACME; replaced files are picked up without a restart.
+	CertFile string
+	KeyFile  string
 }
 
+// ACME challenge types.
+const (
+	ChallengeDNS01     = "dns-01"
+	ChallengeHTTP01    = "http-01"
+	ChallengeTLSALPN01 = "tls-alpn-01"
+)
+
 // DNSConfig holds Azure DNS provider configuration for DNS-01 challenges.
 type DNSConfig struct {
 	SubscriptionID    string
@@ -36,6 +59,11 @@ type Server struct {
 	handler   http.Handler
 	logger    *slog.Logger
 	tlsConfig *tls.Config
+	magic     *certmagic.Config
+	issuer    *certmagic.ACMEIssuer
+
+	server   *http.Server
+	redirect *http.Server // HTTP-01 challenge and HTTPS redirect listener
 }
 
 // NewServer creates a new TLS-enabled server.
@@ -48,6 +76,22 @@ func NewServer(cfg Config, handler http.Handler, logger *slog.Logger) (*Server,
 		}, nil
 	}
 
+	if cfg.CertFile != "" {
+		certs, err := newCertFiles(cfg.CertFile, cfg.KeyFile, logger)
+		if err != nil {
+			return nil, err
+		}
+		return &Server{
+			config:  cfg,
+			handler: handler,
+			logger:  logger,
+			tlsConfig: &tls.Config{
+				GetCertificate: certs.GetCertificate,
+				MinVersion:     tls.VersionTLS12,
+			},
+		}, nil
+	}
+
 	if len(cfg.Domains) == 0 {
 		return nil, fmt.Errorf("TLS enabled but no domains specified")
 	}
@@ -56,33 +100,21 @@ func NewServer(cfg Config, handler http.Handler, logger *slog.Logger) (*Server,
 		return nil, fmt.Errorf("TLS enabled but no email specified")
 	}
 
-	// Configure CertMagic
-	certmagic.DefaultACME.Agreed = true
-	certmagic.DefaultACME.Email = cfg.Email
-
-	if cfg.Staging {
-		certmagic.DefaultACME.CA = certmagic.LetsEncryptStagingCA
+	template, err := acmeTemplate(cfg)
+	if err != nil {
+		return nil, err
 	}
-
 	if cfg.CacheDir != "" {
 		certmagic.Default.Storage = &certmagic.FileStorage{Path: cfg.CacheDir}
 	}
+	magic := certmagic.NewDefault()
+	issuer := certmagic.NewACMEIssuer(magic, template)
+	magic.Issuers = []certmagic.Issuer{issuer}
 
-	// Configure DNS-01 challenge solver with Azure DNS
-	provider := &azure.Provider{
-		SubscriptionId:    cfg.DNS.SubscriptionID,
-		ResourceGroupName: cfg.DNS.ResourceGroupName,
-		ClientId:          cfg.DNS.ClientID, // Empty = System Assigned Managed Identity
-	}
-	certmagic.DefaultACME.DNS01Solver = &certmagic.DNS01Solver{
-		DNSManager: certmagic.DNSManager{
-			DNSProvider: provider,
-		},
-	}
-
-	// Get TLS config
-	tlsConfig, err := certmagic.TLS(cfg.Domains)
-	if err != nil {
+	// Obtain the certificates up front. An HTTP-01 or TLS-ALPN-01 challenge
+	// due before ListenAndServe is answered on a temporary listener that
+	// CertMagic binds to the same port.
+	if err := magic.ManageSync(context.Background(), cfg.Domains); err != nil {
 		return nil, fmt.Errorf("configuring TLS: %w", err)
 	}
 
@@ -90,41 +122,157 @@ func NewServer(cfg Config, handler http.Handler, logger *slog.Logger) (*Server,
 		config:    cfg,
 		handler:   handler,
 		logger:    logger,
-		tlsConfig: tlsConfig,
+		tlsConfig: magic.TLSConfig(),
+		magic:     magic,
+		issuer:    issuer,
 	}, nil
 }
 
-// ListenAndServe starts the server with TLS if enabled.
-func (s *Server) ListenAndServe(addr string) error {
+// acmeTemplate builds the ACME issuer settings for cfg's challenge type. Each
+// type is used exclusively, so a failing challenge is not retried with one
+// the deployment cannot answer.
+func acmeTemplate(cfg Config) (certmagic.ACMEIssuer, error) {
+	template := certmagic.ACMEIssuer{Agreed: true, Email: cfg.Email}
+	if cfg.Staging {
+		template.CA = certmagic.LetsEncryptStagingCA
+	}
+
+	switch cfg.Challenge {
+	case "", ChallengeDNS01:
+		template.DNS01Solver = &certmagic.DNS01Solver{
+			DNSManager: certmagic.DNSManager{
+				DNSProvider: &azure.Provider{
+					SubscriptionId:    cfg.DNS.SubscriptionID,
+					ResourceGroupName: cfg.DNS.ResourceGroupName,
+					ClientId:          cfg.DNS.ClientID, // Empty = System Assigned Managed Identity
+				},
+			},
+		}
+	case ChallengeHTTP01:
+		template.DisableTLSALPNChallenge = true
+		template.AltHTTPPort = cfg.HTTPPort
+	case ChallengeTLSALPN01:
+		template.DisableHTTPChallenge = true
+		template.AltTLSALPNPort = cfg.Port
+	default:
+		return certmagic.ACMEIssuer{}, fmt.Errorf("unsupported ACME challenge: %s", cfg.Challenge)
+	}
+	return template, nil
+}
+
+// Serve serves on ln, with TLS if enabled, until Shutdown, which also
+// closes ln.
+func (s *Server) Serve(ln net.Listener) error {
+	addr := ln.Addr().String()
 	if !s.config.Enabled {
 		s.logger.Info("starting HTTP server (TLS disabled)", "address", addr)
-		server := &http.Server{
-			Addr:              addr,
+		s.server = &http.Server{
 			Handler:           s.handler,
 			ReadHeaderTimeout: 10 * time.Second,
 		}
-		return server.ListenAndServe()
+		return s.server.Serve(ln)
 	}
 
-	s.logger.Info("starting HTTPS server with DNS-01 challenge",
-		"address", addr,
-		"domains", s.config.Domains,
-	)
+	if s.config.CertFile != "" {
+		s.logger.Info("starting HTTPS server with certificate file",
+			"address", addr,
+			"cert_file", s.config.CertFile,
+		)
+	} else {
+		s.logger.Info("starting HTTPS server with ACME certificates",
+			"address", addr,
+			"domains", s.config.Domains,
+			"challenge", s.challenge(),
+		)
+	}
 
-	server := &http.Server{
-		Addr:              addr,
+	if s.challenge() == ChallengeHTTP01 {
+		s.startRedirect(httpsAddr(ln))
+	}
+
+	s.server = &http.Server{
 		Handler:           s.handler,
 		TLSConfig:         s.tlsConfig,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
-	return server.ListenAndServeTLS("", "")
+	return s.server.ServeTLS(ln, "", "")
 }
 
-// Shutdown gracefully shuts down the server.
-func (s *Server) Shutdown(_ context.Context) error {
-	// CertMagic handles its own cleanup
-	return nil
+// httpsAddr is the TCP address of ln for the HTTPS redirect; a socket that
+// is not TCP (a Unix domain socket behind a proxy) is taken to be reached
+// on the default port.
+func httpsAddr(ln net.Listener) string {
+	if addr, ok := ln.Addr().(*net.TCPAddr); ok {
+		return addr.String()
+	}
+	return ":443"
+}
+
+// challenge returns the configured ACME challenge type, defaulting to DNS-01.
+func (s *Server) challenge() string {
+	if s.config.Challenge == "" {
+		return ChallengeDNS01
+	}
+	return s.config.Challenge
+}
+
+// startRedirect serves the HTTP-01 challenge on the HTTP port, redirecting
+// every other request to the HTTPS server at httpsAddr. A failing listener
+// is logged rather than fatal: the certificates are already obtained, and
+// only renewals depend on it.
+func (s *Server) startRedirect(httpsAddr string) {
+	addr := net.JoinHostPort(hostOf(httpsAddr), strconv.Itoa(s.config.HTTPPort))
+	s.redirect = &http.Server{
+		Addr:              addr,
+		Handler:           s.issuer.HTTPChallengeHandler(redirectHandler(httpsAddr)),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       5 * time.Second,
+		WriteTimeout:      5 * time.Second,
+		IdleTimeout:       5 * time.Second,
+	}
+	s.logger.Info("starting HTTP-01 challenge listener", "address", addr)
+	go func() {
+		if err := s.redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("HTTP-01 challenge listener failed", "address", addr, "error", err)
+		}
+	}()
+}
+
+// redirectHandler redirects a request to the same host and path over HTTPS
+// on httpsAddr's port, which is left out of the URL when it is 443.
+func redirectHandler(httpsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := hostOf(r.Host)
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		w.Header().Set("Connection", "close")
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+// hostOf strips the port from a host:port, leaving a bare host as it is.
+func hostOf(hostport string) string {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return hostport
+	}
+	return host
+}
+
+// Shutdown gracefully shuts down the HTTPS server and the HTTP-01 challenge
+// listener. CertMagic handles its own cleanup.
+func (s *Server) Shutdown(ctx context.Context) error {
+	var errs []error
+	if s.redirect != nil {
+		errs = append(errs, s.redirect.Shutdown(ctx))
+	}
+	if s.server != nil {
+		errs = append(errs, s.server.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
 }
 
 // TLSConfig returns the TLS configuration.
@@ -133,14 +281,15 @@ func (s *Server) TLSConfig() *tls.Config {
 }
 
 // ManageCertificates pre-obtains certificates for the configured domains.
+// Certificate files need no such step.
 func (s *Server) ManageCertificates(ctx context.Context) error {
-	if !s.config.Enabled {
+	if !s.config.Enabled || s.config.CertFile != "" {
 		return nil
 	}
 
 	s.logger.Info("obtaining certificates", "domains", s.config.Domains)
 
-	err := certmagic.ManageSync(ctx, s.config.Domains)
+	err := s.magic.ManageSync(ctx, s.config.Domains)
 	if err != nil {
 		return fmt.Errorf("managing certificates: %w", err)
 	}
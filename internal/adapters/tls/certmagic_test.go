@@ -0,0 +1,75 @@
+package tls
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestACMETemplate(t *testing.T) {
+	tests := []struct {
+		challenge   string
+		wantDNS     bool
+		wantHTTP    bool
+		wantTLSALPN bool
+	}{
+		{challenge: "", wantDNS: true},
+		{challenge: ChallengeDNS01, wantDNS: true},
+		{challenge: ChallengeHTTP01, wantHTTP: true},
+		{challenge: ChallengeTLSALPN01, wantTLSALPN: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.challenge, func(t *testing.T) {
+			template, err := acmeTemplate(Config{Email: "admin@example.com", Challenge: tt.challenge, HTTPPort: 8081, Port: 8443})
+			if err != nil {
+				t.Fatalf("acmeTemplate: %v", err)
+			}
+			if got := template.DNS01Solver != nil; got != tt.wantDNS {
+				t.Errorf("DNS-01 solver set = %v, want %v", got, tt.wantDNS)
+			}
+			if tt.wantDNS {
+				return
+			}
+			if got := !template.DisableHTTPChallenge; got != tt.wantHTTP {
+				t.Errorf("HTTP-01 enabled = %v, want %v", got, tt.wantHTTP)
+			}
+			if got := !template.DisableTLSALPNChallenge; got != tt.wantTLSALPN {
+				t.Errorf("TLS-ALPN-01 enabled = %v, want %v", got, tt.wantTLSALPN)
+			}
+			if tt.wantHTTP && template.AltHTTPPort != 8081 {
+				t.Errorf("AltHTTPPort = %d, want 8081", template.AltHTTPPort)
+			}
+			if tt.wantTLSALPN && template.AltTLSALPNPort != 8443 {
+				t.Errorf("AltTLSALPNPort = %d, want 8443", template.AltTLSALPNPort)
+			}
+		})
+	}
+
+	if _, err := acmeTemplate(Config{Challenge: "email-01"}); err == nil {
+		t.Error("acmeTemplate accepted an unknown challenge")
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		httpsAddr string
+		host      string
+		want      string
+	}{
+		{httpsAddr: "0.0.0.0:443", host: "ortus.example.com", want: "https://ortus.example.com/api/v1/sources?x=1"},
+		{httpsAddr: "0.0.0.0:443", host: "ortus.example.com:80", want: "https://ortus.example.com/api/v1/sources?x=1"},
+		{httpsAddr: ":8443", host: "ortus.example.com:8081", want: "https://ortus.example.com:8443/api/v1/sources?x=1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/sources?x=1", nil)
+		req.Host = tt.host
+		rr := httptest.NewRecorder()
+		redirectHandler(tt.httpsAddr).ServeHTTP(rr, req)
+		if rr.Code != http.StatusMovedPermanently {
+			t.Fatalf("status = %d, want 301", rr.Code)
+		}
+		if got := rr.Header().Get("Location"); got != tt.want {
+			t.Errorf("redirect from %s to %s: Location = %q, want %q", tt.host, tt.httpsAddr, got, tt.want)
+		}
+	}
+}
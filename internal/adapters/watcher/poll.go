@@ -0,0 +1,160 @@
+package watcher
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// fileState is what a poll scan remembers of a source file.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// startPolling takes the baseline scan — files present at startup are loaded
+// by the registry, not reported — and starts the poll loop.
+func (w *Watcher) startPolling(ctx context.Context) {
+	w.pollMu.Lock()
+	w.files = w.scan(w.paths)
+	paths, files := slices.Clone(w.paths), len(w.files)
+	w.pollMu.Unlock()
+
+	w.logger.Info("polling directories", "paths", paths, "interval", w.poll, "files", files)
+	go w.pollLoop(ctx)
+}
+
+// pollLoop rescans the paths every poll interval until ctx ends or the
+// watcher is stopped.
+func (w *Watcher) pollLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.pollOnce()
+		}
+	}
+}
+
+// pollOnce scans the paths and turns the differences to the previous scan
+// into events, which then go through the usual debounce and stability check.
+func (w *Watcher) pollOnce() {
+	w.pollMu.Lock()
+	defer w.pollMu.Unlock()
+
+	next := w.scan(w.paths)
+	for path, st := range next {
+		old, seen := w.files[path]
+		switch {
+		case !seen:
+			w.enqueue(path, OpCreate)
+		case st.size != old.size || !st.modTime.Equal(old.modTime):
+			w.enqueue(path, OpModify)
+		}
+	}
+	for path := range w.files {
+		if _, ok := next[path]; !ok {
+			w.enqueue(path, OpDelete)
+		}
+	}
+	w.files = next
+}
+
+// scan lists the source files below roots, honoring Recursive and Ignore.
+// Entries below a directory that cannot be read are carried over from the
+// previous scan: a network mount that hiccups must not look like every file
+// was deleted.
+func (w *Watcher) scan(roots []string) map[string]fileState {
+	files := make(map[string]fileState)
+	var unreadable []string
+	for _, root := range roots {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			w.logger.Warn("invalid watch path", "path", root, "error", err)
+			continue
+		}
+		_ = filepath.WalkDir(absRoot, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				unreadable = append(unreadable, path)
+				return nil
+			}
+			if d.IsDir() {
+				if path != absRoot && (!w.recursive || w.ignored(path)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !domain.IsSupportedSourceFile(path) || w.ignored(path) {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				files[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+			}
+			return nil
+		})
+	}
+	for path, st := range w.files {
+		if below(path, unreadable) {
+			files[path] = st
+		}
+	}
+	return files
+}
+
+// addPollPath adds a directory to the polled paths. Files already in it are
+// taken into the baseline rather than reported, as with fsnotify.
+func (w *Watcher) addPollPath(absPath string) error {
+	if _, err := os.Stat(absPath); err != nil {
+		return err
+	}
+	w.pollMu.Lock()
+	defer w.pollMu.Unlock()
+	w.paths = append(w.paths, absPath)
+	if w.files == nil {
+		w.files = make(map[string]fileState)
+	}
+	for path, st := range w.scan([]string{absPath}) {
+		w.files[path] = st
+	}
+	w.logger.Info("added watch path", "path", absPath)
+	return nil
+}
+
+// removePollPath stops polling a directory without reporting its files as
+// deleted.
+func (w *Watcher) removePollPath(absPath string) {
+	w.pollMu.Lock()
+	defer w.pollMu.Unlock()
+	w.paths = slices.DeleteFunc(w.paths, func(p string) bool {
+		abs, err := filepath.Abs(p)
+		return err == nil && abs == absPath
+	})
+	for path := range w.files {
+		if below(path, []string{absPath}) {
+			delete(w.files, path)
+		}
+	}
+	w.logger.Info("removed watch path", "path", absPath)
+}
+
+// below reports whether path lies in one of dirs.
+func below(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,7 +4,9 @@ package watcher
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"os"
 	"path/filepath"
 	"sync"
 	"time"
@@ -52,6 +54,11 @@ type Handler func(ctx context.Context, event Event) error
 type pendingEvent struct {
 	timestamp time.Time
 	op        Operation
+	// Size and modification time at the last stability check; checked is
+	// false until the first one.
+	checked bool
+	size    int64
+	modTime time.Time
 }
 
 // Watcher watches directories for GeoPackage file changes.
@@ -61,9 +68,20 @@ type Watcher struct {
 	logger    *slog.Logger
 	tracer    output.Tracer
 	paths     []string
+	recursive bool
+	ignore    func(path string) bool
 	debounce  time.Duration
 	mu        sync.Mutex
 	pending   map[string]*pendingEvent
+
+	// Polling mode (fsWatcher is nil): the paths are scanned every poll
+	// interval and compared with the previous scan. pollMu guards paths
+	// and files in this mode.
+	poll     time.Duration
+	pollMu   sync.Mutex
+	files    map[string]fileState
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 // Config holds watcher configuration.
@@ -71,13 +89,26 @@ type Config struct {
 	Paths    []string
 	Debounce time.Duration
 	Tracer   output.Tracer // optional; defaults to NoOp
+	// Recursive watches every subdirectory of Paths, including ones created
+	// later; files already in a new directory are reported as created.
+	Recursive bool
+	// Ignore is optional; matching files are not reported and matching
+	// directories are not watched.
+	Ignore func(path string) bool
+	// PollInterval > 0 scans Paths at this interval instead of subscribing
+	// to fsnotify, for network filesystems (NFS, CIFS) where changes made by
+	// other hosts raise no inotify events.
+	PollInterval time.Duration
 }
 
 // New creates a new file watcher.
 func New(cfg Config, handler Handler, logger *slog.Logger) (*Watcher, error) {
-	fsWatcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, err
+	var fsWatcher *fsnotify.Watcher
+	if cfg.PollInterval <= 0 {
+		var err error
+		if fsWatcher, err = fsnotify.NewWatcher(); err != nil {
+			return nil, err
+		}
 	}
 
 	if cfg.Debounce == 0 {
@@ -95,13 +126,23 @@ func New(cfg Config, handler Handler, logger *slog.Logger) (*Watcher, error) {
 		logger:    logger,
 		tracer:    tracer,
 		paths:     cfg.Paths,
+		recursive: cfg.Recursive,
+		ignore:    cfg.Ignore,
 		debounce:  cfg.Debounce,
 		pending:   make(map[string]*pendingEvent),
+		poll:      cfg.PollInterval,
+		stop:      make(chan struct{}),
 	}, nil
 }
 
 // Start starts watching the configured paths.
 func (w *Watcher) Start(ctx context.Context) error {
+	if w.fsWatcher == nil {
+		w.startPolling(ctx)
+		go w.debounceLoop(ctx)
+		return nil
+	}
+
 	// Add paths to watch
 	for _, path := range w.paths {
 		absPath, err := filepath.Abs(path)
@@ -114,8 +155,12 @@ func (w *Watcher) Start(ctx context.Context) error {
 			w.logger.Warn("failed to watch path", "path", absPath, "error", err)
 			continue
 		}
+		subdirs := 0
+		if w.recursive {
+			subdirs = w.watchSubdirs(absPath, nil)
+		}
 
-		w.logger.Info("watching directory", "path", absPath)
+		w.logger.Info("watching directory", "path", absPath, "subdirectories", subdirs)
 	}
 
 	// Start event loop
@@ -129,6 +174,10 @@ func (w *Watcher) Start(ctx context.Context) error {
 
 // Stop stops the watcher.
 func (w *Watcher) Stop() error {
+	if w.fsWatcher == nil {
+		w.stopOnce.Do(func() { close(w.stop) })
+		return nil
+	}
 	return w.fsWatcher.Close()
 }
 
@@ -156,23 +205,30 @@ func (w *Watcher) eventLoop(ctx context.Context) {
 
 // handleFsEvent processes a single fsnotify event.
 func (w *Watcher) handleFsEvent(event fsnotify.Event) {
+	if w.recursive && event.Op.Has(fsnotify.Create) && isDir(event.Name) {
+		w.watchNewDir(event.Name)
+		return
+	}
+
 	// Only process supported source files
-	if !domain.IsSupportedSourceFile(event.Name) {
+	if !domain.IsSupportedSourceFile(event.Name) || w.ignored(event.Name) {
 		return
 	}
 
 	w.logger.Debug("file event", "path", event.Name, "op", event.Op.String())
 
 	// Convert fsnotify operation to our operation type
-	op := fsnotifyOpToOperation(event.Op)
+	w.enqueue(event.Name, fsnotifyOpToOperation(event.Op))
+}
 
-	// Add to pending events for debouncing
+// enqueue adds an event to the pending events for debouncing.
+func (w *Watcher) enqueue(path string, op Operation) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	existing, exists := w.pending[event.Name]
+	existing, exists := w.pending[path]
 	if !exists {
-		w.pending[event.Name] = &pendingEvent{
+		w.pending[path] = &pendingEvent{
 			timestamp: time.Now(),
 			op:        op,
 		}
@@ -183,6 +239,64 @@ func (w *Watcher) handleFsEvent(event fsnotify.Event) {
 	w.updatePendingEvent(existing, op)
 }
 
+// watchNewDir starts watching a directory created (or moved) below a watched
+// one. Files can land in it before the watch is in place — a dated folder
+// copied in as a whole — so every source file already there is reported as
+// created.
+func (w *Watcher) watchNewDir(dir string) {
+	if w.ignored(dir) {
+		return
+	}
+	if err := w.fsWatcher.Add(dir); err != nil {
+		w.logger.Warn("failed to watch new directory", "path", dir, "error", err)
+		return
+	}
+	w.logger.Info("watching new directory", "path", dir)
+	w.watchSubdirs(dir, func(path string) { w.enqueue(path, OpCreate) })
+}
+
+// watchSubdirs adds a watch for every directory below root that is not
+// ignored and passes each source file found to found (if non-nil). It
+// returns the number of directories added.
+func (w *Watcher) watchSubdirs(root string, found func(path string)) int {
+	added := 0
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return nil
+		}
+		if w.ignored(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			if found != nil && domain.IsSupportedSourceFile(path) {
+				found(path)
+			}
+			return nil
+		}
+		if err := w.fsWatcher.Add(path); err != nil {
+			w.logger.Warn("failed to watch subdirectory", "path", path, "error", err)
+			return nil
+		}
+		added++
+		return nil
+	})
+	return added
+}
+
+// ignored reports whether the Ignore hook excludes path.
+func (w *Watcher) ignored(path string) bool {
+	return w.ignore != nil && w.ignore(path)
+}
+
+// isDir reports whether path is an existing directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
 // updatePendingEvent updates an existing pending event based on the new operation.
 //
 // Concurrency: the caller must hold w.mu while invoking this method. It is an
@@ -231,6 +345,9 @@ func (w *Watcher) processPending(ctx context.Context) {
 		if now.Sub(pending.timestamp) < w.debounce {
 			continue
 		}
+		if pending.op != OpDelete && !settled(path, pending) {
+			continue
+		}
 
 		delete(w.pending, path)
 
@@ -281,6 +398,25 @@ func (w *Watcher) processPending(ctx context.Context) {
 	}
 }
 
+// settled reports whether a created or modified file has stopped changing:
+// its size and modification time must match those seen one debounce period
+// earlier. A file still being copied (scp, rsync in place) keeps growing, and
+// opening a GeoPackage mid-transfer fails or leaves half-built R-tree state.
+// Otherwise the check is re-armed for another period. A file that cannot be
+// stat'ed counts as settled; the handler reports the error.
+func settled(path string, p *pendingEvent) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return true
+	}
+	if p.checked && info.Size() == p.size && info.ModTime().Equal(p.modTime) {
+		return true
+	}
+	p.checked, p.size, p.modTime = true, info.Size(), info.ModTime()
+	p.timestamp = time.Now()
+	return false
+}
+
 // fsnotifyOpToOperation converts fsnotify.Op to our Operation type.
 func fsnotifyOpToOperation(op fsnotify.Op) Operation {
 	switch {
@@ -303,6 +439,9 @@ func (w *Watcher) AddPath(path string) error {
 	if err != nil {
 		return err
 	}
+	if w.fsWatcher == nil {
+		return w.addPollPath(absPath)
+	}
 
 	if err := w.fsWatcher.Add(absPath); err != nil {
 		return err
@@ -318,6 +457,10 @@ func (w *Watcher) RemovePath(path string) error {
 	if err != nil {
 		return err
 	}
+	if w.fsWatcher == nil {
+		w.removePollPath(absPath)
+		return nil
+	}
 
 	if err := w.fsWatcher.Remove(absPath); err != nil {
 		return err
@@ -35,6 +35,34 @@ func TestUpdatePendingEvent(t *testing.T) {
 	}
 }
 
+// TestSettled: a file is handed on only once its size and mtime held still
+// across two checks.
+func TestSettled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.gpkg")
+	if err := os.WriteFile(path, []byte("part"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pe := &pendingEvent{op: OpCreate}
+	if settled(path, pe) {
+		t.Fatal("settled on the first check")
+	}
+	if !settled(path, pe) {
+		t.Fatal("not settled although the file did not change")
+	}
+
+	pe = &pendingEvent{op: OpCreate}
+	settled(path, pe)
+	if err := os.WriteFile(path, []byte("partial upload, grown"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if settled(path, pe) {
+		t.Error("settled while the file was still growing")
+	}
+	if !settled(filepath.Join(t.TempDir(), "gone.gpkg"), &pendingEvent{op: OpModify}) {
+		t.Error("a missing file should be handed on for the handler to report")
+	}
+}
+
 // TestWatcherHotReload drives the watcher end to end against a real directory:
 // creating a .gpkg must fire OpCreate, deleting it must fire OpDelete, and a
 // non-.gpkg file must be ignored.
@@ -97,6 +125,72 @@ func TestWatcherHotReload(t *testing.T) {
 	waitFor(OpDelete)
 }
 
+// TestWatcherRecursive: with Recursive set, files in existing and newly
+// created subdirectories surface as events, and ignored directories do not.
+func TestWatcherRecursive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("filesystem-timing test; skipped in -short mode")
+	}
+
+	dir := t.TempDir()
+	for _, sub := range []string{"eu", "tmp"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events := make(chan Event, 16)
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	w, err := New(Config{
+		Paths:     []string{dir},
+		Debounce:  50 * time.Millisecond,
+		Recursive: true,
+		Ignore:    func(path string) bool { return filepath.Base(path) == "tmp" },
+	}, func(_ context.Context, e Event) error {
+		events <- e
+		return nil
+	}, logger)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := w.Start(t.Context()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() { _ = w.Stop() }()
+
+	write := func(rel string) string {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	waitFor := func(path string) {
+		t.Helper()
+		deadline := time.After(5 * time.Second)
+		for {
+			select {
+			case e := <-events:
+				if filepath.Base(filepath.Dir(e.Path)) == "tmp" {
+					t.Errorf("event in ignored directory: %s", e.Path)
+				}
+				if e.Path == path && e.Operation == OpCreate {
+					return
+				}
+			case <-deadline:
+				t.Fatalf("timed out waiting for create of %s", path)
+			}
+		}
+	}
+
+	write(filepath.Join("tmp", "scratch.gpkg"))
+	waitFor(write(filepath.Join("eu", "parcels.gpkg")))
+	waitFor(write(filepath.Join("2025-06", "roads.gpkg")))
+}
+
 func TestWatcherAddRemovePath(t *testing.T) {
 	dir := t.TempDir()
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
@@ -116,3 +210,59 @@ func TestWatcherAddRemovePath(t *testing.T) {
 		t.Error("AddPath on nonexistent dir should error")
 	}
 }
+
+// TestWatcherPoll: in polling mode, files present at start are the baseline;
+// later creates, changes and deletes surface as events.
+func TestWatcherPoll(t *testing.T) {
+	if testing.Short() {
+		t.Skip("filesystem-timing test; skipped in -short mode")
+	}
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.gpkg")
+	if err := os.WriteFile(existing, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan Event, 16)
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	w, err := New(Config{Paths: []string{dir}, Debounce: 20 * time.Millisecond, PollInterval: 20 * time.Millisecond},
+		func(_ context.Context, e Event) error {
+			events <- e
+			return nil
+		}, logger)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := w.Start(t.Context()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() { _ = w.Stop() }()
+
+	added := filepath.Join(dir, "added.gpkg")
+	if err := os.WriteFile(added, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	waitFor := func(path string, op Operation) {
+		t.Helper()
+		deadline := time.After(5 * time.Second)
+		for {
+			select {
+			case e := <-events:
+				if e.Path == existing && e.Operation == OpCreate {
+					t.Errorf("file from the baseline reported as created")
+				}
+				if e.Path == path && e.Operation == op {
+					return
+				}
+			case <-deadline:
+				t.Fatalf("timed out waiting for %v of %s", op, path)
+			}
+		}
+	}
+	waitFor(added, OpCreate)
+
+	if err := os.Remove(existing); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(existing, OpDelete)
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jobrunner/ortus/internal/config"
+)
+
+// buildAccessLog opens the access log output: stdout, stderr or a file the
+// lines are appended to. It leaves a.accessLog nil when the access log is off.
+func (a *App) buildAccessLog(cfg config.AccessLogConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	switch cfg.Output {
+	case "stdout":
+		a.accessLog = os.Stdout
+	case "stderr":
+		a.accessLog = os.Stderr
+	default:
+		f, err := os.OpenFile(filepath.Clean(cfg.Output), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("opening access log: %w", err)
+		}
+		a.accessLogFile = f
+		a.accessLog = f
+	}
+	return nil
+}
+
+// closeAccessLog closes the access log file, if any. A second call is a no-op.
+func (a *App) closeAccessLog() {
+	if a.accessLogFile == nil {
+		return
+	}
+	if err := a.accessLogFile.Close(); err != nil {
+		a.Logger.Error("access log close error", "error", err)
+	}
+	a.accessLogFile = nil
+}
+
+// accessLogOutput returns the access log output, or a nil interface when the
+// access log is off (a nil *os.File would still be written to).
+func (a *App) accessLogOutput() io.Writer {
+	if a.accessLog == nil {
+		return nil
+	}
+	return a.accessLog
+}
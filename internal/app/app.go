@@ -5,13 +5,22 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 
 	"go.opentelemetry.io/otel/metric"
 	otelmetricnoop "go.opentelemetry.io/otel/metric/noop"
 	oteltrace "go.opentelemetry.io/otel/trace"
 
+	"github.com/jobrunner/ortus/internal/adapters/featureflags"
 	"github.com/jobrunner/ortus/internal/adapters/geopackage"
 	httpAdapter "github.com/jobrunner/ortus/internal/adapters/http"
+	"github.com/jobrunner/ortus/internal/adapters/lease"
 	"github.com/jobrunner/ortus/internal/adapters/mcp"
 	"github.com/jobrunner/ortus/internal/adapters/metrics"
 	"github.com/jobrunner/ortus/internal/adapters/raster"
@@ -32,6 +41,8 @@ type App struct {
 	Config            *config.Config
 	Logger            *slog.Logger
 	Storage           output.ObjectStorage
+	StorageCircuit    *storage.CircuitBreakerStorage // nil for local storage or with the breaker disabled
+	StorageProbe      *application.StorageProbe      // nil unless storage.health_check.enabled
 	Repository        *geopackage.Repository
 	RasterRepository  *raster.Repository
 	Transformer       *geopackage.RepositoryTransformer
@@ -39,6 +50,7 @@ type App struct {
 	QueryService      *application.QueryService
 	HealthService     *application.HealthService
 	SyncService       *application.SyncService
+	Leader            *application.LeaderElector // nil unless sync.leader_election.enabled
 	HTTPServer        *httpAdapter.Server
 	TLSServer         *tlsAdapter.Server
 	Watcher           *watcher.Watcher
@@ -48,6 +60,35 @@ type App struct {
 	Tracer            output.Tracer       // never nil; NoOp when tracing is disabled
 	MCPServer         *mcp.Server         // nil when MCP is disabled
 	Gazetteer         *gazetteer.Service  // nil when the gazetteer feature is disabled
+	FeatureFlags      *featureflags.Provider
+	Maintenance       *application.MaintenanceMode
+	Popularity        *application.Popularity
+	Tiering           *application.Tiering        // nil unless query.tiering.enabled
+	Federation        *application.Federation     // nil unless federation.enabled
+	Geocoder          *application.GeocodeService // nil unless geocoder.provider is set
+	Audit             *application.AuditLog
+	Optimizer         *application.Optimizer
+
+	// LoadConfig re-reads the configuration for ReloadConfig; nil disables
+	// config reloads. LogLevel is the level of Logger's handler, which a
+	// reload adjusts; nil leaves the log level alone. Both are set by the
+	// caller of New.
+	LoadConfig func() (*config.Config, error)
+	LogLevel   *slog.LevelVar
+	reloadMu   sync.Mutex
+
+	// deferredEvents holds the latest watcher event per path seen while in
+	// maintenance mode; they are replayed when it is switched off.
+	deferredMu     sync.Mutex
+	deferredEvents map[string]watcher.Event
+
+	auditFile *os.File // logging.audit.file; nil when audit entries go to the main log
+
+	accessLog     *os.File // logging.access output; nil when the access log is off
+	accessLogFile *os.File // the access log file to close; nil for stdout/stderr
+
+	loadCancel context.CancelFunc // cancels the background startup load; nil before Start
+	loadDone   chan struct{}      // closed when the background startup load returns
 
 	gazetteerClose             func() error         // releases the gazetteer index connection; nil when disabled
 	gazetteerPolicy            domain.BearingPolicy // bearing tuning knobs (config) + constraint tier (manifest)
@@ -104,6 +145,105 @@ func initTracing(ctx context.Context, cfg *config.Config, logger *slog.Logger) (
 	return tp, telemetry.NewTracer(tp.TracerProvider()), nil
 }
 
+// sourceOverrides maps the packages config onto the registry's per-source
+// display overrides and layer filters.
+func sourceOverrides(cfg *config.Config) map[string]domain.SourceOverride {
+	out := make(map[string]domain.SourceOverride, len(cfg.Packages))
+	for key, p := range cfg.Packages {
+		out[p.SourceID(key)] = domain.SourceOverride{
+			Name:        p.Name,
+			License:     p.License,
+			LicenseURL:  p.LicenseURL,
+			Attribution: p.Attribution,
+			Tags:        p.Tags,
+
+			IncludeLayers:   p.Layers.Include,
+			ExcludeLayers:   p.Layers.Exclude,
+			LayerProperties: layerProperties(p.Layers.Layer),
+			LayerMappings:   layerMappings(p.Layers.Layer),
+			LayerComputed:   layerComputed(p.Layers.Layer),
+
+			LoadMode: domain.LoadMode(strings.ToLower(p.LoadMode)),
+
+			Priority:        p.Priority,
+			LayerPriorities: layerPriorities(p.Layers.Layer),
+
+			Pin: domain.Pin{ETag: p.Pin.ETag, VersionID: p.Pin.VersionID},
+		}
+	}
+	return out
+}
+
+// layerProperties maps the per-layer property settings onto the domain
+// policies; nil when no layer has any.
+func layerProperties(layers map[string]config.LayerConfig) map[string]domain.PropertyPolicy {
+	var out map[string]domain.PropertyPolicy
+	for name, l := range layers {
+		if len(l.Properties) == 0 && len(l.Redact) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]domain.PropertyPolicy)
+		}
+		out[name] = domain.PropertyPolicy{Expose: l.Properties, Redact: l.Redact}
+	}
+	return out
+}
+
+// layerMappings maps the per-layer property mappings onto the domain ones,
+// sorted by column so an unchanged config compares equal on reload; nil
+// when no layer has any.
+func layerMappings(layers map[string]config.LayerConfig) map[string]domain.PropertyMappings {
+	var out map[string]domain.PropertyMappings
+	for name, l := range layers {
+		if len(l.Mapping) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]domain.PropertyMappings)
+		}
+		for _, col := range slices.Sorted(maps.Keys(l.Mapping)) {
+			m := l.Mapping[col]
+			out[name] = append(out[name], domain.PropertyMapping{Column: col, Name: m.Name, Type: m.Type, Format: m.Format})
+		}
+	}
+	return out
+}
+
+// layerComputed maps the per-layer computed properties onto the domain ones;
+// nil when no layer has any.
+func layerComputed(layers map[string]config.LayerConfig) map[string][]domain.ComputedProperty {
+	var out map[string][]domain.ComputedProperty
+	for name, l := range layers {
+		if len(l.Computed) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string][]domain.ComputedProperty)
+		}
+		for _, c := range l.Computed {
+			out[name] = append(out[name], domain.ComputedProperty{Name: c.Name, Expr: c.Expr})
+		}
+	}
+	return out
+}
+
+// layerPriorities maps the per-layer priorities onto the overrides; nil when
+// no layer has one.
+func layerPriorities(layers map[string]config.LayerConfig) map[string]int {
+	var out map[string]int
+	for name, l := range layers {
+		if l.Priority == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]int)
+		}
+		out[name] = l.Priority
+	}
+	return out
+}
+
 // New creates and initializes a new application.
 func New(ctx context.Context, cfg *config.Config, logger *slog.Logger) (app *App, retErr error) {
 	app = &App{
@@ -121,44 +261,68 @@ func New(ctx context.Context, cfg *config.Config, logger *slog.Logger) (app *App
 	app.TelemetryProvider = tp // nil when tracing is disabled
 	app.Tracer = tracer        // NoOp when tracing is disabled
 
-	// Initialize metrics provider. Combines a Prometheus reader (for the
-	// existing /metrics scrape endpoint) with an optional OTLP push reader
-	// configured via metrics.otlp.*. Falls back to a no-op meter when
-	// metrics are disabled entirely so service code never has to nil-check.
+	// Initialize metrics provider. Combines the reader of metrics.exporter
+	// (Prometheus for the /metrics scrape endpoint, StatsD or OTLP push) with
+	// an optional OTLP push reader configured via metrics.otlp.*. Falls back
+	// to a no-op meter when metrics are disabled entirely so service code
+	// never has to nil-check.
 	var meter metric.Meter
 	if cfg.Metrics.Enabled {
 		mc, err := metrics.New(ctx, metrics.Options{
-			OTLPEnabled:   cfg.Metrics.OTLP.Enabled,
-			OTLPEndpoint:  cfg.MetricsOTLPEndpoint(),
-			OTLPTransport: cfg.Metrics.OTLP.Transport,
-			OTLPInsecure:  cfg.Metrics.OTLP.Insecure,
-			OTLPHeaders:   cfg.Metrics.OTLP.Headers,
-			OTLPInterval:  cfg.Metrics.OTLP.Interval,
+			Exporter:       cfg.Metrics.Exporter,
+			StatsDAddress:  cfg.Metrics.StatsD.Address,
+			StatsDPrefix:   cfg.Metrics.StatsD.Prefix,
+			StatsDInterval: cfg.Metrics.StatsD.Interval,
+			StatsDTags:     cfg.Metrics.StatsD.Tags,
+			OTLPEnabled:    cfg.Metrics.OTLP.Enabled,
+			OTLPEndpoint:   cfg.MetricsOTLPEndpoint(),
+			OTLPTransport:  cfg.Metrics.OTLP.Transport,
+			OTLPInsecure:   cfg.Metrics.OTLP.Insecure,
+			OTLPHeaders:    cfg.Metrics.OTLP.Headers,
+			OTLPInterval:   cfg.Metrics.OTLP.Interval,
 		}, logger)
 		if err != nil {
 			return nil, fmt.Errorf("initializing metrics: %w", err)
 		}
 		app.Metrics = mc
 		meter = mc.MeterProvider().Meter("github.com/jobrunner/ortus")
-		app.MetricsServer = metrics.NewServer(cfg.Metrics.Port, cfg.Metrics.Path, logger)
+		// Only the Prometheus exporter is scraped; the others push.
+		if cfg.Metrics.Exporter == "" || cfg.Metrics.Exporter == config.MetricsExporterPrometheus {
+			app.MetricsServer = metrics.NewServer(cfg.Metrics.Port, cfg.Metrics.Path, logger)
+		}
 	} else {
 		meter = otelmetricnoop.NewMeterProvider().Meter("github.com/jobrunner/ortus")
 	}
 
 	// Initialize storage adapter
-	store, err := buildStorage(ctx, cfg, app.Tracer)
+	store, circuit, err := buildStorage(ctx, cfg, app.Tracer, logger)
 	if err != nil {
 		return nil, err
 	}
-	app.Storage = store
+	app.Storage, app.StorageCircuit = store, circuit
+	warnStorageFaults(logger, cfg.Storage.Faults)
 
 	// Initialize GeoPackage (vector) repository
 	app.Repository = geopackage.NewRepository(geopackage.Options{
-		CacheMode:     cfg.Query.SQLite.CacheMode,
-		BusyTimeoutMS: cfg.Query.SQLite.BusyTimeoutMS,
-		JournalMode:   cfg.Query.SQLite.JournalMode,
-		MaxOpenConns:  cfg.Query.SQLite.MaxOpenConns,
-		MaxIdleConns:  cfg.Query.SQLite.MaxIdleConns,
+		CacheMode:          cfg.Query.SQLite.CacheMode,
+		BusyTimeoutMS:      cfg.Query.SQLite.BusyTimeoutMS,
+		JournalMode:        cfg.Query.SQLite.JournalMode,
+		MaxOpenConns:       cfg.Query.SQLite.MaxOpenConns,
+		MaxIdleConns:       cfg.Query.SQLite.MaxIdleConns,
+		Verify:             cfg.Query.SQLite.Verify,
+		FallbackSRID:       cfg.Query.FallbackSRID,
+		Blobs:              cfg.Query.Blobs.Mode,
+		BlobMaxBytes:       cfg.Query.Blobs.MaxBytes,
+		Precision:          cfg.Query.Precision.Enabled,
+		GeographicDecimals: cfg.Query.Precision.Geographic,
+		ProjectedDecimals:  cfg.Query.Precision.Projected,
+		Synchronous:        cfg.Query.SQLite.Synchronous,
+		MmapSizeMB:         cfg.Query.SQLite.MmapSizeMB,
+		CacheSizeMB:        cfg.Query.SQLite.CacheSizeMB,
+		TempStore:          cfg.Query.SQLite.TempStore,
+		ReadOnly:           cfg.Query.SQLite.ReadOnly,
+		CheckValidity:      cfg.Query.Validity.Check,
+		MakeValid:          cfg.Query.Validity.MakeValid,
 	})
 	app.Repository.SetTracer(app.Tracer)
 
@@ -187,8 +351,31 @@ func New(ctx context.Context, cfg *config.Config, logger *slog.Logger) (app *App
 		meter,
 		app.Tracer,
 		logger,
-		cfg.Storage.LocalPath,
+		cfg.Storage.LocalPath(),
+	)
+	local := localStorage(cfg.Storage)
+	if cfg.Storage.Type == config.StorageTypeLocal {
+		app.Registry.SetLocalObjects(local)
+	} else if maxBytes := cfg.Storage.Cache.MaxBytes(); maxBytes > 0 {
+		// Remote storage downloads into local_path; bound what it keeps there.
+		app.Registry.SetCache(application.NewDiskCache(cfg.Storage.LocalPath(), maxBytes, app.Registry, meter, logger))
+	}
+	ids, err := domain.NewSourceIDDeriver(
+		domain.SourceIDStrategy(cfg.Storage.SourceID.Strategy),
+		cfg.Storage.SourceID.Pattern,
+		cfg.Storage.SourceID.Template,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("initializing source id strategy: %w", err)
+	}
+	app.Registry.SetSourceIDDeriver(ids)
+	app.Registry.SetSourceOverrides(sourceOverrides(cfg))
+
+	// The audit log records who synced, loaded, unloaded or switched what.
+	if err := app.buildAudit(cfg.Logging.Audit); err != nil {
+		return nil, err
+	}
+	app.Registry.SetAudit(app.Audit)
 
 	// Initialize coordinate transformer
 	transformer, err := geopackage.NewRepositoryTransformer(app.Repository)
@@ -203,9 +390,15 @@ func New(ctx context.Context, cfg *config.Config, logger *slog.Logger) (app *App
 		if retErr != nil {
 			app.closeTransformer()
 			app.closeGazetteer()
+			app.closeAudit()
+			app.closeAccessLog()
 		}
 	}()
 
+	if err := app.buildAccessLog(cfg.Logging.Access); err != nil {
+		return nil, err
+	}
+
 	// Initialize query service
 	app.QueryService = application.NewQueryService(
 		app.Registry,
@@ -214,13 +407,74 @@ func New(ctx context.Context, cfg *config.Config, logger *slog.Logger) (app *App
 		app.Tracer,
 		logger,
 		application.QueryServiceConfig{
-			MaxFeatures:  cfg.Query.MaxFeatures,
-			QueryTimeout: cfg.Query.Timeout,
+			MaxFeatures:      cfg.Query.MaxFeatures,
+			QueryTimeout:     cfg.Query.Timeout,
+			PrioritizeWithin: cfg.Query.PrioritizeWithin,
+			StrictExtent:     cfg.Query.StrictExtent,
+			Dedupe:           cfg.Query.Dedupe,
+			SlowThreshold:    cfg.Query.SlowThreshold,
+			OmitSourceLabels: !cfg.Metrics.SourceLabels,
+			SRIDBounds:       cfg.Query.Bounds(),
 		},
 	)
 
-	// Initialize health service
+	// Feature flags gate subsystems that are still rolling out; the optional
+	// flags file lets an operator switch one off without a redeploy.
+	flags, err := featureflags.New(cfg.Features.Flags, cfg.Features.File, cfg.Features.RefreshInterval, logger)
+	if err != nil {
+		return nil, fmt.Errorf("initializing feature flags: %w", err)
+	}
+	app.FeatureFlags = flags
+	app.QueryService.SetFeatureFlags(flags)
+
+	// Count which sources actually answer queries; the ranking is served over
+	// HTTP and, when enabled, sizes the idle connection pools.
+	app.Popularity = application.NewPopularity()
+	app.QueryService.SetPopularity(app.Popularity)
+	if t := cfg.Query.Tiering; t.Enabled {
+		hotIdle := cfg.Query.SQLite.MaxIdleConns
+		if hotIdle <= 0 {
+			hotIdle = 2 // database/sql default, what an untiered source gets
+		}
+		app.Tiering = application.NewTiering(app.Popularity, app.Repository, app.Registry, application.TieringConfig{
+			Interval:      t.Interval,
+			Window:        t.Window,
+			HotSources:    t.HotSources,
+			HotIdleConns:  hotIdle,
+			ColdIdleConns: t.ColdIdleConns,
+		}, logger)
+	}
+
+	// Forward queries for sources hosted by peer instances.
+	app.Federation, err = buildFederation(cfg.Federation, logger)
+	if err != nil {
+		return nil, fmt.Errorf("initializing federation: %w", err)
+	}
+	if app.Federation != nil {
+		app.QueryService.SetFederation(app.Federation)
+	}
+
+	// Resolve addresses for GET /api/v1/geocode.
+	app.Geocoder, err = buildGeocoder(cfg.Geocoder, cfg.Build.Version)
+	if err != nil {
+		return nil, fmt.Errorf("initializing geocoder: %w", err)
+	}
+
+	// Initialize health service. The maintenance switch fails readiness while
+	// an operator works on the data directory.
 	app.HealthService = application.NewHealthService(app.Registry, cfg.Server.ReadyWhenEmpty, app.Tracer)
+	app.HealthService.SetStartupTimeout(cfg.Server.StartupTimeout)
+	app.Maintenance = application.NewMaintenanceMode(logger)
+	app.Maintenance.SetAudit(app.Audit)
+	app.HealthService.SetMaintenance(app.Maintenance)
+	app.Registry.SetMaintenance(app.Maintenance)
+	app.Optimizer = application.NewOptimizer(app.Registry, logger)
+	app.Optimizer.SetAudit(app.Audit)
+	app.HealthService.SetStorageCircuit(app.StorageCircuit)
+	app.StorageProbe = buildStorageProbe(cfg, app.Storage, app.Tracer, logger)
+	app.HealthService.SetStorageProbe(app.StorageProbe)
+	app.setupLeaderElection(cfg, logger)
+	app.Maintenance.OnLeave(app.replayDeferredEvents)
 
 	// Initialize the optional gazetteer (reverse geocode + bearing). No-op unless
 	// gazetteer.enabled; opens its own dedicated GeoPackage separate from the
@@ -237,8 +491,21 @@ func New(ctx context.Context, cfg *config.Config, logger *slog.Logger) (app *App
 			app.Tracer,
 			logger,
 		)
+		schedule, blackout, err := cfg.Sync.Timing()
+		if err != nil {
+			return nil, err
+		}
+		app.SyncService.SetSchedule(schedule, blackout)
+		app.SyncService.SetAPICooldown(cfg.Sync.APICooldown)
+		app.SyncService.SetMaintenance(app.Maintenance)
+		app.SyncService.SetAudit(app.Audit)
+		if cfg.Sync.Events.Enabled {
+			// Event Grid reports full blob names; keys are relative to the prefix.
+			app.SyncService.SetChangeScope(cfg.Storage.Azure.Container, cfg.Storage.Azure.Prefix)
+		}
 		logger.Info("sync service configured",
 			"interval", cfg.Sync.Interval,
+			"schedule", cfg.Sync.Schedule,
 			"storage_type", cfg.Storage.Type,
 		)
 	}
@@ -251,18 +518,23 @@ func New(ctx context.Context, cfg *config.Config, logger *slog.Logger) (app *App
 	if cfg.TLS.Enabled {
 		tlsServer, err := tlsAdapter.NewServer(
 			tlsAdapter.Config{
-				Enabled:  cfg.TLS.Enabled,
-				Domains:  cfg.TLS.Domains,
-				Email:    cfg.TLS.Email,
-				CacheDir: cfg.TLS.CacheDir,
-				Staging:  cfg.TLS.Staging,
+				Enabled:   cfg.TLS.Enabled,
+				Domains:   cfg.TLS.Domains,
+				Email:     cfg.TLS.Email,
+				CacheDir:  cfg.TLS.CacheDir,
+				Staging:   cfg.TLS.Staging,
+				Challenge: cfg.TLS.Challenge,
+				HTTPPort:  cfg.TLS.HTTPPort,
+				Port:      cfg.Server.Port,
 				DNS: tlsAdapter.DNSConfig{
 					SubscriptionID:    cfg.TLS.DNS.SubscriptionID,
 					ResourceGroupName: cfg.TLS.DNS.ResourceGroupName,
 					ClientID:          cfg.TLS.DNS.ClientID,
 				},
+				CertFile: cfg.TLS.CertFile,
+				KeyFile:  cfg.TLS.KeyFile,
 			},
-			app.HTTPServer.Router(),
+			app.HTTPServer.Handler(),
 			logger,
 		)
 		if err != nil {
@@ -273,19 +545,7 @@ func New(ctx context.Context, cfg *config.Config, logger *slog.Logger) (app *App
 
 	// Initialize file watcher for hot-reload
 	if cfg.Storage.Type == config.StorageTypeLocal {
-		w, err := watcher.New(
-			watcher.Config{
-				Paths:  []string{cfg.Storage.LocalPath},
-				Tracer: app.Tracer,
-			},
-			app.handleFileEvent,
-			logger,
-		)
-		if err != nil {
-			logger.Warn("failed to initialize file watcher", "error", err)
-		} else {
-			app.Watcher = w
-		}
+		app.Watcher = app.buildWatcher(cfg, local)
 	}
 
 	// Initialize MCP server (optional, off by default). Lives on its own
@@ -313,7 +573,7 @@ func New(ctx context.Context, cfg *config.Config, logger *slog.Logger) (app *App
 }
 
 // buildHTTPServer constructs the HTTP server, applying the typed-nil guards for
-// the optional syncer and gazetteer: a nil concrete pointer stuffed into an
+// the optional syncer, gazetteer and feature flags: a nil concrete pointer stuffed into an
 // interface is NOT == nil, which would defeat the handlers' nil checks (and
 // spuriously register the gazetteer route on a disabled feature).
 func (a *App) buildHTTPServer(cfg *config.Config, logger *slog.Logger) *httpAdapter.Server {
@@ -321,6 +581,10 @@ func (a *App) buildHTTPServer(cfg *config.Config, logger *slog.Logger) *httpAdap
 	if a.SyncService != nil {
 		syncer = a.SyncService
 	}
+	var flags output.FeatureFlags
+	if a.FeatureFlags != nil {
+		flags = a.FeatureFlags
+	}
 	return httpAdapter.NewServer(
 		cfg.Server,
 		a.QueryService,
@@ -337,14 +601,75 @@ func (a *App) buildHTTPServer(cfg *config.Config, logger *slog.Logger) *httpAdap
 			BearingPolicy:      a.gazetteerPolicy,
 			GazetteerLicense:   a.gazetteerLicense,
 			Version:            cfg.Build.Version,
+			Branding:           cfg.Frontend.Branding,
 			Transformer:        a.Transformer,
 			BatchMaxPoints:     cfg.Query.Batch.MaxPoints,
 			BatchMaxSyncPoints: cfg.Query.Batch.MaxSyncPoints,
 			BatchConcurrency:   cfg.Query.Batch.Concurrency,
+			RouteMaxPoints:     cfg.Query.Route.MaxPoints,
+			AreaMaxPoints:      cfg.Query.Area.MaxPoints,
+			AggregateMaxGroups: cfg.Query.Aggregate.MaxGroups,
+			Flags:              flags,
+			Maintenance:        a.Maintenance,
+			Popularity:         a.Popularity,
+			Peers:              a.peerCatalog(),
+			Geocoder:           a.geocoderPort(),
+			SourceHealth:       a.Registry,
+			Tables:             a.Registry,
+			Tiles:              a.Registry,
+			ConfigReloader:     a,
+			Audit:              a.Audit,
+			Optimizer:          a.Optimizer,
+			Stager:             a.Registry,
+			ChangeSyncer:       a.changeSyncer(cfg),
+			SyncEventsToken:    cfg.Sync.Events.Token,
+			AccessLog:          a.accessLogOutput(),
+			AccessLogFormat:    cfg.Logging.Access.Format,
+			RequestLog:         cfg.Logging.Requests,
+			ResultBudgetBytes:  int64(cfg.Query.Memory.BudgetMB) * 1024 * 1024,
+			ResultBudgetWait:   cfg.Query.Memory.Wait,
+			SyncStatus:         a.syncStatus(),
+			CacheStats:         a.RasterRepository,
 		},
 	)
 }
 
+// syncStatus returns the sync service as the stats summary's port, or a nil
+// interface when sync is disabled.
+func (a *App) syncStatus() input.SyncStatus {
+	if a.SyncService == nil {
+		return nil
+	}
+	return a.SyncService
+}
+
+// changeSyncer returns the sync service as the Event Grid webhook's port, or
+// a nil interface when sync.events is off.
+func (a *App) changeSyncer(cfg *config.Config) input.ChangeSyncer {
+	if a.SyncService == nil || !cfg.Sync.Events.Enabled {
+		return nil
+	}
+	return a.SyncService
+}
+
+// peerCatalog returns the federation as a catalog port, or a nil interface
+// when federation is off (a nil *Federation would still register the route).
+func (a *App) peerCatalog() input.PeerCatalog {
+	if a.Federation == nil {
+		return nil
+	}
+	return a.Federation
+}
+
+// geocoderPort returns the address search as its port, or a nil interface
+// when no geocoder is configured.
+func (a *App) geocoderPort() input.Geocoder {
+	if a.Geocoder == nil {
+		return nil
+	}
+	return a.Geocoder
+}
+
 // MCPDeps bundles the dependencies the MCP adapter needs. Exported so the
 // stdio-mode subcommand (cmd/ortus) builds the exact same Deps struct via this
 // one definition instead of duplicating the field-by-field wiring.
@@ -372,7 +697,11 @@ func (a *App) MCPDeps() mcp.Deps {
 	}
 }
 
-// Start starts all application components.
+// Start starts all application components. The HTTP (or TLS) listener comes
+// up right away; sources are loaded and indexed in the background, with
+// /health/ready reporting not ready until that pass completes (see
+// loadSources). Large packages therefore delay neither certificate issuance
+// nor the liveness probe.
 func (a *App) Start(ctx context.Context) error {
 	startupCtx, startupSpan := a.Tracer.Start(ctx, "App.Startup")
 	startupSpan.SetAttributes(
@@ -384,10 +713,6 @@ func (a *App) Start(ctx context.Context) error {
 		output.Bool("ortus.watcher.enabled", a.Watcher != nil),
 	)
 
-	// Track whether any startup step failed so the span status reflects
-	// real outcome rather than always claiming OK after RecordError.
-	startupOK := true
-
 	// Remove raster unpack directories orphaned by a previous crash before they
 	// accumulate and exhaust disk.
 	if a.RasterRepository != nil {
@@ -398,28 +723,72 @@ func (a *App) Start(ctx context.Context) error {
 		}
 	}
 
+	a.startBackgroundServers(ctx)
+
+	a.HealthService.SetStarting(true)
+	loadCtx, cancel := context.WithCancel(startupCtx)
+	a.loadCancel = cancel
+	a.loadDone = make(chan struct{})
+	go a.loadSources(ctx, loadCtx, startupSpan)
+
+	// Start server (long-running — must run outside the startup span so it
+	// doesn't keep the span open for the entire lifetime of the process).
+	ln, err := listen(a.Config.Server)
+	if err != nil {
+		return err
+	}
+	if a.Config.TLS.Enabled && a.TLSServer != nil {
+		return a.TLSServer.Serve(ln)
+	}
+	return a.HTTPServer.Serve(ln)
+}
+
+// loadSources is the background half of Start: it loads all sources, binds
+// and warms the gazetteer, and only then starts the file watcher and the sync
+// loop, which would otherwise race the initial load for the same files. It
+// owns and ends the startup span, whose context loadCtx carries. Canceling
+// loadCtx (Shutdown) stops the load at the next source and skips the rest;
+// ctx is the process context the watcher and sync loop keep.
+func (a *App) loadSources(ctx, loadCtx context.Context, startupSpan output.Span) {
+	defer close(a.loadDone)
+	defer a.HealthService.SetStarting(false)
+	defer startupSpan.End()
+	defer func() {
+		if rec := recover(); rec != nil {
+			a.Logger.Error("source load panic recovered", "panic", rec)
+			startupSpan.SetStatus(output.StatusError, "startup load panicked")
+		}
+	}()
+
+	// Track whether any startup step failed so the span status reflects
+	// real outcome rather than always claiming OK after RecordError.
+	startupOK := true
+
 	// Load all sources from storage
-	if err := a.Registry.LoadAll(startupCtx); err != nil {
+	if err := a.Registry.LoadAll(domain.WithActor(loadCtx, "startup")); err != nil {
 		a.Logger.Warn("failed to load sources", "error", err)
 		startupSpan.RecordError(err)
 		startupOK = false
 	}
 	startupSpan.SetAttributes(output.Int("ortus.sources.loaded", a.Registry.SourceCount()))
+	if loadCtx.Err() != nil {
+		startupSpan.SetStatus(output.StatusError, "startup load canceled")
+		return
+	}
 
 	// Open + bind the gazetteer-owned elevation DEM. It is opened here (not in
 	// buildGazetteer) on purpose: after CleanupOrphaned so the freshly-unpacked
 	// bundle isn't swept away, and after LoadAll so the pool-collision check is
 	// meaningful. It is opened out of competition (never a pool source) and is
 	// non-fatal — a missing/unopenable DEM leaves elevation + exposure silent.
-	a.bindGazetteerElevation(startupCtx)
+	a.bindGazetteerElevation(loadCtx)
 
-	// Warm the gazetteer/DEM path BEFORE the listener accepts traffic, so the first
-	// real request isn't cold (the cause of the "Load failed" first request after a
-	// deploy). Synchronous + best-effort: the server only starts listening — and the
-	// reverse proxy only sees a ready ortus — once this returns.
-	a.warmGazetteer(startupCtx)
+	// Warm the gazetteer/DEM path while readiness is still false, so the
+	// first real request isn't cold (the cause of the "Load failed" first
+	// request after a deploy). Best-effort: failures only log.
+	a.warmGazetteer(loadCtx)
 
-	// Start file watcher. Pass the parent ctx (NOT startupCtx) — the
+	// Start file watcher. Pass the parent ctx (NOT loadCtx) — the
 	// watcher keeps the ctx for the life of the process and uses it as the
 	// parent of every Watcher.handle span. Tying file-event spans to the
 	// startup trace would (a) keep the startup trace eternally unfinalized
@@ -433,25 +802,30 @@ func (a *App) Start(ctx context.Context) error {
 		}
 	}
 
-	a.startBackgroundServers(ctx)
+	if a.SyncService != nil {
+		a.SyncService.Start(ctx)
+	}
 
 	if startupOK {
 		startupSpan.SetStatus(output.StatusOK, "")
 	} else {
 		startupSpan.SetStatus(output.StatusError, "one or more startup steps failed")
 	}
-	startupSpan.End()
+}
 
-	// Start server (long-running — must run outside the startup span so it
-	// doesn't keep the span open for the entire lifetime of the process).
-	if a.Config.TLS.Enabled && a.TLSServer != nil {
-		return a.TLSServer.ListenAndServe(a.Config.Server.Address())
+// stopLoading cancels a background startup load still in progress and waits
+// for it to return, so Shutdown does not close what it is still opening.
+func (a *App) stopLoading() {
+	if a.loadCancel == nil {
+		return
 	}
-	return a.HTTPServer.Start()
+	a.loadCancel()
+	<-a.loadDone
 }
 
 // startBackgroundServers spins up the long-running goroutines (metrics
-// scrape endpoint, sync ticker, MCP server). Each one has its own panic
+// scrape endpoint, flag reloads, tiering, federation, MCP server); the sync
+// ticker waits for the startup load (see loadSources). Each one has its own panic
 // recovery so a runaway in one doesn't take the others down. Extracted
 // from Start() for cognitive-complexity reasons — Start was at gocognit 26.
 func (a *App) startBackgroundServers(ctx context.Context) {
@@ -468,10 +842,25 @@ func (a *App) startBackgroundServers(ctx context.Context) {
 		}()
 	}
 
-	if a.SyncService != nil {
-		a.SyncService.Start(ctx)
+	if a.FeatureFlags != nil {
+		a.FeatureFlags.Start(ctx)
+	}
+
+	if a.Tiering != nil {
+		a.Tiering.Start(ctx)
 	}
 
+	if a.Federation != nil {
+		a.Federation.Start(ctx)
+	}
+
+	if a.StorageProbe != nil {
+		a.StorageProbe.Start(ctx)
+	}
+
+	// Before the startup load, which needs to know whether it may download.
+	a.Leader.Start(ctx)
+
 	// MCP server has its own port + its own panic guard, so a runaway
 	// MCP client can't take the main HTTP server with it.
 	if a.MCPServer != nil {
@@ -495,16 +884,45 @@ func (a *App) Shutdown(ctx context.Context) error {
 
 	a.Logger.Info("shutting down application")
 
+	// Stop a startup load still running in the background
+	a.stopLoading()
+
+	// Give up source optimizations still running
+	if a.Optimizer != nil {
+		a.Optimizer.Stop()
+	}
+
 	// Stop sync service
 	if a.SyncService != nil {
 		a.SyncService.Stop()
 	}
+	// Hand the lease over once this replica fetches nothing any more.
+	a.Leader.Stop()
 
 	// Stop watcher
 	if a.Watcher != nil {
 		_ = a.Watcher.Stop()
 	}
 
+	// Stop the feature-flag file reload loop
+	if a.FeatureFlags != nil {
+		a.FeatureFlags.Stop()
+	}
+
+	// Stop idle-connection tiering
+	if a.Tiering != nil {
+		a.Tiering.Stop()
+	}
+
+	// Stop the peer catalog refresh
+	if a.Federation != nil {
+		a.Federation.Stop()
+	}
+
+	if a.StorageProbe != nil {
+		a.StorageProbe.Stop()
+	}
+
 	// Shutdown MCP server first — block new MCP requests before we tear
 	// down the things they would access.
 	if a.MCPServer != nil {
@@ -524,11 +942,17 @@ func (a *App) Shutdown(ctx context.Context) error {
 	if err := a.HTTPServer.Shutdown(ctx); err != nil {
 		a.Logger.Error("HTTP server shutdown error", "error", err)
 	}
+	if a.TLSServer != nil {
+		if err := a.TLSServer.Shutdown(ctx); err != nil {
+			a.Logger.Error("TLS server shutdown error", "error", err)
+		}
+	}
 
 	// Close all sources
+	unloadCtx := domain.WithActor(ctx, "shutdown")
 	packages, _ := a.Registry.ListSources(ctx)
 	for _, pkg := range packages {
-		if err := a.Registry.UnloadSource(ctx, pkg.ID); err != nil {
+		if err := a.Registry.UnloadSource(unloadCtx, pkg.ID); err != nil {
 			a.Logger.Error("failed to unload package", "id", pkg.ID, "error", err)
 		}
 	}
@@ -550,6 +974,9 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	a.closeAudit()
+	a.closeAccessLog()
+
 	// Shutdown telemetry last so spans emitted during shutdown above still
 	// get flushed by the BatchSpanProcessor.
 	if a.TelemetryProvider != nil {
@@ -576,6 +1003,7 @@ func (a *App) closeTransformer() {
 
 // handleFileEvent handles file system events for hot-reload.
 func (a *App) handleFileEvent(ctx context.Context, event watcher.Event) error {
+	ctx = domain.WithActor(ctx, "watcher")
 	ctx, span := a.Tracer.Start(ctx, "App.handleFileEvent",
 		output.WithAttributes(
 			output.String("watcher.path", event.Path),
@@ -584,6 +1012,20 @@ func (a *App) handleFileEvent(ctx context.Context, event watcher.Event) error {
 	)
 	defer span.End()
 
+	if a.Maintenance.Active() {
+		// The operator is working on the directory: don't load half-copied
+		// files. The last event per path is replayed when maintenance ends.
+		a.deferredMu.Lock()
+		if a.deferredEvents == nil {
+			a.deferredEvents = make(map[string]watcher.Event)
+		}
+		a.deferredEvents[event.Path] = event
+		a.deferredMu.Unlock()
+		a.Logger.Info("file event deferred: maintenance mode", "path", event.Path, "operation", event.Operation.String())
+		span.AddEvent("deferred (maintenance mode)")
+		return nil
+	}
+
 	a.Logger.Info("file event", "path", event.Path, "operation", event.Operation.String())
 
 	switch event.Operation {
@@ -613,27 +1055,181 @@ func (a *App) handleFileEvent(ctx context.Context, event watcher.Event) error {
 	return nil
 }
 
+// replayDeferredEvents handles the watcher events deferred during maintenance
+// mode, in path order. It runs in the background: reloads can take a while
+// (index builds) and must not hold up the request that ended maintenance.
+func (a *App) replayDeferredEvents() {
+	a.deferredMu.Lock()
+	events := a.deferredEvents
+	a.deferredEvents = nil
+	a.deferredMu.Unlock()
+	if len(events) == 0 {
+		return
+	}
+
+	paths := make([]string, 0, len(events))
+	for p := range events {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	a.Logger.Info("replaying file events deferred during maintenance", "count", len(paths))
+	go func() {
+		for _, p := range paths {
+			if err := a.handleFileEvent(context.Background(), events[p]); err != nil {
+				a.Logger.Warn("deferred file event failed", "path", p, "error", err)
+			}
+		}
+	}()
+}
+
 // buildStorage assembles the object-storage stack: the configured backend,
-// error normalization (so all backends surface *domain.StorageError), and
-// optional tracing. Error wrapping is innermost so tracing and every caller
-// see the typed error.
-func buildStorage(ctx context.Context, cfg *config.Config, tracer output.Tracer) (output.ObjectStorage, error) {
+// injected faults directly on it (storage.faults), the circuit breaker and
+// unpacking of compressed GeoPackages (remote backends), error normalization
+// (so all backends surface *domain.StorageError), and optional tracing. Error
+// wrapping sits inside tracing so tracing and every caller see the typed
+// error. The breaker is returned for /health; nil when there is none.
+func buildStorage(ctx context.Context, cfg *config.Config, tracer output.Tracer, logger *slog.Logger) (output.ObjectStorage, *storage.CircuitBreakerStorage, error) {
 	store, err := initStorage(ctx, cfg.Storage)
 	if err != nil {
-		return nil, fmt.Errorf("initializing storage: %w", err)
+		return nil, nil, fmt.Errorf("initializing storage: %w", err)
+	}
+	if f := cfg.Storage.Faults; f.Enabled {
+		store = storage.NewFaultInjectingStorage(store, storage.FaultConfig{
+			ErrorRate:  f.ErrorRate,
+			Latency:    f.Latency,
+			Jitter:     f.Jitter,
+			Operations: f.Operations,
+		})
+	}
+	var circuit *storage.CircuitBreakerStorage
+	if cfg.Storage.Type != config.StorageTypeLocal {
+		if cb := cfg.Storage.CircuitBreaker; cb.Enabled {
+			circuit = storage.NewCircuitBreakerStorage(store, storage.BreakerConfig{
+				FailureThreshold: cb.FailureThreshold,
+				OpenTimeout:      cb.OpenTimeout,
+				OnStateChange: func(from, to string) {
+					level := slog.LevelWarn
+					if to == storage.CircuitClosed {
+						level = slog.LevelInfo
+					}
+					logger.Log(context.Background(), level, "storage circuit breaker changed state", "from", from, "to", to)
+				},
+			})
+			store = circuit
+		}
+		store = storage.NewDecompressingStorage(store)
 	}
 	store = storage.NewErrorWrappingStorage(store)
 	if cfg.Tracing.Enabled {
 		store = storage.NewTracedStorage(store, tracer, cfg.Storage.Type)
 	}
-	return store, nil
+	return store, circuit, nil
+}
+
+// buildStorageProbe returns the periodic storage check /health reports, or
+// nil when storage.health_check is off.
+func buildStorageProbe(cfg *config.Config, store output.ObjectStorage, tracer output.Tracer, logger *slog.Logger) *application.StorageProbe {
+	hc := cfg.Storage.HealthCheck
+	if !hc.Enabled {
+		return nil
+	}
+	return application.NewStorageProbe(store, hc.Interval, hc.Timeout, hc.Key, tracer, logger)
+}
+
+// setupLeaderElection elects the replica that downloads into the shared
+// storage.local_path (sync.leader_election) and makes the registry and
+// /health follow the outcome. No-op unless enabled.
+func (a *App) setupLeaderElection(cfg *config.Config, logger *slog.Logger) {
+	le := cfg.Sync.LeaderElection
+	if !le.Enabled {
+		return
+	}
+	identity := le.Identity
+	if identity == "" {
+		identity, _ = os.Hostname()
+	}
+	if identity == "" {
+		identity = fmt.Sprintf("ortus-%d", os.Getpid())
+	}
+	dir := cfg.Storage.LocalPath()
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		logger.Warn("failed to create the shared cache dir for the leader lease", "dir", dir, "error", err)
+	}
+	a.Leader = application.NewLeaderElector(lease.NewFileLease(filepath.Join(dir, ".ortus-leader")), identity, le.LeaseDuration, logger)
+	a.Registry.SetLeadership(a.Leader)
+	a.HealthService.SetLeadership(a.Leader)
+}
+
+// warnStorageFaults makes injected storage faults impossible to miss in the
+// log of a deployment they were left enabled in.
+func warnStorageFaults(logger *slog.Logger, f config.FaultsConfig) {
+	if !f.Enabled {
+		return
+	}
+	logger.Warn("storage fault injection is enabled; not for production",
+		"error_rate", f.ErrorRate, "latency", f.Latency, "jitter", f.Jitter, "operations", f.Operations)
+}
+
+// CheckStorage builds the configured storage backend and lists it once, as
+// the first sync would, to prove it is reachable with the configured
+// credentials. It returns the number of objects listed.
+func CheckStorage(ctx context.Context, cfg *config.Config) (int, error) {
+	store, _, err := buildStorage(ctx, cfg, output.NoOpTracer{}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		return 0, err
+	}
+	objects, err := store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(objects), nil
+}
+
+// buildWatcher creates the hot-reload watcher for the local storage
+// directories. When fsnotify is unavailable (inotify limits, some container
+// runtimes) it falls back to polling; nil means no hot reload.
+func (a *App) buildWatcher(cfg *config.Config, local *storage.LocalStorage) *watcher.Watcher {
+	wcfg := watcher.Config{
+		Paths:     cfg.Storage.LocalPaths,
+		Tracer:    a.Tracer,
+		Recursive: cfg.Storage.Local.Recursive,
+		// Files and directories storage.local.exclude hides from the
+		// listing are not hot-loaded either.
+		Ignore: func(path string) bool {
+			key, ok := local.ObjectKey(path)
+			return ok && local.Excluded(key)
+		},
+	}
+	if cfg.Watcher.Mode == config.WatcherModePoll {
+		wcfg.PollInterval = cfg.Watcher.Interval()
+	}
+	w, err := watcher.New(wcfg, a.handleFileEvent, a.Logger)
+	if err != nil && wcfg.PollInterval == 0 {
+		a.Logger.Warn("fsnotify unavailable, polling for file changes instead", "error", err)
+		wcfg.PollInterval = cfg.Watcher.Interval()
+		w, err = watcher.New(wcfg, a.handleFileEvent, a.Logger)
+	}
+	if err != nil {
+		a.Logger.Warn("failed to initialize file watcher", "error", err)
+		return nil
+	}
+	return w
+}
+
+// localStorage builds the local storage adapter for storage.local_path.
+func localStorage(cfg config.StorageConfig) *storage.LocalStorage {
+	return storage.NewLocalStorageWithConfig(storage.LocalConfig{
+		Paths:     cfg.LocalPaths,
+		Recursive: cfg.Local.Recursive,
+		Exclude:   cfg.Local.Exclude,
+	})
 }
 
 // initStorage initializes the appropriate storage adapter.
 func initStorage(ctx context.Context, cfg config.StorageConfig) (output.ObjectStorage, error) {
 	switch cfg.Type {
 	case config.StorageTypeLocal:
-		return storage.NewLocalStorage(cfg.LocalPath), nil
+		return localStorage(cfg), nil
 
 	case config.StorageTypeS3:
 		return storage.NewS3Storage(ctx, storage.S3Config{
@@ -643,6 +1239,11 @@ func initStorage(ctx context.Context, cfg config.StorageConfig) (output.ObjectSt
 			Endpoint:        cfg.S3.Endpoint,
 			AccessKeyID:     cfg.S3.AccessKeyID,
 			SecretAccessKey: cfg.S3.SecretAccessKey,
+			SessionToken:    cfg.S3.SessionToken,
+			RoleARN:         cfg.S3.AssumeRole.RoleARN,
+			ExternalID:      cfg.S3.AssumeRole.ExternalID,
+			RoleSessionName: cfg.S3.AssumeRole.SessionName,
+			RoleDuration:    cfg.S3.AssumeRole.Duration,
 		})
 
 	case config.StorageTypeAzure:
@@ -661,8 +1262,21 @@ func initStorage(ctx context.Context, cfg config.StorageConfig) (output.ObjectSt
 			Timeout:   cfg.HTTP.Timeout,
 			Username:  cfg.HTTP.Username,
 			Password:  cfg.HTTP.Password,
+			Autoindex: cfg.HTTP.Autoindex,
 		}), nil
 
+	case config.StorageTypeCatalog:
+		return storage.NewCatalogStorage(storage.CatalogConfig{
+			URL:          cfg.Catalog.URL,
+			Protocol:     cfg.Catalog.Protocol,
+			Keywords:     cfg.Catalog.Keywords,
+			TitlePattern: cfg.Catalog.TitlePattern,
+			MaxRecords:   cfg.Catalog.MaxRecords,
+			Timeout:      cfg.Catalog.Timeout,
+			Username:     cfg.Catalog.Username,
+			Password:     cfg.Catalog.Password,
+		})
+
 	default:
 		return nil, fmt.Errorf("unknown storage type: %s", cfg.Type)
 	}
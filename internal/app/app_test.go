@@ -4,7 +4,6 @@ import (
 	"context"
 	"io"
 	"log/slog"
-	"path/filepath"
 	"strings"
 	"testing"
 
@@ -22,10 +21,8 @@ type fakeProvider struct {
 }
 
 func (p *fakeProvider) Supports(path string) bool { return strings.HasSuffix(path, p.ext) }
-func (p *fakeProvider) Open(_ context.Context, path string) (*domain.Source, error) {
+func (p *fakeProvider) Open(_ context.Context, id, path string) (*domain.Source, error) {
 	p.opens++
-	base := filepath.Base(path)
-	id := strings.TrimSuffix(base, filepath.Ext(base))
 	return &domain.Source{
 		ID: id, Path: path, Kind: domain.SourceKindVector, Indexed: true,
 		Layers: []domain.Layer{{Name: "l", SRID: 4326, HasIndex: true}},
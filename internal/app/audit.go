@@ -0,0 +1,38 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/jobrunner/ortus/internal/application"
+	"github.com/jobrunner/ortus/internal/config"
+)
+
+// buildAudit creates the audit log. With logging.audit.file set, entries are
+// appended to that file as JSON lines; otherwise they go to the main log.
+func (a *App) buildAudit(cfg config.AuditConfig) error {
+	logger := a.Logger
+	if cfg.File != "" {
+		f, err := os.OpenFile(filepath.Clean(cfg.File), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("opening audit log: %w", err)
+		}
+		a.auditFile = f
+		logger = slog.New(slog.NewJSONHandler(f, nil))
+	}
+	a.Audit = application.NewAuditLog(cfg.Retain, logger)
+	return nil
+}
+
+// closeAudit closes the audit log file, if any. A second call is a no-op.
+func (a *App) closeAudit() {
+	if a.auditFile == nil {
+		return
+	}
+	if err := a.auditFile.Close(); err != nil {
+		a.Logger.Error("audit log close error", "error", err)
+	}
+	a.auditFile = nil
+}
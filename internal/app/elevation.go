@@ -31,7 +31,7 @@ func (a *App) bindGazetteerElevation(ctx context.Context) {
 
 	// Open the DEM directly into the raster repository — out of competition, never
 	// registered as a pool source. Failure is non-fatal: elevation + exposure stay silent.
-	src, err := a.RasterRepository.Open(ctx, ec.BundlePath)
+	src, err := a.RasterRepository.Open(ctx, a.Registry.DeriveSourceID(ec.BundlePath), ec.BundlePath)
 	if err != nil {
 		a.Logger.Warn("gazetteer elevation disabled — could not open DEM bundle; elevation and exposure will be silent",
 			"bundle_path", ec.BundlePath, "error", err)
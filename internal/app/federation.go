@@ -0,0 +1,45 @@
+package app
+
+import (
+	"log/slog"
+	"sort"
+
+	"github.com/jobrunner/ortus/internal/adapters/federation"
+	"github.com/jobrunner/ortus/internal/application"
+	"github.com/jobrunner/ortus/internal/config"
+)
+
+// buildFederation creates the peer clients in priority order (then by name,
+// so the order does not depend on map iteration). Returns nil when
+// federation is off.
+func buildFederation(cfg config.FederationConfig, logger *slog.Logger) (*application.Federation, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	names := make([]string, 0, len(cfg.Peers))
+	for name := range cfg.Peers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := cfg.Peers[names[i]].Priority, cfg.Peers[names[j]].Priority
+		if pi != pj {
+			return pi < pj
+		}
+		return names[i] < names[j]
+	})
+
+	peers := make([]application.FederatedPeer, 0, len(names))
+	for _, name := range names {
+		pc := cfg.Peers[name]
+		client, err := federation.NewClient(name, pc.URL)
+		if err != nil {
+			return nil, err
+		}
+		timeout := pc.Timeout
+		if timeout <= 0 {
+			timeout = cfg.Timeout
+		}
+		peers = append(peers, application.FederatedPeer{Peer: client, Timeout: timeout})
+	}
+	return application.NewFederation(peers, cfg.RefreshInterval, logger), nil
+}
@@ -62,6 +62,10 @@ func (a *App) buildGazetteer(ctx context.Context) error {
 		JournalMode:   a.Config.Query.SQLite.JournalMode,
 		MaxOpenConns:  a.Config.Query.SQLite.MaxOpenConns,
 		MaxIdleConns:  a.Config.Query.SQLite.MaxIdleConns,
+		Synchronous:   a.Config.Query.SQLite.Synchronous,
+		MmapSizeMB:    a.Config.Query.SQLite.MmapSizeMB,
+		CacheSizeMB:   a.Config.Query.SQLite.CacheSizeMB,
+		TempStore:     a.Config.Query.SQLite.TempStore,
 	})
 	if err != nil {
 		return fmt.Errorf("opening gazetteer GeoPackage: %w", err)
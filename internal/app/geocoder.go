@@ -0,0 +1,48 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/jobrunner/ortus/internal/adapters/geocoder"
+	"github.com/jobrunner/ortus/internal/application"
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// buildGeocoder creates the address search over the configured provider.
+// Returns nil when geocoder.provider is empty.
+func buildGeocoder(cfg config.GeocoderConfig, version string) (*application.GeocodeService, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+	opts := geocoder.Options{
+		URL:         cfg.URL,
+		UserAgent:   cfg.UserAgent,
+		Headers:     cfg.Headers,
+		Timeout:     cfg.Timeout,
+		MinInterval: cfg.MinInterval,
+		License:     domain.License{Name: cfg.License, URL: cfg.LicenseURL, Attribution: cfg.Attribution},
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = fmt.Sprintf("ortus/%s (+https://github.com/jobrunner/ortus)", version)
+	}
+
+	var port output.Geocoder
+	var err error
+	switch cfg.Provider {
+	case config.GeocoderProviderNominatim:
+		if opts.URL == "" {
+			opts.URL = geocoder.NominatimPublicURL
+		}
+		port, err = geocoder.NewNominatim(opts, cfg.Email)
+	case config.GeocoderProviderCustom:
+		port, err = geocoder.NewCustom(opts)
+	default:
+		err = fmt.Errorf("unknown geocoder.provider %q", cfg.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return application.NewGeocodeService(port, cfg.CacheTTL), nil
+}
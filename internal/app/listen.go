@@ -0,0 +1,100 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/jobrunner/ortus/internal/adapters/proxyproto"
+	"github.com/jobrunner/ortus/internal/config"
+)
+
+// listenFDsStart is the first file descriptor systemd passes (SD_LISTEN_FDS_START).
+var listenFDsStart = 3
+
+// listen opens the main listener of cfg, reading the PROXY protocol header
+// of its connections with server.proxy_protocol.
+func listen(cfg config.ServerConfig) (net.Listener, error) {
+	ln, err := listenSocket(cfg)
+	if err != nil || !cfg.ProxyProtocol {
+		return ln, err
+	}
+	pln, err := proxyproto.NewListener(ln, cfg.TrustedProxyCIDRs())
+	if err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return pln, nil
+}
+
+// listenSocket opens a Unix domain socket for server.listen unix://PATH, the
+// socket systemd passes for server.listen systemd, and TCP on host:port
+// otherwise.
+func listenSocket(cfg config.ServerConfig) (net.Listener, error) {
+	if path := cfg.SocketPath(); path != "" {
+		return listenUnix(path)
+	}
+	if cfg.Listen == config.ListenSystemd {
+		return listenSystemd()
+	}
+	return net.Listen("tcp", cfg.Address())
+}
+
+// listenUnix listens on a Unix domain socket at path. A socket file left
+// behind by a crashed process is removed first; one a running process still
+// accepts on is an error, as is any other file at path. The listener removes
+// the socket file when it is closed, i.e. on shutdown.
+func listenUnix(path string) (net.Listener, error) {
+	fi, err := os.Lstat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("checking socket %s: %w", path, err)
+	case fi.Mode()&fs.ModeSocket == 0:
+		return nil, fmt.Errorf("%s exists and is not a socket", path)
+	default:
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("socket %s is in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("removing stale socket %s: %w", path, err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", path, err)
+	}
+	return ln, nil
+}
+
+// listenSystemd takes over the first socket passed by systemd socket
+// activation (sd_listen_fds(3)). LISTEN_PID must name this process, so a
+// child does not claim sockets meant for its parent; the variables are
+// cleared afterwards for the same reason.
+func listenSystemd() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, fmt.Errorf("server.listen is %s but no socket was passed (LISTEN_PID not set to this process)", config.ListenSystemd)
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("server.listen is %s but no socket was passed (LISTEN_FDS=%q)", config.ListenSystemd, os.Getenv("LISTEN_FDS"))
+	}
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+
+	f := os.NewFile(uintptr(listenFDsStart), "LISTEN_FD_"+strconv.Itoa(listenFDsStart))
+	// FileListener dups the descriptor; the original is no longer needed.
+	defer func() { _ = f.Close() }()
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("using the socket passed by systemd: %w", err)
+	}
+	return ln, nil
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/config"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ortus.sock")
+	cfg := config.ServerConfig{Listen: config.ListenUnixPrefix + path}
+
+	ln, err := listen(cfg)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if _, err := listen(cfg); err == nil {
+		t.Error("listen succeeded on a socket in use")
+	}
+	if err := ln.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("socket file left behind after close: %v", err)
+	}
+
+	// A socket file nobody accepts on, as a crashed process leaves it, is
+	// replaced.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+	ln, err = listen(cfg)
+	if err != nil {
+		t.Fatalf("listen over a stale socket: %v", err)
+	}
+	_ = ln.Close()
+
+	// Any other file is left alone.
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listen(cfg); err == nil {
+		t.Error("listen replaced a regular file")
+	}
+}
+
+func TestListenSystemd(t *testing.T) {
+	cfg := config.ServerConfig{Listen: config.ListenSystemd}
+
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+	if _, err := listen(cfg); err == nil {
+		t.Error("listen took a socket passed to another process")
+	}
+
+	passed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = passed.Close() }()
+	f, err := passed.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// listen takes ownership of the descriptor, as of one systemd passes; hand
+	// it a raw copy that no *os.File closes behind its back.
+	fd, err := syscall.Dup(int(f.Fd()))
+	_ = f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := listenFDsStart
+	listenFDsStart = fd
+	t.Cleanup(func() { listenFDsStart = old })
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	ln, err := listen(cfg)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	if ln.Addr().String() != passed.Addr().String() {
+		t.Errorf("listening on %s, want the passed socket %s", ln.Addr(), passed.Addr())
+	}
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Error("LISTEN_FDS not cleared")
+	}
+}
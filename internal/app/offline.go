@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	otelmetricnoop "go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/jobrunner/ortus/internal/adapters/geopackage"
+	"github.com/jobrunner/ortus/internal/adapters/storage"
+	"github.com/jobrunner/ortus/internal/application"
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// Offline is the query stack without any server: a registry over explicitly
+// named local GeoPackages plus the same QueryService the HTTP API uses. It
+// backs one-shot CLI commands (`ortus query`) — no storage listing, sync,
+// watcher, metrics or tracing.
+type Offline struct {
+	Registry     *application.SourceRegistry
+	QueryService *application.QueryService
+
+	repository  *geopackage.Repository
+	transformer *geopackage.RepositoryTransformer
+	logger      *slog.Logger
+}
+
+// NewOffline builds the offline stack with the query and SQLite settings of
+// cfg, so a CLI query behaves like the configured server. Close releases it.
+func NewOffline(cfg *config.Config, logger *slog.Logger) (*Offline, error) {
+	meter := otelmetricnoop.NewMeterProvider().Meter("github.com/jobrunner/ortus")
+
+	repo := geopackage.NewRepository(geopackage.Options{
+		CacheMode:          cfg.Query.SQLite.CacheMode,
+		BusyTimeoutMS:      cfg.Query.SQLite.BusyTimeoutMS,
+		JournalMode:        cfg.Query.SQLite.JournalMode,
+		MaxOpenConns:       cfg.Query.SQLite.MaxOpenConns,
+		MaxIdleConns:       cfg.Query.SQLite.MaxIdleConns,
+		Verify:             cfg.Query.SQLite.Verify,
+		FallbackSRID:       cfg.Query.FallbackSRID,
+		Blobs:              cfg.Query.Blobs.Mode,
+		BlobMaxBytes:       cfg.Query.Blobs.MaxBytes,
+		Precision:          cfg.Query.Precision.Enabled,
+		GeographicDecimals: cfg.Query.Precision.Geographic,
+		ProjectedDecimals:  cfg.Query.Precision.Projected,
+		Synchronous:        cfg.Query.SQLite.Synchronous,
+		MmapSizeMB:         cfg.Query.SQLite.MmapSizeMB,
+		CacheSizeMB:        cfg.Query.SQLite.CacheSizeMB,
+		TempStore:          cfg.Query.SQLite.TempStore,
+		MakeValid:          cfg.Query.Validity.MakeValid,
+	})
+	transformer, err := geopackage.NewRepositoryTransformer(repo)
+	if err != nil {
+		return nil, fmt.Errorf("initializing coordinate transformer: %w", err)
+	}
+
+	// The registry never lists this storage: sources are loaded by path.
+	registry := application.NewSourceRegistry(
+		[]output.SpatialSource{repo},
+		storage.NewLocalStorage(cfg.Storage.LocalPath()),
+		meter,
+		output.NoOpTracer{},
+		logger,
+		cfg.Storage.LocalPath(),
+	)
+	registry.SetSourceOverrides(sourceOverrides(cfg))
+	query := application.NewQueryService(
+		registry,
+		transformer,
+		meter,
+		output.NoOpTracer{},
+		logger,
+		application.QueryServiceConfig{
+			MaxFeatures:      cfg.Query.MaxFeatures,
+			QueryTimeout:     cfg.Query.Timeout,
+			PrioritizeWithin: cfg.Query.PrioritizeWithin,
+			StrictExtent:     cfg.Query.StrictExtent,
+			Dedupe:           cfg.Query.Dedupe,
+		},
+	)
+
+	return &Offline{
+		Registry:     registry,
+		QueryService: query,
+		repository:   repo,
+		transformer:  transformer,
+		logger:       logger,
+	}, nil
+}
+
+// Load opens each file as a source, exactly as the server would on startup
+// (including building a missing spatial index). The first failure aborts.
+func (o *Offline) Load(ctx context.Context, paths ...string) error {
+	for _, p := range paths {
+		if err := o.Registry.LoadSource(ctx, p); err != nil {
+			return fmt.Errorf("loading %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// Close unloads every source and releases the transformer.
+func (o *Offline) Close(ctx context.Context) {
+	sources, _ := o.Registry.ListSources(ctx)
+	for _, src := range sources {
+		if err := o.Registry.UnloadSource(ctx, src.ID); err != nil {
+			o.logger.Error("failed to unload source", "id", src.ID, "error", err)
+		}
+	}
+	if err := o.transformer.Close(); err != nil {
+		o.logger.Error("transformer close error", "error", err)
+	}
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// TestOfflineQuery runs the `ortus query` stack against the gazetteer fixture
+// (a plain GeoPackage) at the warmup point it covers. A copy is loaded because
+// loading may add a missing spatial index to the file.
+func TestOfflineQuery(t *testing.T) {
+	ctx := context.Background()
+	src, err := os.ReadFile("testdata/gazetteer-fixture.gpkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "fixture.gpkg")
+	if err := os.WriteFile(path, src, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Query.MaxFeatures = 100
+	offline, err := NewOffline(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Skipf("SpatiaLite extension not available, skipping: %v", err)
+	}
+	defer offline.Close(ctx)
+
+	if err := offline.Load(ctx, path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	resp, err := offline.QueryService.QueryPoint(ctx, domain.QueryRequest{
+		Coordinate: domain.NewWGS84Coordinate(9.93, 49.79),
+		SourceSRID: domain.SRIDWGS84,
+	})
+	if err != nil {
+		t.Fatalf("QueryPoint: %v", err)
+	}
+	if resp.TotalFeatures == 0 || len(resp.Results) != 1 || resp.Results[0].SourceID != "fixture" {
+		t.Errorf("response = %+v, want features from source fixture", resp)
+	}
+
+	if err := offline.Load(ctx, filepath.Join(t.TempDir(), "missing.gpkg")); err == nil {
+		t.Error("Load of a missing file succeeded")
+	}
+}
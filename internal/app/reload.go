@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+)
+
+var _ input.ConfigReloader = (*App)(nil)
+
+// errReloadUnavailable is returned when the app was built without a
+// LoadConfig function (stdio MCP mode, tests).
+var errReloadUnavailable = errors.New("config reload is not available")
+
+// ReloadConfig re-reads the configuration through LoadConfig and swaps in the
+// settings that can change at runtime: logging.level, server.cors,
+// server.rate_limit, query.max_features, query.timeout and packages. Any
+// other change is logged and waits for a restart. Reloads are serialized; an
+// invalid configuration changes nothing. While maintenance mode is on it
+// returns domain.ErrMaintenance: a changed packages block reopens sources from
+// the data directory the operator is working on.
+func (a *App) ReloadConfig(ctx context.Context) (input.ConfigReload, error) {
+	if a.LoadConfig == nil {
+		return input.ConfigReload{}, errReloadUnavailable
+	}
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	if a.Maintenance.Active() {
+		a.Audit.Record(ctx, domain.AuditConfigReload, "", "", domain.ErrMaintenance)
+		return input.ConfigReload{}, domain.ErrMaintenance
+	}
+
+	next, err := a.LoadConfig()
+	if err != nil {
+		err = fmt.Errorf("reloading config: %w", err)
+		a.Audit.Record(ctx, domain.AuditConfigReload, "", "", err)
+		return input.ConfigReload{}, err
+	}
+	cur := a.Config
+	res := input.ConfigReload{Changed: []string{}, ReopenedSources: []string{}}
+	changed := func(key string, same bool) bool {
+		if !same {
+			res.Changed = append(res.Changed, key)
+		}
+		return !same
+	}
+
+	if changed("logging.level", cur.Logging.Level == next.Logging.Level) && a.LogLevel != nil {
+		a.LogLevel.Set(next.Logging.SlogLevel())
+	}
+	cors := changed("server.cors.allowed_origins", slices.Equal(cur.Server.CORS.AllowedOrigins, next.Server.CORS.AllowedOrigins))
+	limit := changed("server.rate_limit", reflect.DeepEqual(cur.Server.RateLimit, next.Server.RateLimit))
+	if (cors || limit) && a.HTTPServer != nil {
+		a.HTTPServer.Reconfigure(next.Server)
+	}
+	maxFeatures := changed("query.max_features", cur.Query.MaxFeatures == next.Query.MaxFeatures)
+	timeout := changed("query.timeout", cur.Query.Timeout == next.Query.Timeout)
+	if maxFeatures || timeout {
+		a.QueryService.SetLimits(next.Query.MaxFeatures, next.Query.Timeout)
+	}
+	if changed("packages", reflect.DeepEqual(cur.Packages, next.Packages)) {
+		res.ReopenedSources = a.Registry.ReplaceSourceOverrides(ctx, sourceOverrides(next))
+	}
+
+	if needsRestart(cur, next) {
+		a.Logger.Warn("config changes outside the reloadable settings take effect on restart")
+	}
+	cur.Logging.Level = next.Logging.Level
+	cur.Server.CORS = next.Server.CORS
+	cur.Server.RateLimit = next.Server.RateLimit
+	cur.Query.MaxFeatures = next.Query.MaxFeatures
+	cur.Query.Timeout = next.Query.Timeout
+	cur.Packages = next.Packages
+
+	a.Audit.Record(ctx, domain.AuditConfigReload, "", strings.Join(res.Changed, ","), nil)
+	a.Logger.Info("config reloaded", "changed", res.Changed, "reopened_sources", res.ReopenedSources)
+	return res, nil
+}
+
+// needsRestart reports whether next differs from cur in anything but the
+// reloadable settings.
+func needsRestart(cur, next *config.Config) bool {
+	probe := *next
+	probe.Logging.Level = cur.Logging.Level
+	probe.Server.CORS = cur.Server.CORS
+	probe.Server.RateLimit = cur.Server.RateLimit
+	probe.Query.MaxFeatures = cur.Query.MaxFeatures
+	probe.Query.Timeout = cur.Query.Timeout
+	probe.Packages = cur.Packages
+	return !reflect.DeepEqual(probe, *cur)
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/application"
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+func TestReloadConfig(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	gpkg := &fakeProvider{ext: ".gpkg"}
+	reg := application.NewSourceRegistry(
+		[]output.SpatialSource{gpkg}, nil, nil, output.NoOpTracer{}, logger, "/tmp")
+	for _, path := range []string{"/data/parcels.gpkg", "/data/roads.gpkg"} {
+		if err := reg.LoadSource(ctx, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cur := &config.Config{}
+	cur.Logging.Level = "info"
+	cur.Query.MaxFeatures = 100
+	level := new(slog.LevelVar)
+	next := *cur
+	a := &App{
+		Config:       cur,
+		Logger:       logger,
+		Registry:     reg,
+		QueryService: application.NewQueryService(reg, nil, nil, output.NoOpTracer{}, logger, application.QueryServiceConfig{}),
+		LogLevel:     level,
+		LoadConfig:   func() (*config.Config, error) { c := next; return &c, nil },
+	}
+
+	next.Logging.Level = "debug"
+	next.Query.Timeout = 2 * time.Second
+	next.Packages = map[string]config.PackageConfig{"parcels": {Name: "Flurstücke"}}
+	res, err := a.ReloadConfig(ctx)
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if want := []string{"logging.level", "query.timeout", "packages"}; !slices.Equal(res.Changed, want) {
+		t.Errorf("Changed = %v, want %v", res.Changed, want)
+	}
+	if !slices.Equal(res.ReopenedSources, []string{"parcels"}) {
+		t.Errorf("ReopenedSources = %v, want only the source whose override changed", res.ReopenedSources)
+	}
+	if level.Level() != slog.LevelDebug {
+		t.Errorf("log level = %v, want debug", level.Level())
+	}
+	if src, _ := reg.GetSource(ctx, "parcels"); src == nil || src.Name != "Flurstücke" {
+		t.Errorf("parcels = %+v, want the new name applied", src)
+	}
+	if a.Config.Query.Timeout != 2*time.Second || a.Config.Logging.Level != "debug" {
+		t.Errorf("live config not updated: %+v", a.Config.Query)
+	}
+
+	// Reloading the same config is a no-op.
+	if res, err := a.ReloadConfig(ctx); err != nil || len(res.Changed) != 0 || len(res.ReopenedSources) != 0 {
+		t.Errorf("second reload = %+v, %v; want nothing changed", res, err)
+	}
+}
+
+func TestReloadConfigRejectsInvalid(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	level := new(slog.LevelVar)
+	a := &App{
+		Config:     &config.Config{Logging: config.LoggingConfig{Level: "info"}},
+		Logger:     logger,
+		LogLevel:   level,
+		LoadConfig: func() (*config.Config, error) { return nil, errors.New("validating config: bad") },
+	}
+	if _, err := a.ReloadConfig(context.Background()); err == nil {
+		t.Fatal("ReloadConfig succeeded with an invalid config")
+	}
+	if level.Level() != slog.LevelInfo || a.Config.Logging.Level != "info" {
+		t.Error("a rejected reload changed the running settings")
+	}
+
+	if _, err := (&App{}).ReloadConfig(context.Background()); !errors.Is(err, errReloadUnavailable) {
+		t.Errorf("without LoadConfig: err = %v, want errReloadUnavailable", err)
+	}
+}
+
+func TestReloadConfigBlockedInMaintenance(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	level := new(slog.LevelVar)
+	loads := 0
+	a := &App{
+		Config:      &config.Config{Logging: config.LoggingConfig{Level: "info"}},
+		Logger:      logger,
+		LogLevel:    level,
+		Maintenance: application.NewMaintenanceMode(logger),
+		LoadConfig: func() (*config.Config, error) {
+			loads++
+			return &config.Config{
+				Logging:  config.LoggingConfig{Level: "debug"},
+				Packages: map[string]config.PackageConfig{"parcels": {Name: "Flurstücke"}},
+			}, nil
+		},
+	}
+	a.Maintenance.EnterMaintenance(ctx, "replacing parcels.gpkg")
+
+	if _, err := a.ReloadConfig(ctx); !errors.Is(err, domain.ErrMaintenance) {
+		t.Fatalf("ReloadConfig in maintenance: err = %v, want ErrMaintenance", err)
+	}
+	if loads != 0 || level.Level() != slog.LevelInfo || a.Config.Packages != nil {
+		t.Error("a reload refused for maintenance read or applied the config")
+	}
+}
@@ -20,8 +20,9 @@ const warmGazetteerTimeout = 30 * time.Second
 // first client request is what made that request time out ("Load failed", then
 // fine) after every deploy.
 //
-// It runs synchronously before the listener starts, so it DOES delay readiness —
-// but only by at most warmGazetteerTimeout (it never blocks indefinitely). A
+// It runs in the background startup, before readiness turns true, so it DOES
+// delay readiness — but only by at most warmGazetteerTimeout (it never blocks
+// indefinitely). A
 // warmup that hits a real error (e.g. a timeout or DEM failure) is logged at WARN
 // so a still-cold first request is diagnosable; "no result" at the warmup point
 // (ErrNotFound / an unwired optional feature) is normal and not treated as failure.
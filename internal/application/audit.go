@@ -0,0 +1,87 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// DefaultAuditRetain is the number of entries an AuditLog keeps when none is
+// configured.
+const DefaultAuditRetain = 1000
+
+// AuditLog records who changed what and when: syncs, source loads and
+// unloads, maintenance switches and config reloads. Every entry is written to
+// its logger — typically a dedicated handler or file — and the most recent
+// ones are kept in memory for GET /admin/audit. The actor is taken from the
+// action's context (domain.WithActor).
+type AuditLog struct {
+	mu      sync.Mutex
+	entries []domain.AuditEntry // oldest first; at most retain
+	retain  int
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewAuditLog returns an audit log that keeps the last retain entries
+// (DefaultAuditRetain when retain <= 0) and writes each to logger.
+func NewAuditLog(retain int, logger *slog.Logger) *AuditLog {
+	if retain <= 0 {
+		retain = DefaultAuditRetain
+	}
+	return &AuditLog{retain: retain, logger: logger, now: time.Now}
+}
+
+// Record adds an entry for action on target, failed when err is non-nil.
+// Safe on a nil *AuditLog, so services can call it unconditionally.
+func (a *AuditLog) Record(ctx context.Context, action domain.AuditAction, target, detail string, err error) {
+	if a == nil {
+		return
+	}
+	e := domain.AuditEntry{
+		Time:   a.now(),
+		Actor:  domain.ActorFrom(ctx),
+		Action: action,
+		Target: target,
+		Detail: detail,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	a.mu.Lock()
+	a.entries = append(a.entries, e)
+	if n := len(a.entries) - a.retain; n > 0 {
+		a.entries = a.entries[n:]
+	}
+	a.mu.Unlock()
+
+	attrs := []any{"actor", e.Actor, "action", string(e.Action)}
+	if e.Target != "" {
+		attrs = append(attrs, "target", e.Target)
+	}
+	if e.Detail != "" {
+		attrs = append(attrs, "detail", e.Detail)
+	}
+	if e.Error != "" {
+		attrs = append(attrs, "error", e.Error)
+	}
+	a.logger.InfoContext(ctx, "audit", attrs...)
+}
+
+// RecentAudit implements input.AuditTrail.
+func (a *AuditLog) RecentAudit(limit int) []domain.AuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if limit <= 0 || limit > len(a.entries) {
+		limit = len(a.entries)
+	}
+	out := make([]domain.AuditEntry, limit)
+	for i := range out {
+		out[i] = a.entries[len(a.entries)-1-i]
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+func TestAuditLogKeepsNewestFirst(t *testing.T) {
+	var buf bytes.Buffer
+	audit := NewAuditLog(2, slog.New(slog.NewTextHandler(&buf, nil)))
+	ctx := domain.WithActor(context.Background(), "admin@10.0.0.1")
+
+	audit.Record(context.Background(), domain.AuditLoad, "parcels", "", nil)
+	audit.Record(ctx, domain.AuditMaintenanceEnter, "", "replace parcels", nil)
+	audit.Record(ctx, domain.AuditUnload, "parcels", "", errors.New("close failed"))
+
+	got := audit.RecentAudit(0)
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want the 2 retained entries", len(got))
+	}
+	if got[0].Action != domain.AuditUnload || got[0].Error != "close failed" || got[0].Actor != "admin@10.0.0.1" {
+		t.Errorf("newest = %+v", got[0])
+	}
+	if got[1].Action != domain.AuditMaintenanceEnter || got[1].Detail != "replace parcels" {
+		t.Errorf("second = %+v", got[1])
+	}
+	if got := audit.RecentAudit(1); len(got) != 1 || got[0].Action != domain.AuditUnload {
+		t.Errorf("RecentAudit(1) = %+v", got)
+	}
+
+	if !strings.Contains(buf.String(), "actor=system action=load target=parcels") {
+		t.Errorf("log output misses the entry without an actor:\n%s", buf.String())
+	}
+}
+
+func TestAuditLogNilIsNoOp(t *testing.T) {
+	var audit *AuditLog
+	audit.Record(context.Background(), domain.AuditSync, "", "", nil)
+}
@@ -0,0 +1,224 @@
+package application
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// loadedPaths is the registry surface the disk cache needs: a file that
+// backs a loaded source must never be evicted.
+type loadedPaths interface {
+	IsPathLoaded(path string) bool
+}
+
+// cachedFile is a downloaded source file in the cache directory.
+type cachedFile struct {
+	size     int64
+	lastUsed time.Time
+}
+
+// DiskCache bounds the space downloaded sources take in the local cache
+// directory. Before a download it evicts the least recently used files that
+// back no loaded source (together with their metadata sidecars) until the
+// download fits; loaded sources are never evicted, so the cache may exceed
+// its limit while they alone need more. Reconcile rebuilds the index from the
+// directory after the startup load. A nil *DiskCache leaves the cache
+// unbounded.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+	loaded   loadedPaths
+	logger   *slog.Logger
+
+	mu    sync.Mutex
+	files map[string]*cachedFile
+
+	usedBytes atomic.Int64
+	fileCount atomic.Int64
+	evictions metric.Int64Counter
+}
+
+// NewDiskCache creates a cache manager for dir holding at most maxBytes of
+// source files. A nil meter disables the metrics.
+func NewDiskCache(dir string, maxBytes int64, loaded loadedPaths, meter metric.Meter, logger *slog.Logger) *DiskCache {
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("ortus/application")
+	}
+	c := &DiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		loaded:   loaded,
+		logger:   logger,
+		files:    make(map[string]*cachedFile),
+	}
+
+	c.evictions, _ = meter.Int64Counter(
+		"ortus.cache.evictions",
+		metric.WithDescription("Number of cached source files evicted to free disk space"),
+	)
+	used, _ := meter.Int64ObservableGauge(
+		"ortus.cache.bytes",
+		metric.WithDescription("Bytes of downloaded source files in the local cache"),
+		metric.WithUnit("By"),
+	)
+	files, _ := meter.Int64ObservableGauge(
+		"ortus.cache.files",
+		metric.WithDescription("Number of downloaded source files in the local cache"),
+	)
+	limit, _ := meter.Int64ObservableGauge(
+		"ortus.cache.max_bytes",
+		metric.WithDescription("Configured size limit of the local cache"),
+		metric.WithUnit("By"),
+	)
+	_, _ = meter.RegisterCallback(
+		func(_ context.Context, o metric.Observer) error {
+			o.ObserveInt64(used, c.usedBytes.Load())
+			o.ObserveInt64(files, c.fileCount.Load())
+			o.ObserveInt64(limit, c.maxBytes)
+			return nil
+		},
+		used, files, limit,
+	)
+	return c
+}
+
+// Reconcile rebuilds the index from the source files in the cache directory,
+// taking each file's modification time as its last use, and evicts unloaded
+// files while the cache is over its limit. Run it once the startup load has
+// settled which files are loaded.
+func (c *DiskCache) Reconcile(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	files := make(map[string]*cachedFile)
+	_ = filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !domain.IsSupportedSourceFile(path) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[path] = &cachedFile{size: info.Size(), lastUsed: info.ModTime()}
+		}
+		return nil
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files = files
+	c.updateMetrics()
+	c.logger.Info("disk cache reconciled", "dir", c.dir, "files", len(files),
+		"bytes", c.usedBytes.Load(), "max_bytes", c.maxBytes)
+	c.evictLocked(ctx, 0)
+}
+
+// Reserve frees space for a download of size bytes (0 when unknown) by
+// evicting least recently used unloaded files.
+func (c *DiskCache) Reserve(ctx context.Context, size int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictLocked(ctx, size)
+}
+
+// Add records a file that was just downloaded to path as most recently used.
+func (c *DiskCache) Add(path string) {
+	if c == nil {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[path] = &cachedFile{size: info.Size(), lastUsed: time.Now()}
+	c.updateMetrics()
+}
+
+// Touch marks path as used now; the registry calls it when a source is
+// unloaded, so the files that stopped serving last are evicted last.
+func (c *DiskCache) Touch(path string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f, ok := c.files[path]; ok {
+		f.lastUsed = time.Now()
+	}
+}
+
+// Forget drops path from the index after the registry deleted it.
+func (c *DiskCache) Forget(path string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.files, path)
+	c.updateMetrics()
+}
+
+// evictLocked removes unloaded files, least recently used first, until need
+// more bytes fit under the limit. Caller holds c.mu.
+func (c *DiskCache) evictLocked(ctx context.Context, need int64) {
+	used := c.usedBytes.Load()
+	if used+need <= c.maxBytes {
+		return
+	}
+	paths := make([]string, 0, len(c.files))
+	for path := range c.files {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return c.files[paths[i]].lastUsed.Before(c.files[paths[j]].lastUsed)
+	})
+
+	for _, path := range paths {
+		if used+need <= c.maxBytes {
+			break
+		}
+		if c.loaded.IsPathLoaded(path) {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			c.logger.Warn("failed to evict cached source", "path", path, "error", err)
+			continue
+		}
+		for _, sidecar := range domain.SidecarNames(path) {
+			_ = os.Remove(sidecar)
+		}
+		used -= c.files[path].size
+		delete(c.files, path)
+		c.evictions.Add(ctx, 1)
+		c.logger.Info("evicted cached source", "path", path)
+	}
+	c.updateMetrics()
+	if used+need > c.maxBytes {
+		c.logger.Warn("disk cache over its limit; the remaining files back loaded sources",
+			"bytes", used, "needed", need, "max_bytes", c.maxBytes)
+	}
+}
+
+// updateMetrics refreshes the gauge state from the index. Caller holds c.mu.
+func (c *DiskCache) updateMetrics() {
+	var used int64
+	for _, f := range c.files {
+		used += f.size
+	}
+	c.usedBytes.Store(used)
+	c.fileCount.Store(int64(len(c.files)))
+}
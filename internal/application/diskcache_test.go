@@ -0,0 +1,84 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fixedLoaded reports the paths in the set as loaded.
+type fixedLoaded map[string]bool
+
+func (f fixedLoaded) IsPathLoaded(path string) bool { return f[path] }
+
+func TestDiskCacheEvictsLeastRecentlyUsedUnloaded(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	oldest := write("oldest.gpkg", 100, 3*time.Hour)
+	loaded := write("loaded.gpkg", 100, 4*time.Hour)
+	older := write("older.gpkg", 100, 2*time.Hour)
+	recent := write("recent.gpkg", 100, time.Hour)
+	sidecar := filepath.Join(dir, "oldest.meta.yaml")
+	if err := os.WriteFile(sidecar, []byte("title: x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	c := NewDiskCache(dir, 300, fixedLoaded{loaded: true}, nil, testLogger())
+	c.Reconcile(context.Background())
+
+	// 400 bytes against a 300 byte cap: the oldest unloaded file goes with its
+	// sidecar; the loaded file is older still but stays.
+	if exists(oldest) || exists(sidecar) {
+		t.Error("oldest unloaded file (and its sidecar) should be evicted")
+	}
+	if !exists(loaded) || !exists(older) || !exists(recent) {
+		t.Error("loaded and newer files should stay")
+	}
+	if got := c.usedBytes.Load(); got != 300 {
+		t.Errorf("used = %d, want 300", got)
+	}
+
+	// Touching older makes recent the least recently used one.
+	c.Touch(older)
+	c.Reserve(context.Background(), 100)
+	if exists(recent) || !exists(older) {
+		t.Error("Reserve should evict the least recently used unloaded file")
+	}
+
+	added := write("added.gpkg", 100, 0)
+	c.Add(added)
+	if got := c.fileCount.Load(); got != 3 {
+		t.Errorf("files = %d, want 3", got)
+	}
+
+	// Only loaded files would be left to evict: the cache stays over its cap.
+	c.Reserve(context.Background(), 1000)
+	if !exists(loaded) {
+		t.Error("a loaded file must never be evicted")
+	}
+}
+
+func TestDiskCacheNilIsNoOp(t *testing.T) {
+	var c *DiskCache
+	c.Reconcile(context.Background())
+	c.Reserve(context.Background(), 1)
+	c.Add("x.gpkg")
+	c.Touch("x.gpkg")
+	c.Forget("x.gpkg")
+}
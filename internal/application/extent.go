@@ -0,0 +1,84 @@
+package application
+
+import (
+	"context"
+	"math"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// checkExtent implements strict extent mode. It returns an
+// *domain.OutsideExtentError when coord lies outside the extent of every layer
+// of sourceIDs, and nil when some layer covers it. A layer without a declared
+// extent (raster layers, GeoPackages with an empty gpkg_contents bbox) could
+// hold data anywhere, so its presence disables the check, as does having
+// nothing loaded at all.
+func (s *QueryService) checkExtent(ctx context.Context, coord domain.Coordinate, sourceIDs []string) error {
+	union := domain.Extent{
+		MinX: math.Inf(1), MinY: math.Inf(1),
+		MaxX: math.Inf(-1), MaxY: math.Inf(-1),
+		SRID: domain.SRIDWGS84,
+	}
+	for _, sid := range sourceIDs {
+		src, err := s.registry.GetSource(ctx, sid)
+		if err != nil {
+			// Unloaded since the query ran; it contributes nothing.
+			continue
+		}
+		for i := range src.Layers {
+			layer := &src.Layers[i]
+			if layer.Extent == nil {
+				return nil
+			}
+			if c, ok := s.transformCoordinate(ctx, coord, layer); ok && layer.Extent.Contains(c) {
+				return nil
+			}
+			if e, ok := s.extentToWGS84(ctx, *layer.Extent, layer.SRID); ok {
+				union.MinX = math.Min(union.MinX, e.MinX)
+				union.MinY = math.Min(union.MinY, e.MinY)
+				union.MaxX = math.Max(union.MaxX, e.MaxX)
+				union.MaxY = math.Max(union.MaxY, e.MaxY)
+			}
+		}
+	}
+	if !union.IsValid() {
+		// No layer extent could be expressed in WGS84; nothing to report.
+		return nil
+	}
+	return &domain.OutsideExtentError{Extent: union}
+}
+
+// extentToWGS84 returns the WGS84 bounding box of an extent given in srid, by
+// transforming its four corners. That is exact for WGS84 itself and a close
+// enough envelope for the projected systems layers use in practice.
+func (s *QueryService) extentToWGS84(ctx context.Context, e domain.Extent, srid int) (domain.Extent, bool) {
+	if srid == domain.SRIDWGS84 {
+		e.SRID = domain.SRIDWGS84
+		return e, true
+	}
+	if s.transformer == nil || !s.transformer.IsSupported(srid, domain.SRIDWGS84) {
+		return domain.Extent{}, false
+	}
+	out := domain.Extent{
+		MinX: math.Inf(1), MinY: math.Inf(1),
+		MaxX: math.Inf(-1), MaxY: math.Inf(-1),
+		SRID: domain.SRIDWGS84,
+	}
+	corners := []domain.Coordinate{
+		domain.NewCoordinate(e.MinX, e.MinY, srid),
+		domain.NewCoordinate(e.MinX, e.MaxY, srid),
+		domain.NewCoordinate(e.MaxX, e.MinY, srid),
+		domain.NewCoordinate(e.MaxX, e.MaxY, srid),
+	}
+	for _, c := range corners {
+		w, err := s.transformer.Transform(ctx, c, domain.SRIDWGS84)
+		if err != nil {
+			return domain.Extent{}, false
+		}
+		out.MinX = math.Min(out.MinX, w.X)
+		out.MinY = math.Min(out.MinY, w.Y)
+		out.MaxX = math.Max(out.MaxX, w.X)
+		out.MaxY = math.Max(out.MaxY, w.Y)
+	}
+	return out, true
+}
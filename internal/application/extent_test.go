@@ -0,0 +1,76 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+func strictService(t *testing.T, sources map[string]*domain.Source, strict bool) *QueryService {
+	t.Helper()
+	repo := &mockRepository{packages: sources, features: map[string][]domain.Feature{}}
+	reg := NewSourceRegistry([]output.SpatialSource{repo}, &mockStorage{}, testMeter(), output.NoOpTracer{}, testLogger(), "/tmp")
+	for path := range sources {
+		if err := reg.LoadSource(context.Background(), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewQueryService(reg, nil, testMeter(), output.NoOpTracer{}, testLogger(),
+		QueryServiceConfig{StrictExtent: strict})
+}
+
+func TestQueryPointStrictExtent(t *testing.T) {
+	sources := map[string]*domain.Source{
+		"/tmp/de.gpkg": {ID: "de", Path: "/tmp/de.gpkg", Layers: []domain.Layer{
+			{Name: "districts", SRID: 4326, Extent: &domain.Extent{MinX: 5, MinY: 47, MaxX: 15, MaxY: 55}},
+		}},
+		"/tmp/at.gpkg": {ID: "at", Path: "/tmp/at.gpkg", Layers: []domain.Layer{
+			{Name: "districts", SRID: 4326, Extent: &domain.Extent{MinX: 9, MinY: 46, MaxX: 17, MaxY: 49}},
+		}},
+	}
+	ctx := context.Background()
+
+	t.Run("inside an extent stays an empty 200", func(t *testing.T) {
+		resp, err := strictService(t, sources, true).QueryPoint(ctx, domain.QueryRequest{Coordinate: domain.NewWGS84Coordinate(16, 47)})
+		if err != nil || resp.TotalFeatures != 0 {
+			t.Fatalf("resp = %+v, err = %v; want empty response", resp, err)
+		}
+	})
+
+	t.Run("outside every extent is rejected with the union", func(t *testing.T) {
+		_, err := strictService(t, sources, true).QueryPoint(ctx, domain.QueryRequest{Coordinate: domain.NewWGS84Coordinate(-70, -33)})
+		var outside *domain.OutsideExtentError
+		if !errors.As(err, &outside) {
+			t.Fatalf("err = %v, want *OutsideExtentError", err)
+		}
+		want := domain.Extent{MinX: 5, MinY: 46, MaxX: 17, MaxY: 55, SRID: 4326}
+		if outside.Extent != want {
+			t.Errorf("extent = %+v, want %+v", outside.Extent, want)
+		}
+		if !errors.Is(err, domain.ErrOutsideExtent) {
+			t.Error("error does not wrap ErrOutsideExtent")
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		resp, err := strictService(t, sources, false).QueryPoint(ctx, domain.QueryRequest{Coordinate: domain.NewWGS84Coordinate(-70, -33)})
+		if err != nil || resp.TotalFeatures != 0 {
+			t.Fatalf("resp = %+v, err = %v; want empty response", resp, err)
+		}
+	})
+
+	t.Run("a layer without extent disables the check", func(t *testing.T) {
+		withRaster := map[string]*domain.Source{
+			"/tmp/de.gpkg": sources["/tmp/de.gpkg"],
+			"/tmp/dem.gpkg": {ID: "dem", Path: "/tmp/dem.gpkg", Layers: []domain.Layer{
+				{Name: "elevation", SRID: 4326},
+			}},
+		}
+		if _, err := strictService(t, withRaster, true).QueryPoint(ctx, domain.QueryRequest{Coordinate: domain.NewWGS84Coordinate(-70, -33)}); err != nil {
+			t.Errorf("err = %v, want nil", err)
+		}
+	})
+}
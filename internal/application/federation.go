@@ -0,0 +1,263 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// FederatedPeer is one configured federation peer.
+type FederatedPeer struct {
+	Peer    output.Peer
+	Timeout time.Duration // deadline of a forwarded query; also bounds the catalog fetch
+}
+
+// peerState is the last catalog fetched from a peer.
+type peerState struct {
+	sources     map[string]bool // nil until the first successful fetch
+	refreshedAt time.Time
+	err         string
+}
+
+// Federation forwards point queries to peer instances for the sources this
+// instance does not host, and keeps a catalog of what each peer serves so
+// only peers that can add something are asked. Local sources always win; of
+// two peers serving the same source, the one configured first does.
+type Federation struct {
+	peers    []FederatedPeer
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu    sync.RWMutex
+	state []peerState // parallel to peers
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// federationAnswer is what the peers added to one query.
+type federationAnswer struct {
+	results  []domain.QueryResult
+	outcomes []domain.PeerOutcome
+}
+
+// NewFederation creates the federation over peers, in priority order. The
+// catalog is fetched on Start and every interval after.
+func NewFederation(peers []FederatedPeer, interval time.Duration, logger *slog.Logger) *Federation {
+	return &Federation{
+		peers:    peers,
+		interval: interval,
+		logger:   logger,
+		state:    make([]peerState, len(peers)),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start fetches the peer catalog now and then periodically.
+func (f *Federation) Start(ctx context.Context) {
+	f.logger.Info("starting federation", "peers", len(f.peers), "refresh_interval", f.interval)
+
+	f.wg.Add(1)
+	go f.run(ctx)
+}
+
+func (f *Federation) run(ctx context.Context) {
+	defer f.wg.Done()
+	defer func() {
+		if rec := recover(); rec != nil {
+			f.logger.Error("federation panic recovered", "panic", rec)
+		}
+	}()
+
+	f.Refresh(ctx)
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-f.stopCh:
+			return
+		case <-ticker.C:
+			f.Refresh(ctx)
+		}
+	}
+}
+
+// Stop stops the catalog refresh loop.
+func (f *Federation) Stop() {
+	close(f.stopCh)
+	f.wg.Wait()
+}
+
+// Refresh fetches the source list of every peer. A failed fetch keeps the
+// peer's previous catalog, so a short outage does not drop its sources.
+func (f *Federation) Refresh(ctx context.Context) {
+	for i, p := range f.peers {
+		fetchCtx, cancel := context.WithTimeout(ctx, p.Timeout)
+		ids, err := p.Peer.SourceIDs(fetchCtx)
+		cancel()
+
+		f.mu.Lock()
+		if err != nil {
+			f.state[i].err = err.Error()
+		} else {
+			sources := make(map[string]bool, len(ids))
+			for _, id := range ids {
+				sources[id] = true
+			}
+			f.state[i] = peerState{sources: sources, refreshedAt: time.Now()}
+		}
+		f.mu.Unlock()
+
+		if err != nil {
+			f.logger.Warn("failed to fetch peer catalog", "peer", p.Peer.Name(), "error", err)
+		}
+	}
+}
+
+// Peers returns the peer catalog. It implements input.PeerCatalog.
+func (f *Federation) Peers() []input.PeerInfo {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	out := make([]input.PeerInfo, len(f.peers))
+	for i, p := range f.peers {
+		st := f.state[i]
+		ids := make([]string, 0, len(st.sources))
+		for id := range st.sources {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		out[i] = input.PeerInfo{
+			Name:        p.Peer.Name(),
+			SourceIDs:   ids,
+			RefreshedAt: st.refreshedAt,
+			Error:       st.err,
+		}
+	}
+	return out
+}
+
+// Serves reports whether some peer serves sourceID.
+func (f *Federation) Serves(sourceID string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for i := range f.state {
+		if f.state[i].sources[sourceID] {
+			return true
+		}
+	}
+	return false
+}
+
+// assign maps every source not in local to the first peer serving it, and
+// returns the peers that got at least one, each with the ids it answers for.
+// A source-specific request (req.SourceID) goes to its first peer only.
+func (f *Federation) assign(req domain.QueryRequest, local map[string]bool) map[int]map[string]bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	taken := make(map[string]bool)
+	out := make(map[int]map[string]bool)
+	for i := range f.peers {
+		for id := range f.state[i].sources {
+			if local[id] || taken[id] || (req.SourceID != "" && id != req.SourceID) {
+				continue
+			}
+			taken[id] = true
+			if out[i] == nil {
+				out[i] = make(map[string]bool)
+			}
+			out[i][id] = true
+		}
+	}
+	return out
+}
+
+// Query forwards req to every peer serving a source that is not in local, in
+// parallel and each under its own timeout, and keeps from each answer only
+// the sources assigned to that peer.
+func (f *Federation) Query(ctx context.Context, req domain.QueryRequest, local map[string]bool) federationAnswer {
+	assigned := f.assign(req, local)
+	if len(assigned) == 0 {
+		return federationAnswer{}
+	}
+
+	type reply struct {
+		results []domain.QueryResult
+		outcome domain.PeerOutcome
+	}
+	replies := make([]*reply, len(f.peers))
+	var wg sync.WaitGroup
+	for i, ids := range assigned {
+		p := f.peers[i]
+		replies[i] = &reply{}
+		wg.Add(1)
+		go func(r *reply, ids map[string]bool) {
+			defer wg.Done()
+			defer func() {
+				if rec := recover(); rec != nil {
+					f.logger.Error("peer query panic recovered", "peer", p.Peer.Name(), "panic", rec)
+					r.outcome = domain.PeerOutcome{Peer: p.Peer.Name(), Status: domain.PeerStatusError}
+				}
+			}()
+			r.results, r.outcome = f.queryPeer(ctx, p, req, ids)
+		}(replies[i], ids)
+	}
+	wg.Wait()
+
+	var answer federationAnswer
+	for _, r := range replies {
+		if r == nil {
+			continue
+		}
+		answer.results = append(answer.results, r.results...)
+		answer.outcomes = append(answer.outcomes, r.outcome)
+	}
+	return answer
+}
+
+// queryPeer runs req on one peer and keeps the results of the sources in ids.
+func (f *Federation) queryPeer(ctx context.Context, p FederatedPeer, req domain.QueryRequest, ids map[string]bool) ([]domain.QueryResult, domain.PeerOutcome) {
+	outcome := domain.PeerOutcome{Peer: p.Peer.Name(), Status: domain.PeerStatusOK}
+	start := time.Now()
+
+	peerCtx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+	resp, err := p.Peer.QueryPoint(peerCtx, req)
+	outcome.Duration = time.Since(start)
+	if err != nil {
+		outcome.Status = domain.PeerStatusError
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(peerCtx.Err(), context.DeadlineExceeded) {
+			outcome.Status = domain.PeerStatusTimeout
+		}
+		if isCanceled(err) {
+			f.logger.Debug("peer query canceled", "peer", outcome.Peer, "error", err)
+		} else {
+			f.logger.Warn("peer query failed", "peer", outcome.Peer, "status", outcome.Status, "error", err)
+		}
+		return nil, outcome
+	}
+
+	var results []domain.QueryResult
+	for _, r := range resp.Results {
+		if !ids[r.SourceID] || !r.HasFeatures() {
+			continue
+		}
+		r.Peer = outcome.Peer
+		outcome.Features += r.FeatureCount()
+		results = append(results, r)
+	}
+	outcome.Incomplete = resp.Incomplete
+	return results, outcome
+}
@@ -0,0 +1,156 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// fakePeer serves a fixed source list and answers every query with one
+// feature per source, or with err. It remembers the last request.
+type fakePeer struct {
+	name    string
+	sources []string
+	err     error
+	block   bool // wait for the deadline instead of answering
+	last    domain.QueryRequest
+}
+
+func (p *fakePeer) Name() string { return p.name }
+
+func (p *fakePeer) SourceIDs(context.Context) ([]string, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.sources, nil
+}
+
+func (p *fakePeer) QueryPoint(ctx context.Context, req domain.QueryRequest) (*domain.QueryResponse, error) {
+	p.last = req
+	if p.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	resp := &domain.QueryResponse{Coordinate: req.Coordinate}
+	for _, id := range p.sources {
+		if req.SourceID != "" && id != req.SourceID {
+			continue
+		}
+		resp.AddResult(domain.QueryResult{
+			SourceID: id,
+			Features: []domain.Feature{{ID: 1, LayerName: p.name}},
+		})
+	}
+	return resp, nil
+}
+
+func newTestFederation(peers ...*fakePeer) *Federation {
+	fp := make([]FederatedPeer, len(peers))
+	for i, p := range peers {
+		fp[i] = FederatedPeer{Peer: p, Timeout: 50 * time.Millisecond}
+	}
+	f := NewFederation(fp, time.Minute, testLogger())
+	f.Refresh(context.Background())
+	return f
+}
+
+func TestFederationQueryAssignsEachSourceOnce(t *testing.T) {
+	by := &fakePeer{name: "by", sources: []string{"local", "alkis_by", "shared"}}
+	sn := &fakePeer{name: "sn", sources: []string{"shared", "alkis_sn"}}
+	idle := &fakePeer{name: "idle", sources: []string{"local"}}
+	f := newTestFederation(by, sn, idle)
+
+	answer := f.Query(context.Background(), domain.QueryRequest{}, map[string]bool{"local": true})
+
+	got := map[string]string{}
+	for _, r := range answer.results {
+		got[r.SourceID] = r.Peer
+	}
+	want := map[string]string{"alkis_by": "by", "shared": "by", "alkis_sn": "sn"}
+	if len(got) != len(want) {
+		t.Fatalf("results = %v, want %v", got, want)
+	}
+	for id, peer := range want {
+		if got[id] != peer {
+			t.Errorf("%s answered by %q, want %q", id, got[id], peer)
+		}
+	}
+	// "idle" only serves what is local: it is not asked at all.
+	if len(answer.outcomes) != 2 || answer.outcomes[0].Peer != "by" || answer.outcomes[1].Features != 1 {
+		t.Errorf("outcomes = %+v", answer.outcomes)
+	}
+	if idle.last.Coordinate != (domain.Coordinate{}) || idle.last.SourceID != "" {
+		t.Errorf("idle peer was queried: %+v", idle.last)
+	}
+}
+
+func TestFederationQueryPeerFailures(t *testing.T) {
+	slow := &fakePeer{name: "slow", sources: []string{"a"}, block: true}
+	broken := &fakePeer{name: "broken", sources: []string{"b"}}
+	f := newTestFederation(slow, broken)
+	broken.err = errors.New("boom") // after the catalog fetch
+
+	answer := f.Query(context.Background(), domain.QueryRequest{}, nil)
+	if len(answer.results) != 0 {
+		t.Errorf("results = %+v, want none", answer.results)
+	}
+	status := map[string]string{}
+	for _, o := range answer.outcomes {
+		status[o.Peer] = o.Status
+	}
+	if status["slow"] != domain.PeerStatusTimeout || status["broken"] != domain.PeerStatusError {
+		t.Errorf("statuses = %v", status)
+	}
+
+	// A failed catalog fetch keeps the previous list and records the error.
+	f.Refresh(context.Background())
+	infos := f.Peers()
+	if infos[1].Error == "" || len(infos[1].SourceIDs) != 1 || infos[1].RefreshedAt.IsZero() {
+		t.Errorf("catalog after failed refresh = %+v", infos[1])
+	}
+}
+
+func TestQueryPointForwardsToPeers(t *testing.T) {
+	reg := newTestRegistry()
+	setSources(reg, map[string]*sourceEntry{"local": readyEntry("local")})
+	svc := newTestQueryService(reg)
+	peer := &fakePeer{name: "by", sources: []string{"local", "alkis_by"}}
+	svc.SetFederation(newTestFederation(peer))
+
+	coord := domain.Coordinate{X: 11.5, Y: 48.1, SRID: 4326}
+	resp, err := svc.QueryPoint(context.Background(), domain.QueryRequest{Coordinate: coord})
+	if err != nil {
+		t.Fatalf("QueryPoint: %v", err)
+	}
+	if resp.TotalFeatures != 1 || resp.Results[0].SourceID != "alkis_by" || resp.Results[0].Peer != "by" {
+		t.Errorf("results = %+v, want alkis_by from peer by", resp.Results)
+	}
+	if resp.Incomplete || len(resp.Peers) != 1 || resp.Peers[0].Status != domain.PeerStatusOK {
+		t.Errorf("incomplete = %v, peers = %+v", resp.Incomplete, resp.Peers)
+	}
+
+	// A source only the peer serves is queryable by id.
+	resp, err = svc.QueryPoint(context.Background(), domain.QueryRequest{Coordinate: coord, SourceID: "alkis_by"})
+	if err != nil || resp.TotalFeatures != 1 || peer.last.SourceID != "alkis_by" {
+		t.Errorf("source query: err = %v, resp = %+v, forwarded = %+v", err, resp, peer.last)
+	}
+
+	// A forwarded query is answered locally only.
+	_, err = svc.QueryPoint(context.Background(), domain.QueryRequest{Coordinate: coord, SourceID: "alkis_by", NoForward: true})
+	if !errors.Is(err, domain.ErrSourceNotFound) {
+		t.Errorf("NoForward source query err = %v, want ErrSourceNotFound", err)
+	}
+
+	// A failing peer costs its results, not the request.
+	peer.err = errors.New("boom")
+	resp, err = svc.QueryPoint(context.Background(), domain.QueryRequest{Coordinate: coord})
+	if err != nil || !resp.Incomplete || resp.Peers[0].Status != domain.PeerStatusError {
+		t.Errorf("failing peer: err = %v, resp = %+v", err, resp)
+	}
+}
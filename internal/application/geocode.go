@@ -0,0 +1,119 @@
+package application
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// Bounds of the number of matches one address search returns.
+const (
+	DefaultGeocodeLimit = 5
+	MaxGeocodeLimit     = 20
+)
+
+// geocodeCacheSize bounds the answers GeocodeService keeps.
+const geocodeCacheSize = 1024
+
+// GeocodeService searches addresses through the configured geocoder. It
+// checks the search text, bounds the number of matches and keeps answers for
+// cacheTTL, so a search repeated by one or many users reaches the external
+// service once; public services such as nominatim.openstreetmap.org ask for
+// that.
+type GeocodeService struct {
+	geocoder output.Geocoder
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]geocodeAnswer
+}
+
+type geocodeAnswer struct {
+	matches []domain.GeocodeMatch
+	expires time.Time
+}
+
+// NewGeocodeService creates the address search over geocoder. cacheTTL 0
+// caches nothing.
+func NewGeocodeService(geocoder output.Geocoder, cacheTTL time.Duration) *GeocodeService {
+	return &GeocodeService{
+		geocoder: geocoder,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		cache:    make(map[string]geocodeAnswer),
+	}
+}
+
+// Geocode returns up to limit places matching query, best match first. limit
+// 0 takes DefaultGeocodeLimit, and more than MaxGeocodeLimit is cut to it.
+func (s *GeocodeService) Geocode(ctx context.Context, query string, limit int) ([]domain.GeocodeMatch, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" {
+		return nil, &domain.ValidationError{Field: "q", Value: query, Constraint: "non-empty", Message: "q must name an address or place"}
+	}
+	switch {
+	case limit < 0:
+		return nil, &domain.ValidationError{Field: "limit", Value: limit, Constraint: ">= 0", Message: "limit must be a positive integer"}
+	case limit == 0:
+		limit = DefaultGeocodeLimit
+	case limit > MaxGeocodeLimit:
+		limit = MaxGeocodeLimit
+	}
+
+	key := strconv.Itoa(limit) + " " + strings.ToLower(query)
+	if matches, ok := s.cached(key); ok {
+		return matches, nil
+	}
+	matches, err := s.geocoder.Geocode(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	s.store(key, matches)
+	return matches, nil
+}
+
+// License returns the license of the geocoder's data.
+func (s *GeocodeService) License() domain.License {
+	return s.geocoder.License()
+}
+
+func (s *GeocodeService) cached(key string) ([]domain.GeocodeMatch, bool) {
+	if s.cacheTTL <= 0 {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.cache[key]
+	if !ok || !s.now().Before(a.expires) {
+		return nil, false
+	}
+	return a.matches, true
+}
+
+// store caches matches under key. A full cache first drops its expired
+// answers, and all of them if none has expired.
+func (s *GeocodeService) store(key string, matches []domain.GeocodeMatch) {
+	if s.cacheTTL <= 0 {
+		return
+	}
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.cache) >= geocodeCacheSize {
+		for k, a := range s.cache {
+			if !now.Before(a.expires) {
+				delete(s.cache, k)
+			}
+		}
+		if len(s.cache) >= geocodeCacheSize {
+			clear(s.cache)
+		}
+	}
+	s.cache[key] = geocodeAnswer{matches: matches, expires: now.Add(s.cacheTTL)}
+}
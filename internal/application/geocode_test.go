@@ -0,0 +1,82 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+type countingGeocoder struct {
+	calls     int
+	lastQuery string
+	lastLimit int
+	err       error
+}
+
+func (g *countingGeocoder) Geocode(_ context.Context, query string, limit int) ([]domain.GeocodeMatch, error) {
+	g.calls++
+	g.lastQuery, g.lastLimit = query, limit
+	if g.err != nil {
+		return nil, g.err
+	}
+	return []domain.GeocodeMatch{{Label: query, Coordinate: domain.NewWGS84Coordinate(9.93, 49.79)}}, nil
+}
+
+func (g *countingGeocoder) License() domain.License {
+	return domain.License{Name: "ODbL 1.0"}
+}
+
+func TestGeocodeService(t *testing.T) {
+	g := &countingGeocoder{}
+	s := NewGeocodeService(g, time.Hour)
+	now := time.Unix(1_000_000, 0)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := s.Geocode(ctx, "  Residenzplatz 2,\tWürzburg ", 0); err != nil {
+		t.Fatalf("Geocode() error = %v", err)
+	}
+	if g.lastQuery != "Residenzplatz 2, Würzburg" || g.lastLimit != DefaultGeocodeLimit {
+		t.Errorf("geocoder got %q, %d; want the trimmed query and the default limit", g.lastQuery, g.lastLimit)
+	}
+
+	// The same search, in another case, is answered from the cache.
+	if _, err := s.Geocode(ctx, "residenzplatz 2, würzburg", 0); err != nil || g.calls != 1 {
+		t.Errorf("repeated search: calls = %d, err = %v; want 1 call", g.calls, err)
+	}
+	_, _ = s.Geocode(ctx, "residenzplatz 2, würzburg", 1)
+	if g.calls != 2 {
+		t.Errorf("another limit: calls = %d, want 2", g.calls)
+	}
+	now = now.Add(time.Hour)
+	_, _ = s.Geocode(ctx, "residenzplatz 2, würzburg", 0)
+	if g.calls != 3 {
+		t.Errorf("after the TTL: calls = %d, want 3", g.calls)
+	}
+
+	_, _ = s.Geocode(ctx, "Würzburg", 500)
+	if g.lastLimit != MaxGeocodeLimit {
+		t.Errorf("limit 500 reached the geocoder as %d, want %d", g.lastLimit, MaxGeocodeLimit)
+	}
+	for _, tc := range []struct {
+		query string
+		limit int
+	}{{" \t", 0}, {"Würzburg", -1}} {
+		if _, err := s.Geocode(ctx, tc.query, tc.limit); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("Geocode(%q, %d) error = %v, want ErrInvalidInput", tc.query, tc.limit, err)
+		}
+	}
+
+	// Failures are not cached.
+	g.err = domain.ErrUnavailable
+	if _, err := s.Geocode(ctx, "Berlin", 0); !errors.Is(err, domain.ErrUnavailable) {
+		t.Errorf("failing geocoder: error = %v, want ErrUnavailable", err)
+	}
+	g.err = nil
+	if m, err := s.Geocode(ctx, "Berlin", 0); err != nil || len(m) != 1 {
+		t.Errorf("after a failure: %v, %v; want the geocoder asked again", m, err)
+	}
+}
@@ -2,9 +2,11 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -27,10 +29,10 @@ type countingProvider struct {
 }
 
 func (p *countingProvider) Supports(path string) bool { return strings.HasSuffix(path, p.ext) }
-func (p *countingProvider) Open(_ context.Context, path string) (*domain.Source, error) {
+func (p *countingProvider) Open(_ context.Context, id, path string) (*domain.Source, error) {
 	p.opens++
 	return &domain.Source{
-		ID:      domain.DeriveSourceID(path),
+		ID:      id,
 		Path:    path,
 		Name:    fmt.Sprintf("v%d", p.version),
 		Kind:    domain.SourceKindVector,
@@ -79,9 +81,9 @@ func TestLoadSourceReloadsModifiedSource(t *testing.T) {
 type blockingProvider struct{}
 
 func (blockingProvider) Supports(path string) bool { return strings.HasSuffix(path, ".gpkg") }
-func (blockingProvider) Open(_ context.Context, path string) (*domain.Source, error) {
+func (blockingProvider) Open(_ context.Context, id, path string) (*domain.Source, error) {
 	return &domain.Source{
-		ID: domain.DeriveSourceID(path), Path: path, Kind: domain.SourceKindVector, Indexed: true,
+		ID: id, Path: path, Kind: domain.SourceKindVector, Indexed: true,
 		Layers: []domain.Layer{{Name: "l", SRID: 4326, HasIndex: true}},
 	}, nil
 }
@@ -126,6 +128,9 @@ func TestQueryTimeoutIsEnforced(t *testing.T) {
 		if resp.TotalFeatures != 0 {
 			t.Errorf("TotalFeatures = %d, want 0 (timed-out adapter yields no features)", resp.TotalFeatures)
 		}
+		if !resp.Incomplete {
+			t.Error("Incomplete = false, want true (deadline cut the query short)")
+		}
 	case <-time.After(5 * time.Second):
 		t.Fatal("QueryPoint blocked past 5s — timeout not enforced")
 	}
@@ -146,3 +151,38 @@ func TestSafeLocalPath(t *testing.T) {
 		}
 	}
 }
+
+// stubLocalObjects serves keys "<dir>/<file>" from /srv/<dir>/<file>.
+type stubLocalObjects struct{}
+
+func (stubLocalObjects) LocalPath(key string) (string, error) {
+	if strings.Contains(key, "..") {
+		return "", errors.New("escapes")
+	}
+	return filepath.Join("/srv", key), nil
+}
+
+func (stubLocalObjects) ObjectKey(path string) (string, bool) {
+	rel, err := filepath.Rel("/srv", path)
+	return rel, err == nil && !strings.HasPrefix(rel, "..")
+}
+
+func TestSafeLocalPathUsesLocalObjects(t *testing.T) {
+	reg := newRoutingRegistry(nil)
+	reg.SetLocalObjects(stubLocalObjects{})
+	ids, err := domain.NewSourceIDDeriver(domain.SourceIDPrefixed, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.SetSourceIDDeriver(ids)
+
+	if got, err := reg.safeLocalPath("staging/a.gpkg"); err != nil || got != "/srv/staging/a.gpkg" {
+		t.Errorf("safeLocalPath = %q, %v; want the file in place", got, err)
+	}
+	if _, err := reg.safeLocalPath("../a.gpkg"); err == nil {
+		t.Error("safeLocalPath accepted a key the storage rejects")
+	}
+	if id := reg.DeriveSourceID("/srv/staging/a.gpkg"); id != "staging.a" {
+		t.Errorf("DeriveSourceID = %q, want the key derived through the storage", id)
+	}
+}
@@ -2,6 +2,9 @@ package application
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/jobrunner/ortus/internal/domain"
 	"github.com/jobrunner/ortus/internal/ports/input"
@@ -15,6 +18,13 @@ type sourceInspector interface {
 	ListSources(ctx context.Context) ([]domain.Source, error)
 	GetSourceStatus(ctx context.Context, id string) (domain.SourceStatus, error)
 	InitialLoadComplete() bool
+	LoadProgress() input.LoadProgress
+}
+
+// storageCircuit reports the state of the storage circuit breaker: "closed"
+// while the backend answers, "open" or "half_open" while it is failing.
+type storageCircuit interface {
+	State() string
 }
 
 // HealthService provides health check functionality.
@@ -25,6 +35,28 @@ type HealthService struct {
 	// source still reports ready ("no data today"). When false, readiness
 	// additionally requires at least one ready source.
 	readyWhenEmpty bool
+	// maintenance, when on, fails readiness so load balancers drain traffic.
+	maintenance *MaintenanceMode
+	// starting fails readiness while the background startup (source load,
+	// gazetteer warmup) runs.
+	starting atomic.Bool
+	// storage, when set, reports the storage component degraded while its
+	// circuit breaker is not closed. Readiness is unaffected: the loaded
+	// sources keep answering from their local copies.
+	storage storageCircuit
+	// probe, when set, reports the storage component from its periodic
+	// checks. Like the circuit, it does not affect readiness.
+	probe *StorageProbe
+	// leader, when set, reports this replica's sync role.
+	leader leadership
+
+	// startup tracks the startup pass for /health/startup: when it began,
+	// whether it ended, and how long it may take (0 = unbounded).
+	startupMu      sync.Mutex
+	startupBegan   time.Time
+	startupDone    bool
+	startupTimeout time.Duration
+	now            func() time.Time // replaced in tests
 }
 
 // NewHealthService creates a new health service. readyWhenEmpty controls the
@@ -37,7 +69,75 @@ func NewHealthService(registry sourceInspector, readyWhenEmpty bool, tracer outp
 		registry:       registry,
 		tracer:         tracer,
 		readyWhenEmpty: readyWhenEmpty,
+		now:            time.Now,
+	}
+}
+
+// SetMaintenance installs the maintenance switch that fails readiness while
+// it is on. nil removes it.
+func (s *HealthService) SetMaintenance(m *MaintenanceMode) {
+	s.maintenance = m
+}
+
+// SetStorageCircuit installs the storage circuit breaker whose state /health
+// reports. nil removes it.
+func (s *HealthService) SetStorageCircuit(c storageCircuit) {
+	s.storage = c
+}
+
+// SetStorageProbe installs the storage probe whose checks /health reports.
+// nil removes it.
+func (s *HealthService) SetStorageProbe(p *StorageProbe) {
+	s.probe = p
+}
+
+// SetLeadership installs the leader election whose outcome /health reports
+// as the sync_role component. nil removes it.
+func (s *HealthService) SetLeadership(l leadership) {
+	s.leader = l
+}
+
+// SetStartupTimeout sets how long the startup pass may take before
+// StartupStatus reports it timed out. 0 waits indefinitely.
+func (s *HealthService) SetStartupTimeout(d time.Duration) {
+	s.startupMu.Lock()
+	defer s.startupMu.Unlock()
+	s.startupTimeout = d
+}
+
+// SetStarting marks the background startup as running (true) or done
+// (false). Readiness is false while it runs, even with sources ready.
+func (s *HealthService) SetStarting(on bool) {
+	s.startupMu.Lock()
+	if on {
+		s.startupBegan = s.now()
+	} else if !s.startupBegan.IsZero() {
+		s.startupDone = true
+	}
+	s.startupMu.Unlock()
+	s.starting.Store(on)
+}
+
+// StartupStatus reports whether the startup pass (initial source load and
+// gazetteer warmup) has ended. Unlike readiness it latches: once started, the
+// instance stays started, whatever maintenance mode or later syncs do.
+func (s *HealthService) StartupStatus(ctx context.Context) input.StartupStatus {
+	_, span := s.tracer.Start(ctx, "HealthService.StartupStatus")
+	defer span.End()
+
+	s.startupMu.Lock()
+	st := input.StartupStatus{Started: s.startupDone}
+	if !s.startupBegan.IsZero() {
+		st.Elapsed = s.now().Sub(s.startupBegan)
+		st.TimedOut = !st.Started && s.startupTimeout > 0 && st.Elapsed >= s.startupTimeout
+	}
+	s.startupMu.Unlock()
+	if !st.Started {
+		st.Load = s.registry.LoadProgress()
 	}
+
+	span.SetAttributes(output.Bool("health.started", st.Started), output.Bool("health.startup_timed_out", st.TimedOut))
+	return st
 }
 
 // IsHealthy returns true if the service is healthy.
@@ -53,6 +153,20 @@ func (s *HealthService) IsReady(ctx context.Context) bool {
 	ctx, span := s.tracer.Start(ctx, "HealthService.IsReady")
 	defer span.End()
 
+	// Maintenance overrides everything: the instance should drain even with
+	// every source ready. Liveness is unaffected.
+	if s.maintenance.Active() {
+		span.SetAttributes(output.Bool("health.ready", false), output.String("health.reason", "maintenance"))
+		return false
+	}
+
+	// The startup load runs in the background behind a live listener; the
+	// sources it has brought up so far are not the full set yet.
+	if s.starting.Load() {
+		span.SetAttributes(output.Bool("health.ready", false), output.String("health.reason", "startup"))
+		return false
+	}
+
 	sources, err := s.registry.ListSources(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -116,8 +230,12 @@ func (s *HealthService) GetHealthDetails(ctx context.Context) input.HealthDetail
 		states = append(states, input.SourceState{ID: src.ID, Status: string(st), Ready: isReady})
 	}
 
-	components := map[string]string{
-		"storage": "ok",
+	components := s.storageComponents()
+	if s.maintenance.Active() {
+		components["maintenance"] = "active"
+	}
+	if s.leader != nil {
+		components["sync_role"] = syncRole(s.leader.IsLeader())
 	}
 
 	span.SetAttributes(
@@ -132,7 +250,42 @@ func (s *HealthService) GetHealthDetails(ctx context.Context) input.HealthDetail
 		SourcesReady:  ready,
 		Components:    components,
 		Sources:       states,
+		Load:          s.registry.LoadProgress(),
+	}
+}
+
+// storageComponents reports the storage component: ok without a probe,
+// otherwise the probe's status with its last error and success, degraded at
+// best while the circuit breaker is not closed.
+func (s *HealthService) storageComponents() map[string]string {
+	components := map[string]string{"storage": StorageStatusOK}
+	if s.probe != nil {
+		st := s.probe.Status()
+		components["storage"] = st.Status
+		if st.LastError != "" {
+			components["storage_last_error"] = st.LastError
+		}
+		if !st.LastSuccess.IsZero() {
+			components["storage_last_success"] = st.LastSuccess.UTC().Format(time.RFC3339)
+		}
+	}
+	if s.storage != nil {
+		if state := s.storage.State(); state != "closed" {
+			if components["storage"] != StorageStatusDown {
+				components["storage"] = StorageStatusDegraded
+			}
+			components["storage_circuit"] = state
+		}
+	}
+	return components
+}
+
+// syncRole names a replica's role under leader election.
+func syncRole(leader bool) string {
+	if leader {
+		return "leader"
 	}
+	return "follower"
 }
 
 // SourceHealth contains health info for a single source.
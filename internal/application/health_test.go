@@ -2,9 +2,11 @@ package application
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/jobrunner/ortus/internal/domain"
 	"github.com/jobrunner/ortus/internal/ports/output"
@@ -122,6 +124,51 @@ func TestHealthServiceIsReady(t *testing.T) {
 	}
 }
 
+// TestHealthServiceNotReadyWhileStarting: during the background startup the
+// sources loaded so far do not make the instance ready.
+func TestHealthServiceNotReadyWhileStarting(t *testing.T) {
+	registry := newTestRegistry()
+	setSources(registry, map[string]*sourceEntry{"a": readyEntry("a")})
+	service := NewHealthService(registry, true, output.NoOpTracer{})
+
+	service.SetStarting(true)
+	if service.IsReady(context.Background()) {
+		t.Error("IsReady() = true during startup, want false")
+	}
+	service.SetStarting(false)
+	if !service.IsReady(context.Background()) {
+		t.Error("IsReady() = false after startup with a ready source, want true")
+	}
+}
+
+// TestHealthServiceStartupStatus: not started before and during the startup
+// pass, timed out once it overruns the timeout, started afterwards.
+func TestHealthServiceStartupStatus(t *testing.T) {
+	service := NewHealthService(newTestRegistry(), true, output.NoOpTracer{})
+	service.SetStartupTimeout(time.Minute)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if st := service.StartupStatus(ctx); st.Started || st.TimedOut {
+		t.Errorf("before Start: %+v, want not started, not timed out", st)
+	}
+	service.SetStarting(true)
+	now = now.Add(30 * time.Second)
+	if st := service.StartupStatus(ctx); st.Started || st.TimedOut || st.Elapsed != 30*time.Second {
+		t.Errorf("during startup: %+v, want starting for 30s", st)
+	}
+	now = now.Add(30 * time.Second)
+	if st := service.StartupStatus(ctx); st.Started || !st.TimedOut {
+		t.Errorf("at the timeout: %+v, want timed out", st)
+	}
+	service.SetStarting(false)
+	now = now.Add(time.Hour)
+	if st := service.StartupStatus(ctx); !st.Started || st.TimedOut {
+		t.Errorf("after startup: %+v, want started", st)
+	}
+}
+
 func TestHealthServiceGetHealthDetails(t *testing.T) {
 	registry := newTestRegistry()
 	markLoaded(registry)
@@ -152,6 +199,84 @@ func TestHealthServiceGetHealthDetails(t *testing.T) {
 	}
 }
 
+type fixedCircuit string
+
+func (c fixedCircuit) State() string { return string(c) }
+
+// TestHealthServiceStorageCircuit: a storage circuit breaker that is not
+// closed degrades the storage component, but not readiness.
+func TestHealthServiceStorageCircuit(t *testing.T) {
+	registry := newTestRegistry()
+	markLoaded(registry)
+	setSources(registry, map[string]*sourceEntry{"a": readyEntry("a")})
+	service := NewHealthService(registry, true, output.NoOpTracer{})
+
+	service.SetStorageCircuit(fixedCircuit("closed"))
+	if got := service.GetHealthDetails(context.Background()).Components["storage"]; got != "ok" {
+		t.Errorf("Components[storage] with a closed circuit = %q, want ok", got)
+	}
+
+	service.SetStorageCircuit(fixedCircuit("open"))
+	details := service.GetHealthDetails(context.Background())
+	if details.Components["storage"] != "degraded" || details.Components["storage_circuit"] != "open" {
+		t.Errorf("Components with an open circuit = %v, want storage degraded, storage_circuit open", details.Components)
+	}
+	if !details.Ready || !details.Healthy {
+		t.Errorf("Ready, Healthy = %v, %v with an open circuit, want true, true", details.Ready, details.Healthy)
+	}
+}
+
+// TestHealthServiceStorageProbe: the probe's status, last error and last
+// success appear under components; an open circuit does not lift "down".
+func TestHealthServiceStorageProbe(t *testing.T) {
+	registry := newTestRegistry()
+	markLoaded(registry)
+	service := NewHealthService(registry, true, output.NoOpTracer{})
+	storage := &mockStorage{}
+	probe := newTestProbe(storage, "")
+	service.SetStorageProbe(probe)
+
+	if got := service.GetHealthDetails(context.Background()).Components["storage"]; got != StorageStatusUnknown {
+		t.Errorf("Components[storage] before a check = %q, want unknown", got)
+	}
+	probe.Check(context.Background())
+	components := service.GetHealthDetails(context.Background()).Components
+	if components["storage"] != StorageStatusOK || components["storage_last_success"] == "" {
+		t.Errorf("Components after a successful check = %v, want ok with storage_last_success", components)
+	}
+
+	storage.listErr = errors.New("403 Forbidden")
+	for range storageDownAfter {
+		probe.Check(context.Background())
+	}
+	service.SetStorageCircuit(fixedCircuit("open"))
+	components = service.GetHealthDetails(context.Background()).Components
+	if components["storage"] != StorageStatusDown || components["storage_last_error"] != "403 Forbidden" {
+		t.Errorf("Components after failing checks = %v, want down with storage_last_error", components)
+	}
+}
+
+// TestHealthServiceSyncRole: with leader election the replica's role appears
+// under components.
+func TestHealthServiceSyncRole(t *testing.T) {
+	service := NewHealthService(newTestRegistry(), true, output.NoOpTracer{})
+	if _, ok := service.GetHealthDetails(context.Background()).Components["sync_role"]; ok {
+		t.Error("Components[sync_role] present without leader election")
+	}
+	lease := &fakeLease{holder: "b"}
+	e := NewLeaderElector(lease, "a", time.Minute, slog.New(slog.DiscardHandler))
+	service.SetLeadership(e)
+	e.round(context.Background())
+	if got := service.GetHealthDetails(context.Background()).Components["sync_role"]; got != "follower" {
+		t.Errorf("Components[sync_role] = %q, want follower", got)
+	}
+	lease.holder = "a"
+	e.round(context.Background())
+	if got := service.GetHealthDetails(context.Background()).Components["sync_role"]; got != "leader" {
+		t.Errorf("Components[sync_role] = %q, want leader", got)
+	}
+}
+
 func TestHealthServiceGetSourceHealth(t *testing.T) {
 	registry := newTestRegistry()
 	service := NewHealthService(registry, true, output.NoOpTracer{})
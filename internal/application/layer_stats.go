@@ -0,0 +1,75 @@
+package application
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// layerStats learns, per source layer, how often a point query returns
+// features. The query service uses the hit-rate to order layers when a request
+// deadline is tight, so the layers most likely to answer run first.
+type layerStats struct {
+	mu   sync.RWMutex
+	hits map[layerKey]*layerHits
+}
+
+type layerKey struct {
+	source string
+	layer  string
+}
+
+type layerHits struct {
+	queries int64
+	hits    int64
+}
+
+func newLayerStats() *layerStats {
+	return &layerStats{hits: make(map[layerKey]*layerHits)}
+}
+
+// record counts one completed layer query; hit reports whether it returned
+// any features.
+func (s *layerStats) record(sourceID, layer string, hit bool) {
+	k := layerKey{source: sourceID, layer: layer}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.hits[k]
+	if !ok {
+		h = &layerHits{}
+		s.hits[k] = h
+	}
+	h.queries++
+	if hit {
+		h.hits++
+	}
+}
+
+// hitRate returns the smoothed hit-rate (hits+1)/(queries+2): an unseen layer
+// starts at 0.5 rather than 0, so a new layer is not starved behind layers
+// that rarely hit.
+func (s *layerStats) hitRate(sourceID, layer string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	h, ok := s.hits[layerKey{source: sourceID, layer: layer}]
+	if !ok {
+		return 0.5
+	}
+	return float64(h.hits+1) / float64(h.queries+2)
+}
+
+// prioritize returns a copy of layers ordered by descending hit-rate. The sort
+// is stable, so layers with equal rates keep their package order.
+func (s *layerStats) prioritize(sourceID string, layers []domain.Layer) []domain.Layer {
+	rates := make(map[string]float64, len(layers))
+	for i := range layers {
+		rates[layers[i].Name] = s.hitRate(sourceID, layers[i].Name)
+	}
+	out := make([]domain.Layer, len(layers))
+	copy(out, layers)
+	sort.SliceStable(out, func(i, j int) bool {
+		return rates[out[i].Name] > rates[out[j].Name]
+	})
+	return out
+}
@@ -0,0 +1,127 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+func TestLayerStatsHitRate(t *testing.T) {
+	s := newLayerStats()
+	if got := s.hitRate("src", "l"); got != 0.5 {
+		t.Errorf("unseen hitRate = %v, want 0.5", got)
+	}
+	s.record("src", "l", true)
+	s.record("src", "l", true)
+	s.record("src", "l", false)
+	if got, want := s.hitRate("src", "l"), 3.0/5.0; got != want {
+		t.Errorf("hitRate = %v, want %v", got, want)
+	}
+	// Stats are per source: the same layer name elsewhere is unseen.
+	if got := s.hitRate("other", "l"); got != 0.5 {
+		t.Errorf("other source hitRate = %v, want 0.5", got)
+	}
+}
+
+func TestLayerStatsPrioritize(t *testing.T) {
+	s := newLayerStats()
+	for range 4 {
+		s.record("src", "rare", false)
+		s.record("src", "often", true)
+	}
+	layers := []domain.Layer{{Name: "rare"}, {Name: "new"}, {Name: "often"}, {Name: "new2"}}
+
+	got := s.prioritize("src", layers)
+	want := []string{"often", "new", "new2", "rare"}
+	for i, l := range got {
+		if l.Name != want[i] {
+			t.Fatalf("order = %v, want %v", layerNames(got), want)
+		}
+	}
+	if layers[0].Name != "rare" {
+		t.Error("prioritize must not reorder the caller's slice")
+	}
+}
+
+// TestQueryPrioritizesLayersWhenDeadlineTight verifies layer order follows the
+// learned hit-rate only once the remaining time drops below PrioritizeWithin.
+func TestQueryPrioritizesLayersWhenDeadlineTight(t *testing.T) {
+	repo := &mockRepository{
+		packages: map[string]*domain.Source{
+			"/tmp/src.gpkg": {ID: "src", Path: "/tmp/src.gpkg", Layers: []domain.Layer{
+				{Name: "first", SRID: 4326}, {Name: "second", SRID: 4326},
+			}},
+		},
+		features: map[string][]domain.Feature{
+			"src:first":  {{ID: 1, LayerName: "first"}},
+			"src:second": {{ID: 2, LayerName: "second"}},
+		},
+	}
+	reg := NewSourceRegistry([]output.SpatialSource{repo}, &mockStorage{}, testMeter(), output.NoOpTracer{},
+		slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})), "/tmp")
+	if err := reg.LoadSource(context.Background(), "/tmp/src.gpkg"); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewQueryService(reg, nil, testMeter(), output.NoOpTracer{}, testLogger(),
+		QueryServiceConfig{MaxFeatures: 1, PrioritizeWithin: time.Minute})
+	// Teach the stats that "first" never hits.
+	for range 3 {
+		svc.stats.record("src", "first", false)
+	}
+	req := domain.QueryRequest{Coordinate: domain.NewWGS84Coordinate(1, 1)}
+
+	// No deadline: package order, so max_features=1 keeps the "first" hit.
+	res, err := svc.QueryPointInSource(context.Background(), "src", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Features[0].LayerName; got != "first" {
+		t.Errorf("without deadline first layer = %q, want first", got)
+	}
+
+	// Tight deadline: the historically better layer runs first.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	res, err = svc.QueryPointInSource(ctx, "src", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Features[0].LayerName; got != "second" {
+		t.Errorf("with tight deadline first layer = %q, want second", got)
+	}
+	if res.Incomplete {
+		t.Error("Incomplete = true for a query that finished in time")
+	}
+}
+
+func TestQueryMarksExpiredDeadlineIncomplete(t *testing.T) {
+	reg := newTestRegistry()
+	if err := reg.LoadSource(context.Background(), "/tmp/a.gpkg"); err != nil {
+		t.Fatal(err)
+	}
+	svc := newTestQueryService(reg)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	resp, err := svc.QueryPoint(ctx, domain.QueryRequest{Coordinate: domain.NewWGS84Coordinate(1, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Incomplete {
+		t.Error("Incomplete = false, want true")
+	}
+}
+
+func layerNames(layers []domain.Layer) []string {
+	out := make([]string, len(layers))
+	for i := range layers {
+		out[i] = layers[i].Name
+	}
+	return out
+}
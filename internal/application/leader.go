@@ -0,0 +1,144 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// leadership reports whether this instance may fetch from storage.
+// *LeaderElector implements it; a nil one always leads.
+type leadership interface {
+	IsLeader() bool
+}
+
+// LeaderElector elects one of several replicas that share a data directory
+// as the one that downloads and indexes sources (sync.leader_election). It
+// tries to acquire or renew a lease every third of the lease duration;
+// losing the lease, or failing to renew it for a whole lease duration, makes
+// the instance a follower. A nil *LeaderElector always leads, so an instance
+// without leader election behaves as before.
+type LeaderElector struct {
+	lease    output.LeaderLease
+	identity string
+	ttl      time.Duration
+	logger   *slog.Logger
+	onChange func(leader bool)
+
+	leader    atomic.Bool
+	lastRenew time.Time // last successful acquire or renewal; owned by the loop
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewLeaderElector creates an elector that holds lease as identity for ttl
+// at a time.
+func NewLeaderElector(lease output.LeaderLease, identity string, ttl time.Duration, logger *slog.Logger) *LeaderElector {
+	return &LeaderElector{
+		lease:    lease,
+		identity: identity,
+		ttl:      ttl,
+		logger:   logger,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// OnChange registers fn to run whenever the instance gains (true) or loses
+// (false) leadership. Call before Start.
+func (e *LeaderElector) OnChange(fn func(leader bool)) {
+	e.onChange = fn
+}
+
+// IsLeader reports whether this instance holds the lease. A nil elector
+// always leads.
+func (e *LeaderElector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	return e.leader.Load()
+}
+
+// Start runs the first election round before it returns, so the startup load
+// already knows its role, then keeps renewing in the background. No-op on a
+// nil elector.
+func (e *LeaderElector) Start(ctx context.Context) {
+	if e == nil {
+		return
+	}
+	e.logger.Info("leader election started", "identity", e.identity, "lease_duration", e.ttl)
+	e.round(ctx)
+	if !e.leader.Load() {
+		e.logger.Info("following the sync leader", "identity", e.identity)
+	}
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-e.stopCh:
+				return
+			case <-ticker.C:
+				e.round(ctx)
+			}
+		}
+	}()
+}
+
+// Stop ends the renewals and releases the lease if this instance holds it,
+// so another replica takes over without waiting for it to expire. No-op on a
+// nil elector.
+func (e *LeaderElector) Stop() {
+	if e == nil {
+		return
+	}
+	close(e.stopCh)
+	e.wg.Wait()
+	if e.leader.Load() {
+		if err := e.lease.Release(context.Background(), e.identity); err != nil {
+			e.logger.Warn("failed to release leader lease", "error", err)
+		}
+		e.set(false)
+	}
+}
+
+// round tries to acquire or renew the lease once. An error keeps the current
+// role until the lease could have expired, so a brief storage hiccup does
+// not hand leadership back and forth.
+func (e *LeaderElector) round(ctx context.Context) {
+	ok, err := e.lease.TryAcquire(ctx, e.identity, e.ttl)
+	if err != nil {
+		e.logger.Warn("leader lease renewal failed", "error", err)
+		if e.leader.Load() && time.Since(e.lastRenew) >= e.ttl {
+			e.set(false)
+		}
+		return
+	}
+	if ok {
+		e.lastRenew = time.Now()
+	}
+	e.set(ok)
+}
+
+// set records the role and announces a change.
+func (e *LeaderElector) set(leader bool) {
+	if e.leader.Swap(leader) == leader {
+		return
+	}
+	if leader {
+		e.logger.Info("became sync leader", "identity", e.identity)
+	} else {
+		e.logger.Info("following the sync leader", "identity", e.identity)
+	}
+	if e.onChange != nil {
+		e.onChange(leader)
+	}
+}
@@ -0,0 +1,92 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLease grants the lease to whoever holder names; err fails every call.
+type fakeLease struct {
+	mu       sync.Mutex
+	holder   string
+	err      error
+	released []string
+}
+
+func (f *fakeLease) TryAcquire(_ context.Context, holder string, _ time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return false, f.err
+	}
+	return f.holder == holder, nil
+}
+
+func (f *fakeLease) Release(_ context.Context, holder string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.released = append(f.released, holder)
+	return nil
+}
+
+func TestLeaderElector_FollowsTheLease(t *testing.T) {
+	lease := &fakeLease{holder: "a"}
+	e := NewLeaderElector(lease, "a", time.Minute, slog.New(slog.DiscardHandler))
+	var changes []bool
+	e.OnChange(func(leader bool) { changes = append(changes, leader) })
+
+	e.round(context.Background())
+	if !e.IsLeader() {
+		t.Fatal("IsLeader() = false holding the lease, want true")
+	}
+	lease.holder = "b"
+	e.round(context.Background())
+	if e.IsLeader() {
+		t.Fatal("IsLeader() = true after losing the lease, want false")
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("changes = %v, want [true false]", changes)
+	}
+}
+
+// TestLeaderElector_KeepsRoleThroughErrors: a failed renewal keeps the lead
+// until the lease could have expired.
+func TestLeaderElector_KeepsRoleThroughErrors(t *testing.T) {
+	lease := &fakeLease{holder: "a"}
+	e := NewLeaderElector(lease, "a", time.Minute, slog.New(slog.DiscardHandler))
+	e.round(context.Background())
+
+	lease.err = errors.New("stale NFS handle")
+	e.round(context.Background())
+	if !e.IsLeader() {
+		t.Fatal("IsLeader() = false after one failed renewal, want true")
+	}
+	e.lastRenew = time.Now().Add(-time.Minute)
+	e.round(context.Background())
+	if e.IsLeader() {
+		t.Error("IsLeader() = true a lease duration after the last renewal, want false")
+	}
+}
+
+func TestLeaderElector_StopReleases(t *testing.T) {
+	lease := &fakeLease{holder: "a"}
+	e := NewLeaderElector(lease, "a", time.Minute, slog.New(slog.DiscardHandler))
+	e.Start(context.Background())
+	e.Stop()
+	if e.IsLeader() || len(lease.released) != 1 {
+		t.Errorf("after Stop: leader %v, released %v; want follower, [a]", e.IsLeader(), lease.released)
+	}
+}
+
+func TestLeaderElector_NilLeads(t *testing.T) {
+	var e *LeaderElector
+	e.Start(context.Background())
+	if !e.IsLeader() {
+		t.Error("nil elector IsLeader() = false, want true")
+	}
+	e.Stop()
+}
@@ -0,0 +1,98 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+)
+
+// MaintenanceMode holds the operator maintenance switch. The health and sync
+// services and the file watcher consult Active; the HTTP admin endpoint flips
+// it. The zero value is not usable; call NewMaintenanceMode.
+type MaintenanceMode struct {
+	mu      sync.RWMutex
+	status  input.MaintenanceStatus
+	onLeave []func()
+	logger  *slog.Logger
+	audit   *AuditLog // records switching on and off; nil disables auditing
+}
+
+var _ input.Maintenance = (*MaintenanceMode)(nil)
+
+// NewMaintenanceMode returns a switch that starts off.
+func NewMaintenanceMode(logger *slog.Logger) *MaintenanceMode {
+	return &MaintenanceMode{logger: logger}
+}
+
+// Active reports whether maintenance mode is on. A nil receiver is never
+// active, so services without a switch behave as before.
+func (m *MaintenanceMode) Active() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status.Active
+}
+
+// SetAudit installs the audit log that records the switch being flipped.
+// nil switches auditing off.
+func (m *MaintenanceMode) SetAudit(a *AuditLog) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.audit = a
+}
+
+// EnterMaintenance implements input.Maintenance.
+func (m *MaintenanceMode) EnterMaintenance(ctx context.Context, reason string) input.MaintenanceStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.status.Active {
+		m.status = input.MaintenanceStatus{Active: true, Since: time.Now()}
+	}
+	m.status.Reason = reason
+	m.logger.Warn("maintenance mode on: readiness fails, sync and reloads are blocked", "reason", reason)
+	m.audit.Record(ctx, domain.AuditMaintenanceEnter, "", reason, nil)
+	return m.status
+}
+
+// OnLeave registers fn to run each time maintenance mode is switched off,
+// after the switch — for replaying work that was deferred while it was on.
+func (m *MaintenanceMode) OnLeave(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onLeave = append(m.onLeave, fn)
+}
+
+// LeaveMaintenance implements input.Maintenance. The OnLeave hooks run
+// outside the lock, so they may consult Active.
+func (m *MaintenanceMode) LeaveMaintenance(ctx context.Context) input.MaintenanceStatus {
+	m.mu.Lock()
+	wasActive := m.status.Active
+	if wasActive {
+		m.logger.Info("maintenance mode off", "duration", time.Since(m.status.Since).Round(time.Second))
+	}
+	m.status = input.MaintenanceStatus{}
+	hooks := append([]func(){}, m.onLeave...)
+	audit := m.audit
+	m.mu.Unlock()
+
+	if wasActive {
+		audit.Record(ctx, domain.AuditMaintenanceLeave, "", "", nil)
+		for _, fn := range hooks {
+			fn()
+		}
+	}
+	return input.MaintenanceStatus{}
+}
+
+// MaintenanceStatus implements input.Maintenance.
+func (m *MaintenanceMode) MaintenanceStatus() input.MaintenanceStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
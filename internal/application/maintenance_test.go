@@ -0,0 +1,94 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+func TestMaintenanceModeEnterLeave(t *testing.T) {
+	var nilMode *MaintenanceMode
+	if nilMode.Active() {
+		t.Error("nil switch reports active")
+	}
+
+	m := NewMaintenanceMode(testLogger())
+	left := 0
+	m.OnLeave(func() { left++ })
+
+	m.LeaveMaintenance(context.Background())
+	if left != 0 {
+		t.Errorf("OnLeave ran %d times for a switch that was off", left)
+	}
+
+	first := m.EnterMaintenance(context.Background(), "swap districts.gpkg")
+	if !first.Active || first.Since.IsZero() || !m.Active() {
+		t.Fatalf("after enter: %+v", first)
+	}
+	again := m.EnterMaintenance(context.Background(), "still swapping")
+	if !again.Since.Equal(first.Since) || again.Reason != "still swapping" {
+		t.Errorf("re-enter = %+v, want same since and new reason", again)
+	}
+
+	if st := m.LeaveMaintenance(context.Background()); st.Active || m.Active() {
+		t.Errorf("after leave: %+v", st)
+	}
+	if left != 1 {
+		t.Errorf("OnLeave ran %d times, want 1", left)
+	}
+}
+
+func TestHealthServiceMaintenanceFailsReadiness(t *testing.T) {
+	reg := newTestRegistry()
+	markLoaded(reg)
+	setSources(reg, map[string]*sourceEntry{"a": readyEntry("a")})
+	svc := NewHealthService(reg, true, output.NoOpTracer{})
+	m := NewMaintenanceMode(testLogger())
+	svc.SetMaintenance(m)
+	ctx := context.Background()
+
+	m.EnterMaintenance(context.Background(), "")
+	if svc.IsReady(ctx) {
+		t.Error("ready during maintenance")
+	}
+	if !svc.IsHealthy(ctx) {
+		t.Error("maintenance must not fail liveness")
+	}
+	if got := svc.GetHealthDetails(ctx).Components["maintenance"]; got != "active" {
+		t.Errorf("components[maintenance] = %q, want active", got)
+	}
+
+	m.LeaveMaintenance(context.Background())
+	if !svc.IsReady(ctx) {
+		t.Error("not ready after maintenance ended")
+	}
+}
+
+func TestSyncServiceBlockedByMaintenance(t *testing.T) {
+	registry := &SourceRegistry{
+		sources:   make(map[string]*sourceEntry),
+		logger:    testLogger(),
+		localPath: "/tmp",
+		storage:   &mockStorage{},
+		tracer:    output.NoOpTracer{},
+	}
+	service := NewSyncService(registry, time.Hour, output.NoOpTracer{}, testLogger())
+	m := NewMaintenanceMode(testLogger())
+	service.SetMaintenance(m)
+
+	m.EnterMaintenance(context.Background(), "")
+	if _, err := service.TriggerSync(context.Background(), input.SyncSelection{}); !errors.Is(err, domain.ErrMaintenance) {
+		t.Fatalf("err = %v, want ErrMaintenance", err)
+	}
+
+	// The refused call must not have used up the rate-limit cooldown.
+	m.LeaveMaintenance(context.Background())
+	if _, err := service.TriggerSync(context.Background(), input.SyncSelection{}); err != nil {
+		t.Errorf("sync after maintenance: %v", err)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"io"
 	"path/filepath"
-	"strings"
 
 	"github.com/jobrunner/ortus/internal/domain"
 	"github.com/jobrunner/ortus/internal/ports/output"
@@ -22,7 +21,7 @@ func (m *mockRepository) Supports(_ string) bool { return true }
 
 func (m *mockRepository) Prepare(_ context.Context, _, _ string) error { return nil }
 
-func (m *mockRepository) Open(_ context.Context, path string) (*domain.Source, error) {
+func (m *mockRepository) Open(_ context.Context, id, path string) (*domain.Source, error) {
 	if m.openErr != nil {
 		return nil, m.openErr
 	}
@@ -31,14 +30,9 @@ func (m *mockRepository) Open(_ context.Context, path string) (*domain.Source, e
 			return pkg, nil
 		}
 	}
-	// Derive source ID from filename (same as deriveSourceID in registry.go)
-	base := filepath.Base(path)
-	ext := filepath.Ext(base)
-	packageID := strings.TrimSuffix(base, ext)
-
 	return &domain.Source{
-		ID:   packageID,
-		Name: base,
+		ID:   id,
+		Name: filepath.Base(path),
 		Path: path,
 	}, nil
 }
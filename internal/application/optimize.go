@@ -0,0 +1,212 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// Optimizer runs the maintenance of loaded sources' working copies — index
+// rebuilds, ANALYZE, VACUUM — one run per source at a time, in the
+// background, and keeps the progress of the last run per source. The zero
+// value is not usable; call NewOptimizer.
+type Optimizer struct {
+	registry *SourceRegistry
+	logger   *slog.Logger
+	audit    *AuditLog // records each run; nil disables auditing
+
+	mu   sync.Mutex
+	runs map[string]*input.OptimizeProgress
+
+	ctx    context.Context // canceled by Stop
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ input.SourceOptimizer = (*Optimizer)(nil)
+
+// NewOptimizer creates an optimizer for the sources of registry.
+func NewOptimizer(registry *SourceRegistry, logger *slog.Logger) *Optimizer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Optimizer{
+		registry: registry,
+		logger:   logger,
+		runs:     make(map[string]*input.OptimizeProgress),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// SetAudit installs the audit log that records finished runs. nil switches
+// auditing off.
+func (o *Optimizer) SetAudit(a *AuditLog) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.audit = a
+}
+
+// StartOptimize implements input.SourceOptimizer.
+func (o *Optimizer) StartOptimize(ctx context.Context, id string, steps []input.OptimizeStep) (input.OptimizeProgress, error) {
+	steps, err := normalizeOptimizeSteps(steps)
+	if err != nil {
+		return input.OptimizeProgress{}, err
+	}
+	opt, layers, err := o.registry.sourceOptimizer(id)
+	if err != nil {
+		return input.OptimizeProgress{}, err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if run, ok := o.runs[id]; ok && run.Running() {
+		return input.OptimizeProgress{}, domain.ErrOptimizeRunning
+	}
+	run := &input.OptimizeProgress{SourceID: id, Steps: steps, StartedAt: time.Now()}
+	if slices.Contains(steps, input.OptimizeReindex) {
+		run.Layers = len(layers)
+	}
+	o.runs[id] = run
+
+	actor := domain.WithActor(o.ctx, domain.ActorFrom(ctx))
+	o.wg.Add(1)
+	go o.run(actor, opt, run, layers)
+	return snapshotProgress(run), nil
+}
+
+// OptimizeProgress implements input.SourceOptimizer.
+func (o *Optimizer) OptimizeProgress(id string) (input.OptimizeProgress, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	run, ok := o.runs[id]
+	if !ok {
+		return input.OptimizeProgress{}, domain.ErrSourceNotFound
+	}
+	return snapshotProgress(run), nil
+}
+
+// Stop cancels the running runs and waits for them to give up.
+func (o *Optimizer) Stop() {
+	o.cancel()
+	o.wg.Wait()
+}
+
+// run takes the steps of run in order and stops at the first failing one.
+func (o *Optimizer) run(ctx context.Context, opt output.SourceOptimizer, run *input.OptimizeProgress, layers []string) {
+	defer o.wg.Done()
+	id := run.SourceID
+	o.logger.Info("optimizing source", "id", id, "steps", run.Steps)
+
+	var err error
+	for _, step := range run.Steps {
+		o.update(func() { run.Step = step })
+		switch step {
+		case input.OptimizeReindex:
+			for _, layer := range layers {
+				if err = opt.RebuildSpatialIndex(ctx, id, layer); err != nil {
+					err = fmt.Errorf("reindex %s: %w", layer, err)
+					break
+				}
+				o.update(func() { run.LayersDone++ })
+			}
+		case input.OptimizeAnalyze:
+			err = opt.Analyze(ctx, id)
+		case input.OptimizeVacuum:
+			err = opt.Vacuum(ctx, id)
+		}
+		if err != nil {
+			break
+		}
+		o.update(func() { run.Done = append(run.Done, step) })
+	}
+
+	o.update(func() {
+		run.Step = ""
+		run.FinishedAt = time.Now()
+		if err != nil {
+			run.Error = err.Error()
+		}
+	})
+	elapsed := time.Since(run.StartedAt).Round(time.Millisecond)
+	if err != nil {
+		o.logger.Error("source optimization failed", "id", id, "error", err, "elapsed", elapsed)
+	} else {
+		o.logger.Info("source optimized", "id", id, "elapsed", elapsed)
+	}
+	o.mu.Lock()
+	audit := o.audit
+	o.mu.Unlock()
+	audit.Record(ctx, domain.AuditOptimize, id, joinSteps(run.Steps), err)
+}
+
+// update changes run under the lock, so progress readers see a consistent
+// snapshot.
+func (o *Optimizer) update(fn func()) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	fn()
+}
+
+// snapshotProgress copies run, so the caller's slices do not change under
+// it. Call with the lock held.
+func snapshotProgress(run *input.OptimizeProgress) input.OptimizeProgress {
+	p := *run
+	p.Steps = slices.Clone(run.Steps)
+	p.Done = slices.Clone(run.Done)
+	return p
+}
+
+// normalizeOptimizeSteps returns all steps for none and rejects unknown and
+// repeated ones.
+func normalizeOptimizeSteps(steps []input.OptimizeStep) ([]input.OptimizeStep, error) {
+	if len(steps) == 0 {
+		return slices.Clone(input.OptimizeSteps), nil
+	}
+	out := make([]input.OptimizeStep, 0, len(steps))
+	for _, s := range steps {
+		s = input.OptimizeStep(strings.ToLower(string(s)))
+		if !slices.Contains(input.OptimizeSteps, s) {
+			return nil, fmt.Errorf("%w: unknown optimize step %q (want reindex, analyze or vacuum)", domain.ErrInvalidInput, s)
+		}
+		if slices.Contains(out, s) {
+			return nil, fmt.Errorf("%w: optimize step %q given twice", domain.ErrInvalidInput, s)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func joinSteps(steps []input.OptimizeStep) string {
+	names := make([]string, len(steps))
+	for i, s := range steps {
+		names[i] = string(s)
+	}
+	return strings.Join(names, ",")
+}
+
+// sourceOptimizer returns the adapter maintaining a loaded source and the
+// names of the source's layers.
+func (r *SourceRegistry) sourceOptimizer(id string) (output.SourceOptimizer, []string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.sources[id]
+	if !ok || entry.Source == nil || entry.Repo == nil {
+		return nil, nil, domain.ErrSourceNotFound
+	}
+	opt, ok := entry.Repo.(output.SourceOptimizer)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s sources have no maintenance", domain.ErrUnsupportedSource, entry.Source.Kind)
+	}
+	layers := make([]string, len(entry.Source.Layers))
+	for i, l := range entry.Source.Layers {
+		layers[i] = l.Name
+	}
+	return opt, layers, nil
+}
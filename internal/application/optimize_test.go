@@ -0,0 +1,152 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// optimizingRepository is a mockRepository that also implements
+// output.SourceOptimizer, recording each call. Vacuum waits for release.
+type optimizingRepository struct {
+	mockRepository
+	mu        sync.Mutex
+	calls     []string
+	vacuumErr error
+	release   chan struct{}
+}
+
+func (m *optimizingRepository) record(call string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+func (m *optimizingRepository) RebuildSpatialIndex(_ context.Context, _, layer string) error {
+	m.record("reindex " + layer)
+	return nil
+}
+
+func (m *optimizingRepository) Analyze(context.Context, string) error {
+	m.record("analyze")
+	return nil
+}
+
+func (m *optimizingRepository) Vacuum(context.Context, string) error {
+	<-m.release
+	m.record("vacuum")
+	return m.vacuumErr
+}
+
+func newOptimizeTestRegistry(t *testing.T, repo output.SpatialSource) *SourceRegistry {
+	t.Helper()
+	reg := NewSourceRegistry([]output.SpatialSource{repo}, &mockStorage{}, testMeter(), output.NoOpTracer{},
+		slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})), "/tmp")
+	if err := reg.LoadSource(context.Background(), "/data/parcels.gpkg"); err != nil {
+		t.Fatal(err)
+	}
+	return reg
+}
+
+// waitOptimized polls until the run on id has finished.
+func waitOptimized(t *testing.T, o *Optimizer, id string) input.OptimizeProgress {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		p, err := o.OptimizeProgress(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !p.Running() {
+			return p
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("optimization still running: %+v", p)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOptimizerRunsStepsAndReportsProgress(t *testing.T) {
+	repo := &optimizingRepository{release: make(chan struct{})}
+	repo.packages = map[string]*domain.Source{"/data/parcels.gpkg": {
+		ID: "parcels", Path: "/data/parcels.gpkg",
+		Layers: []domain.Layer{{Name: "parcels"}, {Name: "buildings"}},
+	}}
+	reg := newOptimizeTestRegistry(t, repo)
+	audit := NewAuditLog(0, slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+	o := NewOptimizer(reg, slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+	o.SetAudit(audit)
+	defer o.Stop()
+	ctx := domain.WithActor(context.Background(), "admin@10.0.0.1")
+
+	p, err := o.StartOptimize(ctx, "parcels", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Running() || p.Layers != 2 || !slices.Equal(p.Steps, input.OptimizeSteps) {
+		t.Errorf("initial progress = %+v", p)
+	}
+	if _, err := o.StartOptimize(ctx, "parcels", nil); !errors.Is(err, domain.ErrOptimizeRunning) {
+		t.Errorf("second start while running: err = %v, want ErrOptimizeRunning", err)
+	}
+
+	close(repo.release)
+	p = waitOptimized(t, o, "parcels")
+	if p.Error != "" || p.LayersDone != 2 || !slices.Equal(p.Done, input.OptimizeSteps) || p.Step != "" {
+		t.Errorf("final progress = %+v", p)
+	}
+	want := []string{"reindex parcels", "reindex buildings", "analyze", "vacuum"}
+	if !slices.Equal(repo.calls, want) {
+		t.Errorf("calls = %v, want %v", repo.calls, want)
+	}
+	if entries := audit.RecentAudit(1); len(entries) != 1 || entries[0].Action != domain.AuditOptimize ||
+		entries[0].Actor != "admin@10.0.0.1" || entries[0].Detail != "reindex,analyze,vacuum" {
+		t.Errorf("audit = %+v", entries)
+	}
+}
+
+func TestOptimizerStopsAtFailingStep(t *testing.T) {
+	repo := &optimizingRepository{release: make(chan struct{}), vacuumErr: errors.New("disk full")}
+	close(repo.release)
+	o := NewOptimizer(newOptimizeTestRegistry(t, repo), slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+	defer o.Stop()
+
+	if _, err := o.StartOptimize(context.Background(), "parcels", []input.OptimizeStep{"VACUUM", "analyze"}); err != nil {
+		t.Fatal(err)
+	}
+	p := waitOptimized(t, o, "parcels")
+	if p.Error != "disk full" || len(p.Done) != 0 || slices.Contains(repo.calls, "analyze") {
+		t.Errorf("progress = %+v, calls = %v; want the run to stop at vacuum", p, repo.calls)
+	}
+}
+
+func TestOptimizerRejects(t *testing.T) {
+	o := NewOptimizer(newOptimizeTestRegistry(t, &mockRepository{}), slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+	defer o.Stop()
+	ctx := context.Background()
+
+	if _, err := o.StartOptimize(ctx, "parcels", nil); !errors.Is(err, domain.ErrUnsupported) {
+		t.Errorf("adapter without maintenance: err = %v, want ErrUnsupported", err)
+	}
+	if _, err := o.StartOptimize(ctx, "missing", nil); !errors.Is(err, domain.ErrSourceNotFound) {
+		t.Errorf("unknown source: err = %v, want ErrSourceNotFound", err)
+	}
+	for _, steps := range [][]input.OptimizeStep{{"defrag"}, {"analyze", "analyze"}} {
+		if _, err := o.StartOptimize(ctx, "parcels", steps); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("steps %v: err = %v, want ErrInvalidInput", steps, err)
+		}
+	}
+	if _, err := o.OptimizeProgress("parcels"); !errors.Is(err, domain.ErrSourceNotFound) {
+		t.Errorf("progress without a run: err = %v, want ErrSourceNotFound", err)
+	}
+}
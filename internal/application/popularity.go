@@ -0,0 +1,127 @@
+package application
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/ports/input"
+)
+
+// MaxPopularityWindow is the longest window Popularity can answer for; hits
+// older than that are forgotten.
+const MaxPopularityWindow = 24 * time.Hour
+
+// popularitySlots is the number of one-minute buckets kept per source.
+const popularitySlots = int(MaxPopularityWindow / time.Minute)
+
+// Popularity counts, per source, the point queries the source answered with
+// at least one feature, in one-minute buckets over the last 24 hours. Every
+// /query fans out to all sources, so "queried" says nothing about use; a hit
+// does. The counts feed the ranking endpoint and idle-connection tiering.
+type Popularity struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	sources map[string]*hitRing
+}
+
+// hitRing is a ring of per-minute hit counts. minute holds the minute (Unix
+// time / 60) a slot was last written for, so a slot left over from an earlier
+// lap of the ring is recognized as stale without a sweep.
+type hitRing struct {
+	minute [popularitySlots]int64
+	hits   [popularitySlots]int64
+}
+
+// NewPopularity creates an empty popularity tracker.
+func NewPopularity() *Popularity {
+	return &Popularity{
+		now:     time.Now,
+		sources: make(map[string]*hitRing),
+	}
+}
+
+// Record counts one hit for sourceID. Safe on a nil *Popularity, so the query
+// service can call it unconditionally.
+func (p *Popularity) Record(sourceID string) {
+	if p == nil {
+		return
+	}
+	m := p.now().Unix() / 60
+	slot := int(m % int64(popularitySlots))
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r, ok := p.sources[sourceID]
+	if !ok {
+		r = &hitRing{}
+		p.sources[sourceID] = r
+	}
+	if r.minute[slot] != m {
+		r.minute[slot] = m
+		r.hits[slot] = 0
+	}
+	r.hits[slot]++
+}
+
+// Hits returns the hits of sourceID in the last window, rounded up to whole
+// minutes and capped at MaxPopularityWindow.
+func (p *Popularity) Hits(sourceID string, window time.Duration) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r, ok := p.sources[sourceID]
+	if !ok {
+		return 0
+	}
+	return r.sum(p.now().Unix()/60, windowMinutes(window))
+}
+
+// Ranking returns the sources with at least one hit in the last window,
+// most-hit first; ties are ordered by source id so the ranking is stable.
+func (p *Popularity) Ranking(window time.Duration) []input.SourcePopularity {
+	now := p.now().Unix() / 60
+	minutes := windowMinutes(window)
+
+	p.mu.Lock()
+	out := make([]input.SourcePopularity, 0, len(p.sources))
+	for id, r := range p.sources {
+		if n := r.sum(now, minutes); n > 0 {
+			out = append(out, input.SourcePopularity{SourceID: id, Hits: n})
+		} else if minutes == popularitySlots {
+			// Nothing left in the longest window: the source is gone or
+			// unused, drop its ring.
+			delete(p.sources, id)
+		}
+	}
+	p.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Hits != out[j].Hits {
+			return out[i].Hits > out[j].Hits
+		}
+		return out[i].SourceID < out[j].SourceID
+	})
+	for i := range out {
+		out[i].Rank = i + 1
+	}
+	return out
+}
+
+// sum adds up the hits of the last minutes minutes up to and including now.
+func (r *hitRing) sum(now int64, minutes int) int64 {
+	var total int64
+	for i := 0; i < minutes; i++ {
+		m := now - int64(i)
+		slot := int(m % int64(popularitySlots))
+		if r.minute[slot] == m {
+			total += r.hits[slot]
+		}
+	}
+	return total
+}
+
+// windowMinutes converts a window to a whole number of buckets in
+// [1, popularitySlots].
+func windowMinutes(window time.Duration) int {
+	minutes := int((window + time.Minute - 1) / time.Minute)
+	return min(max(minutes, 1), popularitySlots)
+}
@@ -0,0 +1,75 @@
+package application
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPopularityWindows(t *testing.T) {
+	now := time.Date(2026, 7, 6, 12, 0, 0, 0, time.UTC)
+	p := NewPopularity()
+	p.now = func() time.Time { return now }
+
+	p.Record("a")
+	now = now.Add(30 * time.Minute)
+	p.Record("a")
+	p.Record("a")
+
+	if got := p.Hits("a", 10*time.Minute); got != 2 {
+		t.Errorf("10m hits = %d, want 2", got)
+	}
+	if got := p.Hits("a", time.Hour); got != 3 {
+		t.Errorf("1h hits = %d, want 3", got)
+	}
+
+	// A full day later every bucket is stale, even the reused slots.
+	now = now.Add(MaxPopularityWindow)
+	if got := p.Hits("a", MaxPopularityWindow); got != 0 {
+		t.Errorf("hits after 24h = %d, want 0", got)
+	}
+	p.Record("a")
+	if got := p.Hits("a", MaxPopularityWindow); got != 1 {
+		t.Errorf("hits after reuse = %d, want 1", got)
+	}
+
+	var nilP *Popularity
+	nilP.Record("a") // must not panic
+}
+
+func TestPopularityRanking(t *testing.T) {
+	now := time.Date(2026, 7, 6, 12, 0, 0, 0, time.UTC)
+	p := NewPopularity()
+	p.now = func() time.Time { return now }
+
+	p.Record("old")
+	now = now.Add(2 * time.Hour)
+	for range 3 {
+		p.Record("hot")
+	}
+	p.Record("b")
+	p.Record("a")
+
+	got := p.Ranking(time.Hour)
+	want := []struct {
+		id   string
+		hits int64
+	}{{"hot", 3}, {"a", 1}, {"b", 1}}
+	if len(got) != len(want) {
+		t.Fatalf("ranking = %+v, want %d entries", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].SourceID != w.id || got[i].Hits != w.hits || got[i].Rank != i+1 {
+			t.Errorf("ranking[%d] = %+v, want %s with %d hits at rank %d", i, got[i], w.id, w.hits, i+1)
+		}
+	}
+
+	// A day after its last hit, a source drops out of the 24h ranking and
+	// its ring is released.
+	now = now.Add(23 * time.Hour)
+	if got := p.Ranking(MaxPopularityWindow); len(got) != 3 || got[len(got)-1].SourceID == "old" {
+		t.Errorf("24h ranking = %+v, want old dropped", got)
+	}
+	if _, ok := p.sources["old"]; ok {
+		t.Error("ring of a source without hits in 24h was kept")
+	}
+}
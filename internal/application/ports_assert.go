@@ -6,8 +6,16 @@ import "github.com/jobrunner/ortus/internal/ports/input"
 // ports. Adapters depend on these interfaces; these checks fail the build if a
 // service ever drifts from its port contract.
 var (
-	_ input.QueryService   = (*QueryService)(nil)
-	_ input.SourceRegistry = (*SourceRegistry)(nil)
-	_ input.HealthChecker  = (*HealthService)(nil)
-	_ input.Syncer         = (*SyncService)(nil)
+	_ input.QueryService         = (*QueryService)(nil)
+	_ input.SourceRegistry       = (*SourceRegistry)(nil)
+	_ input.HealthChecker        = (*HealthService)(nil)
+	_ input.Syncer               = (*SyncService)(nil)
+	_ input.ChangeSyncer         = (*SyncService)(nil)
+	_ input.Popularity           = (*Popularity)(nil)
+	_ input.PeerCatalog          = (*Federation)(nil)
+	_ input.SourceHealthReporter = (*SourceRegistry)(nil)
+	_ input.AuditTrail           = (*AuditLog)(nil)
+	_ input.AttributeTables      = (*SourceRegistry)(nil)
+	_ input.Tiles                = (*SourceRegistry)(nil)
+	_ input.Geocoder             = (*GeocodeService)(nil)
 )
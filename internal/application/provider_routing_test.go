@@ -22,9 +22,9 @@ type extProvider struct {
 
 func (p *extProvider) Supports(path string) bool { return strings.HasSuffix(path, p.ext) }
 
-func (p *extProvider) Open(_ context.Context, path string) (*domain.Source, error) {
+func (p *extProvider) Open(_ context.Context, id, path string) (*domain.Source, error) {
 	return &domain.Source{
-		ID:     domain.DeriveSourceID(path),
+		ID:     id,
 		Name:   p.tag,
 		Path:   path,
 		Kind:   domain.SourceKindVector,
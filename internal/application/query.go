@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -21,10 +22,21 @@ type sourceQuerier interface {
 	ReadySourceIDs() []string
 	GetSource(ctx context.Context, id string) (*domain.Source, error)
 	Query(ctx context.Context, sourceID, layer string, coord domain.Coordinate) ([]domain.Feature, error)
+	// QueryEncoded is Query with the requested geometry options applied.
+	QueryEncoded(ctx context.Context, sourceID, layer string, coord domain.Coordinate, opts domain.GeometryOptions) ([]domain.Feature, error)
 	// QueryPoints resolves many coordinates against one layer at once (set-based
 	// when the adapter supports it, else a per-point loop), one result slice per
 	// input coordinate in order.
 	QueryPoints(ctx context.Context, sourceID, layer string, coords []domain.Coordinate) ([][]domain.Feature, error)
+	// QueryRoute returns the features of one layer a route runs through;
+	// domain.ErrUnsupported for sources that cannot answer it.
+	QueryRoute(ctx context.Context, sourceID, layer string, route domain.Route) ([]domain.Feature, error)
+	// QueryArea returns the features of one layer in relation rel to an area;
+	// domain.ErrUnsupported for sources that cannot answer it.
+	QueryArea(ctx context.Context, sourceID, layer string, area domain.Area, rel domain.SpatialRelation) ([]domain.Feature, error)
+	// Aggregate aggregates the features of one layer; domain.ErrUnsupported
+	// for sources that cannot answer it.
+	Aggregate(ctx context.Context, sourceID, layer string, q domain.AggregateQuery) (domain.LayerAggregate, error)
 }
 
 // QueryService handles point queries across registered sources.
@@ -34,15 +46,61 @@ type QueryService struct {
 	tracer        output.Tracer
 	queryCount    metric.Int64Counter
 	queryDuration metric.Float64Histogram
+	slowQueries   metric.Int64Counter
 	logger        *slog.Logger
-	maxFeatures   int
-	queryTimeout  time.Duration
+	// omitSourceLabels leaves source_id and layer off the query metrics.
+	omitSourceLabels bool
+	// sridBounds are the configured coordinate ranges per SRID.
+	sridBounds domain.SRIDBounds
+	// maxFeatures and queryTimeout (a time.Duration) are atomic so a config
+	// reload can change them under running queries; see SetLimits.
+	maxFeatures  atomic.Int64
+	queryTimeout atomic.Int64
+	// prioritizeWithin reorders layers by learned hit-rate once less than this
+	// remains before the request deadline; 0 keeps package order.
+	prioritizeWithin time.Duration
+	stats            *layerStats
+	flags            output.FeatureFlags
+	// strictExtent turns an empty answer for a point outside every layer
+	// extent into an *domain.OutsideExtentError.
+	strictExtent bool
+	// dedupe drops features several sources return alike; see
+	// domain.QueryResponse.Dedupe.
+	dedupe bool
+	// popularity counts the sources that answered with features; nil
+	// disables tracking.
+	popularity *Popularity
+	// federation forwards queries for sources other instances host; nil
+	// answers from local sources only.
+	federation *Federation
+	// slowThreshold logs and counts the layer queries taking longer; 0
+	// disables the slow query log.
+	slowThreshold time.Duration
 }
 
 // QueryServiceConfig holds configuration for the query service.
 type QueryServiceConfig struct {
 	MaxFeatures  int
 	QueryTimeout time.Duration // per-query deadline; 0 disables
+	// PrioritizeWithin: when less than this remains before the deadline, query
+	// layers most-likely-to-hit first. 0 disables.
+	PrioritizeWithin time.Duration
+	// StrictExtent: reject a point outside the extent of every loaded layer
+	// with *domain.OutsideExtentError instead of an empty response.
+	StrictExtent bool
+	// Dedupe: return a feature that several sources hold alike (same layer
+	// name, feature ID and geometry) once, from the first source.
+	Dedupe bool
+	// SlowThreshold: log and count every layer query taking longer. 0
+	// disables.
+	SlowThreshold time.Duration
+	// OmitSourceLabels: record the query metrics without their source_id
+	// and layer labels, bounding their series when sources come and go.
+	OmitSourceLabels bool
+	// SRIDBounds: plausible coordinate ranges per SRID, checked on top of
+	// the built-in ones so an implausible point is rejected rather than
+	// answered empty.
+	SRIDBounds domain.SRIDBounds
 }
 
 // NewQueryService creates a new query service. The meter is used directly
@@ -56,9 +114,6 @@ func NewQueryService(
 	logger *slog.Logger,
 	cfg QueryServiceConfig,
 ) *QueryService {
-	if cfg.MaxFeatures == 0 {
-		cfg.MaxFeatures = 1000
-	}
 	if tracer == nil {
 		tracer = output.NoOpTracer{}
 	}
@@ -75,17 +130,63 @@ func NewQueryService(
 		metric.WithDescription("Query duration in seconds"),
 		metric.WithUnit("s"),
 	)
+	slowQueries, _ := meter.Int64Counter(
+		"ortus.query.slow",
+		metric.WithDescription("Layer queries slower than query.slow_threshold"),
+	)
 
-	return &QueryService{
+	s := &QueryService{
 		registry:      registry,
 		transformer:   transformer,
 		tracer:        tracer,
 		queryCount:    queryCount,
 		queryDuration: queryDuration,
+		slowQueries:   slowQueries,
 		logger:        logger,
-		maxFeatures:   cfg.MaxFeatures,
-		queryTimeout:  cfg.QueryTimeout,
+
+		prioritizeWithin: cfg.PrioritizeWithin,
+		stats:            newLayerStats(),
+		flags:            output.NoOpFeatureFlags{},
+		strictExtent:     cfg.StrictExtent,
+		dedupe:           cfg.Dedupe,
+		slowThreshold:    cfg.SlowThreshold,
+		omitSourceLabels: cfg.OmitSourceLabels,
+		sridBounds:       cfg.SRIDBounds,
+	}
+	s.SetLimits(cfg.MaxFeatures, cfg.QueryTimeout)
+	return s
+}
+
+// SetLimits changes the per-point feature cap and the per-query deadline.
+// Queries already running keep the deadline they started with. maxFeatures
+// 0 falls back to the default of 1000; queryTimeout 0 disables the deadline.
+func (s *QueryService) SetLimits(maxFeatures int, queryTimeout time.Duration) {
+	if maxFeatures == 0 {
+		maxFeatures = 1000
 	}
+	s.maxFeatures.Store(int64(maxFeatures))
+	s.queryTimeout.Store(int64(queryTimeout))
+}
+
+// SetFeatureFlags installs the runtime flags that can switch off layer
+// prioritization and SQL geometry encoding. A nil provider leaves both on.
+func (s *QueryService) SetFeatureFlags(f output.FeatureFlags) {
+	if f == nil {
+		f = output.NoOpFeatureFlags{}
+	}
+	s.flags = f
+}
+
+// SetPopularity installs the tracker that counts, per source, the queries it
+// answered with features. nil switches tracking off.
+func (s *QueryService) SetPopularity(p *Popularity) {
+	s.popularity = p
+}
+
+// SetFederation installs the peers queries are forwarded to for sources this
+// instance does not host. nil switches forwarding off.
+func (s *QueryService) SetFederation(f *Federation) {
+	s.federation = f
 }
 
 // QueryPoint performs a point query across all registered GeoPackages.
@@ -95,10 +196,10 @@ func (s *QueryService) QueryPoint(ctx context.Context, req domain.QueryRequest)
 	// Enforce the configured per-query deadline so an expensive or hung adapter
 	// query can't pin a goroutine/connection indefinitely. Respect a caller's
 	// existing deadline if it already set one.
-	if s.queryTimeout > 0 {
+	if timeout := time.Duration(s.queryTimeout.Load()); timeout > 0 {
 		if _, hasDeadline := ctx.Deadline(); !hasDeadline {
 			var cancel context.CancelFunc
-			ctx, cancel = context.WithTimeout(ctx, s.queryTimeout)
+			ctx, cancel = context.WithTimeout(ctx, timeout)
 			defer cancel()
 		}
 	}
@@ -119,64 +220,125 @@ func (s *QueryService) QueryPoint(ctx context.Context, req domain.QueryRequest)
 	}
 
 	// Validate coordinate
-	if err := req.Coordinate.Validate(); err != nil {
+	if err := req.Coordinate.ValidateIn(s.sridBounds); err != nil {
 		span.RecordError(err)
 		span.SetStatus(output.StatusError, "invalid coordinate")
 		return nil, err
 	}
 
-	// Get all ready sources
-	sourceIDs := s.registry.ReadySourceIDs()
-
-	// Filter by specific source if requested
-	if req.SourceID != "" {
-		found := false
-		for _, id := range sourceIDs {
-			if id == req.SourceID {
-				sourceIDs = []string{req.SourceID}
-				found = true
-				break
-			}
-		}
-		if !found {
-			span.RecordError(domain.ErrSourceNotFound)
-			span.SetStatus(output.StatusError, "source not found")
-			return nil, domain.ErrSourceNotFound
-		}
+	federated := s.federation != nil && !req.NoForward
+	sourceIDs, local, err := s.sourcesFor(req, federated)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "source not found")
+		return nil, err
 	}
 
 	span.SetAttributes(output.Int("ortus.sources.queried", len(sourceIDs)))
 
+	// Peers answer while the local sources are queried.
+	var forwarded chan federationAnswer
+	if federated {
+		forwarded = make(chan federationAnswer, 1)
+		go func() { forwarded <- s.federation.Query(ctx, req, local) }()
+	}
+
 	// Query each source
 	for _, sid := range sourceIDs {
+		if ctx.Err() != nil {
+			// Deadline hit: return what we have rather than failing the request.
+			response.Incomplete = true
+			break
+		}
 		result, err := s.QueryPointInSource(ctx, sid, req)
 		if err != nil {
 			s.logger.Warn("query failed for source", "source", sid, "error", err)
-			s.queryCount.Add(ctx, 1, metric.WithAttributes(
-				attribute.String("source_id", sid),
-				attribute.String("status", "error"),
-			))
+			s.queryCount.Add(ctx, 1, s.sourceAttrs(sid, "", attribute.String("status", "error")))
 			continue
 		}
 
+		if result.Incomplete {
+			response.Incomplete = true
+		}
 		if result.HasFeatures() {
 			response.AddResult(*result)
 		}
-		s.queryCount.Add(ctx, 1, metric.WithAttributes(
-			attribute.String("source_id", sid),
-			attribute.String("status", "success"),
-		))
+		s.queryCount.Add(ctx, 1, s.sourceAttrs(sid, "", attribute.String("status", "success")))
+	}
+
+	if forwarded != nil {
+		mergeForwarded(response, <-forwarded)
+		span.SetAttributes(output.Int("ortus.peers.queried", len(response.Peers)))
+	}
+	s.dedupeResponse(span, response)
+
+	// Only an empty, complete answer can be "outside the data": a hit is
+	// inside by definition, and a deadline cut-off proves nothing. With
+	// peers, the local extent says nothing about theirs.
+	if s.strictExtent && response.TotalFeatures == 0 && !response.Incomplete && len(response.Peers) == 0 {
+		if err := s.checkExtent(ctx, req.Coordinate, sourceIDs); err != nil {
+			span.RecordError(err)
+			span.SetStatus(output.StatusError, "outside extent")
+			return nil, err
+		}
 	}
 
 	response.ProcessingTime = time.Since(start)
 	span.SetAttributes(
 		output.Int("ortus.features.total", response.TotalFeatures),
 		output.Float64("ortus.duration_ms", float64(response.ProcessingTime.Microseconds())/1000.0),
+		output.Bool("ortus.incomplete", response.Incomplete),
 	)
 	span.SetStatus(output.StatusOK, "")
 	return response, nil
 }
 
+// sourcesFor returns the local sources req is run against, plus the set of
+// all ready local sources. A req.SourceID that only a peer serves yields no
+// local sources; one that nobody serves is domain.ErrSourceNotFound.
+func (s *QueryService) sourcesFor(req domain.QueryRequest, federated bool) ([]string, map[string]bool, error) {
+	sourceIDs := s.registry.ReadySourceIDs()
+	local := make(map[string]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		local[id] = true
+	}
+	switch {
+	case req.SourceID == "":
+		return sourceIDs, local, nil
+	case local[req.SourceID]:
+		return []string{req.SourceID}, local, nil
+	case federated && s.federation.Serves(req.SourceID):
+		return nil, local, nil
+	default:
+		return nil, nil, domain.ErrSourceNotFound
+	}
+}
+
+// dedupeResponse drops the features response holds more than once when
+// query.dedupe is on.
+func (s *QueryService) dedupeResponse(span output.Span, response *domain.QueryResponse) {
+	if !s.dedupe {
+		return
+	}
+	if n := response.Dedupe(); n > 0 {
+		span.SetAttributes(output.Int("ortus.features.deduplicated", n))
+	}
+}
+
+// mergeForwarded adds the peers' results to response. A peer that failed,
+// timed out or cut its own search short makes the response incomplete.
+func mergeForwarded(response *domain.QueryResponse, answer federationAnswer) {
+	for _, r := range answer.results {
+		response.AddResult(r)
+	}
+	for _, o := range answer.outcomes {
+		if o.Status != domain.PeerStatusOK || o.Incomplete {
+			response.Incomplete = true
+		}
+	}
+	response.Peers = answer.outcomes
+}
+
 // QueryPointInSource performs a point query in a specific source.
 func (s *QueryService) QueryPointInSource(ctx context.Context, sourceID string, req domain.QueryRequest) (*domain.QueryResult, error) {
 	start := time.Now()
@@ -188,6 +350,12 @@ func (s *QueryService) QueryPointInSource(ctx context.Context, sourceID string,
 	)
 	defer span.End()
 
+	if err := req.Coordinate.ValidateIn(s.sridBounds); err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "invalid coordinate")
+		return nil, err
+	}
+
 	// Get source info
 	pkg, err := s.registry.GetSource(ctx, sourceID)
 	if err != nil {
@@ -207,30 +375,54 @@ func (s *QueryService) QueryPointInSource(ctx context.Context, sourceID string,
 		output.Int("ortus.layers.count", len(pkg.Layers)),
 	)
 
+	layers := pkg.Layers
+	if s.deadlineTight(ctx) {
+		layers = s.stats.prioritize(sourceID, layers)
+		span.AddEvent("layers prioritized by hit-rate")
+	}
+
 	// Query each layer
 	maxReached := false
-	for _, layer := range pkg.Layers {
+	for _, layer := range layers {
+		if ctx.Err() != nil {
+			result.Incomplete = true
+			break
+		}
 		if s.queryLayer(ctx, sourceID, &layer, &req, result) {
 			maxReached = true
 			break // max features reached
 		}
 	}
 
+	if result.HasFeatures() {
+		s.popularity.Record(sourceID)
+	}
+
 	result.QueryTime = time.Since(start)
-	s.queryDuration.Record(ctx, result.QueryTime.Seconds(), metric.WithAttributes(
-		attribute.String("source_id", sourceID),
-	))
+	s.queryDuration.Record(ctx, result.QueryTime.Seconds(), s.sourceAttrs(sourceID, ""))
 
 	span.SetAttributes(
 		output.Int("ortus.features.count", result.FeatureCount()),
 		output.Bool("ortus.max_features_reached", maxReached),
 		output.Float64("ortus.duration_ms", float64(result.QueryTime.Microseconds())/1000.0),
+		output.Bool("ortus.incomplete", result.Incomplete),
 	)
 	span.SetStatus(output.StatusOK, "")
 
 	return result, nil
 }
 
+// deadlineTight reports whether layer prioritization applies: it is enabled
+// (configured and not switched off by FlagLayerPrioritization) and less than
+// prioritizeWithin remains before the context deadline.
+func (s *QueryService) deadlineTight(ctx context.Context) bool {
+	if s.prioritizeWithin <= 0 || !s.flags.Enabled(ctx, domain.FlagLayerPrioritization, true) {
+		return false
+	}
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) < s.prioritizeWithin
+}
+
 // queryLayer queries a single layer and appends results. Returns true if max features reached.
 func (s *QueryService) queryLayer(ctx context.Context, sourceID string, layer *domain.Layer, req *domain.QueryRequest, result *domain.QueryResult) bool {
 	ctx, span := s.tracer.Start(ctx, "QueryService.queryLayer",
@@ -243,16 +435,38 @@ func (s *QueryService) queryLayer(ctx context.Context, sourceID string, layer *d
 	)
 	defer span.End()
 
+	// explain is nil, and records nothing, unless the query is explained.
+	start := time.Now()
+	explain := domain.ExplainFrom(ctx).Layer(sourceID, layer.Name)
+	defer explain.Finish(start)
+
 	queryCoord, ok := s.transformCoordinate(ctx, req.Coordinate, layer)
+	explain.Transformed(req.Coordinate, queryCoord, time.Since(start))
 	if !ok {
 		// ok=false covers an unsupported SRID mismatch (no transformer) and a
 		// failed/canceled transform; transformCoordinate logs the specific reason.
 		span.AddEvent("layer skipped (coordinate not transformable)")
+		explain.Skip("coordinate not transformable to the layer SRID")
+		if ctx.Err() != nil {
+			result.Incomplete = true
+		}
 		return false
 	}
 
-	features, err := s.registry.Query(ctx, sourceID, layer.Name, queryCoord)
+	geom := req.Geometry
+	if !geom.IsDefault() && !s.flags.Enabled(ctx, domain.FlagGeometryEncoding, true) {
+		span.AddEvent("geometry encoding switched off by feature flag")
+		geom = domain.GeometryOptions{}
+	}
+	queryStart := time.Now()
+	features, err := s.registry.QueryEncoded(ctx, sourceID, layer.Name, queryCoord, geom)
+	s.logIfSlow(ctx, sourceID, layer.Name, req.Coordinate, time.Since(queryStart))
 	if err != nil {
+		explain.Skip("query failed: " + err.Error())
+		if ctx.Err() != nil {
+			// The deadline (or the client) cut this layer short.
+			result.Incomplete = true
+		}
 		if isCanceled(err) {
 			// Expected when the client aborts the request (e.g. the map UI
 			// cancels the previous in-flight query) — not a server failure.
@@ -265,12 +479,33 @@ func (s *QueryService) queryLayer(ctx context.Context, sourceID string, layer *d
 		return false
 	}
 
+	if req.Coordinate.Z != 0 && !layer.Height.IsZero() {
+		features = filterHeight(features, layer.Height, req.Coordinate.Z)
+	}
+	// The adapter says how a feature matched; the match was evaluated in the
+	// layer's SRID, which only the query point was transformed to.
+	for i := range features {
+		if features[i].Match.Kind != "" {
+			features[i].Match.SRID = layer.SRID
+		}
+	}
+	s.stats.record(sourceID, layer.Name, len(features) > 0)
+
+	// The layer's configured policy comes first: a client can narrow the
+	// properties further, never widen them.
+	layer.PropertyPolicy.Apply(features)
 	if len(req.Properties) > 0 {
 		features = s.filterProperties(features, req.Properties)
 	}
 
 	features, maxReached := s.applyMaxFeaturesLimit(features, result)
+	explain.Kept(len(features))
 	result.Features = append(result.Features, features...)
+	if len(features) > 0 {
+		for _, text := range layer.Notes {
+			result.Notes = append(result.Notes, domain.LayerNote{Layer: layer.Name, Text: text})
+		}
+	}
 
 	span.SetAttributes(
 		output.Int("ortus.layer.features.count", len(features)),
@@ -279,6 +514,37 @@ func (s *QueryService) queryLayer(ctx context.Context, sourceID string, layer *d
 	return maxReached
 }
 
+// logIfSlow logs and counts a layer query that took longer than the slow
+// query threshold, so a pathological layer shows up by name rather than only
+// in the tail of the duration histogram.
+func (s *QueryService) logIfSlow(ctx context.Context, sourceID, layer string, coord domain.Coordinate, d time.Duration) {
+	if s.slowThreshold <= 0 || d <= s.slowThreshold {
+		return
+	}
+	s.logger.Warn("slow layer query",
+		"source", sourceID,
+		"layer", layer,
+		"x", coord.X,
+		"y", coord.Y,
+		"srid", coord.SRID,
+		"duration_ms", float64(d.Microseconds())/1000,
+		"threshold_ms", s.slowThreshold.Milliseconds(),
+	)
+	s.slowQueries.Add(ctx, 1, s.sourceAttrs(sourceID, layer))
+}
+
+// sourceAttrs returns the labels of a query metric: attrs, with the
+// source_id and, when set, the layer unless source labels are omitted.
+func (s *QueryService) sourceAttrs(sourceID, layer string, attrs ...attribute.KeyValue) metric.MeasurementOption {
+	if !s.omitSourceLabels {
+		attrs = append(attrs, attribute.String("source_id", sourceID))
+		if layer != "" {
+			attrs = append(attrs, attribute.String("layer", layer))
+		}
+	}
+	return metric.WithAttributes(attrs...)
+}
+
 // transformCoordinate transforms the coordinate to the layer's SRID if needed.
 func (s *QueryService) transformCoordinate(ctx context.Context, coord domain.Coordinate, layer *domain.Layer) (domain.Coordinate, bool) {
 	if coord.SRID == layer.SRID {
@@ -325,18 +591,31 @@ func isCanceled(err error) bool {
 
 // applyMaxFeaturesLimit limits features to not exceed maxFeatures. Returns true if limit reached.
 func (s *QueryService) applyMaxFeaturesLimit(features []domain.Feature, result *domain.QueryResult) ([]domain.Feature, bool) {
+	maxFeatures := int(s.maxFeatures.Load())
 	total := len(result.Features) + len(features)
-	if total <= s.maxFeatures {
+	if total <= maxFeatures {
 		return features, false
 	}
 
-	remaining := s.maxFeatures - len(result.Features)
+	remaining := maxFeatures - len(result.Features)
 	if remaining > 0 {
 		return features[:remaining], true
 	}
 	return nil, true
 }
 
+// filterHeight keeps the features whose height range spans z. It runs before
+// filterProperties, which may drop the height attributes.
+func filterHeight(features []domain.Feature, h domain.LayerHeight, z float64) []domain.Feature {
+	kept := make([]domain.Feature, 0, len(features))
+	for _, f := range features {
+		if h.Spans(f.Properties, z) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
 // filterProperties filters feature properties to only include requested ones.
 func (s *QueryService) filterProperties(features []domain.Feature, properties []string) []domain.Feature {
 	propSet := make(map[string]bool, len(properties))
@@ -0,0 +1,128 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// QueryAggregate aggregates the features q selects in every layer of every
+// ready source (or those in sources), one aggregation per layer computed by
+// the adapter. A property a layer's PropertyPolicy hides is treated as one
+// the layer lacks. Like QueryBatch it isolates a failing source or layer
+// (logged, it contributes nothing) and aborts on cancellation or the query
+// deadline.
+func (s *QueryService) QueryAggregate(ctx context.Context, q domain.AggregateQuery, sources []string) (*domain.AggregateResponse, error) {
+	start := time.Now()
+	if err := q.ValidateIn(s.sridBounds); err != nil {
+		return nil, err
+	}
+	if timeout := time.Duration(s.queryTimeout.Load()); timeout > 0 {
+		if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+	ctx, span := s.tracer.Start(ctx, "QueryService.QueryAggregate",
+		output.WithAttributes(
+			output.Bool("ortus.aggregate.area", q.Area != nil),
+			output.String("ortus.aggregate.group_by", q.GroupBy),
+			output.Int("ortus.aggregate.stats", len(q.Stats)),
+		),
+	)
+	defer span.End()
+
+	sourceIDs, err := s.resolveBatchSources(sources)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "resolve sources")
+		return nil, err
+	}
+
+	resp := &domain.AggregateResponse{}
+	for _, sid := range sourceIDs {
+		result, err := s.aggregateSource(ctx, sid, q)
+		if err != nil {
+			if isContextErr(err) {
+				span.RecordError(err)
+				span.SetStatus(output.StatusError, "aggregation canceled or timed out")
+				return nil, err
+			}
+			s.logger.Warn("aggregation failed for source", "source", sid, "error", err)
+			continue
+		}
+		if len(result.Layers) == 0 {
+			continue
+		}
+		for _, l := range result.Layers {
+			resp.TotalCount += l.Count
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	resp.ProcessingTime = time.Since(start)
+	span.SetAttributes(output.Int64("ortus.aggregate.count", resp.TotalCount))
+	span.SetStatus(output.StatusOK, "")
+	return resp, nil
+}
+
+// aggregateSource aggregates each layer of one source.
+func (s *QueryService) aggregateSource(ctx context.Context, sid string, q domain.AggregateQuery) (domain.AggregateResult, error) {
+	pkg, err := s.registry.GetSource(ctx, sid)
+	if err != nil {
+		return domain.AggregateResult{}, err
+	}
+	result := domain.AggregateResult{SourceID: pkg.ID, SourceName: pkg.Name}
+	for li := range pkg.Layers {
+		layer := &pkg.Layers[li]
+		lq, ok := s.layerAggregateQuery(ctx, q, layer)
+		if !ok {
+			if err := ctx.Err(); err != nil {
+				return domain.AggregateResult{}, err
+			}
+			continue
+		}
+		agg, err := s.registry.Aggregate(ctx, sid, layer.Name, lq)
+		if err != nil {
+			if isContextErr(err) {
+				return domain.AggregateResult{}, err
+			}
+			if !errors.Is(err, domain.ErrUnsupported) {
+				s.logger.Warn("layer aggregation failed", "source", sid, "layer", layer.Name, "error", err)
+			}
+			continue
+		}
+		result.Layers = append(result.Layers, agg)
+	}
+	return result, nil
+}
+
+// layerAggregateQuery adapts q to a layer: its area transformed into the
+// layer's SRID, and the properties the layer's policy hides dropped. ok is
+// false when the area cannot be transformed.
+func (s *QueryService) layerAggregateQuery(ctx context.Context, q domain.AggregateQuery, layer *domain.Layer) (domain.AggregateQuery, bool) {
+	if q.Area != nil {
+		area, ok := s.transformArea(ctx, *q.Area, layer)
+		if !ok {
+			return domain.AggregateQuery{}, false
+		}
+		q.Area = &area
+	}
+	if layer.PropertyPolicy.IsZero() {
+		return q, true
+	}
+	if q.GroupBy != "" && !layer.PropertyPolicy.Allows(q.GroupBy) {
+		q.GroupBy = ""
+	}
+	stats := make([]string, 0, len(q.Stats))
+	for _, name := range q.Stats {
+		if layer.PropertyPolicy.Allows(name) {
+			stats = append(stats, name)
+		}
+	}
+	q.Stats = stats
+	return q, true
+}
@@ -0,0 +1,47 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// aggregateRepository is a mockRepository that also aggregates, recording
+// the queries it got.
+type aggregateRepository struct {
+	*mockRepository
+	queries []domain.AggregateQuery
+}
+
+func (m *aggregateRepository) Aggregate(_ context.Context, _, layer string, q domain.AggregateQuery) (domain.LayerAggregate, error) {
+	m.queries = append(m.queries, q)
+	return domain.LayerAggregate{Layer: layer, Count: 2}, nil
+}
+
+// TestQueryAggregatePolicy: the properties a layer's policy hides do not
+// reach the adapter, and only sources that aggregate contribute.
+func TestQueryAggregatePolicy(t *testing.T) {
+	registry := batchTestRegistry()
+	repo := &aggregateRepository{mockRepository: registry.sources["pkg1"].Repo.(*mockRepository)}
+	registry.sources["pkg1"].Repo = repo
+	registry.sources["pkg1"].Source.Layers[0].PropertyPolicy = domain.PropertyPolicy{Redact: []string{"code", "pop"}}
+	svc := newTestQueryService(registry)
+
+	resp, err := svc.QueryAggregate(context.Background(), domain.AggregateQuery{
+		GroupBy: "code",
+		Stats:   []string{"pop", "area"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("QueryAggregate: %v", err)
+	}
+	if len(repo.queries) != 1 {
+		t.Fatalf("adapter queries = %d, want 1", len(repo.queries))
+	}
+	if q := repo.queries[0]; q.GroupBy != "" || len(q.Stats) != 1 || q.Stats[0] != "area" {
+		t.Errorf("adapter query = %+v, want no group_by and stats [area]", q)
+	}
+	if resp.TotalCount != 2 || len(resp.Results) != 1 || resp.Results[0].SourceID != "pkg1" {
+		t.Errorf("response = %+v, want pkg1 with 2", resp)
+	}
+}
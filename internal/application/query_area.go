@@ -0,0 +1,47 @@
+package application
+
+import (
+	"context"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// QueryArea returns the features selected by an area: for every ready source
+// (or those in sources) the features of each layer in relation rel to the
+// polygon, one query per layer (see queryShape).
+func (s *QueryService) QueryArea(ctx context.Context, area domain.Area, rel domain.SpatialRelation, sources, properties []string) (*domain.QueryResponse, error) {
+	if err := area.ValidateIn(s.sridBounds); err != nil {
+		return nil, err
+	}
+	attrs := []output.Attribute{
+		output.Int("ortus.area.points", area.Points()),
+		output.Int("ortus.area.srid", area.SRID),
+		output.String("ortus.area.relation", string(rel)),
+	}
+	return s.queryShape(ctx, "QueryService.QueryArea", attrs, sources, properties,
+		func(ctx context.Context, sid string, layer *domain.Layer) ([]domain.Feature, error) {
+			la, ok := s.transformArea(ctx, area, layer)
+			if !ok {
+				return nil, errSkipLayer
+			}
+			return s.registry.QueryArea(ctx, sid, layer.Name, la, rel)
+		})
+}
+
+// transformArea transforms every ring of the area to the layer's SRID; ok is
+// false when a point cannot be (see transformCoordinate).
+func (s *QueryService) transformArea(ctx context.Context, area domain.Area, layer *domain.Layer) (domain.Area, bool) {
+	if area.SRID == layer.SRID {
+		return area, true
+	}
+	rings := make([][]domain.Coordinate, len(area.Rings))
+	for i, ring := range area.Rings {
+		points, ok := s.transformPoints(ctx, ring, layer)
+		if !ok {
+			return domain.Area{}, false
+		}
+		rings[i] = points
+	}
+	return domain.Area{Rings: rings, SRID: layer.SRID}, true
+}
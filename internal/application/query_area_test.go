@@ -0,0 +1,62 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// areaRepository is a mockRepository that also answers area queries, with
+// the features of the layer as for a point query.
+type areaRepository struct {
+	*mockRepository
+	relations []domain.SpatialRelation
+}
+
+func (m *areaRepository) QueryArea(ctx context.Context, sourceID, layer string, _ domain.Area, rel domain.SpatialRelation) ([]domain.Feature, error) {
+	m.relations = append(m.relations, rel)
+	features, err := m.QueryPoint(ctx, sourceID, layer, domain.Coordinate{})
+	for i := range features {
+		features[i].Match.Kind = rel.MatchKind()
+	}
+	return features, err
+}
+
+func testArea() domain.Area {
+	return domain.NewArea([][]domain.Coordinate{{{X: 10, Y: 50}, {X: 11, Y: 50}, {X: 11, Y: 51}, {X: 10, Y: 50}}}, domain.SRIDWGS84)
+}
+
+// TestQueryAreaRelation: the relation reaches the adapter and only sources
+// whose adapter answers area queries contribute.
+func TestQueryAreaRelation(t *testing.T) {
+	registry := batchTestRegistry()
+	repo := &areaRepository{mockRepository: registry.sources["pkg2"].Repo.(*mockRepository)}
+	registry.sources["pkg2"].Repo = repo
+	svc := newTestQueryService(registry)
+
+	resp, err := svc.QueryArea(context.Background(), testArea(), domain.RelationWithin, nil, nil)
+	if err != nil {
+		t.Fatalf("QueryArea: %v", err)
+	}
+	if len(repo.relations) != 1 || repo.relations[0] != domain.RelationWithin {
+		t.Errorf("relations = %v, want [within]", repo.relations)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].SourceID != "pkg2" {
+		t.Fatalf("results = %+v, want only pkg2", resp.Results)
+	}
+	if kind := resp.Results[0].Features[0].Match.Kind; kind != domain.MatchWithin {
+		t.Errorf("match kind = %q, want within", kind)
+	}
+}
+
+// TestQueryAreaInvalid: an open ring is rejected before any source is
+// queried.
+func TestQueryAreaInvalid(t *testing.T) {
+	svc := newTestQueryService(batchTestRegistry())
+	open := domain.NewArea([][]domain.Coordinate{{{X: 10, Y: 50}, {X: 11, Y: 50}, {X: 11, Y: 51}, {X: 10, Y: 51}}}, domain.SRIDWGS84)
+	if _, err := svc.QueryArea(context.Background(), open, domain.RelationIntersects, nil, nil); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -17,10 +17,10 @@ import (
 // source contributes nothing) so one bad source never drops the whole batch.
 func (s *QueryService) QueryBatch(ctx context.Context, coords []domain.Coordinate, sources, properties []string) ([]*domain.QueryResponse, error) {
 	start := time.Now()
-	if s.queryTimeout > 0 {
+	if timeout := time.Duration(s.queryTimeout.Load()); timeout > 0 {
 		if _, hasDeadline := ctx.Deadline(); !hasDeadline {
 			var cancel context.CancelFunc
-			ctx, cancel = context.WithTimeout(ctx, s.queryTimeout)
+			ctx, cancel = context.WithTimeout(ctx, timeout)
 			defer cancel()
 		}
 	}
@@ -59,6 +59,7 @@ func (s *QueryService) QueryBatch(ctx context.Context, coords []domain.Coordinat
 
 	elapsed := time.Since(start)
 	for i := range out {
+		s.dedupeResponse(span, out[i])
 		out[i].ProcessingTime = elapsed
 	}
 	span.SetAttributes(output.Int("ortus.batch.sources_queried", len(sourceIDs)))
@@ -134,6 +135,7 @@ func (s *QueryService) queryBatchSource(ctx context.Context, sid string, coords
 // so the batch aborts instead of reporting a misleading empty result; any other
 // adapter error is isolated (logged, this layer contributes nothing).
 func (s *QueryService) batchLayer(ctx context.Context, sid string, layer *domain.Layer, coords []domain.Coordinate, properties []string, results []domain.QueryResult) error {
+	maxFeatures := int(s.maxFeatures.Load())
 	tc := make([]domain.Coordinate, 0, len(coords))
 	idxs := make([]int, 0, len(coords))
 	for i, c := range coords {
@@ -144,7 +146,7 @@ func (s *QueryService) batchLayer(ctx context.Context, sid string, layer *domain
 		// as an empty result rather than failing the whole request), so QueryBatch
 		// applies the same coordinate validation as QueryPoint even when a caller
 		// bypasses the HTTP handler's pre-validation.
-		if len(results[i].Features) >= s.maxFeatures || c.Validate() != nil {
+		if len(results[i].Features) >= maxFeatures || c.ValidateIn(s.sridBounds) != nil {
 			continue
 		}
 		if qc, ok := s.transformCoordinate(ctx, c, layer); ok {
@@ -165,6 +167,7 @@ func (s *QueryService) batchLayer(ctx context.Context, sid string, layer *domain
 	}
 	for k, origIdx := range idxs {
 		f := feats[k]
+		layer.PropertyPolicy.Apply(f)
 		if len(properties) > 0 {
 			f = s.filterProperties(f, properties)
 		}
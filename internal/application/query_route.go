@@ -0,0 +1,56 @@
+package application
+
+import (
+	"context"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// QueryRoute returns the features along a route: for every ready source (or
+// those in sources) the features of each layer whose geometry the line
+// intersects, one query per layer (see queryShape).
+func (s *QueryService) QueryRoute(ctx context.Context, route domain.Route, sources, properties []string) (*domain.QueryResponse, error) {
+	if err := route.ValidateIn(s.sridBounds); err != nil {
+		return nil, err
+	}
+	attrs := []output.Attribute{
+		output.Int("ortus.route.points", len(route.Points)),
+		output.Int("ortus.route.srid", route.SRID),
+	}
+	return s.queryShape(ctx, "QueryService.QueryRoute", attrs, sources, properties,
+		func(ctx context.Context, sid string, layer *domain.Layer) ([]domain.Feature, error) {
+			lr, ok := s.transformRoute(ctx, route, layer)
+			if !ok {
+				return nil, errSkipLayer
+			}
+			return s.registry.QueryRoute(ctx, sid, layer.Name, lr)
+		})
+}
+
+// transformRoute transforms every point of the route to the layer's SRID; ok
+// is false when one of them cannot be (see transformCoordinate).
+func (s *QueryService) transformRoute(ctx context.Context, route domain.Route, layer *domain.Layer) (domain.Route, bool) {
+	if route.SRID == layer.SRID {
+		return route, true
+	}
+	points, ok := s.transformPoints(ctx, route.Points, layer)
+	if !ok {
+		return domain.Route{}, false
+	}
+	return domain.Route{Points: points, SRID: layer.SRID}, true
+}
+
+// transformPoints transforms points to the layer's SRID; ok is false when
+// one of them cannot be.
+func (s *QueryService) transformPoints(ctx context.Context, points []domain.Coordinate, layer *domain.Layer) ([]domain.Coordinate, bool) {
+	out := make([]domain.Coordinate, len(points))
+	for i, p := range points {
+		tp, ok := s.transformCoordinate(ctx, p, layer)
+		if !ok {
+			return nil, false
+		}
+		out[i] = tp
+	}
+	return out, true
+}
@@ -0,0 +1,91 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// routeRepository is a mockRepository that also answers route queries, with
+// the features of the layer as for a point query.
+type routeRepository struct {
+	*mockRepository
+	routes int
+}
+
+func (m *routeRepository) QueryRoute(ctx context.Context, sourceID, layer string, _ domain.Route) ([]domain.Feature, error) {
+	m.routes++
+	features, err := m.QueryPoint(ctx, sourceID, layer, domain.Coordinate{})
+	for i := range features {
+		features[i].Match.Kind = domain.MatchIntersects
+	}
+	return features, err
+}
+
+// routeTestRegistry is batchTestRegistry with pkg1 owned by a route-capable
+// repository and pkg2 by one that is not.
+func routeTestRegistry() (*SourceRegistry, *routeRepository) {
+	registry := batchTestRegistry()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	repo := &routeRepository{mockRepository: registry.sources["pkg1"].Repo.(*mockRepository)}
+	registry.sources["pkg1"].Repo = repo
+	return registry, repo
+}
+
+func testRoute() domain.Route {
+	return domain.NewRoute([]domain.Coordinate{{X: 10, Y: 50}, {X: 11, Y: 51}}, domain.SRIDWGS84)
+}
+
+// TestQueryRouteSkipsUnsupportedSources: only sources whose adapter answers
+// route queries contribute; the others are left out without failing.
+func TestQueryRouteSkipsUnsupportedSources(t *testing.T) {
+	registry, repo := routeTestRegistry()
+	svc := newTestQueryService(registry)
+
+	resp, err := svc.QueryRoute(context.Background(), testRoute(), nil, []string{"name"})
+	if err != nil {
+		t.Fatalf("QueryRoute: %v", err)
+	}
+	if repo.routes != 1 {
+		t.Errorf("route queries = %d, want 1", repo.routes)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].SourceID != "pkg1" {
+		t.Fatalf("results = %+v, want only pkg1", resp.Results)
+	}
+	f := resp.Results[0].Features[0]
+	if f.Match.Kind != domain.MatchIntersects || f.Match.SRID != domain.SRIDWGS84 {
+		t.Errorf("match = %+v, want intersects in 4326", f.Match)
+	}
+	if _, ok := f.Properties["code"]; ok {
+		t.Errorf("properties = %v, want only name", f.Properties)
+	}
+}
+
+// TestQueryRouteInvalid: a route that does not validate is rejected before
+// any source is queried.
+func TestQueryRouteInvalid(t *testing.T) {
+	registry, repo := routeTestRegistry()
+	svc := newTestQueryService(registry)
+
+	short := domain.NewRoute([]domain.Coordinate{{X: 10, Y: 50}}, domain.SRIDWGS84)
+	if _, err := svc.QueryRoute(context.Background(), short, nil, nil); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("error = %v, want ErrInvalidInput", err)
+	}
+	if repo.routes != 0 {
+		t.Errorf("route queries = %d, want 0", repo.routes)
+	}
+}
+
+// TestQueryRouteUnknownSource: a source filter naming an unknown source is
+// ErrSourceNotFound, as for batch queries.
+func TestQueryRouteUnknownSource(t *testing.T) {
+	registry, _ := routeTestRegistry()
+	svc := newTestQueryService(registry)
+
+	if _, err := svc.QueryRoute(context.Background(), testRoute(), []string{"nope"}, nil); !errors.Is(err, domain.ErrSourceNotFound) {
+		t.Errorf("error = %v, want ErrSourceNotFound", err)
+	}
+}
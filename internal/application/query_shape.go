@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// errSkipLayer is returned by a layerShapeQuery whose shape cannot be
+// transformed into the layer's SRID; the layer contributes nothing.
+var errSkipLayer = errors.New("layer skipped")
+
+// layerShapeQuery queries one layer of a source by a shape (a route, an
+// area), transforming the shape into the layer's SRID first.
+type layerShapeQuery func(ctx context.Context, sourceID string, layer *domain.Layer) ([]domain.Feature, error)
+
+// queryShape runs query on every layer of every ready source (or those in
+// sources) and collects the results in one response without a coordinate.
+// Like QueryBatch it isolates a failing source or layer (logged, it
+// contributes nothing) and aborts on cancellation or the query deadline.
+func (s *QueryService) queryShape(ctx context.Context, spanName string, attrs []output.Attribute, sources, properties []string, query layerShapeQuery) (*domain.QueryResponse, error) {
+	start := time.Now()
+	if timeout := time.Duration(s.queryTimeout.Load()); timeout > 0 {
+		if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+	ctx, span := s.tracer.Start(ctx, spanName, output.WithAttributes(attrs...))
+	defer span.End()
+
+	sourceIDs, err := s.resolveBatchSources(sources)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "resolve sources")
+		return nil, err
+	}
+
+	resp := &domain.QueryResponse{}
+	for _, sid := range sourceIDs {
+		result, err := s.queryShapeSource(ctx, sid, properties, query)
+		if err != nil {
+			if isContextErr(err) {
+				span.RecordError(err)
+				span.SetStatus(output.StatusError, "query canceled or timed out")
+				return nil, err
+			}
+			s.logger.Warn("shape query failed for source", "source", sid, "span", spanName, "error", err)
+			continue
+		}
+		if result.HasFeatures() {
+			resp.AddResult(result)
+		}
+	}
+	s.dedupeResponse(span, resp)
+	resp.ProcessingTime = time.Since(start)
+	span.SetAttributes(output.Int("ortus.features.count", resp.TotalFeatures))
+	span.SetStatus(output.StatusOK, "")
+	return resp, nil
+}
+
+// queryShapeSource runs query on each layer of one source.
+func (s *QueryService) queryShapeSource(ctx context.Context, sid string, properties []string, query layerShapeQuery) (domain.QueryResult, error) {
+	start := time.Now()
+	pkg, err := s.registry.GetSource(ctx, sid)
+	if err != nil {
+		return domain.QueryResult{}, err
+	}
+	result := domain.QueryResult{SourceID: pkg.ID, SourceName: pkg.Name, License: pkg.License}
+	for li := range pkg.Layers {
+		layer := &pkg.Layers[li]
+		if len(result.Features) >= int(s.maxFeatures.Load()) {
+			break
+		}
+		features, err := query(ctx, sid, layer)
+		if err != nil {
+			if isContextErr(err) {
+				return domain.QueryResult{}, err
+			}
+			if errors.Is(err, errSkipLayer) {
+				if err := ctx.Err(); err != nil {
+					return domain.QueryResult{}, err
+				}
+			} else if !errors.Is(err, domain.ErrUnsupported) {
+				s.logger.Warn("shape layer query failed", "source", sid, "layer", layer.Name, "error", err)
+			}
+			continue
+		}
+		for i := range features {
+			features[i].Match.SRID = layer.SRID
+		}
+		layer.PropertyPolicy.Apply(features)
+		if len(properties) > 0 {
+			features = s.filterProperties(features, properties)
+		}
+		features, _ = s.applyMaxFeaturesLimit(features, &result)
+		result.Features = append(result.Features, features...)
+		if len(features) > 0 {
+			for _, text := range layer.Notes {
+				result.Notes = append(result.Notes, domain.LayerNote{Layer: layer.Name, Text: text})
+			}
+		}
+	}
+	result.QueryTime = time.Since(start)
+	return result, nil
+}
@@ -1,15 +1,21 @@
 package application
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 
 	"github.com/jobrunner/ortus/internal/domain"
 	"github.com/jobrunner/ortus/internal/ports/output"
@@ -46,8 +52,8 @@ func TestQueryServiceDefaultConfig(t *testing.T) {
 		QueryServiceConfig{}, // Empty config
 	)
 
-	if svc.maxFeatures != 1000 {
-		t.Errorf("maxFeatures = %d, want 1000", svc.maxFeatures)
+	if got := svc.maxFeatures.Load(); got != 1000 {
+		t.Errorf("maxFeatures = %d, want 1000", got)
 	}
 }
 
@@ -243,7 +249,8 @@ func TestQueryServiceFilterProperties(t *testing.T) {
 }
 
 func TestQueryServiceApplyMaxFeaturesLimit(t *testing.T) {
-	svc := &QueryService{maxFeatures: 5}
+	svc := &QueryService{}
+	svc.maxFeatures.Store(5)
 
 	tests := []struct {
 		name           string
@@ -357,3 +364,415 @@ func TestIsCanceled(t *testing.T) {
 		})
 	}
 }
+
+// staticFlags is a fixed output.FeatureFlags for the kill-switch tests; flags
+// it does not list evaluate to the caller's fallback.
+type staticFlags map[domain.FeatureFlag]bool
+
+func (f staticFlags) Enabled(_ context.Context, flag domain.FeatureFlag, fallback bool) bool {
+	if v, ok := f[flag]; ok {
+		return v
+	}
+	return fallback
+}
+
+// TestQueryServiceFeatureFlagsOff: with layer_prioritization off a tight
+// deadline keeps package order, and with geometry_encoding off the requested
+// geometry options never reach the encoding adapter.
+func TestQueryServiceFeatureFlagsOff(t *testing.T) {
+	ctx := context.Background()
+	enc := &encodingRepository{mockRepository: mockRepository{
+		packages: map[string]*domain.Source{
+			"/tmp/src.gpkg": {ID: "src", Path: "/tmp/src.gpkg", Layers: []domain.Layer{
+				{Name: "first", SRID: 4326}, {Name: "second", SRID: 4326},
+			}},
+		},
+		features: map[string][]domain.Feature{
+			"src:first":  {{ID: 1, LayerName: "first"}},
+			"src:second": {{ID: 2, LayerName: "second"}},
+		},
+	}}
+	reg := NewSourceRegistry([]output.SpatialSource{enc}, &mockStorage{}, testMeter(), output.NoOpTracer{},
+		slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})), "/tmp")
+	if err := reg.LoadSource(ctx, "/tmp/src.gpkg"); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewQueryService(reg, nil, testMeter(), output.NoOpTracer{}, testLogger(),
+		QueryServiceConfig{MaxFeatures: 1, PrioritizeWithin: time.Minute})
+	svc.SetFeatureFlags(staticFlags{
+		domain.FlagLayerPrioritization: false,
+		domain.FlagGeometryEncoding:    false,
+	})
+	for range 3 {
+		svc.stats.record("src", "first", false)
+	}
+
+	tight, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	res, err := svc.QueryPointInSource(tight, "src", domain.QueryRequest{
+		Coordinate: domain.NewWGS84Coordinate(1, 1),
+		Geometry:   domain.GeometryOptions{Format: domain.GeometryFormatGML},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Features[0].LayerName; got != "first" {
+		t.Errorf("prioritization off: first layer = %q, want package order (first)", got)
+	}
+	if enc.got != nil {
+		t.Errorf("geometry encoding off: encoder reached with %+v", enc.got)
+	}
+}
+
+// TestQueryLayerNotes: a layer's notes are attached to the result only when
+// the layer contributed features.
+func TestQueryLayerNotes(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepository{
+		packages: map[string]*domain.Source{
+			"/tmp/src.gpkg": {ID: "src", Path: "/tmp/src.gpkg", Layers: []domain.Layer{
+				{Name: "soil", SRID: 4326, Notes: []string{"accurate to ±100 m", "surveyed 1987"}},
+				{Name: "empty", SRID: 4326, Notes: []string{"never shown"}},
+				{Name: "plain", SRID: 4326},
+			}},
+		},
+		features: map[string][]domain.Feature{
+			"src:soil":  {{ID: 1, LayerName: "soil"}},
+			"src:plain": {{ID: 2, LayerName: "plain"}},
+		},
+	}
+	reg := NewSourceRegistry([]output.SpatialSource{repo}, &mockStorage{}, testMeter(), output.NoOpTracer{}, testLogger(), "/tmp")
+	if err := reg.LoadSource(ctx, "/tmp/src.gpkg"); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewQueryService(reg, nil, testMeter(), output.NoOpTracer{}, testLogger(), QueryServiceConfig{})
+
+	res, err := svc.QueryPointInSource(ctx, "src", domain.QueryRequest{Coordinate: domain.NewWGS84Coordinate(1, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.LayerNote{
+		{Layer: "soil", Text: "accurate to ±100 m"},
+		{Layer: "soil", Text: "surveyed 1987"},
+	}
+	if !reflect.DeepEqual(res.Notes, want) {
+		t.Errorf("notes = %+v, want %+v", res.Notes, want)
+	}
+}
+
+func TestQueryLayerHeight(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepository{
+		packages: map[string]*domain.Source{
+			"/tmp/src.gpkg": {ID: "src", Path: "/tmp/src.gpkg", Layers: []domain.Layer{
+				{Name: "buildings", SRID: 4326, Height: domain.LayerHeight{MinAttribute: "base", MaxAttribute: "roof"}},
+			}},
+		},
+		features: map[string][]domain.Feature{
+			"src:buildings": {
+				{ID: 1, LayerName: "buildings", Properties: map[string]interface{}{"base": 0.0, "roof": 12.0}},
+				{ID: 2, LayerName: "buildings", Properties: map[string]interface{}{"base": 12.0, "roof": 40.0}},
+				{ID: 3, LayerName: "buildings", Properties: map[string]interface{}{"name": "no height data"}},
+			},
+		},
+	}
+	reg := NewSourceRegistry([]output.SpatialSource{repo}, &mockStorage{}, testMeter(), output.NoOpTracer{}, testLogger(), "/tmp")
+	if err := reg.LoadSource(ctx, "/tmp/src.gpkg"); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewQueryService(reg, nil, testMeter(), output.NoOpTracer{}, testLogger(), QueryServiceConfig{})
+
+	for _, tt := range []struct {
+		z    float64
+		want []int64
+	}{
+		{z: 25, want: []int64{2, 3}},
+		{z: 50, want: []int64{3}},
+		{z: 0, want: []int64{1, 2, 3}}, // no Z: no height filter
+	} {
+		coord := domain.NewWGS84Coordinate(1, 1)
+		coord.Z = tt.z
+		res, err := svc.QueryPointInSource(ctx, "src", domain.QueryRequest{Coordinate: coord})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int64
+		for _, f := range res.Features {
+			got = append(got, f.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("z=%v: features = %v, want %v", tt.z, got, tt.want)
+		}
+	}
+}
+
+func TestQueryLayerMatchSRID(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepository{
+		packages: map[string]*domain.Source{
+			"/tmp/src.gpkg": {ID: "src", Path: "/tmp/src.gpkg", Layers: []domain.Layer{{Name: "roads", SRID: 4326}}},
+		},
+		features: map[string][]domain.Feature{
+			"src:roads": {
+				{ID: 1, LayerName: "roads", Match: domain.Match{Kind: domain.MatchBBox, DistanceM: 7}},
+				{ID: 2, LayerName: "roads"},
+			},
+		},
+	}
+	reg := NewSourceRegistry([]output.SpatialSource{repo}, &mockStorage{}, testMeter(), output.NoOpTracer{}, testLogger(), "/tmp")
+	if err := reg.LoadSource(ctx, "/tmp/src.gpkg"); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewQueryService(reg, nil, testMeter(), output.NoOpTracer{}, testLogger(), QueryServiceConfig{})
+
+	res, err := svc.QueryPointInSource(ctx, "src", domain.QueryRequest{Coordinate: domain.NewWGS84Coordinate(1, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (domain.Match{Kind: domain.MatchBBox, DistanceM: 7, SRID: 4326}); res.Features[0].Match != want {
+		t.Errorf("match = %+v, want %+v", res.Features[0].Match, want)
+	}
+	if res.Features[1].Match != (domain.Match{}) {
+		t.Errorf("feature without a match got %+v", res.Features[1].Match)
+	}
+}
+
+func TestQueryServiceExplain(t *testing.T) {
+	registry := newTestRegistry()
+	repo := &mockRepository{
+		features: map[string][]domain.Feature{
+			"pkg:wgs": {{ID: 1, LayerName: "wgs"}, {ID: 2, LayerName: "wgs"}},
+		},
+	}
+	registry.mu.Lock()
+	registry.sources["pkg"] = &sourceEntry{
+		Source: &domain.Source{
+			ID:      "pkg",
+			Indexed: true,
+			Layers: []domain.Layer{
+				{Name: "wgs", SRID: domain.SRIDWGS84, HasIndex: true},
+				{Name: "utm", SRID: 25832, HasIndex: true},
+			},
+		},
+		Repo:   repo,
+		Status: domain.StatusReady,
+	}
+	registry.mu.Unlock()
+
+	svc := newTestQueryService(registry)
+	svc.transformer = &mockTransformer{}
+	explain := &domain.QueryExplain{}
+	ctx := domain.WithExplain(context.Background(), explain)
+	if _, err := svc.QueryPoint(ctx, domain.QueryRequest{Coordinate: domain.NewWGS84Coordinate(10, 50)}); err != nil {
+		t.Fatalf("QueryPoint: %v", err)
+	}
+
+	layers := explain.Layers()
+	if len(layers) != 2 {
+		t.Fatalf("explained %d layers, want 2", len(layers))
+	}
+	wgs, utm := layers[0], layers[1]
+	if wgs.Layer != "wgs" || wgs.Features != 2 || wgs.Transform != nil || wgs.Skipped != "" {
+		t.Errorf("wgs layer = %+v, want 2 features and no transform", wgs)
+	}
+	if utm.Transform == nil || utm.Transform.FromSRID != domain.SRIDWGS84 || utm.Transform.ToSRID != 25832 {
+		t.Errorf("utm transform = %+v, want 4326 -> 25832", utm.Transform)
+	}
+	if wgs.Total < wgs.TransformTime {
+		t.Errorf("wgs timings = %v total, %v transform", wgs.Total, wgs.TransformTime)
+	}
+
+	// Without an explain in the context nothing is recorded.
+	if domain.ExplainFrom(context.Background()).Layer("pkg", "wgs") != nil {
+		t.Error("nil QueryExplain returned a layer record")
+	}
+}
+
+func TestQueryServiceLogsSlowLayerQueries(t *testing.T) {
+	var buf bytes.Buffer
+	svc := NewQueryService(newTestRegistry(), nil, testMeter(), output.NoOpTracer{},
+		slog.New(slog.NewTextHandler(&buf, nil)),
+		QueryServiceConfig{SlowThreshold: 100 * time.Millisecond})
+	coord := domain.NewWGS84Coordinate(10, 50)
+
+	svc.logIfSlow(context.Background(), "pkg", "fast", coord, 100*time.Millisecond)
+	if buf.Len() != 0 {
+		t.Fatalf("query at the threshold was logged: %s", buf.String())
+	}
+	svc.logIfSlow(context.Background(), "pkg", "parcels", coord, 250*time.Millisecond)
+	line := buf.String()
+	for _, want := range []string{`msg="slow layer query"`, "source=pkg", "layer=parcels", "x=10", "y=50", "srid=4326", "duration_ms=250"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log line %q lacks %s", line, want)
+		}
+	}
+
+	buf.Reset()
+	svc.slowThreshold = 0
+	svc.logIfSlow(context.Background(), "pkg", "parcels", coord, time.Hour)
+	if buf.Len() != 0 {
+		t.Errorf("slow query logged with the log disabled: %s", buf.String())
+	}
+}
+
+func TestQueryServiceRedactsLayerProperties(t *testing.T) {
+	registry := newTestRegistry()
+	repo := &mockRepository{
+		features: map[string][]domain.Feature{
+			"test-pkg:parcels": {
+				{ID: 1, LayerName: "parcels", Properties: map[string]interface{}{"id": "A1", "owner": "Jane Doe"}},
+			},
+		},
+	}
+	registry.mu.Lock()
+	registry.sources["test-pkg"] = &sourceEntry{
+		Source: &domain.Source{
+			ID:      "test-pkg",
+			Indexed: true,
+			Layers: []domain.Layer{{
+				Name: "parcels", GeometryType: "POLYGON", SRID: 4326, HasIndex: true,
+				PropertyPolicy: domain.PropertyPolicy{Redact: []string{"owner"}},
+			}},
+		},
+		Repo:   repo,
+		Status: domain.StatusReady,
+	}
+	registry.mu.Unlock()
+
+	svc := newTestQueryService(registry)
+	// Asking for the redacted property explicitly must not bring it back.
+	resp, err := svc.QueryPoint(context.Background(), domain.QueryRequest{
+		Coordinate: domain.NewWGS84Coordinate(10, 50),
+		Properties: []string{"id", "owner"},
+	})
+	if err != nil {
+		t.Fatalf("QueryPoint failed: %v", err)
+	}
+	props := resp.Results[0].Features[0].Properties
+	if _, ok := props["owner"]; ok {
+		t.Errorf("properties = %v, owner must be redacted", props)
+	}
+	if props["id"] != "A1" {
+		t.Errorf("properties = %v, want id kept", props)
+	}
+}
+
+func TestQueryPointDedupe(t *testing.T) {
+	square := domain.Geometry{WKT: "POLYGON((0 0,1 0,1 1,0 1,0 0))", SRID: 4326}
+	feature := domain.Feature{ID: 7, LayerName: "districts", Geometry: square}
+	layers := []domain.Layer{{Name: "districts", SRID: 4326}}
+	sources := map[string]*domain.Source{
+		"/tmp/national.gpkg": {ID: "national", Path: "/tmp/national.gpkg", Layers: layers},
+		"/tmp/regional.gpkg": {ID: "regional", Path: "/tmp/regional.gpkg", Layers: layers, Priority: 1},
+	}
+	repo := &mockRepository{packages: sources, features: map[string][]domain.Feature{
+		"national:districts": {feature},
+		"regional:districts": {feature},
+	}}
+	reg := NewSourceRegistry([]output.SpatialSource{repo}, &mockStorage{}, testMeter(), output.NoOpTracer{}, testLogger(), "/tmp")
+	for path := range sources {
+		if err := reg.LoadSource(context.Background(), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := domain.QueryRequest{Coordinate: domain.NewWGS84Coordinate(0.5, 0.5)}
+
+	for _, dedupe := range []bool{false, true} {
+		svc := NewQueryService(reg, nil, testMeter(), output.NoOpTracer{}, testLogger(),
+			QueryServiceConfig{MaxFeatures: 100, Dedupe: dedupe})
+		resp, err := svc.QueryPoint(context.Background(), req)
+		if err != nil {
+			t.Fatalf("dedupe=%v: %v", dedupe, err)
+		}
+		want := 2
+		if dedupe {
+			want = 1
+		}
+		if resp.TotalFeatures != want || len(resp.Results) != want {
+			t.Errorf("dedupe=%v: %d features in %d results, want %d", dedupe, resp.TotalFeatures, len(resp.Results), want)
+		}
+		// The higher-priority source is queried first and keeps the feature.
+		if dedupe && len(resp.Results) == 1 && resp.Results[0].SourceID != "regional" {
+			t.Errorf("kept from %q, want regional", resp.Results[0].SourceID)
+		}
+	}
+}
+
+// TestQueryServiceOmitSourceLabels: with source labels omitted the queries
+// of every source count in one series per status.
+func TestQueryServiceOmitSourceLabels(t *testing.T) {
+	ctx := context.Background()
+	for _, omit := range []bool{false, true} {
+		repo := &mockRepository{packages: map[string]*domain.Source{
+			"/tmp/a.gpkg": {ID: "a", Path: "/tmp/a.gpkg", Layers: []domain.Layer{{Name: "l", SRID: 4326}}},
+			"/tmp/b.gpkg": {ID: "b", Path: "/tmp/b.gpkg", Layers: []domain.Layer{{Name: "l", SRID: 4326}}},
+		}}
+		reg := NewSourceRegistry([]output.SpatialSource{repo}, &mockStorage{}, testMeter(), output.NoOpTracer{}, testLogger(), "/tmp")
+		for _, p := range []string{"/tmp/a.gpkg", "/tmp/b.gpkg"} {
+			if err := reg.LoadSource(ctx, p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		reader := sdkmetric.NewManualReader()
+		meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+		svc := NewQueryService(reg, nil, meter, output.NoOpTracer{}, testLogger(), QueryServiceConfig{OmitSourceLabels: omit})
+		if _, err := svc.QueryPoint(ctx, domain.QueryRequest{Coordinate: domain.NewWGS84Coordinate(1, 1)}); err != nil {
+			t.Fatal(err)
+		}
+
+		var rm metricdata.ResourceMetrics
+		if err := reader.Collect(ctx, &rm); err != nil {
+			t.Fatal(err)
+		}
+		series := 0
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				sum, ok := m.Data.(metricdata.Sum[int64])
+				if m.Name != "ortus.queries" || !ok {
+					continue
+				}
+				for _, dp := range sum.DataPoints {
+					series++
+					if _, labeled := dp.Attributes.Value("source_id"); labeled == omit {
+						t.Errorf("omit=%v: series %v", omit, dp.Attributes.ToSlice())
+					}
+				}
+			}
+		}
+		if want := map[bool]int{false: 2, true: 1}[omit]; series != want {
+			t.Errorf("omit=%v: %d ortus.queries series, want %d", omit, series, want)
+		}
+	}
+}
+
+// TestQueryServiceSRIDBounds: configured bounds reject an implausible point
+// in every query shape, including the single-source query.
+func TestQueryServiceSRIDBounds(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepository{packages: map[string]*domain.Source{
+		"/tmp/a.gpkg": {ID: "a", Path: "/tmp/a.gpkg", Layers: []domain.Layer{{Name: "l", SRID: 2056}}},
+	}}
+	reg := NewSourceRegistry([]output.SpatialSource{repo}, &mockStorage{}, testMeter(), output.NoOpTracer{}, testLogger(), "/tmp")
+	if err := reg.LoadSource(ctx, "/tmp/a.gpkg"); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewQueryService(reg, nil, testMeter(), output.NoOpTracer{}, testLogger(), QueryServiceConfig{
+		SRIDBounds: domain.SRIDBounds{2056: {MinX: 2480000, MinY: 1070000, MaxX: 2840000, MaxY: 1300000}},
+	})
+
+	inside := domain.QueryRequest{Coordinate: domain.NewCoordinate(2600000, 1200000, 2056)}
+	swapped := domain.QueryRequest{Coordinate: domain.NewCoordinate(1200000, 2600000, 2056)}
+	if _, err := svc.QueryPoint(ctx, inside); err != nil {
+		t.Errorf("QueryPoint inside the bounds: %v", err)
+	}
+	if _, err := svc.QueryPoint(ctx, swapped); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("QueryPoint swapped: err = %v, want ErrInvalidInput", err)
+	}
+	if _, err := svc.QueryPointInSource(ctx, "a", swapped); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("QueryPointInSource swapped: err = %v, want ErrInvalidInput", err)
+	}
+	route := domain.NewRoute([]domain.Coordinate{{X: 2600000, Y: 1200000}, {X: 1200000, Y: 2600000}}, 2056)
+	if _, err := svc.QueryRoute(ctx, route, nil, nil); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("QueryRoute through a swapped point: err = %v, want ErrInvalidInput", err)
+	}
+}
@@ -3,10 +3,13 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -16,6 +19,7 @@ import (
 	"go.opentelemetry.io/otel/metric/noop"
 
 	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
 	"github.com/jobrunner/ortus/internal/ports/output"
 )
 
@@ -28,6 +32,32 @@ type SourceRegistry struct {
 	tracer    output.Tracer
 	logger    *slog.Logger
 	localPath string
+	ids       domain.SourceIDDeriver // zero value derives filename stems
+	// local maps keys to files for a storage that is already local and may
+	// span several directories; nil joins keys onto localPath.
+	local LocalObjects
+	// overrides replace derived display values per source id. Guarded by mu
+	// (a config reload replaces them at runtime).
+	overrides map[string]domain.SourceOverride
+	// failures holds the last failed fetch or open of each source that is not
+	// loaded, so GET /sources/{id}/health can explain why it is missing.
+	// Guarded by mu.
+	failures map[string]loadFailure
+	// audit records loads and unloads; nil disables auditing.
+	audit *AuditLog
+	// cache bounds the space downloads take in localPath; nil leaves it
+	// unbounded.
+	cache *DiskCache
+	// leader, when set, restricts downloads, indexing and deletions in
+	// localPath to the elected replica; see SetLeadership.
+	leader leadership
+	// candidates holds the staged candidate version of sources, by source
+	// id; see StageCandidate. Guarded by mu; stageMu serializes staging,
+	// promotion and discarding.
+	candidates map[string]*stagedSource
+	stageMu    sync.Mutex
+	// maintenance, while on, blocks staging and promotion; nil never does.
+	maintenance *MaintenanceMode
 
 	// Observable gauge state. Atomic so the OTel callback (which can fire
 	// from a metric-export goroutine) doesn't race with mutations under
@@ -37,6 +67,12 @@ type SourceRegistry struct {
 	// failedCount reflects how many sources failed in the last LoadAll pass.
 	failedCount atomic.Int64
 
+	// LoadAll progress, reported on /health while the startup load runs in
+	// the background.
+	loading       atomic.Bool
+	loadTotal     atomic.Int64
+	loadProcessed atomic.Int64
+
 	// initialLoadDone latches true once the first LoadAll pass completes (even
 	// with zero or partially-failed sources). Readiness uses it so the service
 	// reports not-ready only during the initial bring-up, not when later sync
@@ -47,6 +83,114 @@ type SourceRegistry struct {
 // InitialLoadComplete reports whether the first LoadAll pass has finished.
 func (r *SourceRegistry) InitialLoadComplete() bool { return r.initialLoadDone.Load() }
 
+// SetAudit installs the audit log that records source loads and unloads.
+// nil switches auditing off. Call before the first LoadAll.
+func (r *SourceRegistry) SetAudit(a *AuditLog) {
+	r.audit = a
+}
+
+// SetMaintenance installs the maintenance switch that blocks staging and
+// promoting candidates while it is on. nil removes it. Call once at startup.
+func (r *SourceRegistry) SetMaintenance(m *MaintenanceMode) {
+	r.maintenance = m
+}
+
+// LocalObjects is implemented by a storage whose objects already are local
+// files (local storage, possibly over several directories).
+type LocalObjects interface {
+	// LocalPath returns the file for key, rejecting keys that escape it.
+	LocalPath(key string) (string, error)
+	// ObjectKey maps a file back to its key; false when it is not served.
+	ObjectKey(path string) (string, bool)
+}
+
+// SetLocalObjects makes the registry load local storage objects where they
+// are instead of joining keys onto the local path. Call once at startup,
+// before the first LoadAll.
+func (r *SourceRegistry) SetLocalObjects(l LocalObjects) {
+	r.local = l
+}
+
+// SetCache bounds the local cache dir: downloads first evict unloaded
+// sources the cache holds. Call once at startup, before the first LoadAll.
+func (r *SourceRegistry) SetCache(c *DiskCache) {
+	r.cache = c
+}
+
+// SetSourceIDDeriver sets the strategy that maps object keys to source ids.
+// Call once at startup, before the first LoadAll.
+func (r *SourceRegistry) SetSourceIDDeriver(d domain.SourceIDDeriver) {
+	r.ids = d
+}
+
+// SetSourceOverrides installs the configured per-source display values
+// (packages.<id>.*). Keys are matched exactly, then lower-cased: the config
+// loader lower-cases map keys, so "Parcels" must find "parcels". Call at
+// startup, before the first LoadAll; at runtime use ReplaceSourceOverrides.
+func (r *SourceRegistry) SetSourceOverrides(o map[string]domain.SourceOverride) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides = o
+}
+
+// ReplaceSourceOverrides installs new per-source overrides at runtime and
+// reopens every loaded source whose override changed, since the old values
+// were already applied to it. It returns the ids of the reopened sources; a
+// source that fails to reopen is logged and left unloaded, as after any
+// failed load.
+func (r *SourceRegistry) ReplaceSourceOverrides(ctx context.Context, o map[string]domain.SourceOverride) []string {
+	r.mu.Lock()
+	old := r.overrides
+	r.overrides = o
+	paths := make(map[string]string)
+	for id, entry := range r.sources {
+		before, _ := lookupOverride(old, id)
+		after, _ := lookupOverride(o, id)
+		if entry.Source != nil && !reflect.DeepEqual(before, after) {
+			paths[id] = entry.Source.Path
+		}
+	}
+	r.mu.Unlock()
+
+	reopened := make([]string, 0, len(paths))
+	for id, path := range paths {
+		if err := r.LoadSource(ctx, path); err != nil {
+			r.logger.Warn("source not reopened with new overrides", "id", id, "error", err)
+			continue
+		}
+		reopened = append(reopened, id)
+	}
+	sort.Strings(reopened)
+	return reopened
+}
+
+// openSource opens path with provider under id, in the source's configured
+// load mode when it has one and the provider can honor it.
+func (r *SourceRegistry) openSource(ctx context.Context, provider output.SpatialSource, id, path string) (*domain.Source, error) {
+	if o, ok := r.overrideFor(id); ok && o.LoadMode != domain.LoadModeFile {
+		if lo, ok := provider.(output.LoadModeOpener); ok {
+			return lo.OpenWithLoadMode(ctx, id, path, o.LoadMode)
+		}
+		r.logger.Warn("source kind has no load modes — opening normally", "id", id, "load_mode", string(o.LoadMode))
+	}
+	return provider.Open(ctx, id, path)
+}
+
+// overrideFor returns the configured override of a source id, if any.
+func (r *SourceRegistry) overrideFor(id string) (domain.SourceOverride, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return lookupOverride(r.overrides, id)
+}
+
+func lookupOverride(overrides map[string]domain.SourceOverride, id string) (domain.SourceOverride, bool) {
+	if o, ok := overrides[id]; ok {
+		return o, true
+	}
+	o, ok := overrides[strings.ToLower(id)]
+	return o, ok
+}
+
 // loadedSourcePath returns the on-disk path of an already-loaded source, if any.
 func (r *SourceRegistry) loadedSourcePath(id string) (string, bool) {
 	r.mu.RLock()
@@ -61,10 +205,12 @@ func (r *SourceRegistry) loadedSourcePath(id string) (string, bool) {
 }
 
 type sourceEntry struct {
-	Source *domain.Source
-	Repo   output.SpatialSource // adapter that opened this source
-	Status domain.SourceStatus
-	Error  error
+	Source   *domain.Source
+	Repo     output.SpatialSource // adapter that opened this source
+	Status   domain.SourceStatus
+	Error    error     // layer preparation failure of the last load, if any
+	ErrorAt  time.Time // when Error was recorded
+	SyncedAt time.Time // last storage listing that included the source
 }
 
 // NewSourceRegistry creates a new source registry. providers are the source
@@ -87,6 +233,7 @@ func NewSourceRegistry(
 
 	r := &SourceRegistry{
 		sources:   make(map[string]*sourceEntry),
+		failures:  make(map[string]loadFailure),
 		providers: providers,
 		storage:   storage,
 		tracer:    tracer,
@@ -123,22 +270,33 @@ func NewSourceRegistry(
 }
 
 // LoadSource loads a GeoPackage from the given path.
-func (r *SourceRegistry) LoadSource(ctx context.Context, path string) error {
+func (r *SourceRegistry) LoadSource(ctx context.Context, path string) (err error) {
 	ctx, span := r.tracer.Start(ctx, "SourceRegistry.LoadSource",
 		output.WithAttributes(output.String("ortus.source.path", path)),
 	)
 	defer span.End()
+	defer func() { r.audit.Record(ctx, domain.AuditLoad, r.objectKey(path), "", err) }()
 
 	r.logger.Info("loading source", "path", path)
 
-	// Reload vs collision: a source id is the filename stem, so two different
-	// files can derive the same id (e.g. "foo.gpkg" and "foo.zip").
-	id := domain.DeriveSourceID(path)
+	// Resolve the adapter that owns this file kind.
+	provider, err := r.providerFor(path)
+	if err != nil {
+		r.recordFailure(r.DeriveSourceID(path), err)
+		r.logger.Error("no adapter for source", "path", path, "error", err)
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "no adapter")
+		return err
+	}
+
+	// Reload vs collision: two different files can derive the same id (e.g.
+	// "foo.gpkg" and "foo.zip" under the filename strategy).
+	id := r.sourceIDFor(ctx, provider, path)
 	if existingPath, loaded := r.loadedSourcePath(id); loaded {
 		if existingPath != path {
 			// Different file, same id — reject rather than silently evicting the
-			// already-loaded source. The operator must rename one (ids must be
-			// unique across all source files, regardless of extension).
+			// already-loaded source. The operator must rename one or pick a
+			// source id strategy that keeps them apart.
 			err := fmt.Errorf("%w: %q is already loaded as id %q, refusing %q",
 				domain.ErrSourceIDCollision, existingPath, id, path)
 			r.logger.Error("source id collision", "id", id, "existing", existingPath, "incoming", path)
@@ -155,18 +313,10 @@ func (r *SourceRegistry) LoadSource(ctx context.Context, path string) error {
 		}
 	}
 
-	// Resolve the adapter that owns this file kind.
-	provider, err := r.providerFor(path)
-	if err != nil {
-		r.logger.Error("no adapter for source", "path", path, "error", err)
-		span.RecordError(err)
-		span.SetStatus(output.StatusError, "no adapter")
-		return err
-	}
-
 	// Open the source
-	src, err := provider.Open(ctx, path)
+	src, err := r.openSource(ctx, provider, id, path)
 	if err != nil {
+		r.recordFailure(id, err)
 		r.logger.Error("failed to open source", "path", path, "error", err)
 		span.RecordError(err)
 		span.SetStatus(output.StatusError, "open failed")
@@ -179,6 +329,28 @@ func (r *SourceRegistry) LoadSource(ctx context.Context, path string) error {
 		output.Int("ortus.layers.count", len(src.Layers)),
 	)
 
+	if o, ok := r.overrideFor(src.ID); ok {
+		layers := len(src.Layers)
+		o.Apply(src)
+		r.logger.Debug("applied configured source overrides", "id", src.ID, "name", src.Name)
+		if dropped := layers - len(src.Layers); dropped > 0 {
+			r.logger.Info("layers excluded by configuration", "id", src.ID, "excluded", dropped, "kept", len(src.Layers))
+		}
+	}
+
+	// A layer whose SRID is undefined or unresolvable typically answers every
+	// query with nothing; say so at load rather than leave operators guessing.
+	for _, l := range src.Layers {
+		if l.SRIDWarning != "" {
+			r.logger.Warn("layer has a suspect SRID — queries may return no features",
+				"id", src.ID, "layer", l.Name, "srid", l.SRID, "warning", l.SRIDWarning)
+		}
+		if l.InvalidGeometries > 0 {
+			r.logger.Warn("layer has invalid geometries — queries may miss their features",
+				"id", src.ID, "layer", l.Name, "invalid", l.InvalidGeometries, "features", l.FeatureCount)
+		}
+	}
+
 	// License/attribution should travel with every source so it can be surfaced
 	// in query responses and the sources listing. Missing it is not fatal, but
 	// warn loudly so operators notice a package that will show no attribution.
@@ -194,13 +366,16 @@ func (r *SourceRegistry) LoadSource(ctx context.Context, path string) error {
 		Repo:   provider,
 		Status: domain.StatusIndexing,
 	}
+	delete(r.failures, src.ID)
 	r.mu.Unlock()
 
 	// Prepare all layers (builds spatial indices for vector sources; a no-op
 	// for sources that are ready on open).
+	var prepareErrs []error
 	for _, layer := range src.Layers {
 		r.logger.Debug("preparing layer", "source", src.ID, "layer", layer.Name)
 		if err := provider.Prepare(ctx, src.ID, layer.Name); err != nil {
+			prepareErrs = append(prepareErrs, fmt.Errorf("layer %q: %w", layer.Name, err))
 			r.logger.Warn("failed to prepare layer", "source", src.ID, "layer", layer.Name, "error", err)
 			span.AddEvent("layer preparation failed",
 				output.String("ortus.layer.name", layer.Name),
@@ -217,6 +392,10 @@ func (r *SourceRegistry) LoadSource(ctx context.Context, path string) error {
 		entry.Status = domain.StatusReady
 		entry.Source.LoadedAt = time.Now()
 		entry.Source.Indexed = allLayersIndexed(entry.Source.Layers)
+		if len(prepareErrs) > 0 {
+			entry.Error = errors.Join(prepareErrs...)
+			entry.ErrorAt = time.Now()
+		}
 	}
 	r.mu.Unlock()
 
@@ -244,6 +423,10 @@ func (r *SourceRegistry) UnloadSource(ctx context.Context, sourceID string) erro
 	}
 	entry.Status = domain.StatusUnloading
 	repo := entry.Repo
+	path := ""
+	if entry.Source != nil {
+		path = entry.Source.Path
+	}
 	if repo == nil {
 		// Malformed entry with no owning adapter: nothing to close, but it
 		// must not be left stuck in StatusUnloading — drop it.
@@ -256,6 +439,7 @@ func (r *SourceRegistry) UnloadSource(ctx context.Context, sourceID string) erro
 
 	if err := repo.Close(ctx, sourceID); err != nil {
 		r.logger.Error("failed to close source", "id", sourceID, "error", err)
+		r.audit.Record(ctx, domain.AuditUnload, sourceID, "", err)
 		span.RecordError(err)
 		span.SetStatus(output.StatusError, "close failed")
 		return err
@@ -265,11 +449,67 @@ func (r *SourceRegistry) UnloadSource(ctx context.Context, sourceID string) erro
 	delete(r.sources, sourceID)
 	r.mu.Unlock()
 
+	r.cache.Touch(path)
+	r.audit.Record(ctx, domain.AuditUnload, sourceID, "", nil)
 	r.updateMetrics()
 	span.SetStatus(output.StatusOK, "")
 	return nil
 }
 
+// sourceIDFor derives the id a file loads under. Under the metadata strategy
+// the owning adapter is asked for the package's declared identifier first,
+// sanitized like a regex-derived id; every other case (and a package without one) derives it from the object key.
+func (r *SourceRegistry) sourceIDFor(ctx context.Context, provider output.SpatialSource, path string) string {
+	if r.ids.Strategy() == domain.SourceIDMetadata {
+		if ident, ok := provider.(output.SourceIdentifier); ok {
+			id, err := ident.Identify(ctx, path)
+			if err != nil {
+				r.logger.Warn("failed to read source identifier — deriving id from key", "path", path, "error", err)
+			} else if id != "" {
+				return domain.SanitizeSourceID(id)
+			}
+		}
+	}
+	return r.ids.Derive(r.objectKey(path))
+}
+
+// objectKey returns path relative to the local cache dir — the object key the
+// file was downloaded from — or path unchanged when it lies outside it.
+func (r *SourceRegistry) objectKey(path string) string {
+	if r.local != nil {
+		if key, ok := r.local.ObjectKey(path); ok {
+			return key
+		}
+		return path
+	}
+	if r.localPath == "" {
+		return path
+	}
+	rel, err := filepath.Rel(r.localPath, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+// IsPathLoaded reports whether a loaded source is backed by the file at path.
+func (r *SourceRegistry) IsPathLoaded(path string) bool {
+	_, ok := r.loadedSourceID(path)
+	return ok
+}
+
+// loadedSourceID returns the id of the source loaded from path, if any.
+func (r *SourceRegistry) loadedSourceID(path string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for id, entry := range r.sources {
+		if entry.Source != nil && entry.Source.Path == path {
+			return id, true
+		}
+	}
+	return "", false
+}
+
 // allLayersIndexed reports whether every layer has its index/preparation done.
 // An empty layer set is vacuously indexed.
 func allLayersIndexed(layers []domain.Layer) bool {
@@ -296,15 +536,28 @@ func (r *SourceRegistry) providerFor(path string) (output.SpatialSource, error)
 // adapter that owns it. This is the seam the query service uses so it stays
 // agnostic of the source kind.
 func (r *SourceRegistry) Query(ctx context.Context, sourceID, layer string, coord domain.Coordinate) ([]domain.Feature, error) {
-	r.mu.RLock()
-	entry, ok := r.sources[sourceID]
-	r.mu.RUnlock()
-	if !ok || entry.Repo == nil {
-		// entry.Repo is always set by LoadSource; guard anyway so a
-		// malformed entry surfaces a clean error instead of a nil panic.
+	entry, adapterID, ok := r.queryEntry(ctx, sourceID)
+	if !ok {
 		return nil, domain.ErrSourceNotFound
 	}
-	return entry.Repo.QueryPoint(ctx, sourceID, layer, coord)
+	return entry.Repo.QueryPoint(ctx, adapterID, layer, coord)
+}
+
+// QueryEncoded is Query with the geometry options applied to the returned
+// features. Adapters implementing output.GeometryEncoder apply them at query
+// time; any other adapter answers as Query does (full-resolution WKT only).
+func (r *SourceRegistry) QueryEncoded(ctx context.Context, sourceID, layer string, coord domain.Coordinate, opts domain.GeometryOptions) ([]domain.Feature, error) {
+	if opts.IsDefault() {
+		return r.Query(ctx, sourceID, layer, coord)
+	}
+	entry, adapterID, ok := r.queryEntry(ctx, sourceID)
+	if !ok {
+		return nil, domain.ErrSourceNotFound
+	}
+	if enc, isEncoder := entry.Repo.(output.GeometryEncoder); isEncoder {
+		return enc.QueryPointEncoded(ctx, adapterID, layer, coord, opts)
+	}
+	return entry.Repo.QueryPoint(ctx, adapterID, layer, coord)
 }
 
 // QueryPoints is the batch seam: it resolves many coordinates against one layer,
@@ -313,14 +566,12 @@ func (r *SourceRegistry) Query(ctx context.Context, sourceID, layer string, coor
 // in one set-based query; otherwise (e.g. raster) it loops QueryPoint per point,
 // so callers get identical results regardless of adapter.
 func (r *SourceRegistry) QueryPoints(ctx context.Context, sourceID, layer string, coords []domain.Coordinate) ([][]domain.Feature, error) {
-	r.mu.RLock()
-	entry, ok := r.sources[sourceID]
-	r.mu.RUnlock()
-	if !ok || entry.Repo == nil {
+	entry, adapterID, ok := r.queryEntry(ctx, sourceID)
+	if !ok {
 		return nil, domain.ErrSourceNotFound
 	}
 	if bq, isBatch := entry.Repo.(output.BatchQuerier); isBatch {
-		return bq.QueryPoints(ctx, sourceID, layer, coords)
+		return bq.QueryPoints(ctx, adapterID, layer, coords)
 	}
 	// Fallback: adapter has no batch path — loop the per-point query. Honor
 	// context cancellation between points so a disconnected client (or a large
@@ -330,7 +581,7 @@ func (r *SourceRegistry) QueryPoints(ctx context.Context, sourceID, layer string
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
-		feats, err := entry.Repo.QueryPoint(ctx, sourceID, layer, c)
+		feats, err := entry.Repo.QueryPoint(ctx, adapterID, layer, c)
 		if err != nil {
 			return nil, err
 		}
@@ -339,7 +590,73 @@ func (r *SourceRegistry) QueryPoints(ctx context.Context, sourceID, layer string
 	return out, nil
 }
 
-// ListSources returns all registered sources.
+// QueryRoute returns the features of one layer a route runs through, through
+// the adapter's output.RouteQuerier. Sources whose adapter lacks it (e.g.
+// raster) answer domain.ErrUnsupported.
+func (r *SourceRegistry) QueryRoute(ctx context.Context, sourceID, layer string, route domain.Route) ([]domain.Feature, error) {
+	entry, adapterID, ok := r.queryEntry(ctx, sourceID)
+	if !ok {
+		return nil, domain.ErrSourceNotFound
+	}
+	rq, ok := entry.Repo.(output.RouteQuerier)
+	if !ok {
+		return nil, domain.ErrUnsupported
+	}
+	return rq.QueryRoute(ctx, adapterID, layer, route)
+}
+
+// QueryArea returns the features of one layer in relation rel to an area,
+// through the adapter's output.AreaQuerier. Sources whose adapter lacks it
+// (e.g. raster) answer domain.ErrUnsupported.
+func (r *SourceRegistry) QueryArea(ctx context.Context, sourceID, layer string, area domain.Area, rel domain.SpatialRelation) ([]domain.Feature, error) {
+	entry, adapterID, ok := r.queryEntry(ctx, sourceID)
+	if !ok {
+		return nil, domain.ErrSourceNotFound
+	}
+	aq, ok := entry.Repo.(output.AreaQuerier)
+	if !ok {
+		return nil, domain.ErrUnsupported
+	}
+	return aq.QueryArea(ctx, adapterID, layer, area, rel)
+}
+
+// Aggregate aggregates the features of one layer, through the adapter's
+// output.Aggregator. Sources whose adapter lacks it (e.g. raster) answer
+// domain.ErrUnsupported.
+func (r *SourceRegistry) Aggregate(ctx context.Context, sourceID, layer string, q domain.AggregateQuery) (domain.LayerAggregate, error) {
+	entry, adapterID, ok := r.queryEntry(ctx, sourceID)
+	if !ok {
+		return domain.LayerAggregate{}, domain.ErrSourceNotFound
+	}
+	ag, ok := entry.Repo.(output.Aggregator)
+	if !ok {
+		return domain.LayerAggregate{}, domain.ErrUnsupported
+	}
+	return ag.Aggregate(ctx, adapterID, layer, q)
+}
+
+// queryEntry returns the entry the queries of source id read and the id its
+// adapter knows it by: the staged candidate when ctx selects
+// domain.StageCandidate and the source has one, else the active source.
+func (r *SourceRegistry) queryEntry(ctx context.Context, id string) (*sourceEntry, string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if domain.StageFrom(ctx) == domain.StageCandidate {
+		if c, ok := r.candidates[id]; ok {
+			return c.entry, candidateAdapterID(id), true
+		}
+	}
+	entry, ok := r.sources[id]
+	// entry.Repo is always set by LoadSource; guard anyway so a malformed
+	// entry surfaces a clean error instead of a nil panic.
+	if !ok || entry.Repo == nil {
+		return nil, "", false
+	}
+	return entry, id, true
+}
+
+// ListSources returns all registered sources, ordered by id so responses
+// listing them are stable across calls.
 func (r *SourceRegistry) ListSources(ctx context.Context) ([]domain.Source, error) {
 	_, span := r.tracer.Start(ctx, "SourceRegistry.ListSources")
 	defer span.End()
@@ -351,6 +668,7 @@ func (r *SourceRegistry) ListSources(ctx context.Context) ([]domain.Source, erro
 	for _, entry := range r.sources {
 		sources = append(sources, *entry.Source)
 	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].ID < sources[j].ID })
 
 	span.SetAttributes(output.Int("ortus.sources.count", len(sources)))
 	return sources, nil
@@ -366,6 +684,9 @@ func (r *SourceRegistry) GetSource(ctx context.Context, id string) (*domain.Sour
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	if c, ok := r.candidates[id]; ok && domain.StageFrom(ctx) == domain.StageCandidate {
+		return c.entry.Source, nil
+	}
 	entry, ok := r.sources[id]
 	if !ok {
 		span.RecordError(domain.ErrSourceNotFound)
@@ -410,7 +731,9 @@ func (r *SourceRegistry) IsReady(sourceID string) bool {
 	return entry.Status == domain.StatusReady
 }
 
-// ReadySourceIDs returns IDs of all ready sources.
+// ReadySourceIDs returns IDs of all ready sources ordered by configured
+// priority, higher first, then by id — the order multi-source queries run in
+// and report their results in.
 func (r *SourceRegistry) ReadySourceIDs() []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -421,6 +744,13 @@ func (r *SourceRegistry) ReadySourceIDs() []string {
 			ids = append(ids, id)
 		}
 	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := r.sources[ids[i]].Source, r.sources[ids[j]].Source
+		if a == nil || b == nil || a.Priority == b.Priority {
+			return ids[i] < ids[j]
+		}
+		return a.Priority > b.Priority
+	})
 	return ids
 }
 
@@ -450,6 +780,12 @@ func (r *SourceRegistry) LoadAll(ctx context.Context) error {
 
 	r.logger.Info("loading all sources from storage")
 
+	r.loadProcessed.Store(0)
+	r.loadTotal.Store(0)
+	r.loading.Store(true)
+	defer r.loading.Store(false)
+	r.clearCandidates()
+
 	objects, err := r.storage.List(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -458,9 +794,17 @@ func (r *SourceRegistry) LoadAll(ctx context.Context) error {
 	}
 
 	span.SetAttributes(output.Int("ortus.storage.objects", len(objects)))
+	objects = r.publishedObjects(objects)
+	r.loadTotal.Store(int64(len(objects)))
 
 	loaded, failed := 0, 0
 	for _, obj := range objects {
+		// Shutdown during a background startup load: stop at the next source
+		// instead of downloading the rest.
+		if ctx.Err() != nil {
+			break
+		}
+		r.loadProcessed.Add(1)
 		// Reject keys that would escape the local cache dir (a hostile remote
 		// store could return "../../etc/..." object keys → arbitrary write).
 		localPath, err := r.safeLocalPath(obj.Key)
@@ -469,11 +813,13 @@ func (r *SourceRegistry) LoadAll(ctx context.Context) error {
 			failed++
 			continue
 		}
-		if err := r.storage.Download(ctx, obj.Key, localPath); err != nil {
+		if err := r.fetch(ctx, r.ids.Derive(obj.Key), obj, localPath); err != nil {
+			r.recordFailure(r.ids.Derive(obj.Key), err)
 			r.logger.Error("failed to download source", "key", obj.Key, "error", err)
 			failed++
 			continue
 		}
+		r.fetchSidecars(ctx, obj.Key, localPath)
 
 		if err := r.LoadSource(ctx, localPath); err != nil {
 			r.logger.Error("failed to load source", "path", localPath, "error", err)
@@ -484,6 +830,11 @@ func (r *SourceRegistry) LoadAll(ctx context.Context) error {
 	}
 
 	r.failedCount.Store(int64(failed))
+	r.markSynced(objects, time.Now())
+	if r.leading() {
+		r.cache.Reconcile(ctx)
+	}
+	r.publishReady()
 	span.SetAttributes(
 		output.Int("ortus.sources.loaded", loaded),
 		output.Int("ortus.sources.failed", failed),
@@ -503,6 +854,15 @@ func (r *SourceRegistry) LoadAll(ctx context.Context) error {
 	return nil
 }
 
+// LoadProgress reports how far the running (or last) LoadAll pass got.
+func (r *SourceRegistry) LoadProgress() input.LoadProgress {
+	return input.LoadProgress{
+		InProgress: r.loading.Load(),
+		Processed:  int(r.loadProcessed.Load()),
+		Total:      int(r.loadTotal.Load()),
+	}
+}
+
 // IsLoaded returns true if a source with the given ID is already loaded.
 func (r *SourceRegistry) IsLoaded(sourceID string) bool {
 	r.mu.RLock()
@@ -521,7 +881,9 @@ func (r *SourceRegistry) SourceCount() int {
 // SyncStats contains statistics from a sync operation.
 type SyncStats struct {
 	Added   int
+	Updated int // replaced objects reloaded; only change notifications report these
 	Removed int
+	Pinned  int // changes to pinned sources, reported and not applied
 }
 
 // Sync synchronizes with remote storage, downloading new sources and removing
@@ -541,68 +903,284 @@ func (r *SourceRegistry) Sync(ctx context.Context) (SyncStats, error) {
 	}
 
 	// Build set of remote source IDs
-	remoteSources := make(map[string]string) // sourceID -> objectKey
+	remoteSources := make(map[string]string)               // sourceID -> objectKey
+	remoteObjects := make(map[string]output.StorageObject) // objectKey -> object
 	for _, obj := range objects {
-		sourceID := domain.DeriveSourceID(obj.Key)
-		remoteSources[sourceID] = obj.Key
+		remoteSources[r.remoteSourceID(obj.Key)] = obj.Key
+		remoteObjects[obj.Key] = obj
 	}
 
 	stats := SyncStats{}
-	stats.Added = r.syncAddNew(ctx, remoteSources)
+	stats.Added, stats.Pinned = r.syncAddNew(ctx, remoteSources, remoteObjects)
+	stats.Pinned += r.checkPinned(remoteSources, remoteObjects)
 
 	// Remove sources that no longer exist in remote storage
 	// We capture both ID and path in findSourcesToRemove to avoid race conditions
 	sourcesToRemove := r.findSourcesToRemove(remoteSources)
 	for _, src := range sourcesToRemove {
+		if _, ok := r.pinFor(src.id); ok {
+			r.logger.Warn("pinned source is gone from remote storage, keeping it", "id", src.id)
+			stats.Pinned++
+			continue
+		}
 		r.logger.Info("removing source not in remote storage", "id", src.id)
-
-		// Unload the source
-		if err := r.UnloadSource(ctx, src.id); err != nil {
+		if err := r.removeSource(ctx, src); err != nil {
 			r.logger.Error("failed to unload removed source", "id", src.id, "error", err)
 			continue
 		}
-
-		// Delete local cache file
-		if src.path != "" {
-			if err := os.Remove(src.path); err != nil && !os.IsNotExist(err) {
-				r.logger.Warn("failed to delete local cache file", "path", src.path, "error", err)
-			} else {
-				r.logger.Debug("deleted local cache file", "path", src.path)
-			}
-		}
-
 		stats.Removed++
 	}
+	r.markSynced(objects, time.Now())
+	r.publishReady()
 
-	r.logger.Info("sync completed", "added", stats.Added, "removed", stats.Removed, "total", r.SourceCount())
+	r.logger.Info("sync completed", "added", stats.Added, "removed", stats.Removed, "pinned", stats.Pinned, "total", r.SourceCount())
 	span.SetAttributes(
 		output.Int("ortus.sync.added", stats.Added),
 		output.Int("ortus.sync.removed", stats.Removed),
+		output.Int("ortus.sync.pinned", stats.Pinned),
 		output.Int("ortus.sources.total", r.SourceCount()),
 	)
 	span.SetStatus(output.StatusOK, "")
 	return stats, nil
 }
 
+// removeSource unloads a source that is gone from remote storage and deletes
+// its local cache file and sidecars.
+func (r *SourceRegistry) removeSource(ctx context.Context, src sourceToRemove) error {
+	if err := r.UnloadSource(ctx, src.id); err != nil {
+		return err
+	}
+	if src.path == "" || !r.leading() {
+		// A follower leaves the shared cache dir to the leader.
+		return nil
+	}
+	if err := os.Remove(src.path); err != nil && !os.IsNotExist(err) {
+		r.logger.Warn("failed to delete local cache file", "path", src.path, "error", err)
+	} else {
+		r.logger.Debug("deleted local cache file", "path", src.path)
+		r.cache.Forget(src.path)
+	}
+	for _, sidecar := range domain.SidecarNames(src.path) {
+		_ = os.Remove(sidecar)
+	}
+	return nil
+}
+
+// SyncObject applies one change notification from remote storage without
+// listing it: a created or replaced object is downloaded and (re)loaded, a
+// deleted one is unloaded and its local copy removed. Keys of files ortus
+// cannot load are ignored.
+func (r *SourceRegistry) SyncObject(ctx context.Context, key string, deleted bool) (SyncStats, error) {
+	if !domain.IsSupportedSourceFile(key) {
+		return SyncStats{}, nil
+	}
+	id := r.remoteSourceID(key)
+	path, loaded := r.loadedSourcePath(id)
+	if pin, ok := r.pinFor(id); ok && (loaded || deleted || pin.VersionID == "") {
+		// A notification carries no ETag: a pinned source that is not
+		// loaded yet waits for a full sync to check its ETag.
+		r.logger.Warn("change to pinned source not applied", "id", id, "key", key, "deleted", deleted)
+		return SyncStats{Pinned: 1}, nil
+	}
+	if deleted {
+		if !loaded {
+			return SyncStats{}, nil
+		}
+		r.logger.Info("removing source deleted from remote storage", "id", id)
+		if err := r.removeSource(ctx, sourceToRemove{id: id, path: path}); err != nil {
+			return SyncStats{}, err
+		}
+		return SyncStats{Removed: 1}, nil
+	}
+
+	localPath, err := r.safeLocalPath(key)
+	if err != nil {
+		return SyncStats{}, err
+	}
+	if loaded {
+		// The local copy is open; unload it before the download overwrites it.
+		if err := r.UnloadSource(ctx, id); err != nil {
+			r.logger.Warn("failed to unload before replacing", "id", id, "error", err)
+		}
+	}
+	if err := r.fetch(ctx, id, output.StorageObject{Key: key}, localPath); err != nil {
+		r.recordFailure(id, err)
+		return SyncStats{}, fmt.Errorf("downloading %s: %w", key, err)
+	}
+	r.fetchSidecars(ctx, key, localPath)
+	if err := r.LoadSource(ctx, localPath); err != nil {
+		return SyncStats{}, err
+	}
+	r.publishReady()
+	if loaded {
+		return SyncStats{Updated: 1}, nil
+	}
+	return SyncStats{Added: 1}, nil
+}
+
+// SyncSelected refreshes just the selected sources, each as SyncObject
+// does: re-downloaded and reloaded, or unloaded when gone from storage. A
+// source id maps to the key it was loaded from, checked with Exists, so ids
+// alone never list the storage; prefixes list it once and select the keys
+// starting with one — the listed ones refreshed, the loaded ones not listed
+// removed. An id that is not loaded is domain.ErrSourceNotFound, before
+// anything changes. With leader election the selected files would be
+// replaced under the replicas that have them open: domain.ErrUnsupported.
+// A failed source does not stop the others; the errors are joined.
+func (r *SourceRegistry) SyncSelected(ctx context.Context, ids, prefixes []string) (SyncStats, error) {
+	ctx, span := r.tracer.Start(ctx, "SourceRegistry.SyncSelected",
+		output.WithAttributes(output.Int("ortus.sync.ids", len(ids)), output.Int("ortus.sync.prefixes", len(prefixes))),
+	)
+	defer span.End()
+
+	if r.leader != nil {
+		return SyncStats{}, fmt.Errorf("selective sync with leader election: %w", domain.ErrUnsupported)
+	}
+
+	// listed: whether a selected key is in storage, as far as a listing
+	// told; keys selected by id only are checked with Exists.
+	listed := make(map[string]*bool)
+	var keys []string
+	add := func(key string, inStorage *bool) {
+		if _, ok := listed[key]; !ok {
+			keys = append(keys, key)
+		}
+		if inStorage != nil || listed[key] == nil {
+			listed[key] = inStorage
+		}
+	}
+	for _, id := range ids {
+		path, loaded := r.loadedSourcePath(id)
+		key, ok := r.storageKey(path)
+		if !loaded || !ok {
+			return SyncStats{}, fmt.Errorf("%w: %s", domain.ErrSourceNotFound, id)
+		}
+		add(key, nil)
+	}
+	if len(prefixes) > 0 {
+		objects, err := r.storage.List(ctx)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(output.StatusError, "storage list failed")
+			return SyncStats{}, err
+		}
+		yes, no := true, false
+		inStorage := make(map[string]bool, len(objects))
+		for _, obj := range objects {
+			inStorage[obj.Key] = true
+			if hasAnyPrefix(obj.Key, prefixes) {
+				add(obj.Key, &yes)
+			}
+		}
+		for _, path := range r.loadedSourcePaths() {
+			if key, ok := r.storageKey(path); ok && !inStorage[key] && hasAnyPrefix(key, prefixes) {
+				add(key, &no)
+			}
+		}
+	}
+
+	var total SyncStats
+	var errs []error
+	for _, key := range keys {
+		exists := listed[key]
+		if exists == nil {
+			ok, err := r.storage.Exists(ctx, key)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("checking %s: %w", key, err))
+				continue
+			}
+			exists = &ok
+		}
+		stats, err := r.SyncObject(ctx, key, !*exists)
+		total.Added += stats.Added
+		total.Updated += stats.Updated
+		total.Removed += stats.Removed
+		total.Pinned += stats.Pinned
+		if err != nil {
+			r.logger.Error("selective sync failed", "key", key, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	span.SetAttributes(
+		output.Int("ortus.sync.added", total.Added),
+		output.Int("ortus.sync.updated", total.Updated),
+		output.Int("ortus.sync.removed", total.Removed),
+		output.Int("ortus.sync.pinned", total.Pinned),
+	)
+	err := errors.Join(errs...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "selective sync failed")
+		return total, err
+	}
+	span.SetStatus(output.StatusOK, "")
+	return total, nil
+}
+
+// storageKey returns the storage key a source was downloaded from: its
+// path relative to the local cache dir. ok is false for a path outside it.
+func (r *SourceRegistry) storageKey(path string) (string, bool) {
+	rel, err := filepath.Rel(r.localPath, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// loadedSourcePaths returns the paths of all loaded sources.
+func (r *SourceRegistry) loadedSourcePaths() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	paths := make([]string, 0, len(r.sources))
+	for _, entry := range r.sources {
+		if entry.Source != nil {
+			paths = append(paths, entry.Source.Path)
+		}
+	}
+	return paths
+}
+
+// hasAnyPrefix reports whether key starts with one of prefixes.
+func hasAnyPrefix(key string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
 // syncAddNew downloads and loads every remote source not already loaded,
-// returning the number added. Unsafe object keys and download/load failures are
-// logged and skipped (one bad source must not abort the whole sync).
-func (r *SourceRegistry) syncAddNew(ctx context.Context, remoteSources map[string]string) int {
-	added := 0
+// returning the number added and the number a pin held back. Unsafe object
+// keys and download/load failures are logged and skipped (one bad source must
+// not abort the whole sync).
+func (r *SourceRegistry) syncAddNew(ctx context.Context, remoteSources map[string]string, objects map[string]output.StorageObject) (added, pinned int) {
+	published := r.publishedKeys()
 	for sourceID, objectKey := range remoteSources {
 		if r.IsLoaded(sourceID) {
 			r.logger.Debug("source already loaded, skipping", "id", sourceID)
 			continue
 		}
+		if published != nil && !published[objectKey] {
+			r.logger.Debug("source not yet fetched by the sync leader, skipping", "id", sourceID)
+			continue
+		}
 		localPath, err := r.safeLocalPath(objectKey)
 		if err != nil {
 			r.logger.Error("rejecting unsafe storage key", "key", objectKey, "error", err)
 			continue
 		}
-		if err := r.storage.Download(ctx, objectKey, localPath); err != nil {
+		if err := r.fetch(ctx, sourceID, objects[objectKey], localPath); err != nil {
+			r.recordFailure(sourceID, err)
+			if errors.Is(err, domain.ErrPinned) {
+				r.logger.Warn("pinned source not loaded", "id", sourceID, "error", err)
+				pinned++
+				continue
+			}
 			r.logger.Error("failed to download source", "key", objectKey, "error", err)
 			continue
 		}
+		r.fetchSidecars(ctx, objectKey, localPath)
 		if err := r.LoadSource(ctx, localPath); err != nil {
 			r.logger.Error("failed to load source", "path", localPath, "error", err)
 			continue
@@ -610,7 +1188,37 @@ func (r *SourceRegistry) syncAddNew(ctx context.Context, remoteSources map[strin
 		added++
 		r.logger.Info("new source synced", "id", sourceID)
 	}
-	return added
+	return added, pinned
+}
+
+// checkPinned reports the loaded sources pinned to an ETag that storage now
+// lists with another one, returning how many. They stay as they are.
+func (r *SourceRegistry) checkPinned(remoteSources map[string]string, objects map[string]output.StorageObject) int {
+	changed := 0
+	for sourceID, objectKey := range remoteSources {
+		pin, ok := r.pinFor(sourceID)
+		if !ok || pin.ETag == "" || !r.IsLoaded(sourceID) {
+			continue
+		}
+		if obj := objects[objectKey]; !pin.Matches(obj.ETag) {
+			r.logger.Warn("storage holds another version of pinned source, not applied",
+				"id", sourceID, "pinned_etag", pin.ETag, "etag", obj.ETag)
+			changed++
+		}
+	}
+	return changed
+}
+
+// remoteSourceID maps an object key to its source id. A key already loaded
+// keeps the id it was loaded under — under the metadata strategy that id comes
+// from the file itself and cannot be derived from the key alone.
+func (r *SourceRegistry) remoteSourceID(key string) string {
+	if localPath, err := r.safeLocalPath(key); err == nil {
+		if id, ok := r.loadedSourceID(localPath); ok {
+			return id
+		}
+	}
+	return r.ids.Derive(key)
 }
 
 // sourceToRemove holds information about a source that should be removed.
@@ -642,6 +1250,9 @@ func (r *SourceRegistry) findSourcesToRemove(remoteSources map[string]string) []
 // absolute paths and parent-traversal that would escape it (a hostile remote
 // store must not be able to make ortus write outside its data directory).
 func (r *SourceRegistry) safeLocalPath(key string) (string, error) {
+	if r.local != nil {
+		return r.local.LocalPath(key)
+	}
 	clean := filepath.Clean(key)
 	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
 		return "", fmt.Errorf("object key %q escapes the local cache dir", key)
@@ -654,10 +1265,101 @@ func (r *SourceRegistry) safeLocalPath(key string) (string, error) {
 	return joined, nil
 }
 
-// DeriveSourceID derives a source id from a file path or object key (the
-// filename stem), matching the id every adapter assigns. Callers that need to
-// unload/route by path (e.g. the file watcher) should use this rather than an
-// adapter-specific derivation, so the registry stays the single source of truth.
+// pinFor returns the pin configured for a source id, if any.
+func (r *SourceRegistry) pinFor(id string) (domain.Pin, bool) {
+	o, ok := r.overrideFor(id)
+	if !ok || o.Pin.IsZero() {
+		return domain.Pin{}, false
+	}
+	return o.Pin, true
+}
+
+// fetch downloads the object of source id like download, holding it to the
+// source's pin: a version pin downloads that version, an ETag pin refuses an
+// object with another ETag (domain.ErrPinned).
+func (r *SourceRegistry) fetch(ctx context.Context, id string, obj output.StorageObject, localPath string) error {
+	pin, ok := r.pinFor(id)
+	switch {
+	case !ok:
+		return r.download(ctx, obj.Key, obj.Size, localPath)
+	case pin.VersionID != "":
+		return r.downloadVersion(ctx, obj.Key, pin.VersionID, localPath)
+	case !pin.Matches(obj.ETag):
+		return fmt.Errorf("%w to ETag %s: storage has %q for %s", domain.ErrPinned, pin.ETag, obj.ETag, obj.Key)
+	}
+	return r.download(ctx, obj.Key, obj.Size, localPath)
+}
+
+// downloadVersion fetches one version of a source object like download.
+// Storage that keeps no versions answers domain.ErrUnsupported.
+func (r *SourceRegistry) downloadVersion(ctx context.Context, key, versionID, localPath string) error {
+	versioned, ok := r.storage.(output.VersionedStorage)
+	if !ok {
+		return fmt.Errorf("pinned version %s of %s: storage keeps no versions: %w", versionID, key, domain.ErrUnsupported)
+	}
+	if !r.leading() {
+		return nil
+	}
+	// The listed size is the current version's; this one's is unknown.
+	r.cache.Reserve(ctx, 0)
+	if err := versioned.DownloadVersion(ctx, key, versionID, localPath); err != nil {
+		return err
+	}
+	r.cache.Add(localPath)
+	return nil
+}
+
+// download fetches a source object into the local cache dir, first making
+// room for size bytes (0 when unknown) when the cache is bounded. A follower
+// finds the leader's copy there already and fetches nothing.
+func (r *SourceRegistry) download(ctx context.Context, key string, size int64, localPath string) error {
+	if !r.leading() {
+		return nil
+	}
+	r.cache.Reserve(ctx, size)
+	if err := r.storage.Download(ctx, key, localPath); err != nil {
+		return err
+	}
+	r.cache.Add(localPath)
+	return nil
+}
+
+// fetchSidecars mirrors the optional metadata sidecars of a GeoPackage
+// (<name>.meta.yaml / .meta.json) next to its local copy, so the adapter finds
+// them when it opens the file. A sidecar that is gone from storage is removed
+// locally as well; failures only cost the sidecar, never the source.
+func (r *SourceRegistry) fetchSidecars(ctx context.Context, key, localPath string) {
+	if !r.leading() {
+		return
+	}
+	localNames := domain.SidecarNames(localPath)
+	for i, sidecarKey := range domain.SidecarNames(key) {
+		exists, err := r.storage.Exists(ctx, sidecarKey)
+		if err != nil {
+			r.logger.Warn("failed to check metadata sidecar", "key", sidecarKey, "error", err)
+			continue
+		}
+		if !exists {
+			if err := os.Remove(localNames[i]); err != nil && !os.IsNotExist(err) {
+				r.logger.Warn("failed to remove stale metadata sidecar", "path", localNames[i], "error", err)
+			}
+			continue
+		}
+		if err := r.storage.Download(ctx, sidecarKey, localNames[i]); err != nil {
+			r.logger.Warn("failed to download metadata sidecar", "key", sidecarKey, "error", err)
+		}
+	}
+}
+
+// DeriveSourceID returns the source id for a file path, matching the id the
+// registry assigned (or would assign) it. Callers that need to unload/route by
+// path (e.g. the file watcher) should use this rather than an adapter-specific
+// derivation, so the registry stays the single source of truth. A loaded path
+// resolves to its actual id, which also covers a deleted file whose id came
+// from its metadata.
 func (r *SourceRegistry) DeriveSourceID(path string) string {
-	return domain.DeriveSourceID(path)
+	if id, ok := r.loadedSourceID(path); ok {
+		return id
+	}
+	return r.ids.Derive(r.objectKey(path))
 }
@@ -86,6 +86,30 @@ func TestLoadAllCountsLoadedAndFailed(t *testing.T) {
 	if !reg.InitialLoadComplete() {
 		t.Error("initialLoadDone should latch true after LoadAll")
 	}
+	// Every object counts as processed, loaded or not.
+	if p := reg.LoadProgress(); p.InProgress || p.Processed != 3 || p.Total != 3 {
+		t.Errorf("LoadProgress = %+v, want 3/3 done", p)
+	}
+}
+
+// TestLoadAllStopsOnCancel: a shutdown during the background startup load
+// stops it instead of working through the remaining objects.
+func TestLoadAllStopsOnCancel(t *testing.T) {
+	reg := newRegistryWithStorage(&mockStorage{
+		objects: []output.StorageObject{{Key: "a.gpkg"}, {Key: "b.gpkg"}},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := reg.LoadAll(ctx); err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	if got := reg.SourceCount(); got != 0 {
+		t.Errorf("SourceCount = %d, want 0 after cancel", got)
+	}
+	if p := reg.LoadProgress(); p.InProgress || p.Processed != 0 || p.Total != 2 {
+		t.Errorf("LoadProgress = %+v", p)
+	}
 }
 
 // TestLoadAllPropagatesListError verifies a storage.List failure aborts LoadAll
@@ -275,3 +299,56 @@ func TestLoadedSourcePath(t *testing.T) {
 		t.Error("loadedSourcePath(missing) should report false")
 	}
 }
+
+// sidecarStorage serves a fixed set of keys and records what was downloaded.
+type sidecarStorage struct {
+	mockStorage
+	present    map[string]bool
+	downloaded []string
+}
+
+func (s *sidecarStorage) Exists(_ context.Context, key string) (bool, error) {
+	return s.present[key], nil
+}
+
+func (s *sidecarStorage) Download(_ context.Context, key, _ string) error {
+	s.downloaded = append(s.downloaded, key)
+	return nil
+}
+
+// TestLoadAllFetchesSidecars checks that a GeoPackage's metadata sidecar is
+// downloaded with it and that a local sidecar gone from storage is removed.
+func TestLoadAllFetchesSidecars(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "eu", "parcels.meta.json")
+	if err := os.MkdirAll(filepath.Dir(stale), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := &sidecarStorage{
+		mockStorage: mockStorage{objects: []output.StorageObject{{Key: "eu/parcels.gpkg"}, {Key: "dem.zip"}}},
+		present:     map[string]bool{"eu/parcels.meta.yaml": true},
+	}
+	reg := newRegistryWithStorage(storage)
+	reg.localPath = dir
+	if err := reg.LoadAll(context.Background()); err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+
+	want := []string{"eu/parcels.gpkg", "eu/parcels.meta.yaml", "dem.zip"}
+	if len(storage.downloaded) != len(want) {
+		t.Fatalf("downloaded = %v, want %v", storage.downloaded, want)
+	}
+	for i := range want {
+		if storage.downloaded[i] != want[i] {
+			t.Errorf("downloaded = %v, want %v", storage.downloaded, want)
+			break
+		}
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale sidecar still present: stat err = %v", err)
+	}
+}
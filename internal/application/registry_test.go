@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/jobrunner/ortus/internal/domain"
@@ -39,6 +40,228 @@ func TestLoadSourceRejectsIDCollision(t *testing.T) {
 	}
 }
 
+// TestLoadSourceAppliesOverrides verifies configured display values replace
+// the derived ones, with config keys matched case-insensitively.
+func TestLoadSourceAppliesOverrides(t *testing.T) {
+	reg := newTestRegistry()
+	reg.SetSourceOverrides(map[string]domain.SourceOverride{
+		"abc_2024_v3": {Name: "Districts", License: "CC-BY-4.0", Tags: []string{"admin"}},
+	})
+	ctx := context.Background()
+
+	if err := reg.LoadSource(ctx, "/data/ABC_2024_v3.gpkg"); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := reg.LoadSource(ctx, "/data/other.gpkg"); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	src, err := reg.GetSource(ctx, "ABC_2024_v3")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if src.Name != "Districts" || src.License.Name != "CC-BY-4.0" || len(src.Tags) != 1 {
+		t.Errorf("overridden source = %+v", src)
+	}
+	other, _ := reg.GetSource(ctx, "other")
+	if other.Name != "other.gpkg" || len(other.Tags) != 0 {
+		t.Errorf("source without override changed: %+v", other)
+	}
+}
+
+// loadModeRepository is a mockRepository that also implements
+// output.LoadModeOpener, recording the mode each source was opened with.
+type loadModeRepository struct {
+	mockRepository
+	modes map[string]domain.LoadMode
+}
+
+func (m *loadModeRepository) OpenWithLoadMode(ctx context.Context, id, path string, mode domain.LoadMode) (*domain.Source, error) {
+	m.modes[id] = mode
+	return m.Open(ctx, id, path)
+}
+
+// TestLoadSourceOpensWithLoadMode verifies a configured load mode reaches an
+// adapter that supports load modes, and sources without one open normally.
+func TestLoadSourceOpensWithLoadMode(t *testing.T) {
+	repo := &loadModeRepository{modes: map[string]domain.LoadMode{}}
+	reg := NewSourceRegistry([]output.SpatialSource{repo}, &mockStorage{}, testMeter(), output.NoOpTracer{},
+		slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})), "/tmp")
+	reg.SetSourceOverrides(map[string]domain.SourceOverride{
+		"borders": {LoadMode: domain.LoadModeMemory},
+	})
+	ctx := context.Background()
+
+	for _, p := range []string{"/data/borders.gpkg", "/data/parcels.gpkg"} {
+		if err := reg.LoadSource(ctx, p); err != nil {
+			t.Fatalf("LoadSource(%q): %v", p, err)
+		}
+	}
+	if got := repo.modes["borders"]; got != domain.LoadModeMemory {
+		t.Errorf("borders opened with load mode %q, want memory", got)
+	}
+	if _, ok := repo.modes["parcels"]; ok {
+		t.Error("parcels has no load mode and should open through Open")
+	}
+}
+
+// identifyingRepository is a mockRepository that also implements
+// output.SourceIdentifier, declaring ids per path.
+type identifyingRepository struct {
+	mockRepository
+	ids map[string]string
+	err error
+}
+
+func (m *identifyingRepository) Identify(_ context.Context, path string) (string, error) {
+	return m.ids[path], m.err
+}
+
+// TestLoadSourceUsesSourceIDStrategy verifies the configured strategy decides
+// the id a file loads under, so same-named files in different key directories
+// no longer collide under the prefixed strategy.
+func TestLoadSourceUsesSourceIDStrategy(t *testing.T) {
+	reg := newTestRegistry()
+	ids, err := domain.NewSourceIDDeriver(domain.SourceIDPrefixed, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.SetSourceIDDeriver(ids)
+	ctx := context.Background()
+
+	for _, p := range []string{"/tmp/eu/2024-05/parcels.gpkg", "/tmp/us/2024-05/parcels.gpkg"} {
+		if err := reg.LoadSource(ctx, p); err != nil {
+			t.Fatalf("LoadSource(%q): %v", p, err)
+		}
+	}
+	for _, id := range []string{"eu.2024-05.parcels", "us.2024-05.parcels"} {
+		if _, err := reg.GetSource(ctx, id); err != nil {
+			t.Errorf("GetSource(%q): %v", id, err)
+		}
+	}
+	if got := reg.DeriveSourceID("/tmp/eu/2024-05/parcels.gpkg"); got != "eu.2024-05.parcels" {
+		t.Errorf("DeriveSourceID = %q, want eu.2024-05.parcels", got)
+	}
+	// A path outside the cache dir has no key prefix to keep.
+	if got := reg.DeriveSourceID("/elsewhere/x/parcels.gpkg"); got != "elsewhere.x.parcels" {
+		t.Errorf("DeriveSourceID outside cache dir = %q, want elsewhere.x.parcels", got)
+	}
+}
+
+// TestLoadSourceMetadataStrategy verifies the metadata strategy asks the
+// adapter for the declared identifier and falls back to the key-derived id
+// when there is none (or reading it fails).
+func TestLoadSourceMetadataStrategy(t *testing.T) {
+	tests := []struct {
+		name   string
+		ids    map[string]string
+		err    error
+		wantID string
+	}{
+		{"declared identifier", map[string]string{"/tmp/a/roads.gpkg": "roads-eu"}, nil, "roads-eu"},
+		{"declared identifier is sanitized", map[string]string{"/tmp/a/roads.gpkg": "eu/roads"}, nil, "eu.roads"},
+		{"no identifier falls back", nil, nil, "roads"},
+		{"identify error falls back", nil, errors.New("boom"), "roads"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &identifyingRepository{ids: tt.ids, err: tt.err}
+			storage := &mockStorage{objects: []output.StorageObject{{Key: "a/roads.gpkg"}}}
+			reg := NewSourceRegistry([]output.SpatialSource{repo}, storage, testMeter(), output.NoOpTracer{},
+				slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})), "/tmp")
+			ids, err := domain.NewSourceIDDeriver(domain.SourceIDMetadata, "", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			reg.SetSourceIDDeriver(ids)
+			ctx := context.Background()
+
+			if err := reg.LoadAll(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if !reg.IsLoaded(tt.wantID) {
+				t.Fatalf("expected source loaded as %q", tt.wantID)
+			}
+			if got := reg.DeriveSourceID("/tmp/a/roads.gpkg"); got != tt.wantID {
+				t.Errorf("DeriveSourceID = %q, want %q", got, tt.wantID)
+			}
+
+			// A sync must recognize the loaded key and neither reload nor drop it,
+			// even though its id cannot be derived from the key alone.
+			stats, err := reg.Sync(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Added != 0 || stats.Removed != 0 {
+				t.Errorf("sync stats = %+v, want no changes", stats)
+			}
+		})
+	}
+}
+
+// encodingRepository is a mockRepository that also implements
+// output.GeometryEncoder, recording the options it was asked for.
+type encodingRepository struct {
+	mockRepository
+	got *domain.GeometryOptions
+}
+
+func (m *encodingRepository) QueryPointEncoded(_ context.Context, _, _ string, _ domain.Coordinate, opts domain.GeometryOptions) ([]domain.Feature, error) {
+	m.got = &opts
+	return []domain.Feature{{Geometry: domain.Geometry{WKT: "POINT(1 2)", GML: "<gml:Point/>"}}}, nil
+}
+
+// TestQueryEncoded verifies a non-WKT format or a simplify tolerance reaches an
+// encoding adapter, while plain WKT requests and adapters without the
+// capability take the plain query path.
+func TestQueryEncoded(t *testing.T) {
+	ctx := context.Background()
+	coord := domain.NewWGS84Coordinate(1, 2)
+	plain := []domain.Feature{{Geometry: domain.Geometry{WKT: "POINT(1 2)"}}}
+
+	enc := &encodingRepository{mockRepository: mockRepository{features: map[string][]domain.Feature{"roads:l": plain}}}
+	reg := NewSourceRegistry([]output.SpatialSource{enc}, &mockStorage{}, testMeter(), output.NoOpTracer{},
+		slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})), "/tmp")
+	if err := reg.LoadSource(ctx, "/tmp/roads.gpkg"); err != nil {
+		t.Fatal(err)
+	}
+
+	feats, err := reg.QueryEncoded(ctx, "roads", "l", coord, domain.GeometryOptions{Format: domain.GeometryFormatGML})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc.got == nil || enc.got.Format != domain.GeometryFormatGML || len(feats) != 1 || feats[0].Geometry.GML == "" {
+		t.Errorf("GML query: options %+v, features %+v", enc.got, feats)
+	}
+
+	enc.got = nil
+	if _, err := reg.QueryEncoded(ctx, "roads", "l", coord, domain.GeometryOptions{Simplify: 0.01}); err != nil {
+		t.Fatal(err)
+	}
+	if enc.got == nil || enc.got.Simplify != 0.01 {
+		t.Errorf("simplify query: options %+v, want Simplify 0.01", enc.got)
+	}
+
+	enc.got = nil
+	if _, err := reg.QueryEncoded(ctx, "roads", "l", coord, domain.GeometryOptions{Format: domain.GeometryFormatWKT}); err != nil {
+		t.Fatal(err)
+	}
+	if enc.got != nil {
+		t.Errorf("WKT query reached the encoder with %+v", enc.got)
+	}
+
+	fallback := newTestRegistry()
+	if err := fallback.LoadSource(ctx, "/tmp/roads.gpkg"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fallback.QueryEncoded(ctx, "roads", "l", coord, domain.GeometryOptions{Format: domain.GeometryFormatWKB}); err != nil {
+		t.Errorf("fallback query: %v", err)
+	}
+	if _, err := fallback.QueryEncoded(ctx, "missing", "l", coord, domain.GeometryOptions{Format: domain.GeometryFormatWKB}); !errors.Is(err, domain.ErrSourceNotFound) {
+		t.Errorf("missing source error = %v, want ErrSourceNotFound", err)
+	}
+}
+
 func TestSourceRegistryLoadUnload(t *testing.T) {
 	repo := &mockRepository{
 		packages: map[string]*domain.Source{
@@ -196,12 +419,29 @@ func TestSourceRegistryReadySourceIDs(t *testing.T) {
 		t.Errorf("len(ids) = %d, want 2", len(ids))
 	}
 
-	// Check that only ready packages are returned
-	for _, id := range ids {
-		if id != "ready1" && id != "ready2" {
-			t.Errorf("unexpected package ID: %s", id)
+	// Only ready packages, in id order so multi-source results are stable.
+	if len(ids) == 2 && (ids[0] != "ready1" || ids[1] != "ready2") {
+		t.Errorf("ids = %v, want [ready1 ready2]", ids)
+	}
+}
+
+func TestSourceRegistryReadySourceIDsPriority(t *testing.T) {
+	registry := newTestRegistry()
+
+	registry.mu.Lock()
+	for id, prio := range map[string]int{"a": 0, "b": 10, "c": 0, "d": -1, "e": 10} {
+		registry.sources[id] = &sourceEntry{
+			Source: &domain.Source{ID: id, Priority: prio},
+			Status: domain.StatusReady,
 		}
 	}
+	registry.mu.Unlock()
+
+	// Higher priority first, ties in id order.
+	ids := registry.ReadySourceIDs()
+	if got := strings.Join(ids, ","); got != "b,e,a,c,d" {
+		t.Errorf("ids = %v, want [b e a c d]", ids)
+	}
 }
 
 func TestSourceRegistryUnloadNonexistent(t *testing.T) {
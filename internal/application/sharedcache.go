@@ -0,0 +1,129 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// readyManifestName is the file in the local cache dir in which the sync
+// leader publishes the object keys it has downloaded and indexed.
+const readyManifestName = ".ortus-ready.json"
+
+// readyManifest is the content of readyManifestName.
+type readyManifest struct {
+	Keys        []string  `json:"keys"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// SetLeadership makes the registry share its cache dir with other replicas
+// (sync.leader_election): only while e leads does it download, index and
+// delete files there, publishing the keys it has made ready in the cache
+// dir. Otherwise it loads just the published files and leaves the dir
+// alone, so replicas neither download twice nor write the same index. A
+// replica that becomes leader rebuilds the disk cache index it skipped as a
+// follower. Call once at startup, before the first LoadAll and e.Start.
+func (r *SourceRegistry) SetLeadership(e *LeaderElector) {
+	r.leader = e
+	e.OnChange(func(leader bool) {
+		if leader {
+			r.cache.Reconcile(context.Background())
+		}
+	})
+}
+
+// leading reports whether this instance may write to the cache dir: always
+// without leader election.
+func (r *SourceRegistry) leading() bool {
+	return r.leader == nil || r.leader.IsLeader()
+}
+
+// publishedKeys returns the keys the leader has published as ready, or nil
+// when this instance leads and may fetch any key itself. A follower that
+// finds no manifest yet gets an empty set.
+func (r *SourceRegistry) publishedKeys() map[string]bool {
+	if r.leading() {
+		return nil
+	}
+	keys := map[string]bool{}
+	data, err := os.ReadFile(filepath.Join(r.localPath, readyManifestName))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.logger.Warn("failed to read the sync leader's manifest", "error", err)
+		}
+		return keys
+	}
+	var m readyManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		r.logger.Warn("failed to parse the sync leader's manifest", "error", err)
+		return keys
+	}
+	for _, k := range m.Keys {
+		keys[k] = true
+	}
+	return keys
+}
+
+// publishedObjects narrows a storage listing to the objects the leader has
+// published; a leader gets it unchanged.
+func (r *SourceRegistry) publishedObjects(objects []output.StorageObject) []output.StorageObject {
+	published := r.publishedKeys()
+	if published == nil {
+		return objects
+	}
+	ready := make([]output.StorageObject, 0, len(objects))
+	for _, obj := range objects {
+		if published[obj.Key] {
+			ready = append(ready, obj)
+		}
+	}
+	if pending := len(objects) - len(ready); pending > 0 {
+		r.logger.Info("waiting for the sync leader to fetch sources", "pending", pending)
+	}
+	return ready
+}
+
+// publishReady writes the keys of the loaded sources to the manifest while
+// this instance leads an election; a no-op without one.
+func (r *SourceRegistry) publishReady() {
+	if r.leader == nil || !r.leader.IsLeader() {
+		return
+	}
+	r.mu.RLock()
+	keys := make([]string, 0, len(r.sources))
+	for _, entry := range r.sources {
+		if entry.Source != nil {
+			keys = append(keys, r.objectKey(entry.Source.Path))
+		}
+	}
+	r.mu.RUnlock()
+	sort.Strings(keys)
+
+	if err := writeReadyManifest(filepath.Join(r.localPath, readyManifestName), keys); err != nil {
+		r.logger.Warn("failed to publish ready sources", "error", err)
+	}
+}
+
+// writeReadyManifest replaces the manifest at path atomically, so a follower
+// never reads half of it.
+func writeReadyManifest(path string, keys []string) error {
+	data, err := json.Marshal(readyManifest{Keys: keys, PublishedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	return nil
+}
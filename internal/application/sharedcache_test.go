@@ -0,0 +1,90 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// newSharedRegistry returns a registry over storage listing keys, sharing
+// dir under an elector whose role the lease holder decides.
+func newSharedRegistry(dir string, storage *mockStorage, lease *fakeLease, identity string) *SourceRegistry {
+	logger := slog.New(slog.DiscardHandler)
+	r := NewSourceRegistry([]output.SpatialSource{&mockRepository{}}, storage, noop.NewMeterProvider().Meter("test"), output.NoOpTracer{}, logger, dir)
+	e := NewLeaderElector(lease, identity, time.Minute, logger)
+	r.SetLeadership(e)
+	e.round(context.Background())
+	return r
+}
+
+// TestSharedCache_FollowerLoadsWhatTheLeaderPublished: the leader downloads
+// and publishes; a follower downloads nothing and loads only the published
+// keys.
+func TestSharedCache_FollowerLoadsWhatTheLeaderPublished(t *testing.T) {
+	dir := t.TempDir()
+	lease := &fakeLease{holder: "leader"}
+	ctx := context.Background()
+
+	leaderStorage := &mockStorage{objects: []output.StorageObject{{Key: "a.gpkg"}}}
+	leader := newSharedRegistry(dir, leaderStorage, lease, "leader")
+	if err := leader.LoadAll(ctx); err != nil {
+		t.Fatalf("leader LoadAll: %v", err)
+	}
+
+	followerStorage := &mockStorage{
+		objects:     []output.StorageObject{{Key: "a.gpkg"}, {Key: "b.gpkg"}},
+		downloadErr: errors.New("a follower must not download"),
+	}
+	follower := newSharedRegistry(dir, followerStorage, lease, "follower")
+	if err := follower.LoadAll(ctx); err != nil {
+		t.Fatalf("follower LoadAll: %v", err)
+	}
+	if !follower.IsLoaded("a") || follower.IsLoaded("b") {
+		t.Errorf("follower loaded a=%v b=%v, want only the published a", follower.IsLoaded("a"), follower.IsLoaded("b"))
+	}
+
+	// Once the leader has fetched b, the follower's next sync picks it up.
+	leaderStorage.objects = followerStorage.objects
+	if _, err := leader.Sync(ctx); err != nil {
+		t.Fatalf("leader Sync: %v", err)
+	}
+	if stats, err := follower.Sync(ctx); err != nil || stats.Added != 1 || !follower.IsLoaded("b") {
+		t.Errorf("follower Sync = %+v, %v; want b added", stats, err)
+	}
+}
+
+// TestSharedCache_FollowerKeepsFiles: a source gone from storage is unloaded
+// by a follower, but its file is the leader's to delete.
+func TestSharedCache_FollowerKeepsFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.gpkg")
+	if err := os.WriteFile(path, []byte("gpkg"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	lease := &fakeLease{holder: "leader"}
+	ctx := context.Background()
+	if err := writeReadyManifest(filepath.Join(dir, readyManifestName), []string{"a.gpkg"}); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := &mockStorage{objects: []output.StorageObject{{Key: "a.gpkg"}}}
+	follower := newSharedRegistry(dir, storage, lease, "follower")
+	if err := follower.LoadAll(ctx); err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	storage.objects = nil
+	if stats, err := follower.Sync(ctx); err != nil || stats.Removed != 1 {
+		t.Fatalf("Sync = %+v, %v; want a removed", stats, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("follower deleted the shared file: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// loadFailure is the last failed attempt to fetch or open a source.
+type loadFailure struct {
+	err error
+	at  time.Time
+}
+
+// recordFailure remembers why the source id could not be loaded. A later
+// successful load clears it.
+func (r *SourceRegistry) recordFailure(id string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures[id] = loadFailure{err: err, at: time.Now()}
+}
+
+// markSynced stamps every loaded source listed in objects with the sync
+// time, and forgets the failures of sources storage no longer lists.
+func (r *SourceRegistry) markSynced(objects []output.StorageObject, at time.Time) {
+	listed := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		listed[r.remoteSourceID(obj.Key)] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id := range listed {
+		if entry, ok := r.sources[id]; ok {
+			entry.SyncedAt = at
+		}
+	}
+	for id := range r.failures {
+		if !listed[id] {
+			delete(r.failures, id)
+		}
+	}
+}
+
+// SourceHealth reports the status, last error, per-layer index state and last
+// sync time of a source. A source that failed to load is not in the registry
+// proper; it reports domain.StatusError with the failure. It implements
+// input.SourceHealthReporter.
+func (r *SourceRegistry) SourceHealth(ctx context.Context, id string) (input.SourceHealth, error) {
+	_, span := r.tracer.Start(ctx, "SourceRegistry.SourceHealth",
+		output.WithAttributes(output.String("ortus.source.id", id)),
+	)
+	defer span.End()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if entry, ok := r.sources[id]; ok {
+		h := input.SourceHealth{
+			ID:       id,
+			Status:   entry.Status,
+			LoadedAt: entry.Source.LoadedAt,
+			SyncedAt: entry.SyncedAt,
+			Layers:   make([]input.LayerHealth, len(entry.Source.Layers)),
+		}
+		if entry.Error != nil {
+			h.LastError = entry.Error.Error()
+			h.LastErrorAt = entry.ErrorAt
+		}
+		for i, l := range entry.Source.Layers {
+			h.Layers[i] = input.LayerHealth{
+				Name: l.Name, Indexed: l.HasIndex, FeatureCount: l.FeatureCount,
+				SRIDWarning: l.SRIDWarning, InvalidGeometries: l.InvalidGeometries,
+			}
+		}
+		return h, nil
+	}
+	if f, ok := r.failures[id]; ok {
+		return input.SourceHealth{
+			ID:          id,
+			Status:      domain.StatusError,
+			LastError:   f.err.Error(),
+			LastErrorAt: f.at,
+		}, nil
+	}
+
+	span.RecordError(domain.ErrSourceNotFound)
+	span.SetStatus(output.StatusError, "source not found")
+	return input.SourceHealth{}, domain.ErrSourceNotFound
+}
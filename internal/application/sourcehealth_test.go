@@ -0,0 +1,45 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+func TestSourceHealth(t *testing.T) {
+	repo := &mockRepository{openErr: errors.New("not a GeoPackage")}
+	storage := &mockStorage{objects: []output.StorageObject{{Key: "broken.gpkg"}}}
+	reg := newRegistryWithStorage(storage, repo)
+	ctx := context.Background()
+
+	if err := reg.LoadAll(ctx); err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	// The failed source is not listed, but its health explains why.
+	h, err := reg.SourceHealth(ctx, "broken")
+	if err != nil || h.Status != domain.StatusError || h.LastError != "not a GeoPackage" || h.LastErrorAt.IsZero() {
+		t.Fatalf("failed source health = %+v, err = %v", h, err)
+	}
+
+	// Once it loads, the failure is gone and the sync time is stamped.
+	repo.openErr = nil
+	if _, err := reg.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	h, err = reg.SourceHealth(ctx, "broken")
+	if err != nil || h.Status != domain.StatusReady || h.LastError != "" || h.SyncedAt.IsZero() || h.LoadedAt.IsZero() {
+		t.Errorf("loaded source health = %+v, err = %v", h, err)
+	}
+
+	// A failure for a source storage no longer lists is forgotten on sync.
+	reg.recordFailure("gone", errors.New("download failed"))
+	if _, err := reg.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if _, err := reg.SourceHealth(ctx, "gone"); !errors.Is(err, domain.ErrSourceNotFound) {
+		t.Errorf("stale failure err = %v, want ErrSourceNotFound", err)
+	}
+}
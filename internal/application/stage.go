@@ -0,0 +1,309 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// candidatesDir is the directory under the local path candidates are
+// downloaded to, one subdirectory per source. Candidates do not survive a
+// restart: LoadAll clears it.
+const candidatesDir = ".candidates"
+
+// stagedSource is the candidate version of a loaded source, open next to the
+// active one under candidateAdapterID.
+type stagedSource struct {
+	entry *sourceEntry
+	info  input.StagedSource
+}
+
+var _ input.SourceStager = (*SourceRegistry)(nil)
+
+// candidateAdapterID is the id the adapter knows the candidate of source id
+// by, keeping it apart from the active source.
+func candidateAdapterID(id string) string {
+	return id + "@candidate"
+}
+
+// candidateDir returns the directory the candidate of source id is
+// downloaded to.
+func (r *SourceRegistry) candidateDir(id string) (string, error) {
+	name := url.PathEscape(id)
+	if name == "." || name == ".." {
+		return "", fmt.Errorf("%w: source id %q", domain.ErrInvalidInput, id)
+	}
+	return filepath.Join(r.localPath, candidatesDir, name), nil
+}
+
+// StageCandidate implements input.SourceStager. Candidates need a download
+// cache of their own: with local storage, which serves files in place, or
+// leader election, whose followers would not see the candidate, staging is
+// domain.ErrUnsupported; while maintenance mode is on it is
+// domain.ErrMaintenance.
+func (r *SourceRegistry) StageCandidate(ctx context.Context, id string, req input.StageRequest) (staged input.StagedSource, err error) {
+	ctx, span := r.tracer.Start(ctx, "SourceRegistry.StageCandidate",
+		output.WithAttributes(output.String("ortus.source.id", id), output.String("storage.key", req.Key)),
+	)
+	defer span.End()
+	key := req.Key
+	defer func() { r.audit.Record(ctx, domain.AuditStage, id, stageDetail(key, req.VersionID), err) }()
+
+	switch {
+	case r.local != nil:
+		return input.StagedSource{}, fmt.Errorf("staging with local storage: %w", domain.ErrUnsupported)
+	case r.leader != nil:
+		return input.StagedSource{}, fmt.Errorf("staging with leader election: %w", domain.ErrUnsupported)
+	case r.maintenance.Active():
+		return input.StagedSource{}, domain.ErrMaintenance
+	}
+	r.stageMu.Lock()
+	defer r.stageMu.Unlock()
+
+	activePath, loaded := r.loadedSourcePath(id)
+	if !loaded {
+		return input.StagedSource{}, fmt.Errorf("%w: %s", domain.ErrSourceNotFound, id)
+	}
+	if key == "" {
+		if key, loaded = r.storageKey(activePath); !loaded {
+			return input.StagedSource{}, fmt.Errorf("%w: %s was not downloaded from storage", domain.ErrSourceNotFound, id)
+		}
+	}
+	provider, err := r.providerFor(key)
+	if err != nil {
+		return input.StagedSource{}, fmt.Errorf("%w: %s is no source file", domain.ErrInvalidInput, key)
+	}
+	dir, err := r.candidateDir(id)
+	if err != nil {
+		return input.StagedSource{}, err
+	}
+
+	// Replace an earlier candidate.
+	r.mu.Lock()
+	old, ok := r.candidates[id]
+	delete(r.candidates, id)
+	r.mu.Unlock()
+	if ok {
+		r.closeCandidate(ctx, id, old)
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return input.StagedSource{}, err
+	}
+	localPath := filepath.Join(dir, path.Base(key))
+	if err := r.fetchCandidate(ctx, key, req.VersionID, localPath); err != nil {
+		_ = os.RemoveAll(dir)
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "download failed")
+		return input.StagedSource{}, fmt.Errorf("downloading %s: %w", key, err)
+	}
+	r.fetchSidecars(ctx, key, localPath)
+
+	src, err := r.openCandidate(ctx, provider, id, localPath)
+	if err != nil {
+		_ = provider.Close(ctx, candidateAdapterID(id))
+		_ = os.RemoveAll(dir)
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "open failed")
+		return input.StagedSource{}, err
+	}
+
+	staged = input.StagedSource{
+		SourceID:  id,
+		Key:       key,
+		VersionID: req.VersionID,
+		Layers:    len(src.Layers),
+		StagedAt:  time.Now(),
+	}
+	r.mu.Lock()
+	if r.candidates == nil {
+		r.candidates = make(map[string]*stagedSource)
+	}
+	r.candidates[id] = &stagedSource{
+		entry: &sourceEntry{Source: src, Repo: provider, Status: domain.StatusReady},
+		info:  staged,
+	}
+	r.mu.Unlock()
+	r.logger.Info("candidate staged", "id", id, "key", key, "layers", len(src.Layers))
+	span.SetStatus(output.StatusOK, "")
+	return staged, nil
+}
+
+// fetchCandidate downloads a candidate, in versionID when set. The file
+// stays out of the disk cache, which would evict it as an unloaded source.
+func (r *SourceRegistry) fetchCandidate(ctx context.Context, key, versionID, localPath string) error {
+	if versionID == "" {
+		return r.storage.Download(ctx, key, localPath)
+	}
+	versioned, ok := r.storage.(output.VersionedStorage)
+	if !ok {
+		return fmt.Errorf("version %s: storage keeps no versions: %w", versionID, domain.ErrUnsupported)
+	}
+	return versioned.DownloadVersion(ctx, key, versionID, localPath)
+}
+
+// openCandidate opens and prepares the candidate of source id as LoadSource
+// does a source, with the source's overrides applied. Under the metadata id
+// strategy a package declaring another id is refused: it would not load
+// under id once promoted.
+func (r *SourceRegistry) openCandidate(ctx context.Context, provider output.SpatialSource, id, localPath string) (*domain.Source, error) {
+	if r.ids.Strategy() == domain.SourceIDMetadata {
+		if ident, ok := provider.(output.SourceIdentifier); ok {
+			if declared, err := ident.Identify(ctx, localPath); err == nil && declared != "" && domain.SanitizeSourceID(declared) != id {
+				return nil, fmt.Errorf("%w: candidate declares source id %q, not %q", domain.ErrInvalidInput, declared, id)
+			}
+		}
+	}
+	adapterID := candidateAdapterID(id)
+	src, err := provider.Open(ctx, adapterID, localPath)
+	if err != nil {
+		return nil, err
+	}
+	src.ID = id
+	if o, ok := r.overrideFor(id); ok {
+		o.Apply(src)
+	}
+	for _, layer := range src.Layers {
+		if err := provider.Prepare(ctx, adapterID, layer.Name); err != nil {
+			r.logger.Warn("failed to prepare candidate layer", "source", id, "layer", layer.Name, "error", err)
+		}
+	}
+	src.LoadedAt = time.Now()
+	src.Indexed = allLayersIndexed(src.Layers)
+	return src, nil
+}
+
+// PromoteCandidate implements input.SourceStager. The candidate's file
+// replaces the active source's local copy and is loaded in its place;
+// queries find the source missing only while it reopens. Storage is left
+// alone: a later download of the source's key — a change event, a restart —
+// brings back what storage holds there. While maintenance mode is on it
+// returns domain.ErrMaintenance and the candidate stays staged.
+func (r *SourceRegistry) PromoteCandidate(ctx context.Context, id string) (staged input.StagedSource, err error) {
+	ctx, span := r.tracer.Start(ctx, "SourceRegistry.PromoteCandidate",
+		output.WithAttributes(output.String("ortus.source.id", id)),
+	)
+	defer span.End()
+	defer func() { r.audit.Record(ctx, domain.AuditPromote, id, stageDetail(staged.Key, staged.VersionID), err) }()
+
+	if r.maintenance.Active() {
+		return input.StagedSource{}, domain.ErrMaintenance
+	}
+	r.stageMu.Lock()
+	defer r.stageMu.Unlock()
+
+	r.mu.Lock()
+	c, ok := r.candidates[id]
+	delete(r.candidates, id)
+	r.mu.Unlock()
+	if !ok {
+		return input.StagedSource{}, domain.ErrNoCandidate
+	}
+	staged = c.info
+	activePath, loaded := r.loadedSourcePath(id)
+	if !loaded {
+		r.closeCandidate(ctx, id, c)
+		return staged, fmt.Errorf("%w: %s", domain.ErrSourceNotFound, id)
+	}
+
+	// Close the candidate first: its file moves under the active source's
+	// name, which LoadSource then reopens.
+	if err := c.entry.Repo.Close(ctx, candidateAdapterID(id)); err != nil {
+		r.logger.Warn("failed to close candidate before promoting", "id", id, "error", err)
+	}
+	candidatePath := c.entry.Source.Path
+	if err := os.Rename(candidatePath, activePath); err != nil {
+		r.closeCandidate(ctx, id, c)
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "promote failed")
+		return staged, fmt.Errorf("replacing %s: %w", activePath, err)
+	}
+	activeSidecars := domain.SidecarNames(activePath)
+	for i, sidecar := range domain.SidecarNames(candidatePath) {
+		if err := os.Rename(sidecar, activeSidecars[i]); errors.Is(err, os.ErrNotExist) {
+			_ = os.Remove(activeSidecars[i])
+		}
+	}
+	r.closeCandidate(ctx, id, c)
+	r.cache.Add(activePath)
+
+	if err := r.LoadSource(ctx, activePath); err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "reload failed")
+		return staged, err
+	}
+	r.logger.Info("candidate promoted", "id", id, "key", staged.Key)
+	span.SetStatus(output.StatusOK, "")
+	return staged, nil
+}
+
+// DiscardCandidate implements input.SourceStager.
+func (r *SourceRegistry) DiscardCandidate(ctx context.Context, id string) (err error) {
+	defer func() { r.audit.Record(ctx, domain.AuditDiscard, id, "", err) }()
+
+	r.stageMu.Lock()
+	defer r.stageMu.Unlock()
+
+	r.mu.Lock()
+	c, ok := r.candidates[id]
+	delete(r.candidates, id)
+	r.mu.Unlock()
+	if !ok {
+		return domain.ErrNoCandidate
+	}
+	r.closeCandidate(ctx, id, c)
+	return nil
+}
+
+// Candidate implements input.SourceStager.
+func (r *SourceRegistry) Candidate(id string) (input.StagedSource, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.candidates[id]
+	if !ok {
+		return input.StagedSource{}, domain.ErrNoCandidate
+	}
+	return c.info, nil
+}
+
+// closeCandidate closes a candidate taken out of r.candidates and deletes
+// its files. Failures are logged; the candidate is gone either way.
+func (r *SourceRegistry) closeCandidate(ctx context.Context, id string, c *stagedSource) {
+	if err := c.entry.Repo.Close(ctx, candidateAdapterID(id)); err != nil {
+		r.logger.Warn("failed to close candidate", "id", id, "error", err)
+	}
+	if dir, err := r.candidateDir(id); err == nil {
+		if err := os.RemoveAll(dir); err != nil {
+			r.logger.Warn("failed to delete candidate", "id", id, "error", err)
+		}
+	}
+}
+
+// clearCandidates deletes the candidates a previous run left behind.
+func (r *SourceRegistry) clearCandidates() {
+	if r.localPath == "" || r.local != nil || !r.leading() {
+		return
+	}
+	if err := os.RemoveAll(filepath.Join(r.localPath, candidatesDir)); err != nil {
+		r.logger.Warn("failed to delete stale candidates", "error", err)
+	}
+}
+
+// stageDetail is the audit detail of a staged object.
+func stageDetail(key, versionID string) string {
+	if versionID != "" {
+		return "key=" + key + " version=" + versionID
+	}
+	if key != "" {
+		return "key=" + key
+	}
+	return ""
+}
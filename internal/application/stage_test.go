@@ -0,0 +1,172 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// fileStorage is a storage whose downloads write the object's content.
+type fileStorage struct {
+	mockStorage
+	content map[string]string
+}
+
+func (f *fileStorage) Download(_ context.Context, key, dest string) error {
+	content, ok := f.content[key]
+	if !ok {
+		return errors.New("no such object")
+	}
+	return os.WriteFile(dest, []byte(content), 0o600)
+}
+
+func (f *fileStorage) GetReader(_ context.Context, _ string) (io.ReadCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fileStorage) Exists(_ context.Context, key string) (bool, error) {
+	_, ok := f.content[key]
+	return ok, nil
+}
+
+func TestStageAndPromoteCandidate(t *testing.T) {
+	dir := t.TempDir()
+	storage := &fileStorage{content: map[string]string{"parcels.gpkg": "v1", "parcels-v2.gpkg": "v2"}}
+	repo := &mockRepository{features: map[string][]domain.Feature{
+		"parcels:l":           {{ID: 1}},
+		"parcels@candidate:l": {{ID: 2}},
+	}}
+	reg := NewSourceRegistry([]output.SpatialSource{repo}, storage, nil, output.NoOpTracer{}, testLogger(), dir)
+	ctx := context.Background()
+	active := filepath.Join(dir, "parcels.gpkg")
+	if err := os.WriteFile(active, []byte("v1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.LoadSource(ctx, active); err != nil {
+		t.Fatalf("LoadSource: %v", err)
+	}
+
+	if _, err := reg.StageCandidate(ctx, "roads", input.StageRequest{}); !errors.Is(err, domain.ErrSourceNotFound) {
+		t.Errorf("stage unknown source: err = %v, want ErrSourceNotFound", err)
+	}
+	staged, err := reg.StageCandidate(ctx, "parcels", input.StageRequest{Key: "parcels-v2.gpkg"})
+	if err != nil {
+		t.Fatalf("StageCandidate: %v", err)
+	}
+	if staged.SourceID != "parcels" || staged.Key != "parcels-v2.gpkg" {
+		t.Errorf("staged = %+v", staged)
+	}
+
+	// Queries read the candidate only when they ask for it.
+	candidateCtx := domain.WithStage(ctx, domain.StageCandidate)
+	for _, tt := range []struct {
+		ctx  context.Context
+		want int64
+	}{{ctx, 1}, {candidateCtx, 2}} {
+		features, err := reg.Query(tt.ctx, "parcels", "l", domain.Coordinate{})
+		if err != nil || len(features) != 1 || features[0].ID != tt.want {
+			t.Errorf("%s query = %+v, %v; want feature %d", domain.StageFrom(tt.ctx), features, err, tt.want)
+		}
+	}
+	if src, err := reg.GetSource(candidateCtx, "parcels"); err != nil || src.ID != "parcels" || src.Path == active {
+		t.Errorf("candidate source = %+v, %v", src, err)
+	}
+
+	// Promotion moves the candidate's file under the active source's name.
+	if _, err := reg.PromoteCandidate(ctx, "parcels"); err != nil {
+		t.Fatalf("PromoteCandidate: %v", err)
+	}
+	if b, _ := os.ReadFile(active); string(b) != "v2" {
+		t.Errorf("active file = %q after promotion, want v2", b)
+	}
+	if _, err := os.Stat(filepath.Join(dir, candidatesDir, "parcels")); !os.IsNotExist(err) {
+		t.Errorf("candidate dir left behind: %v", err)
+	}
+	if !reg.IsReady("parcels") {
+		t.Error("source not ready after promotion")
+	}
+	if _, err := reg.Candidate("parcels"); !errors.Is(err, domain.ErrNoCandidate) {
+		t.Errorf("Candidate after promotion: err = %v, want ErrNoCandidate", err)
+	}
+	if _, err := reg.PromoteCandidate(ctx, "parcels"); !errors.Is(err, domain.ErrNoCandidate) {
+		t.Errorf("second promotion: err = %v, want ErrNoCandidate", err)
+	}
+}
+
+func TestStageAndPromoteBlockedInMaintenance(t *testing.T) {
+	dir := t.TempDir()
+	storage := &fileStorage{content: map[string]string{"parcels.gpkg": "v2"}}
+	reg := NewSourceRegistry([]output.SpatialSource{&mockRepository{}}, storage, nil, output.NoOpTracer{}, testLogger(), dir)
+	maintenance := NewMaintenanceMode(testLogger())
+	reg.SetMaintenance(maintenance)
+	ctx := context.Background()
+	active := filepath.Join(dir, "parcels.gpkg")
+	if err := os.WriteFile(active, []byte("v1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.LoadSource(ctx, active); err != nil {
+		t.Fatalf("LoadSource: %v", err)
+	}
+	if _, err := reg.StageCandidate(ctx, "parcels", input.StageRequest{}); err != nil {
+		t.Fatalf("StageCandidate: %v", err)
+	}
+
+	maintenance.EnterMaintenance(ctx, "replacing parcels.gpkg")
+	if _, err := reg.StageCandidate(ctx, "parcels", input.StageRequest{}); !errors.Is(err, domain.ErrMaintenance) {
+		t.Errorf("stage in maintenance: err = %v, want ErrMaintenance", err)
+	}
+	if _, err := reg.PromoteCandidate(ctx, "parcels"); !errors.Is(err, domain.ErrMaintenance) {
+		t.Errorf("promote in maintenance: err = %v, want ErrMaintenance", err)
+	}
+	if b, _ := os.ReadFile(active); string(b) != "v1" {
+		t.Errorf("active file = %q during maintenance, want v1", b)
+	}
+
+	// The candidate stays staged and is promoted once maintenance is over.
+	maintenance.LeaveMaintenance(ctx)
+	if _, err := reg.PromoteCandidate(ctx, "parcels"); err != nil {
+		t.Fatalf("PromoteCandidate after maintenance: %v", err)
+	}
+	if b, _ := os.ReadFile(active); string(b) != "v2" {
+		t.Errorf("active file = %q after promotion, want v2", b)
+	}
+}
+
+func TestDiscardCandidate(t *testing.T) {
+	dir := t.TempDir()
+	storage := &fileStorage{content: map[string]string{"parcels.gpkg": "v2"}}
+	reg := NewSourceRegistry([]output.SpatialSource{&mockRepository{}}, storage, nil, output.NoOpTracer{}, testLogger(), dir)
+	ctx := context.Background()
+	active := filepath.Join(dir, "parcels.gpkg")
+	if err := os.WriteFile(active, []byte("v1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.LoadSource(ctx, active); err != nil {
+		t.Fatalf("LoadSource: %v", err)
+	}
+
+	// Without a key the source's own key is staged again.
+	staged, err := reg.StageCandidate(ctx, "parcels", input.StageRequest{})
+	if err != nil || staged.Key != "parcels.gpkg" {
+		t.Fatalf("StageCandidate = %+v, %v", staged, err)
+	}
+	if err := reg.DiscardCandidate(ctx, "parcels"); err != nil {
+		t.Fatalf("DiscardCandidate: %v", err)
+	}
+	if b, _ := os.ReadFile(active); string(b) != "v1" {
+		t.Errorf("active file = %q after discarding, want v1", b)
+	}
+	if err := reg.DiscardCandidate(ctx, "parcels"); !errors.Is(err, domain.ErrNoCandidate) {
+		t.Errorf("second discard: err = %v, want ErrNoCandidate", err)
+	}
+	if _, err := reg.StageCandidate(ctx, "parcels", input.StageRequest{VersionID: "v1"}); !errors.Is(err, domain.ErrUnsupported) {
+		t.Errorf("version without versioned storage: err = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,161 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// Storage component statuses reported by StorageProbe.
+const (
+	StorageStatusUnknown  = "unknown"  // no check has finished yet
+	StorageStatusOK       = "ok"       // the last check succeeded
+	StorageStatusDegraded = "degraded" // the last check failed
+	StorageStatusDown     = "down"     // storageDownAfter checks in a row failed
+)
+
+// storageDownAfter is the number of consecutive failed checks after which
+// storage is reported down rather than degraded: one failure may be a blip,
+// several are an outage.
+const storageDownAfter = 3
+
+// StorageStatus is the outcome of the storage probe's checks so far.
+type StorageStatus struct {
+	Status      string
+	LastError   string    // error of the last failed check; "" after a success
+	LastSuccess time.Time // zero until a check succeeds
+	LastCheck   time.Time // zero until a check finishes
+}
+
+// StorageProbe checks the storage backend on an interval
+// (storage.health_check), so /health reports a broken connection before a
+// sync or download runs into it. A check lists the storage, or, with a sentinel key, asks whether that
+// object exists: cheaper on large buckets, and a missing sentinel counts as a
+// failure, since S3 and Azure answer "absent" when they cannot be reached.
+type StorageProbe struct {
+	storage  output.ObjectStorage
+	interval time.Duration
+	timeout  time.Duration
+	key      string
+	logger   *slog.Logger
+	tracer   output.Tracer
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+
+	mu       sync.RWMutex
+	status   StorageStatus
+	failures int // consecutive failed checks
+}
+
+// NewStorageProbe creates a probe that checks storage every interval, each
+// check bounded by timeout. key is the sentinel object; "" lists instead.
+func NewStorageProbe(storage output.ObjectStorage, interval, timeout time.Duration, key string, tracer output.Tracer, logger *slog.Logger) *StorageProbe {
+	if tracer == nil {
+		tracer = output.NoOpTracer{}
+	}
+	return &StorageProbe{
+		storage:  storage,
+		interval: interval,
+		timeout:  timeout,
+		key:      key,
+		logger:   logger,
+		tracer:   tracer,
+		stopCh:   make(chan struct{}),
+		status:   StorageStatus{Status: StorageStatusUnknown},
+	}
+}
+
+// Start runs a first check right away, then one every interval.
+func (p *StorageProbe) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			p.Check(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the checks and waits for a running one to finish.
+func (p *StorageProbe) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+// Check checks the storage once and records the outcome.
+func (p *StorageProbe) Check(ctx context.Context) {
+	ctx, span := p.tracer.Start(ctx, "StorageProbe.Check")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	err := p.check(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		return // shutting down; says nothing about storage
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prev := p.status.Status
+	p.status.LastCheck = time.Now()
+	if err == nil {
+		p.failures = 0
+		p.status.Status, p.status.LastError = StorageStatusOK, ""
+		p.status.LastSuccess = p.status.LastCheck
+		span.SetStatus(output.StatusOK, "")
+		if prev == StorageStatusDegraded || prev == StorageStatusDown {
+			p.logger.Info("storage reachable again")
+		}
+		return
+	}
+	p.failures++
+	p.status.Status, p.status.LastError = StorageStatusDegraded, err.Error()
+	if p.failures >= storageDownAfter {
+		p.status.Status = StorageStatusDown
+	}
+	span.RecordError(err)
+	span.SetStatus(output.StatusError, "storage check failed")
+	if p.status.Status != prev {
+		p.logger.Warn("storage check failed", "status", p.status.Status, "failures", p.failures, "error", err)
+	}
+}
+
+// errSentinelMissing fails a check whose sentinel object is not found.
+var errSentinelMissing = errors.New("sentinel object not found")
+
+func (p *StorageProbe) check(ctx context.Context) error {
+	if p.key == "" {
+		_, err := p.storage.List(ctx)
+		return err
+	}
+	ok, err := p.storage.Exists(ctx, p.key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s: %w", p.key, errSentinelMissing)
+	}
+	return nil
+}
+
+// Status returns the outcome of the checks so far.
+func (p *StorageProbe) Status() StorageStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.status
+}
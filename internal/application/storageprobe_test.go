@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// sentinelStorage answers Exists with exists, or with err when set.
+type sentinelStorage struct {
+	mockStorage
+	exists bool
+	err    error
+}
+
+func (s *sentinelStorage) Exists(_ context.Context, _ string) (bool, error) {
+	return s.exists, s.err
+}
+
+func newTestProbe(storage output.ObjectStorage, key string) *StorageProbe {
+	return NewStorageProbe(storage, time.Minute, time.Second, key, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// TestStorageProbeStatus walks the probe from unknown through degraded and
+// down back to ok, keeping the last error and the last success.
+func TestStorageProbeStatus(t *testing.T) {
+	storage := &mockStorage{}
+	p := newTestProbe(storage, "")
+	ctx := context.Background()
+
+	if got := p.Status().Status; got != StorageStatusUnknown {
+		t.Fatalf("status before a check = %q, want unknown", got)
+	}
+	p.Check(ctx)
+	ok := p.Status()
+	if ok.Status != StorageStatusOK || ok.LastSuccess.IsZero() || ok.LastError != "" {
+		t.Fatalf("status after a successful check = %+v, want ok with a last success", ok)
+	}
+
+	storage.listErr = errors.New("dial tcp: connection refused")
+	p.Check(ctx)
+	st := p.Status()
+	if st.Status != StorageStatusDegraded || st.LastError != "dial tcp: connection refused" {
+		t.Errorf("status after one failure = %+v, want degraded with the error", st)
+	}
+	if !st.LastSuccess.Equal(ok.LastSuccess) {
+		t.Errorf("last success = %v, want it kept from the earlier check (%v)", st.LastSuccess, ok.LastSuccess)
+	}
+	for range storageDownAfter - 1 {
+		p.Check(ctx)
+	}
+	if got := p.Status().Status; got != StorageStatusDown {
+		t.Errorf("status after %d failures = %q, want down", storageDownAfter, got)
+	}
+
+	storage.listErr = nil
+	p.Check(ctx)
+	if st := p.Status(); st.Status != StorageStatusOK || st.LastError != "" {
+		t.Errorf("status after recovery = %+v, want ok without an error", st)
+	}
+}
+
+// TestStorageProbeSentinel: with a key, the probe asks for that object, and a
+// missing one is a failure.
+func TestStorageProbeSentinel(t *testing.T) {
+	storage := &sentinelStorage{mockStorage: mockStorage{listErr: errors.New("not listed")}, exists: true}
+	p := newTestProbe(storage, ".ortus-health")
+	ctx := context.Background()
+
+	p.Check(ctx)
+	if got := p.Status().Status; got != StorageStatusOK {
+		t.Fatalf("status with the sentinel present = %q, want ok (no listing)", got)
+	}
+	storage.exists = false
+	p.Check(ctx)
+	if st := p.Status(); st.Status != StorageStatusDegraded || !errors.Is(p.check(ctx), errSentinelMissing) {
+		t.Errorf("status with the sentinel missing = %+v, want degraded", st)
+	}
+}
+
+// TestStorageProbeIgnoresShutdown: a check cut short by shutdown is not a
+// storage failure.
+func TestStorageProbeIgnoresShutdown(t *testing.T) {
+	storage := &sentinelStorage{err: context.Canceled}
+	p := newTestProbe(storage, "k")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.Check(ctx)
+	if got := p.Status().Status; got != StorageStatusUnknown {
+		t.Errorf("status after a canceled check = %q, want unknown", got)
+	}
+}
+
+func TestStorageProbeStartStop(t *testing.T) {
+	p := newTestProbe(&mockStorage{}, "")
+	p.Start(context.Background())
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Status().Status == StorageStatusUnknown {
+		if time.Now().After(deadline) {
+			t.Fatal("no check ran after Start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	p.Stop()
+}
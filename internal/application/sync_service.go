@@ -3,8 +3,10 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -22,6 +24,8 @@ type SyncResult = input.SyncResult
 // concrete *SourceRegistry.
 type sourceSyncer interface {
 	Sync(ctx context.Context) (SyncStats, error)
+	SyncObject(ctx context.Context, key string, deleted bool) (SyncStats, error)
+	SyncSelected(ctx context.Context, ids, prefixes []string) (SyncStats, error)
 	SourceCount() int
 }
 
@@ -32,20 +36,37 @@ type SyncService struct {
 	logger   *slog.Logger
 	tracer   output.Tracer
 
+	// schedule, when set, replaces interval; a scheduled sync falling into a
+	// blackout window is postponed to the window's end. See SetSchedule.
+	schedule *domain.Schedule
+	blackout []domain.TimeWindow
+
 	// Lifecycle management
 	stopCh chan struct{}
 	wg     sync.WaitGroup
 
-	// Rate limiting for API triggers
+	// Rate limiting for API triggers: at most one per apiCooldown.
 	lastAPISync time.Time
+	apiCooldown time.Duration
 	apiMutex    sync.Mutex
 
 	// Prevents concurrent sync operations
 	syncOpMutex sync.Mutex
 
-	// Track next scheduled sync for reporting
+	// Track next scheduled and last successful sync for reporting
 	nextSync time.Time
+	lastSync time.Time
 	syncMu   sync.RWMutex
+
+	// maintenance, when on, blocks both scheduled and triggered syncs.
+	maintenance *MaintenanceMode
+	// audit records every sync run; nil disables auditing.
+	audit *AuditLog
+
+	// Change notifications are applied only for objects in this container
+	// ("" = any) below this prefix; see SetChangeScope.
+	changeContainer string
+	changePrefix    string
 }
 
 // NewSyncService creates a new sync service.
@@ -59,14 +80,55 @@ func NewSyncService(registry sourceSyncer, interval time.Duration, tracer output
 		logger:   logger,
 		tracer:   tracer,
 		stopCh:   make(chan struct{}),
-		// Initialize to past time to allow immediate first API call
-		lastAPISync: time.Now().Add(-31 * time.Second),
+		// The zero lastAPISync lets the first API call through.
+		apiCooldown: DefaultSyncAPICooldown,
 	}
 }
 
+// DefaultSyncAPICooldown is the time TriggerSync waits between two syncs
+// unless SetAPICooldown changes it.
+const DefaultSyncAPICooldown = 30 * time.Second
+
+// SetAPICooldown sets the time TriggerSync waits between two syncs; 0
+// lifts the limit. Call before Start.
+func (s *SyncService) SetAPICooldown(d time.Duration) {
+	s.apiCooldown = d
+}
+
+// SetSchedule runs scheduled syncs at the times of schedule instead of
+// every interval (nil keeps the interval) and postpones those falling into
+// a blackout window to its end. Triggered and event syncs are not affected.
+// Call before Start.
+func (s *SyncService) SetSchedule(schedule *domain.Schedule, blackout []domain.TimeWindow) {
+	s.schedule, s.blackout = schedule, blackout
+}
+
+// SetMaintenance installs the maintenance switch that blocks sync while it
+// is on. nil removes it. Call before Start.
+func (s *SyncService) SetMaintenance(m *MaintenanceMode) {
+	s.maintenance = m
+}
+
+// SetAudit installs the audit log that records sync runs. nil switches
+// auditing off. Call before Start.
+func (s *SyncService) SetAudit(a *AuditLog) {
+	s.audit = a
+}
+
+// SetChangeScope limits SyncChanges to objects in container below prefix —
+// the storage's container and key prefix, which List strips from keys. Call
+// before Start.
+func (s *SyncService) SetChangeScope(container, prefix string) {
+	s.changeContainer, s.changePrefix = container, prefix
+}
+
 // Start begins the periodic sync scheduler.
 func (s *SyncService) Start(ctx context.Context) {
-	s.logger.Info("starting sync service", "interval", s.interval)
+	if s.schedule != nil {
+		s.logger.Info("starting sync service", "schedule", s.schedule.String(), "blackout", len(s.blackout))
+	} else {
+		s.logger.Info("starting sync service", "interval", s.interval, "blackout", len(s.blackout))
+	}
 
 	s.wg.Add(1)
 	go s.run(ctx)
@@ -77,12 +139,12 @@ func (s *SyncService) Start(ctx context.Context) {
 // on a span and the loop continues.
 func (s *SyncService) run(ctx context.Context) {
 	defer s.wg.Done()
+	ctx = domain.WithActor(ctx, "scheduler")
 
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
-
-	// Set initial next sync time
-	s.setNextSync(time.Now().Add(s.interval))
+	next := s.nextRun(time.Now())
+	s.setNextSync(next)
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
 
 	for {
 		select {
@@ -92,12 +154,37 @@ func (s *SyncService) run(ctx context.Context) {
 		case <-s.stopCh:
 			s.logger.Info("sync service stopped")
 			return
-		case <-ticker.C:
+		case <-timer.C:
 			s.logger.Debug("scheduled sync triggered")
 			s.doSync(ctx)
-			s.setNextSync(time.Now().Add(s.interval))
+			next = s.nextRun(time.Now())
+			s.setNextSync(next)
+			timer.Reset(time.Until(next))
+		}
+	}
+}
+
+// nextRun returns when the scheduled sync after now runs: at the schedule's
+// next time (or an interval from now), moved past any blackout window it
+// falls into. Adjoining windows are skipped one after another, a week's
+// worth at most, so windows covering all the time cannot stall the loop.
+func (s *SyncService) nextRun(now time.Time) time.Time {
+	next := now.Add(s.interval)
+	if s.schedule != nil {
+		next = s.schedule.Next(now)
+	}
+	for range 7 * len(s.blackout) {
+		moved := false
+		for _, w := range s.blackout {
+			if end, ok := w.End(next); ok {
+				next, moved = end, true
+			}
+		}
+		if !moved {
+			break
 		}
 	}
+	return next
 }
 
 // Stop gracefully stops the sync service.
@@ -107,21 +194,73 @@ func (s *SyncService) Stop() {
 	s.wg.Wait()
 }
 
-// TriggerSync manually triggers a sync operation with rate limiting.
-// Returns ErrRateLimited if called more than 2 times per minute.
-func (s *SyncService) TriggerSync(ctx context.Context) (SyncResult, error) {
+// TriggerSync manually triggers a sync operation with rate limiting: a
+// full sync, or a refresh of the sources sel selects. Returns a
+// *domain.RateLimitError (ErrRateLimited) if called again within the API
+// cooldown, and ErrMaintenance while maintenance mode is on.
+func (s *SyncService) TriggerSync(ctx context.Context, sel input.SyncSelection) (SyncResult, error) {
+	// Checked before the rate limit, so a refused call does not use up the
+	// cooldown.
+	if s.maintenance.Active() {
+		return SyncResult{}, domain.ErrMaintenance
+	}
+
 	s.apiMutex.Lock()
 	defer s.apiMutex.Unlock()
 
-	// Rate limit: 30 seconds cooldown (allows ~2 requests per minute)
-	if time.Since(s.lastAPISync) < 30*time.Second {
-		return SyncResult{}, domain.ErrRateLimited
+	if wait := s.apiCooldown - time.Since(s.lastAPISync); wait > 0 {
+		return SyncResult{}, &domain.RateLimitError{RetryAfter: wait}
 	}
 	s.lastAPISync = time.Now()
 
+	if !sel.IsZero() {
+		return s.syncSelected(ctx, sel)
+	}
 	return s.doSyncWithResult(ctx)
 }
 
+// syncSelected refreshes the sources sel selects.
+func (s *SyncService) syncSelected(ctx context.Context, sel input.SyncSelection) (SyncResult, error) {
+	ctx, span := s.tracer.Start(ctx, "SyncService.syncSelected",
+		output.WithAttributes(output.String("sync.trigger", "manual")),
+	)
+	defer span.End()
+
+	s.syncOpMutex.Lock()
+	defer s.syncOpMutex.Unlock()
+
+	stats, err := s.registry.SyncSelected(ctx, sel.SourceIDs, sel.Prefixes)
+	detail := syncDetail(stats)
+	if len(sel.Prefixes) > 0 {
+		detail = "prefixes=" + strings.Join(sel.Prefixes, ",") + " " + detail
+	}
+	if len(sel.SourceIDs) > 0 {
+		detail = "sources=" + strings.Join(sel.SourceIDs, ",") + " " + detail
+	}
+	s.audit.Record(ctx, domain.AuditSync, "", detail, err)
+	span.SetAttributes(
+		output.Int("sync.added", stats.Added),
+		output.Int("sync.updated", stats.Updated),
+		output.Int("sync.removed", stats.Removed),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "selective sync failed")
+		return SyncResult{}, err
+	}
+	span.SetStatus(output.StatusOK, "")
+
+	return SyncResult{
+		SourcesAdded:    stats.Added,
+		SourcesUpdated:  stats.Updated,
+		SourcesRemoved:  stats.Removed,
+		SourcesPinned:   stats.Pinned,
+		SourcesTotal:    s.registry.SourceCount(),
+		SyncedAt:        s.markSynced(),
+		NextScheduledAt: s.getNextSync(),
+	}, nil
+}
+
 // doSync performs the sync operation without returning detailed results.
 // It is called from the scheduled-tick goroutine and includes panic recovery
 // so a single tick's failure can't take down the loop. defer order matters:
@@ -140,17 +279,25 @@ func (s *SyncService) doSync(ctx context.Context) {
 		}
 	}()
 
+	if s.maintenance.Active() {
+		s.logger.Info("scheduled sync skipped: maintenance mode")
+		span.AddEvent("skipped (maintenance mode)")
+		return
+	}
+
 	// Prevent concurrent sync operations
 	s.syncOpMutex.Lock()
 	defer s.syncOpMutex.Unlock()
 
 	stats, err := s.registry.Sync(ctx)
+	s.audit.Record(ctx, domain.AuditSync, "", syncDetail(stats), err)
 	if err != nil {
 		s.logger.Error("sync failed", "error", err)
 		span.RecordError(err)
 		span.SetStatus(output.StatusError, "registry sync failed")
 		return
 	}
+	s.markSynced()
 	s.logger.Info("sync completed",
 		"added", stats.Added,
 		"removed", stats.Removed,
@@ -176,6 +323,7 @@ func (s *SyncService) doSyncWithResult(ctx context.Context) (SyncResult, error)
 	defer s.syncOpMutex.Unlock()
 
 	stats, err := s.registry.Sync(ctx)
+	s.audit.Record(ctx, domain.AuditSync, "", syncDetail(stats), err)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(output.StatusError, "registry sync failed")
@@ -192,12 +340,96 @@ func (s *SyncService) doSyncWithResult(ctx context.Context) (SyncResult, error)
 	return SyncResult{
 		SourcesAdded:    stats.Added,
 		SourcesRemoved:  stats.Removed,
+		SourcesPinned:   stats.Pinned,
 		SourcesTotal:    s.registry.SourceCount(),
-		SyncedAt:        time.Now(),
+		SyncedAt:        s.markSynced(),
 		NextScheduledAt: s.getNextSync(),
 	}, nil
 }
 
+// SyncChanges implements input.ChangeSyncer: it applies storage change
+// notifications one object at a time instead of listing the storage. Objects
+// outside the change scope are skipped. A failed object does not stop the
+// others; the errors are joined, so the notifier can retry the batch.
+// Returns domain.ErrMaintenance while maintenance mode is on.
+func (s *SyncService) SyncChanges(ctx context.Context, changes []input.ObjectChange) (SyncResult, error) {
+	if s.maintenance.Active() {
+		return SyncResult{}, domain.ErrMaintenance
+	}
+	ctx, span := s.tracer.Start(ctx, "SyncService.SyncChanges",
+		output.WithAttributes(output.String("sync.trigger", "event"), output.Int("sync.changes", len(changes))),
+	)
+	defer span.End()
+
+	s.syncOpMutex.Lock()
+	defer s.syncOpMutex.Unlock()
+
+	var total SyncStats
+	var errs []error
+	for _, c := range changes {
+		key, ok := s.changeKey(c)
+		if !ok {
+			continue
+		}
+		stats, err := s.registry.SyncObject(ctx, key, c.Deleted)
+		total.Added += stats.Added
+		total.Updated += stats.Updated
+		total.Removed += stats.Removed
+		if err != nil {
+			s.logger.Error("change sync failed", "key", key, "deleted", c.Deleted, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	err := errors.Join(errs...)
+	s.audit.Record(ctx, domain.AuditSync, "", fmt.Sprintf("events=%d %s", len(changes), syncDetail(total)), err)
+	span.SetAttributes(
+		output.Int("sync.added", total.Added),
+		output.Int("sync.updated", total.Updated),
+		output.Int("sync.removed", total.Removed),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "change sync failed")
+		return SyncResult{}, err
+	}
+	span.SetStatus(output.StatusOK, "")
+
+	return SyncResult{
+		SourcesAdded:    total.Added,
+		SourcesUpdated:  total.Updated,
+		SourcesRemoved:  total.Removed,
+		SourcesPinned:   total.Pinned,
+		SourcesTotal:    s.registry.SourceCount(),
+		SyncedAt:        s.markSynced(),
+		NextScheduledAt: s.getNextSync(),
+	}, nil
+}
+
+// changeKey maps a change notification to a storage key, the way List keys
+// objects; false when the object is outside the change scope.
+func (s *SyncService) changeKey(c input.ObjectChange) (string, bool) {
+	if s.changeContainer != "" && c.Container != s.changeContainer {
+		return "", false
+	}
+	if !strings.HasPrefix(c.Name, s.changePrefix) {
+		return "", false
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(c.Name, s.changePrefix), "/")
+	return key, key != ""
+}
+
+// syncDetail is the audit detail of a sync run.
+func syncDetail(stats SyncStats) string {
+	detail := fmt.Sprintf("added=%d removed=%d", stats.Added, stats.Removed)
+	if stats.Updated > 0 {
+		detail = fmt.Sprintf("added=%d updated=%d removed=%d", stats.Added, stats.Updated, stats.Removed)
+	}
+	if stats.Pinned > 0 {
+		detail += fmt.Sprintf(" pinned=%d", stats.Pinned)
+	}
+	return detail
+}
+
 // setNextSync updates the next scheduled sync time.
 func (s *SyncService) setNextSync(t time.Time) {
 	s.syncMu.Lock()
@@ -212,6 +444,23 @@ func (s *SyncService) getNextSync() time.Time {
 	return s.nextSync
 }
 
+// markSynced records now as the time of the last successful sync and
+// returns it.
+func (s *SyncService) markSynced() time.Time {
+	now := time.Now()
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	s.lastSync = now
+	return now
+}
+
+// LastSync implements input.SyncStatus.
+func (s *SyncService) LastSync() time.Time {
+	s.syncMu.RLock()
+	defer s.syncMu.RUnlock()
+	return s.lastSync
+}
+
 // Interval returns the sync interval.
 func (s *SyncService) Interval() time.Duration {
 	return s.interval
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/input"
 	"github.com/jobrunner/ortus/internal/ports/output"
 )
 
@@ -29,7 +30,7 @@ func TestSyncService_RateLimiting(t *testing.T) {
 	ctx := context.Background()
 
 	// First call should succeed (sync will return 0 added since storage is empty)
-	result, err := service.TriggerSync(ctx)
+	result, err := service.TriggerSync(ctx, input.SyncSelection{})
 	if err != nil {
 		t.Errorf("first sync should succeed, got error: %v", err)
 	}
@@ -38,12 +39,50 @@ func TestSyncService_RateLimiting(t *testing.T) {
 	}
 
 	// Immediate second call should be rate limited
-	_, err = service.TriggerSync(ctx)
+	_, err = service.TriggerSync(ctx, input.SyncSelection{})
 	if !errors.Is(err, domain.ErrRateLimited) {
 		t.Errorf("expected ErrRateLimited, got %v", err)
 	}
 }
 
+func TestSyncService_APICooldown(t *testing.T) {
+	registry := &SourceRegistry{
+		sources:   make(map[string]*sourceEntry),
+		logger:    testLogger(),
+		localPath: "/tmp",
+		storage:   &mockStorage{},
+		tracer:    output.NoOpTracer{},
+	}
+	ctx := context.Background()
+
+	service := NewSyncService(registry, time.Hour, output.NoOpTracer{}, testLogger())
+	service.SetAPICooldown(time.Minute)
+	if !service.LastSync().IsZero() {
+		t.Errorf("LastSync before any sync = %v, want zero", service.LastSync())
+	}
+	result, err := service.TriggerSync(ctx, input.SyncSelection{})
+	if err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	if !service.LastSync().Equal(result.SyncedAt) {
+		t.Errorf("LastSync = %v, want the first sync's %v", service.LastSync(), result.SyncedAt)
+	}
+	_, err = service.TriggerSync(ctx, input.SyncSelection{})
+	var rateErr *domain.RateLimitError
+	if !errors.As(err, &rateErr) || !errors.Is(err, domain.ErrRateLimited) {
+		t.Fatalf("second sync: err = %v, want a RateLimitError", err)
+	}
+	if rateErr.RetryAfter <= 50*time.Second || rateErr.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %v, want the rest of the minute", rateErr.RetryAfter)
+	}
+
+	// Without a cooldown every trigger syncs.
+	service.SetAPICooldown(0)
+	if _, err := service.TriggerSync(ctx, input.SyncSelection{}); err != nil {
+		t.Errorf("sync without cooldown: %v", err)
+	}
+}
+
 func TestSyncService_StartStop(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
 
@@ -92,6 +131,46 @@ func TestSyncService_Interval(t *testing.T) {
 	}
 }
 
+func TestSyncService_NextRun(t *testing.T) {
+	schedule, err := domain.ParseSchedule("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := func(expr string) domain.TimeWindow {
+		w, err := domain.ParseTimeWindow(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+	at := func(hour, minute int) time.Time { return time.Date(2026, 3, 2, hour, minute, 0, 0, time.UTC) } // a Monday
+
+	tests := []struct {
+		name     string
+		schedule *domain.Schedule
+		blackout []domain.TimeWindow
+		now      time.Time
+		want     time.Time
+	}{
+		{"interval", nil, nil, at(10, 5), at(12, 5)},
+		{"schedule", schedule, nil, at(10, 5), at(11, 0)},
+		{"postponed to window end", schedule, []domain.TimeWindow{window("08:00-18:00")}, at(10, 5), at(18, 0)},
+		{"interval postponed", nil, []domain.TimeWindow{window("Mon 11:00-13:00")}, at(10, 30), at(13, 0)},
+		{"adjoining windows", schedule, []domain.TimeWindow{window("08:00-12:00"), window("12:00-14:30")}, at(9, 0), at(14, 30)},
+		{"outside windows", schedule, []domain.TimeWindow{window("Tue 08:00-18:00")}, at(10, 5), at(11, 0)},
+		{"always blacked out", nil, []domain.TimeWindow{window("00:00-24:00")}, at(10, 5), at(0, 0).AddDate(0, 0, 7)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewSyncService(nil, 2*time.Hour, output.NoOpTracer{}, testLogger())
+			service.SetSchedule(tt.schedule, tt.blackout)
+			if got := service.nextRun(tt.now); !got.Equal(tt.want) {
+				t.Errorf("nextRun = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSyncService_SyncAddsNewSources(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
 
@@ -117,7 +196,7 @@ func TestSyncService_SyncAddsNewSources(t *testing.T) {
 	ctx := context.Background()
 
 	// First sync should add sources
-	result, err := service.TriggerSync(ctx)
+	result, err := service.TriggerSync(ctx, input.SyncSelection{})
 	if err != nil {
 		t.Fatalf("sync failed: %v", err)
 	}
@@ -232,6 +311,143 @@ func TestRegistry_SyncRemovesDeletedSources(t *testing.T) {
 	}
 }
 
+func TestSyncService_SyncChanges(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	registry := NewSourceRegistry([]output.SpatialSource{&mockRepository{}}, &mockStorage{}, nil, output.NoOpTracer{}, logger, "/tmp")
+	service := NewSyncService(registry, time.Hour, output.NoOpTracer{}, logger)
+	service.SetChangeScope("geodata", "prod/")
+	ctx := context.Background()
+
+	result, err := service.SyncChanges(ctx, []input.ObjectChange{
+		{Container: "geodata", Name: "prod/parcels.gpkg"},
+		{Container: "geodata", Name: "prod/roads.gpkg"},
+		{Container: "geodata", Name: "staging/other.gpkg"}, // outside the prefix
+		{Container: "archive", Name: "prod/old.gpkg"},      // other container
+		{Container: "geodata", Name: "prod/readme.txt"},    // not a source file
+	})
+	if err != nil {
+		t.Fatalf("SyncChanges: %v", err)
+	}
+	if result.SourcesAdded != 2 || result.SourcesTotal != 2 {
+		t.Errorf("result = %+v, want 2 added", result)
+	}
+
+	result, err = service.SyncChanges(ctx, []input.ObjectChange{
+		{Container: "geodata", Name: "prod/parcels.gpkg"},
+		{Container: "geodata", Name: "prod/roads.gpkg", Deleted: true},
+	})
+	if err != nil {
+		t.Fatalf("SyncChanges: %v", err)
+	}
+	if result.SourcesUpdated != 1 || result.SourcesRemoved != 1 || result.SourcesTotal != 1 {
+		t.Errorf("result = %+v, want 1 updated, 1 removed", result)
+	}
+
+	registry.storage = &mockStorage{downloadErr: errors.New("boom")}
+	if _, err := service.SyncChanges(ctx, []input.ObjectChange{{Container: "geodata", Name: "prod/new.gpkg"}}); err == nil {
+		t.Error("a failed download should fail the batch so the notifier retries")
+	}
+
+	m := NewMaintenanceMode(logger)
+	m.EnterMaintenance(ctx, "")
+	service.SetMaintenance(m)
+	if _, err := service.SyncChanges(ctx, nil); !errors.Is(err, domain.ErrMaintenance) {
+		t.Errorf("in maintenance: err = %v, want ErrMaintenance", err)
+	}
+}
+
+func TestSyncService_SyncSelected(t *testing.T) {
+	storage := &mockStorage{objects: []output.StorageObject{
+		{Key: "prod/parcels.gpkg"}, {Key: "prod/roads.gpkg"}, {Key: "staging/draft.gpkg"},
+	}}
+	registry := NewSourceRegistry([]output.SpatialSource{&mockRepository{}}, storage, nil, output.NoOpTracer{}, testLogger(), "/tmp")
+	service := NewSyncService(registry, time.Hour, output.NoOpTracer{}, testLogger())
+	service.SetAPICooldown(0)
+	ctx := context.Background()
+
+	if _, err := service.TriggerSync(ctx, input.SyncSelection{}); err != nil {
+		t.Fatalf("full sync: %v", err)
+	}
+
+	// By id: refreshed in place, without listing.
+	storage.listErr = errors.New("no listing expected")
+	result, err := service.TriggerSync(ctx, input.SyncSelection{SourceIDs: []string{"parcels"}})
+	if err != nil {
+		t.Fatalf("sync by id: %v", err)
+	}
+	if result.SourcesUpdated != 1 || result.SourcesAdded != 0 || result.SourcesTotal != 3 {
+		t.Errorf("by id: result = %+v, want 1 updated of 3", result)
+	}
+	if _, err := service.TriggerSync(ctx, input.SyncSelection{SourceIDs: []string{"unknown"}}); !errors.Is(err, domain.ErrSourceNotFound) {
+		t.Errorf("unknown id: err = %v, want ErrSourceNotFound", err)
+	}
+
+	// By prefix: listed keys refreshed or added, loaded keys gone from
+	// storage removed, other prefixes untouched.
+	storage.listErr = nil
+	storage.objects = []output.StorageObject{{Key: "prod/parcels.gpkg"}, {Key: "prod/rivers.gpkg"}, {Key: "staging/draft.gpkg"}}
+	result, err = service.TriggerSync(ctx, input.SyncSelection{Prefixes: []string{"prod/"}})
+	if err != nil {
+		t.Fatalf("sync by prefix: %v", err)
+	}
+	if result.SourcesUpdated != 1 || result.SourcesAdded != 1 || result.SourcesRemoved != 1 || result.SourcesTotal != 3 {
+		t.Errorf("by prefix: result = %+v, want 1 updated, 1 added, 1 removed", result)
+	}
+	if !registry.IsLoaded("draft") || registry.IsLoaded("roads") {
+		t.Errorf("loaded = %v, want draft kept and roads removed", registry.ReadySourceIDs())
+	}
+}
+
+func TestSyncService_PinnedSources(t *testing.T) {
+	storage := &mockStorage{objects: []output.StorageObject{
+		{Key: "parcels.gpkg", ETag: `"aaa"`}, {Key: "roads.gpkg", ETag: "bbb"},
+	}}
+	registry := NewSourceRegistry([]output.SpatialSource{&mockRepository{}}, storage, nil, output.NoOpTracer{}, testLogger(), "/tmp")
+	registry.SetSourceOverrides(map[string]domain.SourceOverride{
+		"parcels": {Pin: domain.Pin{ETag: "aaa"}},
+		"roads":   {Pin: domain.Pin{ETag: "old"}},
+		"rivers":  {Pin: domain.Pin{VersionID: "v1"}},
+	})
+	service := NewSyncService(registry, time.Hour, output.NoOpTracer{}, testLogger())
+	service.SetAPICooldown(0)
+	ctx := context.Background()
+
+	// Only the object with the pinned ETag loads; quotes don't count.
+	result, err := service.TriggerSync(ctx, input.SyncSelection{})
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if result.SourcesAdded != 1 || result.SourcesPinned != 1 || registry.IsLoaded("roads") {
+		t.Errorf("first sync: result = %+v, want parcels added and roads held back", result)
+	}
+
+	// A replaced or deleted pinned object is reported, not applied.
+	storage.objects = []output.StorageObject{{Key: "parcels.gpkg", ETag: "ccc"}}
+	if result, err = service.TriggerSync(ctx, input.SyncSelection{}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if result.SourcesPinned != 1 || result.SourcesRemoved != 0 {
+		t.Errorf("replaced: result = %+v, want 1 pinned", result)
+	}
+	storage.objects = nil
+	if result, err = service.TriggerSync(ctx, input.SyncSelection{}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if result.SourcesPinned != 1 || result.SourcesRemoved != 0 || !registry.IsLoaded("parcels") {
+		t.Errorf("deleted: result = %+v, want parcels kept", result)
+	}
+	for _, deleted := range []bool{false, true} {
+		if stats, err := registry.SyncObject(ctx, "parcels.gpkg", deleted); err != nil || stats != (SyncStats{Pinned: 1}) {
+			t.Errorf("SyncObject(deleted=%v) = %+v, %v; want 1 pinned", deleted, stats, err)
+		}
+	}
+
+	// A version pin needs storage that keeps versions.
+	if _, err := registry.SyncObject(ctx, "rivers.gpkg", false); !errors.Is(err, domain.ErrUnsupported) {
+		t.Errorf("version pin: err = %v, want ErrUnsupported", err)
+	}
+}
+
 func TestRegistry_FindSourcesToRemove(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
 
@@ -0,0 +1,92 @@
+package application
+
+import (
+	"context"
+
+	"github.com/jobrunner/ortus/internal/domain"
+	"github.com/jobrunner/ortus/internal/ports/output"
+)
+
+// tableReader returns the attribute-table capability of the adapter serving
+// sourceID; ok is false when the adapter has none.
+func (r *SourceRegistry) tableReader(sourceID string) (reader output.AttributeTableReader, ok bool, err error) {
+	r.mu.RLock()
+	entry, found := r.sources[sourceID]
+	r.mu.RUnlock()
+	if !found || entry.Repo == nil {
+		return nil, false, domain.ErrSourceNotFound
+	}
+	reader, ok = entry.Repo.(output.AttributeTableReader)
+	return reader, ok, nil
+}
+
+// Tables lists the attribute tables of a loaded source. A source whose
+// adapter does not implement output.AttributeTableReader (e.g. raster) has
+// none. It implements input.AttributeTables.
+func (r *SourceRegistry) Tables(ctx context.Context, sourceID string) ([]domain.AttributeTable, error) {
+	ctx, span := r.tracer.Start(ctx, "SourceRegistry.Tables",
+		output.WithAttributes(output.String("ortus.source.id", sourceID)),
+	)
+	defer span.End()
+
+	reader, ok, err := r.tableReader(sourceID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "source not found")
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+	return reader.Tables(ctx, sourceID)
+}
+
+// ReadTable returns a page of rows from an attribute table of a loaded
+// source. It implements input.AttributeTables.
+func (r *SourceRegistry) ReadTable(ctx context.Context, sourceID, table string, q domain.TableQuery) (domain.TableRows, error) {
+	ctx, span := r.tracer.Start(ctx, "SourceRegistry.ReadTable",
+		output.WithAttributes(
+			output.String("ortus.source.id", sourceID),
+			output.String("ortus.table.name", table),
+		),
+	)
+	defer span.End()
+
+	reader, ok, err := r.tableReader(sourceID)
+	if err == nil && !ok {
+		err = domain.ErrTableNotFound
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(output.StatusError, "table not found")
+		return domain.TableRows{}, err
+	}
+	return reader.ReadTable(ctx, sourceID, table, q)
+}
+
+// Tile returns one tile of a tile set of a loaded source. A source whose
+// adapter does not implement output.TileReader has no tile sets. It
+// implements input.Tiles.
+func (r *SourceRegistry) Tile(ctx context.Context, sourceID, tileSet string, z, x, y int) ([]byte, error) {
+	ctx, span := r.tracer.Start(ctx, "SourceRegistry.Tile",
+		output.WithAttributes(
+			output.String("ortus.source.id", sourceID),
+			output.String("ortus.tileset.name", tileSet),
+			output.Int("ortus.tile.z", z),
+		),
+	)
+	defer span.End()
+
+	r.mu.RLock()
+	entry, found := r.sources[sourceID]
+	r.mu.RUnlock()
+	if !found || entry.Repo == nil {
+		span.RecordError(domain.ErrSourceNotFound)
+		return nil, domain.ErrSourceNotFound
+	}
+	reader, ok := entry.Repo.(output.TileReader)
+	if !ok {
+		return nil, domain.ErrTableNotFound
+	}
+	return reader.Tile(ctx, sourceID, tileSet, z, x, y)
+}
@@ -0,0 +1,135 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// idleConnTuner resizes the idle connection pool of one source. Declared
+// consumer-side; the GeoPackage repository satisfies it, and ignores ids it
+// does not serve.
+type idleConnTuner interface {
+	SetMaxIdleConns(sourceID string, n int) error
+}
+
+// readySources is the registry surface tiering needs.
+type readySources interface {
+	ReadySourceIDs() []string
+}
+
+// TieringConfig configures idle-connection tiering.
+type TieringConfig struct {
+	Interval      time.Duration // how often sources are re-tiered
+	Window        time.Duration // popularity window the ranking is taken over
+	HotSources    int           // number of most-hit sources kept hot
+	HotIdleConns  int           // idle pool size of a hot source
+	ColdIdleConns int           // idle pool size of every other source
+}
+
+// Tiering periodically splits the ready sources into hot and cold by
+// popularity and sizes their idle connection pools accordingly: the most-hit
+// sources keep a full pool, the rest keep only ColdIdleConns, so idle SQLite
+// handles (and their page caches) follow actual use.
+type Tiering struct {
+	popularity *Popularity
+	tuner      idleConnTuner
+	registry   readySources
+	cfg        TieringConfig
+	logger     *slog.Logger
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewTiering creates the tiering loop; call Start to run it.
+func NewTiering(popularity *Popularity, tuner idleConnTuner, registry readySources, cfg TieringConfig, logger *slog.Logger) *Tiering {
+	return &Tiering{
+		popularity: popularity,
+		tuner:      tuner,
+		registry:   registry,
+		cfg:        cfg,
+		logger:     logger,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start begins periodic re-tiering.
+func (t *Tiering) Start(ctx context.Context) {
+	t.logger.Info("starting idle-connection tiering",
+		"interval", t.cfg.Interval, "window", t.cfg.Window, "hot_sources", t.cfg.HotSources)
+
+	t.wg.Add(1)
+	go t.run(ctx)
+}
+
+func (t *Tiering) run(ctx context.Context) {
+	defer t.wg.Done()
+	defer func() {
+		if rec := recover(); rec != nil {
+			t.logger.Error("tiering panic recovered", "panic", rec)
+		}
+	}()
+
+	ticker := time.NewTicker(t.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.stopCh:
+			return
+		case <-ticker.C:
+			t.apply()
+		}
+	}
+}
+
+// Stop stops the tiering loop.
+func (t *Tiering) Stop() {
+	close(t.stopCh)
+	t.wg.Wait()
+}
+
+// apply re-tiers every ready source once. A source is hot when it is among
+// the HotSources most-hit ready sources of the window and had at least one
+// hit. Every source is resized on every pass: resizing is cheap, and a source
+// reloaded since the last pass comes back with a full pool.
+func (t *Tiering) apply() {
+	ready := t.registry.ReadySourceIDs()
+	isReady := make(map[string]bool, len(ready))
+	for _, id := range ready {
+		isReady[id] = true
+	}
+	hot := make(map[string]bool, t.cfg.HotSources)
+	for _, p := range t.popularity.Ranking(t.cfg.Window) {
+		if len(hot) >= t.cfg.HotSources {
+			break
+		}
+		// Unloaded sources keep their hits for a while; they must not take
+		// a hot slot.
+		if isReady[p.SourceID] {
+			hot[p.SourceID] = true
+		}
+	}
+
+	nHot, nCold := 0, 0
+	for _, id := range ready {
+		n := t.cfg.ColdIdleConns
+		if hot[id] {
+			n = t.cfg.HotIdleConns
+		}
+		if err := t.tuner.SetMaxIdleConns(id, n); err != nil {
+			t.logger.Warn("failed to resize idle connections", "source", id, "idle_conns", n, "error", err)
+			continue
+		}
+		if hot[id] {
+			nHot++
+		} else {
+			nCold++
+		}
+	}
+	t.logger.Debug("sources tiered", "hot", nHot, "cold", nCold)
+}
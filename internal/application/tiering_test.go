@@ -0,0 +1,55 @@
+package application
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeIdleTuner map[string]int
+
+func (f fakeIdleTuner) SetMaxIdleConns(sourceID string, n int) error {
+	f[sourceID] = n
+	return nil
+}
+
+type fakeReadySources []string
+
+func (f fakeReadySources) ReadySourceIDs() []string { return f }
+
+func TestTieringApply(t *testing.T) {
+	p := NewPopularity()
+	for range 3 {
+		p.Record("hot")
+	}
+	p.Record("warm")
+	p.Record("unloaded")
+
+	tuner := fakeIdleTuner{}
+	tr := NewTiering(p, tuner, fakeReadySources{"hot", "warm", "cold"}, TieringConfig{
+		Interval:      time.Minute,
+		Window:        time.Hour,
+		HotSources:    2,
+		HotIdleConns:  4,
+		ColdIdleConns: 1,
+	}, testLogger())
+	tr.apply()
+
+	// "unloaded" outranks "warm" (ties break by id) but is not ready: it takes
+	// no hot slot and is not resized.
+	want := fakeIdleTuner{"hot": 4, "warm": 4, "cold": 1}
+	if !reflect.DeepEqual(tuner, want) {
+		t.Errorf("idle conns = %v, want %v", tuner, want)
+	}
+
+	// Demotion once the traffic moves on.
+	for range 5 {
+		p.Record("cold")
+	}
+	tr.cfg.HotSources = 1
+	tr.apply()
+	want = fakeIdleTuner{"hot": 1, "warm": 1, "cold": 4}
+	if !reflect.DeepEqual(tuner, want) {
+		t.Errorf("idle conns after shift = %v, want %v", tuner, want)
+	}
+}
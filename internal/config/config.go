@@ -3,24 +3,43 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/jobrunner/ortus/internal/domain"
 )
 
 // Storage type constants.
 const (
-	StorageTypeLocal = "local"
-	StorageTypeS3    = "s3"
-	StorageTypeAzure = "azure"
-	StorageTypeHTTP  = "http"
+	StorageTypeLocal   = "local"
+	StorageTypeS3      = "s3"
+	StorageTypeAzure   = "azure"
+	StorageTypeHTTP    = "http"
+	StorageTypeCatalog = "catalog"
 )
 
 // dnsProviderAzure is the only supported ACME DNS-01 challenge provider.
 const dnsProviderAzure = "azure"
 
+// ACME challenge types (tls.challenge).
+const (
+	tlsChallengeDNS01     = "dns-01"
+	tlsChallengeHTTP01    = "http-01"
+	tlsChallengeTLSALPN01 = "tls-alpn-01"
+)
+
 // mcpLoopbackHost is the canonical loopback address; MCP binds here by default
 // and treats it (with localhost/::1) as trusted, token-optional.
 const mcpLoopbackHost = "127.0.0.1"
@@ -38,6 +57,17 @@ type Config struct {
 	MCP       MCPConfig       `mapstructure:"mcp"`
 	Gazetteer GazetteerConfig `mapstructure:"gazetteer"`
 	Raster    RasterConfig    `mapstructure:"raster"`
+	Features  FeaturesConfig  `mapstructure:"features"`
+	// Packages overrides display values per source id.
+	Packages map[string]PackageConfig `mapstructure:"packages"`
+	// Federation forwards queries to peer instances for sources hosted there.
+	Federation FederationConfig `mapstructure:"federation"`
+	// Watcher selects how local storage directories are watched for changes.
+	Watcher WatcherConfig `mapstructure:"watcher"`
+	// Frontend brands the query page served at / (server.frontend_enabled).
+	Frontend FrontendConfig `mapstructure:"frontend"`
+	// Geocoder resolves addresses for GET /api/v1/geocode.
+	Geocoder GeocoderConfig `mapstructure:"geocoder"`
 
 	// Build is populated by main.go from -ldflags at startup; not loaded
 	// from config files. Used for the MCP Implementation.Version field
@@ -45,6 +75,138 @@ type Config struct {
 	Build BuildInfo `mapstructure:"-"`
 }
 
+// PackageConfig overrides what is derived from a package's file name or
+// metadata — display name, license, attribution, tags — for the source whose
+// id is the map key, and can hide some of its layers. Keys are lower-cased by
+// the config loader and cannot contain dots; set ID for such source ids.
+// Empty fields keep the derived value.
+type PackageConfig struct {
+	ID          string            `mapstructure:"id"` // source id, when the map key cannot spell it
+	Name        string            `mapstructure:"name"`
+	License     string            `mapstructure:"license"`
+	LicenseURL  string            `mapstructure:"license_url"`
+	Attribution string            `mapstructure:"attribution"`
+	Tags        []string          `mapstructure:"tags"`
+	Layers      LayerFilterConfig `mapstructure:"layers"`
+	// LoadMode trades memory for latency on hot packages: "mmap" maps the
+	// whole file into memory, "memory" copies it into an in-memory database
+	// when it is loaded (for small packages; it holds the full file size in
+	// RAM). Empty reads the file through ordinary I/O.
+	LoadMode string `mapstructure:"load_mode"`
+	// Priority orders the source among the others in queries and their
+	// results: higher first, sources of equal priority by id. Default 0.
+	Priority int `mapstructure:"priority"`
+	// Pin holds the package to one stored version: sync never replaces or
+	// removes it, and reports the changes it holds back.
+	Pin PinConfig `mapstructure:"pin"`
+}
+
+// PinConfig pins a package to the object with ETag, or to version
+// VersionID of it on S3 with bucket versioning. Set one of them.
+type PinConfig struct {
+	ETag      string `mapstructure:"etag"`
+	VersionID string `mapstructure:"version_id"`
+}
+
+// LayerFilterConfig selects the layers of a package that are indexed and
+// queried. Entries are layer (table) names or path.Match globs such as
+// "qa_*". With Include set only matching layers are kept; Exclude drops
+// matching layers after that.
+//
+// Any other key is a layer name with settings for that layer
+// (packages.<id>.layers.<name>.properties).
+type LayerFilterConfig struct {
+	Include []string               `mapstructure:"include"`
+	Exclude []string               `mapstructure:"exclude"`
+	Layer   map[string]LayerConfig `mapstructure:",remain"`
+}
+
+// LayerConfig holds the settings of one layer of a package.
+type LayerConfig struct {
+	// Properties, when set, are the only feature properties the layer
+	// exposes; Redact names properties it never exposes (personal data, say).
+	// Both apply whatever properties a client asks for.
+	Properties []string `mapstructure:"properties"`
+	Redact     []string `mapstructure:"redact"`
+	// Mapping renames and converts properties, keyed by column name. The
+	// property settings above use the mapped names.
+	Mapping map[string]PropertyMappingConfig `mapstructure:"mapping"`
+	// Computed adds properties computed per feature with SQL expressions.
+	Computed []ComputedPropertyConfig `mapstructure:"computed"`
+	// Priority orders the layer among the layers of its package: higher
+	// first, layers of equal priority in the package's order. Default 0.
+	Priority int `mapstructure:"priority"`
+}
+
+// ComputedPropertyConfig is a property computed with a SpatiaLite SQL
+// expression over the layer's columns; {geom} stands for its geometry.
+type ComputedPropertyConfig struct {
+	Name string `mapstructure:"name"`
+	Expr string `mapstructure:"expr"`
+}
+
+// PropertyMappingConfig renames a column's property and converts its value.
+type PropertyMappingConfig struct {
+	Name   string `mapstructure:"name"`   // exposed name; "" keeps the column name
+	Type   string `mapstructure:"type"`   // string | integer | number | boolean | date; "" keeps the value
+	Format string `mapstructure:"format"` // Go time layout of date values (default 2006-01-02)
+}
+
+// SourceID returns the source id the entry under key applies to.
+func (p PackageConfig) SourceID(key string) string {
+	if p.ID != "" {
+		return p.ID
+	}
+	return key
+}
+
+// FederationConfig lets this instance answer for sources hosted by peer
+// instances: point queries are forwarded to the peers serving sources this
+// instance lacks, and their results merged with the local ones.
+type FederationConfig struct {
+	Enabled         bool                  `mapstructure:"enabled"`
+	Timeout         time.Duration         `mapstructure:"timeout"`          // per-peer deadline of a forwarded query
+	RefreshInterval time.Duration         `mapstructure:"refresh_interval"` // how often the peers' source lists are fetched
+	Peers           map[string]PeerConfig `mapstructure:"peers"`            // keyed by peer name
+}
+
+// PeerConfig is one federation peer.
+type PeerConfig struct {
+	URL      string        `mapstructure:"url"`      // base URL of the peer, without /api/v1
+	Priority int           `mapstructure:"priority"` // lower wins when two peers serve the same source
+	Timeout  time.Duration `mapstructure:"timeout"`  // 0 = federation.timeout
+}
+
+// GeocoderConfig selects the external service GET /api/v1/geocode resolves
+// addresses with. An empty Provider turns address search off.
+type GeocoderConfig struct {
+	Provider string `mapstructure:"provider"` // "" (off), "nominatim" or "custom"
+	// URL is the base URL of the Nominatim instance (default: the public
+	// OpenStreetMap one) or the search URL of the custom service.
+	URL       string            `mapstructure:"url"`
+	UserAgent string            `mapstructure:"user_agent"` // identifies this deployment to the service
+	Email     string            `mapstructure:"email"`      // nominatim: contact address sent with each search
+	Headers   map[string]string `mapstructure:"headers"`    // sent with each search, e.g. an API key
+	Timeout   time.Duration     `mapstructure:"timeout"`    // per search
+	// MinInterval spaces the searches sent to the service; the public
+	// Nominatim allows one per second. 0 sends them as they come.
+	MinInterval time.Duration `mapstructure:"min_interval"`
+	// CacheTTL is how long an answer is reused for the same search; 0
+	// caches nothing.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	// License and Attribution are shown with the results; empty takes the
+	// provider's (OpenStreetMap's ODbL for nominatim).
+	License     string `mapstructure:"license"`
+	LicenseURL  string `mapstructure:"license_url"`
+	Attribution string `mapstructure:"attribution"`
+}
+
+// Geocoder providers (geocoder.provider).
+const (
+	GeocoderProviderNominatim = "nominatim"
+	GeocoderProviderCustom    = "custom"
+)
+
 // BuildInfo captures the binary's build identity. Populated from
 // -ldflags in main.go (or left as "dev"/"none" for local builds).
 type BuildInfo struct {
@@ -55,18 +217,90 @@ type BuildInfo struct {
 
 // ServerConfig holds HTTP server configuration.
 type ServerConfig struct {
-	Host            string          `mapstructure:"host"`
-	Port            int             `mapstructure:"port"`
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+	// Listen replaces Host and Port with another listener: "unix://PATH" for
+	// a Unix domain socket, or "systemd" for the socket a systemd socket unit
+	// passes (LISTEN_FDS). Empty listens on TCP Host:Port.
+	Listen          string          `mapstructure:"listen"`
 	ReadTimeout     time.Duration   `mapstructure:"read_timeout"`
 	WriteTimeout    time.Duration   `mapstructure:"write_timeout"`
 	ShutdownTimeout time.Duration   `mapstructure:"shutdown_timeout"`
 	RateLimit       RateLimitConfig `mapstructure:"rate_limit"`
 	CORS            CORSConfig      `mapstructure:"cors"`
 	FrontendEnabled bool            `mapstructure:"frontend_enabled"` // Enable web frontend at /
+	// StaticDir, when set, serves the files of this directory as the frontend
+	// at / instead of the embedded page, with paths that name no file falling
+	// back to index.html (single-page app routing). It must contain index.html.
+	StaticDir string `mapstructure:"static_dir"`
 	// ReadyWhenEmpty: when true (default), readiness reports ready once the
 	// initial load pass is done even with zero sources ("no data today"). When
 	// false, readiness additionally requires at least one ready source.
 	ReadyWhenEmpty bool `mapstructure:"ready_when_empty"`
+	// StartupTimeout bounds the startup pass (initial source load, gazetteer
+	// warmup): past it, /health/startup reports "timeout" instead of
+	// "starting". 0 (default) waits indefinitely.
+	StartupTimeout time.Duration `mapstructure:"startup_timeout"`
+	Admin          AdminConfig   `mapstructure:"admin"`
+	// ErrorFormat selects the error body: "problem" (default) for RFC 7807
+	// application/problem+json, "legacy" for the former {error, message}
+	// envelope.
+	ErrorFormat string `mapstructure:"error_format"`
+	// TrustedProxies are CIDRs of front proxies/load balancers. A request
+	// whose direct peer is within one takes its client IP from
+	// X-Forwarded-For (or X-Real-IP) for logs, rate limiting and audit
+	// records. Empty (default) = never trust forwarded headers.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// ProxyProtocol reads a PROXY protocol (v1 or v2) header on every
+	// connection from a trusted proxy — from every connection when
+	// TrustedProxies is empty — and uses the client address it carries.
+	ProxyProtocol bool `mapstructure:"proxy_protocol"`
+	// MaxBodyMB caps the body of every request; a larger one gets a 413.
+	// The POST endpoints bound their bodies further by their point caps.
+	// 0 = no cap beyond theirs.
+	MaxBodyMB int `mapstructure:"max_body_mb"`
+	// MaxQueryLength caps the query string of every request in bytes; a
+	// longer one gets a 414. 0 = no cap.
+	MaxQueryLength int `mapstructure:"max_query_length"`
+}
+
+// Listener kinds of server.listen.
+const (
+	ListenUnixPrefix = "unix://"
+	ListenSystemd    = "systemd"
+)
+
+// ErrorFormat values of server.error_format.
+const (
+	ErrorFormatProblem = "problem"
+	ErrorFormatLegacy  = "legacy"
+)
+
+// FrontendConfig holds the settings of the query frontend.
+type FrontendConfig struct {
+	Branding FrontendBrandingConfig `mapstructure:"branding"`
+}
+
+// FrontendBrandingConfig lets a deployment brand the query page without
+// forking it. Empty fields keep the stock look. The values are rendered into
+// the page at startup.
+type FrontendBrandingConfig struct {
+	LogoURL      string `mapstructure:"logo_url"`      // http(s) URL or absolute path of a logo shown in the header
+	PrimaryColor string `mapstructure:"primary_color"` // CSS hex color (#rgb or #rrggbb) replacing the accent color
+	FooterText   string `mapstructure:"footer_text"`   // plain text shown above the footer links
+}
+
+// AdminConfig enables the operator endpoints under /admin (maintenance mode).
+// Like the MCP token, the bearer token is read from ORTUS_ADMIN_TOKEN only,
+// never from the config file.
+type AdminConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Token is populated from ORTUS_ADMIN_TOKEN at Load() time. Required when
+	// enabled.
+	Token string `mapstructure:"-"`
+	// Pprof serves the Go runtime profiles under /debug/pprof, behind the
+	// admin token.
+	Pprof bool `mapstructure:"pprof"`
 }
 
 // CORSConfig holds CORS configuration.
@@ -84,30 +318,182 @@ type RateLimitConfig struct {
 	Enabled bool    `mapstructure:"enabled"`
 	Rate    float64 `mapstructure:"rate"`  // sustained requests per second per client IP
 	Burst   int     `mapstructure:"burst"` // token-bucket burst per client IP
-	// TrustedProxies are CIDRs of front proxies/load balancers. When the direct
-	// peer is within one, the client IP is taken from X-Forwarded-For; otherwise
-	// the direct peer (RemoteAddr) is used. Empty (default) = never trust
-	// forwarded headers — correct for ortus exposed directly on a public IP.
+	// TrustedProxies is the former place of server.trusted_proxies and is
+	// still honored in addition to it.
 	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 // StorageConfig holds object storage configuration.
 type StorageConfig struct {
-	Type      string      `mapstructure:"type"` // s3, azure, http, local
-	LocalPath string      `mapstructure:"local_path"`
-	S3        S3Config    `mapstructure:"s3"`
-	Azure     AzureConfig `mapstructure:"azure"`
-	HTTP      HTTPConfig  `mapstructure:"http"`
+	Type string `mapstructure:"type"` // s3, azure, http, catalog, local
+	// LocalPaths is storage.local_path: the data directory, or for local
+	// storage a list of them. Remote storage downloads into the first one.
+	LocalPaths []string           `mapstructure:"local_path"`
+	Local      LocalStorageConfig `mapstructure:"local"`
+	S3         S3Config           `mapstructure:"s3"`
+	Azure      AzureConfig        `mapstructure:"azure"`
+	HTTP       HTTPConfig         `mapstructure:"http"`
+	Catalog    CatalogConfig      `mapstructure:"catalog"`
+	SourceID   SourceIDConfig     `mapstructure:"source_id"`
+	Cache      CacheConfig        `mapstructure:"cache"`
+	Faults     FaultsConfig       `mapstructure:"faults"`
+	// CircuitBreaker guards remote storage backends.
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	// HealthCheck probes the backend for /health.
+	HealthCheck StorageHealthCheckConfig `mapstructure:"health_check"`
+}
+
+// StorageHealthCheckConfig probes the storage backend on an interval
+// (storage.health_check) and reports the outcome under components on
+// /health. A check lists the storage, or with Key set asks whether that
+// sentinel object exists.
+type StorageHealthCheckConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	Key      string        `mapstructure:"key"` // sentinel object key; "" lists
+}
+
+func (h StorageHealthCheckConfig) validate() error {
+	if !h.Enabled {
+		return nil
+	}
+	if h.Interval <= 0 || h.Timeout <= 0 {
+		return fmt.Errorf("storage.health_check.interval and timeout must be positive")
+	}
+	return nil
+}
+
+// CircuitBreakerConfig stops calls to a remote storage backend that keeps
+// failing (storage.circuit_breaker): after FailureThreshold consecutive
+// failures storage calls fail fast for OpenTimeout, then a single probe call
+// decides whether the backend is back. /health reports the storage component
+// degraded meanwhile.
+type CircuitBreakerConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	OpenTimeout      time.Duration `mapstructure:"open_timeout"`
+}
+
+func (c CircuitBreakerConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.FailureThreshold < 1 {
+		return fmt.Errorf("storage.circuit_breaker.failure_threshold must be at least 1")
+	}
+	if c.OpenTimeout <= 0 {
+		return fmt.Errorf("storage.circuit_breaker.open_timeout must be positive")
+	}
+	return nil
+}
+
+// FaultsConfig injects storage failures and delays (storage.faults), so sync
+// and retry behavior can be tested against a misbehaving backend in test and
+// staging deployments. Never enable it in production.
+type FaultsConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	ErrorRate float64       `mapstructure:"error_rate"` // share of calls that fail, 0 to 1
+	Latency   time.Duration `mapstructure:"latency"`    // added to every call
+	Jitter    time.Duration `mapstructure:"jitter"`     // random extra delay, up to this much
+	// Operations limits the faults to list, download, get_reader and exists
+	// calls; empty means all of them.
+	Operations []string `mapstructure:"operations"`
+}
+
+// faultOperations are the storage calls faults can be limited to.
+var faultOperations = []string{"list", "download", "get_reader", "exists"}
+
+func (f FaultsConfig) validate() error {
+	if !f.Enabled {
+		return nil
+	}
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return fmt.Errorf("storage.faults.error_rate must be between 0 and 1")
+	}
+	if f.Latency < 0 || f.Jitter < 0 {
+		return fmt.Errorf("storage.faults.latency and jitter must not be negative")
+	}
+	for _, op := range f.Operations {
+		if !slices.Contains(faultOperations, op) {
+			return fmt.Errorf("storage.faults.operations: unknown operation %q (%s)", op, strings.Join(faultOperations, ", "))
+		}
+	}
+	return nil
+}
+
+// CacheConfig bounds the disk space remote storage downloads take in the
+// local path.
+type CacheConfig struct {
+	// MaxSizeMB caps the downloaded source files; least recently used
+	// unloaded sources are evicted to make room. 0 = unbounded.
+	MaxSizeMB int64 `mapstructure:"max_size_mb"`
+}
+
+// MaxBytes returns the cache limit in bytes (0 = unbounded).
+func (c CacheConfig) MaxBytes() int64 {
+	return c.MaxSizeMB << 20
+}
+
+// LocalPath returns the primary data directory: the first entry of
+// storage.local_path. Remote storage caches its downloads there.
+func (s StorageConfig) LocalPath() string {
+	if len(s.LocalPaths) == 0 {
+		return ""
+	}
+	return s.LocalPaths[0]
+}
+
+// LocalStorageConfig tunes how local storage lists its directories.
+type LocalStorageConfig struct {
+	// Recursive descends into subdirectories (default true).
+	Recursive bool `mapstructure:"recursive"`
+	// Exclude skips files and directories whose object key matches one of
+	// these globs; "**" matches any number of directories ("**/tmp/**").
+	Exclude []string `mapstructure:"exclude"`
+}
+
+// SourceIDConfig selects how source ids are derived from object keys.
+type SourceIDConfig struct {
+	Strategy string `mapstructure:"strategy"` // filename (default), prefixed, regex, metadata
+	Pattern  string `mapstructure:"pattern"`  // regex: matched against the object key
+	Template string `mapstructure:"template"` // regex: expanded from the pattern's groups, e.g. "${name}-${region}"
 }
 
+// S3 credential sources (storage.s3.credentials).
+const (
+	// S3CredentialsAuto uses the static keys when set, otherwise the SDK
+	// default chain.
+	S3CredentialsAuto = "auto"
+	// S3CredentialsChain uses only the SDK default chain (environment, shared
+	// config, IRSA web identity, ECS task role, EC2 instance profile) and
+	// rejects static keys in the config.
+	S3CredentialsChain = "chain"
+	// S3CredentialsStatic requires access_key_id and secret_access_key.
+	S3CredentialsStatic = "static"
+)
+
 // S3Config holds AWS S3 configuration.
 type S3Config struct {
-	Bucket          string `mapstructure:"bucket"`
-	Region          string `mapstructure:"region"`
-	Prefix          string `mapstructure:"prefix"`
-	Endpoint        string `mapstructure:"endpoint"`
-	AccessKeyID     string `mapstructure:"access_key_id"`
-	SecretAccessKey string `mapstructure:"secret_access_key"`
+	Bucket          string             `mapstructure:"bucket"`
+	Region          string             `mapstructure:"region"`
+	Prefix          string             `mapstructure:"prefix"`
+	Endpoint        string             `mapstructure:"endpoint"`
+	Credentials     string             `mapstructure:"credentials"` // auto (default), chain, static
+	AccessKeyID     string             `mapstructure:"access_key_id"`
+	SecretAccessKey string             `mapstructure:"secret_access_key"`
+	SessionToken    string             `mapstructure:"session_token"` // temporary credentials (with the keys)
+	AssumeRole      S3AssumeRoleConfig `mapstructure:"assume_role"`
+}
+
+// S3AssumeRoleConfig makes ortus assume an IAM role through STS on top of the
+// base credentials. The temporary credentials are refreshed before they
+// expire.
+type S3AssumeRoleConfig struct {
+	RoleARN     string        `mapstructure:"role_arn"`
+	ExternalID  string        `mapstructure:"external_id"`
+	SessionName string        `mapstructure:"session_name"` // default: ortus
+	Duration    time.Duration `mapstructure:"duration"`     // 0 = STS default (1h)
 }
 
 // AzureConfig holds Azure Blob Storage configuration.
@@ -122,19 +508,139 @@ type AzureConfig struct {
 // HTTPConfig holds HTTP download configuration.
 type HTTPConfig struct {
 	BaseURL   string        `mapstructure:"base_url"`
-	IndexFile string        `mapstructure:"index_file"` // default: index.txt
+	IndexFile string        `mapstructure:"index_file"` // default: index.txt; .json/.csv are manifests
 	Timeout   time.Duration `mapstructure:"timeout"`
 	Username  string        `mapstructure:"username"`
 	Password  string        `mapstructure:"password"`
+	Autoindex bool          `mapstructure:"autoindex"` // list from the directory listing instead of index_file
+}
+
+// CatalogConfig holds the data-portal harvester configuration: GeoPackage
+// distributions are discovered in a CSW or DCAT catalog instead of an index file.
+type CatalogConfig struct {
+	URL          string        `mapstructure:"url"`
+	Protocol     string        `mapstructure:"protocol"`      // csw or dcat
+	Keywords     []string      `mapstructure:"keywords"`      // dataset must carry one of these; empty = any
+	TitlePattern string        `mapstructure:"title_pattern"` // regexp on the dataset title; empty = any
+	MaxRecords   int           `mapstructure:"max_records"`   // CSW page size
+	Timeout      time.Duration `mapstructure:"timeout"`
+	Username     string        `mapstructure:"username"`
+	Password     string        `mapstructure:"password"`
 }
 
 // QueryConfig holds query-related configuration.
 type QueryConfig struct {
-	Timeout      time.Duration    `mapstructure:"timeout"`
-	MaxFeatures  int              `mapstructure:"max_features"`
-	WithGeometry bool             `mapstructure:"with_geometry"` // Include geometry in results (default: false)
-	SQLite       SQLiteConfig     `mapstructure:"sqlite"`
-	Batch        QueryBatchConfig `mapstructure:"batch"`
+	Timeout      time.Duration        `mapstructure:"timeout"`
+	MaxFeatures  int                  `mapstructure:"max_features"`
+	WithGeometry bool                 `mapstructure:"with_geometry"` // Include geometry in results (default: false)
+	SQLite       SQLiteConfig         `mapstructure:"sqlite"`
+	Batch        QueryBatchConfig     `mapstructure:"batch"`
+	Route        QueryRouteConfig     `mapstructure:"route"`
+	Area         QueryAreaConfig      `mapstructure:"area"`
+	Aggregate    QueryAggregateConfig `mapstructure:"aggregate"`
+	// PrioritizeWithin: once less than this remains before the query deadline,
+	// layers are queried in order of learned hit-rate. 0 disables.
+	PrioritizeWithin time.Duration `mapstructure:"prioritize_within"`
+	// StrictExtent rejects a query whose point lies outside the extent of every
+	// loaded layer (422 with the union extent) instead of an empty 200.
+	StrictExtent bool `mapstructure:"strict_extent"`
+	// Dedupe returns a feature that several packages hold alike (same layer
+	// name, feature ID and geometry) once, from the first package queried.
+	Dedupe bool `mapstructure:"dedupe"`
+	// Tiering sizes per-source idle connection pools by query popularity.
+	Tiering TieringConfig `mapstructure:"tiering"`
+	// FallbackSRID is assumed for GeoPackage layers declaring the undefined
+	// SRID 0 or -1, which otherwise match no query. 0 leaves them as they are.
+	FallbackSRID int `mapstructure:"fallback_srid"`
+	// SlowThreshold logs and counts every layer query that takes longer.
+	// 0 disables the slow query log.
+	SlowThreshold time.Duration `mapstructure:"slow_threshold"`
+	// Blobs says what happens to BLOB feature properties (other than the
+	// geometry), which would otherwise bloat responses with base64.
+	Blobs BlobConfig `mapstructure:"blobs"`
+	// Precision rounds the coordinates of returned geometries and distances.
+	Precision PrecisionConfig `mapstructure:"precision"`
+	// Memory bounds the memory held by the results of the queries in flight.
+	Memory QueryMemoryConfig `mapstructure:"memory"`
+	// Validity checks and repairs invalid GeoPackage geometries.
+	Validity ValidityConfig `mapstructure:"validity"`
+	// SRIDBounds are plausible coordinate ranges keyed by EPSG code, for
+	// SRIDs without built-in ones or to narrow those: a query point outside
+	// them is rejected with a 400 instead of answered empty.
+	SRIDBounds map[string]SRIDBoundsConfig `mapstructure:"srid_bounds"`
+}
+
+// SRIDBoundsConfig is the plausible extent of an SRID's coordinates, in its
+// units.
+type SRIDBoundsConfig struct {
+	MinX float64 `mapstructure:"min_x"`
+	MinY float64 `mapstructure:"min_y"`
+	MaxX float64 `mapstructure:"max_x"`
+	MaxY float64 `mapstructure:"max_y"`
+}
+
+// Bounds returns the bounds keyed by SRID. Keys that are no SRID are
+// skipped; Validate rejects them.
+func (q QueryConfig) Bounds() domain.SRIDBounds {
+	if len(q.SRIDBounds) == 0 {
+		return nil
+	}
+	out := make(domain.SRIDBounds, len(q.SRIDBounds))
+	for key, b := range q.SRIDBounds {
+		srid, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		out[srid] = domain.Extent{MinX: b.MinX, MinY: b.MinY, MaxX: b.MaxX, MaxY: b.MaxY, SRID: srid}
+	}
+	return out
+}
+
+// ValidityConfig handles invalid geometries (self-intersecting rings and
+// the like), on which ST_Covers silently fails: Check counts them per layer
+// when a package loads and reports them in the source health; MakeValid
+// makes point queries test an ST_MakeValid repair of them instead. Check
+// reads every geometry and slows loading large packages down.
+type ValidityConfig struct {
+	Check     bool `mapstructure:"check"`
+	MakeValid bool `mapstructure:"make_valid"`
+}
+
+// QueryMemoryConfig is a global budget for the estimated size of in-flight
+// query results, so a burst of queries matching many features cannot exhaust
+// the process memory. A query that does not fit waits up to Wait for earlier
+// ones to finish and is then rejected with a 503. BudgetMB 0 disables it.
+type QueryMemoryConfig struct {
+	BudgetMB int           `mapstructure:"budget_mb"`
+	Wait     time.Duration `mapstructure:"wait"`
+}
+
+// PrecisionConfig sets the decimals of returned coordinates: Geographic for
+// layers in degrees, Projected for layers in metres or feet and for
+// distances. Disabled, geometries are returned as stored.
+type PrecisionConfig struct {
+	Enabled    bool `mapstructure:"enabled"`
+	Geographic int  `mapstructure:"geographic"`
+	Projected  int  `mapstructure:"projected"`
+}
+
+// BlobConfig handles BLOB feature properties: "base64" returns them as they
+// are (base64-encoded in JSON), "truncate" cuts them to MaxBytes, "exclude"
+// leaves them out.
+type BlobConfig struct {
+	Mode     string `mapstructure:"mode"`
+	MaxBytes int    `mapstructure:"max_bytes"`
+}
+
+// TieringConfig sizes idle SQLite connection pools by usage: the most-hit
+// sources keep query.sqlite.max_idle_conns idle connections, the rest keep
+// ColdIdleConns.
+type TieringConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Interval      time.Duration `mapstructure:"interval"`        // how often sources are re-tiered
+	Window        time.Duration `mapstructure:"window"`          // popularity window, at most 24h
+	HotSources    int           `mapstructure:"hot_sources"`     // number of most-hit sources kept hot
+	ColdIdleConns int           `mapstructure:"cold_idle_conns"` // idle pool size of every other source
 }
 
 // QueryBatchConfig bounds the POST /api/v1/query/batch endpoint.
@@ -144,6 +650,42 @@ type QueryBatchConfig struct {
 	Concurrency   int `mapstructure:"concurrency"`     // worker pool for the per-point gazetteer enrichment path
 }
 
+// QueryRouteConfig bounds the POST /api/v1/query/route endpoint.
+type QueryRouteConfig struct {
+	MaxPoints int `mapstructure:"max_points"` // cap on route vertices per request; 0 = built-in default
+}
+
+func (r QueryRouteConfig) validate() error {
+	if r.MaxPoints < 0 {
+		return fmt.Errorf("query.route.max_points must be >= 0")
+	}
+	return nil
+}
+
+// QueryAreaConfig bounds the POST /api/v1/query/area endpoint.
+type QueryAreaConfig struct {
+	MaxPoints int `mapstructure:"max_points"` // cap on polygon vertices (all rings) per request; 0 = built-in default
+}
+
+func (a QueryAreaConfig) validate() error {
+	if a.MaxPoints < 0 {
+		return fmt.Errorf("query.area.max_points must be >= 0")
+	}
+	return nil
+}
+
+// QueryAggregateConfig bounds the POST /api/v1/query/aggregate endpoint.
+type QueryAggregateConfig struct {
+	MaxGroups int `mapstructure:"max_groups"` // cap on groups per layer, largest first; 0 = built-in default
+}
+
+func (a QueryAggregateConfig) validate() error {
+	if a.MaxGroups < 0 {
+		return fmt.Errorf("query.aggregate.max_groups must be >= 0")
+	}
+	return nil
+}
+
 // SQLiteConfig tunes how the GeoPackage adapter opens its SQLite databases.
 // Defaults are conservative read-oriented values; calibrate with a load test on
 // the target infra (see docs/how-to/run-a-load-test.md).
@@ -164,16 +706,86 @@ type SQLiteConfig struct {
 	MaxOpenConns int `mapstructure:"max_open_conns"`
 	// MaxIdleConns is the idle connection pool size per source DB.
 	MaxIdleConns int `mapstructure:"max_idle_conns"`
+	// Verify checks each GeoPackage when it is opened: "quick" (default) checks
+	// the application_id, the required gpkg_* tables and runs PRAGMA
+	// quick_check; "full" runs the slower PRAGMA integrity_check instead;
+	// "off" skips verification. A failing package is marked as errored.
+	Verify string `mapstructure:"verify"`
+	// ReadOnly opens GeoPackages strictly read-only (mode=ro, immutable=1)
+	// and never builds a spatial index, for instances sharing a read-only
+	// volume (ReadOnlyMany). Layers without a pre-built R-tree index fail to
+	// prepare. Needs local storage.
+	ReadOnly bool `mapstructure:"read_only"`
+	// Synchronous sets PRAGMA synchronous on each connection ("off", "normal",
+	// "full" or "extra"). Empty keeps SQLite's default.
+	Synchronous string `mapstructure:"synchronous"`
+	// MmapSizeMB sets PRAGMA mmap_size: reads of the first MmapSizeMB MiB of
+	// a file go through memory-mapped I/O instead of read() calls, which
+	// pays off on large read-heavy packages. 0 keeps SQLite's default (off).
+	MmapSizeMB int `mapstructure:"mmap_size_mb"`
+	// CacheSizeMB sets PRAGMA cache_size, the page cache of each connection,
+	// in MiB. 0 keeps SQLite's default (2 MiB).
+	CacheSizeMB int `mapstructure:"cache_size_mb"`
+	// TempStore sets PRAGMA temp_store ("default", "file" or "memory"), where
+	// sorts and temporary tables live. Empty keeps SQLite's default.
+	TempStore string `mapstructure:"temp_store"`
+}
+
+// validate checks the pragmas and the read-only mode against the rest of
+// the configuration.
+func (s SQLiteConfig) validate(storageType string) error {
+	switch strings.ToLower(s.Synchronous) {
+	case "", "off", "normal", "full", "extra":
+	default:
+		return fmt.Errorf("query.sqlite.synchronous must be one of off, normal, full, extra")
+	}
+	switch strings.ToLower(s.TempStore) {
+	case "", "default", "file", "memory":
+	default:
+		return fmt.Errorf("query.sqlite.temp_store must be one of default, file, memory")
+	}
+	if s.MmapSizeMB < 0 || s.CacheSizeMB < 0 {
+		return fmt.Errorf("query.sqlite.mmap_size_mb and cache_size_mb must be >= 0")
+	}
+	if !s.ReadOnly {
+		return nil
+	}
+	if storageType != StorageTypeLocal {
+		return fmt.Errorf("query.sqlite.read_only needs local storage; remote storage downloads into local_path")
+	}
+	if s.JournalMode != "" {
+		return fmt.Errorf("query.sqlite.read_only cannot set query.sqlite.journal_mode")
+	}
+	return nil
 }
 
-// TLSConfig holds TLS/CertMagic configuration.
+// TLSConfig holds TLS configuration: either a certificate and key from files
+// (CertFile/KeyFile, e.g. corporate-issued), or automatic ACME certificates
+// via CertMagic (Domains, Email, Challenge).
 type TLSConfig struct {
-	Enabled  bool      `mapstructure:"enabled"`
-	Domains  []string  `mapstructure:"domains"`
-	Email    string    `mapstructure:"email"`
-	CacheDir string    `mapstructure:"cache_dir"`
-	Staging  bool      `mapstructure:"staging"` // Use Let's Encrypt staging
+	Enabled  bool     `mapstructure:"enabled"`
+	Domains  []string `mapstructure:"domains"`
+	Email    string   `mapstructure:"email"`
+	CacheDir string   `mapstructure:"cache_dir"`
+	Staging  bool     `mapstructure:"staging"` // Use Let's Encrypt staging
+	// Challenge is the ACME challenge type: "dns-01" (default, Azure DNS via
+	// DNS), "http-01" (answered on HTTPPort) or "tls-alpn-01" (answered on
+	// the HTTPS port itself).
+	Challenge string `mapstructure:"challenge"`
+	// HTTPPort is the plain-HTTP listener of the http-01 challenge; it
+	// redirects every other request to HTTPS.
+	HTTPPort int       `mapstructure:"http_port"`
 	DNS      DNSConfig `mapstructure:"dns"`
+	// CertFile and KeyFile are PEM files of the certificate (chain) and its
+	// private key. Set both to serve them instead of using ACME; they are
+	// re-read when they change on disk.
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+}
+
+// StaticCert reports whether TLS serves certificate files instead of ACME.
+func (c *TLSConfig) StaticCert() bool {
+	return c.CertFile != "" || c.KeyFile != ""
 }
 
 // DNSConfig holds DNS-01 challenge provider configuration for Azure DNS.
@@ -188,10 +800,36 @@ type DNSConfig struct {
 // endpoint (always on when Enabled) plus the optional OTLP push export
 // (configured via OTLP).
 type MetricsConfig struct {
-	Enabled bool       `mapstructure:"enabled"`
-	Port    int        `mapstructure:"port"`
-	Path    string     `mapstructure:"path"`
-	OTLP    OTLPConfig `mapstructure:"otlp"`
+	Enabled bool `mapstructure:"enabled"`
+	// Exporter selects how metrics leave the process: "prometheus" serves
+	// them for scraping on Port/Path, "statsd" pushes them to a StatsD
+	// daemon, "otlp" to the OTLP collector only. OTLP.Enabled adds an OTLP
+	// push to the other two.
+	Exporter string       `mapstructure:"exporter"`
+	Port     int          `mapstructure:"port"`
+	Path     string       `mapstructure:"path"`
+	StatsD   StatsDConfig `mapstructure:"statsd"`
+	OTLP     OTLPConfig   `mapstructure:"otlp"`
+	// SourceLabels labels the query metrics with source_id (and layer);
+	// off, they have one series per status however many sources load.
+	SourceLabels bool `mapstructure:"source_labels"`
+}
+
+// Metrics exporters (metrics.exporter).
+const (
+	MetricsExporterPrometheus = "prometheus"
+	MetricsExporterStatsD     = "statsd"
+	MetricsExporterOTLP       = "otlp"
+)
+
+// StatsDConfig configures the StatsD metrics exporter.
+type StatsDConfig struct {
+	Address  string        `mapstructure:"address"`  // host:port of the daemon (UDP)
+	Prefix   string        `mapstructure:"prefix"`   // prepended to every metric name, e.g. "ortus."
+	Interval time.Duration `mapstructure:"interval"` // push interval (default 10s)
+	// Tags appends the metric attributes as DogStatsD tags; plain StatsD
+	// daemons need it off.
+	Tags bool `mapstructure:"tags"`
 }
 
 // OTLPConfig configures OTLP export for a single signal (metrics or others).
@@ -208,14 +846,168 @@ type OTLPConfig struct {
 
 // LoggingConfig holds logging configuration.
 type LoggingConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"` // json, text
+	Level  string          `mapstructure:"level"`
+	Format string          `mapstructure:"format"` // json, text
+	Output string          `mapstructure:"output"` // stdout, file
+	File   LogFileConfig   `mapstructure:"file"`
+	Audit  AuditConfig     `mapstructure:"audit"`
+	Access AccessLogConfig `mapstructure:"access"`
+	// Requests tunes the request log per path; the first matching rule
+	// applies, paths without one are logged at info.
+	Requests []RequestLogRule `mapstructure:"requests"`
+}
+
+// RequestLogRule sets the level and sampling of the request log lines of
+// the paths it matches — e.g. debug, or 1 in 100, for health probes.
+type RequestLogRule struct {
+	// Path is an exact request path, or a path prefix when it ends in "*"
+	// (/health/*).
+	Path string `mapstructure:"path"`
+	// Level is debug, info (default), warn, error, or off to drop the lines.
+	Level string `mapstructure:"level"`
+	// Sample logs 1 in Sample requests; 0 or 1 logs all. Responses with a
+	// 5xx status are always logged.
+	Sample int `mapstructure:"sample"`
+}
+
+// SlogLevel maps Level onto a slog level; anything unrecognised is info.
+func (r RequestLogRule) SlogLevel() slog.Level {
+	return slogLevel(r.Level)
+}
+
+// RequestLogOff is the logging.requests level that drops the lines.
+const RequestLogOff = "off"
+
+// AccessLogConfig configures the access log: one line per HTTP request,
+// written apart from the application log for standard log analyzers.
+type AccessLogConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Output  string `mapstructure:"output"` // "stdout", "stderr" or a file path
+	Format  string `mapstructure:"format"` // common, combined, json
+}
+
+// LogOutputFile is the logging.output that writes the log to logging.file
+// instead of stdout.
+const LogOutputFile = "file"
+
+// LogFileConfig configures the rotating log file of logging.output file.
+type LogFileConfig struct {
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"` // rotate once the file reaches this size
+	MaxBackups int    `mapstructure:"max_backups"` // rotated files kept; 0 = all
+	Compress   bool   `mapstructure:"compress"`    // gzip rotated files
+}
+
+// AuditConfig configures the audit log of admin, sync and load actions.
+type AuditConfig struct {
+	File   string `mapstructure:"file"`   // JSON lines file; "" = the main log
+	Retain int    `mapstructure:"retain"` // entries kept for GET /admin/audit; 0 = 1000
+}
+
+// SlogLevel maps Level onto a slog level; anything unrecognised is info.
+func (c LoggingConfig) SlogLevel() slog.Level {
+	return slogLevel(c.Level)
+}
+
+func slogLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
 }
 
 // SyncConfig holds remote storage sync configuration.
 type SyncConfig struct {
 	Enabled  bool          `mapstructure:"enabled"`
 	Interval time.Duration `mapstructure:"interval"` // e.g., "1h", "24h", "30m"
+	// Schedule is a five-field cron expression ("0 3 * * *") that replaces
+	// Interval; "" syncs every Interval.
+	Schedule string `mapstructure:"schedule"`
+	// Blackout lists windows ("Mon-Fri 08:00-18:00") in which no scheduled
+	// sync starts; one falling into a window runs at its end. Schedule and
+	// windows are in the server's local time zone (TZ).
+	Blackout []string `mapstructure:"blackout"`
+	// APICooldown is the time POST /api/v1/sync waits between two syncs;
+	// calls within it get a 429. 0 lifts the limit.
+	APICooldown    time.Duration        `mapstructure:"api_cooldown"`
+	Events         SyncEventsConfig     `mapstructure:"events"`
+	LeaderElection LeaderElectionConfig `mapstructure:"leader_election"`
+}
+
+// Timing parses Schedule (nil when empty) and the Blackout windows.
+func (s SyncConfig) Timing() (*domain.Schedule, []domain.TimeWindow, error) {
+	var schedule *domain.Schedule
+	if s.Schedule != "" {
+		var err error
+		if schedule, err = domain.ParseSchedule(s.Schedule); err != nil {
+			return nil, nil, fmt.Errorf("sync.schedule: %w", err)
+		}
+	}
+	windows := make([]domain.TimeWindow, 0, len(s.Blackout))
+	for _, b := range s.Blackout {
+		w, err := domain.ParseTimeWindow(b)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sync.blackout: %w", err)
+		}
+		windows = append(windows, w)
+	}
+	return schedule, windows, nil
+}
+
+// LeaderElectionConfig elects one of several replicas that share
+// storage.local_path (a shared volume) to download and index sources; the
+// others load the files it has published there. The lease is a file in
+// local_path.
+type LeaderElectionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// LeaseDuration is how long a lease lasts without renewal: the time a
+	// crashed leader blocks the others at most. Renewed every third of it.
+	LeaseDuration time.Duration `mapstructure:"lease_duration"`
+	// Identity names this replica in the lease; "" = the hostname (the pod
+	// name on Kubernetes).
+	Identity string `mapstructure:"identity"`
+}
+
+// SyncEventsConfig enables POST /api/v1/sync/events, the Azure Event Grid
+// webhook that syncs single blobs as they are created or deleted, so the
+// periodic full listing can run rarely.
+type SyncEventsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Token is populated from ORTUS_SYNC_EVENTS_TOKEN at Load() time; Event
+	// Grid passes it in the webhook URL (?token=…). Required when enabled.
+	Token string `mapstructure:"-"`
+}
+
+// Watcher modes.
+const (
+	WatcherModeFSNotify = "fsnotify"
+	WatcherModePoll     = "poll"
+)
+
+// defaultWatcherPollInterval applies when watcher.poll_interval is unset.
+const defaultWatcherPollInterval = 10 * time.Second
+
+// WatcherConfig selects how the local storage directories are watched for
+// hot reload. fsnotify (default) gets kernel events; poll rescans the
+// directories, for NFS/CIFS mounts where changes made by other hosts raise no
+// inotify events.
+type WatcherConfig struct {
+	Mode         string        `mapstructure:"mode"`          // fsnotify, poll
+	PollInterval time.Duration `mapstructure:"poll_interval"` // poll: time between scans; 0 = 10s
+}
+
+// Interval returns the poll interval, defaulted.
+func (w WatcherConfig) Interval() time.Duration {
+	if w.PollInterval <= 0 {
+		return defaultWatcherPollInterval
+	}
+	return w.PollInterval
 }
 
 // MCPConfig configures the in-process Model Context Protocol server. When
@@ -344,6 +1136,17 @@ const (
 	TracingTransportGRPC = "grpc"
 )
 
+// FeaturesConfig holds the runtime feature flags that gate subsystems still
+// being rolled out (see domain.KnownFeatureFlags). Every flag defaults to on.
+// Flags sets per-deployment values; File optionally names a YAML file of
+// `flag: bool` overrides that is re-read every RefreshInterval, so a subsystem
+// can be switched off without a redeploy.
+type FeaturesConfig struct {
+	Flags           map[string]bool `mapstructure:"flags"`
+	File            string          `mapstructure:"file"`
+	RefreshInterval time.Duration   `mapstructure:"refresh_interval"` // 0 = read File only at startup
+}
+
 // TracingConfig holds OpenTelemetry tracing configuration.
 type TracingConfig struct {
 	Enabled     bool              `mapstructure:"enabled"`
@@ -363,6 +1166,7 @@ func Defaults() {
 	// Server defaults
 	viper.SetDefault("server.host", "0.0.0.0")
 	viper.SetDefault("server.port", 8080)
+	viper.SetDefault("server.listen", "")
 	viper.SetDefault("server.read_timeout", 30*time.Second)
 	viper.SetDefault("server.write_timeout", 30*time.Second)
 	viper.SetDefault("server.shutdown_timeout", 10*time.Second)
@@ -370,39 +1174,115 @@ func Defaults() {
 	viper.SetDefault("server.rate_limit.rate", 100.0)
 	viper.SetDefault("server.rate_limit.burst", 200)
 	viper.SetDefault("server.rate_limit.trusted_proxies", []string{})
+	viper.SetDefault("server.trusted_proxies", []string{})
+	viper.SetDefault("server.proxy_protocol", false)
 	viper.SetDefault("server.cors.allowed_origins", []string{})
 	viper.SetDefault("server.frontend_enabled", true)
+	viper.SetDefault("server.static_dir", "")
 	viper.SetDefault("server.ready_when_empty", true)
+	viper.SetDefault("server.startup_timeout", 0)
+	viper.SetDefault("server.admin.enabled", false)
+	viper.SetDefault("server.admin.pprof", false)
+	viper.SetDefault("server.error_format", ErrorFormatProblem)
+	viper.SetDefault("server.max_body_mb", 32)
+	viper.SetDefault("server.max_query_length", 8192)
+	viper.SetDefault("frontend.branding.logo_url", "")
+	viper.SetDefault("frontend.branding.primary_color", "")
+	viper.SetDefault("frontend.branding.footer_text", "")
 
 	// Storage defaults
 	viper.SetDefault("storage.type", StorageTypeLocal)
 	viper.SetDefault("storage.local_path", "./data")
+	viper.SetDefault("storage.local.recursive", true)
+	viper.SetDefault("storage.local.exclude", []string{})
+	viper.SetDefault("watcher.mode", WatcherModeFSNotify)
+	viper.SetDefault("watcher.poll_interval", defaultWatcherPollInterval)
+	viper.SetDefault("storage.s3.credentials", S3CredentialsAuto)
+	viper.SetDefault("storage.s3.assume_role.session_name", "ortus")
 	viper.SetDefault("storage.http.index_file", "index.txt")
 	viper.SetDefault("storage.http.timeout", 5*time.Minute)
+	viper.SetDefault("storage.http.autoindex", false)
+	viper.SetDefault("storage.catalog.url", "")
+	viper.SetDefault("storage.catalog.protocol", "dcat")
+	viper.SetDefault("storage.catalog.keywords", []string{})
+	viper.SetDefault("storage.catalog.title_pattern", "")
+	viper.SetDefault("storage.catalog.max_records", 100)
+	viper.SetDefault("storage.catalog.timeout", 5*time.Minute)
+	viper.SetDefault("storage.source_id.strategy", string(domain.SourceIDFilename))
+	viper.SetDefault("storage.cache.max_size_mb", 0)
+	viper.SetDefault("storage.faults.enabled", false)
+	viper.SetDefault("storage.faults.error_rate", 0.0)
+	viper.SetDefault("storage.faults.latency", 0)
+	viper.SetDefault("storage.faults.jitter", 0)
+	viper.SetDefault("storage.faults.operations", []string{})
+	viper.SetDefault("storage.circuit_breaker.enabled", true)
+	viper.SetDefault("storage.circuit_breaker.failure_threshold", 5)
+	viper.SetDefault("storage.circuit_breaker.open_timeout", "30s")
+	viper.SetDefault("storage.health_check.enabled", true)
+	viper.SetDefault("storage.health_check.interval", "1m")
+	viper.SetDefault("storage.health_check.timeout", "10s")
+	viper.SetDefault("storage.health_check.key", "")
 
 	// Query defaults
 	viper.SetDefault("query.timeout", 30*time.Second)
 	viper.SetDefault("query.max_features", 1000)
 	viper.SetDefault("query.with_geometry", false)
+	viper.SetDefault("query.prioritize_within", 0)
+	viper.SetDefault("query.strict_extent", false)
+	viper.SetDefault("query.dedupe", false)
+	viper.SetDefault("query.tiering.enabled", false)
+	viper.SetDefault("query.tiering.interval", 5*time.Minute)
+	viper.SetDefault("query.tiering.window", time.Hour)
+	viper.SetDefault("query.tiering.hot_sources", 10)
+	viper.SetDefault("query.tiering.cold_idle_conns", 1)
 	viper.SetDefault("query.sqlite.cache_mode", "private")
 	viper.SetDefault("query.sqlite.busy_timeout_ms", 5000)
 	viper.SetDefault("query.sqlite.journal_mode", "")
 	viper.SetDefault("query.sqlite.max_open_conns", 0)
 	viper.SetDefault("query.sqlite.max_idle_conns", 4)
+	viper.SetDefault("query.sqlite.verify", "quick")
+	viper.SetDefault("query.sqlite.read_only", false)
+	viper.SetDefault("query.sqlite.synchronous", "")
+	viper.SetDefault("query.sqlite.mmap_size_mb", 0)
+	viper.SetDefault("query.sqlite.cache_size_mb", 0)
+	viper.SetDefault("query.sqlite.temp_store", "")
+	viper.SetDefault("query.fallback_srid", 0)
+	viper.SetDefault("query.slow_threshold", 0)
+	viper.SetDefault("query.blobs.mode", "base64")
+	viper.SetDefault("query.blobs.max_bytes", 1024)
+	viper.SetDefault("query.precision.enabled", false)
+	viper.SetDefault("query.precision.geographic", 6)
+	viper.SetDefault("query.precision.projected", 2)
+	viper.SetDefault("query.memory.budget_mb", 0)
+	viper.SetDefault("query.memory.wait", "2s")
+	viper.SetDefault("query.validity.check", false)
+	viper.SetDefault("query.validity.make_valid", false)
 	viper.SetDefault("query.batch.max_points", 10000)
 	viper.SetDefault("query.batch.max_sync_points", 1000)
 	viper.SetDefault("query.batch.concurrency", 4)
+	viper.SetDefault("query.route.max_points", 10000)
+	viper.SetDefault("query.area.max_points", 10000)
+	viper.SetDefault("query.aggregate.max_groups", 1000)
 
 	// TLS defaults
 	viper.SetDefault("tls.enabled", false)
 	viper.SetDefault("tls.cache_dir", "./.certmagic")
 	viper.SetDefault("tls.staging", false)
+	viper.SetDefault("tls.cert_file", "")
+	viper.SetDefault("tls.key_file", "")
+	viper.SetDefault("tls.challenge", tlsChallengeDNS01)
+	viper.SetDefault("tls.http_port", 80)
 	viper.SetDefault("tls.dns.provider", dnsProviderAzure)
 
 	// Metrics defaults
 	viper.SetDefault("metrics.enabled", true)
 	viper.SetDefault("metrics.port", 9090)
+	viper.SetDefault("metrics.exporter", MetricsExporterPrometheus)
 	viper.SetDefault("metrics.path", "/metrics")
+	viper.SetDefault("metrics.statsd.address", "127.0.0.1:8125")
+	viper.SetDefault("metrics.statsd.interval", 10*time.Second)
+	viper.SetDefault("metrics.statsd.tags", true)
+	viper.SetDefault("metrics.source_labels", true)
 	viper.SetDefault("metrics.otlp.enabled", false)
 	viper.SetDefault("metrics.otlp.transport", TracingTransportHTTP)
 	viper.SetDefault("metrics.otlp.insecure", true)
@@ -411,10 +1291,52 @@ func Defaults() {
 	// Logging defaults
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "json")
+	viper.SetDefault("logging.output", "stdout")
+	viper.SetDefault("logging.file.path", "")
+	viper.SetDefault("logging.file.max_size_mb", 100)
+	viper.SetDefault("logging.file.max_backups", 5)
+	viper.SetDefault("logging.file.compress", false)
+	viper.SetDefault("logging.audit.file", "")
+	viper.SetDefault("logging.audit.retain", 1000)
+	viper.SetDefault("logging.access.enabled", false)
+	viper.SetDefault("logging.access.output", "stdout")
+	viper.SetDefault("logging.access.format", "combined")
 
 	// Sync defaults
 	viper.SetDefault("sync.enabled", false)
 	viper.SetDefault("sync.interval", time.Hour)
+	viper.SetDefault("sync.schedule", "")
+	viper.SetDefault("sync.blackout", []string{})
+	viper.SetDefault("sync.api_cooldown", 30*time.Second)
+	viper.SetDefault("sync.events.enabled", false)
+	viper.SetDefault("sync.leader_election.enabled", false)
+	viper.SetDefault("sync.leader_election.lease_duration", 15*time.Second)
+	viper.SetDefault("sync.leader_election.identity", "")
+
+	// Feature flags: all on. Registering each one also makes it overridable
+	// from the environment (ORTUS_FEATURES_FLAGS_<NAME>).
+	for _, f := range domain.KnownFeatureFlags() {
+		viper.SetDefault("features.flags."+string(f), true)
+	}
+	viper.SetDefault("features.file", "")
+	viper.SetDefault("features.refresh_interval", 30*time.Second)
+
+	// Federation defaults
+	viper.SetDefault("federation.enabled", false)
+	viper.SetDefault("federation.timeout", 2*time.Second)
+	viper.SetDefault("federation.refresh_interval", time.Minute)
+
+	// Geocoder defaults
+	viper.SetDefault("geocoder.provider", "")
+	viper.SetDefault("geocoder.url", "")
+	viper.SetDefault("geocoder.user_agent", "")
+	viper.SetDefault("geocoder.email", "")
+	viper.SetDefault("geocoder.timeout", 5*time.Second)
+	viper.SetDefault("geocoder.min_interval", time.Second)
+	viper.SetDefault("geocoder.cache_ttl", time.Hour)
+	viper.SetDefault("geocoder.license", "")
+	viper.SetDefault("geocoder.license_url", "")
+	viper.SetDefault("geocoder.attribution", "")
 
 	// MCP defaults
 	viper.SetDefault("mcp.enabled", false)
@@ -515,6 +1437,8 @@ func Load(configPath string) (*Config, error) {
 	// directly so they don't get printed by `viper.Debug()` / leaked into
 	// a marshaled config dump.
 	cfg.MCP.Token = os.Getenv("ORTUS_MCP_TOKEN")
+	cfg.Server.Admin.Token = os.Getenv("ORTUS_ADMIN_TOKEN")
+	cfg.Sync.Events.Token = os.Getenv("ORTUS_SYNC_EVENTS_TOKEN")
 
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("validating config: %w", err)
@@ -537,18 +1461,377 @@ func (c *Config) Validate() error {
 	if err := c.validateTracing(); err != nil {
 		return err
 	}
+	if err := c.validateMetricsExporter(); err != nil {
+		return err
+	}
 	if err := c.validateMetricsOTLP(); err != nil {
 		return err
 	}
 	if err := c.validateMCP(); err != nil {
 		return err
 	}
+	if err := c.validateQuery(); err != nil {
+		return err
+	}
 	if err := c.validateQueryBatch(); err != nil {
 		return err
 	}
+	if err := c.validateFeatures(); err != nil {
+		return err
+	}
+	if err := c.validateLogging(); err != nil {
+		return err
+	}
+	if err := c.validateWatcher(); err != nil {
+		return err
+	}
+	if err := c.validateFrontendBranding(); err != nil {
+		return err
+	}
+	if err := c.validateSync(); err != nil {
+		return err
+	}
+	if err := c.validatePackages(); err != nil {
+		return err
+	}
+	if err := c.validateFederation(); err != nil {
+		return err
+	}
+	if err := c.validateGeocoder(); err != nil {
+		return err
+	}
 	return c.validateGazetteer()
 }
 
+func (c *Config) validateSync() error {
+	if _, _, err := c.Sync.Timing(); err != nil {
+		return err
+	}
+	if c.Sync.Enabled && c.Sync.Schedule == "" && c.Sync.Interval <= 0 {
+		return fmt.Errorf("sync.interval must be > 0 without sync.schedule")
+	}
+	if c.Sync.APICooldown < 0 {
+		return fmt.Errorf("sync.api_cooldown must be >= 0")
+	}
+	if err := c.validateSyncEvents(); err != nil {
+		return err
+	}
+	return c.validateLeaderElection()
+}
+
+func (c *Config) validateLeaderElection() error {
+	le := c.Sync.LeaderElection
+	if !le.Enabled {
+		return nil
+	}
+	if c.Storage.Type == StorageTypeLocal {
+		return fmt.Errorf("sync.leader_election needs remote storage; local storage downloads nothing")
+	}
+	if c.Sync.Events.Enabled {
+		// An event replaces a file in place, under the followers that have
+		// it open.
+		return fmt.Errorf("sync.leader_election cannot be combined with sync.events")
+	}
+	if le.LeaseDuration < 3*time.Second {
+		return fmt.Errorf("sync.leader_election.lease_duration must be at least 3s")
+	}
+	return nil
+}
+
+func (c *Config) validateSyncEvents() error {
+	e := c.Sync.Events
+	if !e.Enabled {
+		return nil
+	}
+	if c.Storage.Type != StorageTypeAzure {
+		return fmt.Errorf("sync.events needs storage type azure")
+	}
+	if !c.Sync.Enabled {
+		return fmt.Errorf("sync.events needs sync.enabled")
+	}
+	if e.Token == "" {
+		// The webhook lives on the public listener; never unauthenticated.
+		return fmt.Errorf("sync.events.enabled is true — ORTUS_SYNC_EVENTS_TOKEN must be set")
+	}
+	return nil
+}
+
+func (c *Config) validateWatcher() error {
+	switch c.Watcher.Mode {
+	case "", WatcherModeFSNotify, WatcherModePoll:
+	default:
+		return fmt.Errorf("watcher.mode must be %s or %s, got %q", WatcherModeFSNotify, WatcherModePoll, c.Watcher.Mode)
+	}
+	if c.Watcher.PollInterval < 0 {
+		return fmt.Errorf("watcher.poll_interval must be >= 0")
+	}
+	return nil
+}
+
+// hexColorPattern matches the CSS hex colors #rgb and #rrggbb.
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validateFrontendBranding checks the branding values that end up in the
+// page's CSS and markup, so a typo fails at startup instead of breaking the
+// page.
+func (c *Config) validateFrontendBranding() error {
+	b := c.Frontend.Branding
+	if b.PrimaryColor != "" && !hexColorPattern.MatchString(b.PrimaryColor) {
+		return fmt.Errorf("frontend.branding.primary_color must be a hex color like #2563eb, got %q", b.PrimaryColor)
+	}
+	if b.LogoURL == "" || strings.HasPrefix(b.LogoURL, "/") && !strings.HasPrefix(b.LogoURL, "//") {
+		return nil
+	}
+	u, err := url.Parse(b.LogoURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("frontend.branding.logo_url must be an http(s) URL or an absolute path, got %q", b.LogoURL)
+	}
+	return nil
+}
+
+// validateFeatures rejects unknown flag names, so a misspelled kill-switch
+// fails at startup instead of silently leaving the subsystem on.
+func (c *Config) validateFeatures() error {
+	for name := range c.Features.Flags {
+		if _, err := domain.ParseFeatureFlag(name); err != nil {
+			return fmt.Errorf("features.flags: %w", err)
+		}
+	}
+	if c.Features.RefreshInterval < 0 {
+		return fmt.Errorf("features.refresh_interval must be >= 0")
+	}
+	return nil
+}
+
+// validatePackages rejects two entries that target the same source id — one
+// would silently win — malformed layer patterns, unknown load modes and pins
+// set both ways.
+func (c *Config) validatePackages() error {
+	seen := make(map[string]string, len(c.Packages))
+	for key, p := range c.Packages {
+		id := strings.ToLower(p.SourceID(key))
+		if other, dup := seen[id]; dup {
+			return fmt.Errorf("packages.%s and packages.%s both configure source %q", other, key, p.SourceID(key))
+		}
+		seen[id] = key
+		for _, pattern := range append(append([]string(nil), p.Layers.Include...), p.Layers.Exclude...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("packages.%s.layers: invalid pattern %q", key, pattern)
+			}
+		}
+		if err := validateLayerConfigs(key, p.Layers.Layer); err != nil {
+			return err
+		}
+		switch strings.ToLower(p.LoadMode) {
+		case "", "mmap", "memory":
+		default:
+			return fmt.Errorf("packages.%s.load_mode must be mmap or memory", key)
+		}
+		if p.Pin.ETag != "" && p.Pin.VersionID != "" {
+			return fmt.Errorf("packages.%s.pin: set etag or version_id, not both", key)
+		}
+	}
+	return nil
+}
+
+func validateLayerConfigs(pkg string, layers map[string]LayerConfig) error {
+	for name, l := range layers {
+		for _, prop := range append(append([]string(nil), l.Properties...), l.Redact...) {
+			if strings.TrimSpace(prop) == "" {
+				return fmt.Errorf("packages.%s.layers.%s: empty property name", pkg, name)
+			}
+		}
+		if err := validatePropertyMapping(l.Mapping); err != nil {
+			return fmt.Errorf("packages.%s.layers.%s.mapping: %w", pkg, name, err)
+		}
+		if err := validateComputed(l.Computed); err != nil {
+			return fmt.Errorf("packages.%s.layers.%s.computed: %w", pkg, name, err)
+		}
+	}
+	return nil
+}
+
+// validateComputed checks what can be checked without the package: the
+// expressions themselves are only compiled by the first query of the layer.
+func validateComputed(computed []ComputedPropertyConfig) error {
+	seen := make(map[string]bool, len(computed))
+	for _, c := range computed {
+		switch {
+		case strings.TrimSpace(c.Name) == "":
+			return fmt.Errorf("empty name")
+		case strings.TrimSpace(c.Expr) == "":
+			return fmt.Errorf("%s: empty expr", c.Name)
+		case strings.Contains(c.Expr, ";"):
+			return fmt.Errorf("%s: expr must be a single expression", c.Name)
+		case seen[strings.ToLower(c.Name)]:
+			return fmt.Errorf("%s: duplicate name", c.Name)
+		}
+		seen[strings.ToLower(c.Name)] = true
+	}
+	return nil
+}
+
+// propertyTypes are the types a property mapping converts to.
+var propertyTypes = []string{
+	domain.PropertyTypeString, domain.PropertyTypeInteger, domain.PropertyTypeNumber,
+	domain.PropertyTypeBoolean, domain.PropertyTypeDate,
+}
+
+func validatePropertyMapping(mapping map[string]PropertyMappingConfig) error {
+	names := make(map[string]string, len(mapping))
+	for _, col := range slices.Sorted(maps.Keys(mapping)) {
+		m := mapping[col]
+		if m.Type != "" && !slices.Contains(propertyTypes, m.Type) {
+			return fmt.Errorf("%s: invalid type %q (must be one of %s)", col, m.Type, strings.Join(propertyTypes, ", "))
+		}
+		if m.Format != "" && m.Type != domain.PropertyTypeDate {
+			return fmt.Errorf("%s: format requires type date", col)
+		}
+		name := col
+		if m.Name != "" {
+			name = strings.ToLower(m.Name)
+		}
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("%s and %s are both mapped to %q", other, col, name)
+		}
+		names[name] = col
+	}
+	return nil
+}
+
+// validateFederation checks the peers only when federation is on, so a
+// prepared but disabled peer list does not block startup.
+func (c *Config) validateFederation() error {
+	f := c.Federation
+	if !f.Enabled {
+		return nil
+	}
+	if len(f.Peers) == 0 {
+		return fmt.Errorf("federation.peers must list at least one peer when federation is enabled")
+	}
+	if f.Timeout <= 0 {
+		return fmt.Errorf("federation.timeout must be > 0")
+	}
+	if f.RefreshInterval <= 0 {
+		return fmt.Errorf("federation.refresh_interval must be > 0")
+	}
+	for name, p := range f.Peers {
+		u, err := url.Parse(p.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("federation.peers.%s.url must be an absolute http(s) URL", name)
+		}
+		if p.Timeout < 0 {
+			return fmt.Errorf("federation.peers.%s.timeout must be >= 0", name)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateGeocoder() error {
+	g := c.Geocoder
+	switch g.Provider {
+	case "":
+		return nil
+	case GeocoderProviderNominatim:
+	case GeocoderProviderCustom:
+		if g.URL == "" {
+			return fmt.Errorf("geocoder.url is required for geocoder.provider %q", GeocoderProviderCustom)
+		}
+	default:
+		return fmt.Errorf("invalid geocoder.provider %q (expected %q or %q)",
+			g.Provider, GeocoderProviderNominatim, GeocoderProviderCustom)
+	}
+	if g.URL != "" {
+		u, err := url.Parse(g.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("geocoder.url must be an absolute http(s) URL")
+		}
+	}
+	if g.Timeout <= 0 {
+		return fmt.Errorf("geocoder.timeout must be > 0")
+	}
+	if g.MinInterval < 0 {
+		return fmt.Errorf("geocoder.min_interval must be >= 0")
+	}
+	if g.CacheTTL < 0 {
+		return fmt.Errorf("geocoder.cache_ttl must be >= 0")
+	}
+	return nil
+}
+
+func (c *Config) validateQuery() error {
+	if c.Query.PrioritizeWithin < 0 {
+		return fmt.Errorf("query.prioritize_within must be >= 0")
+	}
+	if c.Query.FallbackSRID < 0 {
+		return fmt.Errorf("query.fallback_srid must be >= 0")
+	}
+	if c.Query.SlowThreshold < 0 {
+		return fmt.Errorf("query.slow_threshold must be >= 0")
+	}
+	for key, b := range c.Query.SRIDBounds {
+		if srid, err := strconv.Atoi(key); err != nil || srid <= 0 {
+			return fmt.Errorf("query.srid_bounds key %q must be an EPSG code", key)
+		}
+		if b.MinX >= b.MaxX || b.MinY >= b.MaxY {
+			return fmt.Errorf("query.srid_bounds.%s: min_x and min_y must be below max_x and max_y", key)
+		}
+	}
+	sqlite := func() error { return c.Query.SQLite.validate(c.Storage.Type) }
+	for _, validate := range []func() error{c.Query.Blobs.validate, c.Query.Precision.validate, c.Query.Memory.validate, c.Query.Route.validate, c.Query.Area.validate, c.Query.Aggregate.validate, sqlite} {
+		if err := validate(); err != nil {
+			return err
+		}
+	}
+	switch c.Query.SQLite.Verify {
+	case "", "off", "quick", "full":
+	default:
+		return fmt.Errorf("query.sqlite.verify must be one of off, quick, full")
+	}
+	if t := c.Query.Tiering; t.Enabled {
+		if t.Interval <= 0 {
+			return fmt.Errorf("query.tiering.interval must be > 0")
+		}
+		if t.Window <= 0 || t.Window > 24*time.Hour {
+			return fmt.Errorf("query.tiering.window must be > 0 and at most 24h")
+		}
+		if t.HotSources < 0 || t.ColdIdleConns < 0 {
+			return fmt.Errorf("query.tiering.hot_sources and cold_idle_conns must be >= 0")
+		}
+	}
+	return nil
+}
+
+func (b BlobConfig) validate() error {
+	switch b.Mode {
+	case "", "base64", "exclude":
+	case "truncate":
+		if b.MaxBytes <= 0 {
+			return fmt.Errorf("query.blobs.max_bytes must be > 0 with query.blobs.mode truncate")
+		}
+	default:
+		return fmt.Errorf("invalid query.blobs.mode %q (expected base64, truncate or exclude)", b.Mode)
+	}
+	return nil
+}
+
+func (p PrecisionConfig) validate() error {
+	if !p.Enabled {
+		return nil
+	}
+	if p.Geographic < 0 || p.Geographic > 15 || p.Projected < 0 || p.Projected > 15 {
+		return fmt.Errorf("query.precision.geographic and projected must be between 0 and 15")
+	}
+	return nil
+}
+
+func (m QueryMemoryConfig) validate() error {
+	if m.BudgetMB < 0 || m.Wait < 0 {
+		return fmt.Errorf("query.memory.budget_mb and wait must be >= 0")
+	}
+	return nil
+}
+
 // validateQueryBatch keeps the batch caps sane. A zero value means "unset" —
 // viper Load always supplies positive defaults, and the HTTP handler falls back to
 // built-in defaults — so validation only rejects negatives and the one relationship
@@ -622,12 +1905,30 @@ func (c *Config) MetricsOTLPEndpoint() string {
 	return c.Tracing.Endpoint
 }
 
+func (c *Config) validateMetricsExporter() error {
+	switch c.Metrics.Exporter {
+	case "", MetricsExporterPrometheus, MetricsExporterOTLP:
+		return nil
+	case MetricsExporterStatsD:
+	default:
+		return fmt.Errorf("invalid metrics.exporter %q (expected %q, %q or %q)",
+			c.Metrics.Exporter, MetricsExporterPrometheus, MetricsExporterStatsD, MetricsExporterOTLP)
+	}
+	if _, _, err := net.SplitHostPort(c.Metrics.StatsD.Address); err != nil {
+		return fmt.Errorf("invalid metrics.statsd.address %q: %w", c.Metrics.StatsD.Address, err)
+	}
+	if c.Metrics.StatsD.Interval < 0 {
+		return fmt.Errorf("metrics.statsd.interval must be >= 0, got %s", c.Metrics.StatsD.Interval)
+	}
+	return nil
+}
+
 func (c *Config) validateMetricsOTLP() error {
-	if !c.Metrics.OTLP.Enabled {
+	if !c.Metrics.OTLP.Enabled && c.Metrics.Exporter != MetricsExporterOTLP {
 		return nil
 	}
 	if c.MetricsOTLPEndpoint() == "" {
-		return fmt.Errorf("metrics.otlp.enabled is true but no endpoint is configured (set metrics.otlp.endpoint or tracing.endpoint)")
+		return fmt.Errorf("metrics OTLP export is on but no endpoint is configured (set metrics.otlp.endpoint or tracing.endpoint)")
 	}
 	switch c.Metrics.OTLP.Transport {
 	case "", TracingTransportHTTP, TracingTransportGRPC:
@@ -664,6 +1965,94 @@ func (c *Config) validateServer() error {
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
 	}
+	if l := c.Server.Listen; l != "" && l != ListenSystemd && c.Server.SocketPath() == "" {
+		return fmt.Errorf("invalid server.listen %q (expected %sPATH or %s)", l, ListenUnixPrefix, ListenSystemd)
+	}
+	switch c.Server.ErrorFormat {
+	case "", ErrorFormatProblem, ErrorFormatLegacy:
+		// ok
+	default:
+		return fmt.Errorf("invalid server.error_format %q (expected %q or %q)", c.Server.ErrorFormat, ErrorFormatProblem, ErrorFormatLegacy)
+	}
+	for _, cidr := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(cidr)); err != nil {
+			return fmt.Errorf("invalid server.trusted_proxies entry %q: want a CIDR such as 10.0.0.0/8", cidr)
+		}
+	}
+	if c.Server.StartupTimeout < 0 {
+		return fmt.Errorf("server.startup_timeout must be >= 0")
+	}
+	if c.Server.MaxBodyMB < 0 {
+		return fmt.Errorf("server.max_body_mb must be >= 0")
+	}
+	if c.Server.MaxQueryLength < 0 {
+		return fmt.Errorf("server.max_query_length must be >= 0")
+	}
+	if c.Server.Admin.Enabled && c.Server.Admin.Token == "" {
+		// The admin endpoints live on the public listener; never unauthenticated.
+		return fmt.Errorf("server.admin.enabled is true — ORTUS_ADMIN_TOKEN must be set")
+	}
+	if c.Server.StaticDir != "" {
+		if fi, err := os.Stat(filepath.Join(c.Server.StaticDir, "index.html")); err != nil || fi.IsDir() {
+			return fmt.Errorf("server.static_dir %q must be a directory containing index.html", c.Server.StaticDir)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateLogging() error {
+	if c.Logging.Audit.Retain < 0 {
+		return fmt.Errorf("logging.audit.retain must be >= 0")
+	}
+	if err := c.validateLogOutput(); err != nil {
+		return err
+	}
+	for _, rule := range c.Logging.Requests {
+		if !strings.HasPrefix(rule.Path, "/") {
+			return fmt.Errorf("logging.requests: path %q must start with /", rule.Path)
+		}
+		switch rule.Level {
+		case "", "debug", "info", "warn", "error", RequestLogOff:
+		default:
+			return fmt.Errorf("logging.requests: invalid level %q for %s (expected debug, info, warn, error or off)", rule.Level, rule.Path)
+		}
+		if rule.Sample < 0 {
+			return fmt.Errorf("logging.requests: sample for %s must be >= 0", rule.Path)
+		}
+	}
+	access := c.Logging.Access
+	if !access.Enabled {
+		return nil
+	}
+	switch access.Format {
+	case "common", "combined", "json":
+	default:
+		return fmt.Errorf("invalid logging.access.format %q (expected common, combined or json)", access.Format)
+	}
+	if access.Output == "" {
+		return fmt.Errorf("logging.access.output must be stdout, stderr or a file path")
+	}
+	return nil
+}
+
+func (c *Config) validateLogOutput() error {
+	switch c.Logging.Output {
+	case "", "stdout":
+		return nil
+	case LogOutputFile:
+	default:
+		return fmt.Errorf("invalid logging.output %q (expected stdout or file)", c.Logging.Output)
+	}
+	file := c.Logging.File
+	if file.Path == "" {
+		return fmt.Errorf("logging.output is file — logging.file.path must be set")
+	}
+	if file.MaxSizeMB < 1 {
+		return fmt.Errorf("logging.file.max_size_mb must be >= 1")
+	}
+	if file.MaxBackups < 0 {
+		return fmt.Errorf("logging.file.max_backups must be >= 0")
+	}
 	return nil
 }
 
@@ -671,13 +2060,42 @@ func (c *Config) validateTLS() error {
 	if !c.TLS.Enabled {
 		return nil
 	}
+	if c.TLS.StaticCert() {
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			return fmt.Errorf("tls.cert_file and tls.key_file must be set together")
+		}
+		if len(c.TLS.Domains) > 0 {
+			return fmt.Errorf("tls.domains requests ACME certificates and cannot be combined with tls.cert_file")
+		}
+		return nil
+	}
 	if len(c.TLS.Domains) == 0 {
 		return fmt.Errorf("TLS enabled but no domains specified")
 	}
 	if c.TLS.Email == "" {
 		return fmt.Errorf("TLS enabled but no email specified")
 	}
-	// Validate DNS-01 challenge configuration
+	switch c.TLS.Challenge {
+	case "", tlsChallengeDNS01:
+		return c.validateTLSDNS()
+	case tlsChallengeHTTP01:
+		if c.TLS.HTTPPort < 1 || c.TLS.HTTPPort > 65535 {
+			return fmt.Errorf("invalid tls.http_port: %d", c.TLS.HTTPPort)
+		}
+		if c.TLS.HTTPPort == c.Server.Port {
+			return fmt.Errorf("tls.http_port %d collides with server.port", c.TLS.HTTPPort)
+		}
+		return nil
+	case tlsChallengeTLSALPN01:
+		return nil
+	default:
+		return fmt.Errorf("invalid tls.challenge: %q (want %s, %s or %s)",
+			c.TLS.Challenge, tlsChallengeDNS01, tlsChallengeHTTP01, tlsChallengeTLSALPN01)
+	}
+}
+
+// validateTLSDNS checks the DNS-01 challenge provider.
+func (c *Config) validateTLSDNS() error {
 	if c.TLS.DNS.Provider != dnsProviderAzure {
 		return fmt.Errorf("unsupported DNS provider: %s (only 'azure' is supported)", c.TLS.DNS.Provider)
 	}
@@ -691,6 +2109,29 @@ func (c *Config) validateTLS() error {
 }
 
 func (c *Config) validateStorage() error {
+	if _, err := domain.NewSourceIDDeriver(
+		domain.SourceIDStrategy(c.Storage.SourceID.Strategy),
+		c.Storage.SourceID.Pattern,
+		c.Storage.SourceID.Template,
+	); err != nil {
+		return fmt.Errorf("storage.source_id: %w", err)
+	}
+	if len(c.Storage.LocalPaths) > 1 && c.Storage.Type != StorageTypeLocal {
+		return fmt.Errorf("storage.local_path: several directories need storage type local")
+	}
+	if c.Storage.Cache.MaxSizeMB < 0 {
+		return fmt.Errorf("storage.cache.max_size_mb must not be negative")
+	}
+	for _, validate := range []func() error{
+		c.Storage.Faults.validate,
+		c.Storage.CircuitBreaker.validate,
+		c.Storage.HealthCheck.validate,
+	} {
+		if err := validate(); err != nil {
+			return err
+		}
+	}
+
 	switch c.Storage.Type {
 	case StorageTypeLocal:
 		return c.validateLocalStorage()
@@ -700,15 +2141,35 @@ func (c *Config) validateStorage() error {
 		return c.validateAzureStorage()
 	case StorageTypeHTTP:
 		return c.validateHTTPStorage()
+	case StorageTypeCatalog:
+		return c.validateCatalogStorage()
 	default:
 		return fmt.Errorf("unknown storage type: %s", c.Storage.Type)
 	}
 }
 
 func (c *Config) validateLocalStorage() error {
-	if c.Storage.LocalPath == "" {
+	if len(c.Storage.LocalPaths) == 0 {
 		return fmt.Errorf("local storage path is required")
 	}
+	// With several directories each key starts with its directory's base
+	// name, so the base names must tell the directories apart.
+	seen := make(map[string]string, len(c.Storage.LocalPaths))
+	for _, dir := range c.Storage.LocalPaths {
+		if strings.TrimSpace(dir) == "" {
+			return fmt.Errorf("storage.local_path: empty directory")
+		}
+		name := filepath.Base(filepath.Clean(dir))
+		if other, dup := seen[name]; dup && len(c.Storage.LocalPaths) > 1 {
+			return fmt.Errorf("storage.local_path: %s and %s share the name %q", other, dir, name)
+		}
+		seen[name] = dir
+	}
+	for _, pattern := range c.Storage.Local.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("storage.local.exclude: invalid pattern %q", pattern)
+		}
+	}
 	return nil
 }
 
@@ -719,6 +2180,52 @@ func (c *Config) validateS3Storage() error {
 	if c.Storage.S3.Region == "" {
 		return fmt.Errorf("S3 region is required")
 	}
+	if err := c.Storage.S3.validateCredentials(); err != nil {
+		return fmt.Errorf("storage.s3: %w", err)
+	}
+	return c.Storage.S3.AssumeRole.validate()
+}
+
+func (s S3Config) validateCredentials() error {
+	hasKeys := s.AccessKeyID != "" || s.SecretAccessKey != ""
+	switch s.Credentials {
+	case "", S3CredentialsAuto:
+	case S3CredentialsChain:
+		if hasKeys || s.SessionToken != "" {
+			return fmt.Errorf("credentials chain does not allow static keys")
+		}
+		return nil
+	case S3CredentialsStatic:
+		if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+			return fmt.Errorf("credentials static needs access_key_id and secret_access_key")
+		}
+	default:
+		return fmt.Errorf("unknown credentials %q (want auto, chain or static)", s.Credentials)
+	}
+	if hasKeys && (s.AccessKeyID == "" || s.SecretAccessKey == "") {
+		return fmt.Errorf("access_key_id and secret_access_key must be set together")
+	}
+	if s.SessionToken != "" && !hasKeys {
+		return fmt.Errorf("session_token needs access_key_id and secret_access_key")
+	}
+	return nil
+}
+
+func (r S3AssumeRoleConfig) validate() error {
+	if r.RoleARN == "" {
+		if r.ExternalID != "" {
+			return fmt.Errorf("storage.s3.assume_role.external_id needs role_arn")
+		}
+		return nil
+	}
+	if !strings.HasPrefix(r.RoleARN, "arn:") {
+		return fmt.Errorf("storage.s3.assume_role.role_arn must be an ARN, got %q", r.RoleARN)
+	}
+	// STS accepts 15 minutes up to the role's maximum session duration (at
+	// most 12 hours).
+	if r.Duration != 0 && (r.Duration < 15*time.Minute || r.Duration > 12*time.Hour) {
+		return fmt.Errorf("storage.s3.assume_role.duration must be between 15m and 12h")
+	}
 	return nil
 }
 
@@ -739,7 +2246,53 @@ func (c *Config) validateHTTPStorage() error {
 	return nil
 }
 
+func (c *Config) validateCatalogStorage() error {
+	cat := c.Storage.Catalog
+	if cat.URL == "" {
+		return fmt.Errorf("catalog URL is required")
+	}
+	switch strings.ToLower(cat.Protocol) {
+	case "csw", "dcat":
+	default:
+		return fmt.Errorf("unknown catalog protocol: %s (csw, dcat)", cat.Protocol)
+	}
+	if cat.TitlePattern != "" {
+		if _, err := regexp.Compile(cat.TitlePattern); err != nil {
+			return fmt.Errorf("storage.catalog.title_pattern: %w", err)
+		}
+	}
+	if cat.MaxRecords < 0 {
+		return fmt.Errorf("storage.catalog.max_records must not be negative")
+	}
+	return nil
+}
+
 // Address returns the server address string.
 func (c *ServerConfig) Address() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
+
+// TrustedProxyCIDRs returns server.trusted_proxies together with the legacy
+// server.rate_limit.trusted_proxies.
+func (c *ServerConfig) TrustedProxyCIDRs() []string {
+	return append(slices.Clone(c.TrustedProxies), c.RateLimit.TrustedProxies...)
+}
+
+// Endpoint describes where the server listens, for logs: server.listen when
+// set, Address otherwise.
+func (c *ServerConfig) Endpoint() string {
+	if c.Listen != "" {
+		return c.Listen
+	}
+	return c.Address()
+}
+
+// SocketPath returns the Unix domain socket path of a unix:// server.listen,
+// or "" for any other listener.
+func (c *ServerConfig) SocketPath() string {
+	path, ok := strings.CutPrefix(c.Listen, ListenUnixPrefix)
+	if !ok {
+		return ""
+	}
+	return path
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -42,7 +43,7 @@ func TestLoadDefaults(t *testing.T) {
 	if bp := cfg.Gazetteer.Elevation.BundlePath; bp != "" {
 		t.Errorf("gazetteer.elevation.bundle_path default = %q, want empty (feature off)", bp)
 	}
-	if cfg.Storage.Type != StorageTypeLocal || cfg.Storage.LocalPath != "./data" {
+	if cfg.Storage.Type != StorageTypeLocal || cfg.Storage.LocalPath() != "./data" {
 		t.Errorf("storage defaults = %+v", cfg.Storage)
 	}
 	if cfg.Query.MaxFeatures != 1000 {
@@ -86,8 +87,8 @@ logging:
 	if cfg.Server.Host != "10.0.0.5" || cfg.Server.Port != 18080 {
 		t.Errorf("server = %s:%d, want 10.0.0.5:18080", cfg.Server.Host, cfg.Server.Port)
 	}
-	if cfg.Storage.LocalPath != "/srv/data" {
-		t.Errorf("local_path = %q", cfg.Storage.LocalPath)
+	if cfg.Storage.LocalPath() != "/srv/data" {
+		t.Errorf("local_path = %q", cfg.Storage.LocalPaths)
 	}
 	if cfg.Query.MaxFeatures != 42 {
 		t.Errorf("max_features = %d, want 42", cfg.Query.MaxFeatures)
@@ -97,6 +98,86 @@ logging:
 	}
 }
 
+// TestLoadLocalPathList: storage.local_path takes a YAML list as well as a
+// single directory, and a comma-separated list from the environment.
+func TestLoadLocalPathList(t *testing.T) {
+	resetViper(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := `
+storage:
+  type: local
+  local_path: [/srv/staging, /srv/production]
+  local:
+    recursive: false
+    exclude: ["**/tmp/**"]
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Storage.LocalPaths; len(got) != 2 || got[0] != "/srv/staging" || got[1] != "/srv/production" {
+		t.Errorf("local_path = %q", got)
+	}
+	if cfg.Storage.Local.Recursive || len(cfg.Storage.Local.Exclude) != 1 {
+		t.Errorf("storage.local = %+v", cfg.Storage.Local)
+	}
+
+	resetViper(t)
+	t.Setenv("ORTUS_STORAGE_LOCAL_PATH", "/srv/staging,/srv/production")
+	if cfg, err = Load(""); err != nil {
+		t.Fatalf("Load from env: %v", err)
+	}
+	if got := cfg.Storage.LocalPaths; len(got) != 2 || !cfg.Storage.Local.Recursive {
+		t.Errorf("from env: local_path = %q, recursive = %v", got, cfg.Storage.Local.Recursive)
+	}
+}
+
+// TestLoadSRIDBounds: query.srid_bounds is keyed by EPSG code, quoted or
+// not, and rejects keys that are none and empty extents.
+func TestLoadSRIDBounds(t *testing.T) {
+	resetViper(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := `
+storage:
+  type: local
+query:
+  srid_bounds:
+    31468: {min_x: 4100000, min_y: 5200000, max_x: 4900000, max_y: 6200000}
+    "2056": {min_x: 2480000, min_y: 1070000, max_x: 2840000, max_y: 1300000}
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	bounds := cfg.Query.Bounds()
+	if got := bounds[31468]; got.MinX != 4100000 || got.MaxY != 6200000 || got.SRID != 31468 {
+		t.Errorf("bounds[31468] = %+v", got)
+	}
+	if _, ok := bounds[2056]; !ok || len(bounds) != 2 {
+		t.Errorf("bounds = %+v, want 31468 and 2056", bounds)
+	}
+
+	for name, b := range map[string]map[string]SRIDBoundsConfig{
+		"no EPSG code": {"gk4": {MinX: 0, MinY: 0, MaxX: 1, MaxY: 1}},
+		"empty extent": {"31468": {MinX: 1, MinY: 0, MaxX: 1, MaxY: 1}},
+	} {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		c.Query.SRIDBounds = b
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: Validate() = nil, want an error", name)
+		}
+	}
+}
+
 func TestLoadMCPTokenFromEnv(t *testing.T) {
 	resetViper(t)
 	t.Setenv("ORTUS_MCP_TOKEN", "s3cr3t")
@@ -110,6 +191,27 @@ func TestLoadMCPTokenFromEnv(t *testing.T) {
 	}
 }
 
+// TestLoadFeatureFlagFromEnv: every flag defaults to on, and a single flag can
+// be switched off through its ORTUS_FEATURES_FLAGS_<NAME> variable.
+func TestLoadFeatureFlagFromEnv(t *testing.T) {
+	resetViper(t)
+	t.Setenv("ORTUS_FEATURES_FLAGS_GEOMETRY_ENCODING", "false")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if v, ok := cfg.Features.Flags["geometry_encoding"]; !ok || v {
+		t.Errorf("features.flags.geometry_encoding = %v (set %v), want false from env", v, ok)
+	}
+	if !cfg.Features.Flags["layer_prioritization"] {
+		t.Error("features.flags.layer_prioritization default = false, want true")
+	}
+	if cfg.Features.RefreshInterval != 30*time.Second {
+		t.Errorf("features.refresh_interval default = %s, want 30s", cfg.Features.RefreshInterval)
+	}
+}
+
 func TestLoadInvalidConfigFails(t *testing.T) {
 	resetViper(t)
 	path := filepath.Join(t.TempDir(), "config.yaml")
@@ -134,11 +236,69 @@ func TestValidateStorage(t *testing.T) {
 		mutate  func(*Config)
 		wantErr bool
 	}{
-		{"local ok", func(c *Config) { c.Storage.Type = StorageTypeLocal; c.Storage.LocalPath = "./data" }, false},
+		{"local ok", func(c *Config) { c.Storage.Type = StorageTypeLocal; c.Storage.LocalPaths = []string{"./data"} }, false},
 		{"local missing path", func(c *Config) { c.Storage.Type = StorageTypeLocal }, true},
+		{"local several dirs ok", func(c *Config) {
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"/srv/staging", "/srv/production"}
+			c.Storage.Local.Exclude = []string{"**/tmp/**"}
+		}, false},
+		{"local dirs with the same name", func(c *Config) {
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"/a/data", "/b/data"}
+		}, true},
+		{"local bad exclude pattern", func(c *Config) {
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Storage.Local.Exclude = []string{"["}
+		}, true},
+		{"s3 with several local dirs", func(c *Config) {
+			c.Storage.Type = StorageTypeS3
+			c.Storage.S3.Bucket = "b"
+			c.Storage.S3.Region = "eu"
+			c.Storage.LocalPaths = []string{"/srv/a", "/srv/b"}
+		}, true},
 		{"s3 ok", func(c *Config) { c.Storage.Type = StorageTypeS3; c.Storage.S3.Bucket = "b"; c.Storage.S3.Region = "eu" }, false},
 		{"s3 missing bucket", func(c *Config) { c.Storage.Type = StorageTypeS3; c.Storage.S3.Region = "eu" }, true},
 		{"s3 missing region", func(c *Config) { c.Storage.Type = StorageTypeS3; c.Storage.S3.Bucket = "b" }, true},
+		{"s3 assume role ok", func(c *Config) {
+			c.Storage.Type = StorageTypeS3
+			c.Storage.S3 = S3Config{Bucket: "b", Region: "eu", Credentials: S3CredentialsChain,
+				AssumeRole: S3AssumeRoleConfig{RoleARN: "arn:aws:iam::123456789012:role/ortus-reader", ExternalID: "x", Duration: time.Hour}}
+		}, false},
+		{"s3 role not an arn", func(c *Config) {
+			c.Storage.Type = StorageTypeS3
+			c.Storage.S3 = S3Config{Bucket: "b", Region: "eu", AssumeRole: S3AssumeRoleConfig{RoleARN: "ortus-reader"}}
+		}, true},
+		{"s3 role duration too short", func(c *Config) {
+			c.Storage.Type = StorageTypeS3
+			c.Storage.S3 = S3Config{Bucket: "b", Region: "eu",
+				AssumeRole: S3AssumeRoleConfig{RoleARN: "arn:aws:iam::123456789012:role/r", Duration: time.Minute}}
+		}, true},
+		{"s3 external id without role", func(c *Config) {
+			c.Storage.Type = StorageTypeS3
+			c.Storage.S3 = S3Config{Bucket: "b", Region: "eu", AssumeRole: S3AssumeRoleConfig{ExternalID: "x"}}
+		}, true},
+		{"s3 chain rejects static keys", func(c *Config) {
+			c.Storage.Type = StorageTypeS3
+			c.Storage.S3 = S3Config{Bucket: "b", Region: "eu", Credentials: S3CredentialsChain, AccessKeyID: "AK", SecretAccessKey: "SK"}
+		}, true},
+		{"s3 static needs keys", func(c *Config) {
+			c.Storage.Type = StorageTypeS3
+			c.Storage.S3 = S3Config{Bucket: "b", Region: "eu", Credentials: S3CredentialsStatic}
+		}, true},
+		{"s3 session token ok", func(c *Config) {
+			c.Storage.Type = StorageTypeS3
+			c.Storage.S3 = S3Config{Bucket: "b", Region: "eu", AccessKeyID: "AK", SecretAccessKey: "SK", SessionToken: "T"}
+		}, false},
+		{"s3 session token without keys", func(c *Config) {
+			c.Storage.Type = StorageTypeS3
+			c.Storage.S3 = S3Config{Bucket: "b", Region: "eu", SessionToken: "T"}
+		}, true},
+		{"s3 unknown credentials", func(c *Config) {
+			c.Storage.Type = StorageTypeS3
+			c.Storage.S3 = S3Config{Bucket: "b", Region: "eu", Credentials: "imds"}
+		}, true},
 		{"azure ok (account)", func(c *Config) {
 			c.Storage.Type = StorageTypeAzure
 			c.Storage.Azure.Container = "c"
@@ -153,7 +313,40 @@ func TestValidateStorage(t *testing.T) {
 		{"azure missing creds", func(c *Config) { c.Storage.Type = StorageTypeAzure; c.Storage.Azure.Container = "c" }, true},
 		{"http ok", func(c *Config) { c.Storage.Type = StorageTypeHTTP; c.Storage.HTTP.BaseURL = "https://x" }, false},
 		{"http missing url", func(c *Config) { c.Storage.Type = StorageTypeHTTP }, true},
+		{"catalog ok", func(c *Config) {
+			c.Storage.Type = StorageTypeCatalog
+			c.Storage.Catalog = CatalogConfig{URL: "https://x/csw", Protocol: "csw", TitlePattern: "^Bezirke"}
+		}, false},
+		{"catalog missing url", func(c *Config) { c.Storage.Type = StorageTypeCatalog; c.Storage.Catalog.Protocol = "dcat" }, true},
+		{"catalog unknown protocol", func(c *Config) {
+			c.Storage.Type = StorageTypeCatalog
+			c.Storage.Catalog = CatalogConfig{URL: "https://x", Protocol: "oai-pmh"}
+		}, true},
+		{"catalog bad title pattern", func(c *Config) {
+			c.Storage.Type = StorageTypeCatalog
+			c.Storage.Catalog = CatalogConfig{URL: "https://x", Protocol: "dcat", TitlePattern: "("}
+		}, true},
 		{"unknown type", func(c *Config) { c.Storage.Type = "ftp" }, true},
+		{"source id prefixed ok", func(c *Config) {
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Storage.SourceID.Strategy = "prefixed"
+		}, false},
+		{"source id regex ok", func(c *Config) {
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Storage.SourceID = SourceIDConfig{Strategy: "regex", Pattern: `^(?P<r>\w+)/`, Template: "${r}"}
+		}, false},
+		{"source id regex missing template", func(c *Config) {
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Storage.SourceID = SourceIDConfig{Strategy: "regex", Pattern: `^(\w+)/`}
+		}, true},
+		{"source id unknown strategy", func(c *Config) {
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Storage.SourceID.Strategy = "hash"
+		}, true},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -172,19 +365,666 @@ func TestValidateServerPort(t *testing.T) {
 		c := &Config{}
 		c.Server.Port = port
 		c.Storage.Type = StorageTypeLocal
-		c.Storage.LocalPath = "./data"
+		c.Storage.LocalPaths = []string{"./data"}
 		if err := c.Validate(); err == nil {
 			t.Errorf("port %d should be invalid", port)
 		}
 	}
 }
 
+func TestValidateSQLiteVerify(t *testing.T) {
+	for verify, wantErr := range map[string]bool{"": false, "off": false, "quick": false, "full": false, "deep": true} {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		c.Query.SQLite.Verify = verify
+		if err := c.Validate(); (err != nil) != wantErr {
+			t.Errorf("verify %q: Validate() err = %v, wantErr %v", verify, err, wantErr)
+		}
+	}
+}
+
+func TestValidateSQLitePragmas(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*SQLiteConfig)
+		wantErr bool
+	}{
+		{"defaults", func(*SQLiteConfig) {}, false},
+		{"tuned", func(s *SQLiteConfig) {
+			s.Synchronous, s.TempStore, s.MmapSizeMB, s.CacheSizeMB = "NORMAL", "memory", 256, 64
+		}, false},
+		{"bad synchronous", func(s *SQLiteConfig) { s.Synchronous = "fast" }, true},
+		{"bad temp_store", func(s *SQLiteConfig) { s.TempStore = "ram" }, true},
+		{"negative mmap", func(s *SQLiteConfig) { s.MmapSizeMB = -1 }, true},
+		{"negative cache", func(s *SQLiteConfig) { s.CacheSizeMB = -1 }, true},
+	}
+	for _, tt := range tests {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		tt.mutate(&c.Query.SQLite)
+		if err := c.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateSQLiteReadOnly(t *testing.T) {
+	tests := []struct {
+		name        string
+		storage     string
+		journalMode string
+		wantErr     bool
+	}{
+		{"local", StorageTypeLocal, "", false},
+		{"remote storage", StorageTypeS3, "", true},
+		{"journal mode", StorageTypeLocal, "WAL", true},
+	}
+	for _, tt := range tests {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = tt.storage
+		c.Storage.LocalPaths = []string{"./data"}
+		c.Storage.S3.Bucket = "packages"
+		c.Storage.S3.Region = "eu-central-1"
+		c.Query.SQLite.ReadOnly = true
+		c.Query.SQLite.JournalMode = tt.journalMode
+		if err := c.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateQuerySlowThreshold(t *testing.T) {
+	for threshold, wantErr := range map[time.Duration]bool{0: false, 500 * time.Millisecond: false, -time.Second: true} {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		c.Query.SlowThreshold = threshold
+		if err := c.Validate(); (err != nil) != wantErr {
+			t.Errorf("slow_threshold %v: Validate() err = %v, wantErr %v", threshold, err, wantErr)
+		}
+	}
+}
+
+func TestValidateMetricsExporter(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics MetricsConfig
+		wantErr bool
+	}{
+		{name: "default", metrics: MetricsConfig{}},
+		{name: "prometheus", metrics: MetricsConfig{Exporter: "prometheus"}},
+		{name: "statsd", metrics: MetricsConfig{Exporter: "statsd", StatsD: StatsDConfig{Address: "statsd:8125"}}},
+		{name: "otlp", metrics: MetricsConfig{Exporter: "otlp", OTLP: OTLPConfig{Endpoint: "collector:4318"}}},
+		{name: "unknown", metrics: MetricsConfig{Exporter: "graphite"}, wantErr: true},
+		{name: "statsd without port", metrics: MetricsConfig{Exporter: "statsd", StatsD: StatsDConfig{Address: "statsd"}}, wantErr: true},
+		{name: "statsd negative interval", metrics: MetricsConfig{Exporter: "statsd", StatsD: StatsDConfig{Address: "statsd:8125", Interval: -time.Second}}, wantErr: true},
+		{name: "otlp without endpoint", metrics: MetricsConfig{Exporter: "otlp"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Metrics = tt.metrics
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateServerStartupTimeout(t *testing.T) {
+	for timeout, wantErr := range map[time.Duration]bool{0: false, 30 * time.Minute: false, -time.Second: true} {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		c.Server.StartupTimeout = timeout
+		if err := c.Validate(); (err != nil) != wantErr {
+			t.Errorf("startup_timeout %v: Validate() err = %v, wantErr %v", timeout, err, wantErr)
+		}
+	}
+}
+
+func TestValidateGeocoder(t *testing.T) {
+	valid := GeocoderConfig{Timeout: 5 * time.Second}
+	tests := []struct {
+		name    string
+		mutate  func(*GeocoderConfig)
+		wantErr bool
+	}{
+		{"off", func(g *GeocoderConfig) { g.Timeout = 0 }, false},
+		{"nominatim without url", func(g *GeocoderConfig) { g.Provider = GeocoderProviderNominatim }, false},
+		{"custom", func(g *GeocoderConfig) { g.Provider, g.URL = GeocoderProviderCustom, "https://geo.example.org/search" }, false},
+		{"custom without url", func(g *GeocoderConfig) { g.Provider = GeocoderProviderCustom }, true},
+		{"unknown provider", func(g *GeocoderConfig) { g.Provider = "google" }, true},
+		{"relative url", func(g *GeocoderConfig) { g.Provider, g.URL = GeocoderProviderNominatim, "nominatim.example.org" }, true},
+		{"no timeout", func(g *GeocoderConfig) { g.Provider, g.Timeout = GeocoderProviderNominatim, 0 }, true},
+		{"negative min_interval", func(g *GeocoderConfig) { g.Provider, g.MinInterval = GeocoderProviderNominatim, -time.Second }, true},
+		{"negative cache_ttl", func(g *GeocoderConfig) { g.Provider, g.CacheTTL = GeocoderProviderNominatim, -time.Second }, true},
+	}
+	for _, tt := range tests {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		c.Geocoder = valid
+		tt.mutate(&c.Geocoder)
+		if err := c.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateServerRequestLimits(t *testing.T) {
+	for limit, wantErr := range map[int]bool{0: false, 32: false, -1: true} {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		c.Server.MaxBodyMB = limit
+		if err := c.Validate(); (err != nil) != wantErr {
+			t.Errorf("max_body_mb %d: Validate() err = %v, wantErr %v", limit, err, wantErr)
+		}
+		c.Server.MaxBodyMB = 0
+		c.Server.MaxQueryLength = limit
+		if err := c.Validate(); (err != nil) != wantErr {
+			t.Errorf("max_query_length %d: Validate() err = %v, wantErr %v", limit, err, wantErr)
+		}
+	}
+}
+
+func TestValidateServerErrorFormat(t *testing.T) {
+	for format, wantErr := range map[string]bool{"": false, "problem": false, "legacy": false, "xml": true} {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		c.Server.ErrorFormat = format
+		if err := c.Validate(); (err != nil) != wantErr {
+			t.Errorf("error_format %q: Validate() err = %v, wantErr %v", format, err, wantErr)
+		}
+	}
+}
+
+func TestValidateFrontendBranding(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       FrontendBrandingConfig
+		wantErr bool
+	}{
+		{name: "empty", b: FrontendBrandingConfig{}},
+		{name: "full", b: FrontendBrandingConfig{LogoURL: "https://example.org/logo.svg", PrimaryColor: "#0a7f3f", FooterText: "Amt für Geodaten"}},
+		{name: "short color", b: FrontendBrandingConfig{PrimaryColor: "#0af"}},
+		{name: "absolute path logo", b: FrontendBrandingConfig{LogoURL: "/static/logo.png"}},
+		{name: "named color", b: FrontendBrandingConfig{PrimaryColor: "green"}, wantErr: true},
+		{name: "css injection", b: FrontendBrandingConfig{PrimaryColor: "#fff; } body { display: none"}, wantErr: true},
+		{name: "javascript logo", b: FrontendBrandingConfig{LogoURL: "javascript:alert(1)"}, wantErr: true},
+		{name: "relative logo", b: FrontendBrandingConfig{LogoURL: "logo.png"}, wantErr: true},
+		{name: "protocol-relative logo", b: FrontendBrandingConfig{LogoURL: "//cdn.example.org/logo.png"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Frontend.Branding = tt.b
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateServerListen(t *testing.T) {
+	tests := []struct {
+		listen  string
+		wantErr bool
+	}{
+		{listen: ""},
+		{listen: "unix:///run/ortus.sock"},
+		{listen: "systemd"},
+		{listen: "unix://", wantErr: true},
+		{listen: "tcp://:8080", wantErr: true},
+		{listen: "/run/ortus.sock", wantErr: true},
+	}
+	for _, tt := range tests {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Server.Listen = tt.listen
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		if err := c.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("listen %q: Validate() err = %v, wantErr %v", tt.listen, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateServerTrustedProxies(t *testing.T) {
+	c := &Config{}
+	c.Server.Port = 8080
+	c.Storage.Type = StorageTypeLocal
+	c.Storage.LocalPaths = []string{"./data"}
+	c.Server.TrustedProxies = []string{"10.0.0.0/8", "2001:db8::/32"}
+	if err := c.Validate(); err != nil {
+		t.Errorf("valid trusted_proxies rejected: %v", err)
+	}
+	c.Server.TrustedProxies = []string{"10.0.0.1"}
+	if err := c.Validate(); err == nil {
+		t.Error("trusted_proxies entry without prefix length accepted")
+	}
+
+	c.Server.TrustedProxies = []string{"10.0.0.0/8"}
+	c.Server.RateLimit.TrustedProxies = []string{"192.168.0.0/16"}
+	if got := c.Server.TrustedProxyCIDRs(); len(got) != 2 {
+		t.Errorf("TrustedProxyCIDRs() = %v, want both lists", got)
+	}
+}
+
+func TestValidateAccessLog(t *testing.T) {
+	tests := []struct {
+		name    string
+		access  AccessLogConfig
+		wantErr bool
+	}{
+		{name: "off", access: AccessLogConfig{Format: "bogus"}},
+		{name: "combined to stdout", access: AccessLogConfig{Enabled: true, Output: "stdout", Format: "combined"}},
+		{name: "json to file", access: AccessLogConfig{Enabled: true, Output: "/var/log/ortus/access.log", Format: "json"}},
+		{name: "unknown format", access: AccessLogConfig{Enabled: true, Output: "stdout", Format: "apache"}, wantErr: true},
+		{name: "no output", access: AccessLogConfig{Enabled: true, Format: "common"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Logging.Access = tt.access
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLogOutput(t *testing.T) {
+	file := LogFileConfig{Path: "/var/log/ortus/ortus.log", MaxSizeMB: 100, MaxBackups: 5}
+	tests := []struct {
+		name    string
+		output  string
+		file    LogFileConfig
+		wantErr bool
+	}{
+		{name: "default", output: ""},
+		{name: "stdout ignores file", output: "stdout", file: LogFileConfig{MaxSizeMB: -1}},
+		{name: "file", output: LogOutputFile, file: file},
+		{name: "unknown output", output: "syslog", wantErr: true},
+		{name: "file without path", output: LogOutputFile, file: LogFileConfig{MaxSizeMB: 100}, wantErr: true},
+		{name: "zero max size", output: LogOutputFile, file: LogFileConfig{Path: file.Path}, wantErr: true},
+		{name: "negative max backups", output: LogOutputFile, file: LogFileConfig{Path: file.Path, MaxSizeMB: 1, MaxBackups: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Logging.Output = tt.output
+			c.Logging.File = tt.file
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRequestLogRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    RequestLogRule
+		wantErr bool
+	}{
+		{name: "sampled prefix", rule: RequestLogRule{Path: "/health/*", Level: "debug", Sample: 100}},
+		{name: "off", rule: RequestLogRule{Path: "/openapi.json", Level: RequestLogOff}},
+		{name: "default level", rule: RequestLogRule{Path: "/api/v1/sources"}},
+		{name: "relative path", rule: RequestLogRule{Path: "health/*"}, wantErr: true},
+		{name: "unknown level", rule: RequestLogRule{Path: "/health/*", Level: "trace"}, wantErr: true},
+		{name: "negative sample", rule: RequestLogRule{Path: "/health/*", Sample: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Logging.Requests = []RequestLogRule{tt.rule}
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateServerStaticDir(t *testing.T) {
+	withIndex := t.TempDir()
+	if err := os.WriteFile(filepath.Join(withIndex, "index.html"), []byte("<!doctype html>"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for dir, wantErr := range map[string]bool{
+		"":                                  false,
+		withIndex:                           false,
+		t.TempDir():                         true, // no index.html
+		filepath.Join(withIndex, "missing"): true,
+	} {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		c.Server.StaticDir = dir
+		if err := c.Validate(); (err != nil) != wantErr {
+			t.Errorf("static_dir %q: Validate() err = %v, wantErr %v", dir, err, wantErr)
+		}
+	}
+}
+
+func TestValidateTLSCertFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		tls     TLSConfig
+		wantErr bool
+	}{
+		{name: "cert and key", tls: TLSConfig{Enabled: true, CertFile: "tls.crt", KeyFile: "tls.key"}},
+		{name: "cert without key", tls: TLSConfig{Enabled: true, CertFile: "tls.crt"}, wantErr: true},
+		{name: "key without cert", tls: TLSConfig{Enabled: true, KeyFile: "tls.key"}, wantErr: true},
+		{name: "cert files and domains", tls: TLSConfig{Enabled: true, CertFile: "tls.crt", KeyFile: "tls.key", Domains: []string{"ortus.example.com"}}, wantErr: true},
+		{name: "acme without domains", tls: TLSConfig{Enabled: true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.TLS = tt.tls
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTLSChallenge(t *testing.T) {
+	acme := func(challenge string, httpPort int) TLSConfig {
+		return TLSConfig{Enabled: true, Domains: []string{"ortus.example.com"}, Email: "admin@example.com",
+			Challenge: challenge, HTTPPort: httpPort, DNS: DNSConfig{Provider: dnsProviderAzure}}
+	}
+	tests := []struct {
+		name    string
+		tls     TLSConfig
+		wantErr bool
+	}{
+		{name: "dns-01 without azure settings", tls: acme(tlsChallengeDNS01, 80), wantErr: true},
+		{name: "http-01 without azure settings", tls: acme(tlsChallengeHTTP01, 80)},
+		{name: "http-01 on server port", tls: acme(tlsChallengeHTTP01, 8080), wantErr: true},
+		{name: "http-01 without port", tls: acme(tlsChallengeHTTP01, 0), wantErr: true},
+		{name: "tls-alpn-01 without azure settings", tls: acme(tlsChallengeTLSALPN01, 0)},
+		{name: "unknown challenge", tls: acme("email-01", 80), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.TLS = tt.tls
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateServerAdminToken(t *testing.T) {
+	c := &Config{}
+	c.Server.Port = 8080
+	c.Storage.Type = StorageTypeLocal
+	c.Storage.LocalPaths = []string{"./data"}
+	c.Server.Admin.Enabled = true
+	if err := c.Validate(); err == nil {
+		t.Error("admin endpoints without ORTUS_ADMIN_TOKEN should be rejected")
+	}
+	c.Server.Admin.Token = "s3cret"
+	if err := c.Validate(); err != nil {
+		t.Errorf("admin with token rejected: %v", err)
+	}
+}
+
+func TestValidateSyncEvents(t *testing.T) {
+	c := &Config{}
+	c.Server.Port = 8080
+	c.Storage.Type = StorageTypeAzure
+	c.Storage.Azure = AzureConfig{Container: "geodata", AccountName: "acct"}
+	c.Sync = SyncConfig{Enabled: true, Interval: 24 * time.Hour, Events: SyncEventsConfig{Enabled: true}}
+	if err := c.Validate(); err == nil {
+		t.Error("sync.events without ORTUS_SYNC_EVENTS_TOKEN should be rejected")
+	}
+	c.Sync.Events.Token = "s3cret"
+	if err := c.Validate(); err != nil {
+		t.Errorf("sync.events with token rejected: %v", err)
+	}
+	c.Sync.Enabled = false
+	if err := c.Validate(); err == nil {
+		t.Error("sync.events without sync.enabled should be rejected")
+	}
+	c.Sync.Enabled = true
+	c.Storage.Type = StorageTypeLocal
+	c.Storage.LocalPaths = []string{"./data"}
+	if err := c.Validate(); err == nil {
+		t.Error("sync.events on local storage should be rejected")
+	}
+}
+
+func TestValidateLeaderElection(t *testing.T) {
+	remote := func(le LeaderElectionConfig) *Config {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeAzure
+		c.Storage.Azure = AzureConfig{Container: "geodata", AccountName: "acct"}
+		c.Sync = SyncConfig{Enabled: true, Interval: time.Hour, LeaderElection: le}
+		return c
+	}
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{name: "enabled", modify: func(*Config) {}},
+		{name: "short lease", modify: func(c *Config) { c.Sync.LeaderElection.LeaseDuration = time.Second }, wantErr: true},
+		{name: "local storage", modify: func(c *Config) {
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+		}, wantErr: true},
+		{name: "with sync events", modify: func(c *Config) {
+			c.Sync.Events = SyncEventsConfig{Enabled: true, Token: "s3cret"}
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := remote(LeaderElectionConfig{Enabled: true, LeaseDuration: 15 * time.Second})
+			tt.modify(c)
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSyncSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		sync    SyncConfig
+		wantErr bool
+	}{
+		{name: "interval", sync: SyncConfig{Enabled: true, Interval: time.Hour}},
+		{name: "schedule without interval", sync: SyncConfig{Enabled: true, Schedule: "0 3 * * *"}},
+		{name: "blackout", sync: SyncConfig{Enabled: true, Interval: time.Hour, Blackout: []string{"Mon-Fri 08:00-18:00"}}},
+		{name: "no interval", sync: SyncConfig{Enabled: true}, wantErr: true},
+		{name: "bad schedule", sync: SyncConfig{Enabled: true, Schedule: "0 3 * *"}, wantErr: true},
+		{name: "bad blackout", sync: SyncConfig{Enabled: true, Interval: time.Hour, Blackout: []string{"8-18"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeAzure
+			c.Storage.Azure = AzureConfig{Container: "geodata", AccountName: "acct"}
+			c.Sync = tt.sync
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateWatcher(t *testing.T) {
+	c := &Config{}
+	c.Server.Port = 8080
+	c.Storage.Type = StorageTypeLocal
+	c.Storage.LocalPaths = []string{"./data"}
+	c.Watcher = WatcherConfig{Mode: WatcherModePoll, PollInterval: 30 * time.Second}
+	if err := c.Validate(); err != nil {
+		t.Errorf("poll mode rejected: %v", err)
+	}
+	c.Watcher.Mode = "inotify"
+	if err := c.Validate(); err == nil {
+		t.Error("unknown watcher.mode should be rejected")
+	}
+	c.Watcher = WatcherConfig{Mode: WatcherModePoll, PollInterval: -time.Second}
+	if err := c.Validate(); err == nil {
+		t.Error("negative watcher.poll_interval should be rejected")
+	}
+	if got := (WatcherConfig{}).Interval(); got != 10*time.Second {
+		t.Errorf("default poll interval = %s, want 10s", got)
+	}
+}
+
+func TestLoadPackages(t *testing.T) {
+	resetViper(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := `
+packages:
+  ABC_2024_v3:
+    name: Districts
+    tags: [admin]
+  parcels:
+    id: eu.2024-05.parcels
+    name: Parcels
+    layers:
+      exclude: [QA_checks, "staging_*"]
+      Flurstuecke:
+        properties: [flstkennz, gemarkung, Flaeche]
+        redact: [eigentuemer]
+        mapping:
+          FLAECHE: {type: number}
+          GEMA: {name: gemarkung}
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	// Keys come back lower-cased; dotted ids need the explicit id.
+	if p := cfg.Packages["abc_2024_v3"]; p.Name != "Districts" || len(p.Tags) != 1 {
+		t.Errorf("packages.abc_2024_v3 = %+v", p)
+	}
+	if p := cfg.Packages["parcels"]; p.SourceID("parcels") != "eu.2024-05.parcels" {
+		t.Errorf("packages.parcels id = %q", p.SourceID("parcels"))
+	}
+	// List values keep their case.
+	if ex := cfg.Packages["parcels"].Layers.Exclude; len(ex) != 2 || ex[0] != "QA_checks" {
+		t.Errorf("packages.parcels.layers.exclude = %v", ex)
+	}
+	// Any other key under layers is a layer name, lower-cased like all keys.
+	layer, ok := cfg.Packages["parcels"].Layers.Layer["flurstuecke"]
+	if !ok || len(layer.Properties) != 3 || layer.Properties[2] != "Flaeche" || len(layer.Redact) != 1 {
+		t.Errorf("packages.parcels.layers.flurstuecke = %+v (layers %+v)", layer, cfg.Packages["parcels"].Layers)
+	}
+	if m := layer.Mapping; m["flaeche"].Type != "number" || m["gema"].Name != "gemarkung" {
+		t.Errorf("packages.parcels.layers.flurstuecke.mapping = %+v", m)
+	}
+}
+
+func TestValidatePackagesDuplicateID(t *testing.T) {
+	c := &Config{}
+	c.Server.Port = 8080
+	c.Storage.Type = StorageTypeLocal
+	c.Storage.LocalPaths = []string{"./data"}
+	c.Packages = map[string]PackageConfig{
+		"districts": {Name: "A"},
+		"other":     {ID: "Districts", Name: "B"},
+	}
+	if err := c.Validate(); err == nil {
+		t.Error("two entries for the same source id should be rejected")
+	}
+}
+
+func TestValidateFederation(t *testing.T) {
+	mk := func() *Config {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		c.Federation = FederationConfig{
+			Enabled:         true,
+			Timeout:         2 * time.Second,
+			RefreshInterval: time.Minute,
+			Peers:           map[string]PeerConfig{"bavaria": {URL: "https://ortus-by.example.org"}},
+		}
+		return c
+	}
+	if err := mk().Validate(); err != nil {
+		t.Fatalf("valid federation rejected: %v", err)
+	}
+
+	tests := map[string]func(*Config){
+		"no peers":         func(c *Config) { c.Federation.Peers = nil },
+		"no timeout":       func(c *Config) { c.Federation.Timeout = 0 },
+		"no refresh":       func(c *Config) { c.Federation.RefreshInterval = 0 },
+		"relative url":     func(c *Config) { c.Federation.Peers["bavaria"] = PeerConfig{URL: "ortus-by"} },
+		"negative timeout": func(c *Config) { c.Federation.Peers["bavaria"] = PeerConfig{URL: "http://x", Timeout: -1} },
+	}
+	for name, mutate := range tests {
+		c := mk()
+		mutate(c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	c := mk()
+	c.Federation.Enabled = false
+	c.Federation.Peers = nil
+	if err := c.Validate(); err != nil {
+		t.Errorf("disabled federation rejected: %v", err)
+	}
+}
+
 func TestValidateTLS(t *testing.T) {
 	mk := func() *Config {
 		c := &Config{}
 		c.Server.Port = 8080
 		c.Storage.Type = StorageTypeLocal
-		c.Storage.LocalPath = "./data"
+		c.Storage.LocalPaths = []string{"./data"}
 		c.TLS.Enabled = true
 		c.TLS.Domains = []string{"example.com"}
 		c.TLS.Email = "a@b.c"
@@ -218,7 +1058,7 @@ func TestValidateMCP(t *testing.T) {
 		c := &Config{}
 		c.Server.Port = 8080
 		c.Storage.Type = StorageTypeLocal
-		c.Storage.LocalPath = "./data"
+		c.Storage.LocalPaths = []string{"./data"}
 		c.MCP.Enabled = true
 		c.MCP.Host = mcpLoopbackHost
 		c.MCP.Port = 9091
@@ -253,7 +1093,7 @@ func TestValidateGazetteer(t *testing.T) {
 		c := &Config{}
 		c.Server.Port = 8080
 		c.Storage.Type = StorageTypeLocal
-		c.Storage.LocalPath = "./data"
+		c.Storage.LocalPaths = []string{"./data"}
 		return c
 	}
 
@@ -288,12 +1128,41 @@ func TestValidateGazetteer(t *testing.T) {
 	}
 }
 
+func TestValidateFeatures(t *testing.T) {
+	mk := func() *Config {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		return c
+	}
+
+	c := mk()
+	c.Features.Flags = map[string]bool{"geometry_encoding": false}
+	if err := c.Validate(); err != nil {
+		t.Errorf("known flag rejected: %v", err)
+	}
+
+	// A typo must not silently leave a kill-switch unset.
+	c = mk()
+	c.Features.Flags = map[string]bool{"geometry_encodign": false}
+	if err := c.Validate(); err == nil {
+		t.Error("unknown feature flag should fail")
+	}
+
+	c = mk()
+	c.Features.RefreshInterval = -time.Second
+	if err := c.Validate(); err == nil {
+		t.Error("negative features.refresh_interval should fail")
+	}
+}
+
 func TestValidateMetricsOTLPAndTracing(t *testing.T) {
 	mk := func() *Config {
 		c := &Config{}
 		c.Server.Port = 8080
 		c.Storage.Type = StorageTypeLocal
-		c.Storage.LocalPath = "./data"
+		c.Storage.LocalPaths = []string{"./data"}
 		return c
 	}
 
@@ -354,3 +1223,318 @@ func TestCORSEnabled(t *testing.T) {
 		t.Error("CORS with origins should be enabled")
 	}
 }
+
+func TestSanitizeSettingsRedactsSecrets(t *testing.T) {
+	got := sanitizeSettings(map[string]interface{}{
+		"storage": map[string]interface{}{
+			"s3": map[string]interface{}{
+				"bucket":            "b",
+				"secret_access_key": "topsecret",
+				"session_token":     "FwoGZXIvYXdzEtemporary",
+			},
+			"http": map[string]interface{}{
+				"password": "",
+				"timeout":  5 * time.Minute,
+			},
+		},
+		"tracing": map[string]interface{}{
+			"headers": map[string]string{"authorization": "Bearer x"},
+		},
+	}, false)
+
+	storage := got["storage"].(map[string]interface{})
+	s3 := storage["s3"].(map[string]interface{})
+	if s3["bucket"] != "b" || s3["secret_access_key"] != redacted || s3["session_token"] != redacted {
+		t.Errorf("s3 = %v", s3)
+	}
+	http := storage["http"].(map[string]interface{})
+	if http["password"] != "" {
+		t.Errorf("unset password = %v, want empty", http["password"])
+	}
+	if http["timeout"] != "5m0s" {
+		t.Errorf("timeout = %v, want 5m0s", http["timeout"])
+	}
+	headers := got["tracing"].(map[string]interface{})["headers"].(map[string]interface{})
+	if headers["authorization"] != redacted {
+		t.Errorf("headers = %v", headers)
+	}
+}
+
+func TestValidatePackagesLayerPatterns(t *testing.T) {
+	c := &Config{}
+	c.Server.Port = 8080
+	c.Storage.Type = StorageTypeLocal
+	c.Storage.LocalPaths = []string{"./data"}
+	c.Packages = map[string]PackageConfig{
+		"parcels": {Layers: LayerFilterConfig{Exclude: []string{"qa_*", "staging_parcels"}}},
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("valid layer patterns rejected: %v", err)
+	}
+	c.Packages["parcels"] = PackageConfig{Layers: LayerFilterConfig{Include: []string{"[parcels"}}}
+	if err := c.Validate(); err == nil {
+		t.Error("malformed layer pattern should be rejected")
+	}
+}
+
+func TestValidatePackagesLoadMode(t *testing.T) {
+	for mode, wantErr := range map[string]bool{"": false, "mmap": false, "Memory": false, "ramdisk": true} {
+		c := &Config{}
+		c.Server.Port = 8080
+		c.Storage.Type = StorageTypeLocal
+		c.Storage.LocalPaths = []string{"./data"}
+		c.Packages = map[string]PackageConfig{"borders": {LoadMode: mode}}
+		if err := c.Validate(); (err != nil) != wantErr {
+			t.Errorf("load_mode %q: Validate() err = %v, wantErr %v", mode, err, wantErr)
+		}
+	}
+}
+
+func TestValidatePackagesLayerProperties(t *testing.T) {
+	c := &Config{}
+	c.Server.Port = 8080
+	c.Storage.Type = StorageTypeLocal
+	c.Storage.LocalPaths = []string{"./data"}
+	layers := map[string]LayerConfig{"parcels": {Properties: []string{"id", "area"}, Redact: []string{"owner"}}}
+	c.Packages = map[string]PackageConfig{"parcels": {Layers: LayerFilterConfig{Layer: layers}}}
+	if err := c.Validate(); err != nil {
+		t.Errorf("valid layer properties rejected: %v", err)
+	}
+	layers["parcels"] = LayerConfig{Redact: []string{"owner", " "}}
+	if err := c.Validate(); err == nil {
+		t.Error("empty property name should be rejected")
+	}
+}
+
+func TestValidatePackagesLayerMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping map[string]PropertyMappingConfig
+		wantErr string
+	}{
+		{
+			name: "valid",
+			mapping: map[string]PropertyMappingConfig{
+				"gen":    {Name: "name"},
+				"ags":    {Type: "integer"},
+				"beginn": {Name: "valid_from", Type: "date", Format: "02.01.2006"},
+			},
+		},
+		{name: "unknown type", mapping: map[string]PropertyMappingConfig{"ags": {Type: "int"}}, wantErr: "invalid type"},
+		{name: "format without date", mapping: map[string]PropertyMappingConfig{"ags": {Type: "integer", Format: "2006"}}, wantErr: "format requires type date"},
+		{name: "name collision", mapping: map[string]PropertyMappingConfig{"gen": {Name: "Name"}, "bez": {Name: "name"}}, wantErr: "both mapped"},
+		{name: "name collides with column", mapping: map[string]PropertyMappingConfig{"gen": {Name: "name"}, "name": {}}, wantErr: "both mapped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			layers := map[string]LayerConfig{"districts": {Mapping: tt.mapping}}
+			c.Packages = map[string]PackageConfig{"vg250": {Layers: LayerFilterConfig{Layer: layers}}}
+			err := c.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePackagesLayerComputed(t *testing.T) {
+	tests := []struct {
+		name     string
+		computed []ComputedPropertyConfig
+		wantErr  string
+	}{
+		{name: "valid", computed: []ComputedPropertyConfig{{Name: "area_m2", Expr: "ST_Area({geom}, 1)"}, {Name: "density", Expr: "EWZ / KFL"}}},
+		{name: "empty name", computed: []ComputedPropertyConfig{{Expr: "1"}}, wantErr: "empty name"},
+		{name: "empty expr", computed: []ComputedPropertyConfig{{Name: "x"}}, wantErr: "empty expr"},
+		{name: "statement", computed: []ComputedPropertyConfig{{Name: "x", Expr: "1; DROP TABLE t"}}, wantErr: "single expression"},
+		{name: "duplicate", computed: []ComputedPropertyConfig{{Name: "x", Expr: "1"}, {Name: "X", Expr: "2"}}, wantErr: "duplicate name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			layers := map[string]LayerConfig{"districts": {Computed: tt.computed}}
+			c.Packages = map[string]PackageConfig{"vg250": {Layers: LayerFilterConfig{Layer: layers}}}
+			err := c.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateQueryBlobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		blobs   BlobConfig
+		wantErr bool
+	}{
+		{name: "unset", blobs: BlobConfig{}},
+		{name: "base64", blobs: BlobConfig{Mode: "base64"}},
+		{name: "exclude", blobs: BlobConfig{Mode: "exclude"}},
+		{name: "truncate", blobs: BlobConfig{Mode: "truncate", MaxBytes: 256}},
+		{name: "truncate without size", blobs: BlobConfig{Mode: "truncate"}, wantErr: true},
+		{name: "unknown mode", blobs: BlobConfig{Mode: "hex"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Query.Blobs = tt.blobs
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateQueryPrecision(t *testing.T) {
+	tests := []struct {
+		name      string
+		precision PrecisionConfig
+		wantErr   bool
+	}{
+		{name: "disabled", precision: PrecisionConfig{Geographic: -1}},
+		{name: "enabled", precision: PrecisionConfig{Enabled: true, Geographic: 6, Projected: 2}},
+		{name: "whole metres", precision: PrecisionConfig{Enabled: true, Geographic: 5, Projected: 0}},
+		{name: "negative", precision: PrecisionConfig{Enabled: true, Geographic: -1, Projected: 2}, wantErr: true},
+		{name: "too many", precision: PrecisionConfig{Enabled: true, Geographic: 6, Projected: 16}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Query.Precision = tt.precision
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateQueryMemory(t *testing.T) {
+	tests := []struct {
+		name    string
+		memory  QueryMemoryConfig
+		wantErr bool
+	}{
+		{name: "off", memory: QueryMemoryConfig{}},
+		{name: "budget", memory: QueryMemoryConfig{BudgetMB: 512, Wait: 2 * time.Second}},
+		{name: "reject at once", memory: QueryMemoryConfig{BudgetMB: 512}},
+		{name: "negative budget", memory: QueryMemoryConfig{BudgetMB: -1}, wantErr: true},
+		{name: "negative wait", memory: QueryMemoryConfig{BudgetMB: 512, Wait: -time.Second}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Query.Memory = tt.memory
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStorageFaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		faults  FaultsConfig
+		wantErr bool
+	}{
+		{name: "off", faults: FaultsConfig{ErrorRate: 7}},
+		{name: "errors and latency", faults: FaultsConfig{Enabled: true, ErrorRate: 0.2, Latency: time.Second, Jitter: time.Second}},
+		{name: "download only", faults: FaultsConfig{Enabled: true, ErrorRate: 1, Operations: []string{"download"}}},
+		{name: "rate above 1", faults: FaultsConfig{Enabled: true, ErrorRate: 1.5}, wantErr: true},
+		{name: "negative rate", faults: FaultsConfig{Enabled: true, ErrorRate: -0.1}, wantErr: true},
+		{name: "negative jitter", faults: FaultsConfig{Enabled: true, Jitter: -time.Second}, wantErr: true},
+		{name: "unknown operation", faults: FaultsConfig{Enabled: true, Operations: []string{"upload"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Storage.Faults = tt.faults
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStorageCircuitBreaker(t *testing.T) {
+	tests := []struct {
+		name    string
+		breaker CircuitBreakerConfig
+		wantErr bool
+	}{
+		{name: "off", breaker: CircuitBreakerConfig{}},
+		{name: "defaults", breaker: CircuitBreakerConfig{Enabled: true, FailureThreshold: 5, OpenTimeout: 30 * time.Second}},
+		{name: "zero threshold", breaker: CircuitBreakerConfig{Enabled: true, OpenTimeout: time.Second}, wantErr: true},
+		{name: "zero timeout", breaker: CircuitBreakerConfig{Enabled: true, FailureThreshold: 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Storage.CircuitBreaker = tt.breaker
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStorageHealthCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   StorageHealthCheckConfig
+		wantErr bool
+	}{
+		{name: "off", check: StorageHealthCheckConfig{}},
+		{name: "list", check: StorageHealthCheckConfig{Enabled: true, Interval: time.Minute, Timeout: 10 * time.Second}},
+		{name: "sentinel", check: StorageHealthCheckConfig{Enabled: true, Interval: time.Minute, Timeout: time.Second, Key: ".ortus-health"}},
+		{name: "zero interval", check: StorageHealthCheckConfig{Enabled: true, Timeout: time.Second}, wantErr: true},
+		{name: "zero timeout", check: StorageHealthCheckConfig{Enabled: true, Interval: time.Minute}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			c.Server.Port = 8080
+			c.Storage.Type = StorageTypeLocal
+			c.Storage.LocalPaths = []string{"./data"}
+			c.Storage.HealthCheck = tt.check
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
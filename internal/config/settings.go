@@ -0,0 +1,70 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// redacted replaces a secret value in Settings.
+const redacted = "[redacted]"
+
+// secretKeys are the leaf keys whose values Settings never prints. Every value
+// under a "headers" map is redacted as well: OTLP exporter headers typically
+// carry auth tokens.
+var secretKeys = map[string]bool{
+	"access_key_id":     true,
+	"secret_access_key": true,
+	"session_token":     true,
+	"account_key":       true,
+	"connection_string": true,
+	"password":          true,
+	"token":             true,
+}
+
+// FileUsed returns the config file the last Load read, or "" when none was
+// found and only defaults and environment apply.
+func FileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
+// Settings returns the effective configuration of the last Load — defaults,
+// config file and environment merged — as nested maps keyed like the config
+// file. Secret values are replaced and durations rendered as strings, so the
+// result can be printed as YAML.
+func Settings() map[string]interface{} {
+	return sanitizeSettings(viper.AllSettings(), false)
+}
+
+func sanitizeSettings(m map[string]interface{}, secret bool) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			out[k] = sanitizeSettings(t, secret || k == "headers")
+		case map[string]string:
+			h := make(map[string]interface{}, len(t))
+			for hk, hv := range t {
+				h[hk] = hv
+			}
+			out[k] = sanitizeSettings(h, secret || k == "headers")
+		default:
+			out[k] = sanitizeValue(v, secret || secretKeys[k])
+		}
+	}
+	return out
+}
+
+func sanitizeValue(v interface{}, secret bool) interface{} {
+	if secret {
+		if s, ok := v.(string); ok && s == "" {
+			// An unset secret is worth seeing as unset.
+			return ""
+		}
+		return redacted
+	}
+	if d, ok := v.(time.Duration); ok {
+		return d.String()
+	}
+	return v
+}
@@ -0,0 +1,90 @@
+package domain
+
+import "time"
+
+// AggregateQuery asks for statistics of a layer's features instead of the
+// features themselves: how many there are, how many per value of a property,
+// and the range and mean of numeric properties.
+type AggregateQuery struct {
+	// Area restricts the aggregation to the features in Relation to it; nil
+	// aggregates the whole layer.
+	Area     *Area
+	Relation SpatialRelation
+	// GroupBy names the property to count the features by; "" counts them
+	// only in total.
+	GroupBy string
+	// MaxGroups caps the groups returned, largest first; 0 returns all.
+	MaxGroups int
+	// Stats names the numeric properties to compute min, max and avg of.
+	Stats []string
+}
+
+// Validate checks the area of the query, when it has one, and that the
+// property names are not blank.
+func (q AggregateQuery) Validate() error {
+	return q.ValidateIn(nil)
+}
+
+// ValidateIn is Validate that also checks the points of the area against
+// bounds (see Coordinate.ValidateIn).
+func (q AggregateQuery) ValidateIn(bounds SRIDBounds) error {
+	if q.Area != nil {
+		if err := q.Area.ValidateIn(bounds); err != nil {
+			return err
+		}
+	}
+	for _, name := range q.Stats {
+		if name == "" {
+			return &ValidationError{
+				Field:      "stats",
+				Constraint: "non-empty property names",
+				Message:    "stats must not contain an empty property name",
+			}
+		}
+	}
+	return nil
+}
+
+// LayerAggregate is the aggregation of one layer's matching features. A
+// layer without the GroupBy property has no Groups; Stats only covers the
+// requested properties the layer has.
+type LayerAggregate struct {
+	Layer           string
+	Count           int64
+	Groups          []GroupCount
+	GroupsTruncated bool // more groups than AggregateQuery.MaxGroups exist
+	Stats           []PropertyStats
+}
+
+// GroupCount is the number of features with one value of the GroupBy
+// property; Value is nil for the features without one.
+type GroupCount struct {
+	Value interface{}
+	Count int64
+}
+
+// PropertyStats summarizes the numeric values of one property. Count is the
+// number of features with a numeric value; Min, Max and Avg are zero when it
+// is 0.
+type PropertyStats struct {
+	Property string
+	Count    int64
+	Min      float64
+	Max      float64
+	Avg      float64
+}
+
+// AggregateResult is the aggregation of one source, a LayerAggregate per
+// layer that answered.
+type AggregateResult struct {
+	SourceID   string
+	SourceName string
+	Layers     []LayerAggregate
+}
+
+// AggregateResponse is the aggregation across sources.
+type AggregateResponse struct {
+	Results        []AggregateResult
+	TotalCount     int64
+	ProcessingTime time.Duration
+}
@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SpatialRelation is how the features of an area query relate to the area.
+type SpatialRelation string
+
+// Spatial relations of an area query.
+const (
+	// RelationIntersects: the feature's geometry shares any point with the area.
+	RelationIntersects SpatialRelation = "intersects"
+	// RelationWithin: the feature's geometry lies inside the area.
+	RelationWithin SpatialRelation = "within"
+	// RelationContains: the feature's geometry contains the whole area.
+	RelationContains SpatialRelation = "contains"
+)
+
+// ParseSpatialRelation parses a relation name; "" is RelationIntersects.
+func ParseSpatialRelation(s string) (SpatialRelation, error) {
+	switch rel := SpatialRelation(strings.ToLower(strings.TrimSpace(s))); rel {
+	case "":
+		return RelationIntersects, nil
+	case RelationIntersects, RelationWithin, RelationContains:
+		return rel, nil
+	default:
+		return "", &ValidationError{
+			Field:      "relation",
+			Value:      s,
+			Constraint: "intersects | within | contains",
+			Message:    "relation must be intersects, within or contains",
+		}
+	}
+}
+
+// MatchKind is the match kind of the features the relation selects.
+func (rel SpatialRelation) MatchKind() MatchKind {
+	switch rel {
+	case RelationWithin:
+		return MatchWithin
+	case RelationContains:
+		return MatchContains
+	default:
+		return MatchIntersects
+	}
+}
+
+// Area is a polygon drawn or given to select the features it relates to. The
+// first ring is the exterior, any further rings are holes; each ring is
+// closed (its last point repeats the first). All points share SRID.
+type Area struct {
+	Rings [][]Coordinate
+	SRID  int
+}
+
+// NewArea creates an area of rings in srid, giving each point that SRID.
+func NewArea(rings [][]Coordinate, srid int) Area {
+	for _, ring := range rings {
+		for i := range ring {
+			ring[i].SRID = srid
+		}
+	}
+	return Area{Rings: rings, SRID: srid}
+}
+
+// Points returns the number of points of all rings.
+func (a Area) Points() int {
+	n := 0
+	for _, ring := range a.Rings {
+		n += len(ring)
+	}
+	return n
+}
+
+// Validate checks that the area has an exterior ring and that every ring is
+// closed, has at least four points and only points valid for the SRID.
+func (a Area) Validate() error {
+	return a.ValidateIn(nil)
+}
+
+// ValidateIn is Validate that also checks the points against bounds (see
+// Coordinate.ValidateIn).
+func (a Area) ValidateIn(bounds SRIDBounds) error {
+	if len(a.Rings) == 0 {
+		return &ValidationError{
+			Field:      "area",
+			Constraint: ">= 1 ring",
+			Message:    "area must have an exterior ring",
+		}
+	}
+	for ri, ring := range a.Rings {
+		if len(ring) < 4 {
+			return &ValidationError{
+				Field:      "area",
+				Value:      len(ring),
+				Constraint: ">= 4 points per ring",
+				Message:    fmt.Sprintf("area ring %d must have at least 4 points", ri),
+			}
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first.X != last.X || first.Y != last.Y {
+			return &ValidationError{
+				Field:      "area",
+				Constraint: "closed rings",
+				Message:    fmt.Sprintf("area ring %d is not closed: its last point must repeat the first", ri),
+			}
+		}
+		for pi, p := range ring {
+			if err := p.ValidateIn(bounds); err != nil {
+				var ve *ValidationError
+				if errors.As(err, &ve) {
+					ve.Message = fmt.Sprintf("area ring %d point %d: %s", ri, pi, ve.Message)
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WKT returns the POLYGON Well-Known Text of the area.
+func (a Area) WKT() string {
+	var b strings.Builder
+	b.WriteString("POLYGON(")
+	for i, ring := range a.Rings {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		writeWKTPositions(&b, ring)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// Extent returns the bounding box of the area's exterior ring.
+func (a Area) Extent() Extent {
+	if len(a.Rings) == 0 || len(a.Rings[0]) == 0 {
+		return Extent{SRID: a.SRID}
+	}
+	first := a.Rings[0][0]
+	e := Extent{MinX: first.X, MinY: first.Y, MaxX: first.X, MaxY: first.Y, SRID: a.SRID}
+	for _, p := range a.Rings[0][1:] {
+		e.MinX, e.MinY = min(e.MinX, p.X), min(e.MinY, p.Y)
+		e.MaxX, e.MaxY = max(e.MaxX, p.X), max(e.MaxY, p.Y)
+	}
+	return e
+}
+
+// ParseAreaWKT parses a POLYGON in Well-Known Text ("POLYGON((x y, ...), (x
+// y, ...))"; a Z or M ordinate is ignored) into an area in srid.
+func ParseAreaWKT(wkt string, srid int) (Area, error) {
+	tag, body, ok := splitWKT(wkt)
+	if !ok || tag != "POLYGON" {
+		return Area{}, areaWKTError(wkt)
+	}
+	var rings [][]Coordinate
+	for rest := strings.TrimSpace(body); rest != ""; {
+		if rest[0] != '(' {
+			return Area{}, areaWKTError(wkt)
+		}
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return Area{}, areaWKTError(wkt)
+		}
+		ring, ok := parseWKTPositions(rest[1:end])
+		if !ok {
+			return Area{}, areaWKTError(wkt)
+		}
+		rings = append(rings, ring)
+		rest = strings.TrimSpace(rest[end+1:])
+		if rest != "" {
+			if rest[0] != ',' {
+				return Area{}, areaWKTError(wkt)
+			}
+			rest = strings.TrimSpace(rest[1:])
+			if rest == "" {
+				return Area{}, areaWKTError(wkt)
+			}
+		}
+	}
+	return NewArea(rings, srid), nil
+}
+
+func areaWKTError(wkt string) error {
+	return &ValidationError{
+		Field:      "area",
+		Value:      wkt,
+		Constraint: "POLYGON((x y, ...))",
+		Message:    "area must be a WKT POLYGON",
+	}
+}
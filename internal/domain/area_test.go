@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseAreaWKT(t *testing.T) {
+	tests := []struct {
+		name      string
+		wkt       string
+		wantRings int
+		wantErr   bool
+	}{
+		{"square", "POLYGON((0 0, 4 0, 4 4, 0 4, 0 0))", 1, false},
+		{"with hole", "POLYGON((0 0, 4 0, 4 4, 0 4, 0 0), (1 1, 2 1, 2 2, 1 1))", 2, false},
+		{"lowercase z", "polygon z ((0 0 1, 4 0 1, 4 4 1, 0 0 1))", 1, false},
+		{"linestring", "LINESTRING(0 0, 1 1)", 0, true},
+		{"missing ring parentheses", "POLYGON(0 0, 4 0, 4 4, 0 0)", 0, true},
+		{"trailing comma", "POLYGON((0 0, 4 0, 4 4, 0 0),)", 0, true},
+		{"not a number", "POLYGON((0 0, x 0, 4 4, 0 0))", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			area, err := ParseAreaWKT(tt.wkt, SRIDWGS84)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAreaWKT(%q) error = %v, wantErr %v", tt.wkt, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Errorf("error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if len(area.Rings) != tt.wantRings {
+				t.Errorf("rings = %d, want %d", len(area.Rings), tt.wantRings)
+			}
+		})
+	}
+}
+
+func TestAreaValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rings   [][]Coordinate
+		wantMsg string
+	}{
+		{"no ring", nil, "exterior ring"},
+		{"too few points", [][]Coordinate{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0}}}, "at least 4 points"},
+		{"open ring", [][]Coordinate{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}}}, "not closed"},
+		{"out of range", [][]Coordinate{{{X: 0, Y: 0}, {X: 200, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}}}, "ring 0 point 1"},
+		{"valid", [][]Coordinate{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewArea(tt.rings, SRIDWGS84).Validate()
+			if tt.wantMsg == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("Validate() = %v, want it to mention %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestAreaWKTAndExtent(t *testing.T) {
+	area, err := ParseAreaWKT("POLYGON((0 0, 4 0, 4 3, 0 0), (1 0.5, 2 0.5, 2 1, 1 0.5))", SRIDWGS84)
+	if err != nil {
+		t.Fatalf("ParseAreaWKT: %v", err)
+	}
+	if got, want := area.WKT(), "POLYGON((0 0, 4 0, 4 3, 0 0), (1 0.5, 2 0.5, 2 1, 1 0.5))"; got != want {
+		t.Errorf("WKT() = %q, want %q", got, want)
+	}
+	if e := area.Extent(); e.MinX != 0 || e.MinY != 0 || e.MaxX != 4 || e.MaxY != 3 {
+		t.Errorf("Extent() = %+v, want (0,0)-(4,3)", e)
+	}
+	if area.Points() != 8 {
+		t.Errorf("Points() = %d, want 8", area.Points())
+	}
+}
+
+func TestParseSpatialRelation(t *testing.T) {
+	for in, want := range map[string]SpatialRelation{
+		"":         RelationIntersects,
+		"Within":   RelationWithin,
+		"contains": RelationContains,
+	} {
+		if got, err := ParseSpatialRelation(in); err != nil || got != want {
+			t.Errorf("ParseSpatialRelation(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseSpatialRelation("touches"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("ParseSpatialRelation(touches) error = %v, want ErrInvalidInput", err)
+	}
+}
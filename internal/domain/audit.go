@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AuditAction names a change to the served data or the instance's state that
+// is recorded in the audit log.
+type AuditAction string
+
+// Audited actions.
+const (
+	AuditSync             AuditAction = "sync"
+	AuditLoad             AuditAction = "load"
+	AuditUnload           AuditAction = "unload"
+	AuditMaintenanceEnter AuditAction = "maintenance.enter"
+	AuditMaintenanceLeave AuditAction = "maintenance.leave"
+	AuditConfigReload     AuditAction = "config.reload"
+	AuditOptimize         AuditAction = "optimize"
+	AuditStage            AuditAction = "stage"
+	AuditPromote          AuditAction = "promote"
+	AuditDiscard          AuditAction = "discard"
+)
+
+// ActorSystem is the actor of an action that carries none in its context.
+const ActorSystem = "system"
+
+// AuditEntry is one audited action.
+type AuditEntry struct {
+	Time   time.Time
+	Actor  string      // who: "admin@<ip>", a client IP, or a component such as "scheduler"
+	Action AuditAction // what
+	Target string      // the source id or path acted on; "" for instance-wide actions
+	Detail string      // e.g. sync counts or a maintenance reason
+	Error  string      // "" when the action succeeded
+}
+
+type actorKey struct{}
+
+// WithActor returns ctx carrying the actor that the audit log attributes the
+// actions done with ctx to.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor carried by ctx, or ActorSystem.
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return ActorSystem
+}
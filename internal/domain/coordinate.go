@@ -4,6 +4,8 @@ package domain
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 )
 
 // Coordinate represents a geographic coordinate with optional height.
@@ -24,8 +26,17 @@ func NewCoordinate(x, y float64, srid int) Coordinate {
 	return Coordinate{X: x, Y: y, SRID: srid}
 }
 
-// Validate checks if the coordinate is valid for its SRID.
+// Validate checks that the coordinate is plausible for its SRID: longitude
+// and latitude in range for WGS84, and for the projected SRIDs with built-in
+// bounds (see ProjectedBounds) easting and northing within them. Other SRIDs
+// are not checked.
 func (c Coordinate) Validate() error {
+	return c.ValidateIn(nil)
+}
+
+// ValidateIn is Validate that also checks the coordinate against the bounds
+// given for its SRID, on top of any built-in ones.
+func (c Coordinate) ValidateIn(bounds SRIDBounds) error {
 	if c.SRID == SRIDWGS84 {
 		if c.X < -180 || c.X > 180 {
 			return &ValidationError{
@@ -44,9 +55,79 @@ func (c Coordinate) Validate() error {
 			}
 		}
 	}
+	if b, ok := ProjectedBounds(c.SRID); ok {
+		if err := c.within(b); err != nil {
+			return err
+		}
+	}
+	if b, ok := bounds[c.SRID]; ok {
+		return c.within(b)
+	}
 	return nil
 }
 
+// within checks that the coordinate lies in b. When only the swapped
+// coordinate would, the message says so: the usual mistake behind an
+// implausible projected coordinate.
+func (c Coordinate) within(b Extent) error {
+	var field, axis string
+	var value, lo, hi float64
+	switch {
+	case c.X < b.MinX || c.X > b.MaxX:
+		field, axis, value, lo, hi = "x", "easting", c.X, b.MinX, b.MaxX
+	case c.Y < b.MinY || c.Y > b.MaxY:
+		field, axis, value, lo, hi = "y", "northing", c.Y, b.MinY, b.MaxY
+	default:
+		return nil
+	}
+	msg := fmt.Sprintf("%s must be between %s and %s for EPSG:%d", axis, formatBound(lo), formatBound(hi), c.SRID)
+	if b.Contains(Coordinate{X: c.Y, Y: c.X}) {
+		msg += " — x and y look swapped"
+	}
+	return &ValidationError{
+		Field:      field,
+		Value:      value,
+		Constraint: fmt.Sprintf("[%s, %s]", formatBound(lo), formatBound(hi)),
+		Message:    msg,
+	}
+}
+
+func formatBound(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// SRIDBounds maps an SRID to the extent, in its units, its coordinates are
+// plausible in. Coordinate.ValidateIn checks them in addition to the built-in
+// bounds, which they can only narrow.
+type SRIDBounds map[int]Extent
+
+// webMercatorLimit is the easting (and northing) of the Web Mercator square
+// at ±180°.
+const webMercatorLimit = 20037508.342789244
+
+// ProjectedBounds returns the built-in plausible extent of a projected SRID:
+// the Web Mercator square for EPSG:3857; for the UTM zones of WGS 84
+// (326xx/327xx), ETRS89 (25828–25838) and NAD83 (26901–26923) eastings of
+// 0–1 000 000 m — a zone's edges reach well past 900 000 m at mid latitudes,
+// and ETRS89 stretches zone 32 over all of Germany — and the northings of the
+// hemisphere; for the DHDN Gauß-Krüger zones 2–5 (31466–31469) the same
+// easting span behind the zone digit. False for any other SRID.
+func ProjectedBounds(srid int) (Extent, bool) {
+	utmNorth := Extent{MinX: 0, MinY: 0, MaxX: 1000000, MaxY: 9400000, SRID: srid}
+	switch {
+	case srid == SRIDWebMercator:
+		return Extent{MinX: -webMercatorLimit, MinY: -webMercatorLimit, MaxX: webMercatorLimit, MaxY: webMercatorLimit, SRID: srid}, true
+	case srid >= 32601 && srid <= 32660, srid >= 25828 && srid <= 25838, srid >= 26901 && srid <= 26923:
+		return utmNorth, true
+	case srid >= 32701 && srid <= 32760:
+		return Extent{MinX: 0, MinY: 1000000, MaxX: 1000000, MaxY: 10000000, SRID: srid}, true
+	case srid >= 31466 && srid <= 31469:
+		zone := float64(srid-31464) * 1e6
+		return Extent{MinX: zone, MinY: 0, MaxX: zone + 1000000, MaxY: 10000000, SRID: srid}, true
+	}
+	return Extent{}, false
+}
+
 // IsZero returns true if the coordinate is unset.
 func (c Coordinate) IsZero() bool {
 	return c.X == 0 && c.Y == 0 && c.SRID == 0
@@ -68,6 +149,65 @@ func (c Coordinate) WKT() string {
 	return fmt.Sprintf("POINT(%f %f)", c.X, c.Y)
 }
 
+// RoundWKT rounds every coordinate of a WKT geometry to decimals places,
+// dropping trailing zeros, so a response carries no more precision than the
+// data has: 6 decimals of a degree are about 10 cm. A negative decimals
+// returns wkt unchanged.
+func RoundWKT(wkt string, decimals int) string {
+	if decimals < 0 {
+		return wkt
+	}
+	var b strings.Builder
+	b.Grow(len(wkt))
+	for i := 0; i < len(wkt); {
+		c := wkt[i]
+		if !isNumberStart(c) {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(wkt) && isNumberByte(wkt[j]) {
+			j++
+		}
+		if f, err := strconv.ParseFloat(wkt[i:j], 64); err == nil {
+			b.WriteString(formatDecimals(f, decimals))
+		} else {
+			b.WriteString(wkt[i:j])
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// RoundTo rounds f to decimals places; a negative decimals returns f.
+func RoundTo(f float64, decimals int) float64 {
+	if decimals < 0 {
+		return f
+	}
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'f', decimals, 64), 64)
+	return r
+}
+
+func formatDecimals(f float64, decimals int) string {
+	s := strconv.FormatFloat(f, 'f', decimals, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+func isNumberStart(c byte) bool {
+	return c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9')
+}
+
+func isNumberByte(c byte) bool {
+	return isNumberStart(c) || c == 'e' || c == 'E'
+}
+
 // Projection represents a coordinate reference system.
 type Projection struct {
 	SRID int    // EPSG Code
@@ -114,6 +254,12 @@ func (e Extent) Contains(c Coordinate) bool {
 	return c.X >= e.MinX && c.X <= e.MaxX && c.Y >= e.MinY && c.Y <= e.MaxY
 }
 
+// Intersects checks if the extent shares any point with o. Both are taken
+// to be in the same SRID.
+func (e Extent) Intersects(o Extent) bool {
+	return e.MinX <= o.MaxX && o.MinX <= e.MaxX && e.MinY <= o.MaxY && o.MinY <= e.MaxY
+}
+
 // IsValid checks if the extent has valid dimensions.
 func (e Extent) IsValid() bool {
 	return e.MinX <= e.MaxX && e.MinY <= e.MaxY
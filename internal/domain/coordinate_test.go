@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -79,10 +81,45 @@ func TestCoordinateValidate(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:    "non-WGS84 coordinate is always valid",
+			name:    "valid UTM coordinate",
 			coord:   NewCoordinate(500000, 5700000, SRIDETRS89UTM32N),
 			wantErr: false,
 		},
+		{
+			name:    "UTM with x and y swapped",
+			coord:   NewCoordinate(5700000, 500000, SRIDETRS89UTM32N),
+			wantErr: true,
+		},
+		{
+			name:    "southern UTM northing below the hemisphere",
+			coord:   NewCoordinate(500000, 500000, 32733),
+			wantErr: true,
+		},
+		{
+			name:    "Web Mercator in range",
+			coord:   NewCoordinate(-1876403.675, 3291468.780, SRIDWebMercator),
+			wantErr: false,
+		},
+		{
+			name:    "Web Mercator past 180 degrees",
+			coord:   NewCoordinate(20037509, 0, SRIDWebMercator),
+			wantErr: true,
+		},
+		{
+			name:    "Gauss-Krueger in its zone",
+			coord:   NewCoordinate(3500000, 5800000, SRIDDHDN3GK3),
+			wantErr: false,
+		},
+		{
+			name:    "Gauss-Krueger in another zone",
+			coord:   NewCoordinate(2500000, 5800000, SRIDDHDN3GK3),
+			wantErr: true,
+		},
+		{
+			name:    "SRID without bounds is not checked",
+			coord:   NewCoordinate(-1e9, 1e9, 2056),
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -95,6 +132,36 @@ func TestCoordinateValidate(t *testing.T) {
 	}
 }
 
+func TestCoordinateValidateIn(t *testing.T) {
+	bounds := SRIDBounds{
+		2056:             {MinX: 2480000, MinY: 1070000, MaxX: 2840000, MaxY: 1300000},
+		SRIDETRS89UTM32N: {MinX: 280000, MinY: 5200000, MaxX: 920000, MaxY: 6100000},
+	}
+	if err := NewCoordinate(2600000, 1200000, 2056).ValidateIn(bounds); err != nil {
+		t.Errorf("inside custom bounds: %v", err)
+	}
+	err := NewCoordinate(1200000, 2600000, 2056).ValidateIn(bounds)
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Field != "x" || !strings.Contains(ve.Message, "swapped") {
+		t.Errorf("swapped: err = %v, want an x error hinting at swapped axes", err)
+	}
+	// Custom bounds narrow the built-in ones, never widen them.
+	if err := NewCoordinate(500000, 5000000, SRIDETRS89UTM32N).ValidateIn(bounds); err == nil {
+		t.Error("outside the narrowed UTM bounds: err = nil")
+	}
+	if err := NewCoordinate(1000500, 5800000, SRIDETRS89UTM32N).ValidateIn(bounds); err == nil {
+		t.Error("outside the built-in UTM bounds: err = nil")
+	}
+	// Eastern Germany (Görlitz) lies near easting 919 000 in EPSG:25832.
+	goerlitz := NewCoordinate(919000, 5669000, SRIDETRS89UTM32N)
+	if err := goerlitz.ValidateIn(nil); err != nil {
+		t.Errorf("Görlitz, built-in bounds: %v", err)
+	}
+	if err := goerlitz.ValidateIn(bounds); err != nil {
+		t.Errorf("Görlitz, configured bounds: %v", err)
+	}
+}
+
 func TestCoordinateIsZero(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -304,6 +371,30 @@ func TestExtentIsValid(t *testing.T) {
 	}
 }
 
+func TestExtentIntersects(t *testing.T) {
+	extent := Extent{MinX: 0, MinY: 0, MaxX: 100, MaxY: 100}
+	tests := []struct {
+		name  string
+		other Extent
+		want  bool
+	}{
+		{"overlapping", Extent{MinX: 50, MinY: 50, MaxX: 150, MaxY: 150}, true},
+		{"inside", Extent{MinX: 10, MinY: 10, MaxX: 20, MaxY: 20}, true},
+		{"enclosing", Extent{MinX: -10, MinY: -10, MaxX: 110, MaxY: 110}, true},
+		{"touching edge", Extent{MinX: 100, MinY: 0, MaxX: 200, MaxY: 100}, true},
+		{"left of", Extent{MinX: -20, MinY: 0, MaxX: -10, MaxY: 100}, false},
+		{"above", Extent{MinX: 0, MinY: 101, MaxX: 100, MaxY: 200}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extent.Intersects(tt.other); got != tt.want {
+				t.Errorf("Intersects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestExtentDimensions(t *testing.T) {
 	extent := Extent{MinX: 10, MinY: 20, MaxX: 50, MaxY: 80}
 
@@ -330,3 +421,32 @@ func TestExtentCenter(t *testing.T) {
 		t.Errorf("Center().SRID = %d, want %d", center.SRID, SRIDWGS84)
 	}
 }
+
+func TestRoundWKT(t *testing.T) {
+	tests := []struct {
+		wkt      string
+		decimals int
+		want     string
+	}{
+		{"POINT(10.123456789 50.987654321)", 6, "POINT(10.123457 50.987654)"},
+		{"POINT(10.5 50)", 6, "POINT(10.5 50)"},
+		{"LINESTRING(512345.678 5432109.876, -0.001 1e-7)", 2, "LINESTRING(512345.68 5432109.88, 0 0)"},
+		{"POLYGON Z((1.25 2.75 3.125, 1 2 3))", 1, "POLYGON Z((1.2 2.8 3.1, 1 2 3))"},
+		{"POINT(10.123456789 50.987654321)", -1, "POINT(10.123456789 50.987654321)"},
+		{"POINT EMPTY", 3, "POINT EMPTY"},
+	}
+	for _, tt := range tests {
+		if got := RoundWKT(tt.wkt, tt.decimals); got != tt.want {
+			t.Errorf("RoundWKT(%q, %d) = %q, want %q", tt.wkt, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestRoundTo(t *testing.T) {
+	if got := RoundTo(12.3456, 2); got != 12.35 {
+		t.Errorf("RoundTo(12.3456, 2) = %v, want 12.35", got)
+	}
+	if got := RoundTo(12.3456, -1); got != 12.3456 {
+		t.Errorf("RoundTo(12.3456, -1) = %v, want it unchanged", got)
+	}
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Base error types (sentinel errors).
@@ -18,15 +19,25 @@ var (
 var (
 	ErrSourceNotFound        = fmt.Errorf("source: %w", ErrNotFound)
 	ErrSourceIDCollision     = fmt.Errorf("source id collision: %w", ErrInvalidInput)
+	ErrInvalidSource         = fmt.Errorf("invalid source: %w", ErrInvalidInput)
 	ErrLayerNotFound         = fmt.Errorf("layer: %w", ErrNotFound)
+	ErrTableNotFound         = fmt.Errorf("table: %w", ErrNotFound)
+	ErrTileNotFound          = fmt.Errorf("tile: %w", ErrNotFound)
 	ErrInvalidCoordinate     = fmt.Errorf("coordinate: %w", ErrInvalidInput)
 	ErrInvalidSRID           = fmt.Errorf("srid: %w", ErrInvalidInput)
+	ErrInvalidGeometryFormat = fmt.Errorf("geometry format: %w", ErrInvalidInput)
+	ErrInvalidSimplify       = fmt.Errorf("simplify tolerance: %w", ErrInvalidInput)
 	ErrUnsupportedProjection = fmt.Errorf("projection: %w", ErrUnsupported)
 	ErrIndexCreationFailed   = fmt.Errorf("index creation: %w", ErrInternal)
 	ErrNotReady              = fmt.Errorf("service not ready: %w", ErrUnavailable)
 	ErrStorageUnavailable    = fmt.Errorf("storage: %w", ErrUnavailable)
 	ErrUnsupportedSource     = fmt.Errorf("source: %w", ErrUnsupported)
 	ErrRateLimited           = errors.New("rate limit exceeded")
+	ErrOutsideExtent         = fmt.Errorf("outside data extent: %w", ErrInvalidInput)
+	ErrMaintenance           = fmt.Errorf("maintenance mode: %w", ErrUnavailable)
+	ErrOptimizeRunning       = fmt.Errorf("optimization already running: %w", ErrUnavailable)
+	ErrPinned                = errors.New("source is pinned")
+	ErrNoCandidate           = fmt.Errorf("staged candidate: %w", ErrNotFound)
 )
 
 // ValidationError represents a detailed validation error.
@@ -48,6 +59,40 @@ func (e *ValidationError) Unwrap() error {
 	return ErrInvalidInput
 }
 
+// OutsideExtentError reports a query point that lies outside the extent of
+// every loaded layer (strict extent mode). Extent is the union of the layer
+// extents in WGS84, so a client can see where data actually is.
+type OutsideExtentError struct {
+	Extent Extent // union of the layer extents, SRID 4326
+}
+
+// Error implements the error interface.
+func (e *OutsideExtentError) Error() string {
+	return fmt.Sprintf("query point outside the data extent (%g,%g,%g,%g)",
+		e.Extent.MinX, e.Extent.MinY, e.Extent.MaxX, e.Extent.MaxY)
+}
+
+// Unwrap returns the underlying error type.
+func (e *OutsideExtentError) Unwrap() error {
+	return ErrOutsideExtent
+}
+
+// RateLimitError is ErrRateLimited with the time until a call is accepted
+// again.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v, retry in %v", ErrRateLimited, e.RetryAfter.Round(time.Second))
+}
+
+// Unwrap returns ErrRateLimited.
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
 // QueryError represents an error during a query operation.
 type QueryError struct {
 	SourceID string // source identifier
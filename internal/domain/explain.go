@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// QueryExplain records how a point query was executed, layer by layer, for
+// slow-query investigations (?explain=true). It travels in the context so the
+// query service and the adapters fill it in without an extra parameter on
+// every port; a context without one records nothing.
+type QueryExplain struct {
+	mu     sync.Mutex
+	layers []*LayerExplain
+}
+
+// LayerExplain is the execution of a point query on one layer.
+type LayerExplain struct {
+	SourceID string
+	Layer    string
+	// Skipped says why the layer was not queried; "" when it was.
+	Skipped string
+	// Transform is the reprojection of the query point into the layer SRID;
+	// nil when the point already was in it.
+	Transform *TransformExplain
+	// SQL is the statement run against the layer, Plan its EXPLAIN QUERY
+	// PLAN lines. Both are empty for sources that are not queried with SQL.
+	SQL  string
+	Plan []string
+	// RTree reports whether the R-tree index pre-filtered the rows.
+	RTree bool
+	// RowsExamined counts the rows the spatial predicate was evaluated on:
+	// the R-tree candidates, or the whole table without an index.
+	RowsExamined int64
+	// RowsReturned counts the rows the SQL returned, Features those kept
+	// after deduplication, the height and property filters and the feature cap.
+	RowsReturned int
+	Features     int
+	// TransformTime, SQLTime and Total time the stages; Total includes the
+	// filtering after the SQL.
+	TransformTime time.Duration
+	SQLTime       time.Duration
+	Total         time.Duration
+}
+
+// TransformExplain is a reprojection of the query point.
+type TransformExplain struct {
+	FromSRID int
+	ToSRID   int
+	X, Y     float64 // the point in ToSRID
+}
+
+// Layer returns the record of sourceID's layer, creating it on first use.
+// It returns nil on a nil QueryExplain, so callers can write
+// ExplainFrom(ctx).Layer(...) and skip the recording on nil.
+func (e *QueryExplain) Layer(sourceID, layer string) *LayerExplain {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, l := range e.layers {
+		if l.SourceID == sourceID && l.Layer == layer {
+			return l
+		}
+	}
+	l := &LayerExplain{SourceID: sourceID, Layer: layer}
+	e.layers = append(e.layers, l)
+	return l
+}
+
+// The recording methods of LayerExplain do nothing on a nil receiver, so a
+// query runs the same calls whether it is explained or not.
+
+// Transformed records the time taken to get the query point into the layer
+// SRID, and the reprojection when from and to differ in SRID.
+func (l *LayerExplain) Transformed(from, to Coordinate, d time.Duration) {
+	if l == nil {
+		return
+	}
+	l.TransformTime = d
+	if from.SRID != to.SRID {
+		l.Transform = &TransformExplain{FromSRID: from.SRID, ToSRID: to.SRID, X: to.X, Y: to.Y}
+	}
+}
+
+// Skip records why the layer was not queried.
+func (l *LayerExplain) Skip(reason string) {
+	if l != nil {
+		l.Skipped = reason
+	}
+}
+
+// Kept records the number of features the layer contributed.
+func (l *LayerExplain) Kept(n int) {
+	if l != nil {
+		l.Features = n
+	}
+}
+
+// Finish records the total time of the layer, started at start.
+func (l *LayerExplain) Finish(start time.Time) {
+	if l != nil {
+		l.Total = time.Since(start)
+	}
+}
+
+// Layers returns the layer records in the order the layers were queried.
+func (e *QueryExplain) Layers() []LayerExplain {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]LayerExplain, len(e.layers))
+	for i, l := range e.layers {
+		out[i] = *l
+	}
+	return out
+}
+
+type explainKey struct{}
+
+// WithExplain returns ctx recording the execution of the queries run with it
+// into e.
+func WithExplain(ctx context.Context, e *QueryExplain) context.Context {
+	return context.WithValue(ctx, explainKey{}, e)
+}
+
+// ExplainFrom returns the QueryExplain carried by ctx, or nil.
+func ExplainFrom(ctx context.Context) *QueryExplain {
+	e, _ := ctx.Value(explainKey{}).(*QueryExplain)
+	return e
+}
@@ -1,6 +1,11 @@
 package domain
 
-import "strings"
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+)
 
 // Feature represents a geo feature with geometry and properties.
 type Feature struct {
@@ -8,6 +13,33 @@ type Feature struct {
 	LayerName  string                 // Associated layer name
 	Geometry   Geometry               // Geometry data
 	Properties map[string]interface{} // Attribute data
+	Match      Match                  // Why a point query returned the feature
+}
+
+// MatchKind says how a feature matched a point query.
+type MatchKind string
+
+// Match kinds.
+const (
+	// MatchContains: the feature covers the point, boundary included
+	// (polygon layers, raster cells), or contains the queried area.
+	MatchContains MatchKind = "contains"
+	// MatchBBox: the point lies within the feature's bounding box (point and
+	// line layers); the distance tells how far the geometry itself is.
+	MatchBBox MatchKind = "bbox"
+	// MatchIntersects: the feature's geometry intersects the queried route
+	// or area.
+	MatchIntersects MatchKind = "intersects"
+	// MatchWithin: the feature's geometry lies inside the queried area.
+	MatchWithin MatchKind = "within"
+)
+
+// Match explains why a point query returned a feature. The zero value means
+// the adapter recorded nothing.
+type Match struct {
+	Kind      MatchKind
+	DistanceM float64 // distance from the query point to the geometry; 0 when contained
+	SRID      int     // SRID of the layer the match was evaluated in
 }
 
 // GetProperty returns a property value by key.
@@ -61,15 +93,72 @@ func (f *Feature) GetFloatProperty(key string) float64 {
 	return 0
 }
 
-// Geometry represents a geometry object.
+// Geometry represents a geometry object. WKT is always populated; the other
+// encodings only when a query asked for them (see GeometryFormat).
 type Geometry struct {
 	Type        string     // WKT type (Point, Polygon, etc.)
 	WKT         string     // Well-Known Text representation
 	WKB         []byte     // Well-Known Binary representation
+	GeoJSON     string     // GeoJSON geometry object
+	GML         string     // GML 3 fragment
 	SRID        int        // Spatial Reference ID
 	Coordinates Coordinate // For point geometries
 }
 
+// hash identifies the geometry by its SRID and WKT: two features with the
+// same shape in the same SRID hash alike.
+func (g Geometry) hash() uint64 {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d;%s", g.SRID, g.WKT)
+	return h.Sum64()
+}
+
+// GeometryFormat selects the encoding of geometries returned by a query.
+type GeometryFormat string
+
+// Geometry encodings.
+const (
+	GeometryFormatWKT     GeometryFormat = "wkt"
+	GeometryFormatWKB     GeometryFormat = "wkb"
+	GeometryFormatGeoJSON GeometryFormat = "geojson"
+	GeometryFormatGML     GeometryFormat = "gml"
+)
+
+// ParseGeometryFormat parses a geometry format name, case-insensitively. An
+// empty name selects GeometryFormatWKT.
+func ParseGeometryFormat(s string) (GeometryFormat, error) {
+	switch f := GeometryFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return GeometryFormatWKT, nil
+	case GeometryFormatWKT, GeometryFormatWKB, GeometryFormatGeoJSON, GeometryFormatGML:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want wkt, wkb, geojson or gml)", ErrInvalidGeometryFormat, s)
+	}
+}
+
+// GeometryOptions shapes the geometries a query returns. The zero value asks
+// for full-resolution WKT only.
+type GeometryOptions struct {
+	Format GeometryFormat // extra encoding next to WKT ("" = WKT only)
+	// Simplify is the ST_SimplifyPreserveTopology tolerance, in units of the
+	// layer's CRS (degrees for EPSG:4326, metres for UTM). 0 disables it.
+	Simplify float64
+}
+
+// IsDefault reports whether o asks for nothing beyond full-resolution WKT.
+func (o GeometryOptions) IsDefault() bool {
+	return (o.Format == "" || o.Format == GeometryFormatWKT) && o.Simplify == 0
+}
+
+// Validate checks that the simplify tolerance is a finite, non-negative number.
+func (o GeometryOptions) Validate() error {
+	if math.IsNaN(o.Simplify) || math.IsInf(o.Simplify, 0) || o.Simplify < 0 {
+		return fmt.Errorf("%w: %v (must be a non-negative number)", ErrInvalidSimplify, o.Simplify)
+	}
+	return nil
+}
+
 // IsPoint returns true if the geometry is a point.
 // Comparison is case-insensitive — both the all-caps WKT form ("POINT") and
 // the GeoJSON-style title case ("Point") are accepted.
@@ -1,6 +1,10 @@
 package domain
 
-import "testing"
+import (
+	"errors"
+	"math"
+	"testing"
+)
 
 func TestFeatureGetProperty(t *testing.T) {
 	feature := Feature{
@@ -207,3 +211,63 @@ func TestGeometryIsLine(t *testing.T) {
 		})
 	}
 }
+
+func TestParseGeometryFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    GeometryFormat
+		wantErr bool
+	}{
+		{"", GeometryFormatWKT, false},
+		{"wkt", GeometryFormatWKT, false},
+		{"WKB", GeometryFormatWKB, false},
+		{" geojson ", GeometryFormatGeoJSON, false},
+		{"gml", GeometryFormatGML, false},
+		{"kml", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseGeometryFormat(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGeometryFormat(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("error %v does not wrap ErrInvalidInput", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseGeometryFormat(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeometryOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        GeometryOptions
+		wantDefault bool
+		wantErr     bool
+	}{
+		{"zero", GeometryOptions{}, true, false},
+		{"wkt", GeometryOptions{Format: GeometryFormatWKT}, true, false},
+		{"gml", GeometryOptions{Format: GeometryFormatGML}, false, false},
+		{"simplify", GeometryOptions{Simplify: 0.001}, false, false},
+		{"negative", GeometryOptions{Simplify: -1}, false, true},
+		{"nan", GeometryOptions{Simplify: math.NaN()}, false, true},
+		{"inf", GeometryOptions{Simplify: math.Inf(1)}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.IsDefault(); got != tt.wantDefault {
+				t.Errorf("IsDefault() = %v, want %v", got, tt.wantDefault)
+			}
+			err := tt.opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidSimplify) {
+				t.Errorf("error %v does not wrap ErrInvalidSimplify", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "fmt"
+
+// FeatureFlag names a runtime switch guarding a subsystem that is still being
+// rolled out. Every flag defaults to on, so a deployment only has to act to
+// turn a subsystem off (the kill-switch).
+type FeatureFlag string
+
+// Feature flags.
+const (
+	// FlagLayerPrioritization orders layers by learned hit-rate when a query
+	// deadline is tight (query.prioritize_within).
+	FlagLayerPrioritization FeatureFlag = "layer_prioritization"
+	// FlagGeometryEncoding produces the geometry_format / simplify options in
+	// SQL; off, queries return full-resolution WKT only.
+	FlagGeometryEncoding FeatureFlag = "geometry_encoding"
+	// FlagParallelEnrichment runs the per-point gazetteer enrichment of batch
+	// queries on a worker pool; off, points are enriched one at a time.
+	FlagParallelEnrichment FeatureFlag = "parallel_enrichment"
+	// FlagWGS84Reprojection reprojects non-WGS84 HTTP queries to WGS84 for the
+	// wgs84 block and gazetteer enrichment; off, only WGS84 queries get them.
+	FlagWGS84Reprojection FeatureFlag = "wgs84_reprojection"
+)
+
+// KnownFeatureFlags lists every flag, in documentation order.
+func KnownFeatureFlags() []FeatureFlag {
+	return []FeatureFlag{
+		FlagLayerPrioritization,
+		FlagGeometryEncoding,
+		FlagParallelEnrichment,
+		FlagWGS84Reprojection,
+	}
+}
+
+// ParseFeatureFlag returns the flag named s, or an error wrapping
+// ErrInvalidInput for an unknown name (typically a typo in config).
+func ParseFeatureFlag(s string) (FeatureFlag, error) {
+	for _, f := range KnownFeatureFlags() {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown feature flag %q: %w", s, ErrInvalidInput)
+}
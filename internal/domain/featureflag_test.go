@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseFeatureFlag(t *testing.T) {
+	for _, f := range KnownFeatureFlags() {
+		got, err := ParseFeatureFlag(string(f))
+		if err != nil || got != f {
+			t.Errorf("ParseFeatureFlag(%q) = %q, %v; want %q", f, got, err, f)
+		}
+	}
+	_, err := ParseFeatureFlag("Geometry_Encoding")
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unknown flag error = %v, want ErrInvalidInput", err)
+	}
+}
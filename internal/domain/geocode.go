@@ -0,0 +1,130 @@
+package domain
+
+import "strings"
+
+// GeocodeMatch is one place an address search resolved to.
+type GeocodeMatch struct {
+	Label      string     // the place as the geocoder names it, e.g. a full address
+	Coordinate Coordinate // the point of the place, WGS84
+	Extent     Extent     // bounding box of the place, WGS84; zero when the geocoder gives none
+}
+
+// geohashAlphabet is the base-32 alphabet of geohashes.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// DecodeGeohash returns the WGS84 cell a geohash stands for; its center is
+// the point the geohash locates. Letters may be given in either case.
+func DecodeGeohash(hash string) (Extent, error) {
+	cell := Extent{MinX: -180, MinY: -90, MaxX: 180, MaxY: 90, SRID: SRIDWGS84}
+	if hash == "" {
+		return Extent{}, geocodeError("geohash", hash, "geohash is empty")
+	}
+	even := true // bits alternate between longitude and latitude, longitude first
+	for _, r := range strings.ToLower(hash) {
+		v := strings.IndexRune(geohashAlphabet, r)
+		if v < 0 {
+			return Extent{}, geocodeError("geohash", hash, "geohash may only hold 0-9 and b-z without a, i, l and o")
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := v&(1<<bit) != 0
+			if even {
+				mid := (cell.MinX + cell.MaxX) / 2
+				if set {
+					cell.MinX = mid
+				} else {
+					cell.MaxX = mid
+				}
+			} else {
+				mid := (cell.MinY + cell.MaxY) / 2
+				if set {
+					cell.MinY = mid
+				} else {
+					cell.MaxY = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return cell, nil
+}
+
+// Open Location Code (Plus Code) layout: digit pairs of latitude and
+// longitude at resolutions plusCodePairResolutions, the separator after the
+// eighth digit, then up to plusCodeMaxLength-plusCodePairDigits digits of a
+// 5×4 grid refinement. '0' pads a code shortened before the separator.
+const (
+	plusCodeAlphabet   = "23456789CFGHJMPQRVWX"
+	plusCodeSeparator  = '+'
+	plusCodePadding    = '0'
+	plusCodeSepIndex   = 8
+	plusCodePairDigits = 10
+	plusCodeMaxLength  = 15
+	plusCodeGridRows   = 5
+	plusCodeGridCols   = 4
+)
+
+var plusCodePairResolutions = [plusCodePairDigits / 2]float64{20, 1, 0.05, 0.0025, 0.000125}
+
+// DecodePlusCode returns the WGS84 cell a full Plus Code (Open Location Code)
+// stands for, such as "9F4MGC5X+" or "8FWC2345+G6"; its center is the point
+// the code locates. A short code ("GC5X+9F Würzburg") is refused, since it
+// needs a reference location to be resolved.
+func DecodePlusCode(code string) (Extent, error) {
+	upper := strings.ToUpper(code)
+	sep := strings.IndexByte(upper, plusCodeSeparator)
+	switch {
+	case sep < 0 || strings.Count(upper, string(plusCodeSeparator)) > 1:
+		return Extent{}, geocodeError("pluscode", code, "plus code needs exactly one '+'")
+	case sep < plusCodeSepIndex:
+		return Extent{}, geocodeError("pluscode", code, "short plus codes need a reference location; give the full code")
+	case sep > plusCodeSepIndex:
+		return Extent{}, geocodeError("pluscode", code, "plus code has more than 8 digits before '+'")
+	}
+	digits := upper[:sep]
+	refinement := upper[sep+1:]
+	if pad := strings.IndexByte(digits, plusCodePadding); pad >= 0 {
+		if pad == 0 || pad%2 != 0 || strings.Trim(digits[pad:], string(plusCodePadding)) != "" || refinement != "" {
+			return Extent{}, geocodeError("pluscode", code, "plus code padding must fill whole digit pairs up to a final '+'")
+		}
+		digits = digits[:pad]
+	}
+	if len(refinement) == 1 {
+		return Extent{}, geocodeError("pluscode", code, "plus code needs at least two digits after '+'")
+	}
+	digits += refinement
+	if len(digits) > plusCodeMaxLength {
+		digits = digits[:plusCodeMaxLength] // finer digits are legal but below float precision
+	}
+
+	lat, lon := -90.0, -180.0
+	var latRes, lonRes float64
+	for i, r := range digits {
+		v := strings.IndexRune(plusCodeAlphabet, r)
+		if v < 0 {
+			return Extent{}, geocodeError("pluscode", code, "plus code may only hold 23456789CFGHJMPQRVWX")
+		}
+		if i < plusCodePairDigits {
+			res := plusCodePairResolutions[i/2]
+			if i%2 == 0 {
+				lat += float64(v) * res
+				latRes = res
+			} else {
+				lon += float64(v) * res
+				lonRes = res
+			}
+			continue
+		}
+		latRes /= plusCodeGridRows
+		lonRes /= plusCodeGridCols
+		lat += float64(v/plusCodeGridCols) * latRes
+		lon += float64(v%plusCodeGridCols) * lonRes
+	}
+	if lat >= 90 || lon >= 180 {
+		return Extent{}, geocodeError("pluscode", code, "plus code lies outside latitude -90 to 90 or longitude -180 to 180")
+	}
+	return Extent{MinX: lon, MinY: lat, MaxX: lon + lonRes, MaxY: lat + latRes, SRID: SRIDWGS84}, nil
+}
+
+func geocodeError(field, value, msg string) error {
+	return &ValidationError{Field: field, Value: value, Constraint: "valid " + field, Message: msg}
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDecodeGeohash(t *testing.T) {
+	cell, err := DecodeGeohash("ezs42")
+	if err != nil {
+		t.Fatalf("DecodeGeohash(ezs42) error = %v", err)
+	}
+	c := cell.Center()
+	if math.Abs(c.X-(-5.603)) > 0.001 || math.Abs(c.Y-42.605) > 0.001 || c.SRID != SRIDWGS84 {
+		t.Errorf("center = %v, want about -5.603 42.605 in WGS84", c)
+	}
+	if w, h := cell.Width(), cell.Height(); math.Abs(w-0.0439) > 0.0001 || math.Abs(h-0.0439) > 0.0001 {
+		t.Errorf("cell = %v x %v, want about 0.044° square", w, h)
+	}
+
+	upper, err := DecodeGeohash("EZS42")
+	if err != nil || upper != cell {
+		t.Errorf("DecodeGeohash(EZS42) = %v, %v; want the lower-case cell", upper, err)
+	}
+
+	for _, bad := range []string{"", "ezs4a", "u33d b8"} {
+		if _, err := DecodeGeohash(bad); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("DecodeGeohash(%q) error = %v, want ErrInvalidInput", bad, err)
+		}
+	}
+}
+
+func TestDecodePlusCode(t *testing.T) {
+	tests := []struct {
+		code     string
+		lat, lon float64
+		size     float64 // latitude height of the cell
+	}{
+		{code: "8FVC9G8F+6X", lat: 47.3655625, lon: 8.5249375, size: 0.000125},
+		{code: "8fvc9g8f+6x", lat: 47.3655625, lon: 8.5249375, size: 0.000125},
+		{code: "9F4MGC5X+", lat: 52.50875, lon: 13.44875, size: 0.0025},
+		{code: "8FVC0000+", lat: 47.5, lon: 8.5, size: 1},
+		{code: "8FVC9G8F+6XQQ", lat: 47.3655925, lon: 8.5249961, size: 0.000125 / 25},
+	}
+	for _, tt := range tests {
+		cell, err := DecodePlusCode(tt.code)
+		if err != nil {
+			t.Errorf("DecodePlusCode(%q) error = %v", tt.code, err)
+			continue
+		}
+		c := cell.Center()
+		if math.Abs(c.Y-tt.lat) > 1e-6 || math.Abs(c.X-tt.lon) > 1e-6 {
+			t.Errorf("DecodePlusCode(%q) center = %.7f %.7f, want %.7f %.7f", tt.code, c.Y, c.X, tt.lat, tt.lon)
+		}
+		if math.Abs(cell.Height()-tt.size) > 1e-9 {
+			t.Errorf("DecodePlusCode(%q) height = %v, want %v", tt.code, cell.Height(), tt.size)
+		}
+	}
+
+	for _, bad := range []string{
+		"",
+		"9F4MGC5X",    // no separator
+		"GC5X+9F",     // short code
+		"9F4MGC5XC+",  // too many digits before '+'
+		"9F4MGC5X+9",  // one refinement digit
+		"9F4MGC5X++",  // two separators
+		"9F4M0C00+",   // padding interrupted
+		"9F40000+",    // short and padded
+		"9F4M0000+9F", // digits after padding
+		"9F4MGC5A+",   // not in the alphabet
+		"XF4MGC5X+",   // latitude 90 or more
+		"9XVC9G8F+6X", // longitude 180 or more
+	} {
+		if _, err := DecodePlusCode(bad); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("DecodePlusCode(%q) error = %v, want ErrInvalidInput", bad, err)
+		}
+	}
+}
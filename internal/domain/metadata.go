@@ -8,8 +8,10 @@ type Metadata struct {
 	Description string            // Description
 	Creator     string            // Creator/Author
 	CreatedAt   time.Time         // Creation date
+	UpdatedAt   time.Time         // Last revision date
 	Version     string            // Version string
 	Keywords    []string          // Keywords/Tags
+	Constraints []string          // Use and access constraints, as published
 	Custom      map[string]string // Custom metadata fields
 }
 
@@ -60,6 +62,16 @@ type QueryResult struct {
 	License     License       // License information
 	Attribution string        // Attribution text
 	QueryTime   time.Duration // Query execution time
+	Incomplete  bool          // deadline hit before every layer was queried
+	Notes       []LayerNote   // caveats of the layers that matched
+	Peer        string        // federation peer that answered; "" for local sources
+}
+
+// LayerNote is one caveat a layer declares in its metadata, attached to a
+// result so consumers see it next to the features it qualifies.
+type LayerNote struct {
+	Layer string // layer the note belongs to
+	Text  string // caveat text, as published
 }
 
 // FeatureCount returns the number of features in the result.
@@ -78,6 +90,12 @@ type QueryRequest struct {
 	SourceSRID int        // Source coordinate system
 	Properties []string   // Properties to return (empty = all)
 	SourceID   string     // Specific source (empty = all)
+	// Geometry selects the extra encoding and simplification of returned
+	// geometries (zero value = full-resolution WKT only).
+	Geometry GeometryOptions
+	// NoForward answers from local sources only. Set on queries a federation
+	// peer forwarded, so two peers never bounce a query between them.
+	NoForward bool
 }
 
 // QueryResponse represents the full query response.
@@ -86,6 +104,26 @@ type QueryResponse struct {
 	TotalFeatures  int           // Total feature count
 	ProcessingTime time.Duration // Total processing time
 	Coordinate     Coordinate    // Queried coordinate
+	Incomplete     bool          // deadline hit before every source/layer was queried
+	Peers          []PeerOutcome // federation peers asked, in configuration order
+}
+
+// Outcomes of a forwarded query.
+const (
+	PeerStatusOK      = "ok"
+	PeerStatusTimeout = "timeout"
+	PeerStatusError   = "error"
+)
+
+// PeerOutcome records how one federation peer took part in a query.
+type PeerOutcome struct {
+	Peer     string        // peer name
+	Status   string        // PeerStatusOK, PeerStatusTimeout or PeerStatusError
+	Duration time.Duration // time until the peer answered or gave up
+	Features int           // features kept from its answer
+	// Incomplete: the peer hit its own deadline before every source/layer
+	// was queried.
+	Incomplete bool
 }
 
 // AddResult adds a query result to the response.
@@ -93,3 +131,45 @@ func (r *QueryResponse) AddResult(result QueryResult) {
 	r.Results = append(r.Results, result)
 	r.TotalFeatures += result.FeatureCount()
 }
+
+// Dedupe drops the features a result earlier in r already holds — the same
+// layer name, feature ID and geometry, as when overlapping regional and
+// national packages are both loaded — and the results left without
+// features. The first copy, from the source queried first, is kept. Feature
+// slices are copied rather than filtered in place, as they may be shared
+// with a cache. It returns the number of features dropped.
+func (r *QueryResponse) Dedupe() int {
+	type key struct {
+		layer string
+		id    int64
+		geom  uint64
+	}
+	seen := make(map[key]bool, r.TotalFeatures)
+	dropped := 0
+	results := make([]QueryResult, 0, len(r.Results))
+	for _, res := range r.Results {
+		var kept []Feature
+		for i, f := range res.Features {
+			k := key{f.LayerName, f.ID, f.Geometry.hash()}
+			switch {
+			case seen[k]:
+				if kept == nil {
+					kept = append(make([]Feature, 0, len(res.Features)-1), res.Features[:i]...)
+				}
+				dropped++
+			case kept != nil:
+				kept = append(kept, f)
+			}
+			seen[k] = true
+		}
+		if kept != nil {
+			res.Features = kept
+		}
+		if res.HasFeatures() {
+			results = append(results, res)
+		}
+	}
+	r.Results = results
+	r.TotalFeatures -= dropped
+	return dropped
+}
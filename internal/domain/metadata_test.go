@@ -248,6 +248,51 @@ func TestQueryResponseAddResult(t *testing.T) {
 	}
 }
 
+func TestQueryResponseDedupe(t *testing.T) {
+	square := Geometry{WKT: "POLYGON((0 0,1 0,1 1,0 1,0 0))", SRID: 4326}
+	national := []Feature{
+		{ID: 1, LayerName: "districts", Geometry: square},
+		{ID: 2, LayerName: "districts", Geometry: square},
+	}
+	response := &QueryResponse{}
+	response.AddResult(QueryResult{SourceID: "regional", Features: []Feature{{ID: 1, LayerName: "districts", Geometry: square}}})
+	response.AddResult(QueryResult{SourceID: "national", Features: national})
+	response.AddResult(QueryResult{SourceID: "copy", Features: []Feature{
+		{ID: 1, LayerName: "districts", Geometry: square},
+		{ID: 1, LayerName: "districts", Geometry: Geometry{WKT: square.WKT, SRID: 25832}}, // other SRID
+		{ID: 1, LayerName: "parcels", Geometry: square},                                   // other layer
+	}})
+
+	if n := response.Dedupe(); n != 2 {
+		t.Errorf("Dedupe() = %d, want 2", n)
+	}
+	if response.TotalFeatures != 4 {
+		t.Errorf("TotalFeatures = %d, want 4", response.TotalFeatures)
+	}
+	if len(response.Results) != 3 {
+		t.Fatalf("len(Results) = %d, want 3", len(response.Results))
+	}
+	if got := response.Results[1].Features; len(got) != 1 || got[0].ID != 2 {
+		t.Errorf("national features = %+v, want only ID 2", got)
+	}
+	if len(response.Results[2].Features) != 2 {
+		t.Errorf("copy features = %+v, want the other SRID and layer kept", response.Results[2].Features)
+	}
+	// The slices the results were built from are left as they were.
+	if national[0].ID != 1 || national[1].ID != 2 {
+		t.Errorf("source slice modified: %+v", national)
+	}
+
+	// A result whose every feature is a duplicate is dropped.
+	dup := &QueryResponse{}
+	dup.AddResult(QueryResult{SourceID: "a", Features: []Feature{{ID: 1, Geometry: square}}})
+	dup.AddResult(QueryResult{SourceID: "b", Features: []Feature{{ID: 1, Geometry: square}}})
+	dup.Dedupe()
+	if len(dup.Results) != 1 || dup.Results[0].SourceID != "a" {
+		t.Errorf("Results = %+v, want only a", dup.Results)
+	}
+}
+
 func TestQueryResultWithQueryTime(t *testing.T) {
 	result := QueryResult{
 		SourceID:  "test-pkg",
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Property types a PropertyMapping converts values to.
+const (
+	PropertyTypeString  = "string"
+	PropertyTypeInteger = "integer"
+	PropertyTypeNumber  = "number"
+	PropertyTypeBoolean = "boolean"
+	PropertyTypeDate    = "date"
+)
+
+// DefaultDateFormat is the layout dates are formatted with when a mapping
+// does not set one.
+const DefaultDateFormat = "2006-01-02"
+
+// dateLayouts are the layouts text dates are read in: GeoPackage DATE and
+// DATETIME values, and the day-first dates common in German data.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	DefaultDateFormat,
+	"02.01.2006",
+	"20060102",
+}
+
+// PropertyMapping renames one feature property and converts its value, so
+// packages from providers with different schemas answer with the same
+// properties: GEN exposed as name, a text code as an integer.
+type PropertyMapping struct {
+	Column string // property (column) name in the package, compared case-insensitively
+	Name   string // name the property is exposed under; "" keeps Column
+	Type   string // a PropertyType* constant; "" keeps the value as stored
+	Format string // layout (Go reference time) of PropertyTypeDate values
+}
+
+// PropertyMappings are the mappings of a layer's properties.
+type PropertyMappings []PropertyMapping
+
+// ForColumns returns the mapping of each of columns, nil where a column has
+// none, or nil when no column has one. Resolving the columns once per query
+// keeps the per-row work to an index lookup.
+func (m PropertyMappings) ForColumns(columns []string) []*PropertyMapping {
+	var out []*PropertyMapping
+	for i := range m {
+		for j, col := range columns {
+			if !strings.EqualFold(col, m[i].Column) {
+				continue
+			}
+			if out == nil {
+				out = make([]*PropertyMapping, len(columns))
+			}
+			out[j] = &m[i]
+		}
+	}
+	return out
+}
+
+// Rename returns the name the property column is exposed under.
+func (m PropertyMappings) Rename(column string) string {
+	for i := range m {
+		if m[i].Name != "" && strings.EqualFold(m[i].Column, column) {
+			return m[i].Name
+		}
+	}
+	return column
+}
+
+// Map returns the name and value the property column with value v is
+// exposed as. ok is false when v does not convert to the mapping's type; the
+// property is then left out rather than exposed with an unexpected type.
+func (m *PropertyMapping) Map(column string, v interface{}) (name string, value interface{}, ok bool) {
+	name = column
+	if m.Name != "" {
+		name = m.Name
+	}
+	value, ok = m.convert(v)
+	return name, value, ok
+}
+
+func (m *PropertyMapping) convert(v interface{}) (interface{}, bool) {
+	if b, isBytes := v.([]byte); isBytes {
+		v = string(b)
+	}
+	switch m.Type {
+	case PropertyTypeString:
+		return toString(v)
+	case PropertyTypeInteger:
+		return toInteger(v)
+	case PropertyTypeNumber:
+		return toNumber(v)
+	case PropertyTypeBoolean:
+		return toBoolean(v)
+	case PropertyTypeDate:
+		return m.toDate(v)
+	default:
+		return v, true
+	}
+}
+
+func toString(v interface{}) (interface{}, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, true
+	case int64:
+		return strconv.FormatInt(x, 10), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(x), true
+	case time.Time:
+		return x.Format(time.RFC3339), true
+	}
+	return nil, false
+}
+
+func toInteger(v interface{}) (interface{}, bool) {
+	switch x := v.(type) {
+	case int64:
+		return x, true
+	case float64:
+		if x == float64(int64(x)) {
+			return int64(x), true
+		}
+	case bool:
+		if x {
+			return int64(1), true
+		}
+		return int64(0), true
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(x), 10, 64); err == nil {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+func toNumber(v interface{}) (interface{}, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int64:
+		return float64(x), true
+	case string:
+		// Decimal commas are common in German data.
+		if f, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(x), ",", ".", 1), 64); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func toBoolean(v interface{}) (interface{}, bool) {
+	switch x := v.(type) {
+	case bool:
+		return x, true
+	case int64:
+		return x != 0, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(x)) {
+		case "1", "true", "t", "yes", "y", "ja", "j":
+			return true, true
+		case "0", "false", "f", "no", "n", "nein":
+			return false, true
+		}
+	}
+	return nil, false
+}
+
+func (m *PropertyMapping) toDate(v interface{}) (interface{}, bool) {
+	format := m.Format
+	if format == "" {
+		format = DefaultDateFormat
+	}
+	switch x := v.(type) {
+	case time.Time:
+		return x.Format(format), true
+	case string:
+		s := strings.TrimSpace(x)
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.Format(format), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// ComputedProperty is a property derived per feature with a SpatiaLite SQL
+// expression over the layer's columns, e.g. the area of the geometry or a
+// population density, so publishers can enrich results without changing the
+// package.
+type ComputedProperty struct {
+	Name string
+	// Expr is the expression; {geom} in it stands for the layer's geometry,
+	// ready for the spatial functions.
+	Expr string
+}
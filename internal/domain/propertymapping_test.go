@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPropertyMappingMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping PropertyMapping
+		in      interface{}
+		want    interface{}
+		wantOK  bool
+	}{
+		{name: "rename only", mapping: PropertyMapping{Name: "name"}, in: "Mitte", want: "Mitte", wantOK: true},
+		{name: "text to integer", mapping: PropertyMapping{Type: PropertyTypeInteger}, in: " 011 ", want: int64(11), wantOK: true},
+		{name: "bytes to integer", mapping: PropertyMapping{Type: PropertyTypeInteger}, in: []byte("42"), want: int64(42), wantOK: true},
+		{name: "fraction to integer", mapping: PropertyMapping{Type: PropertyTypeInteger}, in: 1.5, wantOK: false},
+		{name: "bad integer", mapping: PropertyMapping{Type: PropertyTypeInteger}, in: "n/a", wantOK: false},
+		{name: "decimal comma", mapping: PropertyMapping{Type: PropertyTypeNumber}, in: "12,5", want: 12.5, wantOK: true},
+		{name: "integer to string", mapping: PropertyMapping{Type: PropertyTypeString}, in: int64(7), want: "7", wantOK: true},
+		{name: "ja to boolean", mapping: PropertyMapping{Type: PropertyTypeBoolean}, in: "Ja", want: true, wantOK: true},
+		{name: "german date", mapping: PropertyMapping{Type: PropertyTypeDate}, in: "01.03.2024", want: "2024-03-01", wantOK: true},
+		{
+			name:    "time with format",
+			mapping: PropertyMapping{Type: PropertyTypeDate, Format: "02.01.2006"},
+			in:      time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+			want:    "01.03.2024",
+			wantOK:  true,
+		},
+		{name: "bad date", mapping: PropertyMapping{Type: PropertyTypeDate}, in: "soon", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, got, ok := tt.mapping.Map("col", tt.in)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("Map(%v) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+			wantName := "col"
+			if tt.mapping.Name != "" {
+				wantName = tt.mapping.Name
+			}
+			if name != wantName {
+				t.Errorf("name = %q, want %q", name, wantName)
+			}
+		})
+	}
+}
+
+func TestPropertyMappingsForColumns(t *testing.T) {
+	if got := (PropertyMappings{{Column: "x"}}).ForColumns([]string{"a", "b"}); got != nil {
+		t.Errorf("ForColumns without a match = %v, want nil", got)
+	}
+	got := PropertyMappings{{Column: "GEN", Name: "name"}}.ForColumns([]string{"fid", "gen"})
+	if len(got) != 2 || got[0] != nil || got[1] == nil || got[1].Name != "name" {
+		t.Errorf("ForColumns = %v, want the mapping at index 1", got)
+	}
+}
+
+func TestSourceOverrideLayerMappingsRenameHeight(t *testing.T) {
+	src := Source{Layers: []Layer{{Name: "buildings", Height: LayerHeight{MinAttribute: "BASE", MaxAttribute: "roof"}}}}
+	SourceOverride{LayerMappings: map[string]PropertyMappings{
+		"Buildings": {{Column: "base", Name: "base_m", Type: PropertyTypeNumber}},
+	}}.Apply(&src)
+
+	l := src.Layers[0]
+	if len(l.PropertyMappings) != 1 {
+		t.Fatalf("PropertyMappings = %v, want the configured one", l.PropertyMappings)
+	}
+	if l.Height != (LayerHeight{MinAttribute: "base_m", MaxAttribute: "roof"}) {
+		t.Errorf("Height = %+v, want the mapped attribute names", l.Height)
+	}
+}
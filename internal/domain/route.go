@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Route is a path given as a line string — a vehicle track, a planned
+// route — to find the features along it. All points share SRID.
+type Route struct {
+	Points []Coordinate
+	SRID   int
+}
+
+// NewRoute creates a route through points in srid, giving each point that
+// SRID.
+func NewRoute(points []Coordinate, srid int) Route {
+	for i := range points {
+		points[i].SRID = srid
+	}
+	return Route{Points: points, SRID: srid}
+}
+
+// Validate checks that the route has at least two points, each valid for the
+// SRID.
+func (r Route) Validate() error {
+	return r.ValidateIn(nil)
+}
+
+// ValidateIn is Validate that also checks the points against bounds (see
+// Coordinate.ValidateIn).
+func (r Route) ValidateIn(bounds SRIDBounds) error {
+	if len(r.Points) < 2 {
+		return &ValidationError{
+			Field:      "route",
+			Value:      len(r.Points),
+			Constraint: ">= 2 points",
+			Message:    "route must have at least 2 points",
+		}
+	}
+	for i, p := range r.Points {
+		if err := p.ValidateIn(bounds); err != nil {
+			var ve *ValidationError
+			if errors.As(err, &ve) {
+				ve.Message = fmt.Sprintf("route point %d: %s", i, ve.Message)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// WKT returns the LINESTRING Well-Known Text of the route.
+func (r Route) WKT() string {
+	var b strings.Builder
+	b.WriteString("LINESTRING")
+	writeWKTPositions(&b, r.Points)
+	return b.String()
+}
+
+// Segments returns the bounding box of each segment of the route, in order.
+// A bbox per segment rather than one for the whole route keeps a long
+// diagonal route from pre-selecting everything in between.
+func (r Route) Segments() []Extent {
+	if len(r.Points) < 2 {
+		return nil
+	}
+	out := make([]Extent, len(r.Points)-1)
+	for i := range out {
+		a, b := r.Points[i], r.Points[i+1]
+		out[i] = Extent{
+			MinX: min(a.X, b.X), MinY: min(a.Y, b.Y),
+			MaxX: max(a.X, b.X), MaxY: max(a.Y, b.Y),
+			SRID: r.SRID,
+		}
+	}
+	return out
+}
+
+// ParseRouteWKT parses a LINESTRING in Well-Known Text ("LINESTRING(x y, x
+// y, ...)"; a Z or M ordinate is ignored) into a route in srid.
+func ParseRouteWKT(wkt string, srid int) (Route, error) {
+	tag, body, ok := splitWKT(wkt)
+	if !ok || tag != "LINESTRING" {
+		return Route{}, routeWKTError(wkt)
+	}
+	points, ok := parseWKTPositions(body)
+	if !ok {
+		return Route{}, routeWKTError(wkt)
+	}
+	return NewRoute(points, srid), nil
+}
+
+// splitWKT splits Well-Known Text into its upper-cased geometry tag, with a
+// Z, M or ZM suffix dropped, and the text between the outer parentheses.
+func splitWKT(wkt string) (tag, body string, ok bool) {
+	s := strings.TrimSpace(wkt)
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return "", "", false
+	}
+	fields := strings.Fields(strings.ToUpper(s[:open]))
+	switch {
+	case len(fields) == 1:
+	case len(fields) == 2 && (fields[1] == "Z" || fields[1] == "M" || fields[1] == "ZM"):
+	default:
+		return "", "", false
+	}
+	return fields[0], s[open+1 : len(s)-1], true
+}
+
+// parseWKTPositions parses a comma-separated WKT position list ("x y, x y");
+// a Z or M ordinate is ignored.
+func parseWKTPositions(s string) ([]Coordinate, bool) {
+	parts := strings.Split(s, ",")
+	points := make([]Coordinate, 0, len(parts))
+	for _, part := range parts {
+		fields := strings.Fields(part)
+		if len(fields) < 2 || len(fields) > 4 {
+			return nil, false
+		}
+		x, errX := strconv.ParseFloat(fields[0], 64)
+		y, errY := strconv.ParseFloat(fields[1], 64)
+		if errX != nil || errY != nil {
+			return nil, false
+		}
+		points = append(points, Coordinate{X: x, Y: y})
+	}
+	return points, true
+}
+
+// writeWKTPositions writes points as a parenthesized WKT position list.
+func writeWKTPositions(b *strings.Builder, points []Coordinate) {
+	b.WriteByte('(')
+	for i, p := range points {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.FormatFloat(p.X, 'f', -1, 64))
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(p.Y, 'f', -1, 64))
+	}
+	b.WriteByte(')')
+}
+
+func routeWKTError(wkt string) error {
+	return &ValidationError{
+		Field:      "route",
+		Value:      wkt,
+		Constraint: "LINESTRING(x y, ...)",
+		Message:    "route must be a WKT LINESTRING",
+	}
+}
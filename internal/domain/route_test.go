@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseRouteWKT(t *testing.T) {
+	tests := []struct {
+		name    string
+		wkt     string
+		want    int
+		wantErr bool
+	}{
+		{"plain", "LINESTRING(8.68 50.11, 8.80 50.05, 9.01 49.99)", 3, false},
+		{"lowercase and spaces", "  linestring ( 1 2 , 3 4 )  ", 2, false},
+		{"z ordinate ignored", "LINESTRING Z(1 2 3, 4 5 6)", 2, false},
+		{"point", "POINT(1 2)", 0, true},
+		{"no parentheses", "LINESTRING 1 2, 3 4", 0, true},
+		{"one ordinate", "LINESTRING(1, 3 4)", 0, true},
+		{"not a number", "LINESTRING(a 2, 3 4)", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := ParseRouteWKT(tt.wkt, SRIDWGS84)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRouteWKT(%q) error = %v, wantErr %v", tt.wkt, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Errorf("error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if len(route.Points) != tt.want {
+				t.Errorf("points = %d, want %d", len(route.Points), tt.want)
+			}
+			for _, p := range route.Points {
+				if p.SRID != SRIDWGS84 {
+					t.Errorf("point SRID = %d, want %d", p.SRID, SRIDWGS84)
+				}
+			}
+		})
+	}
+}
+
+func TestRouteValidate(t *testing.T) {
+	short := NewRoute([]Coordinate{{X: 1, Y: 2}}, SRIDWGS84)
+	if err := short.Validate(); err == nil {
+		t.Error("route with one point should not validate")
+	}
+
+	bad := NewRoute([]Coordinate{{X: 1, Y: 2}, {X: 200, Y: 2}}, SRIDWGS84)
+	err := bad.Validate()
+	if err == nil {
+		t.Fatal("route with an out-of-range longitude should not validate")
+	}
+	if !strings.Contains(err.Error(), "route point 1") {
+		t.Errorf("error = %q, want it to name route point 1", err)
+	}
+
+	ok := NewRoute([]Coordinate{{X: 1, Y: 2}, {X: 3, Y: 4}}, SRIDWGS84)
+	if err := ok.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestRouteSegmentsAndWKT(t *testing.T) {
+	route := NewRoute([]Coordinate{{X: 0, Y: 0}, {X: 4, Y: 2}, {X: 1, Y: 5}}, SRIDWGS84)
+
+	segs := route.Segments()
+	if len(segs) != 2 {
+		t.Fatalf("segments = %d, want 2", len(segs))
+	}
+	if s := segs[1]; s.MinX != 1 || s.MinY != 2 || s.MaxX != 4 || s.MaxY != 5 {
+		t.Errorf("segment 1 = %+v, want (1,2)-(4,5)", s)
+	}
+
+	if got, want := route.WKT(), "LINESTRING(0 0, 4 2, 1 5)"; got != want {
+		t.Errorf("WKT() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,251 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression in the standard five-field form
+// "minute hour day-of-month month day-of-week", e.g. "0 3 * * *" for daily at
+// 03:00. Fields take *, numbers, ranges (1-5), steps (*/15, 8-18/2) and
+// comma-separated lists of them; months and weekdays also take their
+// three-letter English names, and Sunday is 0 or 7. As in cron, when both
+// day fields are restricted a day matching either one matches.
+type Schedule struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64 // bit n set: value n matches
+	domRestricted, dowRestricted  bool
+}
+
+var (
+	monthNames   = map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6, "jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}
+	weekdayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+)
+
+// ParseSchedule parses a five-field cron expression.
+func ParseSchedule(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q: want 5 fields (minute hour day month weekday), got %d", expr, len(fields))
+	}
+	s := &Schedule{expr: strings.Join(fields, " ")}
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("cron expression %q: minute: %w", expr, err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("cron expression %q: hour: %w", expr, err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("cron expression %q: day of month: %w", expr, err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("cron expression %q: month: %w", expr, err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7, weekdayNames); err != nil {
+		return nil, fmt.Errorf("cron expression %q: day of week: %w", expr, err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1 // 7 is Sunday too
+	}
+	s.domRestricted = !strings.HasPrefix(fields[2], "*")
+	s.dowRestricted = !strings.HasPrefix(fields[4], "*")
+	if s.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("cron expression %q never matches", expr)
+	}
+	return s, nil
+}
+
+// parseCronField parses one field into a bit set of the values in [lo, hi]
+// it matches.
+func parseCronField(field string, lo, hi int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rng, step = part[:i], n
+		}
+		from, to := lo, hi
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if from, err = cronValue(a, lo, hi, names); err != nil {
+				return 0, err
+			}
+			if to, err = cronValue(b, lo, hi, names); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, fmt.Errorf("range %q runs backwards", rng)
+			}
+		default:
+			v, err := cronValue(rng, lo, hi, names)
+			if err != nil {
+				return 0, err
+			}
+			from = v
+			if step == 1 {
+				to = v // a single value; with a step, "5/15" runs to hi
+			}
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// cronValue parses a number or name within [lo, hi].
+func cronValue(s string, lo, hi int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < lo || v > hi {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, lo, hi)
+	}
+	return v, nil
+}
+
+// String returns the cron expression.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first time after t, to the minute and in t's location,
+// that the schedule matches; the zero time when none does within five
+// years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's day rule: with both day fields restricted,
+// either matching is enough.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// TimeWindow is a recurring span of the day, optionally limited to some
+// weekdays: "08:00-18:00" every day, "Mon-Fri 07:30-19:00" on workdays. A
+// window whose end is not after its start runs past midnight into the next
+// day ("22:00-02:00"); its weekdays name the day it starts on. Times are in
+// the location of the time tested.
+type TimeWindow struct {
+	expr       string
+	days       uint64 // bit n set: the window starts on weekday n
+	start, end int    // minutes since midnight; end may be 24:00
+}
+
+// ParseTimeWindow parses "[weekdays ]HH:MM-HH:MM", weekdays given like the
+// day-of-week field of a cron expression.
+func ParseTimeWindow(expr string) (TimeWindow, error) {
+	w := TimeWindow{expr: strings.TrimSpace(expr), days: 0x7f}
+	span := w.expr
+	if days, rest, ok := strings.Cut(w.expr, " "); ok {
+		bits, err := parseCronField(days, 0, 7, weekdayNames)
+		if err != nil {
+			return TimeWindow{}, fmt.Errorf("time window %q: weekdays: %w", expr, err)
+		}
+		if bits&(1<<7) != 0 {
+			bits |= 1
+		}
+		w.days, span = bits&0x7f, strings.TrimSpace(rest)
+	}
+	from, to, ok := strings.Cut(span, "-")
+	if !ok {
+		return TimeWindow{}, fmt.Errorf("time window %q: want HH:MM-HH:MM", expr)
+	}
+	var err error
+	if w.start, err = clockMinutes(from); err != nil {
+		return TimeWindow{}, fmt.Errorf("time window %q: %w", expr, err)
+	}
+	if w.end, err = clockMinutes(to); err != nil {
+		return TimeWindow{}, fmt.Errorf("time window %q: %w", expr, err)
+	}
+	if w.start == w.end || w.start == 24*60 {
+		return TimeWindow{}, fmt.Errorf("time window %q is empty", expr)
+	}
+	return w, nil
+}
+
+// clockMinutes parses HH:MM (up to 24:00) into minutes since midnight.
+func clockMinutes(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err == nil {
+		return t.Hour()*60 + t.Minute(), nil
+	}
+	if s == "24:00" {
+		return 24 * 60, nil
+	}
+	return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+}
+
+// String returns the window as configured.
+func (w TimeWindow) String() string {
+	return w.expr
+}
+
+// Contains reports whether t falls inside the window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	_, ok := w.End(t)
+	return ok
+}
+
+// End returns the time the occurrence of the window containing t ends; ok
+// is false when t is outside the window.
+func (w TimeWindow) End(t time.Time) (end time.Time, ok bool) {
+	m := t.Hour()*60 + t.Minute()
+	// at is the clock time minute on day offset days from t's.
+	at := func(offset, minute int) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day()+offset, 0, minute, 0, 0, t.Location())
+	}
+	startsOn := func(offset int) bool { return w.days&(1<<uint(at(offset, 0).Weekday())) != 0 }
+	if w.start < w.end {
+		if startsOn(0) && m >= w.start && m < w.end {
+			return at(0, w.end), true
+		}
+		return time.Time{}, false
+	}
+	// Past midnight: the evening part belongs to today's window, the
+	// morning part to yesterday's.
+	if startsOn(0) && m >= w.start {
+		return at(1, w.end), true
+	}
+	if startsOn(-1) && m < w.end {
+		return at(0, w.end), true
+	}
+	return time.Time{}, false
+}
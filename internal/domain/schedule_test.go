@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// 2026-03-04 is a Wednesday.
+	from := time.Date(2026, 3, 4, 10, 17, 42, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 4, 10, 18, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, 3, 5, 3, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)},
+		{"5/20 8-18/2 * * *", time.Date(2026, 3, 4, 10, 25, 0, 0, time.UTC)},
+		{"0 3 * * sat,sun", time.Date(2026, 3, 7, 3, 0, 0, 0, time.UTC)},
+		{"0 3 * * 7", time.Date(2026, 3, 8, 3, 0, 0, 0, time.UTC)},
+		{"30 1 1 * *", time.Date(2026, 4, 1, 1, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches (the 15th or a Friday).
+		{"0 0 15 * fri", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseSchedule: %v", err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 3 * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"x * * * *",
+		"0 0 30 2 *", // never
+	} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q) = nil error, want one", expr)
+		}
+	}
+}
+
+func TestTimeWindow(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC) // the 2nd is a Monday
+	}
+	tests := []struct {
+		expr    string
+		t       time.Time
+		wantEnd time.Time // zero: outside
+	}{
+		{"08:00-18:00", at(2, 8, 0), at(2, 18, 0)},
+		{"08:00-18:00", at(2, 17, 59), at(2, 18, 0)},
+		{"08:00-18:00", at(2, 18, 0), time.Time{}},
+		{"08:00-18:00", at(2, 7, 59), time.Time{}},
+		{"Mon-Fri 08:00-18:00", at(6, 12, 0), at(6, 18, 0)},
+		{"Mon-Fri 08:00-18:00", at(7, 12, 0), time.Time{}}, // Saturday
+		{"sat,sun 00:00-24:00", at(8, 23, 59), at(9, 0, 0)},
+		{"22:00-02:00", at(2, 23, 0), at(3, 2, 0)},
+		{"22:00-02:00", at(3, 1, 0), at(3, 2, 0)},
+		{"22:00-02:00", at(3, 2, 0), time.Time{}},
+		// Friday night's window runs into Saturday; Saturday's doesn't start.
+		{"Fri 22:00-02:00", at(7, 1, 0), at(7, 2, 0)},
+		{"Fri 22:00-02:00", at(7, 23, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		w, err := ParseTimeWindow(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseTimeWindow(%q): %v", tt.expr, err)
+		}
+		end, ok := w.End(tt.t)
+		if ok != !tt.wantEnd.IsZero() || !end.Equal(tt.wantEnd) {
+			t.Errorf("%q.End(%v) = %v, %v; want %v", tt.expr, tt.t, end, ok, tt.wantEnd)
+		}
+		if w.Contains(tt.t) != ok {
+			t.Errorf("%q.Contains(%v) disagrees with End", tt.expr, tt.t)
+		}
+	}
+}
+
+func TestParseTimeWindowInvalid(t *testing.T) {
+	for _, expr := range []string{"", "08:00", "8-18", "08:00-08:00", "24:00-02:00", "Mon-Funday 08:00-18:00", "08:00-25:00"} {
+		if _, err := ParseTimeWindow(expr); err == nil {
+			t.Errorf("ParseTimeWindow(%q) = nil error, want one", expr)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package domain
 
-import "time"
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // SourceKind identifies the kind of spatial data source backing a Source.
 type SourceKind string
@@ -13,6 +19,20 @@ const (
 	SourceKindRaster SourceKind = "raster"
 )
 
+// LoadMode selects how an adapter reads a source's file.
+type LoadMode string
+
+// Load mode constants.
+const (
+	// LoadModeFile reads the file through ordinary I/O (the default).
+	LoadModeFile LoadMode = ""
+	// LoadModeMmap memory-maps the whole file.
+	LoadModeMmap LoadMode = "mmap"
+	// LoadModeMemory copies the file into memory when it is opened; later
+	// changes to the file only show after a reload.
+	LoadModeMemory LoadMode = "memory"
+)
+
 // Source represents a registered spatial data source — a GeoPackage (vector)
 // or a raster bundle (raster). It is the common currency of the registry and
 // query service; the Kind field discriminates the backing adapter.
@@ -23,8 +43,11 @@ type Source struct {
 	Kind        SourceKind // Backing source kind (vector or raster)
 	Size        int64      // File size in bytes
 	Layers      []Layer    // Feature layers
+	TileSets    []TileSet  // Tile pyramids (GeoPackage 'tiles' tables)
 	Metadata    Metadata   // Source metadata
 	License     License    // License information
+	Tags        []string   // Operator-assigned tags (packages.<id>.tags)
+	Priority    int        // Query and result order; higher first, ties by ID (packages.<id>.priority)
 	Indexed     bool       // Are all spatial indices created / is the source prepared?
 	LoadedAt    time.Time  // Load timestamp
 	LastQueried time.Time  // Last query timestamp
@@ -58,6 +81,158 @@ func (s *Source) GetLayer(name string) (*Layer, bool) {
 	return nil, false
 }
 
+// SourceOverride holds operator-configured presentation values for a source
+// that replace what the adapter derived from the file name or package
+// metadata. Empty fields leave the derived value alone.
+type SourceOverride struct {
+	Name        string   // display name
+	License     string   // license name
+	LicenseURL  string   // link to the license text
+	Attribution string   // attribution text
+	Tags        []string // tags shown with the source
+	// IncludeLayers, when set, keeps only the layers matching one of its
+	// patterns; ExcludeLayers then drops the matching ones. Patterns are
+	// path.Match globs, compared case-insensitively like SQLite table names.
+	IncludeLayers []string
+	ExcludeLayers []string
+	// LayerProperties restricts the properties of the layers it names, keyed
+	// by layer name, compared case-insensitively.
+	LayerProperties map[string]PropertyPolicy
+	// LayerMappings renames and converts the properties of the layers it
+	// names, keyed like LayerProperties.
+	LayerMappings map[string]PropertyMappings
+	// LayerComputed adds computed properties to the layers it names, keyed
+	// like LayerProperties.
+	LayerComputed map[string][]ComputedProperty
+	// LoadMode is how the source's file is read. It takes effect when the
+	// source is opened, not through Apply.
+	LoadMode LoadMode
+	// Priority orders the source among the others; LayerPriorities orders
+	// the layers it names within the source, keyed like LayerProperties.
+	// Higher comes first; unset is 0.
+	Priority        int
+	LayerPriorities map[string]int
+	// Pin holds the source to one stored version of its file. It takes
+	// effect in sync, not through Apply.
+	Pin Pin
+}
+
+// Pin holds a source to one stored version of its file: the object with
+// ETag, or version VersionID on storage that keeps versions (S3 bucket
+// versioning). Sync never replaces or removes a pinned source; a change
+// in storage is reported and left alone.
+type Pin struct {
+	ETag      string
+	VersionID string
+}
+
+// IsZero reports whether the pin is unset.
+func (p Pin) IsZero() bool {
+	return p.ETag == "" && p.VersionID == ""
+}
+
+// Matches reports whether an object with etag satisfies an ETag pin. The
+// quotes S3 and HTTP put around ETags are ignored.
+func (p Pin) Matches(etag string) bool {
+	return strings.Trim(p.ETag, `"`) == strings.Trim(etag, `"`)
+}
+
+// Apply writes the non-empty override fields onto src.
+func (o SourceOverride) Apply(src *Source) {
+	if o.Name != "" {
+		src.Name = o.Name
+	}
+	if o.License != "" {
+		src.License.Name = o.License
+	}
+	if o.LicenseURL != "" {
+		src.License.URL = o.LicenseURL
+	}
+	if o.Attribution != "" {
+		src.License.Attribution = o.Attribution
+	}
+	if len(o.Tags) > 0 {
+		src.Tags = append([]string(nil), o.Tags...)
+	}
+	src.Priority = o.Priority
+	if len(o.IncludeLayers) > 0 || len(o.ExcludeLayers) > 0 {
+		var kept []Layer
+		for _, l := range src.Layers {
+			if o.KeepsLayer(l.Name) {
+				kept = append(kept, l)
+			}
+		}
+		src.Layers = kept
+	}
+	o.applyLayerSettings(src)
+}
+
+// applyLayerSettings writes the per-layer property settings onto the layers
+// of src they name.
+func (o SourceOverride) applyLayerSettings(src *Source) {
+	for name, policy := range o.LayerProperties {
+		if l := src.layerFold(name); l != nil {
+			l.PropertyPolicy = policy
+		}
+	}
+	for name, mappings := range o.LayerMappings {
+		if l := src.layerFold(name); l != nil {
+			l.PropertyMappings = mappings
+			// The height attributes are read off the mapped properties.
+			l.Height.MinAttribute = mappings.Rename(l.Height.MinAttribute)
+			l.Height.MaxAttribute = mappings.Rename(l.Height.MaxAttribute)
+		}
+	}
+	for name, computed := range o.LayerComputed {
+		if l := src.layerFold(name); l != nil {
+			l.Computed = computed
+		}
+	}
+	for name, priority := range o.LayerPriorities {
+		if l := src.layerFold(name); l != nil {
+			l.Priority = priority
+		}
+	}
+	src.SortLayers()
+}
+
+// SortLayers orders the layers by Priority, higher first; layers of equal
+// priority keep the order the package lists them in.
+func (s *Source) SortLayers() {
+	sort.SliceStable(s.Layers, func(i, j int) bool {
+		return s.Layers[i].Priority > s.Layers[j].Priority
+	})
+}
+
+// layerFold returns the layer called name, compared case-insensitively like
+// SQLite table names, or nil.
+func (s *Source) layerFold(name string) *Layer {
+	for i := range s.Layers {
+		if strings.EqualFold(s.Layers[i].Name, name) {
+			return &s.Layers[i]
+		}
+	}
+	return nil
+}
+
+// KeepsLayer reports whether the layer filters let the layer name through.
+func (o SourceOverride) KeepsLayer(name string) bool {
+	if len(o.IncludeLayers) > 0 && !matchesLayer(o.IncludeLayers, name) {
+		return false
+	}
+	return !matchesLayer(o.ExcludeLayers, name)
+}
+
+func matchesLayer(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // Layer represents a queryable layer within a Source: a vector feature table
 // (GeoPackage) or, for raster sources, a raster layer/band.
 type Layer struct {
@@ -69,6 +244,148 @@ type Layer struct {
 	HasIndex       bool    // Has spatial index?
 	FeatureCount   int64   // Number of features
 	Extent         *Extent // Bounding box (optional)
+	// Notes are caveats the publisher attached to the layer (accuracy,
+	// vintage, usage restrictions). They travel with every result the layer
+	// contributes features to.
+	Notes []string
+	// SRIDWarning says why the declared SRID is suspect (undefined, 0/-1, or
+	// missing from the package's spatial reference table); "" when it checks
+	// out. Such a layer typically answers every query with no features.
+	SRIDWarning string
+	// SRIDAssumed is set when SRID is a configured fallback standing in for
+	// an undefined DeclaredSRID, which the stored geometries still carry.
+	SRIDAssumed  bool
+	DeclaredSRID int
+	// Geographic is set when the layer CRS is geographic, its coordinates
+	// degrees rather than metres or feet.
+	Geographic bool
+	// InvalidGeometries counts the geometries ST_IsValid rejects, when
+	// ValidityChecked says the load-time validity check ran. Spatial
+	// predicates fail on invalid polygons, so such features typically match
+	// no query.
+	InvalidGeometries int64
+	ValidityChecked   bool
+	// Height names the attributes holding the vertical extent of 3D
+	// features; a query with a Z keeps only the features whose range
+	// spans it. The zero value applies no height filter.
+	Height LayerHeight
+	// PropertyPolicy limits the properties the layer's features expose,
+	// whatever properties a client asks for. It refers to the properties by
+	// the names PropertyMappings gives them.
+	PropertyPolicy PropertyPolicy
+	// Priority orders the layer among the layers of its source, higher
+	// first (packages.<id>.layers.<name>.priority).
+	Priority int
+	// PropertyMappings rename and convert properties as the features are
+	// read.
+	PropertyMappings PropertyMappings
+	// Computed are properties computed per feature and added to the stored
+	// ones.
+	Computed []ComputedProperty
+}
+
+// PropertyPolicy restricts the feature properties a layer exposes, e.g. to
+// keep personal data in a package from ever leaving the server. Names are
+// compared case-insensitively, like SQLite column names. The zero value
+// exposes every property.
+type PropertyPolicy struct {
+	Expose []string // only these properties are exposed; empty exposes all
+	Redact []string // never exposed, even when listed in Expose
+}
+
+// IsZero reports whether the policy exposes every property.
+func (p PropertyPolicy) IsZero() bool {
+	return len(p.Expose) == 0 && len(p.Redact) == 0
+}
+
+// Allows reports whether the property name may be exposed.
+func (p PropertyPolicy) Allows(name string) bool {
+	if containsFold(p.Redact, name) {
+		return false
+	}
+	return len(p.Expose) == 0 || containsFold(p.Expose, name)
+}
+
+// Apply removes the properties the policy does not allow from each feature.
+// The property maps are copied rather than modified in place.
+func (p PropertyPolicy) Apply(features []Feature) {
+	if p.IsZero() {
+		return
+	}
+	for i := range features {
+		kept := make(map[string]interface{}, len(features[i].Properties))
+		for k, v := range features[i].Properties {
+			if p.Allows(k) {
+				kept[k] = v
+			}
+		}
+		features[i].Properties = kept
+	}
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// LayerHeight names the feature attributes holding the bottom and top of a
+// feature (e.g. a building's base and roof height), in the unit of the query
+// Z. Either may be empty, leaving the range open on that side.
+type LayerHeight struct {
+	MinAttribute string
+	MaxAttribute string
+}
+
+// IsZero reports whether no height attribute is configured.
+func (h LayerHeight) IsZero() bool {
+	return h.MinAttribute == "" && h.MaxAttribute == ""
+}
+
+// Spans reports whether the feature properties place z within the height
+// range. A missing or non-numeric value leaves its side of the range open,
+// so features without height data are never filtered out.
+func (h LayerHeight) Spans(props map[string]interface{}, z float64) bool {
+	if v, ok := heightValue(props, h.MinAttribute); ok && z < v {
+		return false
+	}
+	if v, ok := heightValue(props, h.MaxAttribute); ok && z > v {
+		return false
+	}
+	return true
+}
+
+func heightValue(props map[string]interface{}, attr string) (float64, bool) {
+	if attr == "" {
+		return 0, false
+	}
+	switch v := props[attr].(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case []byte:
+		f, err := strconv.ParseFloat(strings.TrimSpace(string(v)), 64)
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// StoredSRID returns the SRID the layer's stored geometries carry: the
+// declared one when a fallback was assumed, SRID otherwise.
+func (l *Layer) StoredSRID() int {
+	if l.SRIDAssumed {
+		return l.DeclaredSRID
+	}
+	return l.SRID
 }
 
 // IsPointLayer returns true if the layer contains point geometries.
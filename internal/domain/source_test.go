@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -187,6 +190,36 @@ func TestLayerIsLineLayer(t *testing.T) {
 	}
 }
 
+func TestLayerHeightSpans(t *testing.T) {
+	h := LayerHeight{MinAttribute: "base", MaxAttribute: "roof"}
+	props := map[string]interface{}{"base": int64(34), "roof": 61.5}
+
+	tests := []struct {
+		name  string
+		h     LayerHeight
+		props map[string]interface{}
+		z     float64
+		want  bool
+	}{
+		{"inside", h, props, 40, true},
+		{"at base", h, props, 34, true},
+		{"below base", h, props, 33.9, false},
+		{"above roof", h, props, 62, false},
+		{"text values", h, map[string]interface{}{"base": "34", "roof": []byte("61.5")}, 70, false},
+		{"missing roof", h, map[string]interface{}{"base": int64(34)}, 1000, true},
+		{"null base", h, map[string]interface{}{"base": nil, "roof": 61.5}, -5, true},
+		{"max only", LayerHeight{MaxAttribute: "roof"}, props, 10, true},
+		{"unset", LayerHeight{}, props, 1000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.h.Spans(tt.props, tt.z); got != tt.want {
+				t.Errorf("Spans(%v) = %v, want %v", tt.z, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSourceStatus(t *testing.T) {
 	// Test all status constants are defined and unique
 	statuses := []SourceStatus{
@@ -272,3 +305,132 @@ func TestLayerExtent(t *testing.T) {
 		t.Error("Extent should not contain coordinate (0, 50)")
 	}
 }
+
+func TestSourceOverrideApply(t *testing.T) {
+	src := Source{
+		Name:    "abc_2024_v3",
+		License: License{Name: "ODbL", URL: "https://example.com/odbl", Attribution: "from metadata"},
+	}
+	SourceOverride{Name: "Districts", Attribution: "© Example", Tags: []string{"admin"}}.Apply(&src)
+
+	if src.Name != "Districts" {
+		t.Errorf("Name = %q, want Districts", src.Name)
+	}
+	// Empty override fields keep the derived values.
+	want := License{Name: "ODbL", URL: "https://example.com/odbl", Attribution: "© Example"}
+	if src.License != want {
+		t.Errorf("License = %+v, want %+v", src.License, want)
+	}
+	if len(src.Tags) != 1 || src.Tags[0] != "admin" {
+		t.Errorf("Tags = %v, want [admin]", src.Tags)
+	}
+}
+
+func TestSourceOverrideLayerFilters(t *testing.T) {
+	layers := func() []Layer {
+		return []Layer{{Name: "parcels"}, {Name: "QA_parcels"}, {Name: "buildings"}, {Name: "staging_buildings"}}
+	}
+	names := func(src Source) []string {
+		var out []string
+		for _, l := range src.Layers {
+			out = append(out, l.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		override SourceOverride
+		want     []string
+	}{
+		{"no filters", SourceOverride{Name: "x"}, []string{"parcels", "QA_parcels", "buildings", "staging_buildings"}},
+		{"exclude glob", SourceOverride{ExcludeLayers: []string{"qa_*", "staging_*"}}, []string{"parcels", "buildings"}},
+		{"include", SourceOverride{IncludeLayers: []string{"Parcels", "buildings"}}, []string{"parcels", "buildings"}},
+		{"include then exclude", SourceOverride{IncludeLayers: []string{"*parcels"}, ExcludeLayers: []string{"qa_*"}}, []string{"parcels"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := Source{Layers: layers()}
+			tt.override.Apply(&src)
+			if got := names(src); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("layers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceOverrideLayerProperties(t *testing.T) {
+	src := Source{Layers: []Layer{{Name: "Flurstuecke"}, {Name: "buildings"}}}
+	policy := PropertyPolicy{Redact: []string{"owner"}}
+	SourceOverride{LayerProperties: map[string]PropertyPolicy{"flurstuecke": policy}}.Apply(&src)
+
+	if got := src.Layers[0].PropertyPolicy; len(got.Redact) != 1 {
+		t.Errorf("Flurstuecke policy = %+v, want the configured one (names compare case-insensitively)", got)
+	}
+	if !src.Layers[1].PropertyPolicy.IsZero() {
+		t.Errorf("buildings policy = %+v, want none", src.Layers[1].PropertyPolicy)
+	}
+}
+
+func TestSourceOverrideLayerPriorities(t *testing.T) {
+	src := Source{Layers: []Layer{{Name: "a"}, {Name: "b"}, {Name: "Parcels"}, {Name: "c"}}}
+	SourceOverride{Priority: 3, LayerPriorities: map[string]int{"parcels": 5, "c": -1}}.Apply(&src)
+
+	if src.Priority != 3 {
+		t.Errorf("Priority = %d, want 3", src.Priority)
+	}
+	// Higher first; equal priorities keep package order.
+	var got []string
+	for _, l := range src.Layers {
+		got = append(got, l.Name)
+	}
+	if strings.Join(got, ",") != "Parcels,a,b,c" {
+		t.Errorf("layers = %v, want [Parcels a b c]", got)
+	}
+}
+
+func TestPropertyPolicyApply(t *testing.T) {
+	features := func() []Feature {
+		return []Feature{{Properties: map[string]interface{}{"id": 1, "Area": 12.5, "owner": "Jane Doe", "note": "x"}}}
+	}
+	tests := []struct {
+		name   string
+		policy PropertyPolicy
+		want   []string
+	}{
+		{name: "zero", policy: PropertyPolicy{}, want: []string{"Area", "id", "note", "owner"}},
+		{name: "expose", policy: PropertyPolicy{Expose: []string{"id", "area"}}, want: []string{"Area", "id"}},
+		{name: "redact", policy: PropertyPolicy{Redact: []string{"OWNER"}}, want: []string{"Area", "id", "note"}},
+		{name: "redact wins", policy: PropertyPolicy{Expose: []string{"id", "owner"}, Redact: []string{"owner"}}, want: []string{"id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := features()
+			original := fs[0].Properties
+			tt.policy.Apply(fs)
+			var got []string
+			for k := range fs[0].Properties {
+				got = append(got, k)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("properties = %v, want %v", got, tt.want)
+			}
+			if len(original) != 4 {
+				t.Error("Apply modified the original property map")
+			}
+		})
+	}
+}
+
+func TestPinMatches(t *testing.T) {
+	pin := Pin{ETag: "9b2cf535"}
+	for etag, want := range map[string]bool{"9b2cf535": true, `"9b2cf535"`: true, "9b2cf536": false, "": false} {
+		if got := pin.Matches(etag); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", etag, got, want)
+		}
+	}
+	if (Pin{}).IsZero() != true || pin.IsZero() || (Pin{VersionID: "v1"}).IsZero() {
+		t.Error("IsZero wrong")
+	}
+}
@@ -1,7 +1,10 @@
 package domain
 
 import (
+	"fmt"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -47,3 +50,192 @@ func IsSupportedSourceFile(name string) bool {
 	}
 	return false
 }
+
+// compressedGeoPackageExtensions are the archive suffixes a remote GeoPackage
+// may carry; storage decompresses them on download.
+var compressedGeoPackageExtensions = []string{extGeoPackage + ".gz", extGeoPackage + ".zip"}
+
+// DecompressedSourceKey returns the key a compressed GeoPackage object
+// ("parcels.gpkg.gz", "parcels.gpkg.zip") is served under once unpacked
+// ("parcels.gpkg"), and false for any other key.
+func DecompressedSourceKey(key string) (string, bool) {
+	n := strings.ToLower(key)
+	for _, ext := range compressedGeoPackageExtensions {
+		if strings.HasSuffix(n, ext) && len(n) > len(ext) {
+			return key[:len(key)-len(ext)+len(extGeoPackage)], true
+		}
+	}
+	return "", false
+}
+
+// IsStoredSourceFile reports whether a remote storage object holds a source:
+// a supported file or a compressed GeoPackage. Remote backends list with it.
+func IsStoredSourceFile(name string) bool {
+	_, compressed := DecompressedSourceKey(name)
+	return compressed || IsSupportedSourceFile(name)
+}
+
+// MatchKeyGlob reports whether an object key matches pattern. Both are split
+// at "/" (or the OS separator in key); each pattern segment is a path.Match
+// glob, and a "**" segment matches any number of key segments, including
+// none — so "**/tmp/**" matches "tmp/a.gpkg" and "eu/tmp/b/c.gpkg".
+func MatchKeyGlob(pattern, key string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(filepath.ToSlash(key), "/"))
+}
+
+func matchSegments(pattern, key []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(key); i++ {
+				if matchSegments(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(key) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], key[0]); !ok {
+			return false
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return len(key) == 0
+}
+
+// IsGeoPackageFile reports whether a filename/key has the GeoPackage
+// extension (case-insensitive).
+func IsGeoPackageFile(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), extGeoPackage)
+}
+
+// EnsureGeoPackageExt returns name with the GeoPackage extension appended
+// unless it already has it — for keys made up from URLs that carry no file
+// extension (e.g. a catalog download endpoint). A compressed GeoPackage name
+// is kept as is.
+func EnsureGeoPackageExt(name string) string {
+	if _, compressed := DecompressedSourceKey(name); compressed || IsGeoPackageFile(name) {
+		return name
+	}
+	return name + extGeoPackage
+}
+
+// Sidecar metadata suffixes, tried in this order.
+var sidecarSuffixes = []string{".meta.yaml", ".meta.json"}
+
+// SidecarNames returns the names of the optional metadata sidecars of a
+// GeoPackage ("parcels.gpkg" → "parcels.meta.yaml", "parcels.meta.json"), in
+// lookup order. Works for object keys and local paths alike; nil for anything
+// that is not a GeoPackage.
+func SidecarNames(name string) []string {
+	if !IsGeoPackageFile(name) {
+		return nil
+	}
+	stem := name[:len(name)-len(extGeoPackage)]
+	out := make([]string, len(sidecarSuffixes))
+	for i, suffix := range sidecarSuffixes {
+		out[i] = stem + suffix
+	}
+	return out
+}
+
+// SourceIDStrategy selects how a source id is derived from its object key.
+type SourceIDStrategy string
+
+// Source id derivation strategies.
+const (
+	// SourceIDFilename uses the filename stem ("eu/2024/parcels.gpkg" →
+	// "parcels"). The default.
+	SourceIDFilename SourceIDStrategy = "filename"
+	// SourceIDPrefixed keeps the key's directories in front of the stem
+	// ("eu/2024/parcels.gpkg" → "eu.2024.parcels").
+	SourceIDPrefixed SourceIDStrategy = "prefixed"
+	// SourceIDRegex matches the key against a pattern and expands a template
+	// from its capture groups; keys that do not match fall back to the stem.
+	SourceIDRegex SourceIDStrategy = "regex"
+	// SourceIDMetadata uses the identifier the package declares in its own
+	// metadata; packages without one fall back to the stem. Reading the
+	// identifier is adapter work, so Derive alone yields the fallback.
+	SourceIDMetadata SourceIDStrategy = "metadata"
+)
+
+// sourceIDSeparator joins key segments into one id. Ids appear as a single
+// URL path segment (/api/v1/sources/{id}), so they never contain a slash.
+const sourceIDSeparator = "."
+
+// SanitizeSourceID makes id a single URL path segment by joining its
+// slash-separated parts with the id separator ("eu/roads" → "eu.roads"). Ids
+// not built from the key — a regex template's expansion, a package's declared
+// identifier — go through it.
+func SanitizeSourceID(id string) string {
+	return strings.ReplaceAll(id, "/", sourceIDSeparator)
+}
+
+// SourceIDDeriver maps object keys (paths relative to the storage root) to
+// source ids according to a strategy. The zero value derives filename stems,
+// exactly like DeriveSourceID.
+type SourceIDDeriver struct {
+	strategy SourceIDStrategy
+	pattern  *regexp.Regexp
+	template string
+}
+
+// NewSourceIDDeriver builds a deriver for strategy. pattern and template are
+// only used (and then required) by SourceIDRegex. An empty strategy selects
+// SourceIDFilename.
+func NewSourceIDDeriver(strategy SourceIDStrategy, pattern, template string) (SourceIDDeriver, error) {
+	switch strategy {
+	case "", SourceIDFilename:
+		return SourceIDDeriver{strategy: SourceIDFilename}, nil
+	case SourceIDPrefixed, SourceIDMetadata:
+		return SourceIDDeriver{strategy: strategy}, nil
+	case SourceIDRegex:
+		if pattern == "" || template == "" {
+			return SourceIDDeriver{}, fmt.Errorf("%w: source id strategy %q needs a pattern and a template",
+				ErrInvalidInput, strategy)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return SourceIDDeriver{}, fmt.Errorf("%w: source id pattern: %w", ErrInvalidInput, err)
+		}
+		return SourceIDDeriver{strategy: strategy, pattern: re, template: template}, nil
+	default:
+		return SourceIDDeriver{}, fmt.Errorf("%w: unknown source id strategy %q", ErrInvalidInput, strategy)
+	}
+}
+
+// Strategy returns the configured strategy.
+func (d SourceIDDeriver) Strategy() SourceIDStrategy {
+	if d.strategy == "" {
+		return SourceIDFilename
+	}
+	return d.strategy
+}
+
+// Derive returns the id for an object key. Keys use forward slashes; OS
+// separators are normalized first so local paths work too.
+func (d SourceIDDeriver) Derive(key string) string {
+	key = strings.TrimPrefix(filepath.ToSlash(key), "/")
+	stem := DeriveSourceID(key)
+	switch d.strategy {
+	case SourceIDPrefixed:
+		i := strings.LastIndex(key, "/")
+		if i <= 0 {
+			return stem
+		}
+		return strings.ReplaceAll(key[:i], "/", sourceIDSeparator) + sourceIDSeparator + stem
+	case SourceIDRegex:
+		m := d.pattern.FindStringSubmatchIndex(key)
+		if m == nil {
+			return stem
+		}
+		id := string(d.pattern.ExpandString(nil, d.template, key, m))
+		if id == "" {
+			return stem
+		}
+		return SanitizeSourceID(id)
+	default:
+		return stem
+	}
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestDeriveSourceID(t *testing.T) {
 	tests := []struct {
@@ -42,3 +45,159 @@ func TestIsSupportedSourceFile(t *testing.T) {
 		}
 	}
 }
+
+func TestDecompressedSourceKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"parcels.gpkg.gz":     "parcels.gpkg",
+		"eu/roads.GPKG.ZIP":   "eu/roads.GPKG",
+		"parcels.gpkg":        "",
+		"dem.zip":             "",
+		".gpkg.gz":            "",
+		"archive.tar.gz":      "",
+		"nested/x.gpkg.gz.gz": "",
+	} {
+		got, ok := DecompressedSourceKey(key)
+		if got != want || ok != (want != "") {
+			t.Errorf("DecompressedSourceKey(%q) = %q, %v; want %q", key, got, ok, want)
+		}
+	}
+	if !IsStoredSourceFile("parcels.gpkg.gz") || IsSupportedSourceFile("parcels.gpkg.gz") {
+		t.Error("a gzipped GeoPackage is stored but not loadable as is")
+	}
+}
+
+func TestMatchKeyGlob(t *testing.T) {
+	tests := []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"**/tmp/**", "tmp/a.gpkg", true},
+		{"**/tmp/**", "eu/tmp/b/c.gpkg", true},
+		{"**/tmp/**", "eu/tmp", true},
+		{"**/tmp/**", "eu/temp/a.gpkg", false},
+		{"staging/*.gpkg", "staging/a.gpkg", true},
+		{"staging/*.gpkg", "staging/x/a.gpkg", false},
+		{"*.zip", "bundle.zip", true},
+		{"*.zip", "eu/bundle.zip", false},
+		{"**/*.zip", "eu/bundle.zip", true},
+		{"**", "anything/at/all", true},
+	}
+	for _, tt := range tests {
+		if got := MatchKeyGlob(tt.pattern, tt.key); got != tt.want {
+			t.Errorf("MatchKeyGlob(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestEnsureGeoPackageExt(t *testing.T) {
+	tests := map[string]string{
+		"parcels.gpkg":    "parcels.gpkg",
+		"Parcels.GPKG":    "Parcels.GPKG",
+		"download":        "download.gpkg",
+		"bundle.zip":      "bundle.zip.gpkg",
+		"parcels.gpkg.gz": "parcels.gpkg.gz",
+	}
+	for in, want := range tests {
+		if got := EnsureGeoPackageExt(in); got != want {
+			t.Errorf("EnsureGeoPackageExt(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSidecarNames(t *testing.T) {
+	got := SidecarNames("eu/Parcels.GPKG")
+	want := []string{"eu/Parcels.meta.yaml", "eu/Parcels.meta.json"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("SidecarNames = %v, want %v", got, want)
+	}
+	if got := SidecarNames("bundle.zip"); got != nil {
+		t.Errorf("SidecarNames(bundle.zip) = %v, want nil", got)
+	}
+}
+
+func TestNewSourceIDDeriver(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy SourceIDStrategy
+		pattern  string
+		template string
+		wantErr  bool
+	}{
+		{"empty defaults to filename", "", "", "", false},
+		{"filename", SourceIDFilename, "", "", false},
+		{"prefixed", SourceIDPrefixed, "", "", false},
+		{"metadata", SourceIDMetadata, "", "", false},
+		{"regex", SourceIDRegex, `^(?P<region>\w+)/`, "${region}", false},
+		{"regex without pattern", SourceIDRegex, "", "${region}", true},
+		{"regex without template", SourceIDRegex, `^(\w+)/`, "", true},
+		{"regex invalid pattern", SourceIDRegex, `(`, "$1", true},
+		{"unknown", "hash", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSourceIDDeriver(tt.strategy, tt.pattern, tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("err = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
+
+func TestSourceIDDeriver_Derive(t *testing.T) {
+	mustDeriver := func(s SourceIDStrategy, pattern, template string) SourceIDDeriver {
+		t.Helper()
+		d, err := NewSourceIDDeriver(s, pattern, template)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	regex := mustDeriver(SourceIDRegex,
+		`^(?P<region>[a-z]+)/(?P<date>\d{4}-\d{2})/(?P<name>[^/]+)\.gpkg$`, "${name}-${region}-${date}")
+
+	tests := []struct {
+		name    string
+		deriver SourceIDDeriver
+		key     string
+		want    string
+	}{
+		{"zero value is filename", SourceIDDeriver{}, "eu/2024-05/parcels.gpkg", "parcels"},
+		{"filename", mustDeriver(SourceIDFilename, "", ""), "eu/parcels.gpkg", "parcels"},
+		{"prefixed nested", mustDeriver(SourceIDPrefixed, "", ""), "eu/2024-05/parcels.gpkg", "eu.2024-05.parcels"},
+		{"prefixed top level", mustDeriver(SourceIDPrefixed, "", ""), "parcels.gpkg", "parcels"},
+		{"prefixed leading slash", mustDeriver(SourceIDPrefixed, "", ""), "/eu/parcels.gpkg", "eu.parcels"},
+		{"regex match", regex, "eu/2024-05/parcels.gpkg", "parcels-eu-2024-05"},
+		{"regex no match falls back", regex, "misc/parcels.zip", "parcels"},
+		{"regex empty expansion falls back", mustDeriver(SourceIDRegex, `^(?P<x>z?)`, "${x}"), "a/b.gpkg", "b"},
+		{"regex slash in result", mustDeriver(SourceIDRegex, `^(.+)\.gpkg$`, "$1"), "eu/parcels.gpkg", "eu.parcels"},
+		{"metadata falls back to stem", mustDeriver(SourceIDMetadata, "", ""), "eu/parcels.gpkg", "parcels"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.deriver.Derive(tt.key); got != tt.want {
+				t.Errorf("Derive(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceIDDeriver_Strategy(t *testing.T) {
+	if got := (SourceIDDeriver{}).Strategy(); got != SourceIDFilename {
+		t.Errorf("zero value Strategy() = %q, want %q", got, SourceIDFilename)
+	}
+	d, _ := NewSourceIDDeriver(SourceIDPrefixed, "", "")
+	if got := d.Strategy(); got != SourceIDPrefixed {
+		t.Errorf("Strategy() = %q, want %q", got, SourceIDPrefixed)
+	}
+}
+
+func TestSanitizeSourceID(t *testing.T) {
+	for in, want := range map[string]string{"roads": "roads", "a/b": "a.b", "eu/2024/roads": "eu.2024.roads"} {
+		if got := SanitizeSourceID(in); got != want {
+			t.Errorf("SanitizeSourceID(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "context"
+
+// Stage selects which version of a source a query reads: the active one
+// everybody is served, or the candidate an operator staged next to it.
+type Stage string
+
+// Stages.
+const (
+	StageActive    Stage = "active"
+	StageCandidate Stage = "candidate"
+)
+
+type stageKey struct{}
+
+// WithStage returns ctx selecting stage for the queries done with it.
+func WithStage(ctx context.Context, stage Stage) context.Context {
+	return context.WithValue(ctx, stageKey{}, stage)
+}
+
+// StageFrom returns the stage selected by ctx, or StageActive.
+func StageFrom(ctx context.Context) Stage {
+	if stage, ok := ctx.Value(stageKey{}).(Stage); ok && stage != "" {
+		return stage
+	}
+	return StageActive
+}
@@ -0,0 +1,39 @@
+package domain
+
+// AttributeTable is a non-spatial table a source bundles with its layers — a
+// GeoPackage table registered in gpkg_contents with data_type 'attributes',
+// such as a code list the layer attributes refer to.
+type AttributeTable struct {
+	Name        string   // Table name from gpkg_contents.table_name
+	Description string   // Table description
+	Columns     []string // Column names in table order
+	RowCount    int64    // Number of rows
+}
+
+// TableQuery selects a page of rows from an attribute table.
+type TableQuery struct {
+	// Filters keeps only rows whose column equals the value, per entry (ANDed).
+	Filters map[string]string
+	Limit   int // rows per page; > 0
+	Offset  int // rows skipped before the page
+}
+
+// TableRows is one page of an attribute table.
+type TableRows struct {
+	Columns []string
+	Rows    []map[string]interface{}
+	Total   int64 // rows matching the filters across all pages
+}
+
+// TileSet is a tile pyramid a GeoPackage stores (gpkg_contents data_type
+// 'tiles'), e.g. a raster basemap packaged alongside the features. Tiles are
+// addressed by zoom level, column and row as in gpkg_tile_matrix, with the
+// row counted from the top.
+type TileSet struct {
+	Name        string  // Tile table name from gpkg_contents.table_name
+	Description string  // Tile set description
+	SRID        int     // Spatial Reference ID of the tile matrix set
+	MinZoom     int     // Lowest zoom level in gpkg_tile_matrix
+	MaxZoom     int     // Highest zoom level in gpkg_tile_matrix
+	Extent      *Extent // Bounding box (optional)
+}
@@ -0,0 +1,8 @@
+// Package e2e holds the end-to-end tests: the full application, started with
+// internal/testsupport on synthetic GeoPackages, exercised over HTTP through
+// loading, querying, hot-reload and sync. They run the real SQL against real
+// SpatiaLite, which the adapter and handler unit tests mock away, and skip
+// when SpatiaLite is not installed.
+//
+//	go test ./internal/e2e -run Integration -v
+package e2e
@@ -0,0 +1,129 @@
+package e2e
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	httpAdapter "github.com/jobrunner/ortus/internal/adapters/http"
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/testsupport"
+)
+
+// districts is a layer of two adjacent squares, west and east of lon 10.
+var districts = testsupport.Layer{
+	Name: "districts",
+	Features: []testsupport.Feature{
+		{WKT: testsupport.Box(9, 50, 10, 51), Properties: map[string]interface{}{"name": "West", "pop": 1200}},
+		{WKT: testsupport.Box(10, 50, 11, 51), Properties: map[string]interface{}{"name": "East", "pop": 800}},
+	},
+}
+
+// queryNames queries the point and returns the name property of every
+// feature found, by source id.
+func queryNames(t *testing.T, a *testsupport.App, lonlat string) map[string][]string {
+	t.Helper()
+	var resp httpAdapter.QueryResponseDTO
+	if code := a.Get("/api/v1/query?"+lonlat, &resp); code != http.StatusOK {
+		t.Fatalf("query %s: status %d", lonlat, code)
+	}
+	names := map[string][]string{}
+	for _, r := range resp.Results {
+		for _, f := range r.Features {
+			name, _ := f.Properties["name"].(string)
+			names[r.SourceID] = append(names[r.SourceID], name)
+		}
+	}
+	return names
+}
+
+func TestIntegration_QueryPointInPolygon(t *testing.T) {
+	data := t.TempDir()
+	testsupport.WriteGeoPackage(t, filepath.Join(data, "regions.gpkg"), districts)
+	a := testsupport.StartApp(t, data, nil)
+
+	got := queryNames(t, a, "lon=9.5&lat=50.5")
+	if len(got["regions"]) != 1 || got["regions"][0] != "West" {
+		t.Fatalf("features at 9.5,50.5 = %v, want West from regions", got)
+	}
+	if got := queryNames(t, a, "lon=20&lat=20"); len(got["regions"]) != 0 {
+		t.Errorf("features outside the layer = %v, want none", got)
+	}
+
+	var src httpAdapter.SourceDTO
+	if code := a.Get("/api/v1/sources/regions", &src); code != http.StatusOK {
+		t.Fatalf("source regions: status %d", code)
+	}
+	if !src.Ready || src.LayerCount != 1 {
+		t.Errorf("source regions = ready %v, %d layers; want ready with 1 layer", src.Ready, src.LayerCount)
+	}
+}
+
+// TestIntegration_WatcherHotReload: a package dropped into the data directory
+// while the app runs is loaded and queryable without a restart.
+func TestIntegration_WatcherHotReload(t *testing.T) {
+	data := t.TempDir()
+	testsupport.WriteGeoPackage(t, filepath.Join(data, "regions.gpkg"), districts)
+	a := testsupport.StartApp(t, data, nil)
+
+	a.AddSource("parks.gpkg", testsupport.Layer{
+		Name: "parks",
+		Features: []testsupport.Feature{
+			{WKT: testsupport.Box(9.4, 50.4, 9.6, 50.6), Properties: map[string]interface{}{"name": "Stadtpark"}},
+		},
+	})
+	a.WaitForSource("parks")
+
+	got := queryNames(t, a, "lon=9.5&lat=50.5")
+	if len(got["parks"]) != 1 || got["parks"][0] != "Stadtpark" {
+		t.Errorf("features from parks = %v, want Stadtpark", got["parks"])
+	}
+	if len(got["regions"]) != 1 {
+		t.Errorf("features from regions = %v, want the existing source still answering", got["regions"])
+	}
+}
+
+// TestIntegration_ConfigReload: a packages override applied by a config
+// reload renames the running source.
+func TestIntegration_ConfigReload(t *testing.T) {
+	data := t.TempDir()
+	testsupport.WriteGeoPackage(t, filepath.Join(data, "regions.gpkg"), districts)
+	a := testsupport.StartApp(t, data, nil)
+
+	res := a.Reload(func(c *config.Config) {
+		c.Packages = map[string]config.PackageConfig{"regions": {Name: "Verwaltungsbezirke"}}
+	})
+	if len(res.Changed) != 1 || res.Changed[0] != "packages" {
+		t.Errorf("changed = %v, want [packages]", res.Changed)
+	}
+
+	var src httpAdapter.SourceDTO
+	a.WaitFor("renamed source", func() bool {
+		return a.Get("/api/v1/sources/regions", &src) == http.StatusOK && src.Name == "Verwaltungsbezirke"
+	})
+}
+
+// TestIntegration_SyncFromRemote: sources are downloaded from HTTP storage at
+// startup, and a package published later arrives with the next sync.
+func TestIntegration_SyncFromRemote(t *testing.T) {
+	remote := testsupport.StartRemote(t)
+	remote.AddSource("regions.gpkg", districts)
+	a := testsupport.StartApp(t, t.TempDir(), remote.Configure)
+	a.WaitForSource("regions")
+
+	remote.AddSource("rivers.gpkg", testsupport.Layer{
+		Name:         "rivers",
+		GeometryType: "LINESTRING",
+		Features: []testsupport.Feature{
+			{WKT: "LINESTRING(10.5 50, 10.5 51)", Properties: map[string]interface{}{"name": "Fluss"}},
+		},
+	})
+	if code := a.Do(http.MethodPost, "/api/v1/sync", nil, nil); code != http.StatusOK {
+		t.Fatalf("sync: status %d", code)
+	}
+	a.WaitForSource("rivers")
+
+	if got := queryNames(t, a, "lon=10.5&lat=50.5"); len(got["regions"]) != 1 || got["regions"][0] != "East" {
+		t.Errorf("features at 10.5,50.5 = %v, want East from the synced regions", got)
+	}
+}
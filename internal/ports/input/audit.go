@@ -0,0 +1,10 @@
+package input
+
+import "github.com/jobrunner/ortus/internal/domain"
+
+// AuditTrail serves the recent entries of the audit log of admin, sync and
+// load actions.
+type AuditTrail interface {
+	// RecentAudit returns up to limit entries, newest first.
+	RecentAudit(limit int) []domain.AuditEntry
+}
@@ -0,0 +1,19 @@
+package input
+
+import "context"
+
+// ConfigReloader re-reads the configuration and applies the settings that can
+// change without a restart: log level, CORS origins, rate limit, query limits
+// and per-package overrides.
+type ConfigReloader interface {
+	// ReloadConfig loads and validates the configuration, then swaps in its
+	// runtime settings. An invalid configuration is an error and changes
+	// nothing. Returns domain.ErrMaintenance while maintenance mode is on.
+	ReloadConfig(ctx context.Context) (ConfigReload, error)
+}
+
+// ConfigReload reports what a reload changed.
+type ConfigReload struct {
+	Changed         []string // config keys whose value changed, e.g. "server.cors.allowed_origins"
+	ReopenedSources []string // sources reopened to apply changed package overrides
+}
@@ -0,0 +1,18 @@
+package input
+
+import (
+	"context"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// Geocoder searches places by address, so a client can query a point it only
+// knows the address of.
+type Geocoder interface {
+	// Geocode returns up to limit places matching query, best match first.
+	// limit 0 takes the default.
+	Geocode(ctx context.Context, query string, limit int) ([]domain.GeocodeMatch, error)
+
+	// License returns the license and attribution of the geocoder's data.
+	License() domain.License
+}
@@ -0,0 +1,28 @@
+package input
+
+import (
+	"context"
+	"time"
+)
+
+// Maintenance is the operator switch for manual work on the data directory.
+// While it is on, readiness fails so load balancers drain the instance, and
+// sync and file-watcher reloads are refused; queries and health probes are
+// still answered.
+type Maintenance interface {
+	// EnterMaintenance switches maintenance mode on. Entering again only
+	// updates the reason; Since keeps the original start. ctx carries the
+	// actor for the audit log.
+	EnterMaintenance(ctx context.Context, reason string) MaintenanceStatus
+	// LeaveMaintenance switches maintenance mode off.
+	LeaveMaintenance(ctx context.Context) MaintenanceStatus
+	// MaintenanceStatus reports the current state.
+	MaintenanceStatus() MaintenanceStatus
+}
+
+// MaintenanceStatus is the state of the maintenance switch.
+type MaintenanceStatus struct {
+	Active bool      // maintenance mode is on
+	Since  time.Time // when it was switched on; zero when off
+	Reason string    // operator note given when switching it on
+}
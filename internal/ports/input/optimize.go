@@ -0,0 +1,53 @@
+package input
+
+import (
+	"context"
+	"time"
+)
+
+// SourceOptimizer runs maintenance on the working copy of a loaded source in
+// the background — rebuilding its spatial indexes, ANALYZE, VACUUM — and
+// reports how far it got.
+type SourceOptimizer interface {
+	// StartOptimize starts the steps, in the order given, on source id and
+	// returns the initial progress. No steps means all of them. It returns
+	// domain.ErrSourceNotFound, domain.ErrUnsupportedSource for a source kind
+	// without maintenance, domain.ErrInvalidInput for an unknown step and
+	// domain.ErrOptimizeRunning while a run on the source is not finished.
+	// ctx carries the actor for the audit log.
+	StartOptimize(ctx context.Context, id string, steps []OptimizeStep) (OptimizeProgress, error)
+	// OptimizeProgress reports the running or last run on source id, or
+	// domain.ErrSourceNotFound when there has been none.
+	OptimizeProgress(id string) (OptimizeProgress, error)
+}
+
+// OptimizeStep is one maintenance step of an optimization run.
+type OptimizeStep string
+
+// Optimization steps.
+const (
+	OptimizeReindex OptimizeStep = "reindex" // drop and rebuild every layer's spatial index
+	OptimizeAnalyze OptimizeStep = "analyze" // refresh the query planner's statistics
+	OptimizeVacuum  OptimizeStep = "vacuum"  // compact the file
+)
+
+// OptimizeSteps are all steps, in the order a run without steps takes them.
+var OptimizeSteps = []OptimizeStep{OptimizeReindex, OptimizeAnalyze, OptimizeVacuum}
+
+// OptimizeProgress is the state of an optimization run.
+type OptimizeProgress struct {
+	SourceID   string
+	Steps      []OptimizeStep // requested steps, in order
+	Done       []OptimizeStep // finished steps
+	Step       OptimizeStep   // running step; "" once the run is over
+	Layers     int            // layers the reindex step covers
+	LayersDone int            // layers reindexed so far
+	StartedAt  time.Time
+	FinishedAt time.Time // zero while running
+	Error      string    // why the run stopped early; "" when it did not
+}
+
+// Running reports whether the run has not finished yet.
+func (p OptimizeProgress) Running() bool {
+	return p.FinishedAt.IsZero()
+}
@@ -0,0 +1,17 @@
+package input
+
+import "time"
+
+// PeerCatalog lists the federation peers and the sources each one serves, as
+// last fetched.
+type PeerCatalog interface {
+	Peers() []PeerInfo
+}
+
+// PeerInfo is one entry of the peer catalog.
+type PeerInfo struct {
+	Name        string    // peer name from the configuration
+	SourceIDs   []string  // sources the peer serves, sorted
+	RefreshedAt time.Time // last successful catalog fetch; zero if none yet
+	Error       string    // error of the last fetch, "" when it succeeded
+}
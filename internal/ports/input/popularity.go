@@ -0,0 +1,18 @@
+package input
+
+import "time"
+
+// Popularity ranks sources by recent use, so operators can see which packages
+// actually answer queries.
+type Popularity interface {
+	// Ranking returns the sources with at least one hit in the last window,
+	// most-hit first.
+	Ranking(window time.Duration) []SourcePopularity
+}
+
+// SourcePopularity is one entry of a popularity ranking.
+type SourcePopularity struct {
+	Rank     int    // 1-based position in the ranking
+	SourceID string // source id
+	Hits     int64  // queries the source answered with features in the window
+}
@@ -24,6 +24,22 @@ type QueryService interface {
 	// source/layer for all points). sources (optional) restricts to those source
 	// ids; properties (optional) filters returned feature properties.
 	QueryBatch(ctx context.Context, coords []domain.Coordinate, sources []string, properties []string) ([]*domain.QueryResponse, error)
+
+	// QueryRoute returns the features each source has along a route — those
+	// whose geometry the line intersects — in one response without a
+	// coordinate. sources (optional) restricts to those source ids;
+	// properties (optional) filters returned feature properties.
+	QueryRoute(ctx context.Context, route domain.Route, sources []string, properties []string) (*domain.QueryResponse, error)
+
+	// QueryArea returns the features each source has in relation rel to an
+	// area — intersecting it, within it, or containing it — in one response
+	// without a coordinate. sources and properties are as for QueryRoute.
+	QueryArea(ctx context.Context, area domain.Area, rel domain.SpatialRelation, sources []string, properties []string) (*domain.QueryResponse, error)
+
+	// QueryAggregate returns counts and statistics of the features q selects
+	// per source and layer instead of the features. sources (optional)
+	// restricts to those source ids.
+	QueryAggregate(ctx context.Context, q domain.AggregateQuery, sources []string) (*domain.AggregateResponse, error)
 }
 
 // SourceRegistry defines the primary port for source management.
@@ -41,15 +57,57 @@ type SourceRegistry interface {
 // Syncer defines the primary port for triggering storage synchronization.
 type Syncer interface {
 	// TriggerSync runs a synchronization with remote storage on demand,
-	// returning what changed. May return domain.ErrRateLimited.
-	TriggerSync(ctx context.Context) (SyncResult, error)
+	// returning what changed: a full sync, or with a non-empty selection a
+	// refresh of just the selected sources. May return a
+	// *domain.RateLimitError (domain.ErrRateLimited) saying when to try
+	// again, and domain.ErrSourceNotFound for a selected id not loaded.
+	TriggerSync(ctx context.Context, sel SyncSelection) (SyncResult, error)
+}
+
+// SyncStatus reports when remote storage was last synchronized.
+type SyncStatus interface {
+	// LastSync returns when the last successful sync finished — scheduled,
+	// triggered or from change notifications — and the zero time before the
+	// first.
+	LastSync() time.Time
+}
+
+// SyncSelection picks the sources a triggered sync refreshes — downloaded
+// and reloaded even when already loaded, or unloaded when gone from
+// storage. The zero value selects a full sync.
+type SyncSelection struct {
+	SourceIDs []string // loaded sources, refreshed without listing the storage
+	Prefixes  []string // object keys starting with one of these
+}
+
+// IsZero reports whether the selection is empty (a full sync).
+func (s SyncSelection) IsZero() bool {
+	return len(s.SourceIDs) == 0 && len(s.Prefixes) == 0
+}
+
+// ChangeSyncer is the primary port for storage change notifications (Azure
+// Event Grid blob events): it syncs just the objects that changed instead of
+// listing the whole storage.
+type ChangeSyncer interface {
+	// SyncChanges applies the changes, returning what changed in the
+	// registry. May return domain.ErrMaintenance.
+	SyncChanges(ctx context.Context, changes []ObjectChange) (SyncResult, error)
+}
+
+// ObjectChange is one object created, replaced or deleted in remote storage.
+type ObjectChange struct {
+	Container string // container or bucket the notification is for
+	Name      string // full object name, including any configured prefix
+	Deleted   bool
 }
 
 // SyncResult contains the outcome of a synchronization run. It is a driving-port
 // DTO (like HealthDetails) returned to adapters that expose sync.
 type SyncResult struct {
 	SourcesAdded    int       `json:"sources_added"`
+	SourcesUpdated  int       `json:"sources_updated,omitempty"`
 	SourcesRemoved  int       `json:"sources_removed"`
+	SourcesPinned   int       `json:"sources_pinned,omitempty"`
 	SourcesTotal    int       `json:"sources_total"`
 	SyncedAt        time.Time `json:"synced_at"`
 	NextScheduledAt time.Time `json:"next_scheduled_at,omitempty"`
@@ -65,6 +123,18 @@ type HealthChecker interface {
 
 	// GetHealthDetails returns detailed health information.
 	GetHealthDetails(ctx context.Context) HealthDetails
+
+	// StartupStatus reports whether the startup pass has ended.
+	StartupStatus(ctx context.Context) StartupStatus
+}
+
+// StartupStatus is the state of the startup pass (initial source load and
+// gazetteer warmup), reported by /health/startup.
+type StartupStatus struct {
+	Started  bool          // the startup pass has ended; latches
+	TimedOut bool          // still running past server.startup_timeout
+	Elapsed  time.Duration // since the startup pass began; 0 before it does
+	Load     LoadProgress  // progress of the source load while not started
 }
 
 // HealthDetails contains detailed health information.
@@ -75,6 +145,15 @@ type HealthDetails struct {
 	SourcesReady  int               // Number of ready sources
 	Components    map[string]string // Component statuses
 	Sources       []SourceState     // Per-source status (lets a client see which source is still indexing)
+	Load          LoadProgress      // Progress of the startup load
+}
+
+// LoadProgress is the progress of the startup load, which runs in the
+// background while the server already answers.
+type LoadProgress struct {
+	InProgress bool `json:"in_progress"`
+	Processed  int  `json:"processed"` // storage objects handled so far, loaded or failed
+	Total      int  `json:"total"`     // storage objects listed; 0 until the listing returns
 }
 
 // SourceState is the per-source status exposed via /health, so a client can
@@ -0,0 +1,36 @@
+package input
+
+import (
+	"context"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// SourceHealthReporter reports the load state of a single source, including
+// sources that failed to load and are therefore absent from the listing.
+type SourceHealthReporter interface {
+	// SourceHealth returns the health of the source id, or
+	// domain.ErrSourceNotFound when it is neither loaded nor failed.
+	SourceHealth(ctx context.Context, id string) (SourceHealth, error)
+}
+
+// SourceHealth is the per-source health report.
+type SourceHealth struct {
+	ID          string
+	Status      domain.SourceStatus
+	LastError   string        // last fetch, open or indexing error; "" when none
+	LastErrorAt time.Time     // zero when LastError is ""
+	LoadedAt    time.Time     // zero unless loaded
+	SyncedAt    time.Time     // last storage listing that included the source; zero if none
+	Layers      []LayerHealth // empty unless loaded
+}
+
+// LayerHealth is the index, SRID and geometry validity state of one layer.
+type LayerHealth struct {
+	Name              string
+	Indexed           bool
+	FeatureCount      int64
+	SRIDWarning       string // why the declared SRID is suspect; "" when it checks out
+	InvalidGeometries int64  // geometries failing ST_IsValid; 0 unless the validity check ran
+}
@@ -0,0 +1,42 @@
+package input
+
+import (
+	"context"
+	"time"
+)
+
+// SourceStager loads a candidate version of a loaded source next to the
+// active one, so it can be checked with queries selecting
+// domain.StageCandidate before it replaces the active source.
+type SourceStager interface {
+	// StageCandidate downloads req.Key (the source's own key when empty),
+	// in version req.VersionID when set, and opens it as the candidate of
+	// source id, replacing an earlier candidate. It returns
+	// domain.ErrSourceNotFound for a source that is not loaded and
+	// domain.ErrUnsupported where candidates cannot be staged. ctx carries
+	// the actor for the audit log.
+	StageCandidate(ctx context.Context, id string, req StageRequest) (StagedSource, error)
+	// PromoteCandidate makes the candidate of source id the active source,
+	// or returns domain.ErrNoCandidate.
+	PromoteCandidate(ctx context.Context, id string) (StagedSource, error)
+	// DiscardCandidate closes and deletes the candidate of source id, or
+	// returns domain.ErrNoCandidate.
+	DiscardCandidate(ctx context.Context, id string) error
+	// Candidate reports the candidate of source id, or domain.ErrNoCandidate.
+	Candidate(id string) (StagedSource, error)
+}
+
+// StageRequest names the storage object staged as a candidate.
+type StageRequest struct {
+	Key       string // storage key; "" stages the source's own key again
+	VersionID string // object version on versioned storage; "" for the current one
+}
+
+// StagedSource describes a staged candidate.
+type StagedSource struct {
+	SourceID  string
+	Key       string
+	VersionID string
+	Layers    int
+	StagedAt  time.Time
+}
@@ -0,0 +1,18 @@
+package input
+
+import (
+	"context"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// AttributeTables gives read access to the non-spatial tables sources bundle
+// with their layers, such as code lists.
+type AttributeTables interface {
+	// Tables lists the attribute tables of a loaded source, or returns
+	// domain.ErrSourceNotFound.
+	Tables(ctx context.Context, sourceID string) ([]domain.AttributeTable, error)
+	// ReadTable returns a page of rows from one of them, or
+	// domain.ErrSourceNotFound / domain.ErrTableNotFound.
+	ReadTable(ctx context.Context, sourceID, table string, q domain.TableQuery) (domain.TableRows, error)
+}
@@ -0,0 +1,11 @@
+package input
+
+import "context"
+
+// Tiles serves the tile pyramids sources store, such as raster basemaps
+// packaged alongside the features.
+type Tiles interface {
+	// Tile returns the encoded image of one tile, or domain.ErrSourceNotFound,
+	// domain.ErrTableNotFound (no such tile set) or domain.ErrTileNotFound.
+	Tile(ctx context.Context, sourceID, tileSet string, z, x, y int) ([]byte, error)
+}
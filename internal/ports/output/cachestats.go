@@ -0,0 +1,25 @@
+package output
+
+// CacheStatsReporter is implemented by a SpatialSource that keeps an
+// in-process cache on the query path, such as the raster adapter's LRU of
+// open tile handles.
+type CacheStatsReporter interface {
+	// CacheStats returns the lookups since startup.
+	CacheStats() CacheStats
+}
+
+// CacheStats counts the lookups of a cache.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// HitRate returns the share of lookups that were hits, and false before the
+// first lookup.
+func (c CacheStats) HitRate() (float64, bool) {
+	total := c.Hits + c.Misses
+	if total == 0 {
+		return 0, false
+	}
+	return float64(c.Hits) / float64(total), true
+}
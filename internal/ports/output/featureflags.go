@@ -0,0 +1,26 @@
+package output
+
+import (
+	"context"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// FeatureFlags is the secondary port for runtime feature flags. It mirrors an
+// OpenFeature boolean evaluation — a flag key plus the caller's default — so
+// the config-backed adapter (internal/adapters/featureflags) can be swapped
+// for an OpenFeature provider without touching the callers. Evaluation must be
+// cheap and safe for concurrent use: it sits on the query path.
+type FeatureFlags interface {
+	// Enabled reports whether flag is on, returning fallback when the
+	// provider has no value for it (or cannot be reached).
+	Enabled(ctx context.Context, flag domain.FeatureFlag, fallback bool) bool
+}
+
+// NoOpFeatureFlags evaluates every flag to the caller's fallback.
+type NoOpFeatureFlags struct{}
+
+// Enabled implements FeatureFlags.
+func (NoOpFeatureFlags) Enabled(_ context.Context, _ domain.FeatureFlag, fallback bool) bool {
+	return fallback
+}
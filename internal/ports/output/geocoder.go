@@ -0,0 +1,21 @@
+package output
+
+import (
+	"context"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// Geocoder resolves free-text addresses to places through an external
+// service, such as a Nominatim instance. It is an optional output port: when
+// none is configured there is no address search.
+type Geocoder interface {
+	// Geocode returns up to limit places matching query, best match first. No
+	// match is an empty result, not an error; a failure of the service wraps
+	// domain.ErrUnavailable.
+	Geocode(ctx context.Context, query string, limit int) ([]domain.GeocodeMatch, error)
+
+	// License returns the license and attribution of the geocoder's data,
+	// which its terms usually require to be shown next to the results.
+	License() domain.License
+}
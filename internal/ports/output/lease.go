@@ -0,0 +1,19 @@
+package output
+
+import (
+	"context"
+	"time"
+)
+
+// LeaderLease is a lease at most one holder owns at a time. Replicas that
+// share a data directory use it to elect the one that downloads and indexes
+// sources (sync.leader_election).
+type LeaderLease interface {
+	// TryAcquire takes the lease for holder, or renews it when holder owns it
+	// already, for ttl. It reports false while another holder's lease has not
+	// expired.
+	TryAcquire(ctx context.Context, holder string, ttl time.Duration) (bool, error)
+	// Release gives the lease up if holder owns it, so another replica can
+	// take over without waiting for it to expire.
+	Release(ctx context.Context, holder string) error
+}
@@ -0,0 +1,24 @@
+package output
+
+import (
+	"context"
+
+	"github.com/jobrunner/ortus/internal/domain"
+)
+
+// ForwardedHeader marks an HTTP request one ortus instance forwarded to a
+// federation peer. The receiving instance answers it from its own sources
+// only, so peers that list each other never bounce a query back and forth.
+const ForwardedHeader = "X-Ortus-Forwarded"
+
+// Peer is another ortus instance point queries can be forwarded to, for
+// sources this instance does not host (federation).
+type Peer interface {
+	// Name identifies the peer in provenance, logs and the peer catalog.
+	Name() string
+	// SourceIDs returns the ids of the sources the peer serves.
+	SourceIDs(ctx context.Context) ([]string, error)
+	// QueryPoint runs req on the peer, which answers from its own sources
+	// only. req.SourceID, when set, must be a source the peer serves.
+	QueryPoint(ctx context.Context, req domain.QueryRequest) (*domain.QueryResponse, error)
+}
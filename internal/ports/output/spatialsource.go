@@ -16,8 +16,10 @@ type SpatialSource interface {
 	// (typically by file extension, e.g. *.gpkg vs *.zip).
 	Supports(path string) bool
 
-	// Open opens a source file and returns its domain representation.
-	Open(ctx context.Context, path string) (*domain.Source, error)
+	// Open opens a source file under the id the registry assigned it and
+	// returns its domain representation. The adapter keys all later calls
+	// (Prepare, QueryPoint, Close) by that id.
+	Open(ctx context.Context, sourceID, path string) (*domain.Source, error)
 
 	// Prepare performs post-open readiness work for a single layer
 	// (e.g. building a spatial index). It is a no-op for sources that need
@@ -44,3 +46,102 @@ type BatchQuerier interface {
 	// the QueryPoint contract.
 	QueryPoints(ctx context.Context, sourceID string, layer string, coords []domain.Coordinate) ([][]domain.Feature, error)
 }
+
+// RouteQuerier is an OPTIONAL capability a SpatialSource may implement to
+// find the features of a layer a route runs through. The registry
+// type-asserts for it; sources without it (e.g. raster) are left out of route
+// queries.
+type RouteQuerier interface {
+	// QueryRoute returns the features of the layer whose geometry intersects
+	// the route. The route must already be in the layer's SRID.
+	QueryRoute(ctx context.Context, sourceID string, layer string, route domain.Route) ([]domain.Feature, error)
+}
+
+// AreaQuerier is an OPTIONAL capability a SpatialSource may implement to
+// select the features of a layer by a polygon. The registry type-asserts for
+// it; sources without it (e.g. raster) are left out of area queries.
+type AreaQuerier interface {
+	// QueryArea returns the features of the layer whose geometry stands in
+	// relation rel to the area. The area must already be in the layer's SRID.
+	QueryArea(ctx context.Context, sourceID string, layer string, area domain.Area, rel domain.SpatialRelation) ([]domain.Feature, error)
+}
+
+// Aggregator is an OPTIONAL capability a SpatialSource may implement to
+// compute counts and statistics of a layer's features without returning
+// them. The registry type-asserts for it; sources without it (e.g. raster)
+// are left out of aggregations.
+type Aggregator interface {
+	// Aggregate aggregates the layer's features q selects. q.Area must
+	// already be in the layer's SRID. Properties the layer lacks are left out
+	// of the result rather than failing it.
+	Aggregate(ctx context.Context, sourceID string, layer string, q domain.AggregateQuery) (domain.LayerAggregate, error)
+}
+
+// SourceIdentifier is an OPTIONAL capability a SpatialSource may implement to
+// report the identifier a package declares in its own metadata. The registry
+// consults it under the "metadata" source id strategy before Open, and falls
+// back to the key-derived id when the adapter lacks it or returns "".
+type SourceIdentifier interface {
+	Identify(ctx context.Context, path string) (string, error)
+}
+
+// LoadModeOpener is an OPTIONAL capability a SpatialSource may implement to
+// open a source with a configured domain.LoadMode (memory-mapped, or copied
+// into memory). The registry type-asserts for it when a source has a load
+// mode configured, and falls back to Open when the adapter lacks it.
+type LoadModeOpener interface {
+	// OpenWithLoadMode behaves like Open, reading the file as mode says.
+	OpenWithLoadMode(ctx context.Context, sourceID, path string, mode domain.LoadMode) (*domain.Source, error)
+}
+
+// GeometryEncoder is an OPTIONAL capability a SpatialSource may implement to
+// return feature geometries in an additional encoding (WKB, GeoJSON, GML) or
+// simplified, produced at query time. The registry type-asserts for it when a
+// request asks for anything beyond full-resolution WKT, and falls back to
+// QueryPoint when a source (e.g. raster, whose features carry no geometry)
+// does not implement it.
+type GeometryEncoder interface {
+	// QueryPointEncoded behaves like QueryPoint, simplifying every returned
+	// geometry by opts.Simplify and additionally filling the geometry field
+	// matching opts.Format.
+	QueryPointEncoded(ctx context.Context, sourceID string, layer string, coord domain.Coordinate, opts domain.GeometryOptions) ([]domain.Feature, error)
+}
+
+// AttributeTableReader is an OPTIONAL capability a SpatialSource may implement
+// to expose the non-spatial tables a source bundles (GeoPackage 'attributes'
+// tables such as code lists). The registry type-asserts for it; a source
+// without it (e.g. raster) has no tables.
+type AttributeTableReader interface {
+	// Tables lists the source's attribute tables.
+	Tables(ctx context.Context, sourceID string) ([]domain.AttributeTable, error)
+	// ReadTable returns the page of rows q selects, or domain.ErrTableNotFound
+	// when table is not an attribute table of the source. A filter on an
+	// unknown column is domain.ErrInvalidInput.
+	ReadTable(ctx context.Context, sourceID, table string, q domain.TableQuery) (domain.TableRows, error)
+}
+
+// TileReader is an OPTIONAL capability a SpatialSource may implement to serve
+// the tile pyramids a source stores (GeoPackage 'tiles' tables, listed in
+// domain.Source.TileSets). The registry type-asserts for it; a source without
+// it has no tiles.
+type TileReader interface {
+	// Tile returns the encoded image (PNG, JPEG or WebP) of one tile, or
+	// domain.ErrTableNotFound when tileSet is not a tile set of the source and
+	// domain.ErrTileNotFound when the pyramid has no tile at z/x/y.
+	Tile(ctx context.Context, sourceID, tileSet string, z, x, y int) ([]byte, error)
+}
+
+// SourceOptimizer is an OPTIONAL capability a SpatialSource may implement to
+// maintain the working copy of a source: rebuild its spatial indexes, refresh
+// the query planner's statistics and compact the file. The registry
+// type-asserts for it; a source without it (e.g. raster) has nothing to
+// maintain.
+type SourceOptimizer interface {
+	// RebuildSpatialIndex drops and rebuilds the spatial index of a layer.
+	// Queries keep using the old index until the new one is complete.
+	RebuildSpatialIndex(ctx context.Context, sourceID, layer string) error
+	// Analyze refreshes the query planner's statistics (SQLite ANALYZE).
+	Analyze(ctx context.Context, sourceID string) error
+	// Vacuum rewrites the file without free pages (SQLite VACUUM).
+	Vacuum(ctx context.Context, sourceID string) error
+}
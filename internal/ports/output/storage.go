@@ -21,6 +21,14 @@ type ObjectStorage interface {
 	Exists(ctx context.Context, key string) (bool, error)
 }
 
+// VersionedStorage is implemented by storage that keeps earlier versions of
+// an object (S3 bucket versioning), so a source pinned to a version can be
+// fetched after the object was replaced.
+type VersionedStorage interface {
+	// DownloadVersion downloads one version of an object like Download.
+	DownloadVersion(ctx context.Context, key, versionID, dest string) error
+}
+
 // StorageObject represents a file in object storage.
 type StorageObject struct {
 	Key          string // Object key/path
@@ -0,0 +1,220 @@
+package testsupport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/app"
+	"github.com/jobrunner/ortus/internal/config"
+	"github.com/jobrunner/ortus/internal/ports/input"
+)
+
+// readyTimeout bounds how long StartApp and the Wait helpers poll.
+const readyTimeout = 30 * time.Second
+
+// baseConfig is the config file every test app starts from: the defaults,
+// local storage in the data directory and nothing listening besides the HTTP
+// server. It is formatted with the port and the data directory.
+const baseConfig = `server:
+  host: 127.0.0.1
+  port: %d
+storage:
+  type: local
+  local_path: [%q]
+metrics:
+  enabled: false
+tracing:
+  enabled: false
+logging:
+  level: error
+`
+
+// App is the full application running for a test on a local port.
+type App struct {
+	*app.App
+	// URL is the base URL of the HTTP server, e.g. http://127.0.0.1:41234.
+	URL string
+	// DataDir is storage.local_path: the directory sources are loaded from,
+	// and the one remote storage downloads into.
+	DataDir string
+
+	t    testing.TB
+	next *config.Config // returned by LoadConfig on the next reload
+}
+
+// StartApp starts the application the way `ortus serve` does, with the
+// sources in dataDir, and waits until it reports ready. configure adjusts the
+// config before it is validated, e.g. to use remote storage or to enable the
+// admin routes. The app is shut down when the test ends. It skips t when
+// SpatiaLite is not installed.
+func StartApp(t testing.TB, dataDir string, configure func(*config.Config)) *App {
+	t.Helper()
+	RequireSpatiaLite(t)
+	cfg := loadConfig(t, dataDir)
+	if configure != nil {
+		configure(cfg)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("invalid test config: %v", err)
+	}
+
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	a, err := app.New(ctx, cfg, logger)
+	if err != nil {
+		t.Fatalf("creating app: %v", err)
+	}
+	h := &App{App: a, URL: "http://" + cfg.Server.Address(), DataDir: dataDir, t: t, next: cfg}
+	a.LoadConfig = func() (*config.Config, error) {
+		c := *h.next
+		return &c, nil
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- a.Start(ctx) }()
+	t.Cleanup(func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := a.Shutdown(shutdownCtx); err != nil {
+			t.Logf("shutting down app: %v", err)
+		}
+		<-served
+	})
+
+	h.WaitFor("app ready", func() bool {
+		select {
+		case err := <-served:
+			t.Fatalf("app stopped during startup: %v", err)
+		default:
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.URL+"/health/ready", nil)
+		if err != nil {
+			t.Fatalf("building readiness request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false // not listening yet
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	})
+	return h
+}
+
+// loadConfig loads baseConfig through config.Load, so the test app gets the
+// same defaults as a real deployment, on a free local port.
+func loadConfig(t testing.TB, dataDir string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := fmt.Sprintf(baseConfig, freePort(t), dataDir)
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatalf("writing test config: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("loading test config: %v", err)
+	}
+	return cfg
+}
+
+// freePort returns a TCP port nothing listens on right now.
+func freePort(t testing.TB) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("finding a free port: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// Reload applies configure to a copy of the current config and reloads it
+// like POST /admin/reload-config. The copy is shallow: configure must assign
+// new maps and slices rather than modify the current ones, or the reload sees
+// no change.
+func (h *App) Reload(configure func(*config.Config)) input.ConfigReload {
+	h.t.Helper()
+	next := *h.Config
+	configure(&next)
+	h.next = &next
+	res, err := h.ReloadConfig(context.Background())
+	if err != nil {
+		h.t.Fatalf("reloading config: %v", err)
+	}
+	return res
+}
+
+// AddSource writes a GeoPackage named file with layers into the data
+// directory. It is written next to it and renamed into place, so the file
+// watcher never sees a half-written package.
+func (h *App) AddSource(file string, layers ...Layer) {
+	h.t.Helper()
+	staging := filepath.Join(filepath.Dir(h.DataDir), ".staging-"+filepath.Base(h.DataDir))
+	tmp := filepath.Join(staging, file)
+	WriteGeoPackage(h.t, tmp, layers...)
+	if err := os.Rename(tmp, filepath.Join(h.DataDir, file)); err != nil {
+		h.t.Fatalf("moving %s into the data directory: %v", file, err)
+	}
+}
+
+// Get requests path from the app and decodes the JSON response into out,
+// unless out is nil. It returns the status code.
+func (h *App) Get(path string, out interface{}) int {
+	h.t.Helper()
+	return h.Do(http.MethodGet, path, nil, out)
+}
+
+// Do sends a request with a JSON body (nil for none) to the app and decodes
+// the JSON response into out, unless out is nil. It returns the status code.
+func (h *App) Do(method, path string, body io.Reader, out interface{}) int {
+	h.t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), method, h.URL+path, body)
+	if err != nil {
+		h.t.Fatalf("building request %s %s: %v", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		h.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			h.t.Fatalf("decoding %s %s: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// WaitFor polls cond until it holds, failing the test after readyTimeout.
+// Loading, syncing and watching run in the background, so their effects are
+// awaited rather than expected right away.
+func (h *App) WaitFor(what string, cond func() bool) {
+	h.t.Helper()
+	deadline := time.Now().Add(readyTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// WaitForSource waits until the source id is loaded.
+func (h *App) WaitForSource(id string) {
+	h.t.Helper()
+	h.WaitFor("source "+id, func() bool {
+		return h.Get("/api/v1/sources/"+id, nil) == http.StatusOK
+	})
+}
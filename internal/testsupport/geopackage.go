@@ -0,0 +1,236 @@
+// Package testsupport builds what end-to-end tests run against: small
+// synthetic GeoPackages written with SpatiaLite on the fly, a remote storage
+// serving them over HTTP, and the full application listening on a local
+// port. Everything skips the calling test when SpatiaLite is not installed,
+// like the adapter's integration tests.
+//
+// It is imported by tests only; the production binary never links it.
+package testsupport
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	// Registers the sqlite3_with_extensions driver (SQLite with SpatiaLite)
+	// the packages are written with, the one the application reads them with.
+	_ "github.com/jobrunner/ortus/internal/adapters/geopackage"
+)
+
+// Feature is a row of a synthetic layer: its geometry as WKT and its
+// attributes. Property values are strings, integers, floats, booleans,
+// []byte or nil; each property becomes a column of the matching SQLite type.
+type Feature struct {
+	WKT        string
+	Properties map[string]interface{}
+}
+
+// Layer is a feature table of a synthetic GeoPackage.
+type Layer struct {
+	Name string
+	// SRID of the geometries; 0 is EPSG:4326.
+	SRID int
+	// GeometryType is the declared type of the geometry column; "" is
+	// GEOMETRY, which accepts any.
+	GeometryType string
+	Features     []Feature
+}
+
+// Box returns the WKT of the axis-aligned rectangle from (minX, minY) to
+// (maxX, maxY).
+func Box(minX, minY, maxX, maxY float64) string {
+	return fmt.Sprintf("POLYGON((%[1]g %[2]g, %[3]g %[2]g, %[3]g %[4]g, %[1]g %[4]g, %[1]g %[2]g))",
+		minX, minY, maxX, maxY)
+}
+
+// RequireSpatiaLite skips t when the SpatiaLite extension cannot be loaded:
+// the application cannot start without it.
+func RequireSpatiaLite(t testing.TB) {
+	t.Helper()
+	db, err := sql.Open("sqlite3_with_extensions", ":memory:")
+	if err != nil {
+		t.Fatalf("opening SQLite: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	var version string
+	if err := db.QueryRowContext(context.Background(), "SELECT spatialite_version()").Scan(&version); err != nil {
+		t.Skipf("SpatiaLite extension not available, skipping: %v", err)
+	}
+}
+
+// WriteGeoPackage writes a GeoPackage with layers to path: the gpkg_contents
+// and gpkg_geometry_columns entries, the extent of each layer, and GeoPackage
+// binary geometries. It skips t when SpatiaLite cannot be loaded. The file is
+// written in place; write into a staging directory and rename it when a
+// watcher observes the target.
+func WriteGeoPackage(t testing.TB, path string, layers ...Layer) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("creating %s: %v", filepath.Dir(path), err)
+	}
+	db, err := sql.Open("sqlite3_with_extensions", "file:"+path)
+	if err != nil {
+		t.Fatalf("opening %s: %v", path, err)
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT spatialite_version()").Scan(&version); err != nil {
+		t.Skipf("SpatiaLite extension not available, skipping: %v", err)
+	}
+	// Prefer GeoPackage binary geometries; a SpatiaLite build without GPKG
+	// support writes its native blobs, which the adapter reads as well.
+	geom := "AsGPB(GeomFromText(?, ?))"
+	if _, err := db.ExecContext(ctx, "SELECT AsGPB(GeomFromText('POINT(0 0)', 4326))"); err != nil {
+		geom = "GeomFromText(?, ?)"
+	}
+
+	for _, stmt := range []string{
+		`CREATE TABLE gpkg_contents (
+			table_name TEXT PRIMARY KEY, data_type TEXT, identifier TEXT,
+			description TEXT, min_x DOUBLE, min_y DOUBLE, max_x DOUBLE, max_y DOUBLE,
+			srs_id INTEGER)`,
+		`CREATE TABLE gpkg_geometry_columns (
+			table_name TEXT, column_name TEXT, geometry_type_name TEXT,
+			srs_id INTEGER, z TINYINT, m TINYINT)`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("creating GeoPackage tables: %v", err)
+		}
+	}
+	for i := range layers {
+		if err := writeLayer(ctx, db, &layers[i], geom); err != nil {
+			t.Fatalf("writing layer %q: %v", layers[i].Name, err)
+		}
+	}
+}
+
+func writeLayer(ctx context.Context, db *sql.DB, l *Layer, geom string) error {
+	srid := l.SRID
+	if srid == 0 {
+		srid = 4326
+	}
+	geomType := l.GeometryType
+	if geomType == "" {
+		geomType = "GEOMETRY"
+	}
+	columns := propertyColumns(l.Features)
+
+	var ddl strings.Builder
+	fmt.Fprintf(&ddl, "CREATE TABLE %s (fid INTEGER PRIMARY KEY AUTOINCREMENT, geom BLOB", quote(l.Name))
+	for _, c := range columns {
+		fmt.Fprintf(&ddl, ", %s %s", quote(c.name), c.sqlType)
+	}
+	ddl.WriteString(")")
+	if _, err := db.ExecContext(ctx, ddl.String()); err != nil {
+		return err
+	}
+
+	ext := extent(l.Features)
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO gpkg_contents VALUES (?, 'features', ?, '', ?, ?, ?, ?, ?)`,
+		l.Name, l.Name, ext[0], ext[1], ext[2], ext[3], srid); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO gpkg_geometry_columns VALUES (?, 'geom', ?, ?, 0, 0)`,
+		l.Name, geomType, srid); err != nil {
+		return err
+	}
+
+	var insert strings.Builder
+	fmt.Fprintf(&insert, "INSERT INTO %s (geom", quote(l.Name))
+	for _, c := range columns {
+		insert.WriteString(", ")
+		insert.WriteString(quote(c.name))
+	}
+	insert.WriteString(") VALUES (" + geom + strings.Repeat(", ?", len(columns)) + ")")
+	for _, f := range l.Features {
+		args := []interface{}{f.WKT, srid}
+		for _, c := range columns {
+			args = append(args, f.Properties[c.name])
+		}
+		if _, err := db.ExecContext(ctx, insert.String(), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type column struct {
+	name    string
+	sqlType string
+}
+
+// propertyColumns returns the sorted union of the features' property names,
+// typed by the first non-nil value.
+func propertyColumns(features []Feature) []column {
+	types := map[string]string{}
+	for _, f := range features {
+		for k, v := range f.Properties {
+			if types[k] == "" {
+				types[k] = sqlType(v)
+			}
+		}
+	}
+	out := make([]column, 0, len(types))
+	for k, t := range types {
+		if t == "" {
+			t = "TEXT"
+		}
+		out = append(out, column{name: k, sqlType: t})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+func sqlType(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "TEXT"
+	case int, int32, int64:
+		return "INTEGER"
+	case float32, float64:
+		return "DOUBLE"
+	case bool:
+		return "BOOLEAN"
+	case []byte:
+		return "BLOB"
+	default:
+		return ""
+	}
+}
+
+var wktNumber = regexp.MustCompile(`-?\d+(?:\.\d+)?(?:[eE][-+]?\d+)?`)
+
+// extent returns min x, min y, max x, max y over the coordinates of the
+// features' WKT, read as x y pairs: the layers are two-dimensional.
+func extent(features []Feature) [4]float64 {
+	ext := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, f := range features {
+		nums := wktNumber.FindAllString(f.WKT, -1)
+		for i := 0; i+1 < len(nums); i += 2 {
+			x, _ := strconv.ParseFloat(nums[i], 64)
+			y, _ := strconv.ParseFloat(nums[i+1], 64)
+			ext[0], ext[1] = math.Min(ext[0], x), math.Min(ext[1], y)
+			ext[2], ext[3] = math.Max(ext[2], x), math.Max(ext[3], y)
+		}
+	}
+	if math.IsInf(ext[0], 1) {
+		return [4]float64{}
+	}
+	return ext
+}
+
+func quote(ident string) string {
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
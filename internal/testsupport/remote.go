@@ -0,0 +1,76 @@
+package testsupport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jobrunner/ortus/internal/config"
+)
+
+// Remote is a web server that serves the GeoPackages in a directory the way
+// storage.type http expects them: an index.txt listing the files next to the
+// files themselves. The index is generated on each request, so a package
+// added with AddSource is picked up by the next sync.
+type Remote struct {
+	URL string
+	Dir string
+
+	t testing.TB
+}
+
+// StartRemote serves a new, empty directory until the test ends.
+func StartRemote(t testing.TB) *Remote {
+	t.Helper()
+	r := &Remote{Dir: t.TempDir(), t: t}
+	files := http.FileServer(http.Dir(r.Dir))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/index.txt" {
+			r.serveIndex(w)
+			return
+		}
+		files.ServeHTTP(w, req)
+	}))
+	t.Cleanup(srv.Close)
+	r.URL = srv.URL
+	return r
+}
+
+func (r *Remote) serveIndex(w http.ResponseWriter) {
+	entries, err := os.ReadDir(r.Dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".gpkg") {
+			_, _ = w.Write([]byte(e.Name() + "\n"))
+		}
+	}
+}
+
+// AddSource writes a GeoPackage named file with layers to the served
+// directory. Until it is complete it is hidden from the index.
+func (r *Remote) AddSource(file string, layers ...Layer) {
+	r.t.Helper()
+	tmp := filepath.Join(r.Dir, file+".partial")
+	WriteGeoPackage(r.t, tmp, layers...)
+	if err := os.Rename(tmp, filepath.Join(r.Dir, file)); err != nil {
+		r.t.Fatalf("publishing %s: %v", file, err)
+	}
+}
+
+// Configure points cfg at the remote as HTTP storage with sync enabled. The
+// sync interval is long, so tests trigger syncs through POST /api/v1/sync
+// rather than racing the scheduler.
+func (r *Remote) Configure(cfg *config.Config) {
+	cfg.Storage.Type = config.StorageTypeHTTP
+	cfg.Storage.HTTP.BaseURL = r.URL
+	cfg.Sync.Enabled = true
+	cfg.Sync.Interval = 24 * time.Hour
+}
@@ -0,0 +1,27 @@
+#!/usr/bin/env bash
+#
+# vendor-swagger-ui.sh — vendor the Swagger UI assets served at /docs.
+#
+# Downloads the swagger-ui-dist release pinned in
+# internal/adapters/http/swaggerui/VERSION from the npm registry and copies the
+# files the /docs page loads (plus the license) next to it, where go:embed
+# picks them up. Commit the result; bump VERSION and re-run to upgrade.
+#
+# Usage: scripts/vendor-swagger-ui.sh
+set -euo pipefail
+
+DIR="$(dirname "$0")/../internal/adapters/http/swaggerui"
+VERSION="$(tr -d '[:space:]' < "$DIR/VERSION")"
+URL="https://registry.npmjs.org/swagger-ui-dist/-/swagger-ui-dist-${VERSION}.tgz"
+
+TMP="$(mktemp -d)"
+trap 'rm -rf "$TMP"' EXIT
+
+echo "vendor-swagger-ui: fetching swagger-ui-dist ${VERSION}"
+curl -fsSL "$URL" -o "$TMP/dist.tgz"
+tar -xzf "$TMP/dist.tgz" -C "$TMP"
+
+for f in swagger-ui.css swagger-ui-bundle.js swagger-ui-standalone-preset.js LICENSE; do
+	cp "$TMP/package/$f" "$DIR/$f"
+done
+echo "vendor-swagger-ui: wrote $(cd "$DIR" && pwd)"